@@ -0,0 +1,335 @@
+package mock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store/dbadapter"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// DefaultAverageBlockTime is the average block time reported by the chain
+const DefaultAverageBlockTime = 100 * time.Millisecond
+
+// Chain is an in-memory chain hosting a minimal IBC implementation (clients, connections, channels and ICS-20 transfers).
+// It is intended for development and testing of the relayer in-process (e.g. `dev loopback`) and cannot be configured in the config file.
+// Every call of SendMsgs is executed as a single transaction in a new block, so the chain doesn't produce empty blocks.
+// Any light client implementing `exported.ClientState` on top of a KVStore can be hosted (e.g. the mock client).
+type Chain struct {
+	chainID string
+	address sdk.AccAddress
+
+	codec            codec.ProtoCodecMarshaler
+	path             *core.PathEnd
+	msgEventListener core.MsgEventListener
+
+	mu sync.RWMutex
+	// state is the working state, which is equal to the state of the latest block between transactions
+	state *dbadapter.Store
+	// blocks are the committed blocks, where the block at height h is blocks[h-1]
+	blocks []*block
+	// txs are the results of the executed transactions (tx hash => result)
+	txs map[string]*txResult
+	// packets are the packets sent or received on the chain with the heights of the events
+	packets map[packetKey]*core.PacketInfo
+}
+
+var _ core.Chain = (*Chain)(nil)
+
+type block struct {
+	height uint64
+	time   time.Time
+	// state is the snapshot of the state at the end of the block
+	state *dbadapter.Store
+	// sentPackets and writtenAcks are the packet events in the block
+	sentPackets core.PacketInfoList
+	writtenAcks core.PacketInfoList
+}
+
+type txResult struct {
+	txHash        string
+	height        clienttypes.Height
+	failureReason string
+	// failure is the failure of the msg that made the tx fail
+	failure *core.MsgFailureError
+	// events are the events emitted by each msg in the tx
+	events [][]core.MsgEventLog
+}
+
+// packetKey identifies a packet by its source or destination end
+type packetKey struct {
+	portID    string
+	channelID string
+	sequence  uint64
+	// received is true if the key is the destination end of the packet
+	received bool
+}
+
+// NewChain returns a new chain whose genesis block funds the relayer account with `balances`.
+// The address of the relayer account is derived from `chainID`.
+func NewChain(chainID string, balances sdk.Coins) (*Chain, error) {
+	c := &Chain{
+		chainID: chainID,
+		address: sdk.AccAddress(crypto.AddressHash([]byte("relayer/" + chainID))),
+		state:   &dbadapter.Store{DB: dbm.NewMemDB()},
+		txs:     make(map[string]*txResult),
+		packets: make(map[packetKey]*core.PacketInfo),
+	}
+	if !balances.IsValid() {
+		return nil, fmt.Errorf("invalid genesis balances: %v", balances)
+	}
+	if err := setBalance(c.state, c.address, balances); err != nil {
+		return nil, err
+	}
+	c.commit(time.Now(), nil, nil)
+	return c, nil
+}
+
+// ChainID returns ID of the chain
+func (c *Chain) ChainID() string {
+	return c.chainID
+}
+
+// GetAddress returns the address of the relayer account
+func (c *Chain) GetAddress() (sdk.AccAddress, error) {
+	return c.address, nil
+}
+
+// Codec returns the codec
+func (c *Chain) Codec() codec.ProtoCodecMarshaler {
+	return c.codec
+}
+
+// Path returns the path
+func (c *Chain) Path() *core.PathEnd {
+	return c.path
+}
+
+// Init initializes the chain
+func (c *Chain) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	c.codec = codec
+	return nil
+}
+
+// Upgrade simulates a coordinated upgrade of the chain bumping the revision number, where the chain ID is changed to `chainID`
+// (e.g. "ibc1-1" to "ibc1-2") and an empty block of the new revision is committed. The state including the clients is kept,
+// and the revision heights continue from the previous revision. The heights of the previous revision are no longer accepted.
+// It must not be called concurrently with the other methods.
+func (c *Chain) Upgrade(chainID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !clienttypes.IsRevisionFormat(chainID) || clienttypes.ParseChainID(chainID) <= clienttypes.ParseChainID(c.chainID) {
+		return fmt.Errorf("the chain ID %s doesn't have a revision number greater than the one of %s", chainID, c.chainID)
+	}
+	c.chainID = chainID
+	now := time.Now()
+	if last := c.blocks[len(c.blocks)-1].time; !now.After(last) {
+		now = last.Add(time.Nanosecond)
+	}
+	c.commit(now, nil, nil)
+	return nil
+}
+
+// SetRelayInfo sets source's path and counterparty's info to the chain
+func (c *Chain) SetRelayInfo(p *core.PathEnd, _ *core.ProvableChain, _ *core.PathEnd) error {
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("path on chain %s failed to set: %w", c.ChainID(), err)
+	}
+	c.path = p
+	return nil
+}
+
+// SetupForRelay performs chain-specific setup before starting the relay
+func (c *Chain) SetupForRelay(ctx context.Context) error {
+	return nil
+}
+
+// LatestHeight returns the height of the latest block
+func (c *Chain) LatestHeight() (ibcexported.Height, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.height(uint64(len(c.blocks))), nil
+}
+
+// Timestamp returns the time of the block at `height`
+func (c *Chain) Timestamp(height ibcexported.Height) (time.Time, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b, err := c.blockAt(height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return b.time, nil
+}
+
+// AverageBlockTime returns DefaultAverageBlockTime
+func (c *Chain) AverageBlockTime() time.Duration {
+	return DefaultAverageBlockTime
+}
+
+// RegisterMsgEventListener registers a given EventListener to the chain
+func (c *Chain) RegisterMsgEventListener(listener core.MsgEventListener) {
+	c.msgEventListener = listener
+}
+
+// SendMsgs executes `msgs` as a transaction in a new block.
+// The tx is included in the block even if the execution fails, in which case the state changes of all the msgs are discarded.
+func (c *Chain) SendMsgs(msgs []sdk.Msg) ([]core.MsgResult, error) {
+	res := c.executeTx(msgs)
+	if res.failureReason != "" {
+		return nil, fmt.Errorf("tx %s failed on %s: %w", res.txHash, c.ChainID(), res.failure)
+	}
+	if c.msgEventListener != nil {
+		if err := c.msgEventListener.OnSentMsg(msgs); err != nil {
+			return nil, err
+		}
+	}
+	var results []core.MsgResult
+	for i := range msgs {
+		results = append(results, res.msgResult(&MsgID{TxHash: res.txHash, MsgIndex: uint32(i)}))
+	}
+	return results, nil
+}
+
+// GetMsgResult returns the execution result of `sdk.Msg` specified by `MsgID`
+func (c *Chain) GetMsgResult(id core.MsgID) (core.MsgResult, error) {
+	msgID, ok := id.(*MsgID)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message id type: %T", id)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res, ok := c.txs[msgID.TxHash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", core.ErrTxNotFound, msgID.TxHash)
+	}
+	if res.failureReason == "" && int(msgID.MsgIndex) >= len(res.events) {
+		return nil, fmt.Errorf("msg index %d out of range of tx %s", msgID.MsgIndex, msgID.TxHash)
+	}
+	return res.msgResult(msgID), nil
+}
+
+// msgResult returns the result of the msg of the tx specified by `msgID`
+func (res *txResult) msgResult(msgID *MsgID) *MsgResult {
+	if res.failureReason != "" {
+		return &MsgResult{msgID: msgID, height: res.height, txStatus: false, txFailureReason: res.failureReason}
+	}
+	return &MsgResult{msgID: msgID, height: res.height, txStatus: true, events: res.events[msgID.MsgIndex]}
+}
+
+// GetTxResult returns the execution result of the transaction of which hash equals to `txID`
+func (c *Chain) GetTxResult(ctx context.Context, txID string) (*core.TxResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res, ok := c.txs[txID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", core.ErrTxNotFound, txID)
+	}
+	ret := &core.TxResult{
+		TxID:   txID,
+		Height: res.height,
+	}
+	if res.failureReason != "" {
+		ret.Code = 1
+		ret.FailureReason = res.failureReason
+		return ret, nil
+	}
+	for _, events := range res.events {
+		ret.Events = append(ret.Events, events...)
+	}
+	return ret, nil
+}
+
+// executeTx executes `msgs` atomically and commits a new block including the tx
+func (c *Chain) executeTx(msgs []sdk.Msg) *txResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if last := c.blocks[len(c.blocks)-1].time; !now.After(last) {
+		now = last.Add(time.Nanosecond)
+	}
+	height := c.height(uint64(len(c.blocks)) + 1)
+	res := &txResult{
+		txHash: txHash(c.chainID, height.RevisionHeight),
+		height: height,
+	}
+	h := newHost(c, height, now)
+	for i, msg := range msgs {
+		events, err := h.handleMsg(msg)
+		if err != nil {
+			res.failure = &core.MsgFailureError{MsgIndex: i, Reason: err.Error()}
+			res.failureReason = res.failure.Error()
+			res.events = nil
+			break
+		}
+		res.events = append(res.events, events)
+	}
+	if res.failureReason == "" {
+		h.store.Write()
+		c.commit(now, h.sentPackets, h.writtenAcks)
+	} else {
+		c.commit(now, nil, nil)
+	}
+	c.txs[res.txHash] = res
+	return res
+}
+
+// commit appends a block with the snapshot of the working state
+func (c *Chain) commit(t time.Time, sentPackets, writtenAcks core.PacketInfoList) {
+	snapshot := &dbadapter.Store{DB: dbm.NewMemDB()}
+	it := c.state.Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		snapshot.Set(it.Key(), it.Value())
+	}
+	b := &block{
+		height:      uint64(len(c.blocks)) + 1,
+		time:        t,
+		state:       snapshot,
+		sentPackets: sentPackets,
+		writtenAcks: writtenAcks,
+	}
+	for _, p := range sentPackets {
+		c.packets[packetKey{portID: p.SourcePort, channelID: p.SourceChannel, sequence: p.Sequence}] = p
+	}
+	for _, p := range writtenAcks {
+		c.packets[packetKey{portID: p.DestinationPort, channelID: p.DestinationChannel, sequence: p.Sequence, received: true}] = p
+	}
+	c.blocks = append(c.blocks, b)
+}
+
+func (c *Chain) height(h uint64) clienttypes.Height {
+	return clienttypes.NewHeight(clienttypes.ParseChainID(c.chainID), h)
+}
+
+// blockAt returns the block at `height`, which must be called with the lock held
+func (c *Chain) blockAt(height ibcexported.Height) (*block, error) {
+	if err := core.CheckRevision(c.ChainID(), height); err != nil {
+		return nil, err
+	}
+	h := height.GetRevisionHeight()
+	if h == 0 || h > uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("block not found on %s: height=%v latest=%d", c.ChainID(), height, len(c.blocks))
+	}
+	return c.blocks[h-1], nil
+}
+
+func txHash(chainID string, height uint64) string {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, height)
+	h := sha256.Sum256(append([]byte(chainID), bz...))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,129 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+func TestCodec(t *testing.T) {
+	codec := codec.NewProtoCodec(types.NewInterfaceRegistry())
+	mock.RegisterInterfaces(codec.InterfaceRegistry())
+
+	orig := mock.MsgID{TxHash: "hoge", MsgIndex: 123}
+	bz, err := codec.MarshalInterface(core.MsgID(&orig))
+	if err != nil {
+		t.Fatalf("failed to marshal from mock.MsgID to Any: %v", err)
+	}
+	var msgID core.MsgID
+	if err := codec.UnmarshalInterface(bz, &msgID); err != nil {
+		t.Fatalf("failed to unmarshal from Any to core.MsgID: %v", err)
+	}
+	if mockMsgID, ok := msgID.(*mock.MsgID); !ok || orig != *mockMsgID {
+		t.Fatalf("unmatched MsgID: %v != %v", orig, msgID)
+	}
+}
+
+func TestFailedTx(t *testing.T) {
+	cdc := core.MakeCodec()
+	balances := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+	chain, err := mock.NewChain("ibc0", balances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Init("", 0, cdc, false); err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := chain.GetAddress()
+
+	// a client update of an unknown client fails, but the tx is included in a new block
+	msg := &clienttypes.MsgUpdateClient{ClientId: "mock-client-0", Signer: addr.String()}
+	if _, err := chain.SendMsgs([]sdk.Msg{msg}); err == nil {
+		t.Fatal("SendMsgs succeeded unexpectedly")
+	}
+	height, err := chain.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height.GetRevisionHeight() != 2 {
+		t.Fatalf("unexpected height: %v", height)
+	}
+
+	// the state is not changed by the failed tx
+	got, err := chain.QueryBalance(core.NewQueryContext(context.TODO(), height), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsEqual(balances) {
+		t.Fatalf("unexpected balance: %v != %v", got, balances)
+	}
+}
+
+func TestCheckPortBinding(t *testing.T) {
+	chain, err := mock.NewChain("ibc0", sdk.NewCoins(sdk.NewInt64Coin("stake", 100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.CheckPortBinding(context.TODO(), "transfer"); err != nil {
+		t.Errorf("the transfer port must be bound: %v", err)
+	}
+	if err := chain.CheckPortBinding(context.TODO(), "icahost"); !errors.Is(err, core.ErrPortNotBound) {
+		t.Errorf("unexpected error for an unbound port: %v", err)
+	}
+}
+
+func TestSendMsgsResults(t *testing.T) {
+	cdc := core.MakeCodec(mock.RegisterInterfaces, mockprover.RegisterInterfaces)
+	chain, err := mock.NewChain("ibc0", sdk.NewCoins())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Init("", 0, cdc, false); err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := chain.GetAddress()
+	clientState, consensusState, err := mockprover.NewProver(chain, mockprover.ProverConfig{}).CreateInitialLightClientState(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := clienttypes.NewMsgCreateClient(clientState, consensusState, addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the generated identifier is given by the result without querying it again
+	results, err := chain.SendMsgs([]sdk.Msg{msg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	if ok, reason := results[0].Status(); !ok {
+		t.Fatalf("unexpected failure: %s", reason)
+	}
+	events := results[0].Events()
+	if len(events) != 1 {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if ev, ok := events[0].(*core.EventGenerateClientIdentifier); !ok || ev.ID != "mock-client-0" {
+		t.Errorf("unexpected event: %#v", events[0])
+	}
+
+	// the same result is queried by the msg ID
+	res, err := chain.GetMsgResult(results[0].MsgID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.BlockHeight().EQ(results[0].BlockHeight()) || len(res.Events()) != 1 {
+		t.Errorf("unexpected result: %v", res)
+	}
+}
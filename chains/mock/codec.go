@@ -0,0 +1,15 @@
+package mock
+
+import (
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// RegisterInterfaces register the module interfaces to protobuf
+// Any.
+func RegisterInterfaces(registry codectypes.InterfaceRegistry) {
+	registry.RegisterImplementations(
+		(*core.MsgID)(nil),
+		&MsgID{},
+	)
+}
@@ -0,0 +1,108 @@
+package mock_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/chainsdk"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// pathConfig keeps the identifiers generated by the handshakes in the path ends
+type pathConfig struct {
+	ends map[string]*core.PathEnd
+}
+
+func (c *pathConfig) UpdateConfigID(pathName string, chainID string, configID core.ConfigIDType, id string) error {
+	end, ok := c.ends[chainID]
+	if !ok {
+		return fmt.Errorf("chain %s is not on the path %s", chainID, pathName)
+	}
+	switch configID {
+	case core.ConfigIDClient:
+		end.ClientID = id
+	case core.ConfigIDConnection:
+		end.ConnectionID = id
+	case core.ConfigIDChannel:
+		end.ChannelID = id
+	case core.ConfigIDChannelVersion:
+		end.Version = id
+	}
+	return nil
+}
+
+var (
+	testPathConfig     = &pathConfig{}
+	testPathConfigOnce sync.Once
+)
+
+// newTestChains returns a pair of mock chains with `ends` set, whose identifiers generated by the handshakes are kept in `ends`.
+// The core config is set only once since it is shared by the tests.
+func newTestChains(t *testing.T, ends [2]*core.PathEnd) [2]*core.ProvableChain {
+	t.Helper()
+	testPathConfigOnce.Do(func() {
+		core.SetCoreConfig(testPathConfig)
+	})
+	testPathConfig.ends = map[string]*core.PathEnd{ends[0].ChainID: ends[0], ends[1].ChainID: ends[1]}
+
+	cdc := core.MakeCodec(mock.RegisterInterfaces, mockprover.RegisterInterfaces)
+	var chains [2]*core.ProvableChain
+	for i, end := range ends {
+		chain, err := mock.NewChain(end.ChainID, sdk.NewCoins(sdk.NewInt64Coin("stake", 100)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		chains[i] = core.NewProvableChain(chain, mockprover.NewProver(chain, mockprover.ProverConfig{}))
+		if err := chains[i].Init("", 10*time.Second, cdc, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, end := range ends {
+		if err := chains[i].SetRelayInfo(end, chains[1-i], ends[1-i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chains
+}
+
+func TestConformance(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains := newTestChains(t, [2]*core.PathEnd{
+		{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	})
+
+	// the tests of the IBC states are skipped before the path is set up
+	t.Run("initial", func(t *testing.T) {
+		chainsdk.RunConformanceTests(t, chains[0], chainsdk.ConformanceConfig{})
+	})
+
+	policy := core.BackoffPolicy{InitialInterval: mock.DefaultAverageBlockTime.String(), MaxInterval: "1s", Multiplier: 2}
+	if err := core.CreateClients(context.TODO(), "ibc01", chains[0], chains[1], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.CreateConnection(context.TODO(), "ibc01", chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.CreateChannel(context.TODO(), "ibc01", chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+	for _, chain := range chains {
+		t.Run(chain.ChainID(), func(t *testing.T) {
+			chainsdk.RunConformanceTests(t, chain, chainsdk.ConformanceConfig{})
+		})
+	}
+}
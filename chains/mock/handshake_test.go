@@ -0,0 +1,101 @@
+package mock_test
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestResumeChannelHandshake(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	ends := [2]*core.PathEnd{
+		{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	}
+	chains := newTestChains(t, ends)
+	policy := core.BackoffPolicy{InitialInterval: mock.DefaultAverageBlockTime.String(), MaxInterval: "1s", Multiplier: 2}
+	if err := core.CreateClients(context.TODO(), "ibc01", chains[0], chains[1], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.CreateConnection(context.TODO(), "ibc01", chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// a channel handshake initiated on ibc0 by another tool
+	initChannel := func() {
+		t.Helper()
+		signer, err := chains[0].GetAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := chantypes.NewMsgChannelOpenInit("transfer", "ics20-1", chantypes.UNORDERED, []string{ends[0].ConnectionID}, "transfer", signer.String())
+		if _, err := chains[0].SendMsgs([]sdk.Msg{msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	initChannel()
+
+	// the channel ends of another order or version are not adopted
+	for _, update := range []func(end *core.PathEnd){
+		func(end *core.PathEnd) { end.Order = "ordered" },
+		func(end *core.PathEnd) { end.Version = `{"fee_version":"ics29-1","app_version":"ics20-1"}` },
+	} {
+		update(ends[0])
+		if err := core.ResumeChannelHandshake("ibc01", chains[0], chains[1]); err != nil {
+			t.Fatal(err)
+		}
+		if ends[0].ChannelID != "" {
+			t.Errorf("the channel end of another order or version is adopted: %s (order=%s, version=%s)", ends[0].ChannelID, ends[0].Order, ends[0].Version)
+		}
+		ends[0].Order, ends[0].Version = "unordered", "ics20-1"
+	}
+
+	// the channel end is adopted only if requested
+	if err := core.CreateChannel(context.TODO(), "ibc01", chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+	if ends[0].ChannelID != "channel-1" {
+		t.Fatalf("unexpected channel ID: %s", ends[0].ChannelID)
+	}
+
+	ends[0].ChannelID, ends[1].ChannelID = "", ""
+	if err := core.ResumeChannelHandshake("ibc01", chains[0], chains[1]); err != nil {
+		t.Fatal(err)
+	}
+	if ends[0].ChannelID != "channel-0" || ends[1].ChannelID != "" {
+		t.Fatalf("unexpected channel IDs: %s, %s", ends[0].ChannelID, ends[1].ChannelID)
+	}
+	if err := core.CreateChannel(context.TODO(), "ibc01", chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+	height, err := chains[0].LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := chains[0].QueryChannel(core.NewQueryContext(context.TODO(), height))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Channel.State != chantypes.OPEN || res.Channel.Counterparty.ChannelId != ends[1].ChannelID {
+		t.Errorf("the adopted channel is not opened: %v", res.Channel)
+	}
+
+	// the channel end to adopt must be unique
+	initChannel()
+	initChannel()
+	ends[0].ChannelID, ends[1].ChannelID = "", ""
+	if err := core.ResumeChannelHandshake("ibc01", chains[0], chains[1]); err == nil {
+		t.Errorf("one of the multiple channel ends is adopted: %s", ends[0].ChannelID)
+	}
+}
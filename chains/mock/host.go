@@ -0,0 +1,680 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	tmproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/store/cachekv"
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	ibchost "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	mocktypes "github.com/datachainlab/ibc-mock-client/modules/light-clients/xx-mock/types"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var (
+	keyNextClientSequence     = []byte("nextClientSequence")
+	keyNextConnectionSequence = []byte("nextConnectionSequence")
+	keyNextChannelSequence    = []byte("nextChannelSequence")
+)
+
+// host executes the msgs of a transaction on a cache of the working state of the chain.
+// Its handlers follow the ICS-02/03/04 implementation of ibc-go, where the chain itself is expected to be tracked
+// by the mock client on the counterparty chain.
+type host struct {
+	chain  *Chain
+	cdc    codec.ProtoCodecMarshaler
+	store  *cachekv.Store
+	ctx    sdk.Context
+	height clienttypes.Height
+	time   time.Time
+
+	sentPackets core.PacketInfoList
+	writtenAcks core.PacketInfoList
+}
+
+func newHost(c *Chain, height clienttypes.Height, t time.Time) *host {
+	return &host{
+		chain:  c,
+		cdc:    c.codec,
+		store:  cachekv.NewStore(c.state),
+		ctx:    sdk.Context{}.WithBlockHeader(tmproto.Header{ChainID: c.chainID, Height: int64(height.RevisionHeight), Time: t}),
+		height: height,
+		time:   t,
+	}
+}
+
+func (h *host) handleMsg(msg sdk.Msg) ([]core.MsgEventLog, error) {
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	switch msg := msg.(type) {
+	case *clienttypes.MsgCreateClient:
+		return h.createClient(msg)
+	case *clienttypes.MsgUpdateClient:
+		return h.updateClient(msg)
+	case *conntypes.MsgConnectionOpenInit:
+		return h.connOpenInit(msg)
+	case *conntypes.MsgConnectionOpenTry:
+		return h.connOpenTry(msg)
+	case *conntypes.MsgConnectionOpenAck:
+		return h.connOpenAck(msg)
+	case *conntypes.MsgConnectionOpenConfirm:
+		return h.connOpenConfirm(msg)
+	case *chantypes.MsgChannelOpenInit:
+		return h.chanOpenInit(msg)
+	case *chantypes.MsgChannelOpenTry:
+		return h.chanOpenTry(msg)
+	case *chantypes.MsgChannelOpenAck:
+		return h.chanOpenAck(msg)
+	case *chantypes.MsgChannelOpenConfirm:
+		return h.chanOpenConfirm(msg)
+	case *chantypes.MsgRecvPacket:
+		return h.recvPacket(msg)
+	case *chantypes.MsgAcknowledgement:
+		return h.acknowledgePacket(msg)
+	case *transfertypes.MsgTransfer:
+		return h.transfer(msg)
+	default:
+		return nil, fmt.Errorf("unsupported msg type: %T", msg)
+	}
+}
+
+// ------------------------------- //
+// ICS-02
+
+func (h *host) createClient(msg *clienttypes.MsgCreateClient) ([]core.MsgEventLog, error) {
+	cs, err := clienttypes.UnpackClientState(msg.ClientState)
+	if err != nil {
+		return nil, err
+	}
+	cons, err := clienttypes.UnpackConsensusState(msg.ConsensusState)
+	if err != nil {
+		return nil, err
+	}
+	seq := getUint64(h.store, keyNextClientSequence)
+	clientID := clienttypes.FormatClientIdentifier(cs.ClientType(), seq)
+	if err := cs.Initialize(h.ctx, h.cdc, h.clientStore(clientID), cons); err != nil {
+		return nil, fmt.Errorf("failed to initialize the client: %w", err)
+	}
+	setUint64(h.store, keyNextClientSequence, seq+1)
+	return []core.MsgEventLog{&core.EventGenerateClientIdentifier{ID: clientID}}, nil
+}
+
+func (h *host) updateClient(msg *clienttypes.MsgUpdateClient) ([]core.MsgEventLog, error) {
+	clientMsg, err := clienttypes.UnpackClientMessage(msg.ClientMessage)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := h.activeClientState(msg.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	store := h.clientStore(msg.ClientId)
+	if err := cs.VerifyClientMessage(h.ctx, h.cdc, store, clientMsg); err != nil {
+		return nil, fmt.Errorf("failed to verify the client message: %w", err)
+	}
+	if cs.CheckForMisbehaviour(h.ctx, h.cdc, store, clientMsg) {
+		return nil, fmt.Errorf("misbehaviour detected for client %s", msg.ClientId)
+	}
+	var heights []clienttypes.Height
+	for _, height := range cs.UpdateState(h.ctx, h.cdc, store, clientMsg) {
+		heights = append(heights, height.(clienttypes.Height))
+	}
+	return []core.MsgEventLog{&core.EventUpdateClient{ClientID: msg.ClientId, ClientType: cs.ClientType(), ConsensusHeights: heights}}, nil
+}
+
+func (h *host) clientStore(clientID string) storetypes.KVStore {
+	return prefix.NewStore(h.store, ibchost.FullClientKey(clientID, nil))
+}
+
+func (h *host) activeClientState(clientID string) (exported.ClientState, error) {
+	bz := h.clientStore(clientID).Get(ibchost.ClientStateKey())
+	if bz == nil {
+		return nil, fmt.Errorf("client not found: %s", clientID)
+	}
+	cs, err := clienttypes.UnmarshalClientState(h.cdc, bz)
+	if err != nil {
+		return nil, err
+	}
+	if status := cs.Status(h.ctx, h.clientStore(clientID), h.cdc); status != exported.Active {
+		return nil, fmt.Errorf("client %s is not active: %s", clientID, status)
+	}
+	return cs, nil
+}
+
+// validateSelfClient validates the client tracking the chain on the counterparty chain
+func (h *host) validateSelfClient(cs exported.ClientState) error {
+	if _, ok := cs.(*mocktypes.ClientState); !ok {
+		return fmt.Errorf("the chain must be tracked by the mock client: got %T", cs)
+	}
+	if cs.GetLatestHeight().GTE(h.height) {
+		return fmt.Errorf("the client height %v must be less than the current height %v", cs.GetLatestHeight(), h.height)
+	}
+	return nil
+}
+
+// selfConsensusState returns the consensus state of the chain at `height` in the form of the mock client
+func (h *host) selfConsensusState(height exported.Height) (exported.ConsensusState, error) {
+	if height.GTE(h.height) {
+		return nil, fmt.Errorf("the consensus height %v must be less than the current height %v", height, h.height)
+	}
+	b, err := h.chain.blockAt(height)
+	if err != nil {
+		return nil, err
+	}
+	return &mocktypes.ConsensusState{Timestamp: uint64(b.time.UnixNano())}, nil
+}
+
+// verifyMembership verifies the proof of `value` at `path` on the counterparty chain of `conn`
+func (h *host) verifyMembership(conn *conntypes.ConnectionEnd, proofHeight exported.Height, proof []byte, path string, value []byte) error {
+	cs, err := h.activeClientState(conn.ClientId)
+	if err != nil {
+		return err
+	}
+	merklePath, err := commitmenttypes.ApplyPrefix(conn.Counterparty.Prefix, commitmenttypes.NewMerklePath(path))
+	if err != nil {
+		return err
+	}
+	if err := cs.VerifyMembership(h.ctx, h.clientStore(conn.ClientId), h.cdc, proofHeight, conn.DelayPeriod, 0, proof, merklePath, value); err != nil {
+		return fmt.Errorf("failed to verify the proof of %s: %w", path, err)
+	}
+	return nil
+}
+
+// verifyClientAndConsensusStates verifies the client tracking the chain on the counterparty chain with its consensus state
+func (h *host) verifyClientAndConsensusStates(conn *conntypes.ConnectionEnd, anyClientState *codectypes.Any, proofHeight exported.Height, proofClient, proofConsensus []byte, consensusHeight exported.Height) error {
+	cs, err := clienttypes.UnpackClientState(anyClientState)
+	if err != nil {
+		return err
+	}
+	if err := h.validateSelfClient(cs); err != nil {
+		return err
+	}
+	bz, err := h.cdc.MarshalInterface(cs)
+	if err != nil {
+		return err
+	}
+	if err := h.verifyMembership(conn, proofHeight, proofClient, ibchost.FullClientStatePath(conn.Counterparty.ClientId), bz); err != nil {
+		return err
+	}
+	cons, err := h.selfConsensusState(consensusHeight)
+	if err != nil {
+		return err
+	}
+	if bz, err = h.cdc.MarshalInterface(cons); err != nil {
+		return err
+	}
+	return h.verifyMembership(conn, proofHeight, proofConsensus, ibchost.FullConsensusStatePath(conn.Counterparty.ClientId, consensusHeight), bz)
+}
+
+// ------------------------------- //
+// ICS-03
+
+func (h *host) connOpenInit(msg *conntypes.MsgConnectionOpenInit) ([]core.MsgEventLog, error) {
+	if _, err := h.activeClientState(msg.ClientId); err != nil {
+		return nil, err
+	}
+	versions := conntypes.GetCompatibleVersions()
+	if msg.Version != nil && msg.Version.GetIdentifier() != "" {
+		if !conntypes.IsSupportedVersion(versions, msg.Version) {
+			return nil, fmt.Errorf("unsupported connection version: %v", msg.Version)
+		}
+		versions = []exported.Version{msg.Version}
+	}
+	seq := getUint64(h.store, keyNextConnectionSequence)
+	connectionID := conntypes.FormatConnectionIdentifier(seq)
+	conn := conntypes.NewConnectionEnd(conntypes.INIT, msg.ClientId, msg.Counterparty, conntypes.ExportedVersionsToProto(versions), msg.DelayPeriod)
+	h.setConnection(connectionID, &conn)
+	setUint64(h.store, keyNextConnectionSequence, seq+1)
+	return []core.MsgEventLog{&core.EventGenerateConnectionIdentifier{ID: connectionID}}, nil
+}
+
+func (h *host) connOpenTry(msg *conntypes.MsgConnectionOpenTry) ([]core.MsgEventLog, error) {
+	version, err := conntypes.PickVersion(conntypes.GetCompatibleVersions(), conntypes.ProtoVersionsToExported(msg.CounterpartyVersions))
+	if err != nil {
+		return nil, err
+	}
+	conn := conntypes.NewConnectionEnd(conntypes.TRYOPEN, msg.ClientId, msg.Counterparty, []*conntypes.Version{version}, msg.DelayPeriod)
+	expected := conntypes.NewConnectionEnd(
+		conntypes.INIT,
+		msg.Counterparty.ClientId,
+		conntypes.NewCounterparty(msg.ClientId, "", core.DefaultChainPrefix),
+		msg.CounterpartyVersions,
+		msg.DelayPeriod,
+	)
+	if err := h.verifyConnection(&conn, msg.ProofHeight, msg.ProofInit, msg.Counterparty.ConnectionId, &expected); err != nil {
+		return nil, err
+	}
+	if err := h.verifyClientAndConsensusStates(&conn, msg.ClientState, msg.ProofHeight, msg.ProofClient, msg.ProofConsensus, msg.ConsensusHeight); err != nil {
+		return nil, err
+	}
+	seq := getUint64(h.store, keyNextConnectionSequence)
+	connectionID := conntypes.FormatConnectionIdentifier(seq)
+	h.setConnection(connectionID, &conn)
+	setUint64(h.store, keyNextConnectionSequence, seq+1)
+	return []core.MsgEventLog{&core.EventGenerateConnectionIdentifier{ID: connectionID}}, nil
+}
+
+func (h *host) connOpenAck(msg *conntypes.MsgConnectionOpenAck) ([]core.MsgEventLog, error) {
+	conn, err := h.connection(msg.ConnectionId)
+	if err != nil {
+		return nil, err
+	} else if conn.State != conntypes.INIT {
+		return nil, fmt.Errorf("connection %s must be in INIT state: %s", msg.ConnectionId, conn.State)
+	}
+	if !conntypes.IsSupportedVersion(conntypes.ProtoVersionsToExported(conn.Versions), msg.Version) {
+		return nil, fmt.Errorf("unsupported connection version: %v", msg.Version)
+	}
+	expected := conntypes.NewConnectionEnd(
+		conntypes.TRYOPEN,
+		conn.Counterparty.ClientId,
+		conntypes.NewCounterparty(conn.ClientId, msg.ConnectionId, core.DefaultChainPrefix),
+		[]*conntypes.Version{msg.Version},
+		conn.DelayPeriod,
+	)
+	if err := h.verifyConnection(conn, msg.ProofHeight, msg.ProofTry, msg.CounterpartyConnectionId, &expected); err != nil {
+		return nil, err
+	}
+	if err := h.verifyClientAndConsensusStates(conn, msg.ClientState, msg.ProofHeight, msg.ProofClient, msg.ProofConsensus, msg.ConsensusHeight); err != nil {
+		return nil, err
+	}
+	conn.State = conntypes.OPEN
+	conn.Versions = []*conntypes.Version{msg.Version}
+	conn.Counterparty.ConnectionId = msg.CounterpartyConnectionId
+	h.setConnection(msg.ConnectionId, conn)
+	return nil, nil
+}
+
+func (h *host) connOpenConfirm(msg *conntypes.MsgConnectionOpenConfirm) ([]core.MsgEventLog, error) {
+	conn, err := h.connection(msg.ConnectionId)
+	if err != nil {
+		return nil, err
+	} else if conn.State != conntypes.TRYOPEN {
+		return nil, fmt.Errorf("connection %s must be in TRYOPEN state: %s", msg.ConnectionId, conn.State)
+	}
+	expected := conntypes.NewConnectionEnd(
+		conntypes.OPEN,
+		conn.Counterparty.ClientId,
+		conntypes.NewCounterparty(conn.ClientId, msg.ConnectionId, core.DefaultChainPrefix),
+		conn.Versions,
+		conn.DelayPeriod,
+	)
+	if err := h.verifyConnection(conn, msg.ProofHeight, msg.ProofAck, conn.Counterparty.ConnectionId, &expected); err != nil {
+		return nil, err
+	}
+	conn.State = conntypes.OPEN
+	h.setConnection(msg.ConnectionId, conn)
+	return nil, nil
+}
+
+func (h *host) verifyConnection(conn *conntypes.ConnectionEnd, proofHeight exported.Height, proof []byte, counterpartyConnectionID string, expected *conntypes.ConnectionEnd) error {
+	bz, err := h.cdc.Marshal(expected)
+	if err != nil {
+		return err
+	}
+	return h.verifyMembership(conn, proofHeight, proof, ibchost.ConnectionPath(counterpartyConnectionID), bz)
+}
+
+func (h *host) connection(connectionID string) (*conntypes.ConnectionEnd, error) {
+	bz := h.store.Get(ibchost.ConnectionKey(connectionID))
+	if bz == nil {
+		return nil, fmt.Errorf("connection not found: %s", connectionID)
+	}
+	var conn conntypes.ConnectionEnd
+	if err := h.cdc.Unmarshal(bz, &conn); err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+func (h *host) openConnection(connectionID string) (*conntypes.ConnectionEnd, error) {
+	conn, err := h.connection(connectionID)
+	if err != nil {
+		return nil, err
+	} else if conn.State != conntypes.OPEN {
+		return nil, fmt.Errorf("connection %s must be in OPEN state: %s", connectionID, conn.State)
+	}
+	return conn, nil
+}
+
+func (h *host) setConnection(connectionID string, conn *conntypes.ConnectionEnd) {
+	h.store.Set(ibchost.ConnectionKey(connectionID), h.cdc.MustMarshal(conn))
+}
+
+// ------------------------------- //
+// ICS-04
+
+func (h *host) chanOpenInit(msg *chantypes.MsgChannelOpenInit) ([]core.MsgEventLog, error) {
+	if _, err := h.connection(msg.Channel.ConnectionHops[0]); err != nil {
+		return nil, err
+	}
+	app, err := h.app(msg.PortId)
+	if err != nil {
+		return nil, err
+	}
+	version, err := app.onChanOpenInit(msg.Channel.Ordering, msg.Channel.Version)
+	if err != nil {
+		return nil, err
+	}
+	seq := getUint64(h.store, keyNextChannelSequence)
+	channelID := chantypes.FormatChannelIdentifier(seq)
+	channel := chantypes.NewChannel(chantypes.INIT, msg.Channel.Ordering, msg.Channel.Counterparty, msg.Channel.ConnectionHops, version)
+	h.initChannel(msg.PortId, channelID, &channel)
+	setUint64(h.store, keyNextChannelSequence, seq+1)
+	return []core.MsgEventLog{
+		&core.EventGenerateChannelIdentifier{ID: channelID},
+		channelStateChange(msg.PortId, channelID, &channel),
+	}, nil
+}
+
+func (h *host) chanOpenTry(msg *chantypes.MsgChannelOpenTry) ([]core.MsgEventLog, error) {
+	conn, err := h.openConnection(msg.Channel.ConnectionHops[0])
+	if err != nil {
+		return nil, err
+	}
+	app, err := h.app(msg.PortId)
+	if err != nil {
+		return nil, err
+	}
+	expected := chantypes.NewChannel(
+		chantypes.INIT,
+		msg.Channel.Ordering,
+		chantypes.NewCounterparty(msg.PortId, ""),
+		[]string{conn.Counterparty.ConnectionId},
+		msg.CounterpartyVersion,
+	)
+	if err := h.verifyChannel(conn, msg.ProofHeight, msg.ProofInit, msg.Channel.Counterparty.PortId, msg.Channel.Counterparty.ChannelId, &expected); err != nil {
+		return nil, err
+	}
+	version, err := app.onChanOpenTry(msg.Channel.Ordering, msg.CounterpartyVersion)
+	if err != nil {
+		return nil, err
+	}
+	seq := getUint64(h.store, keyNextChannelSequence)
+	channelID := chantypes.FormatChannelIdentifier(seq)
+	channel := chantypes.NewChannel(chantypes.TRYOPEN, msg.Channel.Ordering, msg.Channel.Counterparty, msg.Channel.ConnectionHops, version)
+	h.initChannel(msg.PortId, channelID, &channel)
+	setUint64(h.store, keyNextChannelSequence, seq+1)
+	return []core.MsgEventLog{
+		&core.EventGenerateChannelIdentifier{ID: channelID},
+		channelStateChange(msg.PortId, channelID, &channel),
+	}, nil
+}
+
+func (h *host) chanOpenAck(msg *chantypes.MsgChannelOpenAck) ([]core.MsgEventLog, error) {
+	channel, err := h.channel(msg.PortId, msg.ChannelId)
+	if err != nil {
+		return nil, err
+	} else if channel.State != chantypes.INIT {
+		return nil, fmt.Errorf("channel %s/%s must be in INIT state: %s", msg.PortId, msg.ChannelId, channel.State)
+	}
+	conn, err := h.openConnection(channel.ConnectionHops[0])
+	if err != nil {
+		return nil, err
+	}
+	app, err := h.app(msg.PortId)
+	if err != nil {
+		return nil, err
+	}
+	expected := chantypes.NewChannel(
+		chantypes.TRYOPEN,
+		channel.Ordering,
+		chantypes.NewCounterparty(msg.PortId, msg.ChannelId),
+		[]string{conn.Counterparty.ConnectionId},
+		msg.CounterpartyVersion,
+	)
+	if err := h.verifyChannel(conn, msg.ProofHeight, msg.ProofTry, channel.Counterparty.PortId, msg.CounterpartyChannelId, &expected); err != nil {
+		return nil, err
+	}
+	if err := app.onChanOpenAck(msg.CounterpartyVersion); err != nil {
+		return nil, err
+	}
+	channel.State = chantypes.OPEN
+	channel.Version = msg.CounterpartyVersion
+	channel.Counterparty.ChannelId = msg.CounterpartyChannelId
+	h.setChannel(msg.PortId, msg.ChannelId, channel)
+	return []core.MsgEventLog{channelStateChange(msg.PortId, msg.ChannelId, channel)}, nil
+}
+
+func (h *host) chanOpenConfirm(msg *chantypes.MsgChannelOpenConfirm) ([]core.MsgEventLog, error) {
+	channel, err := h.channel(msg.PortId, msg.ChannelId)
+	if err != nil {
+		return nil, err
+	} else if channel.State != chantypes.TRYOPEN {
+		return nil, fmt.Errorf("channel %s/%s must be in TRYOPEN state: %s", msg.PortId, msg.ChannelId, channel.State)
+	}
+	conn, err := h.openConnection(channel.ConnectionHops[0])
+	if err != nil {
+		return nil, err
+	}
+	expected := chantypes.NewChannel(
+		chantypes.OPEN,
+		channel.Ordering,
+		chantypes.NewCounterparty(msg.PortId, msg.ChannelId),
+		[]string{conn.Counterparty.ConnectionId},
+		channel.Version,
+	)
+	if err := h.verifyChannel(conn, msg.ProofHeight, msg.ProofAck, channel.Counterparty.PortId, channel.Counterparty.ChannelId, &expected); err != nil {
+		return nil, err
+	}
+	channel.State = chantypes.OPEN
+	h.setChannel(msg.PortId, msg.ChannelId, channel)
+	return []core.MsgEventLog{channelStateChange(msg.PortId, msg.ChannelId, channel)}, nil
+}
+
+func (h *host) verifyChannel(conn *conntypes.ConnectionEnd, proofHeight exported.Height, proof []byte, portID, channelID string, expected *chantypes.Channel) error {
+	bz, err := h.cdc.Marshal(expected)
+	if err != nil {
+		return err
+	}
+	return h.verifyMembership(conn, proofHeight, proof, ibchost.ChannelPath(portID, channelID), bz)
+}
+
+func (h *host) channel(portID, channelID string) (*chantypes.Channel, error) {
+	bz := h.store.Get(ibchost.ChannelKey(portID, channelID))
+	if bz == nil {
+		return nil, fmt.Errorf("channel not found: %s/%s", portID, channelID)
+	}
+	var channel chantypes.Channel
+	if err := h.cdc.Unmarshal(bz, &channel); err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (h *host) openChannel(portID, channelID string) (*chantypes.Channel, *conntypes.ConnectionEnd, error) {
+	channel, err := h.channel(portID, channelID)
+	if err != nil {
+		return nil, nil, err
+	} else if channel.State != chantypes.OPEN {
+		return nil, nil, fmt.Errorf("channel %s/%s must be in OPEN state: %s", portID, channelID, channel.State)
+	}
+	conn, err := h.openConnection(channel.ConnectionHops[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return channel, conn, nil
+}
+
+func (h *host) setChannel(portID, channelID string, channel *chantypes.Channel) {
+	h.store.Set(ibchost.ChannelKey(portID, channelID), h.cdc.MustMarshal(channel))
+}
+
+func (h *host) initChannel(portID, channelID string, channel *chantypes.Channel) {
+	h.setChannel(portID, channelID, channel)
+	setUint64(h.store, ibchost.NextSequenceSendKey(portID, channelID), 1)
+	setUint64(h.store, ibchost.NextSequenceRecvKey(portID, channelID), 1)
+	setUint64(h.store, ibchost.NextSequenceAckKey(portID, channelID), 1)
+}
+
+func channelStateChange(portID, channelID string, channel *chantypes.Channel) *core.EventChannelStateChange {
+	return &core.EventChannelStateChange{
+		PortID:                portID,
+		ChannelID:             channelID,
+		CounterpartyPortID:    channel.Counterparty.PortId,
+		CounterpartyChannelID: channel.Counterparty.ChannelId,
+		ConnectionID:          channel.ConnectionHops[0],
+		State:                 channel.State,
+	}
+}
+
+func (h *host) sendPacket(portID, channelID string, data []byte, timeoutHeight clienttypes.Height, timeoutTimestamp uint64) (*core.EventSendPacket, error) {
+	channel, _, err := h.openChannel(portID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	key := ibchost.NextSequenceSendKey(portID, channelID)
+	seq := getUint64(h.store, key)
+	packet := chantypes.NewPacket(data, seq, portID, channelID, channel.Counterparty.PortId, channel.Counterparty.ChannelId, timeoutHeight, timeoutTimestamp)
+	if err := packet.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	h.store.Set(ibchost.PacketCommitmentKey(portID, channelID, seq), chantypes.CommitPacket(h.cdc, &packet))
+	setUint64(h.store, key, seq+1)
+	h.sentPackets = append(h.sentPackets, &core.PacketInfo{Packet: packet, EventHeight: h.height})
+	return &core.EventSendPacket{
+		Sequence:         seq,
+		SrcPort:          portID,
+		SrcChannel:       channelID,
+		TimeoutHeight:    timeoutHeight,
+		TimeoutTimestamp: time.Unix(0, int64(timeoutTimestamp)),
+		Data:             data,
+	}, nil
+}
+
+func (h *host) recvPacket(msg *chantypes.MsgRecvPacket) ([]core.MsgEventLog, error) {
+	p := msg.Packet
+	channel, conn, err := h.openChannel(p.DestinationPort, p.DestinationChannel)
+	if err != nil {
+		return nil, err
+	}
+	if p.SourcePort != channel.Counterparty.PortId || p.SourceChannel != channel.Counterparty.ChannelId {
+		return nil, fmt.Errorf("packet source %s/%s doesn't match the counterparty of the channel %s/%s", p.SourcePort, p.SourceChannel, channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+	}
+	if !p.TimeoutHeight.IsZero() && h.height.GTE(p.TimeoutHeight) {
+		return nil, fmt.Errorf("packet timed out: timeout height %v <= current height %v", p.TimeoutHeight, h.height)
+	}
+	if p.TimeoutTimestamp != 0 && uint64(h.time.UnixNano()) >= p.TimeoutTimestamp {
+		return nil, fmt.Errorf("packet timed out: timeout timestamp %d <= current timestamp %d", p.TimeoutTimestamp, h.time.UnixNano())
+	}
+	path := ibchost.PacketCommitmentPath(p.SourcePort, p.SourceChannel, p.Sequence)
+	if err := h.verifyMembership(conn, msg.ProofHeight, msg.ProofCommitment, path, chantypes.CommitPacket(h.cdc, &p)); err != nil {
+		return nil, err
+	}
+
+	switch channel.Ordering {
+	case chantypes.UNORDERED:
+		key := ibchost.PacketReceiptKey(p.DestinationPort, p.DestinationChannel, p.Sequence)
+		if h.store.Has(key) {
+			// the packet has already been received, so this msg is a no-op
+			return nil, nil
+		}
+		h.store.Set(key, []byte{byte(1)})
+	case chantypes.ORDERED:
+		key := ibchost.NextSequenceRecvKey(p.DestinationPort, p.DestinationChannel)
+		next := getUint64(h.store, key)
+		if p.Sequence < next {
+			return nil, nil
+		} else if p.Sequence != next {
+			return nil, fmt.Errorf("packet sequence %d != next receive sequence %d", p.Sequence, next)
+		}
+		setUint64(h.store, key, next+1)
+	}
+
+	app, err := h.app(p.DestinationPort)
+	if err != nil {
+		return nil, err
+	}
+	ack := app.onRecvPacket(p).Acknowledgement()
+	h.store.Set(ibchost.PacketAcknowledgementKey(p.DestinationPort, p.DestinationChannel, p.Sequence), chantypes.CommitAcknowledgement(ack))
+	h.writtenAcks = append(h.writtenAcks, &core.PacketInfo{Packet: p, Acknowledgement: ack, EventHeight: h.height})
+	return []core.MsgEventLog{
+		&core.EventRecvPacket{
+			Sequence:         p.Sequence,
+			DstPort:          p.DestinationPort,
+			DstChannel:       p.DestinationChannel,
+			TimeoutHeight:    p.TimeoutHeight,
+			TimeoutTimestamp: time.Unix(0, int64(p.TimeoutTimestamp)),
+			Data:             p.Data,
+		},
+		&core.EventWriteAcknowledgement{
+			Sequence:        p.Sequence,
+			DstPort:         p.DestinationPort,
+			DstChannel:      p.DestinationChannel,
+			Acknowledgement: ack,
+		},
+	}, nil
+}
+
+func (h *host) acknowledgePacket(msg *chantypes.MsgAcknowledgement) ([]core.MsgEventLog, error) {
+	p := msg.Packet
+	channel, conn, err := h.openChannel(p.SourcePort, p.SourceChannel)
+	if err != nil {
+		return nil, err
+	}
+	if p.DestinationPort != channel.Counterparty.PortId || p.DestinationChannel != channel.Counterparty.ChannelId {
+		return nil, fmt.Errorf("packet destination %s/%s doesn't match the counterparty of the channel %s/%s", p.DestinationPort, p.DestinationChannel, channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+	}
+	commitmentKey := ibchost.PacketCommitmentKey(p.SourcePort, p.SourceChannel, p.Sequence)
+	commitment := h.store.Get(commitmentKey)
+	if commitment == nil {
+		// the packet has already been acknowledged, so this msg is a no-op
+		return nil, nil
+	} else if !bytes.Equal(commitment, chantypes.CommitPacket(h.cdc, &p)) {
+		return nil, fmt.Errorf("commitment mismatch for packet %d", p.Sequence)
+	}
+	path := ibchost.PacketAcknowledgementPath(p.DestinationPort, p.DestinationChannel, p.Sequence)
+	if err := h.verifyMembership(conn, msg.ProofHeight, msg.ProofAcked, path, chantypes.CommitAcknowledgement(msg.Acknowledgement)); err != nil {
+		return nil, err
+	}
+	if channel.Ordering == chantypes.ORDERED {
+		key := ibchost.NextSequenceAckKey(p.SourcePort, p.SourceChannel)
+		next := getUint64(h.store, key)
+		if p.Sequence != next {
+			return nil, fmt.Errorf("packet sequence %d != next ack sequence %d", p.Sequence, next)
+		}
+		setUint64(h.store, key, next+1)
+	}
+	app, err := h.app(p.SourcePort)
+	if err != nil {
+		return nil, err
+	}
+	if err := app.onAcknowledgementPacket(p, msg.Acknowledgement); err != nil {
+		return nil, err
+	}
+	h.store.Delete(commitmentKey)
+	return []core.MsgEventLog{
+		&core.EventAcknowledgePacket{
+			Sequence:         p.Sequence,
+			SrcPort:          p.SourcePort,
+			SrcChannel:       p.SourceChannel,
+			TimeoutHeight:    p.TimeoutHeight,
+			TimeoutTimestamp: time.Unix(0, int64(p.TimeoutTimestamp)),
+		},
+	}, nil
+}
+
+func getUint64(store storetypes.KVStore, key []byte) uint64 {
+	bz := store.Get(key)
+	if bz == nil {
+		return 0
+	}
+	return sdk.BigEndianToUint64(bz)
+}
+
+func setUint64(store storetypes.KVStore, key []byte, v uint64) {
+	store.Set(key, sdk.Uint64ToBigEndian(v))
+}
@@ -0,0 +1,45 @@
+package mock
+
+import (
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var (
+	_ core.MsgID     = (*MsgID)(nil)
+	_ core.TxMsgID   = (*MsgID)(nil)
+	_ core.MsgResult = (*MsgResult)(nil)
+)
+
+func (*MsgID) Is_MsgID() {}
+
+// TxID implements core.TxMsgID
+func (id *MsgID) TxID() string {
+	return id.TxHash
+}
+
+type MsgResult struct {
+	msgID  *MsgID
+	height clienttypes.Height
+
+	txStatus        bool
+	txFailureReason string
+
+	events []core.MsgEventLog
+}
+
+func (r *MsgResult) MsgID() core.MsgID {
+	return r.msgID
+}
+
+func (r *MsgResult) BlockHeight() clienttypes.Height {
+	return r.height
+}
+
+func (r *MsgResult) Status() (bool, string) {
+	return r.txStatus, r.txFailureReason
+}
+
+func (r *MsgResult) Events() []core.MsgEventLog {
+	return r.events
+}
@@ -0,0 +1,331 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibchost "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// stateAt returns the state of the block at the height of `ctx`
+func (c *Chain) stateAt(ctx core.QueryContext) (storetypes.KVStore, clienttypes.Height, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b, err := c.blockAt(ctx.Height())
+	if err != nil {
+		return nil, clienttypes.Height{}, err
+	}
+	return b.state, c.height(b.height), nil
+}
+
+// QueryClientState returns the client state of dst chain
+func (c *Chain) QueryClientState(ctx core.QueryContext) (*clienttypes.QueryClientStateResponse, error) {
+	store, height, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bz := store.Get(ibchost.FullClientStateKey(c.Path().ClientID))
+	if bz == nil {
+		return nil, fmt.Errorf("client state not found: client_id=%s height=%v", c.Path().ClientID, height)
+	}
+	cs, err := clienttypes.UnmarshalClientState(c.codec, bz)
+	if err != nil {
+		return nil, err
+	}
+	anyCS, err := clienttypes.PackClientState(cs)
+	if err != nil {
+		return nil, err
+	}
+	return clienttypes.NewQueryClientStateResponse(anyCS, nil, height), nil
+}
+
+// QueryClientConsensusState retrevies the latest consensus state for a client in state at a given height
+func (c *Chain) QueryClientConsensusState(ctx core.QueryContext, dstClientConsHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	store, height, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bz := store.Get(ibchost.FullConsensusStateKey(c.Path().ClientID, dstClientConsHeight))
+	if bz == nil {
+		return nil, fmt.Errorf("consensus state not found: client_id=%s consensus_height=%v height=%v", c.Path().ClientID, dstClientConsHeight, height)
+	}
+	cons, err := clienttypes.UnmarshalConsensusState(c.codec, bz)
+	if err != nil {
+		return nil, err
+	}
+	anyCons, err := clienttypes.PackConsensusState(cons)
+	if err != nil {
+		return nil, err
+	}
+	return clienttypes.NewQueryConsensusStateResponse(anyCons, nil, height), nil
+}
+
+// QueryConnection returns the remote end of a given connection
+func (c *Chain) QueryConnection(ctx core.QueryContext) (*conntypes.QueryConnectionResponse, error) {
+	store, height, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bz := store.Get(ibchost.ConnectionKey(c.Path().ConnectionID))
+	if bz == nil {
+		return conntypes.NewQueryConnectionResponse(conntypes.ConnectionEnd{
+			Versions: []*conntypes.Version{},
+			State:    conntypes.UNINITIALIZED,
+		}, nil, height), nil
+	}
+	var conn conntypes.ConnectionEnd
+	if err := c.codec.Unmarshal(bz, &conn); err != nil {
+		return nil, err
+	}
+	return conntypes.NewQueryConnectionResponse(conn, nil, height), nil
+}
+
+// QueryChannel returns the channel associated with a channelID
+func (c *Chain) QueryChannel(ctx core.QueryContext) (*chantypes.QueryChannelResponse, error) {
+	store, height, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bz := store.Get(ibchost.ChannelKey(c.Path().PortID, c.Path().ChannelID))
+	if bz == nil {
+		return chantypes.NewQueryChannelResponse(chantypes.Channel{
+			State: chantypes.UNINITIALIZED,
+		}, nil, height), nil
+	}
+	var channel chantypes.Channel
+	if err := c.codec.Unmarshal(bz, &channel); err != nil {
+		return nil, err
+	}
+	return chantypes.NewQueryChannelResponse(channel, nil, height), nil
+}
+
+// QueryConnectionChannels returns all the channels associated with the connection of the path
+func (c *Chain) QueryConnectionChannels(ctx core.QueryContext) ([]*chantypes.IdentifiedChannel, error) {
+	store, _, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyPrefix := []byte(ibchost.KeyChannelEndPrefix + "/" + ibchost.KeyPortPrefix + "/")
+	it := storetypes.KVStorePrefixIterator(store, keyPrefix)
+	defer it.Close()
+	var channels []*chantypes.IdentifiedChannel
+	for ; it.Valid(); it.Next() {
+		var channel chantypes.Channel
+		if err := c.codec.Unmarshal(it.Value(), &channel); err != nil {
+			return nil, err
+		}
+		if len(channel.ConnectionHops) == 0 || channel.ConnectionHops[0] != c.Path().ConnectionID {
+			continue
+		}
+		portID, channelID, err := ibchost.ParseChannelPath(string(it.Key()))
+		if err != nil {
+			return nil, err
+		}
+		ic := chantypes.NewIdentifiedChannel(portID, channelID, channel)
+		channels = append(channels, &ic)
+	}
+	return channels, nil
+}
+
+// QueryUnreceivedPackets returns a list of unrelayed packet commitments
+func (c *Chain) QueryUnreceivedPackets(ctx core.QueryContext, seqs []uint64) ([]uint64, error) {
+	store, _, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path := c.Path()
+	var unreceived []uint64
+	if path.GetOrder() == chantypes.ORDERED {
+		next := getUint64(store, ibchost.NextSequenceRecvKey(path.PortID, path.ChannelID))
+		for _, seq := range seqs {
+			if seq >= next {
+				unreceived = append(unreceived, seq)
+			}
+		}
+		return unreceived, nil
+	}
+	for _, seq := range seqs {
+		if !store.Has(ibchost.PacketReceiptKey(path.PortID, path.ChannelID, seq)) {
+			unreceived = append(unreceived, seq)
+		}
+	}
+	return unreceived, nil
+}
+
+// QueryUnfinalizedRelayPackets returns packets and heights that are sent but not received at the latest finalized block on the counterparty chain
+func (c *Chain) QueryUnfinalizedRelayPackets(ctx core.QueryContext, counterparty core.LightClientICS04Querier) (core.PacketInfoList, error) {
+	seqs, err := c.commitmentSequences(ctx, ibchost.PacketCommitmentPrefixPath(c.Path().PortID, c.Path().ChannelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query packet commitments: error=%w height=%v", err, ctx.Height())
+	}
+	packets, err := c.packetInfos(seqs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var counterpartyCtx core.QueryContext
+	if counterpartyH, err := counterparty.GetLatestFinalizedHeader(); err != nil {
+		return nil, fmt.Errorf("failed to get latest finalized header: error=%w height=%v", err, ctx.Height())
+	} else {
+		counterpartyCtx = core.NewQueryContext(context.TODO(), counterpartyH.GetHeight())
+	}
+
+	unreceived, err := counterparty.QueryUnreceivedPackets(counterpartyCtx, packets.ExtractSequenceList())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counterparty for unreceived packets: error=%w, height=%v", err, counterpartyCtx.Height())
+	}
+	return packets.Filter(unreceived), nil
+}
+
+// QueryUnreceivedAcknowledgements returns a list of unrelayed packet acks
+func (c *Chain) QueryUnreceivedAcknowledgements(ctx core.QueryContext, seqs []uint64) ([]uint64, error) {
+	store, _, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// a packet commitment is deleted when its acknowledgement is received
+	var unreceived []uint64
+	for _, seq := range seqs {
+		if store.Has(ibchost.PacketCommitmentKey(c.Path().PortID, c.Path().ChannelID, seq)) {
+			unreceived = append(unreceived, seq)
+		}
+	}
+	return unreceived, nil
+}
+
+// QueryUnfinalizedRelayAcknowledgements returns acks and heights that are sent but not received at the latest finalized block on the counterpartychain
+func (c *Chain) QueryUnfinalizedRelayAcknowledgements(ctx core.QueryContext, counterparty core.LightClientICS04Querier) (core.PacketInfoList, error) {
+	seqs, err := c.commitmentSequences(ctx, ibchost.PacketAcknowledgementPrefixPath(c.Path().PortID, c.Path().ChannelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query packet acknowledgement commitments: error=%w height=%v", err, ctx.Height())
+	}
+	packets, err := c.packetInfos(seqs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var counterpartyCtx core.QueryContext
+	if counterpartyH, err := counterparty.GetLatestFinalizedHeader(); err != nil {
+		return nil, fmt.Errorf("failed to get latest finalized header: error=%w height=%v", err, ctx.Height())
+	} else {
+		counterpartyCtx = core.NewQueryContext(context.TODO(), counterpartyH.GetHeight())
+	}
+
+	unreceived, err := counterparty.QueryUnreceivedAcknowledgements(counterpartyCtx, packets.ExtractSequenceList())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counterparty for unreceived acknowledgements: error=%w height=%v", err, counterpartyCtx.Height())
+	}
+	return packets.Filter(unreceived), nil
+}
+
+// commitmentSequences returns the sequences of the commitments under `prefixPath` in ascending order
+func (c *Chain) commitmentSequences(ctx core.QueryContext, prefixPath string) ([]uint64, error) {
+	store, _, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	it := storetypes.KVStorePrefixIterator(prefix.NewStore(store, []byte(prefixPath+"/")), nil)
+	defer it.Close()
+	var seqs []uint64
+	for ; it.Valid(); it.Next() {
+		seq, err := strconv.ParseUint(string(it.Key()), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// packetInfos returns the packets sent (or received if `received` is true) on the channel of the path
+func (c *Chain) packetInfos(seqs []uint64, received bool) (core.PacketInfoList, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var packets core.PacketInfoList
+	for _, seq := range seqs {
+		p, ok := c.packets[packetKey{portID: c.Path().PortID, channelID: c.Path().ChannelID, sequence: seq, received: received}]
+		if !ok {
+			return nil, fmt.Errorf("packet not found: port_id=%s channel_id=%s sequence=%d received=%v", c.Path().PortID, c.Path().ChannelID, seq, received)
+		}
+		packets = append(packets, p)
+	}
+	return packets, nil
+}
+
+// QueryPacketEventsInRange returns the packets sent and the acknowledgements written on the channel of the path
+// in the blocks from `fromHeight` to `toHeight`
+func (c *Chain) QueryPacketEventsInRange(ctx context.Context, fromHeight, toHeight ibcexported.Height) (*core.PacketEvents, error) {
+	if _, ok := core.BlocksBetween(fromHeight, toHeight); !ok {
+		return nil, fmt.Errorf("invalid height range: from=%v to=%v", fromHeight, toHeight)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, err := c.blockAt(fromHeight); err != nil {
+		return nil, err
+	}
+	if _, err := c.blockAt(toHeight); err != nil {
+		return nil, err
+	}
+	path := c.Path()
+	var events core.PacketEvents
+	for _, b := range c.blocks[fromHeight.GetRevisionHeight()-1 : toHeight.GetRevisionHeight()] {
+		for _, p := range b.sentPackets {
+			if p.SourcePort == path.PortID && p.SourceChannel == path.ChannelID {
+				events.SentPackets = append(events.SentPackets, p)
+			}
+		}
+		for _, p := range b.writtenAcks {
+			if p.DestinationPort == path.PortID && p.DestinationChannel == path.ChannelID {
+				events.WrittenAcknowledgements = append(events.WrittenAcknowledgements, p)
+			}
+		}
+	}
+	return &events, nil
+}
+
+// QueryBalance returns the amount of coins in the relayer account
+func (c *Chain) QueryBalance(ctx core.QueryContext, addr sdk.AccAddress) (sdk.Coins, error) {
+	store, _, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return getBalance(store, addr)
+}
+
+// QueryDenomTraces returns all the denom traces from a given chain
+func (c *Chain) QueryDenomTraces(ctx core.QueryContext, offset, limit uint64) (*transfertypes.QueryDenomTracesResponse, error) {
+	store, _, err := c.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	it := storetypes.KVStorePrefixIterator(store, keyPrefixDenomTraces)
+	defer it.Close()
+	var traces transfertypes.Traces
+	for i := uint64(0); it.Valid(); it.Next() {
+		if i++; i <= offset {
+			continue
+		}
+		if limit > 0 && uint64(len(traces)) >= limit {
+			break
+		}
+		var trace transfertypes.DenomTrace
+		if err := c.codec.Unmarshal(it.Value(), &trace); err != nil {
+			return nil, err
+		}
+		traces = append(traces, trace)
+	}
+	return &transfertypes.QueryDenomTracesResponse{DenomTraces: traces}, nil
+}
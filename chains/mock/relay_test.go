@@ -0,0 +1,386 @@
+package mock_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// recordingHistogram keeps the values recorded with their attributes
+type recordingHistogram struct {
+	api.Float64Histogram
+
+	mu      sync.Mutex
+	records []histogramRecord
+}
+
+type histogramRecord struct {
+	value float64
+	attrs attribute.Set
+}
+
+func (h *recordingHistogram) Record(ctx context.Context, value float64, options ...api.RecordOption) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, histogramRecord{value, api.NewRecordConfig(options).Attributes()})
+}
+
+// setUpTransferPath creates the clients, the connection and the channel between the chains
+func setUpTransferPath(t *testing.T, pathName string, chains [2]*core.ProvableChain) {
+	t.Helper()
+	policy := core.BackoffPolicy{InitialInterval: mock.DefaultAverageBlockTime.String(), MaxInterval: "1s", Multiplier: 2}
+	if err := core.CreateClients(context.TODO(), pathName, chains[0], chains[1], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.CreateConnection(context.TODO(), pathName, chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.CreateChannel(context.TODO(), pathName, chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// relayUntilAcknowledged serves the relay cycles until the packet `seq` sent on src is acknowledged
+func relayUntilAcknowledged(t *testing.T, srv *core.RelayService, src *core.ProvableChain, seq uint64) {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		if err := srv.Serve(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+		height, err := src.LatestHeight()
+		if err != nil {
+			t.Fatal(err)
+		}
+		unacked, err := src.QueryUnreceivedAcknowledgements(core.NewQueryContext(context.TODO(), height), []uint64{seq})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unacked) == 0 {
+			return
+		}
+	}
+	t.Fatalf("the packet %d is not acknowledged", seq)
+}
+
+func TestRelayLatencies(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, acks := &recordingHistogram{}, &recordingHistogram{}
+	metrics.PacketDeliveryLatencyHistogram, metrics.AcknowledgementRelayLatencyHistogram = deliveries, acks
+
+	chains := newTestChains(t, [2]*core.PathEnd{
+		{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	})
+	setUpTransferPath(t, "ibc01", chains)
+	dstAddr, err := chains[1].GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.SendTransferMsg(chains[0], chains[1], sdk.NewInt64Coin("stake", 10), dstAddr.String(), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	sh, err := core.NewSyncHeaders(chains[0], chains[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	srv.SetPathName("ibc01")
+	relayUntilAcknowledged(t, srv, chains[0], 1)
+
+	// each latency is recorded once by the msg relaying it, with the chain on which the event was emitted
+	for _, c := range []struct {
+		name      string
+		histogram *recordingHistogram
+		chainID   string
+		direction string
+	}{
+		{"delivery", deliveries, "ibc0", "src"},
+		{"acknowledgement", acks, "ibc1", "dst"},
+	} {
+		if len(c.histogram.records) != 1 {
+			t.Fatalf("%s: unexpected number of records: %d", c.name, len(c.histogram.records))
+		}
+		r := c.histogram.records[0]
+		if r.value < 0 {
+			t.Errorf("%s: negative latency: %v", c.name, r.value)
+		}
+		for k, expected := range map[attribute.Key]string{
+			"path":       "ibc01",
+			"chain_id":   c.chainID,
+			"direction":  c.direction,
+			"port_id":    "transfer",
+			"channel_id": "channel-0",
+		} {
+			if v, ok := r.attrs.Value(k); !ok || v.AsString() != expected {
+				t.Errorf("%s: unexpected %s: actual=%v, expected=%s", c.name, k, v.AsString(), expected)
+			}
+		}
+	}
+}
+
+func TestQueryPathSnapshot(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	ends := [2]*core.PathEnd{
+		{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	}
+	chains := newTestChains(t, ends)
+	path := &core.Path{Src: ends[0], Dst: ends[1], Strategy: &core.StrategyCfg{Type: "naive"}}
+
+	sh, err := core.NewSyncHeaders(chains[0], chains[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the channel not created yet has no backlog, while the missing client is reported
+	snapshot := core.QueryPathSnapshot("ibc01", path, chains[0], chains[1], sh)
+	if snapshot.Src.UnrelayedPackets != 0 || len(snapshot.Src.Errors) != 1 || !strings.HasPrefix(snapshot.Src.Errors[0], "client:") {
+		t.Fatalf("unexpected snapshot before the handshake: %+v", snapshot.Src)
+	}
+
+	setUpTransferPath(t, "ibc01", chains)
+	dstAddr, err := chains[1].GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := core.SendTransferMsg(chains[0], chains[1], sdk.NewInt64Coin("stake", 10), dstAddr.String(), 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sh.Updates(chains[0], chains[1]); err != nil {
+		t.Fatal(err)
+	}
+	snapshot = core.QueryPathSnapshot("ibc01", path, chains[0], chains[1], sh)
+	if snapshot.Src.UnrelayedPackets != 2 || snapshot.SrcToDst.PendingPackets != 2 || snapshot.Dst.UnrelayedAcknowledgements != 0 {
+		t.Fatalf("unexpected snapshot after the transfers: %+v", snapshot)
+	}
+	// the path ends of the chains are restored
+	if chains[0].Path() != ends[0] || chains[1].Path() != ends[1] {
+		t.Fatal("the path ends are not restored")
+	}
+
+	// the received packets are not counted even before the finalization, and their acknowledgements are counted instead
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Updates(chains[0], chains[1]); err != nil {
+		t.Fatal(err)
+	}
+	snapshot = core.QueryPathSnapshot("ibc01", path, chains[0], chains[1], sh)
+	if snapshot.Src.UnrelayedPackets != 0 || snapshot.Dst.UnrelayedAcknowledgements != 2 || snapshot.DstToSrc.PendingAcknowledgements != 2 {
+		t.Fatalf("unexpected snapshot after the relay: %+v", snapshot)
+	}
+}
+
+func TestRelayAcrossCounterpartyUpgrade(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains := newTestChains(t, [2]*core.PathEnd{
+		{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1-1", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	})
+	setUpTransferPath(t, "ibc01", chains)
+	var addrs [2]string
+	for i, chain := range chains {
+		addr, err := chain.GetAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs[i] = addr.String()
+	}
+	transfer := func(from int) {
+		t.Helper()
+		if err := core.SendTransferMsg(chains[from], chains[1-from], sdk.NewInt64Coin("stake", 10), addrs[1-from], 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the first cycle relays a packet of each direction in revision 1 of ibc1
+	sh, err := core.NewSyncHeaders(chains[0], chains[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := core.NewNaiveStrategy(false, false)
+	srv := core.NewRelayService(st, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	transfer(0)
+	transfer(1)
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	// ibc1 is upgraded to revision 2 between the cycles, with a packet of each direction pending
+	transfer(0)
+	transfer(1)
+	if err := chains[1].Chain.(*mock.Chain).Upgrade("ibc1-2"); err != nil {
+		t.Fatal(err)
+	}
+	latest := func() [2]string {
+		var heights [2]string
+		for i, chain := range chains {
+			h, err := chain.LatestHeight()
+			if err != nil {
+				t.Fatal(err)
+			}
+			heights[i] = h.String()
+		}
+		return heights
+	}
+	before := latest()
+	if before[1] != "2-"+strings.Split(before[1], "-")[1] {
+		t.Fatalf("ibc1 is not upgraded: %s", before[1])
+	}
+
+	// the header of the new revision can't update the client tracking the previous revision,
+	// so the cycle fails before submitting any msg until the client is upgraded
+	err = srv.Serve(context.TODO())
+	if err == nil || !strings.Contains(err.Error(), "must be upgraded") {
+		t.Fatalf("unexpected error of the cycle after the upgrade: %v", err)
+	}
+	if after := latest(); after != before {
+		t.Errorf("msgs are submitted after the upgrade: before=%v, after=%v", before, after)
+	}
+
+	// the pending packets are still found on both chains, including the one sent in the previous revision of ibc1
+	if err := sh.Updates(chains[0], chains[1]); err != nil {
+		t.Fatal(err)
+	}
+	rp, err := st.UnrelayedPackets(chains[0], chains[1], sh, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rp.Src) != 1 || rp.Src[0].Sequence != 2 || len(rp.Dst) != 1 || rp.Dst[0].Sequence != 2 {
+		t.Fatalf("unexpected unrelayed packets: src=%v, dst=%v", rp.Src, rp.Dst)
+	}
+	if h := rp.Dst[0].EventHeight; h.RevisionNumber != 1 {
+		t.Errorf("the packet sent in revision 1 has an unexpected event height: %v", h)
+	}
+}
+
+func TestRelayMultipleChannels(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	ends := [2]*core.PathEnd{
+		{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	}
+	chains := newTestChains(t, ends)
+	setUpTransferPath(t, "ibc01", chains)
+
+	// another channel is created over the same clients and connection
+	second := [2]*core.PathEnd{}
+	for i, end := range ends {
+		second[i] = &core.PathEnd{ChainID: end.ChainID, ClientID: end.ClientID, ConnectionID: end.ConnectionID, PortID: "transfer", Order: "unordered", Version: "ics20-1"}
+	}
+	testPathConfig.ends = map[string]*core.PathEnd{second[0].ChainID: second[0], second[1].ChainID: second[1]}
+	for i := range chains {
+		if err := chains[i].SetRelayInfo(second[i], chains[1-i], second[1-i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	policy := core.BackoffPolicy{InitialInterval: mock.DefaultAverageBlockTime.String(), MaxInterval: "1s", Multiplier: 2}
+	if err := core.CreateChannel(context.TODO(), "ibc01", chains[0], chains[1], policy); err != nil {
+		t.Fatal(err)
+	}
+	path := &core.Path{Src: ends[0], Dst: ends[1], Channels: []*core.ChannelPair{{
+		Src: &core.ChannelEnd{ChannelID: second[0].ChannelID, PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+		Dst: &core.ChannelEnd{ChannelID: second[1].ChannelID, PortID: "transfer", Order: "unordered", Version: "ics20-1"},
+	}}}
+
+	// the path ends of the additional channel share the client and the connection of the path
+	pairs := path.ChannelPathEnds()
+	if len(pairs) != 2 || pairs[0].Src != ends[0] || pairs[0].Dst != ends[1] {
+		t.Fatalf("unexpected path ends of the channels: %v", pairs)
+	}
+	for i, end := range []*core.PathEnd{pairs[1].Src, pairs[1].Dst} {
+		if *end != *second[i] || end == second[i] {
+			t.Errorf("unexpected path end of the additional channel: actual=%+v, expected=%+v", end, second[i])
+		}
+	}
+	if second[0].ChannelID == ends[0].ChannelID {
+		t.Fatalf("the additional channel is not created: %s", second[0].ChannelID)
+	}
+
+	// a packet with the same sequence is sent on each channel
+	dstAddr, err := chains[1].GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	setChannel := func(pair core.PathEndPair) {
+		t.Helper()
+		if err := chains[0].SetRelayInfo(pair.Src, chains[1], pair.Dst); err != nil {
+			t.Fatal(err)
+		}
+		if err := chains[1].SetRelayInfo(pair.Dst, chains[0], pair.Src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pair := range pairs {
+		setChannel(pair)
+		if err := core.SendTransferMsg(chains[0], chains[1], sdk.NewInt64Coin("stake", 10), dstAddr.String(), 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the service scans each channel and builds its msgs with the path ends of the channel
+	setChannel(pairs[0])
+	sh, err := core.NewSyncHeaders(chains[0], chains[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	srv.AddChannel(pairs[1].Src, pairs[1].Dst, core.NewNaiveStrategy(false, false))
+	unacked := func() int {
+		t.Helper()
+		var n int
+		for _, pair := range pairs {
+			setChannel(pair)
+			height, err := chains[0].LatestHeight()
+			if err != nil {
+				t.Fatal(err)
+			}
+			seqs, err := chains[0].QueryUnreceivedAcknowledgements(core.NewQueryContext(context.TODO(), height), []uint64{1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			n += len(seqs)
+		}
+		return n
+	}
+	for i := 0; i < 10 && unacked() > 0; i++ {
+		if err := srv.Serve(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := unacked(); n != 0 {
+		t.Fatalf("%d packets are not acknowledged", n)
+	}
+}
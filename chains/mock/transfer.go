@@ -0,0 +1,233 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+
+	sdkmath "cosmossdk.io/math"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var (
+	keyPrefixBalances    = []byte("balances/")
+	keyPrefixDenomTraces = []byte("denomTraces/")
+)
+
+// app is an IBC application bound to a port
+type app interface {
+	onChanOpenInit(order chantypes.Order, version string) (string, error)
+	onChanOpenTry(order chantypes.Order, counterpartyVersion string) (string, error)
+	onChanOpenAck(counterpartyVersion string) error
+	onRecvPacket(packet chantypes.Packet) chantypes.Acknowledgement
+	onAcknowledgementPacket(packet chantypes.Packet, acknowledgement []byte) error
+}
+
+// app returns the application bound to `portID`, which is only ICS-20 on the transfer port
+func (h *host) app(portID string) (app, error) {
+	if portID != transfertypes.PortID {
+		return nil, fmt.Errorf("%w: no application is bound to the port %s", core.ErrPortNotBound, portID)
+	}
+	return transferApp{h}, nil
+}
+
+var _ core.PortBindingChecker = (*Chain)(nil)
+
+// CheckPortBinding implements core.PortBindingChecker
+func (c *Chain) CheckPortBinding(_ context.Context, portID string) error {
+	// the bindings don't depend on the state of the chain
+	_, err := (&host{}).app(portID)
+	return err
+}
+
+// transferApp is a minimal ICS-20 implementation without the fees, the params and the memo handling
+type transferApp struct {
+	h *host
+}
+
+func (a transferApp) onChanOpenInit(order chantypes.Order, version string) (string, error) {
+	if order != chantypes.UNORDERED {
+		return "", fmt.Errorf("ICS-20 channels must be UNORDERED: %s", order)
+	}
+	if version == "" {
+		version = transfertypes.Version
+	}
+	if version != transfertypes.Version {
+		return "", fmt.Errorf("unsupported ICS-20 version: %s", version)
+	}
+	return version, nil
+}
+
+func (a transferApp) onChanOpenTry(order chantypes.Order, counterpartyVersion string) (string, error) {
+	return a.onChanOpenInit(order, counterpartyVersion)
+}
+
+func (a transferApp) onChanOpenAck(counterpartyVersion string) error {
+	if counterpartyVersion != transfertypes.Version {
+		return fmt.Errorf("unsupported ICS-20 version: %s", counterpartyVersion)
+	}
+	return nil
+}
+
+func (a transferApp) onRecvPacket(packet chantypes.Packet) chantypes.Acknowledgement {
+	if err := a.receive(packet); err != nil {
+		return chantypes.NewErrorAcknowledgement(err)
+	}
+	return chantypes.NewResultAcknowledgement([]byte{byte(1)})
+}
+
+func (a transferApp) receive(packet chantypes.Packet) error {
+	data, receiver, amount, err := decodeTransferPacket(packet)
+	if err != nil {
+		return err
+	}
+	if transfertypes.ReceiverChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		// the tokens return to the chain, so they are unescrowed
+		unprefixed := data.Denom[len(transfertypes.GetDenomPrefix(packet.SourcePort, packet.SourceChannel)):]
+		coin := sdk.NewCoin(transfertypes.ParseDenomTrace(unprefixed).IBCDenom(), amount)
+		return a.h.sendCoins(transfertypes.GetEscrowAddress(packet.DestinationPort, packet.DestinationChannel), receiver, coin)
+	}
+	trace := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(packet.DestinationPort, packet.DestinationChannel, data.Denom))
+	a.h.setDenomTrace(trace)
+	return a.h.mintCoins(receiver, sdk.NewCoin(trace.IBCDenom(), amount))
+}
+
+func (a transferApp) onAcknowledgementPacket(packet chantypes.Packet, acknowledgement []byte) error {
+	var ack chantypes.Acknowledgement
+	if err := chantypes.SubModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil {
+		return fmt.Errorf("failed to unmarshal the ICS-20 acknowledgement: %w", err)
+	}
+	if ack.Success() {
+		return nil
+	}
+	// refund the tokens to the sender
+	data, _, amount, err := decodeTransferPacket(packet)
+	if err != nil {
+		return err
+	}
+	sender, err := sdk.AccAddressFromBech32(data.Sender)
+	if err != nil {
+		return err
+	}
+	coin := sdk.NewCoin(transfertypes.ParseDenomTrace(data.Denom).IBCDenom(), amount)
+	if transfertypes.SenderChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		return a.h.sendCoins(transfertypes.GetEscrowAddress(packet.SourcePort, packet.SourceChannel), sender, coin)
+	}
+	return a.h.mintCoins(sender, coin)
+}
+
+func decodeTransferPacket(packet chantypes.Packet) (*transfertypes.FungibleTokenPacketData, sdk.AccAddress, sdkmath.Int, error) {
+	var data transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(packet.Data, &data); err != nil {
+		return nil, nil, sdkmath.Int{}, fmt.Errorf("failed to unmarshal the ICS-20 packet data: %w", err)
+	}
+	if err := data.ValidateBasic(); err != nil {
+		return nil, nil, sdkmath.Int{}, err
+	}
+	receiver, err := sdk.AccAddressFromBech32(data.Receiver)
+	if err != nil {
+		return nil, nil, sdkmath.Int{}, fmt.Errorf("invalid receiver: %w", err)
+	}
+	amount, ok := sdkmath.NewIntFromString(data.Amount)
+	if !ok {
+		return nil, nil, sdkmath.Int{}, fmt.Errorf("invalid amount: %s", data.Amount)
+	}
+	return &data, receiver, amount, nil
+}
+
+func (h *host) transfer(msg *transfertypes.MsgTransfer) ([]core.MsgEventLog, error) {
+	sender, err := sdk.AccAddressFromBech32(msg.Sender)
+	if err != nil {
+		return nil, err
+	}
+	fullDenomPath := msg.Token.Denom
+	if trace, ok := h.denomTraceOf(msg.Token.Denom); ok {
+		fullDenomPath = trace.GetFullDenomPath()
+	}
+	if transfertypes.SenderChainIsSource(msg.SourcePort, msg.SourceChannel, fullDenomPath) {
+		err = h.sendCoins(sender, transfertypes.GetEscrowAddress(msg.SourcePort, msg.SourceChannel), msg.Token)
+	} else {
+		err = h.burnCoins(sender, msg.Token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data := transfertypes.NewFungibleTokenPacketData(fullDenomPath, msg.Token.Amount.String(), msg.Sender, msg.Receiver, msg.Memo)
+	ev, err := h.sendPacket(msg.SourcePort, msg.SourceChannel, data.GetBytes(), msg.TimeoutHeight, msg.TimeoutTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	return []core.MsgEventLog{ev}, nil
+}
+
+// denomTraceOf returns the denom trace of an IBC denom (i.e. `ibc/{hash}`)
+func (h *host) denomTraceOf(denom string) (transfertypes.DenomTrace, bool) {
+	hash, err := transfertypes.ParseHexHash(denom[len(transfertypes.DenomPrefix+"/"):])
+	if len(denom) <= len(transfertypes.DenomPrefix+"/") || denom[:len(transfertypes.DenomPrefix+"/")] != transfertypes.DenomPrefix+"/" || err != nil {
+		return transfertypes.DenomTrace{}, false
+	}
+	bz := h.store.Get(append(keyPrefixDenomTraces, hash...))
+	if bz == nil {
+		return transfertypes.DenomTrace{}, false
+	}
+	var trace transfertypes.DenomTrace
+	h.cdc.MustUnmarshal(bz, &trace)
+	return trace, true
+}
+
+func (h *host) setDenomTrace(trace transfertypes.DenomTrace) {
+	h.store.Set(append(keyPrefixDenomTraces, trace.Hash()...), h.cdc.MustMarshal(&trace))
+}
+
+func (h *host) sendCoins(from, to sdk.AccAddress, coin sdk.Coin) error {
+	if err := h.burnCoins(from, coin); err != nil {
+		return err
+	}
+	return h.mintCoins(to, coin)
+}
+
+func (h *host) mintCoins(addr sdk.AccAddress, coin sdk.Coin) error {
+	balance, err := getBalance(h.store, addr)
+	if err != nil {
+		return err
+	}
+	return setBalance(h.store, addr, balance.Add(coin))
+}
+
+func (h *host) burnCoins(addr sdk.AccAddress, coin sdk.Coin) error {
+	balance, err := getBalance(h.store, addr)
+	if err != nil {
+		return err
+	}
+	newBalance, hasNeg := balance.SafeSub(coin)
+	if hasNeg {
+		return fmt.Errorf("insufficient funds of %s: %v < %v", addr, balance, coin)
+	}
+	return setBalance(h.store, addr, newBalance)
+}
+
+func getBalance(store storetypes.KVStore, addr sdk.AccAddress) (sdk.Coins, error) {
+	bz := store.Get(append(keyPrefixBalances, addr...))
+	if bz == nil {
+		return sdk.NewCoins(), nil
+	}
+	var balance banktypes.Balance
+	if err := balance.Unmarshal(bz); err != nil {
+		return nil, err
+	}
+	return balance.Coins, nil
+}
+
+func setBalance(store storetypes.KVStore, addr sdk.AccAddress, coins sdk.Coins) error {
+	balance := banktypes.Balance{Address: addr.String(), Coins: coins}
+	bz, err := balance.Marshal()
+	if err != nil {
+		return err
+	}
+	store.Set(append(keyPrefixBalances, addr...), bz)
+	return nil
+}
@@ -0,0 +1,100 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// relayMsgTypeURLs are the types of the msgs sent by the relay service, whose authorizations the key must be granted
+var relayMsgTypeURLs = []string{
+	sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}),
+	sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}),
+	sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}),
+	sdk.MsgTypeURL(&chantypes.MsgTimeout{}),
+}
+
+// usesAuthz returns true if the msgs are sent on behalf of the granter via x/authz
+func (c *Chain) usesAuthz() bool {
+	return c.config.AuthzGranter != ""
+}
+
+// keyAddress returns the address of the configured key, which signs the txs and pays the fees
+func (c *Chain) keyAddress() (sdk.AccAddress, error) {
+	defer c.UseSDKContext()()
+	info, err := c.Keybase.Key(c.config.Key)
+	if err != nil {
+		return nil, err
+	}
+	return info.GetAddress()
+}
+
+// granterAddress returns the address of the authz granter
+func (c *Chain) granterAddress() (sdk.AccAddress, error) {
+	return sdk.GetFromBech32(c.config.AuthzGranter, c.config.AccountPrefix)
+}
+
+// wrapAuthz wraps each msg in MsgExec executed by the key on behalf of the granter.
+// Every msg is wrapped separately so that the msg results are still found at the indices of the msgs.
+func (c *Chain) wrapAuthz(msgs []sdk.Msg) ([]sdk.Msg, error) {
+	if !c.usesAuthz() {
+		return msgs, nil
+	}
+	grantee, err := c.keyAddress()
+	if err != nil {
+		return nil, err
+	}
+	defer c.UseSDKContext()()
+	wrapped := make([]sdk.Msg, len(msgs))
+	for i, msg := range msgs {
+		exec := authz.NewMsgExec(grantee, []sdk.Msg{msg})
+		wrapped[i] = &exec
+	}
+	return wrapped, nil
+}
+
+// checkAuthzGrants warns about the relay msg types that the key is not granted by the granter
+func (c *Chain) checkAuthzGrants(ctx context.Context) error {
+	if !c.usesAuthz() {
+		return nil
+	}
+	grantee, err := c.keyAddress()
+	if err != nil {
+		return err
+	}
+	granteeAddr := func() string {
+		defer c.UseSDKContext()()
+		return grantee.String()
+	}()
+	res, err := authz.NewQueryClient(c.CLIContext(0)).Grants(ctx, &authz.QueryGrantsRequest{
+		Granter: c.config.AuthzGranter,
+		Grantee: granteeAddr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query the authz grants from %s to %s: %w", c.config.AuthzGranter, granteeAddr, err)
+	}
+	granted := make(map[string]bool)
+	for _, grant := range res.Grants {
+		var a authz.Authorization
+		if err := c.codec.UnpackAny(grant.Authorization, &a); err != nil {
+			return err
+		}
+		granted[a.MsgTypeURL()] = true
+	}
+	logger := GetChainLogger()
+	for _, typeURL := range relayMsgTypeURLs {
+		if !granted[typeURL] {
+			logger.Warn("the key is not granted the authorization of a relay msg",
+				"chain_id", c.ChainID(),
+				"granter", c.config.AuthzGranter,
+				"grantee", granteeAddr,
+				"msg_type_url", typeURL,
+			)
+		}
+	}
+	return nil
+}
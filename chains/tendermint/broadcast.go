@@ -0,0 +1,93 @@
+package tendermint
+
+import (
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var _ core.InclusionWaiter = (*Chain)(nil)
+
+// maxReportedTxs bounds the number of tx hashes remembered by reportTxSpendOnce.
+// GetMsgResult of a tx is called within a relay cycle, so the hashes of the older cycles can be forgotten.
+const maxReportedTxs = 10000
+
+func isValidBroadcastMode(mode string) bool {
+	switch mode {
+	case "", flags.BroadcastSync, flags.BroadcastAsync:
+		return true
+	default:
+		return false
+	}
+}
+
+// broadcastMode returns the broadcast mode configured for the chain. It defaults to "sync".
+func (c ChainConfig) broadcastMode() string {
+	if c.BroadcastMode == "" {
+		return flags.BroadcastSync
+	}
+	return c.BroadcastMode
+}
+
+// WaitsForInclusion implements core.InclusionWaiter
+func (c *Chain) WaitsForInclusion() bool {
+	return !c.config.SkipCommitWait
+}
+
+// reportTxSpendOnce reports the spend of the tx only for the first time it is called with the tx.
+// When SendMsgs doesn't wait for the inclusion, the spend is reported by GetMsgResult and GetTxResult, which may be called many times with a tx.
+func (c *Chain) reportTxSpendOnce(txHash string, report func()) {
+	c.reportedTxsMu.Lock()
+	defer c.reportedTxsMu.Unlock()
+	if _, ok := c.reportedTxs[txHash]; ok {
+		return
+	}
+	if c.reportedTxs == nil || len(c.reportedTxs) >= maxReportedTxs {
+		c.reportedTxs = make(map[string]struct{})
+	}
+	c.reportedTxs[txHash] = struct{}{}
+	report()
+}
+
+// sequenceTracker tracks the account sequence of the next tx locally.
+// The sequence in the committed state doesn't count the txs accepted by CheckTx but not committed yet, so the txs broadcast
+// one after another without waiting for the commit (e.g. the txs of a batch split by the tx limits with SkipCommitWait) would reuse a sequence.
+type sequenceTracker struct {
+	// mu serializes building and broadcasting the txs, so that a sequence is used by only one tx
+	mu sync.Mutex
+	// next is the sequence of the next tx, or zero if it is not tracked
+	next uint64
+}
+
+// lock locks the tracker until the returned func is called
+func (t *sequenceTracker) lock() func() {
+	t.mu.Lock()
+	return t.mu.Unlock
+}
+
+// apply sets the sequence of the next tx to `txf` if it is ahead of the sequence in the committed state
+func (t *sequenceTracker) apply(txf tx.Factory) tx.Factory {
+	if t.next > txf.Sequence() {
+		return txf.WithSequence(t.next)
+	}
+	return txf
+}
+
+// update updates the sequence of the next tx with the result of broadcasting a tx of `txf`.
+// The sequence is advanced if the tx is accepted, and it is re-queried from the committed state if the tx may have been rejected by a sequence mismatch
+// (e.g. a pending tx is evicted from the mempool) or the result of the broadcast is unknown.
+func (t *sequenceTracker) update(txf tx.Factory, res *sdk.TxResponse, err error) {
+	switch {
+	case err != nil:
+		t.next = 0
+	case res.Code == 0:
+		t.next = txf.Sequence() + 1
+	case res.Codespace == sdkerrors.ErrWrongSequence.Codespace() && res.Code == sdkerrors.ErrWrongSequence.ABCICode():
+		t.next = 0
+	}
+}
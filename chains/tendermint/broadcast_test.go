@@ -0,0 +1,232 @@
+package tendermint_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/chains/tendermint"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// mempoolClient is a Tendermint RPC client of a node whose CheckTx checks the sequences of the txs
+// against the committed sequence plus the txs in the mempool. The txs in the mempool are committed when the inclusion of a tx is queried.
+type mempoolClient struct {
+	rpcclient.Client
+	cdc     codec.ProtoCodecMarshaler
+	address sdk.AccAddress
+
+	mu sync.Mutex
+	// committed is the sequence of the account in the committed state
+	committed uint64
+	// pending is the number of the txs in the mempool
+	pending uint64
+	// sequences are the sequences of the txs accepted by CheckTx
+	sequences []uint64
+	// txs are the txs accepted by CheckTx
+	txs map[string]cmttypes.Tx
+	// failDeliverTx makes the txs fail in DeliverTx
+	failDeliverTx bool
+}
+
+func (c *mempoolClient) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = 0
+}
+
+func (c *mempoolClient) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var res proto.Message
+	switch path {
+	case "/cosmos.auth.v1beta1.Query/Account":
+		account, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{Address: c.address.String(), AccountNumber: 1, Sequence: c.committed})
+		if err != nil {
+			return nil, err
+		}
+		res = &authtypes.QueryAccountResponse{Account: account}
+	case "/cosmos.tx.v1beta1.Service/Simulate":
+		res = &txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 100000}, Result: &sdk.Result{}}
+	case "/cosmos.bank.v1beta1.Query/Balance":
+		balance := sdk.NewInt64Coin("stake", 1000000000)
+		res = &banktypes.QueryBalanceResponse{Balance: &balance}
+	default:
+		return nil, fmt.Errorf("unexpected query: %s", path)
+	}
+	bz, err := proto.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	ret := &coretypes.ResultABCIQuery{}
+	ret.Response.Value = bz
+	ret.Response.Height = 1
+	return ret, nil
+}
+
+func (c *mempoolClient) BroadcastTxSync(ctx context.Context, txBytes cmttypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, err := authtx.NewTxConfig(c.cdc, authtx.DefaultSignModes).TxDecoder()(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	sigs, err := tx.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	if err != nil {
+		return nil, err
+	}
+	if seq := sigs[0].Sequence; seq != c.committed+c.pending {
+		return &coretypes.ResultBroadcastTx{
+			Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+			Codespace: sdkerrors.ErrWrongSequence.Codespace(),
+			Log:       fmt.Sprintf("account sequence mismatch, expected %d, got %d", c.committed+c.pending, seq),
+			Hash:      txBytes.Hash(),
+		}, nil
+	}
+	c.pending++
+	c.sequences = append(c.sequences, sigs[0].Sequence)
+	if c.txs == nil {
+		c.txs = make(map[string]cmttypes.Tx)
+	}
+	c.txs[string(txBytes.Hash())] = txBytes
+	return &coretypes.ResultBroadcastTx{Hash: txBytes.Hash()}, nil
+}
+
+func (c *mempoolClient) Tx(ctx context.Context, hash []byte, prove bool) (*coretypes.ResultTx, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx, ok := c.txs[string(hash)]
+	if !ok {
+		return nil, fmt.Errorf("tx (%X) not found", hash)
+	}
+	c.committed += c.pending
+	c.pending = 0
+	res := &coretypes.ResultTx{Hash: hash, Height: 1, Tx: tx}
+	res.TxResult.Log = "[]"
+	if c.failDeliverTx {
+		res.TxResult.Code = sdkerrors.ErrInsufficientFunds.ABCICode()
+		res.TxResult.Codespace = sdkerrors.ErrInsufficientFunds.Codespace()
+		res.TxResult.Log = "insufficient funds"
+	}
+	return res, nil
+}
+
+func TestSendMsgsWithoutCommitWait(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	cdc := core.MakeCodec(tendermint.RegisterInterfaces)
+	cfg := tendermint.ChainConfig{
+		Key:                  "testkey",
+		ChainId:              "ibc0",
+		RpcAddr:              "http://localhost:26657",
+		AccountPrefix:        "cosmos",
+		GasAdjustment:        1.5,
+		GasPrices:            "0.025stake",
+		AverageBlockTimeMsec: 1000,
+		MaxRetryForCommit:    5,
+		SkipCommitWait:       true,
+	}
+	c, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Init(t.TempDir(), time.Second, cdc, false); err != nil {
+		t.Fatal(err)
+	}
+	chain := c.(*tendermint.Chain)
+	if _, _, err := chain.Keybase.NewMnemonic(cfg.Key, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1); err != nil {
+		t.Fatal(err)
+	}
+	address := chain.MustGetAddress()
+	client := &mempoolClient{cdc: cdc, address: address, committed: 5}
+	chain.Client = client
+
+	counterparty, err := mock.NewChain("ibc1", sdk.NewCoins())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ends := [2]*core.PathEnd{
+		{ChainID: "ibc0", ClientID: "07-tendermint-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+	}
+	if err := chain.SetRelayInfo(ends[0], nil, ends[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := counterparty.SetRelayInfo(ends[1], nil, ends[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// a batch split into several txs is broadcast without waiting for the commit of the previous txs
+	msgs := core.NewRelayMsgs()
+	msgs.MaxMsgLength = 1
+	for i := 0; i < 3; i++ {
+		msgs.Src = append(msgs.Src, banktypes.NewMsgSend(address, address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1))))
+	}
+	msgs.Send(chain, counterparty)
+	if !msgs.Succeeded {
+		t.Fatalf("failed to send the msgs: %v", msgs.Errors)
+	}
+	if len(msgs.SrcMsgIDs) != 3 {
+		t.Fatalf("unexpected msg IDs: %v", msgs.SrcMsgIDs)
+	}
+	expected := []uint64{5, 6, 7}
+	if fmt.Sprint(client.sequences) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected sequences: actual=%v, expected=%v", client.sequences, expected)
+	}
+
+	// the sequence in the committed state is used once the txs are committed
+	if client.committed != 8 {
+		t.Fatalf("the txs are not committed: %d", client.committed)
+	}
+	if _, err := chain.SendMsgs(msgs.Src[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tracked sequence is dropped if the pending txs are evicted from the mempool
+	client.evict()
+	if _, err := chain.SendMsgs(msgs.Src[:1]); err == nil {
+		t.Fatal("the tx with the sequence of the evicted tx is accepted")
+	}
+	if _, err := chain.SendMsgs(msgs.Src[:1]); err != nil {
+		t.Fatal(err)
+	}
+	expected = append(expected, 8, 8)
+	if fmt.Sprint(client.sequences) != fmt.Sprint(expected) {
+		t.Errorf("unexpected sequences: actual=%v, expected=%v", client.sequences, expected)
+	}
+
+	// the inclusion is confirmed also for the chain wrapped in a ProvableChain as the relay service sends the msgs,
+	// so that a tx failed in DeliverTx is not counted as relayed
+	client.failDeliverTx = true
+	msgs = core.NewRelayMsgs()
+	msgs.Src = []sdk.Msg{banktypes.NewMsgSend(address, address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))}
+	msgs.Send(core.NewProvableChain(chain, nil), core.NewProvableChain(counterparty, nil))
+	if msgs.Succeeded {
+		t.Error("the msgs failed in DeliverTx are regarded as succeeded")
+	}
+}
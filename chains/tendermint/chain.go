@@ -18,7 +18,6 @@ import (
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	libclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 	sdkCtx "github.com/cosmos/cosmos-sdk/client"
-	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/codec"
 	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
@@ -53,17 +52,40 @@ type Chain struct {
 	Keybase  keys.Keyring     `yaml:"-" json:"-"`
 	Client   rpcclient.Client `yaml:"-" json:"-"`
 
+	// client of the second RPC endpoint to cross-check the proven states, which is nil if the cross-check is disabled
+	crossCheckClient rpcclient.Client
+	// newEventClient connects to the WebSocket of the RPC endpoint to subscribe the events, which is replaced in tests
+	newEventClient func() (rpcclient.Client, error)
+
 	codec            codec.ProtoCodecMarshaler `yaml:"-" json:"-"`
 	msgEventListener core.MsgEventListener
+	txSpendListener  core.TxSpendListener
+
+	// gas prices parsed from the config, in which IBC vouchers are resolved to the IBC denoms
+	gasPrices sdk.DecCoins
 
 	timeout time.Duration
 	debug   bool
 
+	// txs whose spends have been reported, used when SendMsgs doesn't wait for the inclusion
+	reportedTxs   map[string]struct{}
+	reportedTxsMu sync.Mutex
+
 	// stores facuet addresses that have been used reciently
 	faucetAddrs map[string]time.Time
+
+	// maximum size of the msgs in a tx, which is set up by SetupForRelay (zero means no limit)
+	maxMsgBytes uint64
+
+	// sequence of the next tx, which is ahead of the committed state while the broadcast txs are not committed
+	txSequence sequenceTracker
 }
 
-var _ core.Chain = (*Chain)(nil)
+var (
+	_ core.Chain           = (*Chain)(nil)
+	_ core.TxSpendReporter = (*Chain)(nil)
+	_ CosmosChain          = (*Chain)(nil)
+)
 
 func (c *Chain) ChainID() string {
 	return c.config.ChainId
@@ -73,6 +95,11 @@ func (c *Chain) Config() ChainConfig {
 	return c.config
 }
 
+// RPCAddr returns the address of the Tendermint RPC endpoint of the chain
+func (c *Chain) RPCAddr() string {
+	return c.config.RpcAddr
+}
+
 func (c *Chain) ClientID() string {
 	return c.PathEnd.ClientID
 }
@@ -81,8 +108,12 @@ func (c *Chain) Codec() codec.ProtoCodecMarshaler {
 	return c.codec
 }
 
-// GetAddress returns the sdk.AccAddress associated with the configred key
+// GetAddress returns the sdk.AccAddress associated with the configred key,
+// or the address of the authz granter if the msgs are sent on its behalf
 func (c *Chain) GetAddress() (sdk.AccAddress, error) {
+	if c.usesAuthz() {
+		return c.granterAddress()
+	}
 	defer c.UseSDKContext()()
 
 	// Signing key for c chain
@@ -113,25 +144,39 @@ func (c *Chain) Path() *core.PathEnd {
 }
 
 func (c *Chain) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
-	keybase, err := keys.New(c.config.ChainId, "test", keysDir(homePath, c.config.ChainId), nil, codec)
+	keybase, err := keys.New(c.config.ChainId, c.config.keyringBackend(), keysDir(homePath, c.config.ChainId), keyringInput(), codec)
 	if err != nil {
 		return err
 	}
+	if c.config.RemoteSigner != nil {
+		if keybase, err = newRemoteSignerKeyring(c.config.RemoteSigner, c.config.ChainId, keybase); err != nil {
+			return err
+		}
+	}
 
 	client, err := newRPCClient(c.config.RpcAddr, timeout)
 	if err != nil {
 		return err
 	}
 
-	_, err = sdk.ParseDecCoins(c.config.GasPrices)
+	if c.config.CrossCheckRpcAddr != "" {
+		crossCheckClient, err := newRPCClient(c.config.CrossCheckRpcAddr, timeout)
+		if err != nil {
+			return err
+		}
+		c.crossCheckClient = crossCheckClient
+	}
+
+	gasPrices, err := parseGasPrices(c.config.GasPrices)
 	if err != nil {
-		return fmt.Errorf("failed to parse gas prices (%s) for chain %s", c.config.GasPrices, c.ChainID())
+		return fmt.Errorf("failed to parse gas prices (%s) for chain %s: %w", c.config.GasPrices, c.ChainID(), err)
 	}
 
 	c.Keybase = keybase
 	c.Client = client
 	c.HomePath = homePath
 	c.codec = codec
+	c.gasPrices = gasPrices
 	c.timeout = timeout
 	c.debug = debug
 	c.faucetAddrs = make(map[string]time.Time)
@@ -139,7 +184,10 @@ func (c *Chain) Init(homePath string, timeout time.Duration, codec codec.ProtoCo
 }
 
 func (c *Chain) SetupForRelay(ctx context.Context) error {
-	return nil
+	if err := c.setupTxLimits(ctx); err != nil {
+		return err
+	}
+	return c.checkAuthzGrants(ctx)
 }
 
 // LatestHeight queries the chain for the latest height and returns it
@@ -155,6 +203,9 @@ func (c *Chain) LatestHeight() (ibcexported.Height, error) {
 }
 
 func (c *Chain) Timestamp(height ibcexported.Height) (time.Time, error) {
+	if err := core.CheckRevision(c.ChainID(), height); err != nil {
+		return time.Time{}, err
+	}
 	ht := int64(height.GetRevisionHeight())
 	if header, err := c.Client.Header(context.TODO(), &ht); err != nil {
 		return time.Time{}, err
@@ -172,45 +223,97 @@ func (c *Chain) RegisterMsgEventListener(listener core.MsgEventListener) {
 	c.msgEventListener = listener
 }
 
-func (c *Chain) sendMsgs(msgs []sdk.Msg) (*sdk.TxResponse, error) {
+// RegisterTxSpendListener implements core.TxSpendReporter
+func (c *Chain) RegisterTxSpendListener(listener core.TxSpendListener) {
+	c.txSpendListener = listener
+}
+
+// reportTxSpend notifies the txSpendListener of the gas used and the fee paid by the committed tx
+func (c *Chain) reportTxSpend(resTx *coretypes.ResultTx) {
+	if c.txSpendListener == nil {
+		return
+	}
+	spend := core.TxSpend{
+		ChainID: c.ChainID(),
+		TxID:    resTx.Hash.String(),
+		GasUsed: uint64(resTx.TxResult.GasUsed),
+	}
+	if addr, err := c.GetAddress(); err != nil {
+		GetChainLogger().Error("failed to get the relayer address to get the fees earned", err, "tx_hash", resTx.Hash.String())
+	} else {
+		spend.FeesEarned = feesEarned(resTx.TxResult.Events, addr.String())
+	}
+	tx, err := c.CLIContext(0).TxConfig.TxDecoder()(resTx.Tx)
+	if err != nil {
+		GetChainLogger().Error("failed to decode the tx to get the fee", err, "tx_hash", resTx.Hash.String())
+	} else if feeTx, ok := tx.(sdk.FeeTx); ok {
+		spend.Fee = feeTx.GetFee()
+	}
+	c.txSpendListener.OnTxSpend(spend)
+}
+
+// sendMsgs broadcasts a tx of the msgs and waits for its inclusion unless SkipCommitWait is set.
+// The result of the committed tx is nil if the inclusion is not waited for.
+func (c *Chain) sendMsgs(msgs []sdk.Msg) (*sdk.TxResponse, *coretypes.ResultTx, error) {
 	logger := GetChainLogger()
 	// broadcast tx
 	res, _, err := c.rawSendMsgs(msgs)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	} else if res.Code != 0 {
 		// CheckTx failed
-		return nil, fmt.Errorf("CheckTx failed: %v", errors.ABCIError(res.Codespace, res.Code, res.RawLog))
+		return nil, nil, fmt.Errorf("%w: CheckTx failed: %v", core.ErrTxRejected, errors.ABCIError(res.Codespace, res.Code, res.RawLog))
+	}
+
+	if c.config.SkipCommitWait {
+		// the inclusion is confirmed later by GetMsgResult
+		if c.msgEventListener != nil {
+			if err := c.msgEventListener.OnSentMsg(msgs); err != nil {
+				logger.Error("failed to OnSendMsg call", err)
+			}
+		}
+		return res, nil, nil
 	}
 
 	// wait for tx being committed
-	if resTx, err := c.waitForCommit(res.TxHash); err != nil {
-		return nil, err
-	} else if resTx.TxResult.IsErr() {
+	resTx, err := c.waitForCommit(res.TxHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the fee is charged even if DeliverTx failed
+	c.reportTxSpend(resTx)
+	if resTx.TxResult.IsErr() {
 		// DeliverTx failed
-		return nil, fmt.Errorf("DeliverTx failed: %v", errors.ABCIError(res.Codespace, res.Code, res.RawLog))
+		return nil, nil, fmt.Errorf("%w: DeliverTx failed: %v", core.ErrTxRejected, errors.ABCIError(resTx.TxResult.Codespace, resTx.TxResult.Code, resTx.TxResult.Log))
 	}
 
 	// call msgEventListener if needed
 	if c.msgEventListener != nil {
 		if err := c.msgEventListener.OnSentMsg(msgs); err != nil {
 			logger.Error("failed to OnSendMsg call", err)
-			return res, nil
+			return res, resTx, nil
 		}
 	}
 
-	return res, nil
+	return res, resTx, nil
 }
 
 func (c *Chain) rawSendMsgs(msgs []sdk.Msg) (*sdk.TxResponse, bool, error) {
 	// Instantiate the client context
 	ctx := c.CLIContext(0)
 
-	// Query account details
+	msgs, err := c.wrapAuthz(msgs)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Query account details, and use the sequence tracked locally if the previous txs are not committed yet
+	defer c.txSequence.lock()()
 	txf, err := prepareFactory(ctx, c.TxFactory(0))
 	if err != nil {
 		return nil, false, err
 	}
+	txf = c.txSequence.apply(txf)
 
 	// TODO: Make this work with new CalculateGas method
 	// https://github.com/cosmos/cosmos-sdk/blob/5725659684fc93790a63981c653feee33ecf3225/client/tx/tx.go#L297
@@ -223,26 +326,25 @@ func (c *Chain) rawSendMsgs(msgs []sdk.Msg) (*sdk.TxResponse, bool, error) {
 	// Set the gas amount on the transaction factory
 	txf = txf.WithGas(adjusted)
 
-	// Build the transaction builder
-	txb, err := txf.BuildUnsignedTx(msgs...)
-	if err != nil {
+	if err := c.checkFeeBalance(adjusted); err != nil {
 		return nil, false, err
 	}
 
-	// Attach the signature to the transaction
-	err = tx.Sign(txf, c.config.Key, txb, false)
+	// Build the transaction builder
+	txb, err := txf.BuildUnsignedTx(msgs...)
 	if err != nil {
 		return nil, false, err
 	}
 
-	// Generate the transaction bytes
-	txBytes, err := ctx.TxConfig.TxEncoder()(txb.GetTx())
+	// Attach the signature to the transaction and generate the transaction bytes with the tx hooks applied
+	txBytes, err := c.signAndEncodeTx(ctx, txf, txb, msgs)
 	if err != nil {
 		return nil, false, err
 	}
 
 	// Broadcast those bytes
 	res, err := ctx.BroadcastTx(txBytes)
+	c.txSequence.update(txf, res, err)
 	if err != nil {
 		return nil, false, err
 	}
@@ -404,20 +506,31 @@ func CalculateGas(
 	return simRes, uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
 }
 
-func (c *Chain) SendMsgs(msgs []sdk.Msg) ([]core.MsgID, error) {
+// SendMsgs sends the msgs in a tx and returns the results of the msgs parsed from the committed tx.
+// If SkipCommitWait is set, it returns core.PendingMsgResult's just after broadcasting the tx.
+func (c *Chain) SendMsgs(msgs []sdk.Msg) ([]core.MsgResult, error) {
 	// Broadcast those bytes
-	res, err := c.sendMsgs(msgs)
+	res, resTx, err := c.sendMsgs(msgs)
 	if err != nil {
 		return nil, err
 	}
-	var msgIDs []core.MsgID
+	var results []core.MsgResult
 	for msgIndex := range msgs {
-		msgIDs = append(msgIDs, &MsgID{
+		msgID := &MsgID{
 			TxHash:   res.TxHash,
 			MsgIndex: uint32(msgIndex),
-		})
+		}
+		if resTx == nil {
+			results = append(results, core.NewPendingMsgResult(msgID))
+			continue
+		}
+		result, err := c.msgResultFromTx(msgID, resTx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
 	}
-	return msgIDs, nil
+	return results, nil
 }
 
 func (c *Chain) GetMsgResult(id core.MsgID) (core.MsgResult, error) {
@@ -432,6 +545,15 @@ func (c *Chain) GetMsgResult(id core.MsgID) (core.MsgResult, error) {
 		return nil, fmt.Errorf("failed to query tx: %v", err)
 	}
 
+	if c.config.SkipCommitWait {
+		// the fee is charged even if DeliverTx failed
+		c.reportTxSpendOnce(msgID.TxHash, func() { c.reportTxSpend(resTx) })
+	}
+	return c.msgResultFromTx(msgID, resTx)
+}
+
+// msgResultFromTx returns the result of the msg specified by `msgID` in the committed tx
+func (c *Chain) msgResultFromTx(msgID *MsgID, resTx *coretypes.ResultTx) (*MsgResult, error) {
 	// check height of the delivered tx
 	version := clienttypes.ParseChainID(c.ChainID())
 	height := clienttypes.NewHeight(version, uint64(resTx.Height))
@@ -441,6 +563,7 @@ func (c *Chain) GetMsgResult(id core.MsgID) (core.MsgResult, error) {
 		err := errors.ABCIError(resTx.TxResult.Codespace, resTx.TxResult.Code, resTx.TxResult.Log)
 		txFailureReason := err.Error()
 		return &MsgResult{
+			msgID:           msgID,
 			height:          height,
 			txStatus:        false,
 			txFailureReason: txFailureReason,
@@ -460,12 +583,59 @@ func (c *Chain) GetMsgResult(id core.MsgID) (core.MsgResult, error) {
 	}
 
 	return &MsgResult{
+		msgID:    msgID,
 		height:   height,
 		txStatus: true,
 		events:   events,
 	}, nil
 }
 
+// GetTxResult returns the execution result of the tx of which hash equals to `txID`
+func (c *Chain) GetTxResult(ctx context.Context, txID string) (*core.TxResult, error) {
+	txHash, err := hex.DecodeString(txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the hex string of tx hash: %v", err)
+	}
+	resTx, err := c.Client.Tx(ctx, txHash, false)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("%w: %s", core.ErrTxNotFound, txID)
+		}
+		return nil, fmt.Errorf("failed to retrieve tx: %v", err)
+	}
+	if c.config.SkipCommitWait {
+		// the fee is charged even if DeliverTx failed
+		c.reportTxSpendOnce(txID, func() { c.reportTxSpend(resTx) })
+	}
+
+	res := &core.TxResult{
+		TxID:      txID,
+		Height:    clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(resTx.Height)),
+		Code:      resTx.TxResult.Code,
+		GasWanted: uint64(resTx.TxResult.GasWanted),
+		GasUsed:   uint64(resTx.TxResult.GasUsed),
+	}
+	if resTx.TxResult.IsErr() {
+		res.FailureReason = errors.ABCIError(resTx.TxResult.Codespace, resTx.TxResult.Code, resTx.TxResult.Log).Error()
+		return res, nil
+	}
+
+	abciLogs, err := sdk.ParseABCILogs(resTx.TxResult.Log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABCI logs: %v", err)
+	}
+	for _, log := range abciLogs {
+		for _, ev := range log.Events {
+			events, err := parseMsgEventLog(ev)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse msg event log: %v", err)
+			}
+			res.Events = append(res.Events, events...)
+		}
+	}
+	return res, nil
+}
+
 // ------------------------------- //
 
 func (c *Chain) Key() string {
@@ -523,7 +693,7 @@ func (c *Chain) CLIContext(height int64) sdkCtx.Context {
 		WithNodeURI(c.config.RpcAddr).
 		WithClient(c.Client).
 		WithAccountRetriever(authTypes.AccountRetriever{}).
-		WithBroadcastMode(flags.BroadcastSync).
+		WithBroadcastMode(c.config.broadcastMode()).
 		WithKeyring(c.Keybase).
 		WithOutputFormat("json").
 		WithFrom(c.config.Key).
@@ -542,9 +712,10 @@ func (c *Chain) TxFactory(height int64) tx.Factory {
 		WithChainID(c.config.ChainId).
 		WithTxConfig(ctx.TxConfig).
 		WithGasAdjustment(c.config.GasAdjustment).
-		WithGasPrices(c.config.GasPrices).
+		WithGasPrices(c.gasPrices.String()).
 		WithKeybase(c.Keybase).
-		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT)
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithMemo(c.config.Memo)
 }
 
 // KeysDir returns the path to the keys for this chain
@@ -1,6 +1,7 @@
 package tendermint
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/cometbft/cometbft/light"
@@ -9,12 +10,15 @@ import (
 	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
 )
 
+// maxClockDrift is the max clock drift of the clients created by the relayer
+const maxClockDrift = 10 * time.Minute
+
 func createClient(
 	dstHeader *tmclient.Header,
 	trustingPeriod, unbondingPeriod time.Duration,
-) *tmclient.ClientState {
+) (*tmclient.ClientState, error) {
 	if err := dstHeader.ValidateBasic(); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("invalid header: %w", err)
 	}
 
 	// Blank Client State
@@ -23,11 +27,40 @@ func createClient(
 		tmclient.NewFractionFromTm(light.DefaultTrustLevel),
 		trustingPeriod,
 		unbondingPeriod,
-		time.Minute*10,
+		maxClockDrift,
 		dstHeader.GetHeight().(clienttypes.Height),
 		commitmenttypes.GetSDKSpecs(),
 		[]string{"upgrade", "upgradedIBCState"},
 	)
 
-	return clientState
+	return clientState, nil
+}
+
+// checkClientParams checks the parameters of a client to be created with the header of chain `chainID`,
+// which are otherwise discovered only when updating the client starts failing
+func checkClientParams(chainID string, header *tmclient.Header, trustingPeriod, unbondingPeriod time.Duration, now time.Time) error {
+	if trustingPeriod <= 0 {
+		return fmt.Errorf("trusting period must be positive: %v", trustingPeriod)
+	}
+	if trustingPeriod >= unbondingPeriod {
+		return fmt.Errorf("trusting period (%v) must be less than the unbonding period (%v) of %s", trustingPeriod, unbondingPeriod, chainID)
+	}
+
+	if headerChainID := header.GetHeader().GetChainID(); headerChainID != chainID {
+		return fmt.Errorf("chain ID of the header (%s) doesn't match the configured chain ID (%s)", headerChainID, chainID)
+	}
+	revision := clienttypes.ParseChainID(chainID)
+	if height := header.GetHeight(); height.GetRevisionNumber() != revision {
+		return fmt.Errorf("revision number of the header height %v doesn't match the revision number %d parsed from the chain ID %s", height, revision, chainID)
+	}
+
+	headerTime := header.GetTime()
+	if age := now.Sub(headerTime); age >= trustingPeriod {
+		return fmt.Errorf("header at height %v is stale: its time %v is %v ago, which exceeds the trusting period %v, so the client would be expired on creation",
+			header.GetHeight(), headerTime, age, trustingPeriod)
+	}
+	if headerTime.After(now.Add(maxClockDrift)) {
+		return fmt.Errorf("header at height %v is from the future: its time %v exceeds the max clock drift %v", header.GetHeight(), headerTime, maxClockDrift)
+	}
+	return nil
 }
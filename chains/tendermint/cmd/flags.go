@@ -9,6 +9,9 @@ import (
 const (
 	flagHash  = "hash"
 	flagForce = "force"
+
+	flagMinBalance = "min-balance"
+	flagWait       = "wait"
 )
 
 func lightFlags(cmd *cobra.Command) *cobra.Command {
@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/hyperledger-labs/yui-relayer/chains/tendermint"
 	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // keysCmd represents the keys command
@@ -23,6 +29,7 @@ func keysCmd(ctx *config.Context) *cobra.Command {
 		keysRestoreCmd(ctx),
 		keysShowCmd(ctx),
 		keysListCmd(ctx),
+		keysRotateCmd(ctx),
 	)
 
 	return cmd
@@ -198,3 +205,138 @@ func keysListCmd(ctx *config.Context) *cobra.Command {
 
 	return cmd
 }
+
+type keyRotateOutput struct {
+	OldKey   string `json:"old_key"`
+	NewKey   string `json:"new_key"`
+	Address  string `json:"address"`
+	Mnemonic string `json:"mnemonic,omitempty"`
+	Balance  string `json:"balance"`
+}
+
+// keysRotateCmd respresents the `keys rotate` command
+func keysRotateCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate [chain-id] [new-name]",
+		Short: "switches the signer of a chain to a new key once it is funded",
+		Long: strings.TrimSpace(`Switches the signer of a chain to a new key.
+The key is created unless it already exists, and its address is reported to be funded.
+The signer in the config file is switched only after the key holds a balance of --min-balance
+(or any of the fee denoms if it is not set) within --wait. Running this command again for the same key
+resumes the rotation, e.g. after funding the address. The old key is kept in the keyring so that
+the transactions in flight can still be confirmed. Running relay services use the new key after a restart.`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := ctx.Config.GetChain(args[0])
+			if err != nil {
+				return err
+			}
+			chain := c.Chain.(*tendermint.Chain)
+			keyName := args[1]
+			if keyName == chain.Key() {
+				return fmt.Errorf("the key %s is already the signer of %s", keyName, chain.ChainID())
+			}
+			minBalance, err := sdk.ParseCoinsNormalized(viper.GetString(flagMinBalance))
+			if err != nil {
+				return fmt.Errorf("invalid min balance: %w", err)
+			}
+
+			out := keyRotateOutput{OldKey: chain.Key(), NewKey: keyName}
+			if !chain.KeyExists(keyName) {
+				mnemonic, err := tendermint.CreateMnemonic()
+				if err != nil {
+					return err
+				}
+				if _, err := chain.Keybase.NewAccount(keyName, mnemonic, "", hd.CreateHDPath(118, 0, 0).String(), hd.Secp256k1); err != nil {
+					return err
+				}
+				out.Mnemonic = mnemonic
+			}
+			info, err := chain.Keybase.Key(keyName)
+			if err != nil {
+				return err
+			}
+			addr, err := info.GetAddress()
+			if err != nil {
+				return err
+			}
+			out.Address = func() string {
+				defer chain.UseSDKContext()()
+				return addr.String()
+			}()
+			fmt.Fprintf(cmd.ErrOrStderr(), "fund the address %s of the key %s\n", out.Address, keyName)
+
+			balance, err := waitForFunds(cmd.Context(), chain, addr, minBalance, viper.GetDuration(flagWait))
+			if err != nil {
+				bz, _ := json.Marshal(&out)
+				fmt.Println(string(bz))
+				return err
+			}
+			out.Balance = balance.String()
+
+			cfg := chain.Config()
+			cfg.Key = keyName
+			if err := ctx.Config.UpdateChainConfig(ctx.Codec, chain.ChainID(), &cfg); err != nil {
+				return err
+			}
+			if err := ctx.Config.OverWriteConfig(); err != nil {
+				return err
+			}
+
+			bz, err := json.Marshal(&out)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(bz))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagMinBalance, "", "balance the new key must hold before the switch (e.g. 1000000stake); any balance of the fee denoms if empty")
+	cmd.Flags().Duration(flagWait, 0, "time to wait for the new key to be funded")
+	return cmd
+}
+
+// keysRotatePollInterval is the interval to query the balance of a new key waiting for funds
+const keysRotatePollInterval = 5 * time.Second
+
+// waitForFunds waits until `addr` holds `minBalance`, or any balance of the fee denoms of the chain if `minBalance` is empty
+func waitForFunds(ctx context.Context, chain *tendermint.Chain, addr sdk.AccAddress, minBalance sdk.Coins, wait time.Duration) (sdk.Coins, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		balance, err := queryLatestBalance(chain, addr)
+		if err != nil {
+			return nil, err
+		}
+		if isFunded(balance, minBalance, chain.FeeDenoms()) {
+			return balance, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("the new key is not funded yet: balance=%s: run the command again after funding it", balance)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(keysRotatePollInterval):
+		}
+	}
+}
+
+func queryLatestBalance(chain *tendermint.Chain, addr sdk.AccAddress) (sdk.Coins, error) {
+	height, err := chain.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	return chain.QueryBalance(core.NewQueryContext(context.TODO(), height), addr)
+}
+
+func isFunded(balance, minBalance sdk.Coins, feeDenoms []string) bool {
+	if !minBalance.Empty() {
+		return balance.IsAllGTE(minBalance)
+	}
+	for _, denom := range feeDenoms {
+		if balance.AmountOf(denom).IsPositive() {
+			return true
+		}
+	}
+	return len(feeDenoms) == 0 && !balance.Empty()
+}
@@ -41,7 +41,7 @@ func initLightCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 			chain := c.Chain.(*tendermint.Chain)
-			prover := c.Prover.(*tendermint.Prover)
+			prover := c.CurrentProver().(*tendermint.Prover)
 
 			db, df, err := prover.NewLightDB()
 			if err != nil {
@@ -96,7 +96,7 @@ func updateLightCmd(ctx *config.Context) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			prover := c.Prover.(*tendermint.Prover)
+			prover := c.CurrentProver().(*tendermint.Prover)
 
 			bh, err := prover.GetLatestLightHeader()
 			if err != nil {
@@ -130,7 +130,7 @@ func lightHeaderCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 			chain := c.Chain.(*tendermint.Chain)
-			prover := c.Prover.(*tendermint.Prover)
+			prover := c.CurrentProver().(*tendermint.Prover)
 
 			var header *tmclient.Header
 
@@ -188,7 +188,7 @@ func deleteLightCmd(ctx *config.Context) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			prover := c.Prover.(*tendermint.Prover)
+			prover := c.CurrentProver().(*tendermint.Prover)
 
 			err = prover.DeleteLightDB()
 			if err != nil {
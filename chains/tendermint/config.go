@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/hyperledger-labs/yui-relayer/core"
 )
 
@@ -47,17 +48,90 @@ func (c ChainConfig) Validate() error {
 	if c.MaxRetryForCommit == 0 {
 		errs = append(errs, fmt.Errorf("config attribute \"max_retry_for_commit\" is zero"))
 	}
+	if !isValidKeyringBackend(c.KeyringBackend) {
+		errs = append(errs, fmt.Errorf("config attribute \"keyring_backend\" is invalid: %s", c.KeyringBackend))
+	}
+	if !isValidEventSource(c.EventSource) {
+		errs = append(errs, fmt.Errorf("config attribute \"event_source\" is invalid: %s", c.EventSource))
+	}
+	if c.EventPollInterval != "" {
+		if c.EventSource == eventSourceWebSocket {
+			errs = append(errs, fmt.Errorf("config attribute \"event_poll_interval\" is set while \"event_source\" is %s", c.EventSource))
+		} else if d, err := time.ParseDuration(c.EventPollInterval); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("config attribute \"event_poll_interval\" is invalid: %s", c.EventPollInterval))
+		}
+	}
+	if !isValidBroadcastMode(c.BroadcastMode) {
+		errs = append(errs, fmt.Errorf("config attribute \"broadcast_mode\" is invalid: %s", c.BroadcastMode))
+	}
+	if c.RemoteSigner != nil {
+		if err := c.RemoteSigner.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.AuthzGranter != "" {
+		if _, err := sdk.GetFromBech32(c.AuthzGranter, c.AccountPrefix); err != nil {
+			errs = append(errs, fmt.Errorf("config attribute \"authz_granter\" is invalid: %v", err))
+		}
+	}
+	if c.Consumer != nil {
+		if err := c.Consumer.Validate(c.ChainId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.CrossCheckRpcAddr != "" && c.CrossCheckRpcAddr == c.RpcAddr {
+		errs = append(errs, fmt.Errorf("config attribute \"cross_check_rpc_addr\" must differ from \"rpc_addr\""))
+	}
+	if _, err := getTxHooks(c.TxHooks); err != nil {
+		errs = append(errs, fmt.Errorf("config attribute \"tx_hooks\" is invalid: %v", err))
+	}
 
 	// errors.Join returns nil if len(errs) == 0
 	return errors.Join(errs...)
 }
 
+var _ core.ConfigFieldDescriber = (*ChainConfig)(nil)
+
+// DescribeConfigFields describes the fields of the chain config in the config schema
+func (c ChainConfig) DescribeConfigFields() map[string]string {
+	return map[string]string{
+		"key":                        "name of the relayer's key in the keyring",
+		"chain_id":                   "chain ID of the chain",
+		"rpc_addr":                   "Tendermint RPC address of a node of the chain",
+		"account_prefix":             "bech32 prefix of the account addresses",
+		"gas_adjustment":             "multiplier applied to the simulated gas of a tx",
+		"gas_prices":                 "gas prices of the txs (e.g. \"0.025stake\")",
+		"average_block_time_msec":    "average block time of the chain in milliseconds",
+		"max_retry_for_commit":       "maximum number of queries for the inclusion of a tx",
+		"keyring_backend":            "keyring backend to store the relayer's key: \"test\" (default), \"file\" or \"os\"",
+		"event_source":               "source from which new packet events are detected: \"polling\" (default) or \"websocket\"",
+		"consumer":                   "set if the chain is a consumer chain of Interchain Security",
+		"consumer.provider_chain_id": "chain ID of the provider chain",
+		"consumer.provider_rpc_addr": "Tendermint RPC address of the provider chain",
+		"consumer.unbonding_period":  "unbonding period of the consumer chain (e.g. \"1209600s\")",
+		"broadcast_mode":             "broadcast mode of txs: \"sync\" (default) or \"async\"",
+		"skip_commit_wait":           "if true, the inclusion of the txs is confirmed after all the txs of a relay cycle are broadcasted",
+		"remote_signer":              "external signer daemon holding the key",
+		"remote_signer.address":      "address of the signer: \"unix:///path/to/socket\" or \"host:port\"",
+		"remote_signer.ca_file":      "CA certificate to verify the signer over TCP",
+		"remote_signer.cert_file":    "client certificate for mutual TLS",
+		"remote_signer.key_file":     "client key for mutual TLS",
+		"remote_signer.timeout":      "timeout of each request to the signer (default: \"10s\")",
+		"authz_granter":              "address of the account on whose behalf the msgs are sent via x/authz",
+		"memo":                       "memo attached to the txs",
+		"max_tx_bytes":               "maximum size of a tx in bytes (default: the smaller of the max_bytes consensus parameter and 1MiB)",
+		"tx_hooks":                   "names of the tx hooks applied in order to the txs before they are broadcast",
+		"event_poll_interval":        "interval (e.g. \"2s\") at which new blocks are scanned for packet events if event_source is \"polling\"",
+		"cross_check_rpc_addr":       "Tendermint RPC address of another node queried to cross-check the states proven",
+	}
+}
+
 var _ core.ProverConfig = (*ProverConfig)(nil)
 
 func (c ProverConfig) Build(chain core.Chain) (core.Prover, error) {
-	chain_, ok := chain.(*Chain)
+	chain_, ok := chain.(CosmosChain)
 	if !ok {
-		return nil, fmt.Errorf("chain type must be %T, not %T", &Chain{}, chain)
+		return nil, fmt.Errorf("chain type %T does not implement %T", chain, (*CosmosChain)(nil))
 	}
 	return NewProver(chain_, c), nil
 }
@@ -78,6 +152,16 @@ func (c ProverConfig) Validate() error {
 	return nil
 }
 
+var _ core.ConfigFieldDescriber = (*ProverConfig)(nil)
+
+// DescribeConfigFields describes the fields of the prover config in the config schema
+func (c ProverConfig) DescribeConfigFields() map[string]string {
+	return map[string]string{
+		"trusting_period":        "trusting period of the light client (e.g. \"336h\")",
+		"refresh_threshold_rate": "rate of the trusting period elapsed since the latest update after which the client is refreshed",
+	}
+}
+
 func (c ProverConfig) GetTrustingPeriod() time.Duration {
 	if d, err := time.ParseDuration(c.TrustingPeriod); err != nil {
 		panic(err)
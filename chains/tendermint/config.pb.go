@@ -33,6 +33,37 @@ type ChainConfig struct {
 	GasPrices            string  `protobuf:"bytes,6,opt,name=gas_prices,json=gasPrices,proto3" json:"gas_prices,omitempty"`
 	AverageBlockTimeMsec uint64  `protobuf:"varint,7,opt,name=average_block_time_msec,json=averageBlockTimeMsec,proto3" json:"average_block_time_msec,omitempty"`
 	MaxRetryForCommit    uint64  `protobuf:"varint,8,opt,name=max_retry_for_commit,json=maxRetryForCommit,proto3" json:"max_retry_for_commit,omitempty"`
+	// keyring backend to store the relayer's key: "test" (default, unencrypted), "file" (encrypted with a passphrase) or "os" (OS keychain)
+	KeyringBackend string `protobuf:"bytes,9,opt,name=keyring_backend,json=keyringBackend,proto3" json:"keyring_backend,omitempty"`
+	// source from which new packet events are detected: "polling" (default, scanned in every relay cycle or at `event_poll_interval`) or "websocket"
+	// (subscribed via the RPC WebSocket to wake the relay service as soon as a packet is sent or acknowledged)
+	EventSource string `protobuf:"bytes,10,opt,name=event_source,json=eventSource,proto3" json:"event_source,omitempty"`
+	// set if the chain is a consumer chain of Interchain Security (CCV), whose validator set is sourced from the provider chain
+	Consumer *ConsumerConfig `protobuf:"bytes,11,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	// broadcast mode of txs: "sync" (default, returns after CheckTx) or "async" (returns without waiting for CheckTx)
+	BroadcastMode string `protobuf:"bytes,12,opt,name=broadcast_mode,json=broadcastMode,proto3" json:"broadcast_mode,omitempty"`
+	// if true, SendMsgs returns just after broadcasting a tx without waiting for its inclusion in a block,
+	// and the relayer confirms the inclusion after all the txs of a relay cycle are broadcasted
+	SkipCommitWait bool `protobuf:"varint,13,opt,name=skip_commit_wait,json=skipCommitWait,proto3" json:"skip_commit_wait,omitempty"`
+	// external signer daemon holding the key, in which case the key is not stored in the keyring
+	RemoteSigner *RemoteSignerConfig `protobuf:"bytes,14,opt,name=remote_signer,json=remoteSigner,proto3" json:"remote_signer,omitempty"`
+	// address of the account on whose behalf the relayer sends the IBC msgs via x/authz.
+	// Each msg is wrapped in MsgExec signed by `key`, which must be granted the authorizations of the msgs by this account.
+	AuthzGranter string `protobuf:"bytes,15,opt,name=authz_granter,json=authzGranter,proto3" json:"authz_granter,omitempty"`
+	// memo attached to the txs sent by the relayer, which must not exceed the max_memo_characters parameter of x/auth
+	Memo string `protobuf:"bytes,16,opt,name=memo,proto3" json:"memo,omitempty"`
+	// maximum size of a tx in bytes, which is the max_tx_bytes of the mempool of the node.
+	// If it is zero, the smaller of the max_bytes consensus parameter and the default max_tx_bytes (1MiB) is used.
+	MaxTxBytes uint64 `protobuf:"varint,17,opt,name=max_tx_bytes,json=maxTxBytes,proto3" json:"max_tx_bytes,omitempty"`
+	// names of the tx hooks registered by RegisterTxHook, which are applied in order to the txs before they are broadcast
+	TxHooks []string `protobuf:"bytes,18,rep,name=tx_hooks,json=txHooks,proto3" json:"tx_hooks,omitempty"`
+	// interval (e.g. "2s") at which new blocks are scanned for packet events over HTTP if `event_source` is "polling",
+	// which wakes the relay service in the same way as the WebSocket subscription for the RPC providers disabling subscriptions.
+	// If empty, packets are scanned only in every relay cycle.
+	EventPollInterval string `protobuf:"bytes,19,opt,name=event_poll_interval,json=eventPollInterval,proto3" json:"event_poll_interval,omitempty"`
+	// Tendermint RPC address of another node of the chain, which is queried for the same states as `rpc_addr` when the proofs
+	// are built. The proofs are refused if the results disagree, which protects against a compromised or lagging RPC provider.
+	CrossCheckRpcAddr string `protobuf:"bytes,20,opt,name=cross_check_rpc_addr,json=crossCheckRpcAddr,proto3" json:"cross_check_rpc_addr,omitempty"`
 }
 
 func (m *ChainConfig) Reset()         { *m = ChainConfig{} }
@@ -68,6 +99,48 @@ func (m *ChainConfig) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ChainConfig proto.InternalMessageInfo
 
+type ConsumerConfig struct {
+	// chain ID of the provider chain
+	ProviderChainId string `protobuf:"bytes,1,opt,name=provider_chain_id,json=providerChainId,proto3" json:"provider_chain_id,omitempty"`
+	// Tendermint RPC address of the provider chain
+	ProviderRpcAddr string `protobuf:"bytes,2,opt,name=provider_rpc_addr,json=providerRpcAddr,proto3" json:"provider_rpc_addr,omitempty"`
+	// unbonding period of the consumer chain (e.g. "1209600s"), which is the CCV parameter of the consumer rather than the staking parameter
+	UnbondingPeriod string `protobuf:"bytes,3,opt,name=unbonding_period,json=unbondingPeriod,proto3" json:"unbonding_period,omitempty"`
+}
+
+func (m *ConsumerConfig) Reset()         { *m = ConsumerConfig{} }
+func (m *ConsumerConfig) String() string { return proto.CompactTextString(m) }
+func (*ConsumerConfig) ProtoMessage()    {}
+func (*ConsumerConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d67cd47cbc86ecb1, []int{1}
+}
+func (m *ConsumerConfig) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ConsumerConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ConsumerConfig.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ConsumerConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConsumerConfig.Merge(m, src)
+}
+func (m *ConsumerConfig) XXX_Size() int {
+	return m.Size()
+}
+func (m *ConsumerConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConsumerConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConsumerConfig proto.InternalMessageInfo
+
 type ProverConfig struct {
 	TrustingPeriod       string    `protobuf:"bytes,1,opt,name=trusting_period,json=trustingPeriod,proto3" json:"trusting_period,omitempty"`
 	RefreshThresholdRate *Fraction `protobuf:"bytes,2,opt,name=refresh_threshold_rate,json=refreshThresholdRate,proto3" json:"refresh_threshold_rate,omitempty"`
@@ -77,7 +150,7 @@ func (m *ProverConfig) Reset()         { *m = ProverConfig{} }
 func (m *ProverConfig) String() string { return proto.CompactTextString(m) }
 func (*ProverConfig) ProtoMessage()    {}
 func (*ProverConfig) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d67cd47cbc86ecb1, []int{1}
+	return fileDescriptor_d67cd47cbc86ecb1, []int{2}
 }
 func (m *ProverConfig) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -115,7 +188,7 @@ func (m *Fraction) Reset()         { *m = Fraction{} }
 func (m *Fraction) String() string { return proto.CompactTextString(m) }
 func (*Fraction) ProtoMessage()    {}
 func (*Fraction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_d67cd47cbc86ecb1, []int{2}
+	return fileDescriptor_d67cd47cbc86ecb1, []int{3}
 }
 func (m *Fraction) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -144,10 +217,57 @@ func (m *Fraction) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Fraction proto.InternalMessageInfo
 
+type RemoteSignerConfig struct {
+	// address of the signer: "unix:///path/to/socket" or "host:port" (TLS is required over TCP)
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// CA certificate to verify the signer over TCP
+	CaFile string `protobuf:"bytes,2,opt,name=ca_file,json=caFile,proto3" json:"ca_file,omitempty"`
+	// client certificate and key for mutual TLS
+	CertFile string `protobuf:"bytes,3,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"`
+	KeyFile  string `protobuf:"bytes,4,opt,name=key_file,json=keyFile,proto3" json:"key_file,omitempty"`
+	// timeout of each request to the signer (e.g. "10s"); defaults to 10s
+	Timeout string `protobuf:"bytes,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (m *RemoteSignerConfig) Reset()         { *m = RemoteSignerConfig{} }
+func (m *RemoteSignerConfig) String() string { return proto.CompactTextString(m) }
+func (*RemoteSignerConfig) ProtoMessage()    {}
+func (*RemoteSignerConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d67cd47cbc86ecb1, []int{4}
+}
+func (m *RemoteSignerConfig) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RemoteSignerConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RemoteSignerConfig.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RemoteSignerConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoteSignerConfig.Merge(m, src)
+}
+func (m *RemoteSignerConfig) XXX_Size() int {
+	return m.Size()
+}
+func (m *RemoteSignerConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoteSignerConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoteSignerConfig proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*ChainConfig)(nil), "relayer.chains.tendermint.config.ChainConfig")
+	proto.RegisterType((*ConsumerConfig)(nil), "relayer.chains.tendermint.config.ConsumerConfig")
 	proto.RegisterType((*ProverConfig)(nil), "relayer.chains.tendermint.config.ProverConfig")
 	proto.RegisterType((*Fraction)(nil), "relayer.chains.tendermint.config.Fraction")
+	proto.RegisterType((*RemoteSignerConfig)(nil), "relayer.chains.tendermint.config.RemoteSignerConfig")
 }
 
 func init() {
@@ -155,38 +275,62 @@ func init() {
 }
 
 var fileDescriptor_d67cd47cbc86ecb1 = []byte{
-	// 482 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0x41, 0x6b, 0x13, 0x41,
-	0x14, 0xc7, 0xb3, 0x6d, 0x6c, 0x93, 0x89, 0xad, 0xba, 0x04, 0x5d, 0x45, 0x97, 0x10, 0x10, 0x83,
-	0xd0, 0x5d, 0x50, 0x3c, 0x78, 0x6c, 0x03, 0x05, 0x05, 0x21, 0x2c, 0x05, 0xc1, 0xcb, 0x38, 0x99,
-	0x79, 0x99, 0x8c, 0xcd, 0xcc, 0x2c, 0x6f, 0x66, 0x4b, 0xf2, 0x2d, 0xbc, 0xfa, 0x15, 0xfc, 0x24,
-	0x3d, 0xf6, 0xe8, 0x51, 0x93, 0x2f, 0x22, 0x3b, 0xd9, 0x58, 0x2f, 0xe2, 0x69, 0x66, 0x7f, 0xff,
-	0xdf, 0x7b, 0x2c, 0x6f, 0x1e, 0x39, 0x41, 0x58, 0xb0, 0x15, 0x60, 0xce, 0xe7, 0x4c, 0x19, 0x97,
-	0x7b, 0x30, 0x02, 0x50, 0x2b, 0xe3, 0x73, 0x6e, 0xcd, 0x4c, 0xc9, 0xe6, 0xc8, 0x4a, 0xb4, 0xde,
-	0xc6, 0x83, 0x46, 0xcf, 0xb6, 0x7a, 0x76, 0xab, 0x67, 0x5b, 0xef, 0x49, 0x5f, 0x5a, 0x69, 0x83,
-	0x9c, 0xd7, 0xb7, 0x6d, 0xdd, 0xf0, 0xfb, 0x1e, 0xe9, 0x8d, 0xeb, 0x92, 0x71, 0xb0, 0xe2, 0xfb,
-	0x64, 0xff, 0x12, 0x56, 0x49, 0x34, 0x88, 0x46, 0xdd, 0xa2, 0xbe, 0xc6, 0x8f, 0x49, 0x27, 0xf4,
-	0xa4, 0x4a, 0x24, 0x7b, 0x01, 0x1f, 0x86, 0xef, 0x77, 0xa2, 0x8e, 0xb0, 0xe4, 0x94, 0x09, 0x81,
-	0xc9, 0xfe, 0x36, 0xc2, 0x92, 0x9f, 0x0a, 0x81, 0xf1, 0x73, 0x72, 0xcc, 0x38, 0xb7, 0x95, 0xf1,
-	0xb4, 0x44, 0x98, 0xa9, 0x65, 0xd2, 0x0e, 0xc2, 0x51, 0x43, 0x27, 0x01, 0xd6, 0x9a, 0x64, 0x8e,
-	0x32, 0xf1, 0xa5, 0x72, 0x5e, 0x83, 0xf1, 0xc9, 0x9d, 0x41, 0x34, 0x8a, 0x8a, 0x23, 0xc9, 0xdc,
-	0xe9, 0x1f, 0x18, 0x3f, 0x23, 0xa4, 0xd6, 0x4a, 0x54, 0x1c, 0x5c, 0x72, 0x10, 0x3a, 0x75, 0x25,
-	0x73, 0x93, 0x00, 0xe2, 0x37, 0xe4, 0x11, 0xbb, 0x02, 0x64, 0x12, 0xe8, 0x74, 0x61, 0xf9, 0x25,
-	0xf5, 0x4a, 0x03, 0xd5, 0x0e, 0x78, 0x72, 0x38, 0x88, 0x46, 0xed, 0xa2, 0xdf, 0xc4, 0x67, 0x75,
-	0x7a, 0xa1, 0x34, 0x7c, 0x70, 0xc0, 0xe3, 0x9c, 0xf4, 0x35, 0x5b, 0x52, 0x04, 0x8f, 0x2b, 0x3a,
-	0xb3, 0x48, 0xb9, 0xd5, 0x5a, 0xf9, 0xa4, 0x13, 0x6a, 0x1e, 0x68, 0xb6, 0x2c, 0xea, 0xe8, 0xdc,
-	0xe2, 0x38, 0x04, 0xc3, 0x6f, 0x11, 0xb9, 0x3b, 0x41, 0x7b, 0x05, 0xd8, 0x4c, 0xeb, 0x05, 0xb9,
-	0xe7, 0xb1, 0x72, 0x5e, 0x19, 0x49, 0x4b, 0x40, 0x65, 0x45, 0x33, 0xb9, 0xe3, 0x1d, 0x9e, 0x04,
-	0x1a, 0x7f, 0x26, 0x0f, 0x11, 0x66, 0x08, 0x6e, 0x4e, 0xfd, 0xbc, 0x3e, 0xec, 0x42, 0x50, 0x64,
-	0x1e, 0xc2, 0x48, 0x7b, 0xaf, 0x5e, 0x66, 0xff, 0x7b, 0xbf, 0xec, 0x1c, 0x19, 0xf7, 0xca, 0x9a,
-	0xa2, 0xdf, 0x74, 0xba, 0xd8, 0x35, 0x2a, 0x98, 0x87, 0xe1, 0x7b, 0xd2, 0xd9, 0x19, 0xf1, 0x53,
-	0xd2, 0x35, 0x95, 0x06, 0x64, 0xde, 0x62, 0xf8, 0xa1, 0x76, 0x71, 0x0b, 0xe2, 0x01, 0xe9, 0x09,
-	0x30, 0x56, 0x2b, 0x13, 0xf2, 0xbd, 0x90, 0xff, 0x8d, 0xce, 0x3e, 0x5e, 0xff, 0x4a, 0x5b, 0xd7,
-	0xeb, 0x34, 0xba, 0x59, 0xa7, 0xd1, 0xcf, 0x75, 0x1a, 0x7d, 0xdd, 0xa4, 0xad, 0x9b, 0x4d, 0xda,
-	0xfa, 0xb1, 0x49, 0x5b, 0x9f, 0xde, 0x4a, 0xe5, 0xe7, 0xd5, 0x34, 0xe3, 0x56, 0xe7, 0xf3, 0x55,
-	0x09, 0xb8, 0x00, 0x21, 0x01, 0x4f, 0x16, 0x6c, 0xea, 0xf2, 0x55, 0xa5, 0xfe, 0xbd, 0xb9, 0xd3,
-	0x83, 0xb0, 0x74, 0xaf, 0x7f, 0x07, 0x00, 0x00, 0xff, 0xff, 0x58, 0x5a, 0x44, 0xe1, 0xdd, 0x02,
-	0x00, 0x00,
+	// 868 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xcd, 0x6e, 0x1b, 0x37,
+	0x10, 0xf6, 0xda, 0xae, 0x25, 0x51, 0xb2, 0x6c, 0x33, 0x42, 0xc3, 0xf4, 0x47, 0x50, 0x5d, 0x14,
+	0x51, 0x03, 0x44, 0x2a, 0xd2, 0xf6, 0xd0, 0x63, 0x2c, 0xc0, 0x6d, 0x8a, 0x06, 0x10, 0x36, 0x06,
+	0x82, 0xf6, 0xc2, 0x52, 0xdc, 0xd1, 0x8a, 0xd5, 0xee, 0x72, 0x31, 0xe4, 0xaa, 0x52, 0x9f, 0xa2,
+	0x40, 0x4f, 0x7d, 0xa2, 0xe6, 0x98, 0x63, 0x8f, 0xad, 0xfd, 0x22, 0x05, 0xc9, 0x95, 0xe2, 0xa0,
+	0x08, 0x7c, 0x5a, 0xf2, 0xfb, 0xbe, 0x19, 0x0e, 0x67, 0x86, 0xb3, 0xe4, 0x31, 0x42, 0x26, 0x36,
+	0x80, 0x63, 0xb9, 0x10, 0xaa, 0x30, 0x63, 0x0b, 0x45, 0x02, 0x98, 0xab, 0xc2, 0x8e, 0xa5, 0x2e,
+	0xe6, 0x2a, 0xad, 0x3f, 0xa3, 0x12, 0xb5, 0xd5, 0x74, 0x50, 0xcb, 0x47, 0x41, 0x3e, 0x7a, 0x23,
+	0x1f, 0x05, 0xdd, 0x07, 0xbd, 0x54, 0xa7, 0xda, 0x8b, 0xc7, 0x6e, 0x15, 0xec, 0xce, 0xff, 0x3a,
+	0x22, 0xed, 0x89, 0x33, 0x99, 0x78, 0x15, 0x3d, 0x25, 0x07, 0x4b, 0xd8, 0xb0, 0x68, 0x10, 0x0d,
+	0x5b, 0xb1, 0x5b, 0xd2, 0x07, 0xa4, 0xe9, 0x7d, 0x72, 0x95, 0xb0, 0x7d, 0x0f, 0x37, 0xfc, 0xfe,
+	0x59, 0xe2, 0x28, 0x2c, 0x25, 0x17, 0x49, 0x82, 0xec, 0x20, 0x50, 0x58, 0xca, 0xa7, 0x49, 0x82,
+	0xf4, 0x33, 0xd2, 0x15, 0x52, 0xea, 0xaa, 0xb0, 0xbc, 0x44, 0x98, 0xab, 0x35, 0x3b, 0xf4, 0x82,
+	0xe3, 0x1a, 0x9d, 0x7a, 0xd0, 0xc9, 0x52, 0x61, 0xb8, 0x48, 0x7e, 0xa9, 0x8c, 0xcd, 0xa1, 0xb0,
+	0xec, 0xbd, 0x41, 0x34, 0x8c, 0xe2, 0xe3, 0x54, 0x98, 0xa7, 0x3b, 0x90, 0x7e, 0x4c, 0x88, 0x93,
+	0x95, 0xa8, 0x24, 0x18, 0x76, 0xe4, 0x3d, 0xb5, 0x52, 0x61, 0xa6, 0x1e, 0xa0, 0x5f, 0x93, 0xfb,
+	0x62, 0x05, 0x28, 0x52, 0xe0, 0xb3, 0x4c, 0xcb, 0x25, 0xb7, 0x2a, 0x07, 0x9e, 0x1b, 0x90, 0xac,
+	0x31, 0x88, 0x86, 0x87, 0x71, 0xaf, 0xa6, 0x2f, 0x1c, 0x7b, 0xa5, 0x72, 0x78, 0x6e, 0x40, 0xd2,
+	0x31, 0xe9, 0xe5, 0x62, 0xcd, 0x11, 0x2c, 0x6e, 0xf8, 0x5c, 0x23, 0x97, 0x3a, 0xcf, 0x95, 0x65,
+	0x4d, 0x6f, 0x73, 0x96, 0x8b, 0x75, 0xec, 0xa8, 0x4b, 0x8d, 0x13, 0x4f, 0xd0, 0x87, 0xe4, 0x64,
+	0x09, 0x1b, 0x54, 0x45, 0xca, 0x67, 0x42, 0x2e, 0xa1, 0x48, 0x58, 0xcb, 0xc7, 0xd2, 0xad, 0xe1,
+	0x8b, 0x80, 0xd2, 0x4f, 0x48, 0x07, 0x56, 0x50, 0x58, 0x6e, 0x74, 0x85, 0x12, 0x18, 0xf1, 0xaa,
+	0xb6, 0xc7, 0x5e, 0x78, 0x88, 0xfe, 0x40, 0x9a, 0x52, 0x17, 0xa6, 0xca, 0x01, 0x59, 0x7b, 0x10,
+	0x0d, 0xdb, 0x4f, 0xbe, 0x18, 0xdd, 0x55, 0xc3, 0xd1, 0xa4, 0xb6, 0x08, 0xc5, 0x8a, 0x77, 0x1e,
+	0x5c, 0x1e, 0x67, 0xa8, 0x45, 0x22, 0x85, 0xb1, 0x3c, 0xd7, 0x09, 0xb0, 0x4e, 0x48, 0xf7, 0x0e,
+	0x7d, 0xae, 0x13, 0xa0, 0x43, 0x72, 0x6a, 0x96, 0xaa, 0xac, 0x2f, 0xca, 0x7f, 0x15, 0xca, 0xb2,
+	0xe3, 0x41, 0x34, 0x6c, 0xc6, 0x5d, 0x87, 0x87, 0x6b, 0xbe, 0x14, 0xca, 0xd2, 0x1f, 0xc9, 0x31,
+	0x42, 0xae, 0x2d, 0x70, 0xa3, 0xd2, 0x02, 0x90, 0x75, 0x7d, 0x8c, 0x5f, 0xdd, 0x1d, 0x63, 0xec,
+	0xcd, 0x5e, 0x78, 0xab, 0x3a, 0xce, 0x0e, 0xde, 0xc2, 0xe8, 0xa7, 0xe4, 0x58, 0x54, 0x76, 0xf1,
+	0x1b, 0x4f, 0x51, 0x14, 0x16, 0x90, 0x9d, 0xf8, 0x50, 0x3b, 0x1e, 0xfc, 0x36, 0x60, 0x94, 0x92,
+	0xc3, 0x1c, 0x72, 0xcd, 0x4e, 0x3d, 0xe7, 0xd7, 0x74, 0x40, 0x3a, 0xae, 0x5e, 0x76, 0xcd, 0x67,
+	0x1b, 0x0b, 0x86, 0x9d, 0xf9, 0x3a, 0x91, 0x5c, 0xac, 0xaf, 0xd6, 0x17, 0x0e, 0x71, 0x0d, 0x69,
+	0xd7, 0x7c, 0xa1, 0xf5, 0xd2, 0x30, 0x3a, 0x38, 0x70, 0x0d, 0x69, 0xd7, 0xdf, 0xb9, 0x2d, 0x1d,
+	0x91, 0x7b, 0xa1, 0x24, 0xa5, 0xce, 0x32, 0xae, 0xdc, 0x21, 0x2b, 0x91, 0xb1, 0x7b, 0xde, 0xff,
+	0x99, 0xa7, 0xa6, 0x3a, 0xcb, 0x9e, 0xd5, 0x84, 0x6b, 0x0e, 0x89, 0xda, 0x18, 0x2e, 0x17, 0x20,
+	0x97, 0x7c, 0xd7, 0xe7, 0xbd, 0x60, 0xe0, 0xb9, 0x89, 0xa3, 0xe2, 0xd0, 0xf1, 0xe7, 0x7f, 0x44,
+	0xa4, 0xfb, 0x76, 0x7d, 0xe8, 0x23, 0x72, 0x56, 0xa2, 0x5e, 0xa9, 0x04, 0x90, 0xef, 0xde, 0x50,
+	0x78, 0x5a, 0x27, 0x5b, 0x62, 0x52, 0xbf, 0xa5, 0xdb, 0xda, 0xdd, 0x61, 0xfb, 0x6f, 0x6b, 0xeb,
+	0xa3, 0xe8, 0xe7, 0xe4, 0xb4, 0x2a, 0x66, 0xba, 0x48, 0x5c, 0x27, 0x96, 0x80, 0x4a, 0x27, 0xf5,
+	0xfb, 0x3b, 0xd9, 0xe1, 0x53, 0x0f, 0x9f, 0xff, 0x19, 0x91, 0xce, 0x14, 0xf5, 0x6a, 0x17, 0xd3,
+	0x43, 0x72, 0x62, 0xb1, 0x32, 0xf6, 0x96, 0x69, 0x88, 0xa8, 0xbb, 0x85, 0x83, 0x25, 0xfd, 0x99,
+	0xbc, 0x8f, 0x30, 0x47, 0x30, 0x0b, 0x6e, 0x17, 0xee, 0xa3, 0xb3, 0x84, 0xa3, 0xb0, 0xe0, 0xa3,
+	0x6a, 0x3f, 0x79, 0x74, 0x77, 0x2b, 0x5c, 0xa2, 0x90, 0x56, 0xe9, 0x22, 0xee, 0xd5, 0x9e, 0xae,
+	0xb6, 0x8e, 0x62, 0x61, 0xe1, 0xfc, 0x7b, 0xd2, 0xdc, 0x2a, 0xe8, 0x47, 0xa4, 0x55, 0xb8, 0xcc,
+	0x09, 0xab, 0xd1, 0x07, 0x74, 0x18, 0xbf, 0x01, 0xe8, 0x80, 0xb4, 0x13, 0x28, 0x74, 0xae, 0x0a,
+	0xcf, 0xef, 0x7b, 0xfe, 0x36, 0xe4, 0xee, 0x49, 0xff, 0xdf, 0x79, 0x94, 0x91, 0x86, 0x4b, 0x24,
+	0x18, 0x53, 0xdf, 0x72, 0xbb, 0xa5, 0xf7, 0x49, 0x43, 0x0a, 0x3e, 0x57, 0x19, 0xd4, 0x59, 0x3e,
+	0x92, 0xe2, 0x52, 0x65, 0x40, 0x3f, 0x24, 0x2d, 0x09, 0x68, 0x03, 0x15, 0xb2, 0xda, 0x74, 0x80,
+	0x27, 0x1f, 0x90, 0xe6, 0x12, 0x36, 0x81, 0x0b, 0x03, 0xad, 0xb1, 0x84, 0x8d, 0xa7, 0x18, 0x69,
+	0xb8, 0xb1, 0xa3, 0xab, 0x30, 0xc3, 0x5a, 0xf1, 0x76, 0x7b, 0xf1, 0xf2, 0xd5, 0xbf, 0xfd, 0xbd,
+	0x57, 0xd7, 0xfd, 0xe8, 0xf5, 0x75, 0x3f, 0xfa, 0xe7, 0xba, 0x1f, 0xfd, 0x7e, 0xd3, 0xdf, 0x7b,
+	0x7d, 0xd3, 0xdf, 0xfb, 0xfb, 0xa6, 0xbf, 0xf7, 0xd3, 0x37, 0xa9, 0xb2, 0x8b, 0x6a, 0x36, 0x92,
+	0x3a, 0x1f, 0x2f, 0x36, 0x25, 0x60, 0x06, 0x49, 0x0a, 0xf8, 0x38, 0x13, 0x33, 0x33, 0xde, 0x54,
+	0xea, 0xdd, 0x3f, 0x82, 0xd9, 0x91, 0x9f, 0xe1, 0x5f, 0xfe, 0x37, 0x00, 0x16, 0xa4, 0xa0, 0x16,
+	0x2c, 0x06, 0x00, 0x00,
 }
 
 func (m *ChainConfig) Marshal() (dAtA []byte, err error) {
@@ -209,6 +353,113 @@ func (m *ChainConfig) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.CrossCheckRpcAddr) > 0 {
+		i -= len(m.CrossCheckRpcAddr)
+		copy(dAtA[i:], m.CrossCheckRpcAddr)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.CrossCheckRpcAddr)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xa2
+	}
+	if len(m.EventPollInterval) > 0 {
+		i -= len(m.EventPollInterval)
+		copy(dAtA[i:], m.EventPollInterval)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.EventPollInterval)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x9a
+	}
+	if len(m.TxHooks) > 0 {
+		for iNdEx := len(m.TxHooks) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TxHooks[iNdEx])
+			copy(dAtA[i:], m.TxHooks[iNdEx])
+			i = encodeVarintConfig(dAtA, i, uint64(len(m.TxHooks[iNdEx])))
+			i--
+			dAtA[i] = 0x1
+			i--
+			dAtA[i] = 0x92
+		}
+	}
+	if m.MaxTxBytes != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.MaxTxBytes))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x88
+	}
+	if len(m.Memo) > 0 {
+		i -= len(m.Memo)
+		copy(dAtA[i:], m.Memo)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Memo)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	if len(m.AuthzGranter) > 0 {
+		i -= len(m.AuthzGranter)
+		copy(dAtA[i:], m.AuthzGranter)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.AuthzGranter)))
+		i--
+		dAtA[i] = 0x7a
+	}
+	if m.RemoteSigner != nil {
+		{
+			size, err := m.RemoteSigner.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x72
+	}
+	if m.SkipCommitWait {
+		i--
+		if m.SkipCommitWait {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x68
+	}
+	if len(m.BroadcastMode) > 0 {
+		i -= len(m.BroadcastMode)
+		copy(dAtA[i:], m.BroadcastMode)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.BroadcastMode)))
+		i--
+		dAtA[i] = 0x62
+	}
+	if m.Consumer != nil {
+		{
+			size, err := m.Consumer.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConfig(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5a
+	}
+	if len(m.EventSource) > 0 {
+		i -= len(m.EventSource)
+		copy(dAtA[i:], m.EventSource)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.EventSource)))
+		i--
+		dAtA[i] = 0x52
+	}
+	if len(m.KeyringBackend) > 0 {
+		i -= len(m.KeyringBackend)
+		copy(dAtA[i:], m.KeyringBackend)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.KeyringBackend)))
+		i--
+		dAtA[i] = 0x4a
+	}
 	if m.MaxRetryForCommit != 0 {
 		i = encodeVarintConfig(dAtA, i, uint64(m.MaxRetryForCommit))
 		i--
@@ -263,6 +514,50 @@ func (m *ChainConfig) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *ConsumerConfig) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ConsumerConfig) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ConsumerConfig) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.UnbondingPeriod) > 0 {
+		i -= len(m.UnbondingPeriod)
+		copy(dAtA[i:], m.UnbondingPeriod)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.UnbondingPeriod)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ProviderRpcAddr) > 0 {
+		i -= len(m.ProviderRpcAddr)
+		copy(dAtA[i:], m.ProviderRpcAddr)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.ProviderRpcAddr)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.ProviderChainId) > 0 {
+		i -= len(m.ProviderChainId)
+		copy(dAtA[i:], m.ProviderChainId)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.ProviderChainId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *ProverConfig) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -338,6 +633,64 @@ func (m *Fraction) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RemoteSignerConfig) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RemoteSignerConfig) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RemoteSignerConfig) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Timeout) > 0 {
+		i -= len(m.Timeout)
+		copy(dAtA[i:], m.Timeout)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Timeout)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.KeyFile) > 0 {
+		i -= len(m.KeyFile)
+		copy(dAtA[i:], m.KeyFile)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.KeyFile)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.CertFile) > 0 {
+		i -= len(m.CertFile)
+		copy(dAtA[i:], m.CertFile)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.CertFile)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.CaFile) > 0 {
+		i -= len(m.CaFile)
+		copy(dAtA[i:], m.CaFile)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.CaFile)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Address) > 0 {
+		i -= len(m.Address)
+		copy(dAtA[i:], m.Address)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.Address)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintConfig(dAtA []byte, offset int, v uint64) int {
 	offset -= sovConfig(v)
 	base := offset
@@ -384,6 +737,75 @@ func (m *ChainConfig) Size() (n int) {
 	if m.MaxRetryForCommit != 0 {
 		n += 1 + sovConfig(uint64(m.MaxRetryForCommit))
 	}
+	l = len(m.KeyringBackend)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.EventSource)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.Consumer != nil {
+		l = m.Consumer.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.BroadcastMode)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.SkipCommitWait {
+		n += 2
+	}
+	if m.RemoteSigner != nil {
+		l = m.RemoteSigner.Size()
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.AuthzGranter)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Memo)
+	if l > 0 {
+		n += 2 + l + sovConfig(uint64(l))
+	}
+	if m.MaxTxBytes != 0 {
+		n += 2 + sovConfig(uint64(m.MaxTxBytes))
+	}
+	if len(m.TxHooks) > 0 {
+		for _, s := range m.TxHooks {
+			l = len(s)
+			n += 2 + l + sovConfig(uint64(l))
+		}
+	}
+	l = len(m.EventPollInterval)
+	if l > 0 {
+		n += 2 + l + sovConfig(uint64(l))
+	}
+	l = len(m.CrossCheckRpcAddr)
+	if l > 0 {
+		n += 2 + l + sovConfig(uint64(l))
+	}
+	return n
+}
+
+func (m *ConsumerConfig) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ProviderChainId)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.ProviderRpcAddr)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.UnbondingPeriod)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
 	return n
 }
 
@@ -419,6 +841,35 @@ func (m *Fraction) Size() (n int) {
 	return n
 }
 
+func (m *RemoteSignerConfig) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.CaFile)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.CertFile)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.KeyFile)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.Timeout)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	return n
+}
+
 func sovConfig(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -663,25 +1114,538 @@ func (m *ChainConfig) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipConfig(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyringBackend", wireType)
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthConfig
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
+			m.KeyringBackend = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EventSource", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EventSource = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Consumer", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Consumer == nil {
+				m.Consumer = &ConsumerConfig{}
+			}
+			if err := m.Consumer.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BroadcastMode", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BroadcastMode = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SkipCommitWait", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SkipCommitWait = bool(v != 0)
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RemoteSigner", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RemoteSigner == nil {
+				m.RemoteSigner = &RemoteSignerConfig{}
+			}
+			if err := m.RemoteSigner.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthzGranter", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AuthzGranter = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Memo", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Memo = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTxBytes", wireType)
+			}
+			m.MaxTxBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTxBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 18:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TxHooks", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TxHooks = append(m.TxHooks, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 19:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EventPollInterval", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EventPollInterval = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 20:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CrossCheckRpcAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CrossCheckRpcAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ConsumerConfig) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ConsumerConfig: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ConsumerConfig: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProviderChainId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProviderChainId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProviderRpcAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProviderRpcAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnbondingPeriod", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.UnbondingPeriod = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
 	return nil
 }
 func (m *ProverConfig) Unmarshal(dAtA []byte) error {
@@ -890,6 +1854,216 @@ func (m *Fraction) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *RemoteSignerConfig) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConfig
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RemoteSignerConfig: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RemoteSignerConfig: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CaFile", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CaFile = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CertFile", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CertFile = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyFile", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeyFile = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Timeout = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConfig(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipConfig(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
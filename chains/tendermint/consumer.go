@@ -0,0 +1,143 @@
+package tendermint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tmproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+)
+
+// Consumer chains of Interchain Security don't have the staking module because their validator set is sourced from the provider chain.
+// So the validator sets are queried from the Tendermint RPC of the consumer chain instead of the staking module,
+// and the unbonding period is taken from the config and checked against the provider chain.
+
+// validatorsPerPage is the max number of validators returned by a `validators` RPC request
+const validatorsPerPage = 100
+
+func (c ConsumerConfig) Validate(chainID string) error {
+	if strings.TrimSpace(c.ProviderChainId) == "" {
+		return fmt.Errorf("config attribute \"consumer.provider_chain_id\" is empty")
+	}
+	if c.ProviderChainId == chainID {
+		return fmt.Errorf("config attribute \"consumer.provider_chain_id\" must differ from the chain ID: %s", chainID)
+	}
+	if strings.TrimSpace(c.ProviderRpcAddr) == "" {
+		return fmt.Errorf("config attribute \"consumer.provider_rpc_addr\" is empty")
+	}
+	if d, err := time.ParseDuration(c.UnbondingPeriod); err != nil {
+		return fmt.Errorf("config attribute \"consumer.unbonding_period\" is invalid: %v", err)
+	} else if d <= 0 {
+		return fmt.Errorf("config attribute \"consumer.unbonding_period\" must be positive: %v", d)
+	}
+	return nil
+}
+
+func (c ConsumerConfig) GetUnbondingPeriod() time.Duration {
+	if d, err := time.ParseDuration(c.UnbondingPeriod); err != nil {
+		panic(err)
+	} else {
+		return d
+	}
+}
+
+// IsConsumer returns true if the chain is a consumer chain of Interchain Security
+func (c *Chain) IsConsumer() bool {
+	return c.config.Consumer != nil
+}
+
+// queryConsumerUnbondingPeriod returns the unbonding period of the consumer chain after checking the provider relationship.
+// The unbonding period of a consumer chain must be shorter than the one of the provider chain,
+// otherwise a misbehaviour of the validators on the consumer chain could be left unpunished on the provider chain.
+func (c *Chain) queryConsumerUnbondingPeriod() (time.Duration, error) {
+	consumer := c.config.Consumer
+	client, err := newRPCClient(consumer.ProviderRpcAddr, c.timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := client.Status(context.TODO())
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the status of the provider chain: %w", err)
+	}
+	if network := status.NodeInfo.Network; network != consumer.ProviderChainId {
+		return 0, fmt.Errorf("provider RPC %s serves %s, not the provider chain %s", consumer.ProviderRpcAddr, network, consumer.ProviderChainId)
+	}
+
+	req, err := (&stakingtypes.QueryParamsRequest{}).Marshal()
+	if err != nil {
+		return 0, err
+	}
+	res, err := client.ABCIQuery(context.TODO(), "/cosmos.staking.v1beta1.Query/Params", req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the staking params of the provider chain: %w", err)
+	} else if !res.Response.IsOK() {
+		return 0, fmt.Errorf("failed to query the staking params of the provider chain: %s", res.Response.Log)
+	}
+	var params stakingtypes.QueryParamsResponse
+	if err := params.Unmarshal(res.Response.Value); err != nil {
+		return 0, err
+	}
+
+	ubdPeriod := consumer.GetUnbondingPeriod()
+	if providerPeriod := params.Params.UnbondingTime; ubdPeriod >= providerPeriod {
+		return 0, fmt.Errorf("unbonding period of the consumer chain %s (%v) must be less than the one of the provider chain %s (%v)",
+			c.ChainID(), ubdPeriod, consumer.ProviderChainId, providerPeriod)
+	}
+	return ubdPeriod, nil
+}
+
+// queryValsetFromRPC returns the validator set at a given height from the Tendermint RPC
+func (c *Chain) queryValsetFromRPC(height clienttypes.Height) (*tmproto.ValidatorSet, error) {
+	h := int64(height.GetRevisionHeight())
+	var vals []*tmtypes.Validator
+	for page := 1; ; page++ {
+		p, perPage := page, validatorsPerPage
+		res, err := c.Client.Validators(context.TODO(), &h, &p, &perPage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query validators: error=%w height=%v", err, height)
+		}
+		vals = append(vals, res.Validators...)
+		if len(res.Validators) == 0 || len(vals) >= res.Total {
+			break
+		}
+	}
+
+	// the validators are returned in the order of the validator set, which decides its hash
+	tmValSet := &tmtypes.ValidatorSet{
+		Validators: vals,
+	}
+	tmValSet.GetProposer()
+
+	protoValSet, err := tmValSet.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	protoValSet.TotalVotingPower = tmValSet.TotalVotingPower()
+	return protoValSet, nil
+}
+
+// checkConsumerHeader checks that the header, from which the consensus state is constructed, is signed by
+// the validator set of the consumer chain replicated from the provider chain rather than the one of the provider chain.
+// The validator set in the header itself is checked against the header by the header's ValidateBasic.
+func checkConsumerHeader(chain CosmosChain, header *tmclient.Header) error {
+	height := header.GetHeight().(clienttypes.Height)
+	protoValSet, err := chain.QueryValsetAtHeight(height)
+	if err != nil {
+		return err
+	}
+	valSet, err := tmtypes.ValidatorSetFromProto(protoValSet)
+	if err != nil {
+		return err
+	}
+	if hash := valSet.Hash(); !bytes.Equal(hash, header.Header.ValidatorsHash) {
+		return fmt.Errorf("hash of the validator set at height %v (%X) doesn't match the validators hash in the header (%X)", height, hash, header.Header.ValidatorsHash)
+	}
+	return nil
+}
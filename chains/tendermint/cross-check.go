@@ -0,0 +1,53 @@
+package tendermint
+
+import (
+	"bytes"
+	"fmt"
+
+	sdkCtx "github.com/cosmos/cosmos-sdk/client"
+	ibcclient "github.com/cosmos/ibc-go/v7/modules/core/client"
+)
+
+// CrossCheckingChain is an optional interface of CosmosChain that has a second RPC endpoint to cross-check the query results
+type CrossCheckingChain interface {
+	// CrossCheckCLIContext returns the client context of the second RPC endpoint, or false if the cross-check is disabled
+	CrossCheckCLIContext(height int64) (sdkCtx.Context, bool)
+}
+
+var _ CrossCheckingChain = (*Chain)(nil)
+
+// CrossCheckCLIContext implements CrossCheckingChain
+func (c *Chain) CrossCheckCLIContext(height int64) (sdkCtx.Context, bool) {
+	if c.crossCheckClient == nil {
+		return sdkCtx.Context{}, false
+	}
+	return c.CLIContext(height).
+		WithNodeURI(c.config.CrossCheckRpcAddr).
+		WithClient(c.crossCheckClient), true
+}
+
+// crossCheckState queries the state at `path` from the second RPC endpoint of the chain and checks that
+// both the value and the proof agree with the ones obtained from the primary endpoint.
+// It does nothing if the chain has no second endpoint.
+func crossCheckState(chain CosmosChain, height int64, path string, value, proof []byte) error {
+	cc, ok := chain.(CrossCheckingChain)
+	if !ok {
+		return nil
+	}
+	clientCtx, ok := cc.CrossCheckCLIContext(height)
+	if !ok {
+		return nil
+	}
+	v, p, _, err := ibcclient.QueryTendermintProof(clientCtx, []byte(path))
+	if err != nil {
+		return fmt.Errorf("failed to cross-check the state at %s with %s: %w", path, clientCtx.NodeURI, err)
+	}
+	if !bytes.Equal(v, value) {
+		return fmt.Errorf("cross-check of the state at %s failed: the value from %s disagrees: %x != %x", path, clientCtx.NodeURI, v, value)
+	}
+	// the proofs are deterministic for the same state at the same height, so a different proof means a different commitment root
+	if !bytes.Equal(p, proof) {
+		return fmt.Errorf("cross-check of the state at %s failed: the proof from %s disagrees", path, clientCtx.NodeURI)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/errors"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var _ core.FundsSender = (*Chain)(nil)
+
+// SendFunds implements core.FundsSender.
+// The tx is signed by the key `fromKey` in the keyring of the chain, which pays the fee, without the tx hooks and x/authz of the relay msgs.
+func (c *Chain) SendFunds(ctx context.Context, fromKey string, to sdk.AccAddress, amount sdk.Coins) (string, error) {
+	info, err := c.Keybase.Key(fromKey)
+	if err != nil {
+		return "", fmt.Errorf("key %s is not found in the keyring: %w", fromKey, err)
+	}
+	done := c.UseSDKContext()
+	from, err := info.GetAddress()
+	if err != nil {
+		done()
+		return "", err
+	}
+	msg := banktypes.NewMsgSend(from, to, amount)
+	done()
+
+	cliCtx := c.CLIContext(0).WithFrom(fromKey).WithFromName(fromKey).WithFromAddress(from)
+	txf, err := prepareFactory(cliCtx, c.TxFactory(0))
+	if err != nil {
+		return "", err
+	}
+	_, adjusted, err := CalculateGas(cliCtx.QueryWithData, txf, msg)
+	if err != nil {
+		return "", err
+	}
+	txf = txf.WithGas(adjusted)
+	txb, err := txf.BuildUnsignedTx(msg)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Sign(txf, fromKey, txb, false); err != nil {
+		return "", err
+	}
+	txBytes, err := cliCtx.TxConfig.TxEncoder()(txb.GetTx())
+	if err != nil {
+		return "", err
+	}
+	res, err := cliCtx.BroadcastTx(txBytes)
+	if err != nil {
+		return "", err
+	} else if res.Code != 0 {
+		return "", fmt.Errorf("%w: CheckTx failed: %v", core.ErrTxRejected, errors.ABCIError(res.Codespace, res.Code, res.RawLog))
+	}
+
+	resTx, err := c.waitForCommit(res.TxHash)
+	if err != nil {
+		return "", err
+	} else if resTx.TxResult.IsErr() {
+		return "", fmt.Errorf("%w: DeliverTx failed: %v", core.ErrTxRejected, errors.ABCIError(resTx.TxResult.Codespace, resTx.TxResult.Code, resTx.TxResult.Log))
+	}
+	return res.TxHash, nil
+}
@@ -0,0 +1,99 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	feetypes "github.com/cosmos/ibc-go/v7/modules/apps/29-fee/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var _ core.FeeDenomsProvider = (*Chain)(nil)
+
+// parseGasPrices parses `gas_prices` of the chain config.
+// A denom of the gas prices may be an IBC voucher on chains with a fee abstraction module, which is given either as
+// the IBC denom (e.g. "ibc/27394FB0...") or as the denom trace (e.g. "transfer/channel-0/uatom") converted to the IBC denom.
+func parseGasPrices(gasPrices string) (sdk.DecCoins, error) {
+	prices, err := sdk.ParseDecCoins(gasPrices)
+	if err != nil {
+		return nil, err
+	}
+	var resolved sdk.DecCoins
+	for _, price := range prices {
+		if strings.Contains(price.Denom, "/") && !strings.HasPrefix(price.Denom, transfertypes.DenomPrefix+"/") {
+			trace := transfertypes.ParseDenomTrace(price.Denom)
+			if err := trace.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid denom trace of the gas price %s: %w", price.Denom, err)
+			}
+			price.Denom = trace.IBCDenom()
+		}
+		resolved = resolved.Add(price)
+	}
+	return resolved, nil
+}
+
+// FeeDenoms implements core.FeeDenomsProvider
+func (c *Chain) FeeDenoms() []string {
+	var denoms []string
+	for _, price := range c.gasPrices {
+		denoms = append(denoms, price.Denom)
+	}
+	return denoms
+}
+
+// checkFeeBalance returns an error if the relayer account can't pay the fee of a tx consuming `gas`,
+// so that a tx is not broadcasted in vain on a chain where the relayer holds only bridged assets running out
+func (c *Chain) checkFeeBalance(gas uint64) error {
+	addr, err := c.keyAddress()
+	if err != nil {
+		return err
+	}
+	queryClient := bankTypes.NewQueryClient(c.CLIContext(0))
+	glDec := sdk.NewDec(int64(gas))
+	for _, price := range c.gasPrices {
+		fee := price.Amount.Mul(glDec).Ceil().RoundInt()
+		res, err := queryClient.Balance(context.Background(), bankTypes.NewQueryBalanceRequest(addr, price.Denom))
+		if err != nil {
+			return fmt.Errorf("failed to query the balance in the fee denom %s: %w", price.Denom, err)
+		}
+		if res.Balance.Amount.LT(fee) {
+			return fmt.Errorf("insufficient balance in the fee denom: address=%s balance=%s fee=%s", addr, res.Balance, sdk.NewCoin(price.Denom, fee))
+		}
+	}
+	return nil
+}
+
+// feesEarned sums up the ICS-29 relayer fees distributed to `receiver` by the events of a tx
+func feesEarned(events []abci.Event, receiver string) sdk.Coins {
+	var earned sdk.Coins
+	for _, ev := range events {
+		if ev.Type != feetypes.EventTypeDistributeFee {
+			continue
+		}
+		var to, fee string
+		for _, attr := range ev.Attributes {
+			switch attr.Key {
+			case feetypes.AttributeKeyReceiver:
+				to = attr.Value
+			case feetypes.AttributeKeyFee:
+				fee = attr.Value
+			}
+		}
+		if to != receiver || fee == "" {
+			continue
+		}
+		coins, err := sdk.ParseCoinsNormalized(fee)
+		if err != nil {
+			GetChainLogger().Error("failed to parse the distributed fee", err, "fee", fee)
+			continue
+		}
+		earned = earned.Add(coins...)
+	}
+	return earned
+}
@@ -0,0 +1,27 @@
+package tendermint
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var _ core.GasEstimator = (*Chain)(nil)
+
+// EstimateGas implements core.GasEstimator.
+// The msgs are simulated in the same way as they are submitted, so the estimation includes the authz wrapping and the gas adjustment.
+func (c *Chain) EstimateGas(msgs []sdk.Msg) (uint64, error) {
+	ctx := c.CLIContext(0)
+	msgs, err := c.wrapAuthz(msgs)
+	if err != nil {
+		return 0, err
+	}
+	txf, err := prepareFactory(ctx, c.TxFactory(0))
+	if err != nil {
+		return 0, err
+	}
+	_, adjusted, err := CalculateGas(ctx.QueryWithData, txf, msgs...)
+	if err != nil {
+		return 0, err
+	}
+	return adjusted, nil
+}
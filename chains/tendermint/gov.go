@@ -0,0 +1,125 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var _ core.ClientSubstituteProposer = (*Chain)(nil)
+
+// SubmitClientSubstituteProposal implements core.ClientSubstituteProposer.
+// The legacy ClientUpdateProposal is submitted as a gov v1 proposal executed by the gov module.
+func (c *Chain) SubmitClientSubstituteProposal(ctx context.Context, proposal core.ClientSubstituteProposal) (uint64, error) {
+	proposer, err := c.GetAddress()
+	if err != nil {
+		return 0, err
+	}
+	deposit := proposal.Deposit
+	if deposit.IsZero() {
+		if deposit, err = c.queryMinDeposit(ctx); err != nil {
+			return 0, err
+		}
+	}
+	content := clienttypes.NewClientUpdateProposal(proposal.Title, proposal.Summary, proposal.SubjectClientID, proposal.SubstituteClientID)
+	authority := authtypes.NewModuleAddress(govtypes.ModuleName).String()
+	exec, err := govv1.NewLegacyContent(content, authority)
+	if err != nil {
+		return 0, err
+	}
+	msg, err := govv1.NewMsgSubmitProposal([]sdk.Msg{exec}, deposit, proposer.String(), "", proposal.Title, proposal.Summary)
+	if err != nil {
+		return 0, err
+	}
+
+	results, err := c.SendMsgs([]sdk.Msg{msg})
+	if err != nil {
+		return 0, err
+	}
+	msgID, ok := results[0].MsgID().(*MsgID)
+	if !ok {
+		return 0, fmt.Errorf("unexpected message id type: %T", results[0].MsgID())
+	}
+	resTx, err := c.waitForCommit(msgID.TxHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the tx submitting the proposal: %w", err)
+	}
+	if resTx.TxResult.Code != 0 {
+		return 0, fmt.Errorf("the tx submitting the proposal failed: %s", resTx.TxResult.Log)
+	}
+	for _, ev := range resTx.TxResult.Events {
+		if ev.Type != govtypes.EventTypeSubmitProposal {
+			continue
+		}
+		for _, attr := range ev.Attributes {
+			if attr.Key == govtypes.AttributeKeyProposalID {
+				return strconv.ParseUint(attr.Value, 10, 64)
+			}
+		}
+	}
+	return 0, fmt.Errorf("the proposal ID is not found in the events of the tx %s", msgID.TxHash)
+}
+
+// DepositToProposal implements core.ClientSubstituteProposer
+func (c *Chain) DepositToProposal(ctx context.Context, proposalID uint64, amount sdk.Coins) error {
+	depositor, err := c.GetAddress()
+	if err != nil {
+		return err
+	}
+	results, err := c.SendMsgs([]sdk.Msg{govv1.NewMsgDeposit(depositor, proposalID, amount)})
+	if err != nil {
+		return err
+	}
+	res, err := core.ResolveMsgResult(c, results[0])
+	if err != nil {
+		return err
+	}
+	if ok, reason := res.Status(); !ok {
+		return fmt.Errorf("the deposit to the proposal %d failed: %s", proposalID, reason)
+	}
+	return nil
+}
+
+// QueryProposalStatus implements core.ClientSubstituteProposer
+func (c *Chain) QueryProposalStatus(ctx context.Context, proposalID uint64) (*core.ProposalStatus, error) {
+	res, err := govv1.NewQueryClient(c.CLIContext(0)).Proposal(ctx, &govv1.QueryProposalRequest{ProposalId: proposalID})
+	if err != nil {
+		return nil, err
+	}
+	minDeposit, err := c.queryMinDeposit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p := res.Proposal
+	return &core.ProposalStatus{
+		ProposalID:   p.Id,
+		Status:       p.Status.String(),
+		TotalDeposit: p.TotalDeposit,
+		MinDeposit:   minDeposit,
+		DepositEnd:   p.DepositEndTime,
+		VotingEnd:    p.VotingEndTime,
+	}, nil
+}
+
+// queryMinDeposit returns the minimum deposit for a proposal to enter the voting period
+func (c *Chain) queryMinDeposit(ctx context.Context) (sdk.Coins, error) {
+	res, err := govv1.NewQueryClient(c.CLIContext(0)).Params(ctx, &govv1.QueryParamsRequest{ParamsType: govv1.ParamDeposit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the deposit params of the gov module: %w", err)
+	}
+	switch {
+	case res.Params != nil:
+		return res.Params.MinDeposit, nil
+	case res.DepositParams != nil: //nolint:staticcheck // the chains before v0.47 only return the deprecated field
+		return res.DepositParams.MinDeposit, nil //nolint:staticcheck
+	default:
+		return nil, fmt.Errorf("the deposit params of the gov module are empty")
+	}
+}
@@ -0,0 +1,63 @@
+package tendermint
+
+import (
+	"io"
+	"os"
+
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// envKeyringPassphrase is the environment variable to pass the passphrase of the "file" keyring backend non-interactively
+const envKeyringPassphrase = "YRLY_KEYRING_PASSPHRASE"
+
+// keyringBackend returns the keyring backend configured for the chain. It defaults to "test" for backward compatibility.
+func (c ChainConfig) keyringBackend() string {
+	if c.KeyringBackend == "" {
+		return keys.BackendTest
+	}
+	return c.KeyringBackend
+}
+
+// keyringInput returns the reader from which the keyring reads the passphrase.
+// If YRLY_KEYRING_PASSPHRASE is set, the passphrase is answered to every prompt
+// (the keyring asks for it twice when the keystore is created), otherwise it is read from stdin.
+func keyringInput() io.Reader {
+	if pass, ok := os.LookupEnv(envKeyringPassphrase); ok {
+		return &repeatReader{line: []byte(pass + "\n")}
+	}
+	return os.Stdin
+}
+
+// repeatReader is an io.Reader that yields `line` endlessly
+type repeatReader struct {
+	line []byte
+	off  int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.line[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.line)
+	}
+	return n, nil
+}
+
+func isValidKeyringBackend(backend string) bool {
+	switch backend {
+	case "", keys.BackendTest, keys.BackendFile, keys.BackendOS:
+		return true
+	default:
+		return false
+	}
+}
+
+var _ core.AttestationSigner = (*Chain)(nil)
+
+// SignAttestation implements core.AttestationSigner with the relayer key in the keyring
+func (c *Chain) SignAttestation(msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	return c.Keybase.Sign(c.config.Key, msg)
+}
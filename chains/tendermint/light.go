@@ -35,7 +35,7 @@ var ErrLightNotInitialized = errors.New("light client is not initialized")
 func (pr *Prover) LightClient(db dbm.DB) (*light.Client, error) {
 	prov := pr.LightHTTP()
 	return light.NewClientFromTrustedStore(
-		pr.chain.config.ChainId,
+		pr.chain.ChainID(),
 		pr.getTrustingPeriod(),
 		prov,
 		// TODO: provide actual witnesses!
@@ -48,7 +48,7 @@ func (pr *Prover) LightClient(db dbm.DB) (*light.Client, error) {
 
 // LightHTTP returns the http client for light clients
 func (pr *Prover) LightHTTP() lightp.Provider {
-	cl, err := lighthttp.New(pr.chain.config.ChainId, pr.chain.config.RpcAddr)
+	cl, err := lighthttp.New(pr.chain.ChainID(), pr.chain.RPCAddr())
 	if err != nil {
 		panic(err)
 	}
@@ -56,9 +56,8 @@ func (pr *Prover) LightHTTP() lightp.Provider {
 }
 
 func (pr *Prover) NewLightDB() (db *dbm.GoLevelDB, df func(), err error) {
-	c := pr.chain
 	if err := retry.Do(func() error {
-		db, err = dbm.NewGoLevelDB(c.config.ChainId, lightDir(c.HomePath))
+		db, err = dbm.NewGoLevelDB(pr.chain.ChainID(), lightDir(pr.homePath))
 		if err != nil {
 			return fmt.Errorf("can't open light client database: %w", err)
 		}
@@ -79,7 +78,7 @@ func (pr *Prover) NewLightDB() (db *dbm.GoLevelDB, df func(), err error) {
 
 // DeleteLightDB removes the light client database on disk, forcing re-initialization
 func (pr *Prover) DeleteLightDB() error {
-	return os.RemoveAll(filepath.Join(lightDir(pr.chain.HomePath), fmt.Sprintf("%s.db", pr.chain.ChainID())))
+	return os.RemoveAll(filepath.Join(lightDir(pr.homePath), fmt.Sprintf("%s.db", pr.chain.ChainID())))
 }
 
 // LightClientWithTrust takes a header from the chain and attempts to add that header to the light
@@ -88,7 +87,7 @@ func (pr *Prover) LightClientWithTrust(db dbm.DB, to light.TrustOptions) (*light
 	prov := pr.LightHTTP()
 	return light.NewClient(
 		context.Background(),
-		pr.chain.config.ChainId,
+		pr.chain.ChainID(),
 		to,
 		prov,
 		// TODO: provide actual witnesses!
@@ -135,7 +134,7 @@ func (pr *Prover) LightClientWithoutTrust(db dbm.DB) (*light.Client, error) {
 	}
 	return light.NewClient(
 		context.Background(),
-		pr.chain.config.ChainId,
+		pr.chain.ChainID(),
 		light.TrustOptions{
 			Period: pr.getTrustingPeriod(),
 			Height: height,
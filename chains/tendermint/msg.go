@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -16,12 +17,19 @@ import (
 
 var (
 	_ core.MsgID     = (*MsgID)(nil)
+	_ core.TxMsgID   = (*MsgID)(nil)
 	_ core.MsgResult = (*MsgResult)(nil)
 )
 
 func (*MsgID) Is_MsgID() {}
 
+// TxID implements core.TxMsgID
+func (id *MsgID) TxID() string {
+	return id.TxHash
+}
+
 type MsgResult struct {
+	msgID  *MsgID
 	height clienttypes.Height
 
 	// These show the status of the tx that contains the message.
@@ -32,6 +40,10 @@ type MsgResult struct {
 	events []core.MsgEventLog
 }
 
+func (r *MsgResult) MsgID() core.MsgID {
+	return r.msgID
+}
+
 func (r *MsgResult) BlockHeight() clienttypes.Height {
 	return r.height
 }
@@ -49,19 +61,103 @@ func parseMsgEventLogs(logs sdk.ABCIMessageLogs, msgIndex uint32) ([]core.MsgEve
 	for _, log := range logs {
 		if msgIndex == log.MsgIndex {
 			for _, ev := range log.Events {
-				event, err := parseMsgEventLog(ev)
+				events, err := parseMsgEventLog(ev)
 				if err != nil {
 					return nil, fmt.Errorf("failed to parse msg event log: %v", err)
 				}
-				msgEventLogs = append(msgEventLogs, event)
+				msgEventLogs = append(msgEventLogs, events...)
 			}
 		}
 	}
 	return msgEventLogs, nil
 }
 
-func parseMsgEventLog(ev sdk.StringEvent) (core.MsgEventLog, error) {
+// parseMsgEventLog translates a native event into the core events.
+// A channel handshake event that generates a channel identifier is translated into two events
+// of the identifier generation and the channel state change.
+func parseMsgEventLog(ev sdk.StringEvent) ([]core.MsgEventLog, error) {
+	switch ev.Type {
+	case chantypes.EventTypeChannelOpenInit, chantypes.EventTypeChannelOpenTry:
+		generated, err := parseSingleMsgEventLog(ev)
+		if err != nil {
+			return nil, err
+		}
+		stateChange, err := parseChannelStateChange(ev)
+		if err != nil {
+			return nil, err
+		}
+		return []core.MsgEventLog{generated, stateChange}, nil
+	case chantypes.EventTypeChannelOpenAck, chantypes.EventTypeChannelOpenConfirm,
+		chantypes.EventTypeChannelCloseInit, chantypes.EventTypeChannelCloseConfirm, chantypes.EventTypeChannelClosed:
+		stateChange, err := parseChannelStateChange(ev)
+		if err != nil {
+			return nil, err
+		}
+		return []core.MsgEventLog{stateChange}, nil
+	default:
+		event, err := parseSingleMsgEventLog(ev)
+		if err != nil {
+			return nil, err
+		}
+		return []core.MsgEventLog{event}, nil
+	}
+}
+
+// channelStates are the states of a channel after the operations emitting the events
+var channelStates = map[string]chantypes.State{
+	chantypes.EventTypeChannelOpenInit:     chantypes.INIT,
+	chantypes.EventTypeChannelOpenTry:      chantypes.TRYOPEN,
+	chantypes.EventTypeChannelOpenAck:      chantypes.OPEN,
+	chantypes.EventTypeChannelOpenConfirm:  chantypes.OPEN,
+	chantypes.EventTypeChannelCloseInit:    chantypes.CLOSED,
+	chantypes.EventTypeChannelCloseConfirm: chantypes.CLOSED,
+	chantypes.EventTypeChannelClosed:       chantypes.CLOSED,
+}
+
+func parseChannelStateChange(ev sdk.StringEvent) (*core.EventChannelStateChange, error) {
+	event := core.EventChannelStateChange{State: channelStates[ev.Type]}
+	var err0, err1 error
+	event.PortID, err0 = getAttributeString(ev, chantypes.AttributeKeyPortID)
+	event.ChannelID, err1 = getAttributeString(ev, chantypes.AttributeKeyChannelID)
+	if err := errors.Join(err0, err1); err != nil {
+		return nil, err
+	}
+	// the counterparty channel ID is empty or absent until it is known
+	event.CounterpartyPortID, _ = getAttributeString(ev, chantypes.AttributeCounterpartyPortID)
+	event.CounterpartyChannelID, _ = getAttributeString(ev, chantypes.AttributeCounterpartyChannelID)
+	event.ConnectionID, _ = getAttributeString(ev, chantypes.AttributeKeyConnectionID)
+	return &event, nil
+}
+
+func parseUpdateClient(ev sdk.StringEvent) (*core.EventUpdateClient, error) {
+	var event core.EventUpdateClient
+	var err0, err1 error
+	event.ClientID, err0 = getAttributeString(ev, clienttypes.AttributeKeyClientID)
+	event.ClientType, err1 = getAttributeString(ev, clienttypes.AttributeKeyClientType)
+	if err := errors.Join(err0, err1); err != nil {
+		return nil, err
+	}
+	heights, err := getAttributeString(ev, clienttypes.AttributeKeyConsensusHeights)
+	if err != nil {
+		// the deprecated attribute emitted by the older versions of ibc-go
+		if heights, err = getAttributeString(ev, clienttypes.AttributeKeyConsensusHeight); err != nil {
+			return nil, err
+		}
+	}
+	for _, h := range strings.Split(heights, ",") {
+		height, err := clienttypes.ParseHeight(h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse height: %v", err)
+		}
+		event.ConsensusHeights = append(event.ConsensusHeights, height)
+	}
+	return &event, nil
+}
+
+func parseSingleMsgEventLog(ev sdk.StringEvent) (core.MsgEventLog, error) {
 	switch ev.Type {
+	case clienttypes.EventTypeUpdateClient:
+		return parseUpdateClient(ev)
 	case clienttypes.EventTypeCreateClient:
 		clientID, err := getAttributeString(ev, clienttypes.AttributeKeyClientID)
 		if err != nil {
@@ -0,0 +1,51 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+var _ core.PortBindingChecker = (*Chain)(nil)
+
+// portNotBoundMessages are the messages of the errors returned by the IBC core module when no app is bound to a port
+var portNotBoundMessages = []string{
+	"could not retrieve module from port-id",
+	"route not found to module",
+}
+
+// CheckPortBinding implements core.PortBindingChecker.
+// The chain has no query of the port bindings, so MsgChannelOpenInit on the port is simulated,
+// which fails in the lookup of the module by the port before any other check if no module is bound.
+func (c *Chain) CheckPortBinding(ctx context.Context, portID string) error {
+	signer, err := c.GetAddress()
+	if err != nil {
+		return err
+	}
+	msg := chantypes.NewMsgChannelOpenInit(
+		portID,
+		c.PathEnd.Version,
+		c.PathEnd.GetOrder(),
+		[]string{c.PathEnd.ConnectionID},
+		portID,
+		signer.String(),
+	)
+	cliCtx := c.CLIContext(0)
+	txf, err := prepareFactory(cliCtx, c.TxFactory(0))
+	if err != nil {
+		return err
+	}
+	if _, _, err = CalculateGas(cliCtx.QueryWithData, txf, msg); err == nil {
+		return nil
+	}
+	for _, m := range portNotBoundMessages {
+		if strings.Contains(err.Error(), m) {
+			return fmt.Errorf("%w: %v", core.ErrPortNotBound, err)
+		}
+	}
+	// the other errors (e.g. the version rejected by the app) are reported by the handshake itself
+	return nil
+}
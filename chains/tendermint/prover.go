@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/cometbft/cometbft/light"
+	tmproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	"github.com/cometbft/cometbft/types"
 	tmtypes "github.com/cometbft/cometbft/types"
+	sdkCtx "github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	ibcclient "github.com/cosmos/ibc-go/v7/modules/core/client"
@@ -18,18 +20,42 @@ import (
 	"github.com/hyperledger-labs/yui-relayer/core"
 )
 
+// CosmosChain is the interface of a chain that Prover generates proofs and headers for.
+// Chain implements it, and other Cosmos SDK based chain implementations can be paired with Prover by implementing it.
+type CosmosChain interface {
+	core.Chain
+
+	// RPCAddr returns the address of the Tendermint RPC endpoint of the chain
+	RPCAddr() string
+
+	// CLIContext returns an instance of client.Context derived from Chain
+	CLIContext(height int64) sdkCtx.Context
+
+	// QueryValsetAtHeight returns the validator set at a given height
+	QueryValsetAtHeight(height clienttypes.Height) (*tmproto.ValidatorSet, error)
+
+	// QueryUnbondingPeriod returns the unbonding period of the chain
+	QueryUnbondingPeriod() (time.Duration, error)
+
+	// IsConsumer returns true if the chain is a consumer chain of Interchain Security
+	IsConsumer() bool
+}
+
 type Prover struct {
-	chain  *Chain
+	chain  CosmosChain
 	config ProverConfig
+
+	homePath string
 }
 
 var _ core.Prover = (*Prover)(nil)
 
-func NewProver(chain *Chain, config ProverConfig) *Prover {
+func NewProver(chain CosmosChain, config ProverConfig) *Prover {
 	return &Prover{chain: chain, config: config}
 }
 
 func (pr *Prover) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	pr.homePath = homePath
 	return nil
 }
 
@@ -44,11 +70,16 @@ func (pr *Prover) SetupForRelay(ctx context.Context) error {
 
 // ProveState returns the proof of an IBC state specified by `path` and `value`
 func (pr *Prover) ProveState(ctx core.QueryContext, path string, value []byte) ([]byte, clienttypes.Height, error) {
+	if err := core.CheckRevision(pr.chain.ChainID(), ctx.Height()); err != nil {
+		return nil, clienttypes.Height{}, err
+	}
 	clientCtx := pr.chain.CLIContext(int64(ctx.Height().GetRevisionHeight()))
 	if v, proof, proofHeight, err := ibcclient.QueryTendermintProof(clientCtx, []byte(path)); err != nil {
 		return nil, clienttypes.Height{}, err
 	} else if !bytes.Equal(v, value) {
 		return nil, clienttypes.Height{}, fmt.Errorf("value unmatch: %x != %x", v, value)
+	} else if err := crossCheckState(pr.chain, int64(ctx.Height().GetRevisionHeight()), path, v, proof); err != nil {
+		return nil, clienttypes.Height{}, err
 	} else {
 		return proof, proofHeight, nil
 	}
@@ -78,11 +109,23 @@ func (pr *Prover) CreateInitialLightClientState(height ibcexported.Height) (ibce
 		return nil, nil, fmt.Errorf("failed to query for the unbonding period: %v", err)
 	}
 
-	cs := createClient(
+	if err := checkClientParams(pr.chain.ChainID(), selfHeader, pr.getTrustingPeriod(), ubdPeriod, time.Now()); err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for the client of %s: %w", pr.chain.ChainID(), err)
+	}
+	if pr.chain.IsConsumer() {
+		if err := checkConsumerHeader(pr.chain, selfHeader); err != nil {
+			return nil, nil, fmt.Errorf("invalid header of the consumer chain %s: %w", pr.chain.ChainID(), err)
+		}
+	}
+
+	cs, err := createClient(
 		selfHeader,
 		pr.getTrustingPeriod(),
 		ubdPeriod,
 	)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	cons := selfHeader.ConsensusState()
 
@@ -108,13 +151,19 @@ func (pr *Prover) SetupHeadersForUpdate(counterparty core.FinalityAwareChain, la
 	}
 
 	var cs ibcexported.ClientState
-	if err := self.codec.UnpackAny(counterpartyClientRes.ClientState, &cs); err != nil {
+	if err := self.Codec().UnpackAny(counterpartyClientRes.ClientState, &cs); err != nil {
 		return nil, err
 	}
 
 	// inject TrustedHeight as latest height stored on counterparty client
 	h.TrustedHeight = cs.GetLatestHeight().(clienttypes.Height)
 
+	// a header can't be verified with a trusted height of a previous revision, so the client must be upgraded first
+	if revision := h.GetHeight().GetRevisionNumber(); h.TrustedHeight.GetRevisionNumber() != revision {
+		return nil, fmt.Errorf("the client on %s is at revision %d but %s is at revision %d: the client must be upgraded to the new revision",
+			counterparty.ChainID(), h.TrustedHeight.GetRevisionNumber(), self.ChainID(), revision)
+	}
+
 	// query TrustedValidators at Trusted Height from the self chain
 	valSet, err := self.QueryValsetAtHeight(h.TrustedHeight)
 	if err != nil {
@@ -144,7 +193,7 @@ func (pr *Prover) CheckRefreshRequired(counterparty core.ChainInfoICS02Querier)
 	}
 
 	var cs ibcexported.ClientState
-	if err := pr.chain.codec.UnpackAny(resCs.ClientState, &cs); err != nil {
+	if err := pr.chain.Codec().UnpackAny(resCs.ClientState, &cs); err != nil {
 		return false, fmt.Errorf("failed to unpack Any into tendermint client state: %v", err)
 	}
 
@@ -154,7 +203,7 @@ func (pr *Prover) CheckRefreshRequired(counterparty core.ChainInfoICS02Querier)
 	}
 
 	var cons ibcexported.ConsensusState
-	if err := pr.chain.codec.UnpackAny(resCons.ConsensusState, &cons); err != nil {
+	if err := pr.chain.Codec().UnpackAny(resCons.ConsensusState, &cons); err != nil {
 		return false, fmt.Errorf("failed to unpack Any into tendermint consensus state: %v", err)
 	}
 	lcLastTimestamp := time.Unix(0, int64(cons.GetTimestamp()))
@@ -17,6 +17,7 @@ import (
 	querytypes "github.com/cosmos/cosmos-sdk/types/query"
 	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	feetypes "github.com/cosmos/ibc-go/v7/modules/apps/29-fee/types"
 	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
 	clientutils "github.com/cosmos/ibc-go/v7/modules/core/02-client/client/utils"
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
@@ -27,6 +28,8 @@ import (
 	committypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
 	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
 	"github.com/hyperledger-labs/yui-relayer/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // QueryClientState retrevies the latest consensus state for a client in state at a given height
@@ -100,6 +103,32 @@ func (c *Chain) queryChannel(height int64, prove bool) (chanRes *chantypes.Query
 }
 
 // QueryClientConsensusState retrevies the latest consensus state for a client in state at a given height
+// QueryConnectionChannels returns all the channels associated with the connection of the path
+func (c *Chain) QueryConnectionChannels(ctx core.QueryContext) ([]*chantypes.IdentifiedChannel, error) {
+	qc := chantypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight())))
+	var (
+		channels []*chantypes.IdentifiedChannel
+		nextKey  []byte
+	)
+	for {
+		res, err := qc.ConnectionChannels(context.Background(), &chantypes.QueryConnectionChannelsRequest{
+			Connection: c.PathEnd.ConnectionID,
+			Pagination: &querytypes.PageRequest{
+				Key:   nextKey,
+				Limit: 1000,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query connection channels: error=%w height=%v", err, ctx.Height())
+		}
+		channels = append(channels, res.Channels...)
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return channels, nil
+		}
+		nextKey = res.Pagination.NextKey
+	}
+}
+
 func (c *Chain) QueryClientConsensusState(
 	ctx core.QueryContext, dstClientConsHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
 	return c.queryClientConsensusState(int64(ctx.Height().GetRevisionHeight()), dstClientConsHeight, false)
@@ -145,6 +174,64 @@ func (c *Chain) QueryDenomTraces(ctx core.QueryContext, offset, limit uint64) (*
 	})
 }
 
+var _ core.FeePayeeQuerier = (*Chain)(nil)
+
+// QueryPayee returns the payee registered for the relayer on the channel, or an empty string if not registered
+func (c *Chain) QueryPayee(ctx core.QueryContext, relayer string) (string, error) {
+	res, err := feetypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight()))).Payee(context.Background(), &feetypes.QueryPayeeRequest{
+		ChannelId: c.PathEnd.ChannelID,
+		Relayer:   relayer,
+	})
+	if status.Code(err) == codes.NotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return res.PayeeAddress, nil
+}
+
+// QueryCounterpartyPayee returns the counterparty payee registered for the relayer on the channel, or an empty string if not registered
+func (c *Chain) QueryCounterpartyPayee(ctx core.QueryContext, relayer string) (string, error) {
+	res, err := feetypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight()))).CounterpartyPayee(context.Background(), &feetypes.QueryCounterpartyPayeeRequest{
+		ChannelId: c.PathEnd.ChannelID,
+		Relayer:   relayer,
+	})
+	if status.Code(err) == codes.NotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return res.CounterpartyPayee, nil
+}
+
+var _ core.IncentivizedPacketQuerier = (*Chain)(nil)
+
+// QueryIncentivizedPackets returns the sequences of the packets sent on the channel on which fees are escrowed
+func (c *Chain) QueryIncentivizedPackets(ctx core.QueryContext) ([]uint64, error) {
+	height := ctx.Height().GetRevisionHeight()
+	queryClient := feetypes.NewQueryClient(c.CLIContext(int64(height)))
+	// the response has no next key, so the packets are paginated by the offset
+	const limit = 100
+	var seqs []uint64
+	for offset := uint64(0); ; offset += limit {
+		res, err := queryClient.IncentivizedPacketsForChannel(context.Background(), &feetypes.QueryIncentivizedPacketsForChannelRequest{
+			Pagination:  &querytypes.PageRequest{Offset: offset, Limit: limit},
+			PortId:      c.PathEnd.PortID,
+			ChannelId:   c.PathEnd.ChannelID,
+			QueryHeight: height,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range res.IncentivizedPackets {
+			seqs = append(seqs, p.PacketId.Sequence)
+		}
+		if len(res.IncentivizedPackets) < limit {
+			return seqs, nil
+		}
+	}
+}
+
 // queryPacketCommitments returns an array of packet commitments
 func (c *Chain) queryPacketCommitments(
 	ctx core.QueryContext,
@@ -246,18 +333,19 @@ func (c *Chain) QueryUnfinalizedRelayAcknowledgements(ctx core.QueryContext, cou
 
 	var packets core.PacketInfoList
 	for _, ps := range res.Acknowledgements {
-		packet, rpHeight, err := c.queryReceivedPacket(ctx, ps.Sequence)
+		packet, _, err := c.queryReceivedPacket(ctx, ps.Sequence)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query received packet: error=%w height=%v", err, ctx.Height())
 		}
-		ack, _, err := c.queryWrittenAcknowledgement(ctx, ps.Sequence)
+		// an acknowledgement may be written asynchronously in a later block than RecvPacket
+		ack, ackHeight, err := c.queryWrittenAcknowledgement(ctx, ps.Sequence)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query written acknowledgement: error=%w height=%v", err, ctx.Height())
 		}
 		packets = append(packets, &core.PacketInfo{
 			Packet:          *packet,
 			Acknowledgement: ack,
-			EventHeight:     rpHeight,
+			EventHeight:     ackHeight,
 		})
 	}
 
@@ -277,6 +365,93 @@ func (c *Chain) QueryUnfinalizedRelayAcknowledgements(ctx core.QueryContext, cou
 	return packets, nil
 }
 
+// packetEventsPerPage is the number of transactions fetched by a tx_search request of QueryPacketEventsInRange
+const packetEventsPerPage = 100
+
+// QueryPacketEventsInRange returns the packets sent and the acknowledgements written on the channel of the path
+// in the blocks from `fromHeight` to `toHeight` by paginated tx_search requests
+func (c *Chain) QueryPacketEventsInRange(ctx context.Context, fromHeight, toHeight ibcexported.Height) (*core.PacketEvents, error) {
+	if err := core.CheckRevision(c.ChainID(), fromHeight); err != nil {
+		return nil, err
+	}
+	if err := core.CheckRevision(c.ChainID(), toHeight); err != nil {
+		return nil, err
+	}
+	if _, ok := core.BlocksBetween(fromHeight, toHeight); !ok {
+		return nil, fmt.Errorf("invalid height range: from=%v to=%v", fromHeight, toHeight)
+	}
+	heightRange := fmt.Sprintf("tx.height>=%d AND tx.height<=%d", fromHeight.GetRevisionHeight(), toHeight.GetRevisionHeight())
+	revision := clienttypes.ParseChainID(c.ChainID())
+
+	var events core.PacketEvents
+	sendTxs, err := c.searchTxs(ctx, fmt.Sprintf("%s.packet_src_channel='%s' AND %s", spTag, c.Path().ChannelID, heightRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search send_packet events: error=%w from=%v to=%v", err, fromHeight, toHeight)
+	}
+	for _, tx := range sendTxs {
+		packets, err := core.GetPacketsFromEvents(tx.TxResult.Events, chantypes.EventTypeSendPacket)
+		if err != nil {
+			return nil, err
+		}
+		for _, packet := range packets {
+			if packet.SourcePort != c.Path().PortID || packet.SourceChannel != c.Path().ChannelID {
+				continue
+			}
+			events.SentPackets = append(events.SentPackets, &core.PacketInfo{
+				Packet:      packet,
+				EventHeight: clienttypes.NewHeight(revision, uint64(tx.Height)),
+			})
+		}
+	}
+
+	ackTxs, err := c.searchTxs(ctx, fmt.Sprintf("%s.packet_dst_channel='%s' AND %s", waTag, c.Path().ChannelID, heightRange))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search write_acknowledgement events: error=%w from=%v to=%v", err, fromHeight, toHeight)
+	}
+	for _, tx := range ackTxs {
+		packets, err := core.GetPacketsFromEvents(tx.TxResult.Events, chantypes.EventTypeWriteAck)
+		if err != nil {
+			return nil, err
+		}
+		acks, err := core.GetPacketAcknowledgementsFromEvents(tx.TxResult.Events)
+		if err != nil {
+			return nil, err
+		}
+		// both are extracted from the same write_acknowledgement events in the same order
+		if len(packets) != len(acks) {
+			return nil, fmt.Errorf("mismatched number of packets and acknowledgements in tx %X: %d != %d", tx.Hash, len(packets), len(acks))
+		}
+		for i, packet := range packets {
+			if packet.DestinationPort != c.Path().PortID || packet.DestinationChannel != c.Path().ChannelID {
+				continue
+			}
+			events.WrittenAcknowledgements = append(events.WrittenAcknowledgements, &core.PacketInfo{
+				Packet:          packet,
+				Acknowledgement: acks[i].Data(),
+				EventHeight:     clienttypes.NewHeight(revision, uint64(tx.Height)),
+			})
+		}
+	}
+
+	return &events, nil
+}
+
+// searchTxs returns all the transactions matching `query` in ascending order of the height
+func (c *Chain) searchTxs(ctx context.Context, query string) ([]*ctypes.ResultTx, error) {
+	var txs []*ctypes.ResultTx
+	for page := 1; ; page++ {
+		p, perPage := page, packetEventsPerPage
+		res, err := c.Client.TxSearch(ctx, query, false, &p, &perPage, "asc")
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, res.Txs...)
+		if len(res.Txs) == 0 || len(txs) >= res.TotalCount {
+			return txs, nil
+		}
+	}
+}
+
 // querySentPacket finds a SendPacket event corresponding to `seq` and returns the packet in it
 func (c *Chain) querySentPacket(ctx core.QueryContext, seq uint64) (*chantypes.Packet, clienttypes.Height, error) {
 	txs, err := c.QueryTxs(int64(ctx.Height().GetRevisionHeight()), 1, 1000, sendPacketQuery(c.Path().ChannelID, int(seq)))
@@ -388,7 +563,11 @@ func (c *Chain) QueryHistoricalInfo(height clienttypes.Height) (*stakingtypes.Qu
 }
 
 // QueryValsetAtHeight returns the validator set at a given height
+// A consumer chain has no staking module, so its validator set is queried from the Tendermint RPC.
 func (c *Chain) QueryValsetAtHeight(height clienttypes.Height) (*tmproto.ValidatorSet, error) {
+	if c.IsConsumer() {
+		return c.queryValsetFromRPC(height)
+	}
 	res, err := c.QueryHistoricalInfo(height)
 	if err != nil {
 		return nil, err
@@ -440,7 +619,11 @@ func (c *Chain) toTmValidator(val stakingtypes.Validator) (*tmtypes.Validator, e
 }
 
 // QueryUnbondingPeriod returns the unbonding period of the chain
+// A consumer chain has no staking module, so the configured unbonding period is returned after it is checked against the provider chain.
 func (c *Chain) QueryUnbondingPeriod() (time.Duration, error) {
+	if c.IsConsumer() {
+		return c.queryConsumerUnbondingPeriod()
+	}
 	req := stakingtypes.QueryParamsRequest{}
 
 	queryClient := stakingtypes.NewQueryClient(c.CLIContext(0))
@@ -0,0 +1,50 @@
+package tendermint
+
+import (
+	"fmt"
+	"time"
+
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/hyperledger-labs/yui-relayer/signer"
+)
+
+// defaultRemoteSignerTimeout is the timeout of each request to the remote signer if it isn't configured
+const defaultRemoteSignerTimeout = 10 * time.Second
+
+// Validate validates the remote signer config
+func (c *RemoteSignerConfig) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("config attribute \"remote_signer.address\" is empty")
+	}
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return fmt.Errorf("config attribute \"remote_signer.timeout\" is invalid: %v", err)
+		}
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("config attributes \"remote_signer.cert_file\" and \"remote_signer.key_file\" must be set together")
+	}
+	return nil
+}
+
+// GetTimeout returns the timeout of each request to the remote signer
+func (c *RemoteSignerConfig) GetTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultRemoteSignerTimeout
+}
+
+// newRemoteSignerKeyring returns a keyring signing with the remote signer. `local` serves the other operations.
+// The connection is established lazily, so the signer doesn't have to be up for the commands that don't sign.
+func newRemoteSignerKeyring(cfg *RemoteSignerConfig, chainID string, local keys.Keyring) (keys.Keyring, error) {
+	conn, err := signer.Dial(cfg.Address, &signer.TLSConfig{
+		CAFile:   cfg.CaFile,
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the connection to the remote signer %s: %w", cfg.Address, err)
+	}
+	return signer.NewKeyring(local, signer.NewRemoteSignerClient(conn), chainID, cfg.GetTimeout()), nil
+}
@@ -0,0 +1,233 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// event sources of ChainConfig.EventSource
+const (
+	eventSourcePolling   = "polling"
+	eventSourceWebSocket = "websocket"
+)
+
+const (
+	subscriber = "yui-relayer"
+
+	// minStallTimeout is the minimum time without a new block after which the subscription is regarded as dropped
+	minStallTimeout = 30 * time.Second
+)
+
+func isValidEventSource(source string) bool {
+	switch source {
+	case "", eventSourcePolling, eventSourceWebSocket:
+		return true
+	default:
+		return false
+	}
+}
+
+var _ core.PacketEventSubscriber = (*Chain)(nil)
+
+// SubscribePacketEvents implements core.PacketEventSubscriber.
+// In the WebSocket mode, it subscribes new block headers in addition to packet events so that a gap in the block heights is detected,
+// and the blocks missed while the subscription is down are scanned by tx_search on reconnection.
+// If `event_poll_interval` is set in the polling mode, new blocks are scanned by tx_search at the interval instead.
+func (c *Chain) SubscribePacketEvents(ctx context.Context, notify func(height ibcexported.Height)) error {
+	if c.config.EventSource != eventSourceWebSocket {
+		if c.config.EventPollInterval == "" {
+			return nil
+		}
+		return c.pollPacketEvents(ctx, notify)
+	}
+	logger := GetChainLogger().With("chain_id", c.ChainID())
+	backoff := core.DefaultBackoffPolicy().NewBackoff()
+
+	var lastHeight int64 // height of the last block observed by the subscription
+	for {
+		err := c.subscribePacketEvents(ctx, &lastHeight, backoff, notify)
+		if ctx.Err() != nil {
+			return nil
+		}
+		wait := backoff.Next()
+		logger.Warn("packet event subscription dropped; reconnecting", "error", err, "last_height", lastHeight, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// subscribePacketEvents subscribes the events until the subscription drops or `ctx` is done.
+// On a reconnection, the blocks from the last observed block to the block before the first new header are scanned,
+// since the tx events of a block are published after its header and may have been lost with the previous subscription.
+func (c *Chain) subscribePacketEvents(ctx context.Context, lastHeight *int64, backoff *core.Backoff, notify func(height ibcexported.Height)) error {
+	newClient := c.newEventClient
+	if newClient == nil {
+		newClient = func() (rpcclient.Client, error) { return rpchttp.New(c.config.RpcAddr, "/websocket") }
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	if err := client.Start(); err != nil {
+		return fmt.Errorf("failed to connect to the WebSocket: %w", err)
+	}
+	defer func() {
+		_ = client.UnsubscribeAll(context.Background(), subscriber)
+		_ = client.Stop()
+	}()
+
+	// the tx events are subscribed before the headers, so that the tx events of the block of the first header are received
+	queries := c.packetEventQueries()
+	sendPackets, err := client.Subscribe(ctx, subscriber, txEventQuery(queries[0]))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe send_packet events: %w", err)
+	}
+	writeAcks, err := client.Subscribe(ctx, subscriber, txEventQuery(queries[1]))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe write_acknowledgement events: %w", err)
+	}
+	headers, err := client.Subscribe(ctx, subscriber, tmtypes.QueryForEvent(tmtypes.EventNewBlockHeader).String())
+	if err != nil {
+		return fmt.Errorf("failed to subscribe new block headers: %w", err)
+	}
+	backoff.Reset()
+
+	stallTimeout := 10 * c.AverageBlockTime()
+	if stallTimeout < minStallTimeout {
+		stallTimeout = minStallTimeout
+	}
+	stall := time.NewTimer(stallTimeout)
+	defer stall.Stop()
+
+	revision := clienttypes.ParseChainID(c.ChainID())
+	resumed := *lastHeight > 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-stall.C:
+			return fmt.Errorf("no new block for %v", stallTimeout)
+		case ev, ok := <-headers:
+			if !ok {
+				return fmt.Errorf("block header subscription closed")
+			}
+			data, ok := ev.Data.(tmtypes.EventDataNewBlockHeader)
+			if !ok {
+				continue
+			}
+			height := data.Header.Height
+			// the tx events of the last observed block may have been lost after its header, so the scan starts from it
+			if *lastHeight > 0 && height > *lastHeight && (resumed || height > *lastHeight+1) {
+				if err := c.catchUpPacketEvents(ctx, client, *lastHeight, height-1, notify); err != nil {
+					return fmt.Errorf("failed to scan the missed blocks [%d, %d]: %w", *lastHeight, height-1, err)
+				}
+			}
+			resumed = false
+			if height > *lastHeight {
+				*lastHeight = height
+			}
+			stall.Reset(stallTimeout)
+		case ev, ok := <-sendPackets:
+			if !ok {
+				return fmt.Errorf("send_packet subscription closed")
+			}
+			notifyTxEvent(ev, revision, notify)
+		case ev, ok := <-writeAcks:
+			if !ok {
+				return fmt.Errorf("write_acknowledgement subscription closed")
+			}
+			notifyTxEvent(ev, revision, notify)
+		}
+	}
+}
+
+// pollPacketEvents scans the blocks produced since the last poll for packet events at `event_poll_interval` until `ctx` is done.
+// A failed poll is retried at the next interval from the same height, so no block is missed.
+func (c *Chain) pollPacketEvents(ctx context.Context, notify func(height ibcexported.Height)) error {
+	interval, err := time.ParseDuration(c.config.EventPollInterval)
+	if err != nil {
+		return err
+	}
+	logger := GetChainLogger().With("chain_id", c.ChainID())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastHeight int64 // height of the last block scanned
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		status, err := c.Client.Status(ctx)
+		if err != nil {
+			logger.Warn("failed to poll the latest height", "error", err)
+			continue
+		}
+		latest := status.SyncInfo.LatestBlockHeight
+		if lastHeight == 0 {
+			// packets sent before the first poll are scanned by the relay cycle
+			lastHeight = latest
+			continue
+		}
+		if latest <= lastHeight {
+			continue
+		}
+		if err := c.catchUpPacketEvents(ctx, c.Client, lastHeight+1, latest, notify); err != nil {
+			logger.Warn("failed to scan the new blocks for packet events", "error", err, "from", lastHeight+1, "to", latest)
+			continue
+		}
+		lastHeight = latest
+	}
+}
+
+func notifyTxEvent(ev coretypes.ResultEvent, revision uint64, notify func(height ibcexported.Height)) {
+	if data, ok := ev.Data.(tmtypes.EventDataTx); ok {
+		notify(clienttypes.NewHeight(revision, uint64(data.Height)))
+	}
+}
+
+func txEventQuery(query string) string {
+	return fmt.Sprintf("%s='%s' AND %s", tmtypes.EventTypeKey, tmtypes.EventTx, query)
+}
+
+// catchUpPacketEvents notifies the latest block in [from, to] containing packet events
+func (c *Chain) catchUpPacketEvents(ctx context.Context, client rpcclient.Client, from, to int64, notify func(height ibcexported.Height)) error {
+	var latest int64
+	for _, query := range c.packetEventQueries() {
+		page, perPage := 1, 1
+		res, err := client.TxSearch(ctx, fmt.Sprintf("%s AND tx.height>=%d AND tx.height<=%d", query, from, to), false, &page, &perPage, "desc")
+		if err != nil {
+			return err
+		}
+		if len(res.Txs) > 0 && res.Txs[0].Height > latest {
+			latest = res.Txs[0].Height
+		}
+	}
+	if latest > 0 {
+		notify(clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(latest)))
+	}
+	return nil
+}
+
+// packetEventQueries returns the queries for the packets sent and the acknowledgements written on the connection of the path
+func (c *Chain) packetEventQueries() []string {
+	return []string{
+		fmt.Sprintf("%s.%s='%s'", chantypes.EventTypeSendPacket, chantypes.AttributeKeyConnection, c.Path().ConnectionID),
+		fmt.Sprintf("%s.%s='%s'", chantypes.EventTypeWriteAck, chantypes.AttributeKeyConnection, c.Path().ConnectionID),
+	}
+}
@@ -0,0 +1,139 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+var txHeightRange = regexp.MustCompile(`tx\.height>=(\d+) AND tx\.height<=(\d+)`)
+
+// eventClient is a WebSocket client of a node which publishes the given block headers
+// and finds the packet events at the given heights by tx_search
+type eventClient struct {
+	rpcclient.Client
+	headers chan coretypes.ResultEvent
+	// packetHeights are the heights of the blocks containing packet events
+	packetHeights []int64
+
+	mu sync.Mutex
+	// scanned are the ranges of the blocks scanned by tx_search
+	scanned [][2]int64
+}
+
+func newScriptedEventClient(headers []int64, closed bool, packetHeights []int64) *eventClient {
+	c := &eventClient{headers: make(chan coretypes.ResultEvent, len(headers)), packetHeights: packetHeights}
+	for _, h := range headers {
+		c.headers <- coretypes.ResultEvent{Data: tmtypes.EventDataNewBlockHeader{Header: tmtypes.Header{Height: h}}}
+	}
+	if closed {
+		close(c.headers)
+	}
+	return c
+}
+
+func (c *eventClient) Start() error { return nil }
+
+func (c *eventClient) Stop() error { return nil }
+
+func (c *eventClient) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	if strings.Contains(query, tmtypes.EventNewBlockHeader) {
+		return c.headers, nil
+	}
+	// the packet events are never published while subscribed
+	return make(chan coretypes.ResultEvent), nil
+}
+
+func (c *eventClient) UnsubscribeAll(ctx context.Context, subscriber string) error { return nil }
+
+func (c *eventClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error) {
+	m := txHeightRange.FindStringSubmatch(query)
+	if m == nil {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	from, _ := strconv.ParseInt(m[1], 10, 64)
+	to, _ := strconv.ParseInt(m[2], 10, 64)
+	c.mu.Lock()
+	c.scanned = append(c.scanned, [2]int64{from, to})
+	c.mu.Unlock()
+
+	res := &coretypes.ResultTxSearch{}
+	for i := len(c.packetHeights) - 1; i >= 0; i-- {
+		if h := c.packetHeights[i]; h >= from && h <= to {
+			res.Txs = append(res.Txs, &coretypes.ResultTx{Height: h})
+			res.TotalCount++
+			break
+		}
+	}
+	return res, nil
+}
+
+func TestSubscribePacketEventsCatchesUpOnReconnection(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+
+	packetHeights := []int64{11, 13}
+	clients := []*eventClient{
+		// the subscription drops after the header of block 11 before its tx events are published
+		newScriptedEventClient([]int64{10, 11}, true, packetHeights),
+		// the subscription resumes at block 12 without a gap, then skips blocks 13 and 14
+		newScriptedEventClient([]int64{12, 15}, false, packetHeights),
+	}
+	var connections int
+	c := &Chain{
+		config:  ChainConfig{ChainId: "ibc0-1", EventSource: eventSourceWebSocket},
+		PathEnd: &core.PathEnd{ChainID: "ibc0-1", ConnectionID: "connection-0"},
+		newEventClient: func() (rpcclient.Client, error) {
+			if connections >= len(clients) {
+				return nil, fmt.Errorf("no more connections")
+			}
+			connections++
+			return clients[connections-1], nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notified := make(chan ibcexported.Height, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.SubscribePacketEvents(ctx, func(height ibcexported.Height) { notified <- height })
+	}()
+
+	for _, expected := range packetHeights {
+		select {
+		case height := <-notified:
+			if height.GetRevisionNumber() != 1 || height.GetRevisionHeight() != uint64(expected) {
+				t.Fatalf("expected a notification of height 1-%d, got %v", expected, height)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("no notification of height 1-%d", expected)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients[0].scanned) != 0 {
+		t.Fatalf("expected no scan on the first connection, got %v", clients[0].scanned)
+	}
+	// each range is scanned for send_packet and write_acknowledgement
+	expected := [][2]int64{{11, 11}, {11, 11}, {12, 14}, {12, 14}}
+	if fmt.Sprint(clients[1].scanned) != fmt.Sprint(expected) {
+		t.Fatalf("expected the scanned ranges %v, got %v", expected, clients[1].scanned)
+	}
+}
@@ -0,0 +1,128 @@
+package tendermint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	sdkCtx "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// TxHookContext is the context of a tx passed to the tx hooks
+type TxHookContext struct {
+	// ClientCtx is the client context of the chain, which gives access to the codec, the tx config and the keyring
+	ClientCtx sdkCtx.Context
+	ChainID   string
+	// Key is the name of the relayer's key in the keyring
+	Key string
+	// Msgs are the msgs of the tx, which are already wrapped in MsgExec if the chain uses x/authz
+	Msgs []sdk.Msg
+}
+
+// TxHook is a hook called while the chain builds a tx, which allows chain modules and plugins to support the chains
+// with non-standard tx envelopes without forking SendMsgs.
+// The hooks are enabled for a chain by listing their names in the `tx_hooks` config attribute, and are called in the listed order.
+type TxHook interface {
+	// BeforeSign is called with the unsigned tx after the gas and the fee are set.
+	// It can mutate the tx (e.g. add extension options, set a tip or a fee granter) and return a modified factory
+	// (e.g. to change the sign mode) used to sign the tx.
+	BeforeSign(ctx *TxHookContext, txf tx.Factory, txb sdkCtx.TxBuilder) (tx.Factory, error)
+
+	// BeforeBroadcast is called with the encoded signed tx and returns the bytes broadcast to the chain,
+	// which allows to wrap the tx in another envelope.
+	BeforeBroadcast(ctx *TxHookContext, txBytes []byte) ([]byte, error)
+}
+
+// TxSigner is an optional interface of TxHook to sign txs instead of the keyring of the chain,
+// e.g. to sign EIP-712 typed data or to collect the signatures of a multisig account.
+// If multiple hooks of a chain implement it, the first one signs the tx.
+type TxSigner interface {
+	SignTx(ctx *TxHookContext, txf tx.Factory, txb sdkCtx.TxBuilder) error
+}
+
+var (
+	txHooksMu sync.RWMutex
+	txHooks   = make(map[string]TxHook)
+)
+
+// RegisterTxHook registers the tx hook with the name referred from the `tx_hooks` config attribute.
+// It must be called before the config is loaded, e.g. in the init function of the module providing the hook.
+func RegisterTxHook(name string, hook TxHook) {
+	txHooksMu.Lock()
+	defer txHooksMu.Unlock()
+	if _, ok := txHooks[name]; ok {
+		panic(fmt.Sprintf("tx hook %s is already registered", name))
+	}
+	txHooks[name] = hook
+}
+
+// RegisteredTxHooks returns the sorted names of the registered tx hooks
+func RegisteredTxHooks() []string {
+	txHooksMu.RLock()
+	defer txHooksMu.RUnlock()
+	var names []string
+	for name := range txHooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getTxHooks returns the hooks of the names in order
+func getTxHooks(names []string) ([]TxHook, error) {
+	txHooksMu.RLock()
+	defer txHooksMu.RUnlock()
+	var hooks []TxHook
+	for _, name := range names {
+		hook, ok := txHooks[name]
+		if !ok {
+			return nil, fmt.Errorf("tx hook %s is not registered (registered: %v)", name, RegisteredTxHooks())
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// signAndEncodeTx signs the unsigned tx and encodes it with the tx hooks of the chain applied
+func (c *Chain) signAndEncodeTx(ctx sdkCtx.Context, txf tx.Factory, txb sdkCtx.TxBuilder, msgs []sdk.Msg) ([]byte, error) {
+	hooks, err := getTxHooks(c.config.TxHooks)
+	if err != nil {
+		return nil, err
+	}
+	hctx := &TxHookContext{ClientCtx: ctx, ChainID: c.config.ChainId, Key: c.config.Key, Msgs: msgs}
+
+	for i, hook := range hooks {
+		if txf, err = hook.BeforeSign(hctx, txf, txb); err != nil {
+			return nil, fmt.Errorf("tx hook %s failed before signing: %w", c.config.TxHooks[i], err)
+		}
+	}
+
+	signed := false
+	for i, hook := range hooks {
+		if signer, ok := hook.(TxSigner); ok {
+			if err := signer.SignTx(hctx, txf, txb); err != nil {
+				return nil, fmt.Errorf("tx hook %s failed to sign the tx: %w", c.config.TxHooks[i], err)
+			}
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		if err := tx.Sign(txf, c.config.Key, txb, false); err != nil {
+			return nil, err
+		}
+	}
+
+	txBytes, err := ctx.TxConfig.TxEncoder()(txb.GetTx())
+	if err != nil {
+		return nil, err
+	}
+	for i, hook := range hooks {
+		if txBytes, err = hook.BeforeBroadcast(hctx, txBytes); err != nil {
+			return nil, fmt.Errorf("tx hook %s failed before broadcasting: %w", c.config.TxHooks[i], err)
+		}
+	}
+	return txBytes, nil
+}
@@ -0,0 +1,50 @@
+package tendermint_test
+
+import (
+	"strings"
+	"testing"
+
+	sdkCtx "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/hyperledger-labs/yui-relayer/chains/tendermint"
+)
+
+type memoHook struct{}
+
+func (memoHook) BeforeSign(_ *tendermint.TxHookContext, txf tx.Factory, txb sdkCtx.TxBuilder) (tx.Factory, error) {
+	txb.SetMemo("hooked")
+	return txf, nil
+}
+
+func (memoHook) BeforeBroadcast(_ *tendermint.TxHookContext, txBytes []byte) ([]byte, error) {
+	return txBytes, nil
+}
+
+func TestTxHooksConfig(t *testing.T) {
+	tendermint.RegisterTxHook("test-memo", memoHook{})
+	cfg := tendermint.ChainConfig{
+		Key:                  "testkey",
+		ChainId:              "ibc0",
+		RpcAddr:              "http://localhost:26657",
+		AccountPrefix:        "cosmos",
+		GasAdjustment:        1.5,
+		GasPrices:            "0.025stake",
+		AverageBlockTimeMsec: 1000,
+		MaxRetryForCommit:    5,
+		TxHooks:              []string{"test-memo"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("a registered hook is rejected: %v", err)
+	}
+	cfg.TxHooks = append(cfg.TxHooks, "unknown")
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "tx_hooks") {
+		t.Fatalf("an unregistered hook is accepted: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("a hook is registered twice")
+		}
+	}()
+	tendermint.RegisterTxHook("test-memo", memoHook{})
+}
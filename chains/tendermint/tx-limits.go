@@ -0,0 +1,57 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+const (
+	// defaultMaxTxBytes is the default max_tx_bytes of the mempool of CometBFT
+	defaultMaxTxBytes = 1024 * 1024
+	// txOverheadBytes is the size reserved for the parts of a tx other than the msgs and the memo
+	// (the auth info, the fee, the signature and the Any wrapping the msgs)
+	txOverheadBytes = 1024
+)
+
+var _ core.TxLimitsProvider = (*Chain)(nil)
+
+// TxLimits returns the maximum size of the msgs in a tx, which is set up by SetupForRelay
+func (c *Chain) TxLimits() core.TxLimits {
+	return core.TxLimits{MaxTxBytes: c.maxMsgBytes}
+}
+
+// setupTxLimits determines the maximum size of the msgs in a tx from the configured or queried max tx size and the memo,
+// and checks that the memo is accepted by the chain
+func (c *Chain) setupTxLimits(ctx context.Context) error {
+	maxTxBytes := c.config.MaxTxBytes
+	if maxTxBytes == 0 {
+		maxTxBytes = defaultMaxTxBytes
+		res, err := c.Client.ConsensusParams(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to query the consensus params: %v", err)
+		}
+		if maxBytes := res.ConsensusParams.Block.MaxBytes; maxBytes > 0 && uint64(maxBytes) < maxTxBytes {
+			maxTxBytes = uint64(maxBytes)
+		}
+	}
+
+	if c.config.Memo != "" {
+		res, err := authtypes.NewQueryClient(c.CLIContext(0)).Params(ctx, &authtypes.QueryParamsRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to query the auth params: %v", err)
+		}
+		if maxMemo := res.Params.MaxMemoCharacters; uint64(len(c.config.Memo)) > maxMemo {
+			return fmt.Errorf("the memo exceeds the max_memo_characters of %s: %d > %d", c.ChainID(), len(c.config.Memo), maxMemo)
+		}
+	}
+
+	overhead := uint64(txOverheadBytes + len(c.config.Memo))
+	if maxTxBytes <= overhead {
+		return fmt.Errorf("max tx size of %s is too small: %d <= %d", c.ChainID(), maxTxBytes, overhead)
+	}
+	c.maxMsgBytes = maxTxBytes - overhead
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package chainsdk helps the authors of the third-party chain modules implement core.Chain and core.Prover.
+// It provides the base implementations of the parts common to the modules and a conformance test suite
+// (see RunConformanceTests) verifying that a module behaves as the core of the relayer expects.
+package chainsdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// ChainBase implements the parts of core.Chain common to the chain modules: the codec, the path and the msg event listener.
+// A chain module embeds it and implements the rest of core.Chain.
+// A module overriding Init or SetRelayInfo must call the method of ChainBase in it.
+type ChainBase struct {
+	codec            codec.ProtoCodecMarshaler
+	path             *core.PathEnd
+	counterparty     *core.ProvableChain
+	counterpartyPath *core.PathEnd
+	msgEventListener core.MsgEventListener
+}
+
+// Codec returns the codec
+func (b *ChainBase) Codec() codec.ProtoCodecMarshaler {
+	return b.codec
+}
+
+// Path returns the path
+func (b *ChainBase) Path() *core.PathEnd {
+	return b.path
+}
+
+// Counterparty returns the counterparty chain and its path end set by SetRelayInfo
+func (b *ChainBase) Counterparty() (*core.ProvableChain, *core.PathEnd) {
+	return b.counterparty, b.counterpartyPath
+}
+
+// Init keeps the codec
+func (b *ChainBase) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	b.codec = codec
+	return nil
+}
+
+// SetRelayInfo validates and keeps the path and the counterparty
+func (b *ChainBase) SetRelayInfo(path *core.PathEnd, counterparty *core.ProvableChain, counterpartyPath *core.PathEnd) error {
+	if err := path.Validate(); err != nil {
+		return fmt.Errorf("path on chain %s failed to set: %w", path.ChainID, err)
+	}
+	b.path = path
+	b.counterparty = counterparty
+	b.counterpartyPath = counterpartyPath
+	return nil
+}
+
+// SetupForRelay does nothing
+func (b *ChainBase) SetupForRelay(ctx context.Context) error {
+	return nil
+}
+
+// RegisterMsgEventListener registers a given EventListener to the chain
+func (b *ChainBase) RegisterMsgEventListener(listener core.MsgEventListener) {
+	b.msgEventListener = listener
+}
+
+// NotifySentMsgs calls the registered listener with the msgs sent by SendMsgs, which must be called after the msgs are included
+func (b *ChainBase) NotifySentMsgs(msgs []sdk.Msg) error {
+	if b.msgEventListener == nil {
+		return nil
+	}
+	return b.msgEventListener.OnSentMsg(msgs)
+}
+
+// ProverBase implements the lifecycle methods of core.Prover for the provers using the path of the chain instead of their own.
+// A prover module embeds it and implements core.LightClient and core.StateProver.
+type ProverBase struct{}
+
+// Init does nothing
+func (ProverBase) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	return nil
+}
+
+// SetRelayInfo does nothing
+func (ProverBase) SetRelayInfo(path *core.PathEnd, counterparty *core.ProvableChain, counterpartyPath *core.PathEnd) error {
+	return nil
+}
+
+// SetupForRelay does nothing
+func (ProverBase) SetupForRelay(ctx context.Context) error {
+	return nil
+}
+
+// TxNotFound returns the error of GetTxResult for a tx not included in any block yet, which core polls for
+func TxNotFound(txID string) error {
+	return fmt.Errorf("%w: %s", core.ErrTxNotFound, txID)
+}
+
+// WaitForHeight waits until the latest height of the chain reaches `height`, polling it at the average block time of the chain
+func WaitForHeight(ctx context.Context, chain core.ChainInfo, height ibcexported.Height) error {
+	interval := chain.AverageBlockTime()
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		latest, err := chain.LatestHeight()
+		if err != nil {
+			return err
+		}
+		if latest.GTE(height) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the height of %s doesn't reach %v (latest: %v): %w", chain.ChainID(), height, latest, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
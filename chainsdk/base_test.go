@@ -0,0 +1,79 @@
+package chainsdk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/chainsdk"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+type recordingListener struct {
+	msgs []sdk.Msg
+}
+
+func (l *recordingListener) OnSentMsg(msgs []sdk.Msg) error {
+	l.msgs = append(l.msgs, msgs...)
+	return nil
+}
+
+func TestChainBase(t *testing.T) {
+	var b chainsdk.ChainBase
+	if err := b.SetRelayInfo(&core.PathEnd{ChainID: "ibc0", PortID: "transfer", Order: "chaos", Version: "ics20-1"}, nil, nil); err == nil {
+		t.Error("the invalid path is set")
+	}
+	path := &core.PathEnd{ChainID: "ibc0", PortID: "transfer", Order: "unordered", Version: "ics20-1"}
+	if err := b.SetRelayInfo(path, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.Path() != path {
+		t.Errorf("unexpected path: %v", b.Path())
+	}
+
+	// no listener is registered
+	if err := b.NotifySentMsgs([]sdk.Msg{&clienttypes.MsgUpdateClient{}}); err != nil {
+		t.Fatal(err)
+	}
+	listener := &recordingListener{}
+	b.RegisterMsgEventListener(listener)
+	if err := b.NotifySentMsgs([]sdk.Msg{&clienttypes.MsgUpdateClient{}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(listener.msgs) != 1 {
+		t.Errorf("unexpected msgs: %v", listener.msgs)
+	}
+
+	if err := chainsdk.TxNotFound("hash"); !errors.Is(err, core.ErrTxNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWaitForHeight(t *testing.T) {
+	chain, err := mock.NewChain("ibc0", sdk.NewCoins())
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := chain.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chainsdk.WaitForHeight(context.TODO(), chain, latest); err != nil {
+		t.Fatal(err)
+	}
+
+	// the mock chain doesn't produce empty blocks
+	ctx, cancel := context.WithTimeout(context.TODO(), 3*mock.DefaultAverageBlockTime)
+	defer cancel()
+	start := time.Now()
+	if err := chainsdk.WaitForHeight(ctx, chain, latest.Increment()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if time.Since(start) < 3*mock.DefaultAverageBlockTime {
+		t.Error("returned before the deadline")
+	}
+}
@@ -0,0 +1,327 @@
+package chainsdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// ConformanceConfig configures the conformance tests
+type ConformanceConfig struct {
+	// UnknownTxID is the ID of a tx that doesn't exist on the chain (default: the hex string of a zero hash)
+	UnknownTxID string
+
+	// MaxClockDrift is the maximum time by which the block time may be ahead of the local clock (default: 1m)
+	MaxClockDrift time.Duration
+}
+
+func (cfg ConformanceConfig) unknownTxID() string {
+	if cfg.UnknownTxID == "" {
+		return strings.Repeat("00", 32)
+	}
+	return cfg.UnknownTxID
+}
+
+func (cfg ConformanceConfig) maxClockDrift() time.Duration {
+	if cfg.MaxClockDrift == 0 {
+		return time.Minute
+	}
+	return cfg.MaxClockDrift
+}
+
+// RunConformanceTests runs the conformance tests of both the chain and the prover of `chain` as the subtests of `t`.
+// `chain` must be initialized by Init and its path must be set by SetRelayInfo.
+// The tests querying the client, the connection and the channel of the path are skipped if the path doesn't have their identifiers,
+// so a chain on which the path is set up (e.g. by `dev scaffold` or `tx link`) is tested the most.
+// The tests only read the state of the chain.
+func RunConformanceTests(t *testing.T, chain *core.ProvableChain, cfg ConformanceConfig) {
+	t.Run("Chain", func(t *testing.T) {
+		RunChainTests(t, chain.Chain, cfg)
+	})
+	t.Run("Prover", func(t *testing.T) {
+		RunProverTests(t, chain.CurrentProver(), chain.Chain, cfg)
+	})
+}
+
+// RunChainTests runs the conformance tests of `chain` as the subtests of `t` (see RunConformanceTests)
+func RunChainTests(t *testing.T, chain core.Chain, cfg ConformanceConfig) {
+	t.Run("ChainInfo", func(t *testing.T) {
+		if chain.ChainID() == "" {
+			t.Error("ChainID returns an empty ID")
+		}
+		height := latestHeight(t, chain)
+		if height.IsZero() {
+			t.Fatal("LatestHeight returns a zero height")
+		}
+		if next := latestHeight(t, chain); next.LT(height) {
+			t.Errorf("LatestHeight decreases: %v -> %v", height, next)
+		}
+		ts, err := chain.Timestamp(height)
+		if err != nil {
+			t.Fatalf("Timestamp failed at the latest height %v: %v", height, err)
+		}
+		if ts.IsZero() {
+			t.Errorf("Timestamp returns a zero time at %v", height)
+		} else if ts.After(time.Now().Add(cfg.maxClockDrift())) {
+			t.Errorf("Timestamp returns a time in the future at %v: %v", height, ts)
+		}
+		if chain.AverageBlockTime() <= 0 {
+			t.Errorf("AverageBlockTime returns a non-positive duration: %v", chain.AverageBlockTime())
+		}
+	})
+
+	t.Run("Init", func(t *testing.T) {
+		if chain.Codec() == nil {
+			t.Error("Codec returns nil after Init")
+		}
+		addr, err := chain.GetAddress()
+		if err != nil {
+			t.Fatalf("GetAddress failed: %v", err)
+		}
+		if addr.Empty() {
+			t.Error("GetAddress returns an empty address")
+		}
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		path := chain.Path()
+		if path == nil {
+			t.Fatal("Path returns nil after SetRelayInfo")
+		}
+		if path.ChainID != chain.ChainID() {
+			t.Errorf("the chain ID of the path differs from the chain: %s != %s", path.ChainID, chain.ChainID())
+		}
+	})
+
+	t.Run("GetTxResult", func(t *testing.T) {
+		txID := cfg.unknownTxID()
+		if _, err := chain.GetTxResult(context.TODO(), txID); !errors.Is(err, core.ErrTxNotFound) {
+			t.Errorf("GetTxResult of the unknown tx %s must return an error wrapping core.ErrTxNotFound: %v", txID, err)
+		}
+	})
+
+	t.Run("QueryBalance", func(t *testing.T) {
+		addr, err := chain.GetAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		balance, err := chain.QueryBalance(queryContext(t, chain), addr)
+		if err != nil {
+			t.Fatalf("QueryBalance failed: %v", err)
+		}
+		if !balance.IsValid() {
+			t.Errorf("QueryBalance returns invalid coins: %v", balance)
+		}
+	})
+
+	t.Run("QueryClientState", func(t *testing.T) {
+		path := requirePath(t, chain, pathClient)
+		ctx := queryContext(t, chain)
+		res, err := chain.QueryClientState(ctx)
+		if err != nil {
+			t.Fatalf("QueryClientState failed: %v", err)
+		}
+		var clientState ibcexported.ClientState
+		if err := chain.Codec().UnpackAny(res.ClientState, &clientState); err != nil {
+			t.Fatalf("the client state can't be unpacked: %v", err)
+		}
+		if err := clientState.Validate(); err != nil {
+			t.Errorf("the client state of %s is invalid: %v", path.ClientID, err)
+		}
+		consRes, err := chain.QueryClientConsensusState(ctx, clientState.GetLatestHeight())
+		if err != nil {
+			t.Fatalf("QueryClientConsensusState failed at the latest height of the client %v: %v", clientState.GetLatestHeight(), err)
+		}
+		var consensusState ibcexported.ConsensusState
+		if err := chain.Codec().UnpackAny(consRes.ConsensusState, &consensusState); err != nil {
+			t.Errorf("the consensus state can't be unpacked: %v", err)
+		}
+	})
+
+	t.Run("QueryConnection", func(t *testing.T) {
+		path := requirePath(t, chain, pathConnection)
+		res, err := chain.QueryConnection(queryContext(t, chain))
+		if err != nil {
+			t.Fatalf("QueryConnection failed: %v", err)
+		}
+		if res.Connection == nil {
+			t.Fatalf("the connection %s is not found", path.ConnectionID)
+		}
+		if res.Connection.ClientId != path.ClientID {
+			t.Errorf("the client of the connection differs from the path: %s != %s", res.Connection.ClientId, path.ClientID)
+		}
+	})
+
+	t.Run("QueryChannel", func(t *testing.T) {
+		path := requirePath(t, chain, pathChannel)
+		ctx := queryContext(t, chain)
+		res, err := chain.QueryChannel(ctx)
+		if err != nil {
+			t.Fatalf("QueryChannel failed: %v", err)
+		}
+		if res.Channel == nil {
+			t.Fatalf("the channel %s/%s is not found", path.PortID, path.ChannelID)
+		}
+		if len(res.Channel.ConnectionHops) == 0 || res.Channel.ConnectionHops[0] != path.ConnectionID {
+			t.Errorf("the connection of the channel differs from the path: %v != %s", res.Channel.ConnectionHops, path.ConnectionID)
+		}
+		if seqs, err := chain.QueryUnreceivedPackets(ctx, nil); err != nil {
+			t.Errorf("QueryUnreceivedPackets failed without the sequences: %v", err)
+		} else if len(seqs) != 0 {
+			t.Errorf("QueryUnreceivedPackets returns the sequences not asked: %v", seqs)
+		}
+		if seqs, err := chain.QueryUnreceivedAcknowledgements(ctx, nil); err != nil {
+			t.Errorf("QueryUnreceivedAcknowledgements failed without the sequences: %v", err)
+		} else if len(seqs) != 0 {
+			t.Errorf("QueryUnreceivedAcknowledgements returns the sequences not asked: %v", seqs)
+		}
+		if events, err := chain.QueryPacketEventsInRange(context.TODO(), ctx.Height(), ctx.Height()); err != nil {
+			t.Errorf("QueryPacketEventsInRange failed at %v: %v", ctx.Height(), err)
+		} else if events == nil {
+			t.Errorf("QueryPacketEventsInRange returns nil at %v", ctx.Height())
+		}
+	})
+}
+
+// RunProverTests runs the conformance tests of `prover` of `chain` as the subtests of `t` (see RunConformanceTests)
+func RunProverTests(t *testing.T, prover core.Prover, chain core.Chain, cfg ConformanceConfig) {
+	t.Run("GetLatestFinalizedHeader", func(t *testing.T) {
+		header := finalizedHeader(t, prover)
+		if err := header.ValidateBasic(); err != nil {
+			t.Errorf("the latest finalized header is invalid: %v", err)
+		}
+		if latest := latestHeight(t, chain); header.GetHeight().GT(latest) {
+			t.Errorf("the latest finalized height is greater than the latest height: %v > %v", header.GetHeight(), latest)
+		}
+		msg, err := clienttypes.PackClientMessage(header)
+		if err != nil {
+			t.Fatalf("the header can't be packed: %v", err)
+		}
+		var unpacked ibcexported.ClientMessage
+		if err := chain.Codec().UnpackAny(msg, &unpacked); err != nil {
+			t.Errorf("the header isn't registered in the codec: %v", err)
+		}
+	})
+
+	t.Run("CreateInitialLightClientState", func(t *testing.T) {
+		height := finalizedHeader(t, prover).GetHeight()
+		for _, h := range []ibcexported.Height{nil, height} {
+			clientState, consensusState, err := prover.CreateInitialLightClientState(h)
+			if err != nil {
+				t.Fatalf("CreateInitialLightClientState failed at %v: %v", h, err)
+			}
+			if clientState == nil || consensusState == nil {
+				t.Fatalf("CreateInitialLightClientState returns nil at %v", h)
+			}
+			if err := clientState.Validate(); err != nil {
+				t.Errorf("the client state is invalid: %v", err)
+			}
+			if err := consensusState.ValidateBasic(); err != nil {
+				t.Errorf("the consensus state is invalid: %v", err)
+			}
+			if clientState.ClientType() != consensusState.ClientType() {
+				t.Errorf("the types of the client state and the consensus state differ: %s != %s", clientState.ClientType(), consensusState.ClientType())
+			}
+			if h == nil && clientState.GetLatestHeight().GT(height) {
+				t.Errorf("the client state is ahead of the latest finalized height: %v > %v", clientState.GetLatestHeight(), height)
+			} else if h != nil && !clientState.GetLatestHeight().EQ(h) {
+				t.Errorf("the client state isn't at the given height: %v != %v", clientState.GetLatestHeight(), h)
+			}
+			msg, err := clienttypes.NewMsgCreateClient(clientState, consensusState, "signer")
+			if err != nil {
+				t.Fatalf("the states can't be packed: %v", err)
+			}
+			var unpackedClientState ibcexported.ClientState
+			if err := chain.Codec().UnpackAny(msg.ClientState, &unpackedClientState); err != nil {
+				t.Errorf("the client state isn't registered in the codec: %v", err)
+			}
+			var unpackedConsensusState ibcexported.ConsensusState
+			if err := chain.Codec().UnpackAny(msg.ConsensusState, &unpackedConsensusState); err != nil {
+				t.Errorf("the consensus state isn't registered in the codec: %v", err)
+			}
+		}
+	})
+
+	t.Run("ProveState", func(t *testing.T) {
+		path := requirePath(t, chain, pathClient)
+		ctx := core.NewQueryContext(context.TODO(), finalizedHeader(t, prover).GetHeight())
+		res, err := chain.QueryClientState(ctx)
+		if err != nil {
+			t.Fatalf("QueryClientState failed: %v", err)
+		}
+		value, err := chain.Codec().Marshal(res.ClientState)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, proofHeight, err := prover.ProveState(ctx, host.FullClientStatePath(path.ClientID), value)
+		if err != nil {
+			t.Fatalf("ProveState failed for the client state of %s: %v", path.ClientID, err)
+		}
+		if len(proof) == 0 {
+			t.Error("ProveState returns an empty proof")
+		}
+		if proofHeight.IsZero() {
+			t.Error("ProveState returns a zero proof height")
+		}
+	})
+}
+
+// pathLevel is the identifiers of the path required by a test
+type pathLevel int
+
+const (
+	pathClient pathLevel = iota
+	pathConnection
+	pathChannel
+)
+
+// requirePath skips the test unless the path of the chain has the identifiers up to `level`
+func requirePath(t *testing.T, chain core.Chain, level pathLevel) *core.PathEnd {
+	t.Helper()
+	path := chain.Path()
+	if path == nil {
+		t.Fatal("Path returns nil after SetRelayInfo")
+	}
+	for i, id := range []string{path.ClientID, path.ConnectionID, path.ChannelID}[:level+1] {
+		if id == "" {
+			t.Skipf("the path has no %s", []string{"client", "connection", "channel"}[i])
+		}
+	}
+	return path
+}
+
+func latestHeight(t *testing.T, chain core.ChainInfo) ibcexported.Height {
+	t.Helper()
+	height, err := chain.LatestHeight()
+	if err != nil {
+		t.Fatalf("LatestHeight failed: %v", err)
+	}
+	if height == nil {
+		t.Fatal("LatestHeight returns nil")
+	}
+	return height
+}
+
+func queryContext(t *testing.T, chain core.ChainInfo) core.QueryContext {
+	t.Helper()
+	return core.NewQueryContext(context.TODO(), latestHeight(t, chain))
+}
+
+func finalizedHeader(t *testing.T, prover core.Prover) core.Header {
+	t.Helper()
+	header, err := prover.GetLatestFinalizedHeader()
+	if err != nil {
+		t.Fatalf("GetLatestFinalizedHeader failed: %v", err)
+	}
+	if header == nil {
+		t.Fatal("GetLatestFinalizedHeader returns nil")
+	}
+	return header
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/spf13/cobra"
+)
+
+func devBenchCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagPackets       = "packets"
+		flagMaxCycles     = "max-cycles"
+		flagMinThroughput = "min-throughput"
+		benchPath         = "bench"
+		benchDenom        = "stake"
+	)
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "measure the relay throughput between in-memory mock chains",
+		Long: strings.TrimSpace(`Wire a pair of in-memory mock chains with mock provers in-process as "dev loopback" does, send K ICS-20 packets
+in a transaction and relay the packets and their acknowledgements, measuring the relay throughput, the latency of the proof
+queries and the allocations. The chains and the provers cost almost nothing, so the results mostly reflect the cost of
+the core of the relayer, and comparing them between builds catches its performance regressions before a release.
+The command fails if the throughput is below --min-throughput.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			packets, err := cmd.Flags().GetInt(flagPackets)
+			if err != nil {
+				return err
+			}
+			maxCycles, err := cmd.Flags().GetInt(flagMaxCycles)
+			if err != nil {
+				return err
+			}
+			minThroughput, err := cmd.Flags().GetFloat64(flagMinThroughput)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format: %s", output)
+			}
+			if packets <= 0 {
+				return fmt.Errorf("packets must be positive: %d", packets)
+			}
+
+			proofs := &proofLatencies{}
+			supply := sdk.NewCoins(sdk.NewInt64Coin(benchDenom, int64(packets)))
+			src, dst, err := newLoopbackChains(ctx, benchPath, supply, func(prover core.Prover) core.Prover {
+				return &benchProver{Prover: prover, latencies: proofs}
+			})
+			if err != nil {
+				return err
+			}
+			report := &loopbackReport{}
+			if err := runLoopbackHandshake(cmd.Context(), report, benchPath, src, dst); err != nil {
+				report.print(src, dst)
+				return fmt.Errorf("bench failed: %w", err)
+			}
+
+			result, err := runBench(cmd.Context(), src, dst, sdk.NewInt64Coin(benchDenom, 1), packets, maxCycles, proofs)
+			if err != nil {
+				return fmt.Errorf("bench failed: %w", err)
+			}
+			if output == "json" {
+				bz, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(bz))
+			} else {
+				result.print()
+			}
+			if minThroughput > 0 && result.Throughput < minThroughput {
+				return fmt.Errorf("the throughput is below the minimum: %.1f < %.1f packets/s", result.Throughput, minThroughput)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int(flagPackets, 100, "number of the packets relayed")
+	cmd.Flags().Int(flagMaxCycles, 20, "maximum number of the relay cycles to relay all the packets and the acknowledgements")
+	cmd.Flags().Float64(flagMinThroughput, 0, "minimum throughput in packets per second, below which the command fails (0 disables the check)")
+	cmd.Flags().String(flagOutput, "text", "output format (text|json)")
+	return cmd
+}
+
+// benchResult is the result of `dev bench`
+type benchResult struct {
+	Packets int `json:"packets"`
+	Cycles  int `json:"cycles"`
+	// Elapsed is the time to relay all the packets and their acknowledgements
+	Elapsed time.Duration `json:"elapsed"`
+	// Throughput is the number of the packets relayed (with their acknowledgements) per second
+	Throughput float64      `json:"throughput"`
+	Proofs     proofSummary `json:"proofs"`
+	// Allocs and AllocBytes are the heap allocations during the relay
+	Allocs              uint64 `json:"allocs"`
+	AllocBytes          uint64 `json:"alloc_bytes"`
+	AllocsPerPacket     uint64 `json:"allocs_per_packet"`
+	AllocBytesPerPacket uint64 `json:"alloc_bytes_per_packet"`
+}
+
+func (r *benchResult) print() {
+	fmt.Printf("packets:     %d\n", r.Packets)
+	fmt.Printf("cycles:      %d\n", r.Cycles)
+	fmt.Printf("elapsed:     %v\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Printf("throughput:  %.1f packets/s\n", r.Throughput)
+	fmt.Printf("proofs:      count=%d mean=%v p50=%v p95=%v max=%v\n", r.Proofs.Count, r.Proofs.Mean, r.Proofs.P50, r.Proofs.P95, r.Proofs.Max)
+	fmt.Printf("allocations: %d (%d per packet), %d bytes (%d per packet)\n", r.Allocs, r.AllocsPerPacket, r.AllocBytes, r.AllocBytesPerPacket)
+}
+
+// runBench sends `packets` packets of `coin` from src to dst in a transaction and relays them and their acknowledgements
+func runBench(ctx context.Context, src, dst *core.ProvableChain, coin sdk.Coin, packets, maxCycles int, proofs *proofLatencies) (*benchResult, error) {
+	srcAddr, err := src.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	dstAddr, err := dst.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	dstHeight, err := dst.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	timeoutHeight := core.HeightAfter(dstHeight, 1000)
+	msgs := make([]sdk.Msg, 0, packets)
+	seqs := make([]uint64, 0, packets)
+	for i := 0; i < packets; i++ {
+		msgs = append(msgs, src.Path().MsgTransfer(dst.Path(), coin, dstAddr.String(), srcAddr, timeoutHeight, 0, ""))
+		seqs = append(seqs, uint64(i+1))
+	}
+	if _, err := src.SendMsgs(msgs); err != nil {
+		return nil, fmt.Errorf("failed to send the packets: %w", err)
+	}
+
+	sh, err := core.NewSyncHeaders(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), src, dst, sh, 0, 0, 0, 0, 0)
+	voucher := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(dst.Path().PortID, dst.Path().ChannelID, coin.Denom)).IBCDenom()
+	amount := coin.Amount.Int64() * int64(packets)
+
+	proofs.reset()
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	cycles := 0
+	for done := false; !done; {
+		if cycles == maxCycles {
+			return nil, fmt.Errorf("the packets weren't relayed in %d cycles", maxCycles)
+		}
+		if err := srv.Serve(ctx); err != nil {
+			return nil, err
+		}
+		cycles++
+		if done, err = loopbackCompleted(src, dst, amount, voucher, seqs); err != nil {
+			return nil, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	allocs, allocBytes := after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc
+	return &benchResult{
+		Packets:             packets,
+		Cycles:              cycles,
+		Elapsed:             elapsed,
+		Throughput:          float64(packets) / elapsed.Seconds(),
+		Proofs:              proofs.summary(),
+		Allocs:              allocs,
+		AllocBytes:          allocBytes,
+		AllocsPerPacket:     allocs / uint64(packets),
+		AllocBytesPerPacket: allocBytes / uint64(packets),
+	}, nil
+}
+
+// benchProver measures the latency of the proofs of the prover
+type benchProver struct {
+	core.Prover
+	latencies *proofLatencies
+}
+
+func (pr *benchProver) ProveState(ctx core.QueryContext, path string, value []byte) ([]byte, clienttypes.Height, error) {
+	start := time.Now()
+	defer func() { pr.latencies.add(time.Since(start)) }()
+	return pr.Prover.ProveState(ctx, path, value)
+}
+
+// proofLatencies records the latencies of the proofs of both chains
+type proofLatencies struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+type proofSummary struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	Max   time.Duration `json:"max"`
+}
+
+func (l *proofLatencies) add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.latencies = append(l.latencies, d)
+}
+
+func (l *proofLatencies) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.latencies = nil
+}
+
+func (l *proofLatencies) summary() proofSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(l.latencies)
+	if n == 0 {
+		return proofSummary{}
+	}
+	sorted := append([]time.Duration(nil), l.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return proofSummary{
+		Count: n,
+		Mean:  total / time.Duration(n),
+		P50:   sorted[n/2],
+		P95:   sorted[(n*95)/100],
+		Max:   sorted[n-1],
+	}
+}
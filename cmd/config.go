@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func configCmd(ctx *config.Context) *cobra.Command {
@@ -19,6 +24,9 @@ func configCmd(ctx *config.Context) *cobra.Command {
 	cmd.AddCommand(
 		configShowCmd(ctx),
 		configInitCmd(ctx),
+		configMigrateCmd(ctx),
+		configDiffCmd(ctx),
+		configSchemaCmd(ctx),
 	)
 
 	return cmd
@@ -29,7 +37,7 @@ func configInitCmd(ctx *config.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "init",
 		Aliases: []string{"i"},
-		Short:   "Creates a default home directory at path defined by --home",
+		Short:   "Creates a default config file at the path defined by --home and --config",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := ctx.Config.CreateConfig(); err != nil {
 				return err
@@ -62,3 +70,176 @@ func configShowCmd(ctx *config.Context) *cobra.Command {
 
 	return cmd
 }
+
+// Command for upgrading the config file to the current layout
+func configMigrateCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrades the config file to the layout supported by this relayer",
+		Long: strings.TrimSpace(`Upgrades the config file at the --home and --config location to the layout supported by this relayer.
+The original config file is kept as a backup file with the suffix ".bak".`),
+		Args: cobra.NoArgs,
+		// the config file can't be loaded before it is migrated
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return viper.BindPFlags(cmd.Flags())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgPath := configFilePath()
+			bz, err := os.ReadFile(cfgPath)
+			if err != nil {
+				return err
+			}
+			out, from, err := config.MigrateConfig(bz, ctx.Modules)
+			if err != nil {
+				return err
+			}
+			if from == config.CurrentConfigVersion {
+				fmt.Printf("config is already at version %d\n", from)
+				return nil
+			}
+			if err := utils.WriteFileAtomic(cfgPath+".bak", bz, 0600); err != nil {
+				return fmt.Errorf("failed to back up the config file: %v", err)
+			}
+			if err := utils.WriteFileAtomic(cfgPath, out, 0600); err != nil {
+				return err
+			}
+			fmt.Printf("migrated config from version %d to %d\n", from, config.CurrentConfigVersion)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// Command for previewing the changes of a new config file from the current one
+func configDiffCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [new-config-file]",
+		Short: "Shows the changes of a new config file from the current configuration and how to apply them",
+		Long: strings.TrimSpace(`Shows the changes of a new config file from the current configuration (the config file at the --home and --config
+location with the overrides by --set) before applying it: the chains and paths added or removed, and the fields changed.
+Each change is shown with the relay services affected by it and what to do to apply it: the services must be restarted
+for the change to take effect as the config is read only when a service starts.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format: %s", output)
+			}
+			bz, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			newConfig, err := config.ParseConfig(ctx.Codec, bz)
+			if err != nil {
+				return fmt.Errorf("failed to parse the new config file %s: %w", args[0], err)
+			}
+			changes, err := config.DiffConfigs(ctx.Config, newConfig)
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				if changes == nil {
+					changes = []config.ConfigChange{}
+				}
+				out, err := json.Marshal(changes)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+				return nil
+			}
+			if len(changes) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "no changes")
+				return nil
+			}
+			restarts := make(map[string]bool)
+			for _, c := range changes {
+				fmt.Fprintln(cmd.OutOrStdout(), formatConfigChange(c))
+				if c.Impact == config.ImpactRestart {
+					for _, p := range c.Paths {
+						restarts[p] = true
+					}
+				}
+			}
+			if len(restarts) > 0 {
+				var paths []string
+				for p := range restarts {
+					paths = append(paths, p)
+				}
+				sort.Strings(paths)
+				fmt.Fprintf(cmd.OutOrStdout(), "\nthe relay services of the paths to restart: %s\n", strings.Join(paths, ", "))
+			}
+			return nil
+		},
+	}
+	return outputFlag(cmd)
+}
+
+func formatConfigChange(c config.ConfigChange) string {
+	var sb strings.Builder
+	target := c.Section
+	if c.Name != "" {
+		target += " " + c.Name
+	}
+	switch {
+	case c.Key != "":
+		fmt.Fprintf(&sb, "~ %s: %s: %s -> %s", target, c.Key, orAbsent(c.Old), orAbsent(c.New))
+	case c.New == "" && c.Impact != config.ImpactStart:
+		fmt.Fprintf(&sb, "- %s", target)
+	default:
+		fmt.Fprintf(&sb, "+ %s", target)
+	}
+	switch c.Impact {
+	case config.ImpactNone:
+		sb.WriteString(" (no relay service affected)")
+	default:
+		fmt.Fprintf(&sb, " (%s: %s)", c.Impact, strings.Join(c.Paths, ", "))
+	}
+	return sb.String()
+}
+
+func orAbsent(v string) string {
+	if v == "" {
+		return "<absent>"
+	}
+	return v
+}
+
+// Command for printing the JSON schema of the config file
+func configSchemaCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Prints the JSON schema of the config file",
+		Long: strings.TrimSpace(`Prints the JSON schema of the config file, with which editors and CI can validate config files.
+The chain and prover configs are described by the modules built into this relayer, and every object of the config
+rejects the fields it doesn't define, as the relayer does when it loads the config file.`),
+		Args: cobra.NoArgs,
+		// the schema doesn't depend on the config file
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return viper.BindPFlags(cmd.Flags())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := config.ConfigSchema(ctx.Codec.InterfaceRegistry())
+			if err != nil {
+				return err
+			}
+			out, err := json.MarshalIndent(schema, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+	return cmd
+}
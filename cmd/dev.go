@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	mockchain "github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+	"github.com/spf13/cobra"
+)
+
+func devCmd(ctx *config.Context) *cobra.Command {
+	const flagLogLevel = "log-level"
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "commands for the development of the relayer",
+		RunE:  noCommand,
+		// the dev commands don't read the config file
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			level, err := cmd.Flags().GetString(flagLogLevel)
+			if err != nil {
+				return err
+			}
+			if err := log.InitLogger(level, "text", "stderr"); err != nil {
+				return err
+			}
+			if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+				return fmt.Errorf("failed to initialize the metrics: %v", err)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().String(flagLogLevel, "ERROR", "log level of the dev commands")
+
+	cmd.AddCommand(
+		devLoopbackCmd(ctx),
+		devBenchCmd(ctx),
+		devFaucetRequestCmd(ctx),
+		devScaffoldCmd(ctx),
+	)
+
+	return cmd
+}
+
+func devLoopbackCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagAmount     = "amount"
+		flagMaxCycles  = "max-cycles"
+		loopbackPath   = "loopback"
+		loopbackDenom  = "stake"
+		loopbackSupply = 1_000_000
+	)
+	cmd := &cobra.Command{
+		Use:   "loopback",
+		Short: "run a handshake and a packet round trip between in-memory mock chains",
+		Long: strings.TrimSpace(`Wire a pair of in-memory mock chains with mock provers in-process, create the clients,
+the connection and an ICS-20 channel between them, transfer tokens and relay the packet and its acknowledgement.
+A report is printed at the end and the command fails if any step fails, so that it can be used as a smoke test
+of a build of the relayer without any external chain.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			amount, err := cmd.Flags().GetInt64(flagAmount)
+			if err != nil {
+				return err
+			}
+			maxCycles, err := cmd.Flags().GetInt(flagMaxCycles)
+			if err != nil {
+				return err
+			}
+			coin := sdk.NewInt64Coin(loopbackDenom, amount)
+			if !coin.IsPositive() {
+				return fmt.Errorf("amount must be positive: %d", amount)
+			}
+
+			supply := sdk.NewCoins(sdk.NewInt64Coin(loopbackDenom, loopbackSupply))
+			src, dst, err := newLoopbackChains(ctx, loopbackPath, supply, nil)
+			if err != nil {
+				return err
+			}
+
+			report := &loopbackReport{}
+			err = runLoopback(cmd.Context(), report, loopbackPath, src, dst, coin, maxCycles)
+			report.print(src, dst)
+			if err != nil {
+				return fmt.Errorf("loopback failed: %w", err)
+			}
+			fmt.Println("PASS")
+			return nil
+		},
+	}
+	cmd.Flags().Int64(flagAmount, 100, "amount of the tokens transferred in the packet round trip")
+	cmd.Flags().Int(flagMaxCycles, 10, "maximum number of the relay cycles to complete the packet round trip")
+	return cmd
+}
+
+func devFaucetRequestCmd(ctx *config.Context) *cobra.Command {
+	const flagAmount = "amount"
+	cmd := &cobra.Command{
+		Use:   "faucet-request [chain-id]",
+		Short: "request the faucet of a chain to top up the relayer account",
+		Long: strings.TrimSpace(`Request the faucet configured for the chain in the "faucets" section of the config file
+to send coins to the relayer account, e.g. to fund the relayer on a testnet in CI and devnet workflows.
+The faucet is either an HTTP faucet (type "http") or a funded key in the keyring of the chain (type "key").
+The relay service also requests the faucet automatically when the balance falls below the min-balance of the faucet.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// unlike the other dev commands, the command reads the config file for the chain and the faucet
+			if err := ctx.Config.InitConfig(ctx, homePath, configPath, debug, configOverrides...); err != nil {
+				return core.NewFailure(core.FailureConfig, fmt.Errorf("failed to initialize the configuration: %w", err))
+			}
+			chainID := args[0]
+			chain, err := ctx.Config.GetChain(chainID)
+			if err != nil {
+				return core.NewFailure(core.FailureConfig, err)
+			}
+			faucet, ok := ctx.Config.Faucets[chainID]
+			if !ok {
+				return core.NewFailure(core.FailureConfig, fmt.Errorf("no faucet is configured for chain %s", chainID))
+			}
+			cfg := *faucet
+			if amount, err := cmd.Flags().GetString(flagAmount); err != nil {
+				return err
+			} else if amount != "" {
+				cfg.Amount = amount
+			}
+			res, err := core.RequestFaucet(cmd.Context(), chain, &cfg)
+			if err != nil {
+				return err
+			}
+			out, err := json.Marshal(res)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagAmount, "", "coins to request instead of the amount of the faucet config (e.g. 1000000stake)")
+	return cmd
+}
+
+// newLoopbackChains returns a pair of in-memory mock chains with mock provers on the path `pathName`, whose accounts have `supply`.
+// If `wrapProver` is not nil, it wraps the prover of each chain (e.g. to measure the proofs).
+func newLoopbackChains(ctx *config.Context, pathName string, supply sdk.Coins, wrapProver func(core.Prover) core.Prover) (src, dst *core.ProvableChain, err error) {
+	mockchain.RegisterInterfaces(ctx.Codec.InterfaceRegistry())
+	mockprover.RegisterInterfaces(ctx.Codec.InterfaceRegistry())
+	path := &core.Path{
+		Src:      loopbackPathEnd("ibc0"),
+		Dst:      loopbackPathEnd("ibc1"),
+		Strategy: &core.StrategyCfg{Type: "naive"},
+	}
+	core.SetCoreConfig(&loopbackConfig{paths: core.Paths{pathName: path}})
+
+	var chains [2]*core.ProvableChain
+	for i, pe := range []*core.PathEnd{path.Src, path.Dst} {
+		chain, err := mockchain.NewChain(pe.ChainID, supply)
+		if err != nil {
+			return nil, nil, err
+		}
+		var prover core.Prover = mockprover.NewProver(chain, mockprover.ProverConfig{})
+		if wrapProver != nil {
+			prover = wrapProver(prover)
+		}
+		chains[i] = core.NewProvableChain(chain, prover)
+		if err := chains[i].Init(homePath, 10*time.Second, ctx.Codec, debug); err != nil {
+			return nil, nil, err
+		}
+	}
+	src, dst = chains[0], chains[1]
+	if err := src.SetRelayInfo(path.Src, dst, path.Dst); err != nil {
+		return nil, nil, err
+	}
+	if err := dst.SetRelayInfo(path.Dst, src, path.Src); err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+func loopbackPathEnd(chainID string) *core.PathEnd {
+	return &core.PathEnd{
+		ChainID: chainID,
+		PortID:  transfertypes.PortID,
+		Order:   "unordered",
+		Version: transfertypes.Version,
+	}
+}
+
+// loopbackConfig is the core config of `dev loopback`, which keeps the generated identifiers only in memory
+type loopbackConfig struct {
+	paths core.Paths
+}
+
+var _ core.ConfigI = (*loopbackConfig)(nil)
+
+func (c *loopbackConfig) UpdateConfigID(pathName string, chainID string, configID core.ConfigIDType, id string) error {
+	path, err := c.paths.Get(pathName)
+	if err != nil {
+		return err
+	}
+	var pathEnd *core.PathEnd
+	switch chainID {
+	case path.Src.ChainID:
+		pathEnd = path.Src
+	case path.Dst.ChainID:
+		pathEnd = path.Dst
+	default:
+		return fmt.Errorf("chain %s is not on the path %s", chainID, pathName)
+	}
+	switch configID {
+	case core.ConfigIDClient:
+		pathEnd.ClientID = id
+	case core.ConfigIDConnection:
+		pathEnd.ConnectionID = id
+	case core.ConfigIDChannel:
+		pathEnd.ChannelID = id
+	case core.ConfigIDChannelVersion:
+		pathEnd.Version = id
+	}
+	return nil
+}
+
+// loopbackReport records the steps of `dev loopback`
+type loopbackReport struct {
+	steps []loopbackStep
+}
+
+type loopbackStep struct {
+	name    string
+	elapsed time.Duration
+	err     error
+}
+
+// run runs `f` as a step and records its result
+func (r *loopbackReport) run(name string, f func() error) error {
+	start := time.Now()
+	err := f()
+	r.steps = append(r.steps, loopbackStep{name: name, elapsed: time.Since(start), err: err})
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func (r *loopbackReport) print(src, dst *core.ProvableChain) {
+	fmt.Println("steps:")
+	for _, s := range r.steps {
+		result := "ok"
+		if s.err != nil {
+			result = "FAILED: " + s.err.Error()
+		}
+		fmt.Printf("  %-12s %-10v %s\n", s.name, s.elapsed.Round(time.Millisecond), result)
+	}
+	fmt.Println("chains:")
+	for _, c := range []*core.ProvableChain{src, dst} {
+		pe := c.Path()
+		fmt.Printf("  %s: client=%s connection=%s channel=%s/%s", c.ChainID(), pe.ClientID, pe.ConnectionID, pe.PortID, pe.ChannelID)
+		if balance, err := queryLoopbackBalance(c); err != nil {
+			fmt.Printf(" balance=(error: %v)\n", err)
+		} else {
+			fmt.Printf(" balance=%v\n", balance)
+		}
+	}
+}
+
+// loopbackBackoffPolicy waits for a block of the mock chains between the steps of the handshakes
+var loopbackBackoffPolicy = core.BackoffPolicy{
+	InitialInterval: mockchain.DefaultAverageBlockTime.String(),
+	MaxInterval:     "1s",
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// runLoopbackHandshake creates the clients, the connection and the channel between the mock chains
+func runLoopbackHandshake(ctx context.Context, report *loopbackReport, pathName string, src, dst *core.ProvableChain) error {
+	if err := report.run("clients", func() error {
+		return core.CreateClients(ctx, pathName, src, dst, nil, nil)
+	}); err != nil {
+		return err
+	}
+	if err := report.run("connection", func() error {
+		return core.CreateConnection(ctx, pathName, src, dst, loopbackBackoffPolicy)
+	}); err != nil {
+		return err
+	}
+	return report.run("channel", func() error {
+		return core.CreateChannel(ctx, pathName, src, dst, loopbackBackoffPolicy)
+	})
+}
+
+func runLoopback(ctx context.Context, report *loopbackReport, pathName string, src, dst *core.ProvableChain, coin sdk.Coin, maxCycles int) error {
+	if err := runLoopbackHandshake(ctx, report, pathName, src, dst); err != nil {
+		return err
+	}
+
+	dstAddr, err := dst.GetAddress()
+	if err != nil {
+		return err
+	}
+	voucher := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(dst.Path().PortID, dst.Path().ChannelID, coin.Denom)).IBCDenom()
+	if err := report.run("transfer", func() error {
+		return core.SendTransferMsg(src, dst, coin, dstAddr.String(), 0, 0)
+	}); err != nil {
+		return err
+	}
+
+	return report.run("relay", func() error {
+		sh, err := core.NewSyncHeaders(src, dst)
+		if err != nil {
+			return err
+		}
+		srv := core.NewRelayService(core.NewNaiveStrategy(false, false), src, dst, sh, 0, 0, 0, 0, 0)
+		for i := 0; i < maxCycles; i++ {
+			if err := srv.Serve(ctx); err != nil {
+				return err
+			}
+			done, err := loopbackCompleted(src, dst, coin.Amount.Int64(), voucher, []uint64{1})
+			if err != nil {
+				return err
+			} else if done {
+				return nil
+			}
+		}
+		return fmt.Errorf("the packet round trip didn't complete in %d cycles", maxCycles)
+	})
+}
+
+// loopbackCompleted returns true if the voucher of `amount` is received on dst and the packets `seqs` are acknowledged on src
+func loopbackCompleted(src, dst *core.ProvableChain, amount int64, voucher string, seqs []uint64) (bool, error) {
+	dstBalance, err := queryLoopbackBalance(dst)
+	if err != nil {
+		return false, err
+	}
+	if dstBalance.AmountOf(voucher).Int64() != amount {
+		return false, nil
+	}
+	srcHeight, err := src.LatestHeight()
+	if err != nil {
+		return false, err
+	}
+	unacked, err := src.QueryUnreceivedAcknowledgements(core.NewQueryContext(context.TODO(), srcHeight), seqs)
+	if err != nil {
+		return false, err
+	}
+	return len(unacked) == 0, nil
+}
+
+func queryLoopbackBalance(c *core.ProvableChain) (sdk.Coins, error) {
+	h, err := c.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := c.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	return c.QueryBalance(core.NewQueryContext(context.TODO(), h), addr)
+}
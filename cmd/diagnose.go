@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/spf13/cobra"
+)
+
+func diagnoseCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagMaxRPCLag          = "max-rpc-lag"
+		flagClientExpiryMargin = "client-expiry-margin"
+		flagStaleRelay         = "stale-relay"
+		flagNoColor            = "no-color"
+	)
+	defaults := core.DefaultDiagnoseOptions()
+	cmd := &cobra.Command{
+		Use:   "diagnose [path-name]",
+		Short: "diagnose the problems of a path and suggest how to fix them",
+		Long: strings.TrimSpace(`Run a battery of checks on a path (RPC reachability and lag, keys and balances, client expiry,
+connection and channel states, pending packets and the pause state) and print the likely causes
+of the problems found with the commands suggested to fix them.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
+			c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format: %s", output)
+			}
+			noColor, err := cmd.Flags().GetBool(flagNoColor)
+			if err != nil {
+				return err
+			}
+			var opts core.DiagnoseOptions
+			if opts.MaxRPCLag, err = cmd.Flags().GetDuration(flagMaxRPCLag); err != nil {
+				return err
+			}
+			if opts.ClientExpiryMargin, err = cmd.Flags().GetDuration(flagClientExpiryMargin); err != nil {
+				return err
+			}
+			if opts.StaleRelay, err = cmd.Flags().GetDuration(flagStaleRelay); err != nil {
+				return err
+			}
+
+			checks := core.CheckPath(c[src], c[dst])
+			snapshot, err := queryPathSnapshot(ctx, pathName)
+			if err != nil {
+				return err
+			}
+			pause, err := core.LoadPauseState(core.PauseStateFile(homePath, pathName))
+			if err != nil {
+				return err
+			}
+			findings := core.Diagnose(pathName, checks, snapshot, pause, opts, time.Now())
+
+			if output == "json" {
+				out, err := json.Marshal(findings)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+			} else {
+				printDiagnosis(findings, noColor)
+			}
+
+			problems := 0
+			for _, f := range findings {
+				if f.Severity == core.DiagnosisError {
+					problems++
+				}
+			}
+			if problems > 0 {
+				return fmt.Errorf("%d problems found on path %s", problems, pathName)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Duration(flagMaxRPCLag, defaults.MaxRPCLag, "maximum age of the latest block before the RPC node is regarded as lagging")
+	cmd.Flags().Duration(flagClientExpiryMargin, defaults.ClientExpiryMargin, "time before the expiry of a client from which it is regarded as expiring soon")
+	cmd.Flags().Duration(flagStaleRelay, defaults.StaleRelay, "time since the last relay after which pending packets are regarded as stuck")
+	cmd.Flags().Bool(flagNoColor, false, "disable colored output")
+	return outputFlag(cmd)
+}
+
+func printDiagnosis(findings []core.DiagnosisFinding, noColor bool) {
+	marks := map[core.DiagnosisSeverity]string{
+		core.DiagnosisOK:      "\x1b[32m✔\x1b[0m",
+		core.DiagnosisWarning: "\x1b[33m!\x1b[0m",
+		core.DiagnosisError:   "\x1b[31m✘\x1b[0m",
+	}
+	if noColor {
+		marks = map[core.DiagnosisSeverity]string{
+			core.DiagnosisOK:      "✔",
+			core.DiagnosisWarning: "!",
+			core.DiagnosisError:   "✘",
+		}
+	}
+	for _, f := range findings {
+		scope := "path"
+		if f.ChainID != "" {
+			scope = f.ChainID
+		}
+		fmt.Printf("%s [%s] %s: %s\n", marks[f.Severity], scope, f.Check, f.Detail)
+		if len(f.Causes) > 0 {
+			fmt.Println("    likely causes:")
+			for _, cause := range f.Causes {
+				fmt.Printf("      - %s\n", cause)
+			}
+		}
+		if len(f.Suggestions) > 0 {
+			fmt.Println("    suggestions:")
+			for _, s := range f.Suggestions {
+				fmt.Printf("      - %s\n", s)
+			}
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -12,10 +16,23 @@ const (
 	flagJSON                = "json"
 	flagYAML                = "yaml"
 	flagFile                = "file"
-	flagTimeout             = "timeout"
 	flagTimeoutHeightOffset = "timeout-height-offset"
 	flagTimeoutTimeOffset   = "timeout-time-offset"
+	flagTimeoutHeight       = "timeout-height"
+	flagTimeoutTimestamp    = "timeout-timestamp"
 	flagIBCDenoms           = "ibc-denoms"
+	flagOutput              = "output"
+	flagTimeout             = "timeout"
+	flagDeadline            = "deadline"
+	flagSrcHeight           = "src-height"
+	flagDstHeight           = "dst-height"
+	flagFromHeight          = "from-height"
+	flagToHeight            = "to-height"
+
+	flagBackoffInitialInterval = "backoff-initial-interval"
+	flagBackoffMaxInterval     = "backoff-max-interval"
+	flagBackoffMultiplier      = "backoff-multiplier"
+	flagBackoffJitter          = "backoff-jitter"
 )
 
 func heightFlag(cmd *cobra.Command) *cobra.Command {
@@ -50,25 +67,90 @@ func jsonFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
-func timeoutFlag(cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().StringP(flagTimeout, "o", "1s", "timeout between relayer runs")
-	if err := viper.BindPFlag(flagTimeout, cmd.Flags().Lookup(flagTimeout)); err != nil {
+func deadlineFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagDeadline, 0, "abort the command after this duration with the last observed states (0 means no deadline)")
+	return cmd
+}
+
+// getDeadlineContext returns a context that is canceled after the duration given by the deadline flag
+func getDeadlineContext(cmd *cobra.Command) (context.Context, context.CancelFunc, error) {
+	to, err := cmd.Flags().GetDuration(flagDeadline)
+	if err != nil {
+		return nil, nil, err
+	}
+	if to <= 0 {
+		ctx, cancel := context.WithCancel(cmd.Context())
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), to)
+	return ctx, cancel, nil
+}
+
+func backoffFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Duration(flagBackoffInitialInterval, 0, "interval to wait between handshake steps (overrides the global config)")
+	cmd.Flags().Duration(flagBackoffMaxInterval, 0, "maximum interval to wait while the handshake doesn't progress (overrides the global config)")
+	cmd.Flags().Float64(flagBackoffMultiplier, 0, "factor by which the interval grows while the handshake doesn't progress (overrides the global config)")
+	cmd.Flags().Float64(flagBackoffJitter, 0, "fraction of the interval randomly subtracted from it (overrides the global config)")
+	// --timeout was the fixed interval between handshake steps before the backoff was introduced
+	cmd.Flags().StringP(flagTimeout, "o", "", "interval to wait between handshake steps")
+	if err := cmd.Flags().MarkDeprecated(flagTimeout, "use --"+flagBackoffInitialInterval+" instead"); err != nil {
 		panic(err)
 	}
 	return cmd
 }
 
-func getTimeout(cmd *cobra.Command) (time.Duration, error) {
-	to, err := cmd.Flags().GetString(flagTimeout)
+// getBackoffPolicy returns the backoff policy in the global config overridden by the flags set explicitly
+func getBackoffPolicy(cmd *cobra.Command, ctx *config.Context) (core.BackoffPolicy, error) {
+	policy, err := ctx.Config.Global.BackoffPolicy()
 	if err != nil {
-		return 0, err
+		return core.BackoffPolicy{}, err
 	}
-	return time.ParseDuration(to)
+	if cmd.Flags().Changed(flagTimeout) {
+		if cmd.Flags().Changed(flagBackoffInitialInterval) {
+			return core.BackoffPolicy{}, fmt.Errorf("cannot set both --%s and --%s", flagTimeout, flagBackoffInitialInterval)
+		}
+		to, err := cmd.Flags().GetString(flagTimeout)
+		if err != nil {
+			return core.BackoffPolicy{}, err
+		}
+		d, err := time.ParseDuration(to)
+		if err != nil {
+			return core.BackoffPolicy{}, fmt.Errorf("invalid --%s: %w", flagTimeout, err)
+		}
+		policy.InitialInterval = d.String()
+	}
+	if cmd.Flags().Changed(flagBackoffInitialInterval) {
+		d, err := cmd.Flags().GetDuration(flagBackoffInitialInterval)
+		if err != nil {
+			return core.BackoffPolicy{}, err
+		}
+		policy.InitialInterval = d.String()
+	}
+	if cmd.Flags().Changed(flagBackoffMaxInterval) {
+		d, err := cmd.Flags().GetDuration(flagBackoffMaxInterval)
+		if err != nil {
+			return core.BackoffPolicy{}, err
+		}
+		policy.MaxInterval = d.String()
+	}
+	if cmd.Flags().Changed(flagBackoffMultiplier) {
+		if policy.Multiplier, err = cmd.Flags().GetFloat64(flagBackoffMultiplier); err != nil {
+			return core.BackoffPolicy{}, err
+		}
+	}
+	if cmd.Flags().Changed(flagBackoffJitter) {
+		if policy.Jitter, err = cmd.Flags().GetFloat64(flagBackoffJitter); err != nil {
+			return core.BackoffPolicy{}, err
+		}
+	}
+	return policy, policy.Validate()
 }
 
 func timeoutFlags(cmd *cobra.Command) *cobra.Command {
-	cmd.Flags().Uint64P(flagTimeoutHeightOffset, "y", 0, "set timeout height offset for ")
-	cmd.Flags().DurationP(flagTimeoutTimeOffset, "c", time.Duration(0), "specify the path to relay over")
+	cmd.Flags().Uint64P(flagTimeoutHeightOffset, "y", 0, "timeout height as the number of blocks after the latest height of the dst chain")
+	cmd.Flags().DurationP(flagTimeoutTimeOffset, "c", time.Duration(0), "timeout timestamp as the duration after the latest block time of the dst chain")
+	cmd.Flags().String(flagTimeoutHeight, "", "absolute timeout height on the dst chain in the {revision}-{height} format (e.g. 1-12345)")
+	cmd.Flags().String(flagTimeoutTimestamp, "", "absolute timeout timestamp in RFC3339 (e.g. 2024-01-02T15:04:05Z)")
 	if err := viper.BindPFlag(flagTimeoutHeightOffset, cmd.Flags().Lookup(flagTimeoutHeightOffset)); err != nil {
 		panic(err)
 	}
@@ -85,3 +167,11 @@ func ibcDenomFlags(cmd *cobra.Command) *cobra.Command {
 	}
 	return cmd
 }
+
+func outputFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagOutput, "text", "output format (text|json)")
+	if err := viper.BindPFlag(flagOutput, cmd.Flags().Lookup(flagOutput)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/spf13/cobra"
+)
+
+// newBackoffCmd returns a command with the backoff flags parsed from `args`
+func newBackoffCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := backoffFlags(&cobra.Command{Use: "test"})
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestGetBackoffPolicy(t *testing.T) {
+	global := &core.BackoffPolicy{InitialInterval: "2s", MaxInterval: "1m", Multiplier: 3, Jitter: 0.1}
+	ctx := &config.Context{Config: &config.Config{Global: config.GlobalConfig{Backoff: global}}}
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected core.BackoffPolicy
+	}{
+		{"global", nil, *global},
+		{"deprecated timeout", []string{"--timeout", "5s"}, core.BackoffPolicy{InitialInterval: "5s", MaxInterval: "1m", Multiplier: 3, Jitter: 0.1}},
+		{"flags", []string{"--backoff-initial-interval", "500ms", "--backoff-max-interval", "10s", "--backoff-multiplier", "1.5", "--backoff-jitter", "0"},
+			core.BackoffPolicy{InitialInterval: "500ms", MaxInterval: "10s", Multiplier: 1.5, Jitter: 0}},
+	}
+	for _, c := range cases {
+		policy, err := getBackoffPolicy(newBackoffCmd(t, c.args...), ctx)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		} else if policy != c.expected {
+			t.Errorf("%s: unexpected policy: actual=%+v, expected=%+v", c.name, policy, c.expected)
+		}
+	}
+
+	// the default policy is used without the global config
+	if policy, err := getBackoffPolicy(newBackoffCmd(t), &config.Context{Config: &config.Config{}}); err != nil || policy != core.DefaultBackoffPolicy() {
+		t.Errorf("unexpected policy without the global config: policy=%+v, err=%v", policy, err)
+	}
+
+	for _, c := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--timeout", "5s", "--backoff-initial-interval", "1s"}, "cannot set both"},
+		{[]string{"--timeout", "5"}, "invalid --timeout"},
+		{[]string{"--backoff-jitter", "1"}, "jitter"},
+	} {
+		if _, err := getBackoffPolicy(newBackoffCmd(t, c.args...), ctx); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%v: unexpected error: %v", c.args, err)
+		}
+	}
+}
+
+func TestGetDeadlineContext(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		t.Helper()
+		cmd := deadlineFlag(&cobra.Command{Use: "test"})
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		cmd.SetContext(context.Background())
+		return cmd
+	}
+
+	// no deadline by default
+	ctx, cancel, err := getDeadlineContext(newCmd())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("the context has a deadline without the flag")
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("the context is not canceled: %v", ctx.Err())
+	}
+
+	ctx, cancel, err = getDeadlineContext(newCmd("--deadline", "1ms"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Millisecond {
+		t.Errorf("unexpected deadline: %v", deadline)
+	}
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("unexpected error of the context: %v", ctx.Err())
+	}
+}
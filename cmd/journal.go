@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/spf13/cobra"
+)
+
+func journalCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "inspect the relay journals",
+		Long:  "Commands to inspect the journals recording the packet msgs submitted by the relay service",
+		RunE:  noCommand,
+	}
+	cmd.AddCommand(
+		journalVerifyCmd(ctx),
+	)
+	return cmd
+}
+
+func queryJournalCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "query the relay journals",
+		RunE:  noCommand,
+	}
+	cmd.AddCommand(
+		queryJournalExportCmd(ctx),
+	)
+	return cmd
+}
+
+func queryJournalExportCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagFormat = "format"
+		flagSince  = "since"
+		flagOut    = "out"
+	)
+	cmd := &cobra.Command{
+		Use:   "export [path-name]",
+		Short: "export the journal of a path in CSV",
+		Long: strings.TrimSpace(`Export the packet msgs recorded in the journal of a path for accounting and analytics pipelines.
+Each row is a msg with its chains, channel, packet, transfer amount, the gas used, the fee paid and the ICS-29 fees earned
+by the tx including the msg (aggregate them per tx_id as a tx may include several msgs), and the latency from the packet event.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
+			path, err := ctx.Config.Paths.Get(pathName)
+			if err != nil {
+				return err
+			}
+			format, err := cmd.Flags().GetString(flagFormat)
+			if err != nil {
+				return err
+			}
+			since, err := cmd.Flags().GetString(flagSince)
+			if err != nil {
+				return err
+			}
+			out, err := cmd.Flags().GetString(flagOut)
+			if err != nil {
+				return err
+			}
+
+			entries, err := core.ReadJournal(core.JournalFile(homePath, pathName))
+			if err != nil {
+				return err
+			}
+			if since != "" {
+				t, err := parseSince(since)
+				if err != nil {
+					return err
+				}
+				entries = core.FilterJournalSince(entries, t)
+			}
+
+			w := cmd.OutOrStdout()
+			if out != "" {
+				f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return core.ExportJournal(w, core.JournalExportFormat(format), path, entries)
+		},
+	}
+	cmd.Flags().String(flagFormat, string(core.JournalExportCSV), "export format (csv)")
+	cmd.Flags().String(flagSince, "", "export only the entries recorded since the time (RFC3339) or the duration ago (e.g. 24h)")
+	cmd.Flags().String(flagOut, "", "file to write the export to (stdout if empty)")
+	return cmd
+}
+
+// parseSince parses a time in RFC3339 or a duration before now
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %s: neither a time in RFC3339 nor a duration", s)
+	}
+	return t, nil
+}
+
+func journalVerifyCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagPubKey        = "pub-key"
+		flagRequireSigned = "require-signed"
+	)
+	cmd := &cobra.Command{
+		Use:   "verify [path-name]",
+		Short: "verify the attestations of the journal of a path",
+		Long: strings.TrimSpace(`Verify the signatures of the relayer over the entries of the journal of a path,
+which are recorded if journal-attestations is enabled for the path. The journal can be verified
+by anyone holding it, e.g. a customer or a fee-reimbursement program, with the public key of the relayer.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
+			if _, err := ctx.Config.Paths.Get(pathName); err != nil {
+				return err
+			}
+			var pubKeys [][]byte
+			encoded, err := cmd.Flags().GetStringSlice(flagPubKey)
+			if err != nil {
+				return err
+			}
+			for _, s := range encoded {
+				pk, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return fmt.Errorf("invalid public key %s: %w", s, err)
+				}
+				pubKeys = append(pubKeys, pk)
+			}
+			requireSigned, err := cmd.Flags().GetBool(flagRequireSigned)
+			if err != nil {
+				return err
+			}
+
+			entries, err := core.ReadJournal(core.JournalFile(homePath, pathName))
+			if err != nil {
+				return err
+			}
+			var verified, unsigned, invalid int
+			for i, e := range entries {
+				if e.Attestation == nil {
+					unsigned++
+					if requireSigned {
+						fmt.Fprintf(cmd.OutOrStdout(), "line %d: the entry is not attested (chain_id=%s, sequence=%d)\n", i+1, e.ChainID, e.Sequence)
+					}
+					continue
+				}
+				err := e.VerifyAttestation()
+				if err == nil && len(pubKeys) > 0 && !containsBytes(pubKeys, e.Attestation.PubKey) {
+					err = fmt.Errorf("signed by an unexpected key %s", base64.StdEncoding.EncodeToString(e.Attestation.PubKey))
+				}
+				if err != nil {
+					invalid++
+					fmt.Fprintf(cmd.OutOrStdout(), "line %d: %v (chain_id=%s, sequence=%d)\n", i+1, err, e.ChainID, e.Sequence)
+					continue
+				}
+				verified++
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "entries: %d, verified: %d, unsigned: %d, invalid: %d\n", len(entries), verified, unsigned, invalid)
+			if invalid > 0 || (requireSigned && unsigned > 0) {
+				return fmt.Errorf("the journal of %s failed the verification", pathName)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSlice(flagPubKey, nil, "base64-encoded public keys of the relayer expected to sign the entries (any key if not specified)")
+	cmd.Flags().Bool(flagRequireSigned, false, "fail if any entry is not attested")
+	return cmd
+}
+
+func containsBytes(list [][]byte, b []byte) bool {
+	for _, e := range list {
+		if bytes.Equal(e, b) {
+			return true
+		}
+	}
+	return false
+}
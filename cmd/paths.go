@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
 	"github.com/hyperledger-labs/yui-relayer/core"
@@ -26,6 +27,11 @@ connection, and channel ids from both the source and destination chains as well
 		pathsListCmd(ctx),
 		pathsAddCmd(ctx),
 		pathsEditCmd(ctx),
+		pathsCheckCmd(ctx),
+		pathsPauseCmd(ctx),
+		pathsResumeCmd(ctx),
+		pathsPauseGroupCmd(ctx),
+		pathsResumeGroupCmd(ctx),
 	)
 
 	return cmd
@@ -306,3 +312,136 @@ func userInputPathAdd(config *config.Config, src, dst, name string) error {
 
 	return nil
 }
+
+func pathsCheckCmd(ctx *config.Context) *cobra.Command {
+	const flagNoColor = "no-color"
+	cmd := &cobra.Command{
+		Use:   "check [path-name]",
+		Short: "check that a path is ready to relay",
+		Long: strings.TrimSpace(`Check RPC reachability, key existence and balance, client validity,
+and the states and compatibility of the connection and the channel on both ends of the path.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, src, dst, err := ctx.Config.ChainsFromPath(args[0])
+			if err != nil {
+				return err
+			}
+			noColor, err := cmd.Flags().GetBool(flagNoColor)
+			if err != nil {
+				return err
+			}
+
+			results := core.CheckPath(c[src], c[dst])
+			failed := 0
+			for _, r := range results {
+				mark := "\x1b[32m✔\x1b[0m"
+				if noColor {
+					mark = "✔"
+				}
+				if !r.OK {
+					failed++
+					mark = "\x1b[31m✘\x1b[0m"
+					if noColor {
+						mark = "✘"
+					}
+				}
+				fmt.Printf("%s [%s] %s: %s\n", mark, r.ChainID, r.Item, r.Detail)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d checks failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool(flagNoColor, false, "disable colored output")
+	return cmd
+}
+
+func pathsPauseCmd(ctx *config.Context) *cobra.Command {
+	const flagReason = "reason"
+	cmd := &cobra.Command{
+		Use:   "pause [path-name]",
+		Short: "pause relaying on a path",
+		Long: strings.TrimSpace(`Pause relaying on a path. The pause state is persisted in the home directory,
+so the path stays paused across restarts of the relay service until it is resumed.
+A running relay service stops relaying in the next relay cycle.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := ctx.Config.Paths.Get(args[0]); err != nil {
+				return err
+			}
+			reason, err := cmd.Flags().GetString(flagReason)
+			if err != nil {
+				return err
+			}
+			return core.PausePath(core.PauseStateFile(homePath, args[0]), reason)
+		},
+	}
+	cmd.Flags().String(flagReason, "", "reason to pause the path")
+	return cmd
+}
+
+func pathsResumeCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume [path-name]",
+		Short: "resume relaying on a paused path",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := ctx.Config.Paths.Get(args[0]); err != nil {
+				return err
+			}
+			return core.ResumePath(core.PauseStateFile(homePath, args[0]))
+		},
+	}
+	return cmd
+}
+
+func pathsPauseGroupCmd(ctx *config.Context) *cobra.Command {
+	const flagReason = "reason"
+	cmd := &cobra.Command{
+		Use:   "pause-group [group]",
+		Short: "pause relaying on all the paths of a group",
+		Long: strings.TrimSpace(`Pause relaying on all the paths of a group. The pause state of the group is persisted in the home directory
+apart from the pause states of the paths, so resuming the group doesn't resume the paths paused individually.
+The running relay services of the paths stop relaying in the next relay cycle.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkGroup(ctx, args[0]); err != nil {
+				return err
+			}
+			reason, err := cmd.Flags().GetString(flagReason)
+			if err != nil {
+				return err
+			}
+			return core.PausePath(core.GroupPauseStateFile(homePath, args[0]), reason)
+		},
+	}
+	cmd.Flags().String(flagReason, "", "reason to pause the group")
+	return cmd
+}
+
+func pathsResumeGroupCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume-group [group]",
+		Short: "resume relaying on the paths of a paused group",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkGroup(ctx, args[0]); err != nil {
+				return err
+			}
+			return core.ResumePath(core.GroupPauseStateFile(homePath, args[0]))
+		},
+	}
+	return cmd
+}
+
+// checkGroup returns an error if no path belongs to the group
+func checkGroup(ctx *config.Context, group string) error {
+	if err := core.ValidateGroupName(group); err != nil {
+		return err
+	}
+	if len(ctx.Config.Paths.PathsInGroup(group)) == 0 {
+		return fmt.Errorf("no path belongs to group %s", group)
+	}
+	return nil
+}
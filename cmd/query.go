@@ -4,6 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -28,6 +33,10 @@ func queryCmd(ctx *config.Context) *cobra.Command {
 		queryBalanceCmd(ctx),
 		queryUnrelayedPackets(ctx),
 		queryUnrelayedAcknowledgements(ctx),
+		queryStatusCmd(ctx),
+		querySpendCmd(ctx),
+		queryJournalCmd(ctx),
+		queryPacketEvents(ctx),
 		flags.LineBreak,
 		queryClientCmd(ctx),
 		queryConnection(ctx),
@@ -226,6 +235,62 @@ func queryUnrelayedPackets(ctx *config.Context) *cobra.Command {
 	return cmd
 }
 
+func queryPacketEvents(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packet-events [path-name] [chain-id]",
+		Short: "Query the packets sent and the acknowledgements written on the channel of a given path in a range of blocks",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chains, _, _, err := ctx.Config.ChainsFromPath(args[0])
+			if err != nil {
+				return err
+			}
+			c, ok := chains[args[1]]
+			if !ok {
+				return fmt.Errorf("chain %s is not in the path %s", args[1], args[0])
+			}
+			latestHeight, err := c.LatestHeight()
+			if err != nil {
+				return err
+			}
+			from, err := cmd.Flags().GetUint64(flagFromHeight)
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetUint64(flagToHeight)
+			if err != nil {
+				return err
+			}
+			if to == 0 {
+				to = latestHeight.GetRevisionHeight()
+			}
+			events, err := c.QueryPacketEventsInRange(
+				context.TODO(),
+				clienttypes.NewHeight(latestHeight.GetRevisionNumber(), from),
+				clienttypes.NewHeight(latestHeight.GetRevisionNumber(), to),
+			)
+			if err != nil {
+				return err
+			}
+			if events.SentPackets == nil {
+				events.SentPackets = []*core.PacketInfo{}
+			}
+			if events.WrittenAcknowledgements == nil {
+				events.WrittenAcknowledgements = []*core.PacketInfo{}
+			}
+			out, err := json.Marshal(events)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+	cmd.Flags().Uint64(flagFromHeight, 1, "the lowest height of the blocks to scan")
+	cmd.Flags().Uint64(flagToHeight, 0, "the highest height of the blocks to scan (0 means the latest height)")
+	return cmd
+}
+
 func queryUnrelayedAcknowledgements(ctx *config.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "unrelayed-acknowledgements [path]",
@@ -274,3 +339,245 @@ func queryUnrelayedAcknowledgements(ctx *config.Context) *cobra.Command {
 
 	return cmd
 }
+
+func queryStatusCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagPath  = "path"
+		flagGroup = "group"
+	)
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Query a snapshot of the status of the paths",
+		Long: strings.TrimSpace(`Query a snapshot of the status of the paths in one call: chain heights, client expiry times,
+pending packets and acknowledgements, the last relay time and the relayer's balances per chain, and the pending,
+relayed and failed packets, acknowledgements and timeouts per direction (src->dst and dst->src).
+It is intended to be run periodically to feed external dashboards.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName, err := cmd.Flags().GetString(flagPath)
+			if err != nil {
+				return err
+			}
+			group, err := cmd.Flags().GetString(flagGroup)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format: %s", output)
+			}
+
+			var pathNames []string
+			if pathName != "" {
+				if _, err := ctx.Config.Paths.Get(pathName); err != nil {
+					return err
+				}
+				pathNames = []string{pathName}
+			} else if group != "" {
+				pathNames = ctx.Config.Paths.PathsInGroup(group)
+			} else {
+				for name := range ctx.Config.Paths {
+					pathNames = append(pathNames, name)
+				}
+				sort.Strings(pathNames)
+			}
+
+			statuses := make([]*core.PathSnapshot, 0, len(pathNames))
+			for _, name := range pathNames {
+				status, err := queryPathSnapshot(ctx, name)
+				if err != nil {
+					return fmt.Errorf("failed to query the status of path %s: %w", name, err)
+				}
+				statuses = append(statuses, status)
+			}
+
+			if output == "json" {
+				out, err := json.Marshal(statuses)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			printPathSnapshots(statuses)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagPath, "", "name of the path to query (all paths if empty)")
+	cmd.Flags().String(flagGroup, "", "query only the paths of the group")
+	return outputFlag(cmd)
+}
+
+func queryPathSnapshot(ctx *config.Context, pathName string) (*core.PathSnapshot, error) {
+	c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
+	if err != nil {
+		return nil, err
+	}
+	path, err := ctx.Config.Paths.Get(pathName)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := core.NewSyncHeaders(c[src], c[dst])
+	if err != nil {
+		return nil, err
+	}
+	status := core.QueryPathSnapshot(pathName, path, c[src], c[dst], sh)
+
+	relayStatus, err := core.LoadRelayStatus(core.RelayStatusFile(homePath, pathName))
+	if err != nil {
+		return nil, err
+	}
+	if relayStatus != nil {
+		status.SetRelayStatus(relayStatus)
+	}
+	return status, nil
+}
+
+func printPathSnapshots(statuses []*core.PathSnapshot) {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	formatChannel := func(ch *core.ChannelSnapshot) string {
+		switch {
+		case ch == nil:
+			return "-"
+		case ch.Version == nil:
+			return fmt.Sprintf("%s %s", ch.ChannelID, ch.State)
+		default:
+			return fmt.Sprintf("%s %s %s fee=%t", ch.ChannelID, ch.State, ch.Version.AppVersion, ch.Version.FeeEnabled())
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tCHAIN\tHEIGHT\tCLIENT\tCLIENT EXPIRES AT\tCHANNEL\tUNRELAYED PACKETS\tUNRELAYED ACKS\tBALANCE\tLAST RELAY\tERRORS")
+	for _, ps := range statuses {
+		for _, cs := range []core.ChainSnapshot{ps.Src, ps.Dst} {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+				ps.Path,
+				cs.ChainID,
+				cs.LatestHeight,
+				cs.Client.ClientID,
+				formatTime(cs.Client.ExpiresAt),
+				formatChannel(cs.Channel),
+				cs.UnrelayedPackets,
+				cs.UnrelayedAcknowledgements,
+				cs.Balance,
+				formatTime(ps.LastRelayTime),
+				strings.Join(cs.Errors, "; "),
+			)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tDIRECTION\tPENDING PACKETS\tPENDING ACKS\tRELAYED PACKETS\tRELAYED ACKS\tRELAYED TIMEOUTS\tFAILED")
+	for _, ps := range statuses {
+		for _, d := range []struct {
+			direction string
+			stats     core.DirectionStats
+		}{
+			{fmt.Sprintf("%s->%s", ps.Src.ChainID, ps.Dst.ChainID), ps.SrcToDst},
+			{fmt.Sprintf("%s->%s", ps.Dst.ChainID, ps.Src.ChainID), ps.DstToSrc},
+		} {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\n",
+				ps.Path,
+				d.direction,
+				d.stats.PendingPackets,
+				d.stats.PendingAcknowledgements,
+				d.stats.RelayedPackets,
+				d.stats.RelayedAcknowledgements,
+				d.stats.RelayedTimeouts,
+				d.stats.Failed,
+			)
+		}
+	}
+	w.Flush()
+}
+
+func querySpendCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagPath  = "path"
+		flagGroup = "group"
+	)
+	cmd := &cobra.Command{
+		Use:   "spend",
+		Short: "Query the cumulative gas used and fees paid by the relay service per path and chain",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName, err := cmd.Flags().GetString(flagPath)
+			if err != nil {
+				return err
+			}
+			group, err := cmd.Flags().GetString(flagGroup)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format: %s", output)
+			}
+
+			var pathNames []string
+			if pathName != "" {
+				if _, err := ctx.Config.Paths.Get(pathName); err != nil {
+					return err
+				}
+				pathNames = []string{pathName}
+			} else if group != "" {
+				pathNames = ctx.Config.Paths.PathsInGroup(group)
+			} else {
+				for name := range ctx.Config.Paths {
+					pathNames = append(pathNames, name)
+				}
+				sort.Strings(pathNames)
+			}
+
+			spends := make([]*core.PathSpend, 0, len(pathNames))
+			for _, name := range pathNames {
+				spend, err := core.LoadPathSpend(core.SpendFile(homePath, name), name)
+				if err != nil {
+					return err
+				}
+				spends = append(spends, spend)
+			}
+
+			if output == "json" {
+				out, err := json.Marshal(spends)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PATH\tCHAIN\tGAS USED\tFEES\tDAY\tDAY FEES")
+			for _, ps := range spends {
+				chainIDs := make([]string, 0, len(ps.Chains))
+				for chainID := range ps.Chains {
+					chainIDs = append(chainIDs, chainID)
+				}
+				sort.Strings(chainIDs)
+				for _, chainID := range chainIDs {
+					cs := ps.Chains[chainID]
+					fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", ps.Path, chainID, cs.GasUsed, cs.Fees, cs.Day, cs.DayFees)
+				}
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().String(flagPath, "", "name of the path to query (all paths if empty)")
+	cmd.Flags().String(flagGroup, "", "query only the paths of the group")
+	return outputFlag(cmd)
+}
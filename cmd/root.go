@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
@@ -18,14 +19,25 @@ import (
 )
 
 var (
-	homePath    string
-	debug       bool
-	defaultHome = os.ExpandEnv("$HOME/.yui-relayer")
-	configPath  = "config/config.json"
+	homePath          string
+	debug             bool
+	defaultHome       = os.ExpandEnv("$HOME/.yui-relayer")
+	defaultConfigPath = "config/config.json"
+	configPath        string
+	configOverrides   []string
+	failureSummary    string
+)
+
+const (
+	flagConfig         = "config"
+	flagSet            = "set"
+	flagFailureSummary = "failure-summary"
 )
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // It can support any chain by giving modules.
+// If the command fails, the failure summary is written to the file given by --failure-summary,
+// and the process should exit with ExitCode of the returned error.
 func Execute(modules ...config.ModuleI) error {
 	// rootCmd represents the base command when called without any subcommands
 	var rootCmd = &cobra.Command{
@@ -38,9 +50,15 @@ func Execute(modules ...config.ModuleI) error {
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = true
 
-	// Register top level flags --home and --debug
+	// Register top level flags --home, --config, --set and --debug
 	rootCmd.PersistentFlags().StringVar(&homePath, flags.FlagHome, defaultHome, "set home directory")
+	rootCmd.PersistentFlags().StringVar(&configPath, flagConfig, defaultConfigPath, "path of the config file (relative to the home directory unless it is an absolute path)")
+	rootCmd.PersistentFlags().StringArrayVar(&configOverrides, flagSet, nil, "override a config value in the form of key=value, where key is a dot-separated JSON path (e.g. global.timeout=20s); can be repeated")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "debug output")
+	rootCmd.PersistentFlags().StringVar(&failureSummary, flagFailureSummary, "", "write a JSON summary of the failure (kind, exit code and error) to the file if the command fails")
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return core.NewFailure(core.FailureConfig, err)
+	})
 	if err := viper.BindPFlag(flags.FlagHome, rootCmd.PersistentFlags().Lookup(flags.FlagHome)); err != nil {
 		return err
 	}
@@ -50,11 +68,7 @@ func Execute(modules ...config.ModuleI) error {
 
 	// Register interfaces
 
-	codec := core.MakeCodec()
-	for _, module := range modules {
-		module.RegisterInterfaces(codec.InterfaceRegistry())
-	}
-	ctx := &config.Context{Modules: modules, Config: &config.Config{}, Codec: codec}
+	ctx := config.NewContext(modules...)
 
 	// Register subcommands
 
@@ -66,6 +80,11 @@ func Execute(modules ...config.ModuleI) error {
 		queryCmd(ctx),
 		modulesCmd(ctx),
 		serviceCmd(ctx),
+		stateCmd(ctx),
+		diagnoseCmd(ctx),
+		journalCmd(ctx),
+		devCmd(ctx),
+		versionCmd(ctx),
 		flags.LineBreak,
 	)
 	for _, module := range modules {
@@ -79,11 +98,11 @@ func Execute(modules ...config.ModuleI) error {
 		if err := viper.BindPFlags(cmd.Flags()); err != nil {
 			return fmt.Errorf("failed to bind the flag set to the configuration: %v", err)
 		}
-		if err := ctx.Config.InitConfig(ctx, homePath, configPath, debug); err != nil {
-			return fmt.Errorf("failed to initialize the configuration: %v", err)
+		if err := ctx.Config.InitConfig(ctx, homePath, configPath, debug, configOverrides...); err != nil {
+			return core.NewFailure(core.FailureConfig, fmt.Errorf("failed to initialize the configuration: %w", err))
 		}
 		if err := initLogger(ctx); err != nil {
-			return err
+			return core.NewFailure(core.FailureConfig, err)
 		}
 		if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
 			return fmt.Errorf("failed to initialize the metrics: %v", err)
@@ -97,7 +116,22 @@ func Execute(modules ...config.ModuleI) error {
 		return nil
 	}
 
-	return rootCmd.Execute()
+	executed, err := rootCmd.ExecuteC()
+	if err != nil && failureSummary != "" {
+		if err := core.NewFailureSummary(executed.CommandPath(), err).WriteFile(failureSummary); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	return err
+}
+
+// ExitCode returns the exit code of the process for the error returned by Execute, which depends on the kind of the failure:
+// 2 for a config error, 3 for a chain unreachable, 4 for a handshake timeout, 5 for a rejected tx, and 1 otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return core.FailureKindOf(err).ExitCode()
 }
 
 // readLineFromBuf reads one line from stdin.
@@ -111,6 +145,14 @@ func initLogger(ctx *config.Context) error {
 	return log.InitLogger(c.Level, c.Format, c.Output)
 }
 
+// configFilePath returns the path of the config file specified by --home and --config
+func configFilePath() string {
+	if filepath.IsAbs(configPath) {
+		return configPath
+	}
+	return filepath.Join(homePath, configPath)
+}
+
 func noCommand(cmd *cobra.Command, args []string) error {
 	cmd.Help()
 	return errors.New("specified command does not exist")
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/spf13/cobra"
+)
+
+func devScaffoldCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagChains    = "chains"
+		flagPathName  = "path-name"
+		flagSimappDir = "simapp-dir"
+		flagForce     = "force"
+	)
+	cmd := &cobra.Command{
+		Use:   "scaffold [dir]",
+		Short: "generate a devnet of two simapp chains with a ready-made relayer config and path",
+		Long: strings.TrimSpace(`Generate a devnet in the directory: a docker-compose file running two simapp chains built from
+--simapp-dir (tests/chains/tendermint of this repository), the relayer configs of the chains and the path between them,
+and setup.sh, which starts the chains, imports the funded keys of the chains into the relayer home (.relayer in the directory)
+and creates the clients, the connection and the channel of the path. Run setup.sh and then "service start" on the path
+to get a complete working environment, which also serves as a standard fixture of the integration tests.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "devnet"
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			chainIDs, err := cmd.Flags().GetStringSlice(flagChains)
+			if err != nil {
+				return err
+			}
+			pathName, err := cmd.Flags().GetString(flagPathName)
+			if err != nil {
+				return err
+			}
+			simappDir, err := cmd.Flags().GetString(flagSimappDir)
+			if err != nil {
+				return err
+			}
+			force, err := cmd.Flags().GetBool(flagForce)
+			if err != nil {
+				return err
+			}
+			devnet, err := newScaffoldDevnet(dir, chainIDs, pathName, simappDir)
+			if err != nil {
+				return err
+			}
+			files, err := devnet.write(force)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				fmt.Println("created", f)
+			}
+			fmt.Printf("run %s to start the devnet and set up the path %s\n", filepath.Join(dir, "setup.sh"), pathName)
+			return nil
+		},
+	}
+	cmd.Flags().StringSlice(flagChains, []string{"ibc0", "ibc1"}, "chain IDs of the two chains")
+	cmd.Flags().String(flagPathName, "ibc01", "name of the path between the chains")
+	cmd.Flags().String(flagSimappDir, filepath.Join("tests", "chains", "tendermint"), "directory of the simapp to build the chain images from")
+	cmd.Flags().Bool(flagForce, false, "overwrite the existing files")
+	return cmd
+}
+
+// scaffoldChainIDPattern restricts the chain IDs to the names valid for the docker-compose services
+var scaffoldChainIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// scaffoldProjectInvalidChars are the characters not allowed in the docker-compose project name, which is derived from the directory name
+var scaffoldProjectInvalidChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// scaffoldDevnet is the devnet generated by `dev scaffold`
+type scaffoldDevnet struct {
+	Dir      string
+	Project  string
+	PathName string
+	// SimappDir is the build context of the chain images relative to Dir
+	SimappDir string
+	Chains    []scaffoldChain
+	// Relayer is the relayer binary invoked by setup.sh unless overridden by $RLY
+	Relayer string
+}
+
+type scaffoldChain struct {
+	ChainID  string
+	RPCPort  int
+	P2PPort  int
+	GRPCPort int
+}
+
+func newScaffoldDevnet(dir string, chainIDs []string, pathName, simappDir string) (*scaffoldDevnet, error) {
+	if len(chainIDs) != 2 || chainIDs[0] == chainIDs[1] {
+		return nil, fmt.Errorf("two different chain IDs must be specified: %v", chainIDs)
+	}
+	for _, chainID := range chainIDs {
+		// the chain IDs are also the names of the services and the files
+		if !scaffoldChainIDPattern.MatchString(chainID) {
+			return nil, fmt.Errorf("invalid chain ID: %q", chainID)
+		}
+	}
+	if pathName == "" {
+		return nil, fmt.Errorf("path name must be specified")
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	absSimapp, err := filepath.Abs(simappDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(absSimapp, "Dockerfile")); err != nil {
+		return nil, fmt.Errorf("simapp directory %s doesn't have a Dockerfile: %w", simappDir, err)
+	}
+	relSimapp, err := filepath.Rel(absDir, absSimapp)
+	if err != nil {
+		return nil, err
+	}
+	relayer, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	devnet := &scaffoldDevnet{
+		Dir:       dir,
+		Project:   strings.Trim(scaffoldProjectInvalidChars.ReplaceAllString(strings.ToLower(filepath.Base(absDir)), "-"), "-_"),
+		PathName:  pathName,
+		SimappDir: filepath.ToSlash(relSimapp),
+		Relayer:   relayer,
+	}
+	// the ports are the same as the ones of the e2e tests (tests/cases/docker-compose-test.yaml)
+	for i, chainID := range chainIDs {
+		devnet.Chains = append(devnet.Chains, scaffoldChain{
+			ChainID:  chainID,
+			RPCPort:  26657 - 100*i,
+			P2PPort:  26656 - 90*i,
+			GRPCPort: 9090 + i,
+		})
+	}
+	if devnet.Project == "" {
+		devnet.Project = "devnet"
+	}
+	return devnet, nil
+}
+
+var scaffoldTemplates = template.Must(template.New("scaffold").Parse(`
+{{define "docker-compose.yaml"}}# generated by ` + "`dev scaffold`" + `
+name: {{.Project}}
+
+services:
+{{- range .Chains}}
+  {{.ChainID}}:
+    build:
+      context: {{$.SimappDir}}
+      args:
+        CHAINID: {{.ChainID}}
+    image: {{$.Project}}-{{.ChainID}}
+    ports:
+      - {{.P2PPort}}:26656
+      - {{.RPCPort}}:26657
+      - {{.GRPCPort}}:9090
+    healthcheck:
+      test: "wget -q -O - http://localhost:26657/health || exit 1"
+      interval: 5s
+      timeout: 10s
+      retries: 20
+{{- end}}
+{{end}}
+
+{{define "chain.json"}}{
+  "chain": {
+    "@type": "/relayer.chains.tendermint.config.ChainConfig",
+    "key": "relayer",
+    "chain_id": "{{.ChainID}}",
+    "rpc_addr": "http://localhost:{{.RPCPort}}",
+    "account_prefix": "cosmos",
+    "gas_adjustment": 1.5,
+    "gas_prices": "0.025stake",
+    "average_block_time_msec": 1000,
+    "max_retry_for_commit": 5
+  },
+  "prover": {
+    "@type": "/relayer.chains.tendermint.config.ProverConfig",
+    "trusting_period": "336h",
+    "refresh_threshold_rate": {
+      "numerator": 2,
+      "denominator": 3
+    }
+  }
+}
+{{end}}
+
+{{define "path.json"}}{
+  "src": {
+    "chain-id": "{{(index .Chains 0).ChainID}}",
+    "port-id": "transfer",
+    "order": "unordered",
+    "version": "ics20-1"
+  },
+  "dst": {
+    "chain-id": "{{(index .Chains 1).ChainID}}",
+    "port-id": "transfer",
+    "order": "unordered",
+    "version": "ics20-1"
+  },
+  "strategy": {
+    "type": "naive"
+  }
+}
+{{end}}
+
+{{define "setup.sh"}}#!/usr/bin/env bash
+# generated by ` + "`dev scaffold`" + `: starts the devnet and sets up the path {{.PathName}} with the relayer home in ./.relayer
+set -eux
+
+cd "$(dirname "$0")"
+RLY_BINARY=${RLY:-{{.Relayer}}}
+RLY_HOME=${RLY_HOME:-$PWD/.relayer}
+rly() {
+  "$RLY_BINARY" --home "$RLY_HOME" "$@"
+}
+retry() {
+  local n=$1; shift
+  for i in $(seq "$n"); do
+    "$@" && return 0
+    sleep 3
+  done
+  return 1
+}
+
+docker compose up -d --build --wait
+
+rm -rf "$RLY_HOME"
+rly config init
+rly chains add-dir configs/chains/
+{{- range .Chains}}
+
+# import the funded key generated in the genesis of {{.ChainID}}
+SEED=$(docker compose exec -T {{.ChainID}} cat /root/data/{{.ChainID}}/key_seed.json | jq -r '.mnemonic')
+rly tendermint keys restore {{.ChainID}} relayer "$SEED"
+retry 5 rly tendermint light init {{.ChainID}} -f
+{{- end}}
+
+rly paths add {{(index .Chains 0).ChainID}} {{(index .Chains 1).ChainID}} {{.PathName}} --file=configs/path.json
+retry 5 rly tx clients --src-height 2 {{.PathName}}
+retry 5 rly tx connection {{.PathName}}
+retry 5 rly tx channel {{.PathName}}
+
+echo "the path {{.PathName}} is ready: $RLY_BINARY --home $RLY_HOME service start {{.PathName}}"
+{{end}}
+`))
+
+// write writes the files of the devnet and returns their paths
+func (d *scaffoldDevnet) write(force bool) ([]string, error) {
+	type file struct {
+		name     string
+		template string
+		data     interface{}
+		perm     os.FileMode
+	}
+	files := []file{
+		{"docker-compose.yaml", "docker-compose.yaml", d, 0644},
+		{filepath.Join("configs", "path.json"), "path.json", d, 0644},
+		{"setup.sh", "setup.sh", d, 0755},
+	}
+	for _, c := range d.Chains {
+		files = append(files, file{filepath.Join("configs", "chains", c.ChainID+".json"), "chain.json", c, 0644})
+	}
+
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(d.Dir, f.name)); err == nil {
+				return nil, fmt.Errorf("%s already exists (use --force to overwrite)", filepath.Join(d.Dir, f.name))
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+	var written []string
+	for _, f := range files {
+		var b strings.Builder
+		if err := scaffoldTemplates.ExecuteTemplate(&b, f.template, f.data); err != nil {
+			return written, err
+		}
+		name := filepath.Join(d.Dir, f.name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(name, []byte(b.String()), f.perm); err != nil {
+			return written, err
+		}
+		written = append(written, name)
+	}
+	return written, nil
+}
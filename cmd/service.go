@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
 	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/relayer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -33,50 +39,100 @@ func startCmd(ctx *config.Context) *cobra.Command {
 		flagSrcRelayOptimizeCount    = "src-relay-optimize-count"
 		flagDstRelayOptimizeInterval = "dst-relay-optimize-interval"
 		flagDstRelayOptimizeCount    = "dst-relay-optimize-count"
+		flagObserve                  = "observe"
+		flagAdminAddr                = "admin-addr"
+		flagAdminAuthTokenFile       = "admin-auth-token-file"
+		flagAdminTLSCert             = "admin-tls-cert"
+		flagAdminTLSKey              = "admin-tls-key"
+		flagAdminTLSClientCA         = "admin-tls-client-ca"
+		flagAdminEnableProverSwap    = "admin-enable-prover-swap"
+		flagAdminAllowedOrigins      = "admin-allowed-origins"
+		flagLeaderLeaseFile          = "leader-lease-file"
+		flagLeaderLeaseTTL           = "leader-lease-ttl"
+		flagShardIndex               = "shard-index"
+		flagClientStateCacheMaxAge   = "client-state-cache-max-age"
+		flagOnce                     = "once"
 	)
 	const (
-		defaultRelayInterval         = 3 * time.Second
-		defaultPrometheusAddr        = "localhost:2223"
-		defaultRelayOptimizeInterval = 10 * time.Second
-		defaultRelayOptimizeCount    = 5
+		defaultRelayInterval          = 3 * time.Second
+		defaultPrometheusAddr         = "localhost:2223"
+		defaultRelayOptimizeInterval  = 10 * time.Second
+		defaultRelayOptimizeCount     = 5
+		defaultLeaderLeaseTTL         = 30 * time.Second
+		defaultClientStateCacheMaxAge = 30 * time.Second
 	)
 
 	cmd := &cobra.Command{
 		Use:  "start [path-name]",
 		Args: cobra.ExactArgs(1),
+		Long: strings.TrimSpace(`Start the relay service of the path, which performs the relay cycles at the relay interval until it is terminated.
+With --once, it performs a single relay cycle (updating the clients close to expiry and relaying all the pending packets
+and acknowledgements on all the channels in both directions) and exits with a non-zero status if the cycle or any of
+its transactions fails, which is intended for the deployments scheduled by cron or serverless functions.
+The prometheus exporter and the admin API are not started with --once.`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := metrics.ShutdownMetrics(cmd.Context()); err != nil {
-				return fmt.Errorf("failed to shutdown the metrics subsystem with null exporter: %v", err)
+			once := viper.GetBool(flagOnce)
+			if !once {
+				if err := metrics.ShutdownMetrics(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to shutdown the metrics subsystem with null exporter: %v", err)
+				}
+				if err := metrics.InitializeMetrics(metrics.ExporterProm{Addr: viper.GetString(flagPrometheusAddr)}); err != nil {
+					return fmt.Errorf("failed to re-initialize the metrics subsystem with prometheus exporter: %v", err)
+				}
 			}
-			if err := metrics.InitializeMetrics(metrics.ExporterProm{Addr: viper.GetString(flagPrometheusAddr)}); err != nil {
-				return fmt.Errorf("failed to re-initialize the metrics subsystem with prometheus exporter: %v", err)
-			}
-			c, src, dst, err := ctx.Config.ChainsFromPath(args[0])
+			srv, err := relayer.NewRelayService(ctx, homePath, args[0], relayer.ServiceOptions{
+				RelayInterval:            viper.GetDuration(flagRelayInterval),
+				SrcRelayOptimizeInterval: viper.GetDuration(flagSrcRelayOptimizeInterval),
+				SrcRelayOptimizeCount:    viper.GetUint64(flagSrcRelayOptimizeCount),
+				DstRelayOptimizeInterval: viper.GetDuration(flagDstRelayOptimizeInterval),
+				DstRelayOptimizeCount:    viper.GetUint64(flagDstRelayOptimizeCount),
+				Observe:                  viper.GetBool(flagObserve),
+				ShardIndex:               viper.GetUint32(flagShardIndex),
+				LeaderLeaseFile:          viper.GetString(flagLeaderLeaseFile),
+				LeaderLeaseTTL:           viper.GetDuration(flagLeaderLeaseTTL),
+				ClientStateCacheMaxAge:   viper.GetDuration(flagClientStateCacheMaxAge),
+			})
 			if err != nil {
 				return err
 			}
-			path, err := ctx.Config.Paths.Get(args[0])
-			if err != nil {
-				return err
+			// the leadership is released on a termination signal so that a standby instance takes over immediately
+			sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if once {
+				return srv.RunOnce(sigCtx)
 			}
-			st, err := core.GetStrategy(*path.Strategy)
-			if err != nil {
-				return err
+			if addr := viper.GetString(flagAdminAddr); addr != "" {
+				opts := core.AdminServerOptions{
+					TLSCertFile:      viper.GetString(flagAdminTLSCert),
+					TLSKeyFile:       viper.GetString(flagAdminTLSKey),
+					TLSClientCAFile:  viper.GetString(flagAdminTLSClientCA),
+					AllowedOrigins:   viper.GetStringSlice(flagAdminAllowedOrigins),
+					EnableProverSwap: viper.GetBool(flagAdminEnableProverSwap),
+				}
+				if file := viper.GetString(flagAdminAuthTokenFile); file != "" {
+					bz, err := os.ReadFile(file)
+					if err != nil {
+						return fmt.Errorf("failed to read the auth token of the admin API: %w", err)
+					}
+					if opts.AuthToken = strings.TrimSpace(string(bz)); opts.AuthToken == "" {
+						return fmt.Errorf("the auth token file of the admin API is empty: %s", file)
+					}
+				}
+				if opts.EnableProverSwap {
+					if err := srv.EnableProverSwap(); err != nil {
+						return err
+					}
+				}
+				admin := core.NewAdminServer(srv, opts)
+				if err := admin.Start(addr); err != nil {
+					return err
+				}
+				defer admin.Close()
 			}
-			if err := st.SetupRelay(context.TODO(), c[src], c[dst]); err != nil {
+			if err := srv.Start(sigCtx); err != nil && !errors.Is(err, context.Canceled) {
 				return err
 			}
-			return core.StartService(
-				context.Background(),
-				st,
-				c[src],
-				c[dst],
-				viper.GetDuration(flagRelayInterval),
-				viper.GetDuration(flagSrcRelayOptimizeInterval),
-				viper.GetUint64(flagSrcRelayOptimizeCount),
-				viper.GetDuration(flagDstRelayOptimizeInterval),
-				viper.GetUint64(flagDstRelayOptimizeCount),
-			)
+			return nil
 		},
 	}
 	cmd.Flags().Duration(flagRelayInterval, defaultRelayInterval, "time interval to perform relays")
@@ -85,5 +141,18 @@ func startCmd(ctx *config.Context) *cobra.Command {
 	cmd.Flags().Uint64(flagSrcRelayOptimizeCount, defaultRelayOptimizeCount, "maximum number of relays to delay for optimization")
 	cmd.Flags().Duration(flagDstRelayOptimizeInterval, defaultRelayOptimizeInterval, "maximum time interval to delay relays for optimization")
 	cmd.Flags().Uint64(flagDstRelayOptimizeCount, defaultRelayOptimizeCount, "maximum number of relays to delay for optimization")
+	cmd.Flags().Bool(flagObserve, false, "scan packets and emit metrics without submitting any transactions")
+	cmd.Flags().String(flagAdminAddr, "", "host address to which the admin API server listens (disabled if empty); a non-loopback address requires TLS")
+	cmd.Flags().String(flagAdminAuthTokenFile, "", "file containing the bearer token required by the admin API; the endpoints changing the state are disabled without it or a client CA")
+	cmd.Flags().String(flagAdminTLSCert, "", "certificate with which the admin API is served over TLS")
+	cmd.Flags().String(flagAdminTLSKey, "", "key of the certificate of the admin API")
+	cmd.Flags().String(flagAdminTLSClientCA, "", "CA certificate to verify the client certificates of the admin API (mutual TLS)")
+	cmd.Flags().StringSlice(flagAdminAllowedOrigins, nil, "origins of the web pages allowed to stream the events from the admin API (e.g. https://dashboard.example.com)")
+	cmd.Flags().Bool(flagAdminEnableProverSwap, false, "enable swapping the prover of a chain by POST /prover of the admin API, which also saves the prover to the config file")
+	cmd.Flags().String(flagLeaderLeaseFile, "", "lease file on a shared file system to elect the leader among the instances serving the path; only the leader relays and the others observe (disabled if empty)")
+	cmd.Flags().Duration(flagLeaderLeaseTTL, defaultLeaderLeaseTTL, "time for which the leadership lasts without renewal")
+	cmd.Flags().Uint32(flagShardIndex, 0, "index of the shard relayed by this instance if the path has the sharding config")
+	cmd.Flags().Duration(flagClientStateCacheMaxAge, defaultClientStateCacheMaxAge, "maximum age of the client states cached in the relay cycles, after which the updates by other relayers are observed (disabled if 0)")
+	cmd.Flags().Bool(flagOnce, false, "perform a single relay cycle and exit")
 	return cmd
 }
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/utils"
+	"github.com/spf13/cobra"
+)
+
+func stateCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "manage the durable state of the relayer",
+		Long: strings.TrimSpace(`Commands to export and import the durable state of the relayer stored in the home directory
+(relay status, spend, pause state and journal of the paths), to migrate a relayer deployment between machines.`),
+		RunE: noCommand,
+	}
+
+	cmd.AddCommand(
+		stateExportCmd(ctx),
+		stateImportCmd(ctx),
+	)
+
+	return cmd
+}
+
+func stateExportCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagOut         = "out"
+		flagWithJournal = "with-journal"
+	)
+	cmd := &cobra.Command{
+		Use:   "export [path-name...]",
+		Short: "export the durable state of the paths (all the paths if not specified)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathNames := args
+			if len(pathNames) == 0 {
+				for name := range ctx.Config.Paths {
+					pathNames = append(pathNames, name)
+				}
+				sort.Strings(pathNames)
+			}
+			for _, name := range pathNames {
+				if _, err := ctx.Config.Paths.Get(name); err != nil {
+					return err
+				}
+			}
+			withJournal, err := cmd.Flags().GetBool(flagWithJournal)
+			if err != nil {
+				return err
+			}
+			export, err := core.ExportState(homePath, pathNames, withJournal)
+			if err != nil {
+				return err
+			}
+			bz, err := json.MarshalIndent(export, "", "  ")
+			if err != nil {
+				return err
+			}
+			out, err := cmd.Flags().GetString(flagOut)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				fmt.Println(string(bz))
+				return nil
+			}
+			return utils.WriteFileAtomic(out, bz, 0600)
+		},
+	}
+	cmd.Flags().String(flagOut, "", "file to write the state to (stdout if empty)")
+	cmd.Flags().Bool(flagWithJournal, false, "include the content of the journals, otherwise only their positions are exported")
+	return cmd
+}
+
+func stateImportCmd(ctx *config.Context) *cobra.Command {
+	const flagOverwrite = "overwrite"
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "import the durable state of the paths exported by `state export`",
+		Long: strings.TrimSpace(`Import the durable state of the paths exported by 'state export'.
+The paths must be configured beforehand, and the import fails if the state of a path already exists unless --overwrite is given.
+Stop the relay service of the paths before importing.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bz, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var export core.StateExport
+			if err := json.Unmarshal(bz, &export); err != nil {
+				return fmt.Errorf("failed to unmarshal the state file %s: %w", args[0], err)
+			}
+			for name := range export.Paths {
+				if _, err := ctx.Config.Paths.Get(name); err != nil {
+					return err
+				}
+			}
+			overwrite, err := cmd.Flags().GetBool(flagOverwrite)
+			if err != nil {
+				return err
+			}
+			return core.ImportState(homePath, &export, overwrite)
+		},
+	}
+	cmd.Flags().Bool(flagOverwrite, false, "overwrite the existing state of the paths")
+	return cmd
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/spf13/cobra"
+)
+
+func proposeClientSubstituteCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagTitle        = "title"
+		flagSummary      = "summary"
+		flagDeposit      = "deposit"
+		flagProposalID   = "proposal-id"
+		flagWait         = "wait"
+		flagPollInterval = "poll-interval"
+	)
+	cmd := &cobra.Command{
+		Use:   "propose-client-substitute [path-name] [chain-id] [substitute-client-id]",
+		Short: "submit a governance proposal to substitute the expired or frozen client of a path",
+		Long: strings.TrimSpace(`Submit a governance proposal on a chain to substitute the client of the path on the chain, which is expired
+or frozen, with an active client tracking the same counterparty chain (e.g. a client newly created with the same parameters).
+The initial deposit is the minimum deposit of the chain unless --deposit is given.
+With --proposal-id, no proposal is submitted, and --deposit is added to the existing proposal instead.
+The status of the proposal is tracked until it is executed or rejected if --wait is set, and then the status of the client is checked.`),
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName, chainID, substitute := args[0], args[1], args[2]
+			chains, _, _, err := ctx.Config.ChainsFromPath(pathName)
+			if err != nil {
+				return err
+			}
+			chain, ok := chains[chainID]
+			if !ok {
+				return fmt.Errorf("chain %s is not in the path %s", chainID, pathName)
+			}
+			proposer, ok := chain.Chain.(core.ClientSubstituteProposer)
+			if !ok {
+				return fmt.Errorf("chain %s doesn't support the client substitute proposals", chainID)
+			}
+			subject := chain.Path().ClientID
+			if subject == substitute {
+				return fmt.Errorf("the substitute client must be different from the subject client %s", subject)
+			}
+
+			title, err := cmd.Flags().GetString(flagTitle)
+			if err != nil {
+				return err
+			}
+			if title == "" {
+				title = fmt.Sprintf("Substitute the client %s with %s", subject, substitute)
+			}
+			summary, err := cmd.Flags().GetString(flagSummary)
+			if err != nil {
+				return err
+			}
+			if summary == "" {
+				summary = fmt.Sprintf("The IBC client %s can't be updated any more. This proposal substitutes it with the active client %s to recover the IBC connection.", subject, substitute)
+			}
+			depositStr, err := cmd.Flags().GetString(flagDeposit)
+			if err != nil {
+				return err
+			}
+			deposit, err := sdk.ParseCoinsNormalized(depositStr)
+			if err != nil {
+				return fmt.Errorf("invalid deposit: %w", err)
+			}
+			proposalID, err := cmd.Flags().GetUint64(flagProposalID)
+			if err != nil {
+				return err
+			}
+			wait, err := cmd.Flags().GetBool(flagWait)
+			if err != nil {
+				return err
+			}
+			interval, err := cmd.Flags().GetDuration(flagPollInterval)
+			if err != nil {
+				return err
+			}
+
+			if _, err := chain.GetAddress(); err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if proposalID == 0 {
+				proposalID, err = proposer.SubmitClientSubstituteProposal(cmd.Context(), core.ClientSubstituteProposal{
+					Title:              title,
+					Summary:            summary,
+					SubjectClientID:    subject,
+					SubstituteClientID: substitute,
+					Deposit:            deposit,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "submitted the proposal %d to substitute %s with %s on %s\n", proposalID, subject, substitute, chainID)
+			} else if !deposit.IsZero() {
+				if err := proposer.DepositToProposal(cmd.Context(), proposalID, deposit); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "deposited %s to the proposal %d\n", deposit, proposalID)
+			}
+
+			status, err := trackProposal(cmd.Context(), proposer, proposalID, wait, interval, func(s *core.ProposalStatus) {
+				fmt.Fprintln(out, formatProposalStatus(s))
+			})
+			if err != nil {
+				return err
+			}
+			if !status.Final() {
+				return nil
+			}
+			if status.Status != core.ProposalStatusPassed {
+				return fmt.Errorf("the proposal %d was not executed: %s", proposalID, status.Status)
+			}
+
+			latest, err := chain.LatestHeight()
+			if err != nil {
+				return err
+			}
+			clientStatus, err := core.QueryClientStatus(core.NewQueryContext(cmd.Context(), latest), chain)
+			if err != nil {
+				return err
+			}
+			if clientStatus != exported.Active {
+				return fmt.Errorf("the proposal %d passed, but the client %s is still %s", proposalID, subject, clientStatus)
+			}
+			fmt.Fprintf(out, "the client %s is active again; resume relaying with `yrly paths resume %s` if the path is paused\n", subject, pathName)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagTitle, "", "title of the proposal (generated if empty)")
+	cmd.Flags().String(flagSummary, "", "summary of the proposal (generated if empty)")
+	cmd.Flags().String(flagDeposit, "", "initial deposit of the proposal, or the deposit added to the proposal of --proposal-id (the minimum deposit of the chain is used for a new proposal if empty)")
+	cmd.Flags().Uint64(flagProposalID, 0, "ID of the proposal already submitted to track instead of submitting a new one")
+	cmd.Flags().Bool(flagWait, true, "track the status of the proposal until it is executed or rejected")
+	cmd.Flags().Duration(flagPollInterval, 30*time.Second, "interval of polling the status of the proposal")
+	return cmd
+}
+
+// trackProposal polls the status of the proposal until it becomes final if `wait` is true, or returns the current status otherwise.
+// `report` is called when the status changes.
+func trackProposal(ctx context.Context, proposer core.ClientSubstituteProposer, proposalID uint64, wait bool, interval time.Duration, report func(*core.ProposalStatus)) (*core.ProposalStatus, error) {
+	var last string
+	for {
+		status, err := proposer.QueryProposalStatus(ctx, proposalID)
+		if err != nil {
+			return nil, err
+		}
+		if status.Status != last {
+			report(status)
+			last = status.Status
+		}
+		if !wait || status.Final() {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func formatProposalStatus(s *core.ProposalStatus) string {
+	line := fmt.Sprintf("proposal %d: %s", s.ProposalID, s.Status)
+	switch s.Status {
+	case core.ProposalStatusDepositPeriod:
+		line += fmt.Sprintf(" (deposit %s of the minimum %s", s.TotalDeposit, s.MinDeposit)
+		if missing := missingDeposit(s.TotalDeposit, s.MinDeposit); !missing.IsZero() {
+			line += fmt.Sprintf("; add %s with --proposal-id %d --deposit %s", missing, s.ProposalID, missing)
+		}
+		if s.DepositEnd != nil {
+			line += fmt.Sprintf("; the deposit period ends at %s", s.DepositEnd.Format(time.RFC3339))
+		}
+		line += ")"
+	case core.ProposalStatusVotingPeriod:
+		if s.VotingEnd != nil {
+			line += fmt.Sprintf(" (the voting period ends at %s)", s.VotingEnd.Format(time.RFC3339))
+		}
+	}
+	return line
+}
+
+// missingDeposit returns the amounts to add to `total` to reach `min`
+func missingDeposit(total, min sdk.Coins) sdk.Coins {
+	var missing sdk.Coins
+	for _, coin := range min {
+		if have := total.AmountOf(coin.Denom); have.LT(coin.Amount) {
+			missing = missing.Add(sdk.NewCoin(coin.Denom, coin.Amount.Sub(have)))
+		}
+	}
+	return missing
+}
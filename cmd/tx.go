@@ -3,7 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
@@ -33,22 +37,16 @@ func transactionCmd(ctx *config.Context) *cobra.Command {
 		flags.LineBreak,
 		createClientsCmd(ctx),
 		updateClientsCmd(ctx),
+		proposeClientSubstituteCmd(ctx),
 		createConnectionCmd(ctx),
 		createChannelCmd(ctx),
+		linkCmd(ctx),
 	)
 
 	return cmd
 }
 
 func createClientsCmd(ctx *config.Context) *cobra.Command {
-	const (
-		flagSrcHeight = "src-height"
-		flagDstHeight = "dst-height"
-	)
-	const (
-		defaultSrcHeight = 0
-		defaultDstHeight = 0
-	)
 	cmd := &cobra.Command{
 		Use:   "clients [path-name]",
 		Short: "create a clients between two configured chains with a configured path",
@@ -70,38 +68,216 @@ func createClientsCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
-			// if the option "src-height" is not set or is set zero, the latest finalized height is used.
-			var srcHeight exported.Height
-			if height, err := cmd.Flags().GetUint64(flagSrcHeight); err != nil {
+			srcHeight, dstHeight, err := getClientHeights(cmd, c[src], c[dst])
+			if err != nil {
 				return err
-			} else if height == 0 {
-				srcHeight = nil
-			} else if latestHeight, err := c[src].LatestHeight(); err != nil {
-				return fmt.Errorf("failed to get the latest height of src chain: %v", err)
-			} else {
-				srcHeight = clienttypes.NewHeight(latestHeight.GetRevisionNumber(), height)
 			}
 
-			// if the option "dst-height" is not set or is set zero, the latest finalized height is used.
-			var dstHeight exported.Height
-			if height, err := cmd.Flags().GetUint64(flagDstHeight); err != nil {
+			deadlineCtx, cancel, err := getDeadlineContext(cmd)
+			if err != nil {
 				return err
-			} else if height == 0 {
-				dstHeight = nil
-			} else if latestHeight, err := c[dst].LatestHeight(); err != nil {
-				return fmt.Errorf("failed to get the latest height of dst chain: %v", err)
-			} else {
-				dstHeight = clienttypes.NewHeight(latestHeight.GetRevisionNumber(), height)
 			}
+			defer cancel()
 
-			return core.CreateClients(pathName, c[src], c[dst], srcHeight, dstHeight)
+			return core.CreateClients(deadlineCtx, pathName, c[src], c[dst], srcHeight, dstHeight)
 		},
 	}
-	cmd.Flags().Uint64(flagSrcHeight, defaultSrcHeight, "src header at this height is submitted to dst chain")
-	cmd.Flags().Uint64(flagDstHeight, defaultDstHeight, "dst header at this height is submitted to src chain")
+	return deadlineFlag(clientHeightFlags(cmd))
+}
+
+func clientHeightFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Uint64(flagSrcHeight, 0, "src header at this height is submitted to dst chain")
+	cmd.Flags().Uint64(flagDstHeight, 0, "dst header at this height is submitted to src chain")
 	return cmd
 }
 
+// getClientHeights returns the heights of the headers to create the clients with.
+// If the option "src-height" or "dst-height" is not set or is set zero, nil is returned for it and the latest finalized height is used.
+func getClientHeights(cmd *cobra.Command, src, dst *core.ProvableChain) (srcHeight, dstHeight exported.Height, err error) {
+	if height, err := cmd.Flags().GetUint64(flagSrcHeight); err != nil {
+		return nil, nil, err
+	} else if height != 0 {
+		latestHeight, err := src.LatestHeight()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the latest height of src chain: %w", err)
+		}
+		srcHeight = clienttypes.NewHeight(latestHeight.GetRevisionNumber(), height)
+	}
+
+	if height, err := cmd.Flags().GetUint64(flagDstHeight); err != nil {
+		return nil, nil, err
+	} else if height != 0 {
+		latestHeight, err := dst.LatestHeight()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the latest height of dst chain: %w", err)
+		}
+		dstHeight = clienttypes.NewHeight(latestHeight.GetRevisionNumber(), height)
+	}
+	return srcHeight, dstHeight, nil
+}
+
+func linkCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagAll         = "all"
+		flagParallelism = "parallelism"
+	)
+	cmd := &cobra.Command{
+		Use:   "link [path-name]",
+		Short: "create clients, a connection and a channel between two configured chains with a configured path",
+		Long: strings.TrimSpace(`This command performs "tx clients", "tx connection" and "tx channel" in sequence.
+On a path whose protocol is "v2", the clients are registered as the counterparties of each other instead of the handshakes.
+The stages already completed are skipped, and a stage-by-stage summary is printed at the end.
+With --all, every configured path is linked concurrently with at most --parallelism paths at a time,
+where the paths sharing a chain are linked one after another, and a path-by-path summary is printed at the end.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool(flagAll)
+			if err != nil {
+				return err
+			}
+			if all {
+				if len(args) != 0 {
+					return fmt.Errorf("a path name can't be given with --%s", flagAll)
+				}
+				parallelism, err := cmd.Flags().GetInt(flagParallelism)
+				if err != nil {
+					return err
+				}
+				return linkAll(cmd, ctx, parallelism)
+			}
+			if len(args) != 1 {
+				return fmt.Errorf("a path name or --%s is required", flagAll)
+			}
+			pathName := args[0]
+			c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
+			if err != nil {
+				return err
+			}
+
+			// ensure that keys exist
+			if _, err = c[src].GetAddress(); err != nil {
+				return err
+			}
+			if _, err = c[dst].GetAddress(); err != nil {
+				return err
+			}
+
+			srcHeight, dstHeight, err := getClientHeights(cmd, c[src], c[dst])
+			if err != nil {
+				return err
+			}
+			backoff, err := getBackoffPolicy(cmd, ctx)
+			if err != nil {
+				return err
+			}
+			deadlineCtx, cancel, err := getDeadlineContext(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			results, err := core.Link(deadlineCtx, pathName, c[src], c[dst], srcHeight, dstHeight, backoff)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "STAGE\tSTATUS\tELAPSED\tERROR")
+			for _, r := range results {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Stage, r.Status, r.Elapsed.Round(time.Millisecond), r.Error)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			return err
+		},
+	}
+	cmd.Flags().Bool(flagAll, false, "link all the configured paths concurrently")
+	cmd.Flags().Int(flagParallelism, 4, "maximum number of the paths linked at the same time with --all")
+	return deadlineFlag(backoffFlags(clientHeightFlags(cmd)))
+}
+
+// linkAll links all the configured paths concurrently and prints the summary of each path
+func linkAll(cmd *cobra.Command, ctx *config.Context, parallelism int) error {
+	if parallelism < 1 {
+		return fmt.Errorf("parallelism must be positive: %d", parallelism)
+	}
+	for _, name := range []string{flagSrcHeight, flagDstHeight} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s can't be used with --all", name)
+		}
+	}
+	backoff, err := getBackoffPolicy(cmd, ctx)
+	if err != nil {
+		return err
+	}
+	deadlineCtx, cancel, err := getDeadlineContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var pathNames []string
+	for name := range ctx.Config.Paths {
+		pathNames = append(pathNames, name)
+	}
+	sort.Strings(pathNames)
+	var tasks []core.LinkTask
+	for _, pathName := range pathNames {
+		pathName := pathName
+		path := ctx.Config.Paths[pathName]
+		tasks = append(tasks, core.LinkTask{
+			PathName: pathName,
+			ChainIDs: [2]string{path.Src.ChainID, path.Dst.ChainID},
+			Link: func(linkCtx context.Context) ([]core.LinkStageResult, error) {
+				// the path ends are set to the chains only while no other path of the chains is linked
+				c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
+				if err != nil {
+					return nil, err
+				}
+				if _, err = c[src].GetAddress(); err != nil {
+					return nil, err
+				}
+				if _, err = c[dst].GetAddress(); err != nil {
+					return nil, err
+				}
+				return core.Link(linkCtx, pathName, c[src], c[dst], nil, nil, backoff)
+			},
+		})
+	}
+	if len(tasks) == 0 {
+		return fmt.Errorf("no path is configured")
+	}
+
+	results := core.LinkAll(deadlineCtx, tasks, parallelism)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tRESULT\tCLIENTS\tCONNECTION\tCHANNEL\tELAPSED\tERROR")
+	failed := 0
+	for _, r := range results {
+		result := "linked"
+		if r.Error != "" {
+			result = "failed"
+			failed++
+		}
+		stages := make(map[core.LinkStage]core.LinkStageStatus)
+		for _, s := range r.Stages {
+			stages[s.Stage] = s.Status
+		}
+		status := func(stage core.LinkStage) core.LinkStageStatus {
+			if s, ok := stages[stage]; ok {
+				return s
+			}
+			return core.LinkStageNotRun
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.PathName, result,
+			status(core.LinkStageClients), status(core.LinkStageConnection), status(core.LinkStageChannel),
+			r.Elapsed.Round(time.Millisecond), r.Error)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d paths failed to link", failed, len(results))
+	}
+	return nil
+}
+
 func updateClientsCmd(ctx *config.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update-clients [path-name]",
@@ -143,7 +319,7 @@ func createConnectionCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
-			to, err := getTimeout(cmd)
+			backoff, err := getBackoffPolicy(cmd, ctx)
 			if err != nil {
 				return err
 			}
@@ -156,28 +332,67 @@ func createConnectionCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
-			return core.CreateConnection(pathName, c[src], c[dst], to)
+			deadlineCtx, cancel, err := getDeadlineContext(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			return core.CreateConnection(deadlineCtx, pathName, c[src], c[dst], backoff)
 		},
 	}
 
-	return timeoutFlag(cmd)
+	return deadlineFlag(backoffFlags(cmd))
 }
 
 func createChannelCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagPort       = "port"
+		flagSrcPort    = "src-port"
+		flagDstPort    = "dst-port"
+		flagOrder      = "order"
+		flagVersion    = "version"
+		flagSrcVersion = "src-version"
+		flagDstVersion = "dst-version"
+		flagResume     = "resume"
+	)
 	cmd := &cobra.Command{
 		Use:   "channel [path-name]",
 		Short: "create a channel between two configured chains with a configured path",
 		Long: strings.TrimSpace(`This command is meant to be used to repair or 
-		create a channel between two chains with a configured path in the config file`),
+		create a channel between two chains with a configured path in the config file.
+		The port, order and version of the channel are taken from the path config unless overridden by the flags,
+		which are saved to the path config. A version may be the JSON metadata of a middleware stack,
+		e.g. --version '{"fee_version":"ics29-1","app_version":"ics20-1"}'.
+		With --resume, a channel handshake on the connection that was initiated by another tool
+		and matches the port, order and version of the path is continued instead of starting a new one.`),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			pathName := args[0]
+			path, err := ctx.Config.Paths.Get(pathName)
+			if err != nil {
+				return err
+			}
+			params, err := channelParamsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			changed, err := path.SetChannelParams(*params)
+			if err != nil {
+				return err
+			}
+			if changed {
+				if err := ctx.Config.OverWriteConfig(); err != nil {
+					return err
+				}
+			}
+
 			c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
 			if err != nil {
 				return err
 			}
 
-			to, err := getTimeout(cmd)
+			backoff, err := getBackoffPolicy(cmd, ctx)
 			if err != nil {
 				return err
 			}
@@ -190,11 +405,63 @@ func createChannelCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
-			return core.CreateChannel(pathName, c[src], c[dst], to)
+			deadlineCtx, cancel, err := getDeadlineContext(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
+			if resume, err := cmd.Flags().GetBool(flagResume); err != nil {
+				return err
+			} else if resume {
+				if err := core.ResumeChannelHandshake(pathName, c[src], c[dst]); err != nil {
+					return err
+				}
+			}
+
+			return core.CreateChannel(deadlineCtx, pathName, c[src], c[dst], backoff)
 		},
 	}
+	cmd.Flags().String(flagPort, "", "port ID of both channel ends")
+	cmd.Flags().String(flagSrcPort, "", "port ID of the src channel end (overrides --port)")
+	cmd.Flags().String(flagDstPort, "", "port ID of the dst channel end (overrides --port)")
+	cmd.Flags().String(flagOrder, "", "channel order ('ORDERED' or 'UNORDERED')")
+	cmd.Flags().String(flagVersion, "", "version proposed by both channel ends, which may be JSON metadata of a middleware stack")
+	cmd.Flags().String(flagSrcVersion, "", "version proposed by the src channel end (overrides --version)")
+	cmd.Flags().String(flagDstVersion, "", "version proposed by the dst channel end (overrides --version)")
+	cmd.Flags().Bool(flagResume, false, "continue a channel handshake in INIT or TRYOPEN state initiated by another tool if the channel IDs of the path are not set")
+	return deadlineFlag(backoffFlags(cmd))
+}
 
-	return timeoutFlag(cmd)
+// channelParamsFromFlags reads the flags of `tx channel` overriding the channel parameters of the path config
+func channelParamsFromFlags(cmd *cobra.Command) (*core.ChannelParams, error) {
+	get := func(name, fallback string) (string, error) {
+		v, err := cmd.Flags().GetString(name)
+		if err != nil || v != "" {
+			return v, err
+		}
+		return cmd.Flags().GetString(fallback)
+	}
+	var (
+		params core.ChannelParams
+		err    error
+	)
+	if params.SrcPortID, err = get("src-port", "port"); err != nil {
+		return nil, err
+	}
+	if params.DstPortID, err = get("dst-port", "port"); err != nil {
+		return nil, err
+	}
+	if params.Order, err = cmd.Flags().GetString("order"); err != nil {
+		return nil, err
+	}
+	if params.SrcVersion, err = get("src-version", "version"); err != nil {
+		return nil, err
+	}
+	if params.DstVersion, err = get("dst-version", "version"); err != nil {
+		return nil, err
+	}
+	return &params, nil
 }
 
 func relayMsgsCmd(ctx *config.Context) *cobra.Command {
@@ -263,7 +530,9 @@ func relayMsgsCmd(ctx *config.Context) *cobra.Command {
 			}
 
 			st.Send(c[src], c[dst], msgs)
-
+			if err := msgs.Err(); err != nil {
+				return fmt.Errorf("failed to relay the packets: %w", err)
+			}
 			return nil
 		},
 	}
@@ -338,7 +607,9 @@ func relayAcksCmd(ctx *config.Context) *cobra.Command {
 			}
 
 			st.Send(c[src], c[dst], msgs)
-
+			if err := msgs.Err(); err != nil {
+				return fmt.Errorf("failed to relay the acknowledgements: %w", err)
+			}
 			return nil
 		},
 	}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/spf13/cobra"
+)
+
+func versionCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Shows the identity of the relayer: the build, the modules, the chain and prover types and the config hash",
+		Long: strings.TrimSpace(`Shows the identity of the relayer to attach to bug reports: the version and the revision of the build,
+the versions of ibc-go, the Cosmos SDK and CometBFT, the connection versions, the modules with their Go module versions,
+the chain and prover types of the configured chains and the hash of the effective config.
+The same identity is logged when a relay service starts and served by the admin API at GET /identity.`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format: %s", output)
+			}
+			id, err := ctx.Identity()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output == "json" {
+				bz, err := json.Marshal(id)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, string(bz))
+				return nil
+			}
+			version := id.Version
+			if id.Revision != "" {
+				version += " (" + id.Revision
+				if id.Modified {
+					version += ", modified"
+				}
+				version += ")"
+			}
+			fmt.Fprintf(out, "version: %s\n", version)
+			fmt.Fprintf(out, "go: %s\n", id.GoVersion)
+			fmt.Fprintf(out, "ibc-go: %s\n", id.IBCGoVersion)
+			fmt.Fprintf(out, "cosmos-sdk: %s\n", id.CosmosSDKVersion)
+			fmt.Fprintf(out, "cometbft: %s\n", id.CometBFTVersion)
+			fmt.Fprintf(out, "connection versions: %s\n", strings.Join(id.ConnectionVersions, " "))
+			fmt.Fprintln(out, "modules:")
+			for _, m := range id.Modules {
+				fmt.Fprintf(out, "  %s: %s@%s\n", m.Name, m.GoModule, m.Version)
+			}
+			fmt.Fprintln(out, "chains:")
+			for _, c := range id.Chains {
+				fmt.Fprintf(out, "  %s: chain=%s prover=%s\n", c.ChainID, c.ChainType, c.ProverType)
+			}
+			fmt.Fprintf(out, "config hash: %s\n", id.ConfigHash)
+			return nil
+		},
+	}
+	return outputFlag(cmd)
+}
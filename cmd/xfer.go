@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	"github.com/hyperledger-labs/yui-relayer/config"
 	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/spf13/cobra"
@@ -16,8 +19,9 @@ func xfersend(ctx *config.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "transfer [path-name] [src-chain-id] [dst-chain-id] [amount] [dst-addr]",
 		Short: "Initiate a transfer from one chain to another",
-		Long: "Sends the first step to transfer tokens in an IBC transfer." +
-			" The created packet must be relayed to another chain",
+		Long: strings.TrimSpace(`Sends the first step to transfer tokens in an IBC transfer. The created packet must be relayed to another chain.
+The timeout is computed from the latest height and block time of the dst chain: 1000 blocks and 10 minutes after them by default,
+which can be overridden with the offset flags or the absolute timeout flags. The command refuses a timeout already in the past.`),
 		Args: cobra.ExactArgs(5),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, _, _, err := ctx.Config.ChainsFromPath(args[0])
@@ -35,12 +39,7 @@ func xfersend(ctx *config.Context) *cobra.Command {
 				return fmt.Errorf("not found chain '%v' in the path", dst)
 			}
 
-			toHeightOffset, err := cmd.Flags().GetUint64(flagTimeoutHeightOffset)
-			if err != nil {
-				return err
-			}
-
-			toTimeOffset, err := cmd.Flags().GetDuration(flagTimeoutTimeOffset)
+			timeout, err := transferTimeoutOptions(cmd)
 			if err != nil {
 				return err
 			}
@@ -60,19 +59,48 @@ func xfersend(ctx *config.Context) *cobra.Command {
 			}
 			dstAddr := args[4]
 
-			switch {
-			case toHeightOffset > 0 && toTimeOffset > 0:
-				return fmt.Errorf("cannot set both --timeout-height-offset and --timeout-time-offset, choose one")
-			case toHeightOffset > 0:
-				return core.SendTransferMsg(c[src], c[dst], amount, dstAddr, toHeightOffset, 0)
-			case toTimeOffset > 0:
-				return core.SendTransferMsg(c[src], c[dst], amount, dstAddr, 0, toTimeOffset)
-			case toHeightOffset == 0 && toTimeOffset == 0:
-				return core.SendTransferMsg(c[src], c[dst], amount, dstAddr, 0, 0)
-			default:
-				return fmt.Errorf("shouldn't be here")
-			}
+			return core.SendTransferMsgWithTimeout(c[src], c[dst], amount, dstAddr, timeout)
 		},
 	}
 	return timeoutFlags(cmd)
 }
+
+// transferTimeoutOptions returns the timeout of a transfer specified by the flags of timeoutFlags
+func transferTimeoutOptions(cmd *cobra.Command) (core.TransferTimeoutOptions, error) {
+	var opts core.TransferTimeoutOptions
+	var err error
+	if opts.HeightOffset, err = cmd.Flags().GetUint64(flagTimeoutHeightOffset); err != nil {
+		return opts, err
+	}
+	if opts.TimeOffset, err = cmd.Flags().GetDuration(flagTimeoutTimeOffset); err != nil {
+		return opts, err
+	}
+	if opts.TimeOffset < 0 {
+		return opts, fmt.Errorf("--%s must not be negative: %v", flagTimeoutTimeOffset, opts.TimeOffset)
+	}
+	height, err := cmd.Flags().GetString(flagTimeoutHeight)
+	if err != nil {
+		return opts, err
+	}
+	if height != "" {
+		if opts.Height, err = clienttypes.ParseHeight(height); err != nil {
+			return opts, fmt.Errorf("invalid --%s: %w", flagTimeoutHeight, err)
+		}
+	}
+	timestamp, err := cmd.Flags().GetString(flagTimeoutTimestamp)
+	if err != nil {
+		return opts, err
+	}
+	if timestamp != "" {
+		if opts.Timestamp, err = time.Parse(time.RFC3339, timestamp); err != nil {
+			return opts, fmt.Errorf("invalid --%s: %w", flagTimeoutTimestamp, err)
+		}
+	}
+	if opts.HeightOffset > 0 && !opts.Height.IsZero() {
+		return opts, fmt.Errorf("cannot set both --%s and --%s", flagTimeoutHeightOffset, flagTimeoutHeight)
+	}
+	if opts.TimeOffset > 0 && !opts.Timestamp.IsZero() {
+		return opts, fmt.Errorf("cannot set both --%s and --%s", flagTimeoutTimeOffset, flagTimeoutTimestamp)
+	}
+	return opts, nil
+}
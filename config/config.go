@@ -12,18 +12,29 @@ import (
 )
 
 type Config struct {
-	Global GlobalConfig             `yaml:"global" json:"global"`
-	Chains []core.ChainProverConfig `yaml:"chains" json:"chains"`
-	Paths  core.Paths               `yaml:"paths" json:"paths"`
+	// Version is the version of the config file layout (see CurrentConfigVersion)
+	Version uint64                   `yaml:"version" json:"version"`
+	Global  GlobalConfig             `yaml:"global" json:"global"`
+	Chains  []core.ChainProverConfig `yaml:"chains" json:"chains"`
+	Paths   core.Paths               `yaml:"paths" json:"paths"`
+	// Groups configures the controls shared by the paths of each group by group name (see core.Path.Group)
+	Groups map[string]*core.GroupCfg `yaml:"groups,omitempty" json:"groups,omitempty"`
+	// Faucets configures the faucets topping up the relayer accounts on testnets by chain ID
+	Faucets map[string]*core.FaucetCfg `yaml:"faucets,omitempty" json:"faucets,omitempty"`
+	// ProofSpecs configures the ICS-23 proof specs of the proofs given by the chains by chain ID
+	ProofSpecs map[string]*core.ProofSpecsCfg `yaml:"proof-specs,omitempty" json:"proof-specs,omitempty"`
 
 	// cache
 	chains Chains `yaml:"-" json:"-"`
+	// manager serializes the access to the config and its write-back (see Manager)
+	manager *ConfigManager `yaml:"-" json:"-"`
 
 	ConfigPath string `yaml:"-" json:"-"`
 }
 
 func defaultConfig(configPath string) Config {
 	return Config{
+		Version:    CurrentConfigVersion,
 		Global:     newDefaultGlobalConfig(),
 		Chains:     []core.ChainProverConfig{},
 		Paths:      core.Paths{},
@@ -36,6 +47,13 @@ type GlobalConfig struct {
 	Timeout        string       `yaml:"timeout" json:"timeout"`
 	LightCacheSize int          `yaml:"light-cache-size" json:"light-cache-size"`
 	LoggerConfig   LoggerConfig `yaml:"logger" json:"logger"`
+
+	// Backoff is the backoff policy between the steps of handshakes. The default policy is used if it is not set.
+	Backoff *core.BackoffPolicy `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+
+	// FaultInjection injects artificial failures into the chains for the e2e tests (e.g. with `--set global.fault-injection.broadcast-drop-rate=0.2`).
+	// It must never be set in production.
+	FaultInjection *core.FaultInjectionCfg `yaml:"fault-injection,omitempty" json:"fault-injection,omitempty"`
 }
 
 type LoggerConfig struct {
@@ -57,6 +75,17 @@ func newDefaultGlobalConfig() GlobalConfig {
 	}
 }
 
+// BackoffPolicy returns the configured backoff policy or the default one
+func (g GlobalConfig) BackoffPolicy() (core.BackoffPolicy, error) {
+	if g.Backoff == nil {
+		return core.DefaultBackoffPolicy(), nil
+	}
+	if err := g.Backoff.Validate(); err != nil {
+		return core.BackoffPolicy{}, err
+	}
+	return *g.Backoff, nil
+}
+
 func (c *Config) InitCoreConfig() {
 	initCoreConfig(c)
 }
@@ -83,6 +112,28 @@ func (c *Config) AddChain(m codec.JSONCodec, config core.ChainProverConfig) erro
 	return nil
 }
 
+// UpdateChainConfig replaces the chain config of the chain `chainID`.
+// The chain instance already built from the previous config is kept, so the new config takes effect on the next run.
+func (c *Config) UpdateChainConfig(m codec.JSONCodec, chainID string, chainConfig core.ChainConfig) error {
+	for i, ch := range c.chains {
+		if ch.ChainID() == chainID {
+			return c.Chains[i].SetChainConfig(m, chainConfig)
+		}
+	}
+	return fmt.Errorf("chain with ID %s doesn't exist in config", chainID)
+}
+
+// UpdateProverConfig replaces the prover config of the chain `chainID`.
+// Unlike UpdateChainConfig, the prover of the chain instance is not rebuilt, since it is swapped by the caller (see core.ProvableChain.SwapProver).
+func (c *Config) UpdateProverConfig(m codec.JSONCodec, chainID string, proverConfig core.ProverConfig) error {
+	for i, ch := range c.chains {
+		if ch.ChainID() == chainID {
+			return c.Chains[i].SetProverConfig(m, proverConfig)
+		}
+	}
+	return fmt.Errorf("chain with ID %s doesn't exist in config", chainID)
+}
+
 // AddPath adds an additional path to the config
 func (c *Config) AddPath(name string, path *core.Path) (err error) {
 	return c.Paths.Add(name, path)
@@ -103,25 +154,37 @@ func (c *Config) DeleteChain(chain string) *Config {
 	return c
 }
 
-// ChainsFromPath takes the path name and returns the properly configured chains
+// ChainsFromPath takes the path name and returns the properly configured chains.
+// The chains are given copies of the path ends, so that the handshakes running concurrently (e.g. `tx link --all`)
+// don't touch the config while it is written by another goroutine. The identifiers generated by the handshakes
+// are saved to the config through the ConfigManager (see CoreConfig.UpdateConfigID).
 func (c *Config) ChainsFromPath(path string) (map[string]*core.ProvableChain, string, string, error) {
-
-	pth, err := c.Paths.Get(path)
-	if err != nil {
-		return nil, "", "", err
+	var srcEnd, dstEnd core.PathEnd
+	if err := c.Manager().View(func(c *Config) error {
+		pth, err := c.Paths.Get(path)
+		if err != nil {
+			return err
+		}
+		srcEnd, dstEnd = *pth.Src, *pth.Dst
+		return nil
+	}); err != nil {
+		return nil, "", "", core.NewFailure(core.FailureConfig, err)
 	}
 
-	src, dst := pth.Src.ChainID, pth.Dst.ChainID
+	src, dst := srcEnd.ChainID, dstEnd.ChainID
 	chains, err := c.chains.Gets(src, dst)
 	if err != nil {
-		return nil, "", "", err
+		return nil, "", "", core.NewFailure(core.FailureConfig, err)
 	}
 
-	if err = chains[src].SetRelayInfo(pth.Src, chains[dst], pth.Dst); err != nil {
-		return nil, "", "", err
+	if err = chains[src].SetRelayInfo(&srcEnd, chains[dst], &dstEnd); err != nil {
+		return nil, "", "", core.NewFailure(core.FailureConfig, err)
+	}
+	if err = chains[dst].SetRelayInfo(&dstEnd, chains[src], &srcEnd); err != nil {
+		return nil, "", "", core.NewFailure(core.FailureConfig, err)
 	}
-	if err = chains[dst].SetRelayInfo(pth.Dst, chains[src], pth.Src); err != nil {
-		return nil, "", "", err
+	if err = core.CheckProtocolSupport(chains[src], chains[dst]); err != nil {
+		return nil, "", "", core.NewFailure(core.FailureConfig, err)
 	}
 
 	return chains, src, dst, nil
@@ -138,23 +201,65 @@ func InitChains(ctx *Context, homePath string, debug bool) error {
 		if err := chain.Init(homePath, to, ctx.Codec, debug); err != nil {
 			return fmt.Errorf("did you remember to run 'rly config init' error:%w", err)
 		}
+		if fi := ctx.Config.Global.FaultInjection; fi != nil && fi.Targets(chain.ChainID()) {
+			if err := chain.InjectFaults(fi); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func (c *Config) InitConfig(ctx *Context, homePath, configPath string, debug bool) error {
-	cfgPath := fmt.Sprintf("%s/%s", homePath, configPath)
+// InitConfig loads the config file at `configPath`, which is relative to `homePath` unless it is an absolute path.
+// `overrides` in the form of `key=value` are applied to the content of the config file (see ApplyOverrides).
+// The overrides are not persisted: the overridden values are kept as they are in the config file if the command updates it.
+func (c *Config) InitConfig(ctx *Context, homePath, configPath string, debug bool, overrides ...string) error {
+	cfgPath := configPath
+	if !filepath.IsAbs(cfgPath) {
+		cfgPath = fmt.Sprintf("%s/%s", homePath, configPath)
+	}
 	c.ConfigPath = cfgPath
 	if _, err := os.Stat(cfgPath); err == nil {
 		file, err := os.ReadFile(cfgPath)
 		if err != nil {
 			return err
 		}
+		c.Manager().loaded(file, overrides)
+		if file, err = upgradeConfig(file); err != nil {
+			return err
+		}
+		if file, err = ApplyOverrides(file, overrides); err != nil {
+			return err
+		}
 		// unmarshall them into the struct
 		if err = UnmarshalJSON(ctx.Codec, file, c); err != nil {
 			return err
 		}
+		for name, path := range c.Paths {
+			path.Normalize()
+			if err := path.ValidateIdentifiers(); err != nil {
+				return fmt.Errorf("path %s: %w", name, err)
+			}
+		}
+		for name, group := range c.Groups {
+			if err := core.ValidateGroupName(name); err != nil {
+				return err
+			}
+			if err := group.Validate(); err != nil {
+				return fmt.Errorf("group %s: %w", name, err)
+			}
+		}
+		for chainID, faucet := range c.Faucets {
+			if err := faucet.Validate(); err != nil {
+				return fmt.Errorf("chain %s: %w", chainID, err)
+			}
+		}
+		for chainID, specs := range c.ProofSpecs {
+			if err := specs.Validate(); err != nil {
+				return fmt.Errorf("chain %s: %w", chainID, err)
+			}
+		}
 		// ensure config has []*relayer.Chain used for all chain operations
 		if err = InitChains(ctx, homePath, debug); err != nil {
 			return err
@@ -188,15 +293,11 @@ func (c *Config) CreateConfig() error {
 	return nil
 }
 
+// OverWriteConfig writes the config to the config file atomically.
+// The previous content of the config file is kept as a backup file with the suffix ".bak".
+// It returns ErrConfigChanged without writing if the config file has been modified by another process since it was loaded.
 func (c *Config) OverWriteConfig() error {
-	configData, err := json.Marshal(c)
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(c.ConfigPath, configData, 0600); err != nil {
-		return err
-	}
-	return nil
+	return c.Manager().Write()
 }
 
 func defaultConfigBytes(configPath string) []byte {
@@ -1,9 +1,25 @@
 package config
 
-import "github.com/cosmos/cosmos-sdk/codec"
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
 
 type Context struct {
 	Modules []ModuleI
 	Codec   codec.ProtoCodecMarshaler
 	Config  *Config
 }
+
+// NewContext returns a context with an empty config and a codec with the interfaces of the modules registered.
+// The packet decoders of the modules are registered to the global registry as well.
+func NewContext(modules ...ModuleI) *Context {
+	codec := core.MakeCodec()
+	for _, module := range modules {
+		module.RegisterInterfaces(codec.InterfaceRegistry())
+		if r, ok := module.(PacketDecoderRegisterer); ok {
+			r.RegisterPacketDecoders(core.GetPacketDecoderRegistry())
+		}
+	}
+	return &Context{Modules: modules, Config: &Config{}, Codec: codec}
+}
@@ -3,14 +3,20 @@ package config
 import (
 	"fmt"
 
+	"github.com/cosmos/cosmos-sdk/codec"
+
 	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
 )
 
 type CoreConfig struct {
 	config *Config
 }
 
-var _ core.ConfigI = (*CoreConfig)(nil)
+var (
+	_ core.ConfigI             = (*CoreConfig)(nil)
+	_ core.ProverConfigUpdater = (*CoreConfig)(nil)
+)
 
 func initCoreConfig(c *Config) {
 	config := &CoreConfig{
@@ -19,8 +25,33 @@ func initCoreConfig(c *Config) {
 	core.SetCoreConfig(config)
 }
 
+// UpdateConfigID saves the identifier to the path end and writes the config file.
+// The update goes through the ConfigManager, since the handshakes of multiple paths may run concurrently (e.g. `tx link --all`).
 func (c CoreConfig) UpdateConfigID(pathName string, chainID string, configID core.ConfigIDType, id string) error {
-	configPath, err := c.config.Paths.Get(pathName)
+	if err := c.config.Manager().Update(func(config *Config) error {
+		return updateConfigID(config, pathName, chainID, configID, id)
+	}); err != nil {
+		return err
+	}
+	logger := log.GetLogger().WithModule("config")
+	logger.Info("saved the generated identifier to the config file", "path_name", pathName, "chain_id", chainID, "type", string(configID), "id", id)
+	return nil
+}
+
+// UpdateProverConfig saves the prover config of the chain swapped at runtime and writes the config file
+func (c CoreConfig) UpdateProverConfig(m codec.JSONCodec, chainID string, prover core.ProverConfig) error {
+	if err := c.config.Manager().Update(func(config *Config) error {
+		return config.UpdateProverConfig(m, chainID, prover)
+	}); err != nil {
+		return err
+	}
+	logger := log.GetLogger().WithModule("config")
+	logger.Info("saved the prover config to the config file", "chain_id", chainID)
+	return nil
+}
+
+func updateConfigID(c *Config, pathName string, chainID string, configID core.ConfigIDType, id string) error {
+	configPath, err := c.Paths.Get(pathName)
 	if err != nil {
 		return err
 	}
@@ -34,16 +65,6 @@ func (c CoreConfig) UpdateConfigID(pathName string, chainID string, configID cor
 	if pathEnd == nil {
 		return fmt.Errorf("pathEnd is nil")
 	}
-	switch configID {
-	case core.ConfigIDClient:
-		pathEnd.ClientID = id
-	case core.ConfigIDConnection:
-		pathEnd.ConnectionID = id
-	case core.ConfigIDChannel:
-		pathEnd.ChannelID = id
-	}
-	if err := c.config.OverWriteConfig(); err != nil {
-		return err
-	}
+	pathEnd.SetConfigID(configID, id)
 	return nil
 }
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+// ConfigImpact is what the operator must do to a running relayer for a config change to take effect
+type ConfigImpact string
+
+const (
+	// ImpactNone means that no relay service is affected by the change
+	ImpactNone ConfigImpact = "none"
+	// ImpactRestart means that the relay services of the affected paths must be restarted
+	ImpactRestart ConfigImpact = "restart"
+	// ImpactStart means that a relay service must be started for the added path
+	ImpactStart ConfigImpact = "start"
+	// ImpactStop means that the relay service of the removed path must be stopped
+	ImpactStop ConfigImpact = "stop"
+)
+
+// ConfigChange is a difference between two configs
+type ConfigChange struct {
+	// Section is "global", "chain" or "path"
+	Section string `json:"section"`
+	// Name is the chain ID or the path name, or empty for the global section
+	Name string `json:"name,omitempty"`
+	// Key is the dot-separated key of the changed field, or empty if the whole chain or path is added or removed
+	Key string `json:"key,omitempty"`
+	// Old and New are the JSON values of the field, which are empty if the field is absent
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+
+	Impact ConfigImpact `json:"impact"`
+	// Paths are the paths of which relay services are affected by the change
+	Paths []string `json:"paths,omitempty"`
+}
+
+// ParseConfig parses a config file in the current layout (or a layout migrated implicitly) without initializing the chains
+func ParseConfig(m codec.Codec, bz []byte) (*Config, error) {
+	bz, err := upgradeConfig(bz)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := UnmarshalJSON(m, bz, &c); err != nil {
+		return nil, err
+	}
+	for name, path := range c.Paths {
+		path.Normalize()
+		if err := path.ValidateIdentifiers(); err != nil {
+			return nil, fmt.Errorf("path %s: %w", name, err)
+		}
+	}
+	return &c, nil
+}
+
+// DiffConfigs returns the changes from `old` to `new`.
+// Every config is read only when a relay service starts, so a change of the global settings, a chain or a path
+// takes effect only after the relay services of the affected paths are restarted.
+func DiffConfigs(old, new *Config) ([]ConfigChange, error) {
+	var changes []ConfigChange
+
+	// the global settings are used by all the relay services
+	allPaths := runningPaths(old, func(string, string) bool { return true })
+	global, err := diffJSON(old.Global, new.Global)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range global {
+		changes = append(changes, ConfigChange{Section: "global", Key: d.key, Old: d.old, New: d.new, Impact: restartImpact(allPaths), Paths: allPaths})
+	}
+
+	oldChains, err := chainConfigsByID(old)
+	if err != nil {
+		return nil, err
+	}
+	newChains, err := chainConfigsByID(new)
+	if err != nil {
+		return nil, err
+	}
+	for _, chainID := range sortedKeys(oldChains, newChains) {
+		paths := runningPaths(old, func(src, dst string) bool { return src == chainID || dst == chainID })
+		o, inOld := oldChains[chainID]
+		n, inNew := newChains[chainID]
+		switch {
+		case !inNew:
+			changes = append(changes, ConfigChange{Section: "chain", Name: chainID, Old: string(o), Impact: restartImpact(paths), Paths: paths})
+		case !inOld:
+			changes = append(changes, ConfigChange{Section: "chain", Name: chainID, New: string(n), Impact: restartImpact(paths), Paths: paths})
+		default:
+			diffs, err := diffJSON(o, n)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range diffs {
+				changes = append(changes, ConfigChange{Section: "chain", Name: chainID, Key: d.key, Old: d.old, New: d.new, Impact: restartImpact(paths), Paths: paths})
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(old.Paths, new.Paths) {
+		o, inOld := old.Paths[name]
+		n, inNew := new.Paths[name]
+		switch {
+		case !inNew:
+			changes = append(changes, ConfigChange{Section: "path", Name: name, Impact: ImpactStop, Paths: []string{name}})
+		case !inOld:
+			changes = append(changes, ConfigChange{Section: "path", Name: name, Impact: ImpactStart, Paths: []string{name}})
+		default:
+			diffs, err := diffJSON(o, n)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range diffs {
+				changes = append(changes, ConfigChange{Section: "path", Name: name, Key: d.key, Old: d.old, New: d.new, Impact: ImpactRestart, Paths: []string{name}})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// restartImpact returns the impact of a change affecting the relay services of `paths`
+func restartImpact(paths []string) ConfigImpact {
+	if len(paths) == 0 {
+		return ImpactNone
+	}
+	return ImpactRestart
+}
+
+// runningPaths returns the names of the paths in the current config of which chains satisfy `f`.
+// The paths added by the new config are excluded because their services are not running yet.
+func runningPaths(old *Config, f func(src, dst string) bool) []string {
+	var paths []string
+	for _, name := range sortedKeys(old.Paths) {
+		p := old.Paths[name]
+		if f(p.Src.ChainID, p.Dst.ChainID) {
+			paths = append(paths, name)
+		}
+	}
+	return paths
+}
+
+// chainConfigsByID returns the JSON of the chain and prover configs by chain ID
+func chainConfigsByID(c *Config) (map[string]json.RawMessage, error) {
+	configs := make(map[string]json.RawMessage)
+	for i, chain := range c.chains {
+		bz, err := json.Marshal(c.Chains[i])
+		if err != nil {
+			return nil, err
+		}
+		configs[chain.ChainID()] = bz
+	}
+	return configs, nil
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type jsonDiff struct {
+	key      string
+	old, new string
+}
+
+// diffJSON compares the JSON encodings of `old` and `new` field by field.
+// The objects are compared recursively, and the other values (including arrays) are compared as a whole.
+func diffJSON(old, new interface{}) ([]jsonDiff, error) {
+	o, err := toJSONValue(old)
+	if err != nil {
+		return nil, err
+	}
+	n, err := toJSONValue(new)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []jsonDiff
+	if err := diffJSONValues("", o, n, &diffs); err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(bz, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diffJSONValues(key string, old, new interface{}, diffs *[]jsonDiff) error {
+	oldObj, oldIsObj := old.(map[string]interface{})
+	newObj, newIsObj := new.(map[string]interface{})
+	if oldIsObj && newIsObj {
+		for _, k := range sortedKeys(oldObj, newObj) {
+			child := k
+			if key != "" {
+				child = key + "." + k
+			}
+			if err := diffJSONValues(child, oldObj[k], newObj[k], diffs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	o, err := encodeJSONValue(old)
+	if err != nil {
+		return err
+	}
+	n, err := encodeJSONValue(new)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(o, n) {
+		*diffs = append(*diffs, jsonDiff{key: key, old: string(o), new: string(n)})
+	}
+	return nil
+}
+
+// encodeJSONValue encodes the value, or returns nil for an absent value
+func encodeJSONValue(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// Identity returns the identity of the relayer with the modules of the context and the chains of the loaded config
+func (ctx *Context) Identity() (*core.RelayerIdentity, error) {
+	id := core.NewRelayerIdentity()
+	for _, m := range ctx.Modules {
+		id.AddModule(m.Name(), m)
+	}
+	for i, chain := range ctx.Config.chains {
+		chainConfig, err := ctx.Config.Chains[i].GetChainConfig()
+		if err != nil {
+			return nil, err
+		}
+		proverConfig, err := ctx.Config.Chains[i].GetProverConfig()
+		if err != nil {
+			return nil, err
+		}
+		id.AddChain(chain.ChainID(), chainConfig, proverConfig)
+	}
+	var bz []byte
+	if err := ctx.Config.Manager().View(func(c *Config) (err error) {
+		bz, err = json.Marshal(c)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(bz)
+	id.ConfigHash = hex.EncodeToString(hash[:])
+	return id, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// ErrConfigChanged is returned when the config file has been modified by another process since it was loaded or last written.
+// The config is not written in that case so that the external edits are not lost; reload the config and retry the command.
+var ErrConfigChanged = errors.New("the config file has been changed externally")
+
+// ConfigManager serializes the access to a Config shared by multiple goroutines (e.g. the handshakes of `tx link --all`)
+// and writes it back to the config file.
+// The config file is written atomically, and the write fails with ErrConfigChanged if the file on disk no longer has
+// the content the manager last read or wrote (optimistic concurrency control against the edits by other processes).
+type ConfigManager struct {
+	mu     sync.RWMutex
+	config *Config
+	// digest is the sha256 digest of the config file content last read or written, or nil if the file has not been read
+	digest []byte
+	// overrides applied to the config on load, which are not written to the config file, and the content of the config file before they are applied
+	overrides []string
+	original  []byte
+}
+
+// managersMu guards the lazy creation of the managers of the configs
+var managersMu sync.Mutex
+
+// Manager returns the ConfigManager of the config
+func (c *Config) Manager() *ConfigManager {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+	if c.manager == nil {
+		c.manager = &ConfigManager{config: c}
+	}
+	return c.manager
+}
+
+// loaded records the content of the config file from which the config is loaded with `overrides` (see ApplyOverrides)
+func (m *ConfigManager) loaded(file []byte, overrides []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.digest = digestOf(file)
+	m.overrides = overrides
+	m.original = file
+}
+
+// View calls `f` with the config while no update is in progress
+func (m *ConfigManager) View(f func(c *Config) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return f(m.config)
+}
+
+// Update calls `f` to modify the config and writes the config to the config file if `f` succeeds.
+// The updates are serialized, and the readers through View don't observe the config being modified.
+func (m *ConfigManager) Update(f func(c *Config) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := f(m.config); err != nil {
+		return err
+	}
+	return m.write()
+}
+
+// Write writes the config to the config file
+func (m *ConfigManager) Write() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write()
+}
+
+// write writes the config to the config file atomically, keeping the previous content as a backup file with the suffix ".bak".
+// The values overridden on load are written as they are in the config file so that the overrides are not persisted.
+// The caller must hold the write lock.
+func (m *ConfigManager) write() error {
+	path := m.config.ConfigPath
+	configData, err := json.Marshal(m.config)
+	if err != nil {
+		return err
+	}
+	if configData, err = revertOverrides(configData, m.original, m.overrides); err != nil {
+		return err
+	}
+	if prev, err := os.ReadFile(path); err == nil {
+		if m.digest != nil && !bytes.Equal(digestOf(prev), m.digest) {
+			return fmt.Errorf("%w: %s", ErrConfigChanged, path)
+		}
+		if err := utils.WriteFileAtomic(path+".bak", prev, 0600); err != nil {
+			return fmt.Errorf("failed to back up the config file: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path, configData, 0600); err != nil {
+		return err
+	}
+	m.digest = digestOf(configData)
+	m.original = configData
+	return nil
+}
+
+func digestOf(bz []byte) []byte {
+	digest := sha256.Sum256(bz)
+	return digest[:]
+}
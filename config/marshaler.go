@@ -1,18 +1,26 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 
 	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/hyperledger-labs/yui-relayer/core"
 )
 
 func MarshalJSON(config Config) ([]byte, error) {
 	return json.Marshal(config)
 }
 
+// UnmarshalJSON parses the config file `bz` into `config` and builds its chains.
+// The fields unknown to the config are rejected rather than ignored, so that a typo in the config file is not silently dropped
+// (the unknown fields of the chain and prover configs are rejected by the codec).
 func UnmarshalJSON(m codec.Codec, bz []byte, config *Config) error {
-	if err := json.Unmarshal(bz, config); err != nil {
-		return err
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(config); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
 	}
 	for _, c := range config.Chains {
 		if err := c.Init(m); err != nil {
@@ -22,6 +30,9 @@ func UnmarshalJSON(m codec.Codec, bz []byte, config *Config) error {
 		if err != nil {
 			return err
 		}
+		if pc, ok := m.(codec.ProtoCodecMarshaler); ok {
+			core.RegisterChainInterfaces(pc.InterfaceRegistry(), chain)
+		}
 		config.chains = append(config.chains, chain)
 	}
 	return nil
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentConfigVersion is the version of the config file layout supported by this relayer
+const CurrentConfigVersion uint64 = 1
+
+// Migration upgrades the config file layout from `Version - 1` to `Version`.
+// It operates on the raw JSON object so that layouts which can't be unmarshaled into the current Config can be migrated.
+// The numbers in the object are json.Number so that large integers (e.g. heights and gas) are kept as they are.
+type Migration struct {
+	Version     uint64
+	Description string
+	Migrate     func(cfg map[string]interface{}) error
+	// Implicit is true if the migration changes nothing but the version, including the module configs.
+	// A config file of the previous version is loaded as it is without `config migrate`.
+	Implicit bool
+}
+
+// migrations must be sorted by Version and cover all the versions up to CurrentConfigVersion
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "add the config file version",
+		Migrate:     func(cfg map[string]interface{}) error { return nil },
+		Implicit:    true,
+	},
+}
+
+// ConfigMigrator is an optional interface of ModuleI to migrate the chain and prover configs of the module.
+type ConfigMigrator interface {
+	// MigrateConfig migrates the JSON object of a chain or prover config from the layout of config version `version - 1` to `version`.
+	// It is called for all the chain and prover configs, so it must ignore the configs whose "@type" doesn't belong to the module.
+	// The numbers in the object are json.Number. It isn't called for the implicit migrations.
+	MigrateConfig(version uint64, cfg map[string]interface{}) error
+}
+
+// ConfigVersion returns the version of the config file. A config file without a version has version 0.
+func ConfigVersion(bz []byte) (uint64, error) {
+	var v struct {
+		Version uint64 `json:"version"`
+	}
+	if err := json.Unmarshal(bz, &v); err != nil {
+		return 0, err
+	}
+	return v.Version, nil
+}
+
+// upgradeConfig returns the config file `bz` in the layout supported by this relayer.
+// A config file older than the current version is migrated on load if all the migrations from its version are implicit,
+// and an error is returned if it requires `config migrate`.
+func upgradeConfig(bz []byte) ([]byte, error) {
+	version, err := ConfigVersion(bz)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case version < CurrentConfigVersion:
+		for _, m := range migrations {
+			if m.Version > version && !m.Implicit {
+				return nil, fmt.Errorf("config file version %d is older than the supported version %d: run `config migrate` to upgrade it", version, CurrentConfigVersion)
+			}
+		}
+		out, _, err := MigrateConfig(bz, nil)
+		return out, err
+	case version > CurrentConfigVersion:
+		return nil, fmt.Errorf("config file version %d is newer than the supported version %d: upgrade the relayer", version, CurrentConfigVersion)
+	default:
+		return bz, nil
+	}
+}
+
+// MigrateConfig upgrades the config file `bz` to the current layout and returns the migrated config file and its original version.
+func MigrateConfig(bz []byte, modules []ModuleI) ([]byte, uint64, error) {
+	from, err := ConfigVersion(bz)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > CurrentConfigVersion {
+		return nil, 0, fmt.Errorf("config file version %d is newer than the supported version %d", from, CurrentConfigVersion)
+	}
+
+	// the numbers are kept as they are instead of being converted into float64
+	var cfg map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, 0, err
+	}
+
+	for _, m := range migrations {
+		if m.Version <= from {
+			continue
+		}
+		if err := m.Migrate(cfg); err != nil {
+			return nil, 0, fmt.Errorf("failed to migrate the config to version %d (%s): %v", m.Version, m.Description, err)
+		}
+		// the module configs of the previous version are loaded as they are if the migration is implicit
+		if !m.Implicit {
+			if err := migrateModuleConfigs(cfg, m.Version, modules); err != nil {
+				return nil, 0, fmt.Errorf("failed to migrate the module configs to version %d: %v", m.Version, err)
+			}
+		}
+		cfg["version"] = m.Version
+	}
+
+	out, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, from, nil
+}
+
+func migrateModuleConfigs(cfg map[string]interface{}, version uint64, modules []ModuleI) error {
+	chains, _ := cfg["chains"].([]interface{})
+	for _, module := range modules {
+		migrator, ok := module.(ConfigMigrator)
+		if !ok {
+			continue
+		}
+		for i, c := range chains {
+			c, ok := c.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("chains[%d] is not an object", i)
+			}
+			for _, key := range []string{"chain", "prover"} {
+				if sub, ok := c[key].(map[string]interface{}); ok {
+					if err := migrator.MigrateConfig(version, sub); err != nil {
+						return fmt.Errorf("chains[%d].%s: module %s: %v", i, key, module.Name(), err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
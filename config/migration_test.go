@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/spf13/cobra"
+)
+
+// migratingModule renames "rpc_addr" of its chain configs to "rpc-addr" and records the versions it is called with
+type migratingModule struct {
+	versions []uint64
+}
+
+func (m *migratingModule) Name() string                                             { return "test" }
+func (m *migratingModule) RegisterInterfaces(registry codectypes.InterfaceRegistry) {}
+func (m *migratingModule) GetCmd(ctx *Context) *cobra.Command                       { return nil }
+
+func (m *migratingModule) MigrateConfig(version uint64, cfg map[string]interface{}) error {
+	m.versions = append(m.versions, version)
+	if cfg["@type"] != "/test.ChainConfig" {
+		return nil
+	}
+	if addr, ok := cfg["rpc_addr"]; ok {
+		cfg["rpc-addr"] = addr
+		delete(cfg, "rpc_addr")
+	}
+	return nil
+}
+
+// withMigrations replaces the migrations during the test
+func withMigrations(t *testing.T, ms ...Migration) {
+	orig := migrations
+	migrations = ms
+	t.Cleanup(func() { migrations = orig })
+}
+
+func TestMigrateConfig(t *testing.T) {
+	// the large integers are kept as they are instead of being rounded by float64
+	bz := []byte(`{"global":{"max-gas":18446744073709551615},"chains":[{"chain":{"@type":"/test.ChainConfig","rpc_addr":"http://localhost:26657"},"prover":{"@type":"/test.ProverConfig"}}]}`)
+	out, from, err := MigrateConfig(bz, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != 0 {
+		t.Errorf("unexpected original version: %d", from)
+	}
+	if !strings.Contains(string(out), `"max-gas":18446744073709551615`) {
+		t.Errorf("the number is not kept: %s", out)
+	}
+	if version, err := ConfigVersion(out); err != nil || version != CurrentConfigVersion {
+		t.Errorf("unexpected version of the migrated config: version=%d, err=%v", version, err)
+	}
+
+	// the module configs are migrated only by the explicit migrations
+	withMigrations(t,
+		Migration{Version: 1, Migrate: func(cfg map[string]interface{}) error { return nil }, Implicit: true},
+		Migration{Version: 2, Migrate: func(cfg map[string]interface{}) error {
+			cfg["migrated"] = true
+			return nil
+		}},
+	)
+	module := &migratingModule{}
+	out, _, err = MigrateConfig(bz, []ModuleI{module})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(module.versions) != "[2 2]" {
+		t.Errorf("unexpected versions of the module migrations: %v", module.versions)
+	}
+	var cfg struct {
+		Version  uint64 `json:"version"`
+		Migrated bool   `json:"migrated"`
+		Chains   []struct {
+			Chain map[string]interface{} `json:"chain"`
+		} `json:"chains"`
+	}
+	if err := json.Unmarshal(out, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != 2 || !cfg.Migrated || cfg.Chains[0].Chain["rpc-addr"] != "http://localhost:26657" || cfg.Chains[0].Chain["rpc_addr"] != nil {
+		t.Errorf("unexpected migrated config: %s", out)
+	}
+
+	// a config file of a version newer than the supported one is rejected
+	if _, _, err := MigrateConfig([]byte(`{"version":3}`), nil); err == nil {
+		t.Error("the config file of a newer version is migrated")
+	}
+}
+
+func TestUpgradeConfig(t *testing.T) {
+	// the config file without a version is loaded since the migration to version 1 is implicit
+	out, err := upgradeConfig([]byte(`{"global":{"timeout":"10s"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := ConfigVersion(out); err != nil || version != 1 {
+		t.Errorf("unexpected version of the upgraded config: version=%d, err=%v", version, err)
+	}
+	current := []byte(`{"version":1}`)
+	if out, err := upgradeConfig(current); err != nil || string(out) != string(current) {
+		t.Errorf("the config file of the current version is changed: out=%s, err=%v", out, err)
+	}
+	if _, err := upgradeConfig([]byte(`{"version":2}`)); err == nil || !strings.Contains(err.Error(), "upgrade the relayer") {
+		t.Errorf("unexpected error for a newer config file: %v", err)
+	}
+
+	// the config file requiring an explicit migration is not loaded even if the first migration is implicit
+	withMigrations(t,
+		Migration{Version: 1, Migrate: func(cfg map[string]interface{}) error { return nil }, Implicit: true},
+		Migration{Version: 2, Migrate: func(cfg map[string]interface{}) error { return nil }},
+	)
+	if _, err := upgradeConfig([]byte(`{}`)); err == nil || !strings.Contains(err.Error(), "config migrate") {
+		t.Errorf("unexpected error for a config file requiring the migration: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/spf13/cobra"
 )
 
@@ -16,3 +17,9 @@ type ModuleI interface {
 	// GetCmd returns the command
 	GetCmd(ctx *Context) *cobra.Command
 }
+
+// PacketDecoderRegisterer is an optional interface of ModuleI to register the decoders of the packet data of apps.
+// The decoders are used to log, filter and report the packets relayed on the channels of the apps.
+type PacketDecoderRegisterer interface {
+	RegisterPacketDecoders(registry *core.PacketDecoderRegistry)
+}
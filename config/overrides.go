@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ApplyOverrides applies the overrides in the form of `key=value` to the JSON config file content.
+// A key is a dot-separated path of the JSON fields of the config file, in which array elements are specified by their indices
+// (e.g. `global.timeout=20s`, `chains.0.chain.rpc_addr=http://localhost:26657`, `paths.ibc01.strategy.type=naive`).
+// A value is parsed as JSON if possible (e.g. numbers, booleans, objects), and otherwise as a string.
+// Intermediate objects are created for keys not present in the config file.
+func ApplyOverrides(bz []byte, overrides []string) ([]byte, error) {
+	if len(overrides) == 0 {
+		return bz, nil
+	}
+	root, err := decodeJSON(bz)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		key, value, ok := strings.Cut(o, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid override (must be key=value): %s", o)
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+		if root, err = setValue(root, strings.Split(key, "."), v); err != nil {
+			return nil, fmt.Errorf("failed to apply the override %s: %w", o, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// setValue sets `value` at `keys` under `node` and returns the updated node
+func setValue(node interface{}, keys []string, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	key := keys[0]
+	switch n := node.(type) {
+	case nil:
+		child, err := setValue(nil, keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{key: child}, nil
+	case map[string]interface{}:
+		child, err := setValue(n[key], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = child
+		return n, nil
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, fmt.Errorf("invalid array index: %s", key)
+		}
+		if n[i], err = setValue(n[i], keys[1:], value); err != nil {
+			return nil, err
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%s is not an object or an array", key)
+	}
+}
+
+// revertOverrides returns the config file content `bz` with the values at the keys of `overrides` reverted to the ones in `original`,
+// which is the content of the config file before the overrides are applied, so that the overrides are not persisted.
+// The keys absent in `original` are removed. Note that the changes of the overridden values in `bz` are discarded.
+func revertOverrides(bz, original []byte, overrides []string) ([]byte, error) {
+	if len(overrides) == 0 {
+		return bz, nil
+	}
+	root, err := decodeJSON(bz)
+	if err != nil {
+		return nil, err
+	}
+	orig, err := decodeJSON(original)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range overrides {
+		key, _, _ := strings.Cut(o, "=")
+		keys := strings.Split(key, ".")
+		if v, ok := getValue(orig, keys); ok {
+			root, err = setValue(root, keys, v)
+		} else {
+			err = deleteValue(root, keys)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to revert the override %s: %w", o, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// decodeJSON decodes `bz` keeping the numbers as they are
+func decodeJSON(bz []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// getValue returns the value at `keys` under `node`
+func getValue(node interface{}, keys []string) (interface{}, bool) {
+	if len(keys) == 0 {
+		return node, true
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[keys[0]]
+		if !ok {
+			return nil, false
+		}
+		return getValue(child, keys[1:])
+	case []interface{}:
+		i, err := strconv.Atoi(keys[0])
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, false
+		}
+		return getValue(n[i], keys[1:])
+	default:
+		return nil, false
+	}
+}
+
+// deleteValue removes the field at `keys` under `node` if it exists
+func deleteValue(node interface{}, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	parent, ok := getValue(node, keys[:len(keys)-1])
+	if !ok {
+		return nil
+	}
+	switch n := parent.(type) {
+	case map[string]interface{}:
+		delete(n, keys[len(keys)-1])
+		return nil
+	case []interface{}:
+		return fmt.Errorf("array element %s can't be removed", keys[len(keys)-1])
+	default:
+		return nil
+	}
+}
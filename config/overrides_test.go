@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestRevertOverrides(t *testing.T) {
+	original := []byte(`{"global":{"timeout":"10s","max-gas":18446744073709551615},"chains":[{"chain":{"rpc_addr":"http://a"}}]}`)
+	overrides := []string{"global.timeout=20s", "chains.0.chain.rpc_addr=http://b", "global.new-field=1"}
+	overridden, err := ApplyOverrides(original, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"chains":[{"chain":{"rpc_addr":"http://b"}}],"global":{"max-gas":18446744073709551615,"new-field":1,"timeout":"20s"}}`; string(overridden) != expected {
+		t.Fatalf("unexpected overridden config: %s", overridden)
+	}
+
+	// the overridden values are reverted and the added keys are removed, while the other changes are kept
+	changed, err := ApplyOverrides(overridden, []string{"global.max-gas=100", "paths.ibc01.strategy.type=naive"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverted, err := revertOverrides(changed, original, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"chains":[{"chain":{"rpc_addr":"http://a"}}],"global":{"max-gas":100,"timeout":"10s"},"paths":{"ibc01":{"strategy":{"type":"naive"}}}}`; string(reverted) != expected {
+		t.Errorf("unexpected reverted config: %s", reverted)
+	}
+
+	if out, err := revertOverrides(changed, original, nil); err != nil || string(out) != string(changed) {
+		t.Errorf("the config is changed without overrides: out=%s, err=%v", out, err)
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// JSONSchemaDraft is the JSON schema dialect of the schema generated by ConfigSchema
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON schema of a value in the config file.
+// Only the keywords needed to describe the config file are supported.
+type JSONSchema struct {
+	Schema      string      `json:"$schema,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Type        interface{} `json:"type,omitempty"` // a type name or a list of type names
+	Const       string      `json:"const,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"`
+	Minimum     *int        `json:"minimum,omitempty"`
+
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+	// AdditionalProperties is false for the objects with a fixed set of fields, or the schema of the values of a map
+	AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema   `json:"items,omitempty"`
+	OneOf                []*JSONSchema `json:"oneOf,omitempty"`
+}
+
+// ConfigSchema returns the JSON schema of the config file.
+// The chain and prover configs are described by the implementations of ChainConfig and ProverConfig registered in `registry`
+// by the modules, each identified by its "@type", and every object of the config rejects the fields it doesn't define.
+func ConfigSchema(registry codectypes.InterfaceRegistry) (*JSONSchema, error) {
+	schema := goTypeSchema(reflect.TypeOf(Config{}))
+	schema.Schema = JSONSchemaDraft
+	schema.Title = "yui-relayer config"
+
+	chainSchemas, err := implementationSchemas(registry, core.ChainConfigInterfaceName)
+	if err != nil {
+		return nil, err
+	}
+	proverSchemas, err := implementationSchemas(registry, core.ProverConfigInterfaceName)
+	if err != nil {
+		return nil, err
+	}
+	schema.Properties["chains"].Items = &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"chain":  {Description: "chain config of a chain module", OneOf: chainSchemas},
+			"prover": {Description: "prover config of a prover module", OneOf: proverSchemas},
+		},
+		Required:             []string{"chain", "prover"},
+		AdditionalProperties: false,
+	}
+	return schema, nil
+}
+
+// implementationSchemas returns the schemas of the implementations of the interface `name` registered in `registry`
+func implementationSchemas(registry codectypes.InterfaceRegistry, name string) ([]*JSONSchema, error) {
+	typeURLs := registry.ListImplementations(name)
+	sort.Strings(typeURLs)
+	schemas := make([]*JSONSchema, 0, len(typeURLs))
+	for _, typeURL := range typeURLs {
+		msg, err := registry.Resolve(typeURL)
+		if err != nil {
+			return nil, err
+		}
+		var descriptions map[string]string
+		if d, ok := msg.(core.ConfigFieldDescriber); ok {
+			descriptions = d.DescribeConfigFields()
+		}
+		schema := protoTypeSchema(reflect.TypeOf(msg), descriptions, "")
+		schema.Title = strings.TrimPrefix(typeURL, "/")
+		schema.Properties["@type"] = &JSONSchema{Type: "string", Const: typeURL}
+		schema.Required = append(schema.Required, "@type")
+		schemas = append(schemas, schema)
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no implementation of %s is registered", name)
+	}
+	return schemas, nil
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	zero           = 0
+)
+
+// goTypeSchema returns the schema of the JSON encoding of the Go type `t` by encoding/json
+// (the nil pointers, maps and slices are encoded as null)
+func goTypeSchema(t reflect.Type) *JSONSchema {
+	if t.Kind() == reflect.Ptr {
+		return nullable(goTypeSchema(t.Elem()))
+	}
+	if t == rawMessageType {
+		return &JSONSchema{}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+		addStructFields(schema, t)
+		return schema
+	case reflect.Map:
+		return nullable(&JSONSchema{Type: "object", AdditionalProperties: goTypeSchema(t.Elem())})
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Description: "base64 encoded bytes"}
+		}
+		schema := &JSONSchema{Type: "array", Items: goTypeSchema(t.Elem())}
+		if t.Kind() == reflect.Slice {
+			return nullable(schema)
+		}
+		return schema
+	default:
+		return scalarSchema(t.Kind(), false)
+	}
+}
+
+// addStructFields adds the fields of the struct type `t` encoded by encoding/json to the properties of `schema`
+func addStructFields(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(schema, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema.Properties[name] = goTypeSchema(f.Type)
+	}
+}
+
+// protoTypeSchema returns the schema of the JSON encoding of the generated Go type of a protobuf message by jsonpb,
+// which uses the original field names of the proto file, encodes the 64-bit integers as strings and the unset messages as null
+func protoTypeSchema(t reflect.Type, descriptions map[string]string, prefix string) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := protoFieldName(f.Tag.Get("protobuf"))
+		if name == "" {
+			continue
+		}
+		var fieldSchema *JSONSchema
+		ft := f.Type
+		switch {
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8:
+			fieldSchema = nullable(&JSONSchema{Type: "array", Items: protoValueSchema(ft.Elem(), descriptions, prefix+name+".")})
+		case ft.Kind() == reflect.Map:
+			fieldSchema = nullable(&JSONSchema{Type: "object", AdditionalProperties: protoValueSchema(ft.Elem(), descriptions, prefix+name+".")})
+		case ft.Kind() == reflect.Ptr:
+			fieldSchema = nullable(protoValueSchema(ft, descriptions, prefix+name+"."))
+		default:
+			fieldSchema = protoValueSchema(ft, descriptions, prefix+name+".")
+		}
+		fieldSchema.Description = descriptions[prefix+name]
+		schema.Properties[name] = fieldSchema
+	}
+	return schema
+}
+
+func protoValueSchema(t reflect.Type, descriptions map[string]string, prefix string) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return protoTypeSchema(t, descriptions, prefix)
+	case reflect.Slice:
+		return &JSONSchema{Type: "string", Description: "base64 encoded bytes"}
+	default:
+		return scalarSchema(t.Kind(), true)
+	}
+}
+
+// nullable allows null in place of the value of `schema`
+func nullable(schema *JSONSchema) *JSONSchema {
+	if t, ok := schema.Type.(string); ok {
+		schema.Type = []string{t, "null"}
+	}
+	return schema
+}
+
+// protoFieldName returns the original field name in the `protobuf` struct tag of a generated Go type
+func protoFieldName(tag string) string {
+	for _, s := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(s, "name="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// scalarSchema returns the schema of a scalar value. If `proto` is true, the 64-bit integers are encoded as strings by jsonpb.
+func scalarSchema(kind reflect.Kind, proto bool) *JSONSchema {
+	switch kind {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Int64, reflect.Uint64:
+		if proto {
+			return &JSONSchema{Type: []string{"integer", "string"}, Pattern: "^-?[0-9]+$"}
+		}
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer", Minimum: &zero}
+	default:
+		return &JSONSchema{}
+	}
+}
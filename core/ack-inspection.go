@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// AckResult is the result of a packet decoded from its acknowledgement
+type AckResult string
+
+const (
+	// AckResultSuccess is the result of a successful acknowledgement
+	AckResultSuccess AckResult = "success"
+	// AckResultError is the result of an error acknowledgement, which means the application failed to handle the packet
+	AckResultError AckResult = "error"
+	// AckResultUnknown is the result of an acknowledgement not in the standard format of ICS-04
+	AckResultUnknown AckResult = "unknown"
+)
+
+// DecodeAcknowledgement decodes an acknowledgement in the standard format of ICS-04 (e.g. ICS-20, ICS-27 and ICS-721),
+// and returns its result and the error message if it is an error acknowledgement.
+// Applications may define their own formats, whose result is AckResultUnknown.
+func DecodeAcknowledgement(ack []byte) (AckResult, string) {
+	var v chantypes.Acknowledgement
+	if err := chantypes.SubModuleCdc.UnmarshalJSON(ack, &v); err != nil || v.Response == nil {
+		return AckResultUnknown, ""
+	}
+	if v.Success() {
+		return AckResultSuccess, ""
+	}
+	return AckResultError, v.GetError()
+}
+
+// ErrorAckAlertCfg configures the webhook alert raised when a channel starts producing error acknowledgements at a high rate,
+// which is an early signal of problems of the application on the counterparty chain
+type ErrorAckAlertCfg struct {
+	// WebhookCfg is the webhook receiving the ErrorAckAlert, whose URL is required
+	WebhookCfg `yaml:",inline"`
+
+	// Threshold is the number of error acknowledgements on a channel within the window to raise an alert (default: 10)
+	Threshold uint64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+
+	// Window is the sliding window in which the error acknowledgements are counted, which is also the minimum interval
+	// between alerts of a channel (default: "10m")
+	Window string `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *ErrorAckAlertCfg) Validate() error {
+	if cfg.WebhookURL == "" {
+		return fmt.Errorf("error-ack-alert: webhook-url is required")
+	}
+	if err := cfg.WebhookCfg.Validate(); err != nil {
+		return fmt.Errorf("error-ack-alert: %w", err)
+	}
+	if cfg.Window != "" {
+		if d, err := time.ParseDuration(cfg.Window); err != nil {
+			return fmt.Errorf("error-ack-alert: invalid window: %w", err)
+		} else if d <= 0 {
+			return fmt.Errorf("error-ack-alert: window must be positive: %v", d)
+		}
+	}
+	return nil
+}
+
+// ErrorAckAlert is the payload of the webhook alert
+type ErrorAckAlert struct {
+	Path string `json:"path"`
+	// ChainID, PortID and ChannelID are the end of the channel on which the packets are sent and the error acknowledgements are relayed
+	ChainID   string `json:"chain_id"`
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+	// ErrorAcks is the number of error acknowledgements in the window
+	ErrorAcks int    `json:"error_acks"`
+	Window    string `json:"window"`
+	// LastError is the error message of the latest error acknowledgement
+	LastError string    `json:"last_error"`
+	Time      time.Time `json:"time"`
+}
+
+// errorAckMonitor counts the error acknowledgements per channel and raises alerts
+type errorAckMonitor struct {
+	path      string
+	threshold int
+	window    time.Duration
+	notify    func(ctx context.Context, alert *ErrorAckAlert) error
+
+	mu        sync.Mutex
+	errorAcks map[string][]time.Time
+	alertedAt map[string]time.Time
+}
+
+func newErrorAckMonitor(pathName string, cfg *ErrorAckAlertCfg) *errorAckMonitor {
+	threshold, window := 10, 10*time.Minute
+	if cfg.Threshold > 0 {
+		threshold = int(cfg.Threshold)
+	}
+	if cfg.Window != "" {
+		window, _ = time.ParseDuration(cfg.Window)
+	}
+	send := cfg.WebhookCfg.sender()
+	return &errorAckMonitor{
+		path:      pathName,
+		threshold: threshold,
+		window:    window,
+		notify: func(ctx context.Context, alert *ErrorAckAlert) error {
+			return send(ctx, alert)
+		},
+		errorAcks: make(map[string][]time.Time),
+		alertedAt: make(map[string]time.Time),
+	}
+}
+
+// record records an error acknowledgement relayed to the channel end and returns an alert if the rate exceeds the threshold.
+// An alert is raised at most once per window for each channel.
+func (m *errorAckMonitor) record(chainID, portID, channelID, errMsg string, now time.Time) *ErrorAckAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := chainID + "/" + portID + "/" + channelID
+	times := append(m.errorAcks[key], now)
+	for len(times) > 0 && now.Sub(times[0]) > m.window {
+		times = times[1:]
+	}
+	m.errorAcks[key] = times
+	if len(times) < m.threshold {
+		return nil
+	}
+	if last, ok := m.alertedAt[key]; ok && now.Sub(last) < m.window {
+		return nil
+	}
+	m.alertedAt[key] = now
+	return &ErrorAckAlert{
+		Path:      m.path,
+		ChainID:   chainID,
+		PortID:    portID,
+		ChannelID: channelID,
+		ErrorAcks: len(times),
+		Window:    m.window.String(),
+		LastError: errMsg,
+		Time:      now,
+	}
+}
+
+// SetErrorAckAlert enables the webhook alert raised when a channel of the path starts producing error acknowledgements at a high rate
+func (srv *RelayService) SetErrorAckAlert(pathName string, cfg *ErrorAckAlertCfg) {
+	srv.errorAcks = newErrorAckMonitor(pathName, cfg)
+}
+
+// inspectAcknowledgements decodes the acknowledgements relayed successfully, counts them by result
+// and raises alerts for the channels producing error acknowledgements at a high rate
+func (srv *RelayService) inspectAcknowledgements(entries []*JournalEntry) {
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	now := time.Now()
+	for _, e := range entries {
+		if e.AckResult == "" || !e.Success {
+			continue
+		}
+		metrics.AcknowledgementsRelayedCounter.Add(context.TODO(), 1, api.WithAttributes(
+			attribute.Key("chain_id").String(e.ChainID),
+			attribute.Key("port_id").String(e.SourcePort),
+			attribute.Key("channel_id").String(e.SourceChannel),
+			attribute.Key("result").String(string(e.AckResult)),
+			relayDirectionAttr(e.Direction),
+		))
+		if e.AckResult != AckResultError {
+			continue
+		}
+		logger.Warn("relayed an error acknowledgement",
+			"chain_id", e.ChainID,
+			"source_channel", e.SourceChannel,
+			"sequence", e.Sequence,
+			"ack_error", e.AckError,
+		)
+		if srv.errorAcks == nil {
+			continue
+		}
+		if alert := srv.errorAcks.record(e.ChainID, e.SourcePort, e.SourceChannel, e.AckError, now); alert != nil {
+			logger.Warn("the channel is producing error acknowledgements at a high rate",
+				"chain_id", alert.ChainID,
+				"channel_id", alert.ChannelID,
+				"error_acks", alert.ErrorAcks,
+				"window", alert.Window,
+			)
+			// the alert is sent in the background not to delay the relay
+			go func() {
+				if err := srv.errorAcks.notify(context.TODO(), alert); err != nil {
+					logger.Error("failed to send the error acknowledgement alert", err)
+				}
+			}()
+		}
+	}
+}
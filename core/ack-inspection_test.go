@@ -0,0 +1,48 @@
+package core_test
+
+import (
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestDecodeAcknowledgement(t *testing.T) {
+	cases := map[string]struct {
+		ack    []byte
+		result core.AckResult
+	}{
+		"success": {chantypes.NewResultAcknowledgement([]byte{1}).Acknowledgement(), core.AckResultSuccess},
+		"error":   {chantypes.NewErrorAcknowledgement(chantypes.ErrInvalidPacket).Acknowledgement(), core.AckResultError},
+		"garbage": {[]byte("not an acknowledgement"), core.AckResultUnknown},
+		"empty":   {[]byte("{}"), core.AckResultUnknown},
+	}
+	for name, c := range cases {
+		result, errMsg := core.DecodeAcknowledgement(c.ack)
+		if result != c.result {
+			t.Errorf("%s: unexpected result: actual=%s, expected=%s", name, result, c.result)
+		}
+		if (errMsg != "") != (c.result == core.AckResultError) {
+			t.Errorf("%s: unexpected error message: %q", name, errMsg)
+		}
+	}
+}
+
+func TestErrorAckAlertCfgValidate(t *testing.T) {
+	cases := []struct {
+		cfg   core.ErrorAckAlertCfg
+		valid bool
+	}{
+		{core.ErrorAckAlertCfg{WebhookCfg: core.WebhookCfg{WebhookURL: "https://example.com/hook"}}, true},
+		{core.ErrorAckAlertCfg{Threshold: 3, Window: "1m", WebhookCfg: core.WebhookCfg{WebhookURL: "http://localhost:8080", Timeout: "5s"}}, true},
+		{core.ErrorAckAlertCfg{}, false},
+		{core.ErrorAckAlertCfg{WebhookCfg: core.WebhookCfg{WebhookURL: "example.com/hook"}}, false},
+		{core.ErrorAckAlertCfg{Window: "10", WebhookCfg: core.WebhookCfg{WebhookURL: "https://example.com/hook"}}, false},
+		{core.ErrorAckAlertCfg{WebhookCfg: core.WebhookCfg{WebhookURL: "https://example.com/hook", Timeout: "-1s"}}, false},
+	}
+	for i, c := range cases {
+		if err := c.cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("case %d: unexpected validation result: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,521 @@
+package core
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+const (
+	// eventStreamBuffer is the number of the relay events buffered for a client of `GET /events`
+	eventStreamBuffer = 256
+	// eventStreamKeepAlive is the interval of the keep-alive messages of `GET /events`
+	eventStreamKeepAlive = 30 * time.Second
+	// adminReadHeaderTimeout is the time limit to read the headers of a request to the admin API
+	adminReadHeaderTimeout = 10 * time.Second
+)
+
+// AdminServerOptions are the options of the admin API server
+type AdminServerOptions struct {
+	// AuthToken is the bearer token required in the Authorization header of the requests (disabled if empty)
+	AuthToken string
+
+	// TLSCertFile and TLSKeyFile are the certificate and the key with which the API is served over TLS (disabled if empty)
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile is the CA certificate to verify the client certificates, which authenticate the clients by mutual TLS (disabled if empty)
+	TLSClientCAFile string
+
+	// AllowedOrigins are the origins (e.g. "https://dashboard.example.com") of the web pages allowed to stream the events by `GET /events`.
+	// The requests from the other web pages are rejected, even from the same origin as the admin API, which may be a rebound DNS name.
+	// The requests without the Origin header (i.e. not from browsers) are always allowed.
+	AllowedOrigins []string
+
+	// EnableProverSwap enables `POST /prover`, which replaces the prover of a chain and saves it to the config file
+	EnableProverSwap bool
+}
+
+// AdminServer serves the HTTP API to operate a running relay service.
+// All the responses are JSON objects, and errors are returned as {"error": "..."}.
+//
+// The endpoints changing the state of the service (all the methods but GET) and the event stream require the client
+// to be authenticated by the bearer token or by a client certificate (mutual TLS), and they are disabled if neither is configured.
+// If either is configured, all the endpoints require the authentication.
+type AdminServer struct {
+	srv    *RelayService
+	mux    *http.ServeMux
+	opts   AdminServerOptions
+	server *http.Server
+
+	upgrader websocket.Upgrader
+}
+
+// NewAdminServer returns the admin API server of the relay service
+func NewAdminServer(srv *RelayService, opts AdminServerOptions) *AdminServer {
+	s := &AdminServer{srv: srv, mux: http.NewServeMux(), opts: opts}
+	s.upgrader = websocket.Upgrader{CheckOrigin: s.checkOrigin}
+	s.mux.HandleFunc("/held-packets", s.handleHeldPackets)
+	s.mux.HandleFunc("/held-packets/release", s.handleReleasePacket)
+	s.mux.HandleFunc("/delayed-packets", s.handleDelayedPackets)
+	s.mux.HandleFunc("/relay-packet", s.handleRelayPacket)
+	s.mux.HandleFunc("/priority-packets", s.handlePriorityPackets)
+	s.mux.HandleFunc("/pause", s.handlePause)
+	s.mux.HandleFunc("/resume", s.handleResume)
+	s.mux.HandleFunc("/events", s.handleEvents)
+	s.mux.HandleFunc("/identity", s.handleIdentity)
+	s.mux.HandleFunc("/status", s.handleStatus)
+	s.mux.HandleFunc("/prover", s.handleProver)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *AdminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		switch {
+		case s.authConfigured():
+			w.Header().Set("WWW-Authenticate", `Bearer realm="relayer"`)
+			writeAdminError(w, http.StatusUnauthorized, fmt.Errorf("the request is not authenticated"))
+			return
+		case requiresAuth(r):
+			writeAdminError(w, http.StatusForbidden, fmt.Errorf("%s %s requires the admin API to be configured with an auth token or a client CA", r.Method, r.URL.Path))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start starts serving the admin API on `addr` in background.
+// It returns an error if it fails to listen on `addr`, or if `addr` is not a loopback address and TLS is not configured,
+// since the auth token would be sent in plaintext over the network.
+func (s *AdminServer) Start(addr string) error {
+	logger := log.GetLogger().WithModule("core.admin")
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+	if !isLoopbackAddr(addr) {
+		if tlsConfig == nil {
+			return fmt.Errorf("refusing to serve the admin API on the non-loopback address %s without TLS", addr)
+		}
+		logger.Warn("the admin API is served on a non-loopback address", "addr", addr)
+	}
+	if !s.authConfigured() {
+		logger.Warn("neither an auth token nor a client CA is configured, so only the read-only endpoints of the admin API are served", "addr", addr)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s for the admin API: %w", addr, err)
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: adminReadHeaderTimeout}
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("admin API server stopped", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops serving the admin API
+func (s *AdminServer) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+func (s *AdminServer) authConfigured() bool {
+	return s.opts.AuthToken != "" || s.opts.TLSClientCAFile != ""
+}
+
+// authenticated returns true if the client of `r` is authenticated by the bearer token or by a verified client certificate
+func (s *AdminServer) authenticated(r *http.Request) bool {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return true
+	}
+	if s.opts.AuthToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.AuthToken)) == 1
+}
+
+// checkOrigin returns true if the request is not from a browser or from a web page of the allowed origins
+func (s *AdminServer) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.opts.AllowedOrigins {
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresAuth returns true if `r` is served only to the authenticated clients even if no authentication is configured
+func requiresAuth(r *http.Request) bool {
+	if r.URL.Path == "/events" {
+		return true
+	}
+	return r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+func (s *AdminServer) tlsConfig() (*tls.Config, error) {
+	if s.opts.TLSCertFile == "" && s.opts.TLSKeyFile == "" {
+		if s.opts.TLSClientCAFile != "" {
+			return nil, fmt.Errorf("the client CA of the admin API requires the server certificate and key")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(s.opts.TLSCertFile, s.opts.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the certificate of the admin API: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if s.opts.TLSClientCAFile != "" {
+		ca, err := os.ReadFile(s.opts.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in the client CA file: %s", s.opts.TLSClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// isLoopbackAddr returns true if `addr` (host:port) is bound only to a loopback interface
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ReleasePacketRequest is the request body of `POST /held-packets/release`
+type ReleasePacketRequest struct {
+	ChainID   string `json:"chain_id"`
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+	Sequence  uint64 `json:"sequence"`
+}
+
+// handleStatus handles `GET /status`
+func (s *AdminServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, s.srv.RelayStatus())
+}
+
+// handleIdentity handles `GET /identity`
+func (s *AdminServer) handleIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	id := s.srv.Identity()
+	if id == nil {
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("the identity of the relayer is not set"))
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, id)
+}
+
+// handleHeldPackets handles `GET /held-packets`
+func (s *AdminServer) handleHeldPackets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, map[string]interface{}{"held_packets": s.srv.HeldPackets()})
+}
+
+// handleReleasePacket handles `POST /held-packets/release`
+func (s *AdminServer) handleReleasePacket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	var req ReleasePacketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+	if err := s.srv.ReleasePacket(req.ChainID, req.PortID, req.ChannelID, req.Sequence); err != nil {
+		writeAdminError(w, http.StatusNotFound, err)
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, map[string]interface{}{"released": req})
+}
+
+// handleDelayedPackets handles `GET /delayed-packets`
+func (s *AdminServer) handleDelayedPackets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, map[string]interface{}{"delayed_packets": s.srv.DelayedPackets()})
+}
+
+// RelayPacketRequest is the request body of `POST /relay-packet`
+type RelayPacketRequest struct {
+	// Path is the name of the path served by the relayer, which is checked to catch requests sent to a wrong relayer
+	Path string `json:"path"`
+	// ChainID is the ID of the chain from which the packet is sent, which is needed only if both ends of a channel have the same channel ID
+	ChainID   string `json:"chain_id,omitempty"`
+	ChannelID string `json:"channel_id"`
+	Sequence  uint64 `json:"sequence"`
+}
+
+// handleRelayPacket handles `POST /relay-packet`, which requests a packet to be relayed on demand.
+// It responds 202 Accepted as soon as the request is queued, and the packet is relayed in the next relay cycle started immediately.
+func (s *AdminServer) handleRelayPacket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	var req RelayPacketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+	p, err := s.srv.RelayPacket(req.Path, req.ChainID, req.ChannelID, req.Sequence)
+	switch {
+	case errors.Is(err, ErrRelayRequestNotFound):
+		writeAdminError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrRelayRequestRejected):
+		writeAdminError(w, http.StatusConflict, err)
+	case err != nil:
+		writeAdminError(w, http.StatusBadRequest, err)
+	default:
+		writeAdminResponse(w, http.StatusAccepted, map[string]interface{}{"queued": p})
+	}
+}
+
+// handlePriorityPackets handles `GET /priority-packets`
+func (s *AdminServer) handlePriorityPackets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, map[string]interface{}{"priority_packets": s.srv.PriorityPackets()})
+}
+
+// PauseRequest is the request body of `POST /pause`
+type PauseRequest struct {
+	Reason string `json:"reason"`
+}
+
+// handlePause handles `GET /pause` to get the pause state and `POST /pause` to pause the path
+func (s *AdminServer) handlePause(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req PauseRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+				return
+			}
+		}
+		if err := s.srv.Pause(req.Reason); err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+	default:
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	s.writePauseState(w)
+}
+
+// handleResume handles `POST /resume`
+func (s *AdminServer) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	if err := s.srv.Resume(); err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.writePauseState(w)
+}
+
+// handleEvents handles `GET /events`, which streams the relay events in real time.
+// The events are sent as JSON text messages over WebSocket if the request is a WebSocket handshake,
+// and as Server-Sent Events otherwise. The types of the events can be restricted by `?types=packet_relayed,error`.
+func (s *AdminServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	if !s.checkOrigin(r) {
+		writeAdminError(w, http.StatusForbidden, fmt.Errorf("origin not allowed: %s", r.Header.Get("Origin")))
+		return
+	}
+	types, err := ParseRelayEventTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	if websocket.IsWebSocketUpgrade(r) {
+		s.streamEventsOverWebSocket(w, r, types)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAdminError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	events, cancel := s.srv.Events().Subscribe(eventStreamBuffer, types...)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case ev := <-events:
+			bz, err := json.Marshal(ev)
+			if err != nil {
+				logger := log.GetLogger().WithModule("core.admin")
+				logger.Error("failed to marshal the relay event", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, bz); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func (s *AdminServer) streamEventsOverWebSocket(w http.ResponseWriter, r *http.Request, types []RelayEventType) {
+	logger := log.GetLogger().WithModule("core.admin")
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already responded with an error
+		logger.Error("failed to upgrade the connection to WebSocket", err)
+		return
+	}
+	defer conn.Close()
+	events, cancel := s.srv.Events().Subscribe(eventStreamBuffer, types...)
+	defer cancel()
+
+	// the messages from the client are discarded, but reading them is needed to detect the close of the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case ev := <-events:
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// SwapProverRequest is the request body of `POST /prover`
+type SwapProverRequest struct {
+	ChainID string `json:"chain_id"`
+	// Prover is the prover config in the same format as the config file (e.g. {"@type": "/relayer.provers.mock.config.ProverConfig", ...})
+	Prover json.RawMessage `json:"prover"`
+}
+
+// handleProver handles `GET /prover` to get the provers of the chains and `POST /prover` to swap the prover of a chain
+func (s *AdminServer) handleProver(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAdminResponse(w, http.StatusOK, map[string]interface{}{"provers": s.srv.Provers()})
+	case http.MethodPost:
+		if !s.opts.EnableProverSwap {
+			writeAdminError(w, http.StatusForbidden, fmt.Errorf("swapping the prover is not enabled on the admin API"))
+			return
+		}
+		var req SwapProverRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+			return
+		}
+		info, err := s.srv.SwapProver(r.Context(), req.ChainID, req.Prover)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeAdminResponse(w, http.StatusOK, map[string]interface{}{"swapped": info})
+	default:
+		writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+	}
+}
+
+func (s *AdminServer) writePauseState(w http.ResponseWriter) {
+	state, err := s.srv.PauseState()
+	if err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAdminResponse(w, http.StatusOK, state)
+}
+
+func writeAdminResponse(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger := log.GetLogger().WithModule("core.admin")
+		logger.Error("failed to write the response", err)
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminResponse(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,149 @@
+package core_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestAdminServerAuth(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, _ := newScriptedChains(t)
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], nil, 0, 0, 0, 0, 0)
+	serve := func(admin *core.AdminServer, method, path, token string) int {
+		var body string
+		if method == http.MethodPost {
+			body = `{"chain_id":"ibc0","port_id":"transfer","channel_id":"channel-0","sequence":1}`
+		}
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// only the read-only endpoints are served without the authentication
+	open := core.NewAdminServer(srv, core.AdminServerOptions{})
+	cases := []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/status", http.StatusOK},
+		{http.MethodGet, "/held-packets", http.StatusOK},
+		{http.MethodPost, "/held-packets/release", http.StatusForbidden},
+		{http.MethodPost, "/pause", http.StatusForbidden},
+		{http.MethodGet, "/events", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		if status := serve(open, c.method, c.path, "token"); status != c.status {
+			t.Errorf("%s %s without auth: unexpected status: actual=%d, expected=%d", c.method, c.path, status, c.status)
+		}
+	}
+
+	// all the endpoints require the token once it is configured
+	admin := core.NewAdminServer(srv, core.AdminServerOptions{AuthToken: "token"})
+	for _, token := range []string{"", "wrong"} {
+		for _, c := range cases {
+			if status := serve(admin, c.method, c.path, token); status != http.StatusUnauthorized {
+				t.Errorf("%s %s with token %q: unexpected status: %d", c.method, c.path, token, status)
+			}
+		}
+	}
+	if status := serve(admin, http.MethodGet, "/status", "token"); status != http.StatusOK {
+		t.Errorf("unexpected status: %d", status)
+	}
+	// the request reaches the handler, which doesn't find the packet
+	if status := serve(admin, http.MethodPost, "/held-packets/release", "token"); status != http.StatusNotFound {
+		t.Errorf("unexpected status: %d", status)
+	}
+}
+
+func TestAdminServerEventsOrigin(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, _ := newScriptedChains(t)
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], nil, 0, 0, 0, 0, 0)
+	admin := core.NewAdminServer(srv, core.AdminServerOptions{AuthToken: "token", AllowedOrigins: []string{"https://dashboard.example.com"}})
+	stream := func(origin string) int {
+		// the stream ends immediately since the request is canceled
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+		req.Header.Set("Authorization", "Bearer token")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	cases := map[string]int{
+		"":                              http.StatusOK,
+		"https://dashboard.example.com": http.StatusOK,
+		"https://evil.example.com":      http.StatusForbidden,
+		// the admin API itself doesn't serve any web page
+		"http://example.com": http.StatusForbidden,
+	}
+	for origin, expected := range cases {
+		if status := stream(origin); status != expected {
+			t.Errorf("origin %q: unexpected status: actual=%d, expected=%d", origin, status, expected)
+		}
+	}
+}
+
+func TestAdminServerStart(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, _ := newScriptedChains(t)
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], nil, 0, 0, 0, 0, 0)
+	admin := core.NewAdminServer(srv, core.AdminServerOptions{AuthToken: "token"})
+
+	// the token would be sent in plaintext over the network
+	for _, addr := range []string{"0.0.0.0:0", ":0"} {
+		if err := admin.Start(addr); err == nil {
+			t.Errorf("the admin API is served on %s without TLS", addr)
+		}
+	}
+	if err := core.NewAdminServer(srv, core.AdminServerOptions{TLSClientCAFile: "ca.pem"}).Start("127.0.0.1:0"); err == nil {
+		t.Error("the client CA is accepted without the server certificate")
+	}
+
+	// the listen error is returned to the caller
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if err := admin.Start(ln.Addr().String()); err == nil {
+		t.Error("the admin API is served on the address in use")
+	}
+
+	if err := admin.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"sync"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+)
+
+// asyncProofs keeps track of the proof requests issued to AsyncStateProvers across relay cycles
+type asyncProofs struct {
+	mu       sync.Mutex
+	requests map[string]map[string]*asyncProofRequest // chainID => commitment path => request
+}
+
+type asyncProofRequest struct {
+	id          ProofRequestID
+	ready       bool
+	proof       []byte
+	proofHeight clienttypes.Height
+}
+
+func newAsyncProofs() *asyncProofs {
+	return &asyncProofs{requests: make(map[string]map[string]*asyncProofRequest)}
+}
+
+// prove returns a proof of the state specified by `path` and `value`.
+// If the prover of `chain` is an AsyncStateProver, it returns `ready == false` until the requested proof has been generated.
+// Otherwise, it falls back to `ProveState` and the proof is always ready.
+func (ap *asyncProofs) prove(ctx QueryContext, chain *ProvableChain, path string, value []byte) (proof []byte, proofHeight clienttypes.Height, ready bool, err error) {
+	prover, ok := chain.CurrentProver().(AsyncStateProver)
+	if !ok {
+		proof, proofHeight, err = chain.ProveState(ctx, path, value)
+		return proof, proofHeight, err == nil, err
+	}
+
+	logger := GetChannelLogger(chain)
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	reqs, ok := ap.requests[chain.ChainID()]
+	if !ok {
+		reqs = make(map[string]*asyncProofRequest)
+		ap.requests[chain.ChainID()] = reqs
+	}
+
+	req, ok := reqs[path]
+	if !ok {
+		id, err := prover.RequestStateProof(ctx, path, value)
+		if err != nil {
+			return nil, clienttypes.Height{}, false, err
+		}
+		reqs[path] = &asyncProofRequest{id: id}
+		logger.Info("requested a state proof", "height", ctx.Height(), "path", path, "request_id", id)
+		return nil, clienttypes.Height{}, false, nil
+	} else if req.ready {
+		return req.proof, req.proofHeight, true, nil
+	}
+
+	status, err := prover.GetStateProofStatus(req.id)
+	if err != nil {
+		return nil, clienttypes.Height{}, false, err
+	}
+	switch status {
+	case ProofStatusReady:
+		proof, proofHeight, err = prover.GetStateProof(req.id)
+		if err != nil {
+			return nil, clienttypes.Height{}, false, err
+		}
+		// keep the proof until the packet disappears from the relay targets because the relay may fail
+		req.ready, req.proof, req.proofHeight = true, proof, proofHeight
+		return proof, proofHeight, true, nil
+	case ProofStatusFailed:
+		// the proof will be requested again in the next relay cycle
+		delete(reqs, path)
+		logger.Info("state proof generation failed", "path", path, "request_id", req.id)
+		return nil, clienttypes.Height{}, false, nil
+	default:
+		return nil, clienttypes.Height{}, false, nil
+	}
+}
+
+// retain drops the requests on the chain whose paths are not contained in `paths`.
+// It is used to forget the requests for packets that no longer need to be relayed (e.g. already relayed).
+func (ap *asyncProofs) retain(chainID string, paths map[string]struct{}) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	for path := range ap.requests[chainID] {
+		if _, ok := paths[path]; !ok {
+			delete(ap.requests[chainID], path)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// shed modes of BacklogAlarmCfg
+const (
+	// ShedNone keeps relaying all the pending packets of a channel in alarm
+	ShedNone = ""
+	// ShedNewest relays only the newest packets of a channel in alarm
+	ShedNewest = "newest"
+	// ShedIncentivized relays only the packets of a channel in alarm on which ICS-29 fees are escrowed
+	ShedIncentivized = "incentivized"
+)
+
+// BacklogAlarmCfg raises an alert when the pending packets on a channel exceed a threshold,
+// and optionally sheds the packets of the channel so that a spammed channel doesn't consume all the gas budget
+type BacklogAlarmCfg struct {
+	// Threshold is the number of the pending packets sent on a channel end above which the channel is in alarm
+	Threshold uint64 `json:"threshold" yaml:"threshold"`
+
+	// WebhookCfg is the webhook receiving the BacklogAlert when a channel enters or leaves the alarm
+	WebhookCfg `yaml:",inline"`
+
+	// Shed is the packets relayed on a channel in alarm: "" (all), "newest" (the newest ShedCount packets)
+	// or "incentivized" (the packets on which ICS-29 fees are escrowed).
+	// The packets are never shed on ordered channels, on which a packet can't be relayed before the preceding ones.
+	Shed string `json:"shed,omitempty" yaml:"shed,omitempty"`
+
+	// ShedCount is the number of the newest packets relayed if Shed is "newest"
+	ShedCount uint64 `json:"shed-count,omitempty" yaml:"shed-count,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *BacklogAlarmCfg) Validate() error {
+	if cfg.Threshold == 0 {
+		return fmt.Errorf("backlog-alarm: threshold must be positive")
+	}
+	if err := cfg.WebhookCfg.Validate(); err != nil {
+		return fmt.Errorf("backlog-alarm: %w", err)
+	}
+	switch cfg.Shed {
+	case ShedNone, ShedIncentivized:
+	case ShedNewest:
+		if cfg.ShedCount == 0 {
+			return fmt.Errorf("backlog-alarm: shed-count must be positive if shed is %s", ShedNewest)
+		}
+	default:
+		return fmt.Errorf("backlog-alarm: invalid shed: %s", cfg.Shed)
+	}
+	return nil
+}
+
+// ShedPackets returns the packets relayed on a channel in alarm, given the sequences of the incentivized packets.
+// `packets` must be sorted by sequence.
+func (cfg *BacklogAlarmCfg) ShedPackets(packets PacketInfoList, incentivized []uint64) PacketInfoList {
+	switch cfg.Shed {
+	case ShedNewest:
+		if uint64(len(packets)) > cfg.ShedCount {
+			return packets[uint64(len(packets))-cfg.ShedCount:]
+		}
+		return packets
+	case ShedIncentivized:
+		return packets.Filter(incentivized)
+	default:
+		return packets
+	}
+}
+
+// IncentivizedPacketQuerier is an optional interface of Chain supporting the ICS-29 fee middleware,
+// which tells the packets with escrowed fees on the channel currently set to the chain.
+// The packets are shed in the "incentivized" mode only on the chains implementing it.
+type IncentivizedPacketQuerier interface {
+	// QueryIncentivizedPackets returns the sequences of the packets sent on the channel on which fees are escrowed
+	QueryIncentivizedPackets(ctx QueryContext) ([]uint64, error)
+}
+
+// BacklogAlert is the payload of the webhook alert
+type BacklogAlert struct {
+	Path string `json:"path"`
+	// ChainID, PortID and ChannelID are the end of the channel on which the pending packets are sent
+	ChainID   string `json:"chain_id"`
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+	// Alarm is true when the channel enters the alarm, and false when it leaves
+	Alarm          bool      `json:"alarm"`
+	PendingPackets int       `json:"pending_packets"`
+	Threshold      uint64    `json:"threshold"`
+	Shed           string    `json:"shed,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// backlogAlarm keeps track of the channel ends in alarm
+type backlogAlarm struct {
+	path   string
+	cfg    *BacklogAlarmCfg
+	notify func(ctx context.Context, alert *BacklogAlert) error
+
+	mu      sync.Mutex
+	alarmed map[string]bool
+}
+
+func newBacklogAlarm(pathName string, cfg *BacklogAlarmCfg) *backlogAlarm {
+	send := cfg.WebhookCfg.sender()
+	return &backlogAlarm{
+		path: pathName,
+		cfg:  cfg,
+		notify: func(ctx context.Context, alert *BacklogAlert) error {
+			return send(ctx, alert)
+		},
+		alarmed: make(map[string]bool),
+	}
+}
+
+// update updates the alarm state of the channel end with the number of the pending packets,
+// and returns an alert if the channel enters or leaves the alarm
+func (a *backlogAlarm) update(chainID string, end *PathEnd, pending int, now time.Time) *BacklogAlert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := chainID + "/" + end.PortID + "/" + end.ChannelID
+	alarm := uint64(pending) > a.cfg.Threshold
+	if alarm == a.alarmed[key] {
+		return nil
+	}
+	a.alarmed[key] = alarm
+	return &BacklogAlert{
+		Path:           a.path,
+		ChainID:        chainID,
+		PortID:         end.PortID,
+		ChannelID:      end.ChannelID,
+		Alarm:          alarm,
+		PendingPackets: pending,
+		Threshold:      a.cfg.Threshold,
+		Shed:           a.cfg.Shed,
+		Time:           now,
+	}
+}
+
+// SetBacklogAlarm enables the alarm raised when the pending packets on a channel of the path exceed the threshold
+func (srv *RelayService) SetBacklogAlarm(pathName string, cfg *BacklogAlarmCfg) {
+	srv.backlogAlarm = newBacklogAlarm(pathName, cfg)
+}
+
+// checkBacklogs updates the alarm states of the channel ends with the pending packets, and sheds the packets of the channel ends in alarm
+func (srv *RelayService) checkBacklogs(ch *relayChannel, pseqs *RelayPackets) {
+	if srv.backlogAlarm == nil {
+		return
+	}
+	pseqs.Src = srv.checkBacklog(srv.src, ch.srcEnd, pseqs.Src)
+	pseqs.Dst = srv.checkBacklog(srv.dst, ch.dstEnd, pseqs.Dst)
+}
+
+func (srv *RelayService) checkBacklog(chain *ProvableChain, end *PathEnd, packets PacketInfoList) PacketInfoList {
+	logger := GetChainLogger(chain)
+	cfg := srv.backlogAlarm.cfg
+	if alert := srv.backlogAlarm.update(chain.ChainID(), end, len(packets), time.Now()); alert != nil {
+		if alert.Alarm {
+			logger.Warn("the pending packets on the channel exceed the threshold",
+				"channel_id", end.ChannelID, "pending_packets", alert.PendingPackets, "threshold", cfg.Threshold, "shed", cfg.Shed)
+		} else {
+			logger.Info("the pending packets on the channel are back below the threshold",
+				"channel_id", end.ChannelID, "pending_packets", alert.PendingPackets, "threshold", cfg.Threshold)
+		}
+		// the alert is sent in the background not to delay the relay
+		go func() {
+			if err := srv.backlogAlarm.notify(context.TODO(), alert); err != nil {
+				logger.Error("failed to send the backlog alert", err)
+			}
+		}()
+	}
+	if uint64(len(packets)) <= cfg.Threshold || cfg.Shed == ShedNone || end.GetOrder() == chantypes.ORDERED {
+		return packets
+	}
+
+	var incentivized []uint64
+	if cfg.Shed == ShedIncentivized {
+		q, ok := chain.Chain.(IncentivizedPacketQuerier)
+		if !ok {
+			logger.Warn("packets are not shed because the chain doesn't support querying the incentivized packets", "channel_id", end.ChannelID)
+			return packets
+		}
+		seqs, err := q.QueryIncentivizedPackets(srv.sh.GetQueryContext(chain.ChainID()))
+		if err != nil {
+			logger.Error("failed to query the incentivized packets; packets are not shed", err, "channel_id", end.ChannelID)
+			return packets
+		}
+		incentivized = seqs
+	}
+	ret := cfg.ShedPackets(packets, incentivized)
+	if shed := len(packets) - len(ret); shed > 0 {
+		logger.Info("shed the pending packets on the channel in alarm", "channel_id", end.ChannelID, "shed", cfg.Shed, "num_shed", shed, "num_relayed", len(ret))
+		metrics.ShedPacketsCounter.Add(context.TODO(), int64(shed), api.WithAttributes(
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("port_id").String(end.PortID),
+			attribute.Key("channel_id").String(end.ChannelID),
+		))
+	}
+	return ret
+}
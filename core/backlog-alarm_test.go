@@ -0,0 +1,52 @@
+package core_test
+
+import (
+	"reflect"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestBacklogAlarmCfgValidate(t *testing.T) {
+	cases := map[string]struct {
+		cfg   core.BacklogAlarmCfg
+		valid bool
+	}{
+		"alarm only":       {core.BacklogAlarmCfg{Threshold: 100}, true},
+		"newest":           {core.BacklogAlarmCfg{Threshold: 100, Shed: core.ShedNewest, ShedCount: 10}, true},
+		"incentivized":     {core.BacklogAlarmCfg{Threshold: 100, Shed: core.ShedIncentivized, WebhookCfg: core.WebhookCfg{WebhookURL: "https://example.com/hook"}}, true},
+		"no threshold":     {core.BacklogAlarmCfg{}, false},
+		"newest w/o count": {core.BacklogAlarmCfg{Threshold: 100, Shed: core.ShedNewest}, false},
+		"unknown shed":     {core.BacklogAlarmCfg{Threshold: 100, Shed: "oldest"}, false},
+		"invalid webhook":  {core.BacklogAlarmCfg{Threshold: 100, WebhookCfg: core.WebhookCfg{WebhookURL: "example.com"}}, false},
+	}
+	for name, c := range cases {
+		if err := c.cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected result: %v", name, err)
+		}
+	}
+}
+
+func TestBacklogAlarmShedPackets(t *testing.T) {
+	var packets core.PacketInfoList
+	for seq := uint64(1); seq <= 5; seq++ {
+		packets = append(packets, &core.PacketInfo{Packet: chantypes.Packet{Sequence: seq}})
+	}
+	cases := map[string]struct {
+		cfg          core.BacklogAlarmCfg
+		incentivized []uint64
+		expected     []uint64
+	}{
+		"none":         {core.BacklogAlarmCfg{}, nil, []uint64{1, 2, 3, 4, 5}},
+		"newest":       {core.BacklogAlarmCfg{Shed: core.ShedNewest, ShedCount: 2}, nil, []uint64{4, 5}},
+		"newest all":   {core.BacklogAlarmCfg{Shed: core.ShedNewest, ShedCount: 10}, nil, []uint64{1, 2, 3, 4, 5}},
+		"incentivized": {core.BacklogAlarmCfg{Shed: core.ShedIncentivized}, []uint64{2, 5, 7}, []uint64{2, 5}},
+	}
+	for name, c := range cases {
+		seqs := c.cfg.ShedPackets(packets, c.incentivized).ExtractSequenceList()
+		if !reflect.DeepEqual(seqs, c.expected) {
+			t.Errorf("%s: unexpected packets: %v", name, seqs)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// BackoffPolicy defines the jittered exponential backoff to wait between the steps of handshakes
+type BackoffPolicy struct {
+	// InitialInterval is the interval to wait after a successful step (e.g. "1s")
+	InitialInterval string `json:"initial-interval" yaml:"initial-interval"`
+	// MaxInterval caps the interval (e.g. "30s")
+	MaxInterval string `json:"max-interval" yaml:"max-interval"`
+	// Multiplier is the factor by which the interval grows each time the handshake doesn't progress
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+	// Jitter is the fraction of the interval randomly subtracted from it (0 <= Jitter < 1)
+	Jitter float64 `json:"jitter" yaml:"jitter"`
+}
+
+// DefaultBackoffPolicy returns the backoff policy used if it is not configured
+func DefaultBackoffPolicy() BackoffPolicy {
+	return BackoffPolicy{
+		InitialInterval: "1s",
+		MaxInterval:     "30s",
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+// Validate validates the policy
+func (p BackoffPolicy) Validate() error {
+	initial, err := time.ParseDuration(p.InitialInterval)
+	if err != nil {
+		return fmt.Errorf("backoff: invalid initial-interval: %w", err)
+	} else if initial <= 0 {
+		return fmt.Errorf("backoff: initial-interval must be positive: %v", initial)
+	}
+	max, err := time.ParseDuration(p.MaxInterval)
+	if err != nil {
+		return fmt.Errorf("backoff: invalid max-interval: %w", err)
+	} else if max < initial {
+		return fmt.Errorf("backoff: max-interval must not be less than initial-interval: %v < %v", max, initial)
+	}
+	if p.Multiplier < 1 {
+		return fmt.Errorf("backoff: multiplier must not be less than 1: %v", p.Multiplier)
+	}
+	if p.Jitter < 0 || p.Jitter >= 1 {
+		return fmt.Errorf("backoff: jitter must be in [0, 1): %v", p.Jitter)
+	}
+	return nil
+}
+
+// NewBackoff returns a new backoff following the policy. The policy must be valid.
+func (p BackoffPolicy) NewBackoff() *Backoff {
+	initial, _ := time.ParseDuration(p.InitialInterval)
+	max, _ := time.ParseDuration(p.MaxInterval)
+	return &Backoff{
+		initial:    initial,
+		max:        max,
+		multiplier: p.Multiplier,
+		jitter:     p.Jitter,
+		current:    initial,
+	}
+}
+
+// Backoff is the state of a jittered exponential backoff
+type Backoff struct {
+	initial, max time.Duration
+	multiplier   float64
+	jitter       float64
+
+	current time.Duration
+}
+
+// Next returns the interval to wait and grows the interval for the next call
+func (b *Backoff) Next() time.Duration {
+	d := b.current
+	if next := time.Duration(float64(b.current) * b.multiplier); next > b.max {
+		b.current = b.max
+	} else {
+		b.current = next
+	}
+	return d - time.Duration(b.jitter*rand.Float64()*float64(d))
+}
+
+// Reset resets the interval to the initial one
+func (b *Backoff) Reset() {
+	b.current = b.initial
+}
+
+// sleepContext waits for the duration `d` or until `ctx` is done
+func sleepContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
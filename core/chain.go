@@ -17,19 +17,38 @@ import (
 // ProvableChain represents a chain that is supported by the relayer
 type ProvableChain struct {
 	Chain
+	// Prover is the prover of the chain. If the prover swap is enabled (see EnableProverSwap), the prover is held
+	// behind a holder swappable at runtime, so the concrete type and the optional interfaces of the prover
+	// should be checked on CurrentProver instead of this field.
 	Prover
+
+	// setup records the setup of the prover to set up a new prover in the same way on a swap, which is nil if the chain is not built by NewProvableChain
+	setup *proverSetup
+	// faults are the failures injected for testing (see InjectFaults)
+	faults *faultInjector
+	// clientStates caches the client states queried from the chain (see EnableClientStateCache)
+	clientStates *clientStateCache
 }
 
 // NewProvableChain returns a new ProvableChain instance
 func NewProvableChain(chain Chain, prover Prover) *ProvableChain {
-	return &ProvableChain{Chain: chain, Prover: prover}
+	return &ProvableChain{Chain: chain, Prover: prover, setup: &proverSetup{}}
+}
+
+// unwrapChain returns the chain wrapped by `chain` if it is a ProvableChain, which only has the methods of Chain and Prover,
+// so that the optional interfaces of the chain (e.g. TxLimitsProvider) can be checked on it
+func unwrapChain(chain Chain) Chain {
+	if pc, ok := chain.(*ProvableChain); ok {
+		return pc.Chain
+	}
+	return chain
 }
 
 func (pc *ProvableChain) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
 	if err := pc.Chain.Init(homePath, timeout, codec, debug); err != nil {
 		return err
 	}
-	if err := pc.Prover.Init(homePath, timeout, codec, debug); err != nil {
+	if err := pc.setup.initProver(pc.Prover, homePath, timeout, codec, debug); err != nil {
 		return err
 	}
 	return nil
@@ -39,7 +58,7 @@ func (pc *ProvableChain) SetRelayInfo(path *PathEnd, counterparty *ProvableChain
 	if err := pc.Chain.SetRelayInfo(path, counterparty, counterpartyPath); err != nil {
 		return err
 	}
-	if err := pc.Prover.SetRelayInfo(path, counterparty, counterpartyPath); err != nil {
+	if err := pc.setup.setRelayInfo(pc.Prover, path, counterparty, counterpartyPath); err != nil {
 		return err
 	}
 	return nil
@@ -49,12 +68,32 @@ func (pc *ProvableChain) SetupForRelay(ctx context.Context) error {
 	if err := pc.Chain.SetupForRelay(ctx); err != nil {
 		return err
 	}
-	if err := pc.Prover.SetupForRelay(ctx); err != nil {
+	if err := pc.setup.setupProverForRelay(ctx, pc.Prover); err != nil {
 		return err
 	}
 	return nil
 }
 
+// SendMsgs sends the msgs to the chain, invalidating the cached client states of the clients updated by the msgs
+func (pc *ProvableChain) SendMsgs(msgs []sdk.Msg) ([]MsgResult, error) {
+	if err := pc.dropBroadcast(msgs); err != nil {
+		return nil, err
+	}
+	if pc.clientStates != nil {
+		// the msgs may be included even if SendMsgs fails (e.g. on a timeout of the inclusion)
+		defer pc.clientStates.invalidate(msgs)
+	}
+	return pc.Chain.SendMsgs(msgs)
+}
+
+// QueryClientState returns the client state of the path on the chain, which may be cached (see EnableClientStateCache)
+func (pc *ProvableChain) QueryClientState(ctx QueryContext) (*clienttypes.QueryClientStateResponse, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.queryClientState(ctx)
+}
+
 // Chain represents a chain that supports sending transactions and querying the state
 type Chain interface {
 	// GetAddress returns the address of relayer
@@ -76,14 +115,21 @@ type Chain interface {
 	SetupForRelay(ctx context.Context) error
 
 	// SendMsgs sends msgs to the chain and waits for them to be included in blocks.
-	// This function returns err=nil only if all the msgs executed successfully at the blocks.
+	// This function returns err=nil only if all the msgs executed successfully at the blocks,
+	// and then returns the results of the msgs with the events emitted by each msg, aligned with `msgs`.
 	// It should be noted that the block is not finalized at that point and can be reverted afterwards.
-	SendMsgs(msgs []sdk.Msg) ([]MsgID, error)
+	// A chain implementing InclusionWaiter may return just after broadcasting the msgs instead with PendingMsgResult's,
+	// and then the inclusion is confirmed by GetMsgResult.
+	SendMsgs(msgs []sdk.Msg) ([]MsgResult, error)
 
 	// GetMsgResult returns the execution result of `sdk.Msg` specified by `MsgID`
 	// If the msg is not included in any block, this function waits for inclusion.
 	GetMsgResult(id MsgID) (MsgResult, error)
 
+	// GetTxResult returns the execution result of the transaction specified by `txID`.
+	// It returns an error wrapping ErrTxNotFound if the transaction is not included in any block yet, so that it can be polled.
+	GetTxResult(ctx context.Context, txID string) (*TxResult, error)
+
 	// RegisterMsgEventListener registers a given EventListener to the chain
 	RegisterMsgEventListener(MsgEventListener)
 
@@ -144,6 +190,9 @@ type ICS04Querier interface {
 	// QueryChannel returns the channel associated with a channelID
 	QueryChannel(ctx QueryContext) (chanRes *chantypes.QueryChannelResponse, err error)
 
+	// QueryConnectionChannels returns all the channels associated with the connection of the path
+	QueryConnectionChannels(ctx QueryContext) ([]*chantypes.IdentifiedChannel, error)
+
 	// QueryUnreceivedPackets returns a list of unrelayed packet commitments
 	QueryUnreceivedPackets(ctx QueryContext, seqs []uint64) ([]uint64, error)
 
@@ -155,6 +204,11 @@ type ICS04Querier interface {
 
 	// QueryUnfinalizedRelayedAcknowledgements returns acks and heights that are sent but not received at the latest finalized block on the counterpartychain
 	QueryUnfinalizedRelayAcknowledgements(ctx QueryContext, counterparty LightClientICS04Querier) (PacketInfoList, error)
+
+	// QueryPacketEventsInRange returns the packets sent and the acknowledgements written on the channel of the path
+	// in the blocks from `fromHeight` to `toHeight` (both inclusive), so that a range of blocks is scanned at once
+	// instead of querying each height. Chain modules may use any indexed search available to them (e.g. tx_search, eth_getLogs).
+	QueryPacketEventsInRange(ctx context.Context, fromHeight, toHeight ibcexported.Height) (*PacketEvents, error)
 }
 
 // ICS20Querier is an interface to the state of ICS-20
@@ -166,6 +220,30 @@ type ICS20Querier interface {
 	QueryDenomTraces(ctx QueryContext, offset, limit uint64) (*transfertypes.QueryDenomTracesResponse, error)
 }
 
+// FeeDenomsProvider is an optional interface of Chain that tells the denoms in which the relayer pays the tx fees.
+// The fee denoms may be IBC vouchers on chains with a fee abstraction module, where the relayer holds only bridged assets.
+type FeeDenomsProvider interface {
+	// FeeDenoms returns the denoms of the tx fees paid by the relayer
+	FeeDenoms() []string
+}
+
+// TxLimits are the limits of a tx accepted by a chain
+type TxLimits struct {
+	// MaxTxBytes is the maximum total size of the encoded msgs in a tx, excluding the other parts of the tx (e.g. the memo and the signatures)
+	MaxTxBytes uint64
+	// MaxMsgs is the maximum number of msgs in a tx
+	MaxMsgs uint64
+}
+
+// TxLimitsProvider is an optional interface of Chain that tells the limits of a tx, which are queried from the chain or configured.
+// RelayMsgs.Send splits the msgs into txs within the limits of each chain in addition to the limits of the strategy,
+// so that e.g. both the max_tx_bytes of a Cosmos chain and the calldata limit of an Ethereum chain are honored.
+// Zero means no limit.
+type TxLimitsProvider interface {
+	// TxLimits returns the limits of a tx
+	TxLimits() TxLimits
+}
+
 type LightClientICS04Querier interface {
 	LightClient
 	ICS04Querier
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// ChallengeWindowProvider is an optional interface of Chain or Prover for chains whose state can be proven
+// only after a challenge window elapses since it is committed (e.g. optimistic rollups).
+// The packets and acknowledgements written on such a chain are queued until the window elapses.
+type ChallengeWindowProvider interface {
+	// ChallengeWindow returns the time since a block is committed until the proofs of its state become available
+	ChallengeWindow() time.Duration
+}
+
+// challengeWindowOf returns the challenge window of the chain, or 0 if the chain has no challenge window
+func challengeWindowOf(chain *ProvableChain) time.Duration {
+	if p, ok := chain.Chain.(ChallengeWindowProvider); ok {
+		return p.ChallengeWindow()
+	}
+	if p, ok := chain.CurrentProver().(ChallengeWindowProvider); ok {
+		return p.ChallengeWindow()
+	}
+	return 0
+}
+
+// DelayedPacket is a packet or an acknowledgement queued until the challenge window of the chain on which it is written elapses
+type DelayedPacket struct {
+	// ChainID is the ID of the chain on which the packet or the acknowledgement is written
+	ChainID   string `json:"chain_id"`
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+	Sequence  uint64 `json:"sequence"`
+	// Acknowledgement is true if the acknowledgement of the packet is queued
+	Acknowledgement bool      `json:"acknowledgement"`
+	AvailableAt     time.Time `json:"available_at"`
+}
+
+type delayedPacketKey struct {
+	chainID   string
+	portID    string
+	channelID string
+	sequence  uint64
+	ack       bool
+}
+
+// challengeWindowQueue keeps track of the packets and acknowledgements waiting for the challenge windows of a path
+type challengeWindowQueue struct {
+	mu      sync.Mutex
+	delayed map[delayedPacketKey]*DelayedPacket
+}
+
+func newChallengeWindowQueue() *challengeWindowQueue {
+	return &challengeWindowQueue{
+		delayed: make(map[delayedPacketKey]*DelayedPacket),
+	}
+}
+
+// filter returns the packets written on `chain` whose challenge window has elapsed, queueing the others.
+// `end` is the channel end on `chain`, and `ack` is true if `packets` are acknowledgements.
+// Queued packets that are no longer unrelayed are forgotten, and on ordered channels the packets following a queued packet are also left unrelayed.
+func (q *challengeWindowQueue) filter(chain *ProvableChain, end *PathEnd, packets PacketInfoList, ack bool) (PacketInfoList, error) {
+	window := challengeWindowOf(chain)
+	if window <= 0 {
+		return packets, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	chainID := chain.ChainID()
+	unrelayed := make(map[uint64]struct{}, len(packets))
+	for _, p := range packets {
+		unrelayed[p.Sequence] = struct{}{}
+	}
+	for key := range q.delayed {
+		if key.chainID != chainID || key.portID != end.PortID || key.channelID != end.ChannelID || key.ack != ack {
+			continue
+		}
+		if _, ok := unrelayed[key.sequence]; !ok {
+			delete(q.delayed, key)
+		}
+	}
+
+	logger := GetChainLogger(chain)
+	ordered := end.GetOrder() == chantypes.ORDERED
+	now := time.Now()
+	var ret PacketInfoList
+	for _, p := range packets {
+		key := delayedPacketKey{chainID: chainID, portID: end.PortID, channelID: end.ChannelID, sequence: p.Sequence, ack: ack}
+		d, ok := q.delayed[key]
+		if !ok {
+			// the block of a previous revision can't be queried, so the window is counted from now, which is after the event
+			ts := now
+			if !inPreviousRevision(chainID, p.EventHeight) {
+				var err error
+				if ts, err = chain.Timestamp(p.EventHeight); err != nil {
+					return nil, err
+				}
+			}
+			d = &DelayedPacket{
+				ChainID:         chainID,
+				PortID:          end.PortID,
+				ChannelID:       end.ChannelID,
+				Sequence:        p.Sequence,
+				Acknowledgement: ack,
+				AvailableAt:     ts.Add(window),
+			}
+			if now.Before(d.AvailableAt) {
+				logger.Info("queued a packet until the challenge window elapses",
+					"port_id", end.PortID,
+					"channel_id", end.ChannelID,
+					"sequence", p.Sequence,
+					"acknowledgement", ack,
+					"available_at", d.AvailableAt,
+				)
+			}
+			q.delayed[key] = d
+		}
+		if now.Before(d.AvailableAt) {
+			if ordered {
+				return ret, nil
+			}
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// list returns the packets waiting for the challenge windows sorted by the time they become available
+func (q *challengeWindowQueue) list() []*DelayedPacket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	ret := make([]*DelayedPacket, 0, len(q.delayed))
+	for _, d := range q.delayed {
+		if !now.Before(d.AvailableAt) {
+			continue
+		}
+		cp := *d
+		ret = append(ret, &cp)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].AvailableAt.Before(ret[j].AvailableAt) })
+	return ret
+}
+
+// filterChallengeWindows removes the packets and acknowledgements whose challenge windows have not elapsed yet
+func (srv *RelayService) filterChallengeWindows(ch *relayChannel, pseqs, aseqs *RelayPackets) error {
+	var err error
+	if pseqs.Src, err = srv.delayed.filter(srv.src, ch.srcEnd, pseqs.Src, false); err != nil {
+		return err
+	}
+	if pseqs.Dst, err = srv.delayed.filter(srv.dst, ch.dstEnd, pseqs.Dst, false); err != nil {
+		return err
+	}
+	if aseqs.Src, err = srv.delayed.filter(srv.src, ch.srcEnd, aseqs.Src, true); err != nil {
+		return err
+	}
+	if aseqs.Dst, err = srv.delayed.filter(srv.dst, ch.dstEnd, aseqs.Dst, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DelayedPackets returns the packets and acknowledgements waiting for the challenge windows of the chains
+func (srv *RelayService) DelayedPackets() []*DelayedPacket {
+	return srv.delayed.list()
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// ChannelDiscoveryCfg enables the discovery of channels on the connection of a path.
+// The relay service periodically queries all the channels on the connection and begins relaying
+// the open channels matching the filter rules without any changes to the config.
+type ChannelDiscoveryCfg struct {
+	// Interval is the time interval to query the channels (e.g. "1m")
+	Interval string `json:"interval" yaml:"interval"`
+
+	// PortIDs limits the discovered channels to the ones whose src port is contained in it. Empty means any port.
+	PortIDs []string `json:"port-ids,omitempty" yaml:"port-ids,omitempty"`
+
+	// ExcludeChannelIDs excludes the channels whose src channel ID is contained in it
+	ExcludeChannelIDs []string `json:"exclude-channel-ids,omitempty" yaml:"exclude-channel-ids,omitempty"`
+
+	// Order limits the discovered channels to the ones with the order ("ORDERED" or "UNORDERED"). Empty means any order.
+	Order string `json:"order,omitempty" yaml:"order,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *ChannelDiscoveryCfg) Validate() error {
+	if d, err := time.ParseDuration(cfg.Interval); err != nil {
+		return fmt.Errorf("channel-discovery: invalid interval: %w", err)
+	} else if d <= 0 {
+		return fmt.Errorf("channel-discovery: interval must be positive: %v", d)
+	}
+	if cfg.Order != "" && OrderFromString(strings.ToUpper(cfg.Order)) == chantypes.NONE {
+		return fmt.Errorf("channel-discovery: invalid order: %s", cfg.Order)
+	}
+	return nil
+}
+
+// GetInterval returns the interval to query the channels
+func (cfg *ChannelDiscoveryCfg) GetInterval() time.Duration {
+	d, _ := time.ParseDuration(cfg.Interval)
+	return d
+}
+
+// Match returns true if the channel on the src chain matches the filter rules
+func (cfg *ChannelDiscoveryCfg) Match(channel *chantypes.IdentifiedChannel) bool {
+	if len(cfg.PortIDs) > 0 && !slices.Contains(cfg.PortIDs, channel.PortId) {
+		return false
+	}
+	if slices.Contains(cfg.ExcludeChannelIDs, channel.ChannelId) {
+		return false
+	}
+	if cfg.Order != "" && OrderFromString(strings.ToUpper(cfg.Order)) != channel.Ordering {
+		return false
+	}
+	return true
+}
+
+// DiscoverChannels queries the channels on the connection set to `src` and
+// returns the pairs of path ends of the open channels matching the filter rules in `cfg`
+func DiscoverChannels(src, dst *ProvableChain, cfg *ChannelDiscoveryCfg) ([]PathEndPair, error) {
+	height, err := src.LatestHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest height: %v", err)
+	}
+	channels, err := src.QueryConnectionChannels(NewQueryContext(context.TODO(), height))
+	if err != nil {
+		return nil, err
+	}
+
+	srcPath, dstPath := src.Path(), dst.Path()
+	var pairs []PathEndPair
+	for _, ch := range channels {
+		if ch.State != chantypes.OPEN || !cfg.Match(ch) {
+			continue
+		}
+		order := orderToString(ch.Ordering)
+		pairs = append(pairs, PathEndPair{
+			Src: srcPath.withChannel(&ChannelEnd{
+				ChannelID: ch.ChannelId,
+				PortID:    ch.PortId,
+				Order:     order,
+				Version:   ch.Version,
+			}),
+			Dst: dstPath.withChannel(&ChannelEnd{
+				ChannelID: ch.Counterparty.ChannelId,
+				PortID:    ch.Counterparty.PortId,
+				Order:     order,
+				Version:   ch.Version,
+			}),
+		})
+	}
+	return pairs, nil
+}
+
+// orderToString is the inverse of OrderFromString
+func orderToString(order chantypes.Order) string {
+	switch order {
+	case chantypes.UNORDERED:
+		return "UNORDERED"
+	case chantypes.ORDERED:
+		return "ORDERED"
+	default:
+		return ""
+	}
+}
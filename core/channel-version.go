@@ -0,0 +1,155 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	icatypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	feetypes "github.com/cosmos/ibc-go/v7/modules/apps/29-fee/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// ChannelVersion is a channel version parsed into the metadata of the middlewares and the app
+type ChannelVersion struct {
+	// Raw is the version string of the channel
+	Raw string `json:"raw"`
+	// FeeVersion is the version of the ICS-29 fee middleware; it is empty if the fee middleware is not enabled
+	FeeVersion string `json:"fee_version,omitempty"`
+	// AppVersion is the version of the app at the bottom of the middleware stack (e.g. "ics20-1")
+	AppVersion string `json:"app_version"`
+	// ICA is the ICS-27 metadata if the app is interchain accounts
+	ICA *icatypes.Metadata `json:"ica,omitempty"`
+}
+
+// FeeEnabled returns true if the ICS-29 fee middleware is enabled on the channel
+func (v *ChannelVersion) FeeEnabled() bool {
+	return v.FeeVersion != ""
+}
+
+// ParseChannelVersion parses a channel version, unwrapping the ICS-29 fee metadata and decoding the ICS-27 metadata.
+// A version in another JSON format is regarded as the opaque version of the app.
+// It returns an error if the version looks like JSON metadata but is malformed.
+func ParseChannelVersion(version string) (*ChannelVersion, error) {
+	v := &ChannelVersion{Raw: version}
+	appVersion := version
+	if strings.HasPrefix(version, "{") {
+		var fee feetypes.Metadata
+		if err := json.Unmarshal([]byte(version), &fee); err != nil {
+			return nil, fmt.Errorf("malformed channel version metadata: %w", err)
+		}
+		if fee.FeeVersion != "" {
+			if fee.FeeVersion != feetypes.Version {
+				return nil, fmt.Errorf("unsupported fee version: %s", fee.FeeVersion)
+			}
+			if fee.AppVersion == "" {
+				return nil, fmt.Errorf("empty app version in the fee metadata")
+			}
+			v.FeeVersion = fee.FeeVersion
+			appVersion = fee.AppVersion
+		}
+	}
+	if strings.HasPrefix(appVersion, "{") {
+		var ica icatypes.Metadata
+		if err := json.Unmarshal([]byte(appVersion), &ica); err != nil {
+			return nil, fmt.Errorf("malformed app version metadata: %w", err)
+		}
+		if ica.Version == icatypes.Version {
+			if ica.ControllerConnectionId == "" || ica.HostConnectionId == "" {
+				return nil, fmt.Errorf("ICS-27 metadata without the connection identifiers: %s", appVersion)
+			}
+			v.ICA = &ica
+		}
+		if ica.Version != "" {
+			appVersion = ica.Version
+		}
+	}
+	v.AppVersion = appVersion
+	return v, nil
+}
+
+// NormalizeChannelVersion validates a channel version and compacts it if it is JSON metadata (e.g. written across lines for a middleware stack),
+// so that the version proposed in the handshake matches the one the app of the counterparty chain marshals byte by byte
+func NormalizeChannelVersion(version string) (string, error) {
+	version = strings.TrimSpace(version)
+	if strings.HasPrefix(version, "{") {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(version)); err != nil {
+			return "", fmt.Errorf("malformed channel version metadata: %w", err)
+		}
+		version = buf.String()
+	}
+	if _, err := ParseChannelVersion(version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// LogAttrs returns the attributes of the version for logging
+func (v *ChannelVersion) LogAttrs() []interface{} {
+	attrs := []interface{}{"version", v.Raw, "app_version", v.AppVersion, "fee_enabled", v.FeeEnabled()}
+	if v.ICA != nil {
+		attrs = append(attrs, "ica_address", v.ICA.Address, "ica_encoding", v.ICA.Encoding)
+	}
+	return attrs
+}
+
+// validateCounterpartyVersion checks the version proposed by the counterparty channel end before the handshake proceeds.
+// It fails if the version is malformed or the fee middleware is enabled on only one of the path config and the counterparty,
+// in which case the channel would silently be opened without (or be rejected by) the fee middleware.
+func validateCounterpartyVersion(self *PathEnd, counterparty *chantypes.Channel) error {
+	cv, err := ParseChannelVersion(counterparty.Version)
+	if err != nil {
+		return fmt.Errorf("invalid version of the counterparty channel end: %w", err)
+	}
+	// an empty version lets the app of the chain choose the version
+	if self.Version == "" || cv.Raw == "" {
+		return nil
+	}
+	sv, err := ParseChannelVersion(self.Version)
+	if err != nil {
+		return fmt.Errorf("invalid channel version in the path config: %w", err)
+	}
+	if sv.FeeEnabled() != cv.FeeEnabled() {
+		return fmt.Errorf("fee middleware mismatch: the path config version is %s but the counterparty proposes %s", sv.Raw, cv.Raw)
+	}
+	return nil
+}
+
+// syncNegotiatedChannelVersion queries the version negotiated in the channel handshake and saves it to the path config,
+// so that operators can confirm the metadata (e.g. fee-enablement) in the path config and status
+func syncNegotiatedChannelVersion(pathName string, src, dst *ProvableChain) error {
+	logger := GetChannelPairLogger(src, dst)
+	sh, err := src.LatestHeight()
+	if err != nil {
+		return err
+	}
+	dh, err := dst.LatestHeight()
+	if err != nil {
+		return err
+	}
+	srcChan, dstChan, err := QueryChannelPair(NewQueryContext(context.TODO(), sh), NewQueryContext(context.TODO(), dh), src, dst, false)
+	if err != nil {
+		return err
+	}
+	if srcChan.Channel.Version != dstChan.Channel.Version {
+		return fmt.Errorf("the channel versions differ between the ends: %s and %s", srcChan.Channel.Version, dstChan.Channel.Version)
+	}
+	version, err := ParseChannelVersion(srcChan.Channel.Version)
+	if err != nil {
+		return fmt.Errorf("invalid negotiated channel version: %w", err)
+	}
+	logger.Info("negotiated channel version", version.LogAttrs()...)
+	for _, chain := range []*ProvableChain{src, dst} {
+		if chain.Path().Version == version.Raw {
+			continue
+		}
+		chain.Path().Version = version.Raw
+		if err := config.UpdateConfigID(pathName, chain.ChainID(), ConfigIDChannelVersion, version.Raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package core_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestParseChannelVersion(t *testing.T) {
+	const icaMetadata = `{"version":"ics27-1","controller_connection_id":"connection-0","host_connection_id":"connection-1","address":"cosmos1abc","encoding":"proto3","tx_type":"sdk_multi_msg"}`
+	cases := map[string]struct {
+		version    string
+		appVersion string
+		feeEnabled bool
+		ica        bool
+		valid      bool
+	}{
+		"plain":              {"ics20-1", "ics20-1", false, false, true},
+		"empty":              {"", "", false, false, true},
+		"fee":                {`{"fee_version":"ics29-1","app_version":"ics20-1"}`, "ics20-1", true, false, true},
+		"ica":                {icaMetadata, "ics27-1", false, true, true},
+		"fee and ica":        {`{"fee_version":"ics29-1","app_version":` + strconv.Quote(icaMetadata) + `}`, "ics27-1", true, true, true},
+		"opaque json":        {`{"foo":"bar"}`, `{"foo":"bar"}`, false, false, true},
+		"malformed json":     {`{"fee_version":`, "", false, false, false},
+		"unknown fee":        {`{"fee_version":"ics29-2","app_version":"ics20-1"}`, "", false, false, false},
+		"fee without app":    {`{"fee_version":"ics29-1"}`, "", false, false, false},
+		"ica without conns":  {`{"version":"ics27-1"}`, "", false, false, false},
+		"malformed app json": {`{"fee_version":"ics29-1","app_version":"{"}`, "", false, false, false},
+	}
+	for name, c := range cases {
+		v, err := core.ParseChannelVersion(c.version)
+		if !c.valid {
+			if err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if v.AppVersion != c.appVersion || v.FeeEnabled() != c.feeEnabled || (v.ICA != nil) != c.ica || v.Raw != c.version {
+			t.Errorf("%s: unexpected parse result: %+v", name, v)
+		}
+	}
+}
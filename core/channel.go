@@ -11,16 +11,39 @@ import (
 	"golang.org/x/exp/slog"
 )
 
-// CreateChannel runs the channel creation messages on timeout until they pass
-// TODO: add max retries or something to this function
-func CreateChannel(pathName string, src, dst *ProvableChain, to time.Duration) error {
+// CreateChannel runs the channel creation messages, waiting between the steps according to the backoff policy, until they pass.
+// It aborts with the last observed states of the channel ends when `ctx` is done (e.g. the deadline is exceeded).
+func CreateChannel(ctx context.Context, pathName string, src, dst *ProvableChain, policy BackoffPolicy) error {
+	if src.Path().Protocol() == ProtocolV2 {
+		return fmt.Errorf("the path %s is a %s path, which has no channel handshake: register the counterparties instead", pathName, ProtocolV2)
+	}
+	sh, err := NewSyncHeaders(src, dst)
+	if err != nil {
+		return err
+	}
+	return createChannel(ctx, pathName, src, dst, sh, policy)
+}
+
+// createChannel is CreateChannel with SyncHeaders shared with the other stages of a handshake
+func createChannel(ctx context.Context, pathName string, src, dst *ProvableChain, sh SyncHeaders, policy BackoffPolicy) error {
 	logger := GetChannelPairLogger(src, dst)
 	defer logger.TimeTrack(time.Now(), "CreateChannel")
 
-	ticker := time.NewTicker(to)
+	if err := checkPortBindings(ctx, src, dst); err != nil {
+		logger.Error("port binding precheck failed", err)
+		return err
+	}
+
+	backoff := policy.NewBackoff()
 	failures := 0
-	for ; true; <-ticker.C {
-		chanSteps, err := createChannelStep(src, dst)
+	for ; true; sleepContext(ctx, backoff.Next()) {
+		if err := ctx.Err(); err != nil {
+			states := channelStates(src, dst)
+			logger.Error("channel handshake aborted", err, "states", states)
+			return fmt.Errorf("channel handshake aborted: %w: %s", err, states)
+		}
+
+		chanSteps, err := createChannelStep(src, dst, sh)
 		if err != nil {
 			logger.Error(
 				"failed to create channel step",
@@ -36,7 +59,7 @@ func CreateChannel(pathName string, src, dst *ProvableChain, to time.Duration) e
 
 		chanSteps.Send(src, dst)
 		if chanSteps.Success() {
-			if err := SyncChainConfigsFromEvents(pathName, chanSteps.SrcMsgIDs, chanSteps.DstMsgIDs, src, dst); err != nil {
+			if err := SyncChainConfigsFromEvents(pathName, chanSteps.SrcMsgResults, chanSteps.DstMsgResults, src, dst); err != nil {
 				return err
 			}
 		}
@@ -48,25 +71,25 @@ func CreateChannel(pathName string, src, dst *ProvableChain, to time.Duration) e
 			logger.Info(
 				"★ Channel created",
 			)
-			return nil
+			return syncNegotiatedChannelVersion(pathName, src, dst)
 		// In the case of success, reset the failures counter
 		case chanSteps.Success():
 			failures = 0
+			backoff.Reset()
 			continue
 		// In the case of failure, increment the failures counter and exit if this is the 3rd failure
 		case !chanSteps.Success():
 			failures++
 			logger.Info("retrying transaction...")
-			time.Sleep(5 * time.Second)
 			if failures > 2 {
 				logger.Error(
 					"! Channel failed",
 					err,
 				)
-				return fmt.Errorf("! Channel failed: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
+				return withSendErrors(fmt.Errorf("! Channel failed: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
 					src.ChainID(), src.Path().ChannelID, src.Path().PortID,
 					dst.ChainID(), dst.Path().ChannelID, dst.Path().PortID,
-				)
+				), chanSteps)
 			}
 		}
 	}
@@ -74,13 +97,13 @@ func CreateChannel(pathName string, src, dst *ProvableChain, to time.Duration) e
 	return nil
 }
 
-func createChannelStep(src, dst *ProvableChain) (*RelayMsgs, error) {
+func createChannelStep(src, dst *ProvableChain, sh SyncHeaders) (*RelayMsgs, error) {
 	out := NewRelayMsgs()
 	if err := validatePaths(src, dst); err != nil {
 		return nil, err
 	}
 	// First, update the light clients to the latest header and return the header
-	sh, err := NewSyncHeaders(src, dst)
+	err := sh.Updates(src, dst)
 	if err != nil {
 		return nil, err
 	}
@@ -114,72 +137,123 @@ func createChannelStep(src, dst *ProvableChain) (*RelayMsgs, error) {
 		return out, nil
 	}
 
-	switch {
-	// Handshake hasn't been started on src or dst, relay `chanOpenInit` to src
-	case srcChan.Channel.State == chantypes.UNINITIALIZED && dstChan.Channel.State == chantypes.UNINITIALIZED:
-		logChannelStates(src, dst, srcChan, dstChan)
-		addr := mustGetAddress(src)
-		out.Src = append(out.Src,
-			src.Path().ChanInit(dst.Path(), addr),
-		)
-	// Handshake has started on dst (1 step done), relay `chanOpenTry` and `updateClient` to src
-	case srcChan.Channel.State == chantypes.UNINITIALIZED && dstChan.Channel.State == chantypes.INIT:
-		logChannelStates(src, dst, srcChan, dstChan)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ChanTry(dst.Path(), dstChan, addr))
-	// Handshake has started on src (1 step done), relay `chanOpenTry` and `updateClient` to dst
-	case srcChan.Channel.State == chantypes.INIT && dstChan.Channel.State == chantypes.UNINITIALIZED:
-		logChannelStates(dst, src, dstChan, srcChan)
-		addr := mustGetAddress(dst)
-		if len(srcUpdateHeaders) > 0 {
-			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
-		}
-		out.Dst = append(out.Dst, dst.Path().ChanTry(src.Path(), srcChan, addr))
-
-	// Handshake has started on src (2 steps done), relay `chanOpenAck` and `updateClient` to dst
-	case srcChan.Channel.State == chantypes.TRYOPEN && dstChan.Channel.State == chantypes.INIT:
-		logChannelStates(dst, src, dstChan, srcChan)
-		addr := mustGetAddress(dst)
-		if len(srcUpdateHeaders) > 0 {
-			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
-		}
-		out.Dst = append(out.Dst, dst.Path().ChanAck(src.Path(), srcChan, addr))
-
-	// Handshake has started on dst (2 steps done), relay `chanOpenAck` and `updateClient` to src
-	case srcChan.Channel.State == chantypes.INIT && dstChan.Channel.State == chantypes.TRYOPEN:
-		logChannelStates(src, dst, srcChan, dstChan)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ChanAck(dst.Path(), dstChan, addr))
-
-	// Handshake has confirmed on dst (3 steps done), relay `chanOpenConfirm` and `updateClient` to src
-	case srcChan.Channel.State == chantypes.TRYOPEN && dstChan.Channel.State == chantypes.OPEN:
-		logChannelStates(src, dst, srcChan, dstChan)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ChanConfirm(dstChan, addr))
-		out.Last = true
-
-	// Handshake has confirmed on src (3 steps done), relay `chanOpenConfirm` and `updateClient` to dst
-	case srcChan.Channel.State == chantypes.OPEN && dstChan.Channel.State == chantypes.TRYOPEN:
-		logChannelStates(dst, src, dstChan, srcChan)
-		addr := mustGetAddress(dst)
-		if len(srcUpdateHeaders) > 0 {
-			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
-		}
-		out.Dst = append(out.Dst, dst.Path().ChanConfirm(srcChan, addr))
-		out.Last = true
+	logChannelStates(src, dst, srcChan, dstChan)
+	srcAddr, err := src.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	dstAddr, err := dst.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	return ChannelHandshakeStep(
+		&ChannelHandshakeEnd{Path: src.Path(), Signer: srcAddr, Channel: srcChan, UpdateHeaders: srcUpdateHeaders},
+		&ChannelHandshakeEnd{Path: dst.Path(), Signer: dstAddr, Channel: dstChan, UpdateHeaders: dstUpdateHeaders},
+	)
+}
+
+// ResumeChannelHandshake detects a channel handshake on the connection of the path that was initiated by another tool (e.g. a chain's own tx),
+// and sets the identifiers of the channel ends to the path config so that CreateChannel continues the handshake from its current state.
+// Only the channel ends whose port, order and version match the path config are adopted.
+// It is opt-in since a channel end with no counterparty channel may have been initiated for another relayer.
+func ResumeChannelHandshake(pathName string, src, dst *ProvableChain) error {
+	if err := detectChannel(pathName, src, dst); err != nil {
+		return err
+	}
+	if err := detectChannel(pathName, dst, src); err != nil {
+		return err
+	}
+	return nil
+}
+
+// detectChannel finds the channel end on `self` in INIT or TRYOPEN state that corresponds to the path if its identifier is not set yet.
+// The order and the version of the channel end must match the path config if they are set.
+func detectChannel(pathName string, self, counterparty *ProvableChain) error {
+	if self.Path().ChannelID != "" {
+		return nil
+	}
+	logger := GetChannelPairLogger(self, counterparty)
+
+	selfH, err := self.LatestHeight()
+	if err != nil {
+		return err
+	}
+	selfCtx := NewQueryContext(context.TODO(), selfH)
+
+	cpPath := counterparty.Path()
+
+	// the counterparty channel end may already know the identifier of the channel end on `self`
+	var knownID string
+	if cpPath.ChannelID != "" {
+		cpH, err := counterparty.LatestHeight()
+		if err != nil {
+			return err
+		}
+		cpChan, err := counterparty.QueryChannel(NewQueryContext(context.TODO(), cpH))
+		if err != nil {
+			return err
+		}
+		if cpChan.Channel.State != chantypes.UNINITIALIZED {
+			knownID = cpChan.Channel.Counterparty.ChannelId
+		}
+	}
+
+	channels, err := self.QueryConnectionChannels(selfCtx)
+	if err != nil {
+		return err
+	}
+	var candidates []*chantypes.IdentifiedChannel
+	for _, ch := range channels {
+		if ch.PortId != self.Path().PortID || ch.Counterparty.PortId != cpPath.PortID {
+			continue
+		}
+		if ch.State != chantypes.INIT && ch.State != chantypes.TRYOPEN {
+			continue
+		}
+		if self.Path().Order != "" && ch.Ordering != self.Path().GetOrder() {
+			continue
+		}
+		if self.Path().Version != "" && ch.Version != self.Path().Version {
+			continue
+		}
+		switch {
+		case knownID != "":
+			if ch.ChannelId != knownID {
+				continue
+			}
+		case cpPath.ChannelID != "":
+			if ch.Counterparty.ChannelId != cpPath.ChannelID {
+				continue
+			}
+		default:
+			if ch.Counterparty.ChannelId != "" {
+				continue
+			}
+		}
+		candidates = append(candidates, ch)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
 	default:
-		panic(fmt.Sprintf("not implemeneted error: %v <=> %v", srcChan.Channel.State.String(), dstChan.Channel.State.String()))
+		var ids []string
+		for _, ch := range candidates {
+			ids = append(ids, ch.ChannelId)
+		}
+		return fmt.Errorf("multiple channels in handshake found on %s: %v; set one of them to the path config explicitly", self.ChainID(), ids)
 	}
-	return out, nil
+
+	ch := candidates[0]
+	logger.Info(
+		"found a channel handshake in progress",
+		"chain_id", self.ChainID(),
+		"channel_id", ch.ChannelId,
+		"state", ch.State.String(),
+	)
+	self.Path().ChannelID = ch.ChannelId
+	return config.UpdateConfigID(pathName, self.ChainID(), ConfigIDChannel, ch.ChannelId)
 }
 
 func logChannelStates(src, dst *ProvableChain, srcChan, dstChan *chantypes.QueryChannelResponse) {
@@ -237,3 +311,22 @@ func GetChannelPairLogger(src, dst Chain) *log.RelayLogger {
 		).
 		WithModule("core.channel")
 }
+
+// channelStates returns the latest states of the channel ends for diagnostics
+func channelStates(src, dst *ProvableChain) string {
+	stateOf := func(chain *ProvableChain) string {
+		if chain.Path().ChannelID == "" {
+			return fmt.Sprintf("[%s]port{%s}: no channel", chain.ChainID(), chain.Path().PortID)
+		}
+		height, err := chain.LatestHeight()
+		if err != nil {
+			return fmt.Sprintf("[%s]chan{%s}: failed to get the latest height: %v", chain.ChainID(), chain.Path().ChannelID, err)
+		}
+		res, err := chain.QueryChannel(NewQueryContext(context.TODO(), height))
+		if err != nil {
+			return fmt.Sprintf("[%s]chan{%s}: failed to query the channel: %v", chain.ChainID(), chain.Path().ChannelID, err)
+		}
+		return fmt.Sprintf("[%s]chan{%s}: %s", chain.ChainID(), chain.Path().ChannelID, res.Channel.State)
+	}
+	return fmt.Sprintf("%s, %s", stateOf(src), stateOf(dst))
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+)
+
+// ClientRecovery describes a client that can't be updated any more and the steps to recover it
+type ClientRecovery struct {
+	// ChainID is the chain hosting the client
+	ChainID  string `json:"chain_id"`
+	ClientID string `json:"client_id"`
+	// Status is the status of the client (Expired or Frozen)
+	Status string   `json:"status"`
+	Steps  []string `json:"steps"`
+}
+
+// IsInactiveClientError returns true if the error may be caused by an expired or frozen client.
+// The error may be a string returned by a chain, so it is also matched by the messages.
+func IsInactiveClientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, clienttypes.ErrClientNotActive) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, clienttypes.ErrClientNotActive.Error()) ||
+		// the light client fails to verify a header with an expired trusted header
+		strings.Contains(msg, "header has expired")
+}
+
+// QueryClientStatus returns the status of the client of the path on the chain, which is Active, Expired or Frozen.
+// The expiry is known only for tendermint clients, and the other clients are regarded as active unless frozen.
+func QueryClientStatus(ctx QueryContext, chain *ProvableChain) (ibcexported.Status, error) {
+	res, err := chain.QueryClientState(ctx)
+	if err != nil {
+		return "", err
+	}
+	var clientState ibcexported.ClientState
+	if err := chain.Codec().UnpackAny(res.ClientState, &clientState); err != nil {
+		return "", err
+	}
+	if tmcs, ok := clientState.(*tmclient.ClientState); ok && !tmcs.FrozenHeight.IsZero() {
+		return ibcexported.Frozen, nil
+	}
+	var cs ClientSnapshot
+	if err := cs.query(ctx, chain); err != nil {
+		return "", err
+	}
+	if cs.ExpiresAt != nil && !cs.ExpiresAt.After(time.Now()) {
+		return ibcexported.Expired, nil
+	}
+	return ibcexported.Active, nil
+}
+
+// NewClientRecovery returns the recovery of the client of the path on `chain` tracking `counterparty`.
+// An inactive client can only be recovered by a governance proposal substituting it with an active client.
+func NewClientRecovery(pathName string, chain, counterparty *ProvableChain, status ibcexported.Status) *ClientRecovery {
+	if pathName == "" {
+		pathName = "[path-name]"
+	}
+	clientID := chain.Path().ClientID
+	return &ClientRecovery{
+		ChainID:  chain.ChainID(),
+		ClientID: clientID,
+		Status:   string(status),
+		Steps: []string{
+			fmt.Sprintf("create a substitute client of %s on %s with the same client parameters as %s and keep it updated", counterparty.ChainID(), chain.ChainID(), clientID),
+			fmt.Sprintf("submit a governance proposal on %s to substitute %s with the new client and track it until it passes with `yrly tx propose-client-substitute %s %s [substitute-client-id]`", chain.ChainID(), clientID, pathName, chain.ChainID()),
+			fmt.Sprintf("after the proposal passes, resume relaying with `yrly paths resume %s`", pathName),
+		},
+	}
+}
+
+// recoverInactiveClients checks the clients of the path if `err` may be caused by an inactive client,
+// and pauses the path with the recovery steps if a client is expired or frozen, so that no more gas is spent on futile retries.
+// It returns true if the path is paused.
+func (srv *RelayService) recoverInactiveClients(cause error) bool {
+	if !IsInactiveClientError(cause) {
+		return false
+	}
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	var recoveries []*ClientRecovery
+	for _, c := range []struct{ chain, counterparty *ProvableChain }{{srv.src, srv.dst}, {srv.dst, srv.src}} {
+		height, err := c.chain.LatestHeight()
+		if err != nil {
+			logger.Error("failed to get the latest height to check the client status", err, "chain_id", c.chain.ChainID())
+			continue
+		}
+		status, err := QueryClientStatus(NewQueryContext(context.TODO(), height), c.chain)
+		if err != nil {
+			logger.Error("failed to query the client status", err, "chain_id", c.chain.ChainID())
+			continue
+		}
+		if status == ibcexported.Active {
+			continue
+		}
+		recovery := NewClientRecovery(srv.pathName, c.chain, c.counterparty, status)
+		logger.Error("the client can't be updated any more and requires recovery", cause,
+			"chain_id", recovery.ChainID,
+			"client_id", recovery.ClientID,
+			"status", recovery.Status,
+			"steps", recovery.Steps,
+		)
+		srv.events.Publish(RelayEvent{
+			Type:     RelayEventClientRecoveryRequired,
+			ChainID:  recovery.ChainID,
+			ClientID: recovery.ClientID,
+			Error:    fmt.Sprintf("the client is %s", recovery.Status),
+			Recovery: recovery,
+		})
+		recoveries = append(recoveries, recovery)
+	}
+	if len(recoveries) == 0 {
+		return false
+	}
+	if srv.pauseFile == "" {
+		logger.Warn("the path can't be paused for the recovery because the pause state file is not set")
+		return false
+	}
+	reason := fmt.Sprintf("the client %s on %s is %s and requires recovery", recoveries[0].ClientID, recoveries[0].ChainID, recoveries[0].Status)
+	state := &PauseState{Paused: true, Reason: reason, Since: time.Now(), Recovery: recoveries}
+	if err := SavePauseState(srv.pauseFile, state); err != nil {
+		logger.Error("failed to pause the path for the recovery", err, "file", srv.pauseFile)
+		return false
+	}
+	return true
+}
+
+// ClientSubstituteProposal is a governance proposal to substitute an inactive client with an active client
+type ClientSubstituteProposal struct {
+	Title              string
+	Summary            string
+	SubjectClientID    string
+	SubstituteClientID string
+	// Deposit is the initial deposit of the proposal; the minimum deposit of the chain is used if empty
+	Deposit sdk.Coins
+}
+
+// ProposalStatus is the status of a governance proposal
+type ProposalStatus struct {
+	ProposalID uint64 `json:"proposal_id"`
+	// Status is the status of the proposal (e.g. PROPOSAL_STATUS_VOTING_PERIOD)
+	Status       string     `json:"status"`
+	TotalDeposit sdk.Coins  `json:"total_deposit"`
+	MinDeposit   sdk.Coins  `json:"min_deposit"`
+	DepositEnd   *time.Time `json:"deposit_end,omitempty"`
+	VotingEnd    *time.Time `json:"voting_end,omitempty"`
+}
+
+const (
+	ProposalStatusDepositPeriod = "PROPOSAL_STATUS_DEPOSIT_PERIOD"
+	ProposalStatusVotingPeriod  = "PROPOSAL_STATUS_VOTING_PERIOD"
+	ProposalStatusPassed        = "PROPOSAL_STATUS_PASSED"
+	ProposalStatusRejected      = "PROPOSAL_STATUS_REJECTED"
+	// ProposalStatusFailed means that the proposal passed but its execution failed
+	ProposalStatusFailed = "PROPOSAL_STATUS_FAILED"
+)
+
+// Final returns true if the proposal is executed or will never be executed
+func (s *ProposalStatus) Final() bool {
+	switch s.Status {
+	case ProposalStatusPassed, ProposalStatusRejected, ProposalStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClientSubstituteProposer is an optional interface of Chain of which clients are substituted by governance proposals
+type ClientSubstituteProposer interface {
+	// SubmitClientSubstituteProposal submits the proposal with the relayer account as the proposer and returns the proposal ID
+	SubmitClientSubstituteProposal(ctx context.Context, proposal ClientSubstituteProposal) (uint64, error)
+	// DepositToProposal adds a deposit to the proposal from the relayer account
+	DepositToProposal(ctx context.Context, proposalID uint64, amount sdk.Coins) error
+	// QueryProposalStatus returns the status of the proposal
+	QueryProposalStatus(ctx context.Context, proposalID uint64) (*ProposalStatus, error)
+}
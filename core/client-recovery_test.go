@@ -0,0 +1,72 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestIsInactiveClientError(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		inactive bool
+	}{
+		"wrapped": {errorsmod.Wrapf(clienttypes.ErrClientNotActive, "cannot update client (%s) with status %s", "07-tendermint-0", "Expired"), true},
+		"string from the chain": {
+			fmt.Errorf("CheckTx failed: %s", "failed to execute message; message index: 0: cannot update client (07-tendermint-0) with status Frozen: client state is not active"),
+			true,
+		},
+		"light client": {errors.New("failed to verify the header: old header has expired at 2024-01-01"), true},
+		"other":        {errors.New("insufficient fees"), false},
+		"nil":          {nil, false},
+	}
+	for name, c := range cases {
+		if core.IsInactiveClientError(c.err) != c.inactive {
+			t.Errorf("%s: unexpected result for %v", name, c.err)
+		}
+	}
+}
+
+func TestDiagnoseClientRecovery(t *testing.T) {
+	steps := []string{"create a substitute client", "submit a governance proposal", "yrly paths resume ibc01"}
+	pause := &core.PauseState{
+		Paused: true,
+		Reason: "the client 07-tendermint-0 on ibc0 is Expired and requires recovery",
+		Since:  time.Now(),
+		Recovery: []*core.ClientRecovery{
+			{ChainID: "ibc0", ClientID: "07-tendermint-0", Status: "Expired", Steps: steps},
+			{ChainID: "ibc1", ClientID: "07-tendermint-1", Status: "Expired", Steps: steps},
+		},
+	}
+	findings := core.Diagnose("ibc01", nil, nil, pause, core.DefaultDiagnoseOptions(), time.Now())
+	if len(findings) != 1 {
+		t.Fatalf("unexpected findings: %v", findings)
+	}
+	f := findings[0]
+	if f.Severity != core.DiagnosisError || len(f.Causes) != 2 {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	// the steps shared by the clients are suggested once
+	if len(f.Suggestions) != len(steps) {
+		t.Errorf("unexpected suggestions: %v", f.Suggestions)
+	}
+}
+
+func TestProposalStatusFinal(t *testing.T) {
+	for status, final := range map[string]bool{
+		core.ProposalStatusDepositPeriod: false,
+		core.ProposalStatusVotingPeriod:  false,
+		core.ProposalStatusPassed:        true,
+		core.ProposalStatusRejected:      true,
+		core.ProposalStatusFailed:        true,
+	} {
+		if (&core.ProposalStatus{Status: status}).Final() != final {
+			t.Errorf("unexpected result for %s", status)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+)
+
+// clientStateCache caches the client states queried from a chain, which are queried several times in every relay cycle
+// (e.g. to check if the client needs to be refreshed and to set up the headers to update it) but change only when the client is updated.
+// An entry is invalidated when the relayer submits a msg updating the client, and it expires after the max age
+// so that the updates by other relayers are observed as well.
+type clientStateCache struct {
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*clientStateEntry // clientID => entry
+	hits    uint64
+	misses  uint64
+}
+
+type clientStateEntry struct {
+	res       *clienttypes.QueryClientStateResponse
+	height    clienttypes.Height
+	fetchedAt time.Time
+}
+
+// ClientStateCacheStats is the statistics of the client state cache of a chain
+type ClientStateCacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type noClientStateCacheKey struct{}
+
+// WithoutClientStateCache returns a query context whose client state queries bypass the client state cache,
+// which is needed when the client state is proven at the height of the context (e.g. in the connection handshake)
+func WithoutClientStateCache(ctx QueryContext) QueryContext {
+	return NewQueryContext(context.WithValue(ctx.Context(), noClientStateCacheKey{}, true), ctx.Height())
+}
+
+// EnableClientStateCache makes the client state queries through the ProvableChain return the cached client state
+// if it was queried within `maxAge` at a height equal to or lower than the height of the query.
+// The cache is disabled if `maxAge` is 0.
+func (pc *ProvableChain) EnableClientStateCache(maxAge time.Duration) {
+	if maxAge <= 0 {
+		pc.clientStates = nil
+		return
+	}
+	pc.clientStates = &clientStateCache{
+		maxAge:  maxAge,
+		entries: make(map[string]*clientStateEntry),
+	}
+}
+
+// ClientStateCacheStats returns the statistics of the client state cache, or false if the cache is disabled
+func (pc *ProvableChain) ClientStateCacheStats() (ClientStateCacheStats, bool) {
+	c := pc.clientStates
+	if c == nil {
+		return ClientStateCacheStats{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ClientStateCacheStats{Hits: c.hits, Misses: c.misses}, true
+}
+
+func (c *clientStateCache) get(clientID string, height clienttypes.Height) *clienttypes.QueryClientStateResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[clientID]
+	if !ok || time.Since(e.fetchedAt) > c.maxAge || height.LT(e.height) {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	// the callers may set the proof of the response
+	res := *e.res
+	return &res
+}
+
+func (c *clientStateCache) put(clientID string, height clienttypes.Height, res *clienttypes.QueryClientStateResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[clientID]; ok && height.LT(e.height) {
+		return
+	}
+	cached := *res
+	c.entries[clientID] = &clientStateEntry{res: &cached, height: height, fetchedAt: time.Now()}
+}
+
+// invalidate removes the entries of the clients updated by `msgs`
+func (c *clientStateCache) invalidate(msgs []sdk.Msg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case *clienttypes.MsgUpdateClient:
+			delete(c.entries, msg.ClientId)
+		case *clienttypes.MsgUpgradeClient:
+			delete(c.entries, msg.ClientId)
+		case *clienttypes.MsgSubmitMisbehaviour:
+			delete(c.entries, msg.ClientId)
+		}
+	}
+}
+
+// queryClientState queries the client state through the client state cache if it is enabled
+func (pc *ProvableChain) queryClientState(ctx QueryContext) (*clienttypes.QueryClientStateResponse, error) {
+	c := pc.clientStates
+	if c == nil || ctx.Context().Value(noClientStateCacheKey{}) != nil || pc.Path() == nil {
+		return pc.Chain.QueryClientState(ctx)
+	}
+	clientID := pc.Path().ClientID
+	height := clienttypes.NewHeight(ctx.Height().GetRevisionNumber(), ctx.Height().GetRevisionHeight())
+	if res := c.get(clientID, height); res != nil {
+		return res, nil
+	}
+	res, err := pc.Chain.QueryClientState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.put(clientID, height, res)
+	return res, nil
+}
@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// countingClientChain counts the client state queries
+type countingClientChain struct {
+	core.Chain
+	path  *core.PathEnd
+	calls int
+}
+
+func (c *countingClientChain) Path() *core.PathEnd {
+	return c.path
+}
+
+func (c *countingClientChain) QueryClientState(ctx core.QueryContext) (*clienttypes.QueryClientStateResponse, error) {
+	c.calls++
+	return clienttypes.NewQueryClientStateResponse(nil, nil, ctx.Height().(clienttypes.Height)), nil
+}
+
+func (c *countingClientChain) SendMsgs(msgs []sdk.Msg) ([]core.MsgResult, error) {
+	return nil, nil
+}
+
+func TestClientStateCache(t *testing.T) {
+	var chain *countingClientChain
+	_, pc := newMockChain(t, "ibc0", func(mockChain *mock.Chain, prover *mockprover.Prover) (core.Chain, core.Prover) {
+		chain = &countingClientChain{Chain: mockChain, path: &core.PathEnd{ChainID: "ibc0", ClientID: "mock-client-0"}}
+		return chain, prover
+	})
+	pc.EnableClientStateCache(time.Minute)
+
+	query := func(ctx core.QueryContext, expectedCalls int) {
+		t.Helper()
+		res, err := pc.QueryClientState(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the proofs set by the callers must not be cached
+		res.Proof = []byte("proof")
+		if chain.calls != expectedCalls {
+			t.Errorf("unexpected client state queries at %v: actual=%d, expected=%d", ctx.Height(), chain.calls, expectedCalls)
+		}
+	}
+	at := func(height uint64) core.QueryContext {
+		return core.NewQueryContext(context.TODO(), clienttypes.NewHeight(0, height))
+	}
+
+	query(at(10), 1)
+	query(at(11), 1)
+	if res, _ := pc.QueryClientState(at(12)); res.Proof != nil {
+		t.Error("the proof set by a caller is cached")
+	}
+	// the cached state may be newer than the state at a lower height
+	query(at(9), 2)
+	query(core.WithoutClientStateCache(at(11)), 3)
+
+	// an update of another client doesn't invalidate the cache
+	if _, err := pc.SendMsgs([]sdk.Msg{&clienttypes.MsgUpdateClient{ClientId: "mock-client-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	query(at(11), 3)
+	if _, err := pc.SendMsgs([]sdk.Msg{&clienttypes.MsgUpdateClient{ClientId: "mock-client-0"}}); err != nil {
+		t.Fatal(err)
+	}
+	query(at(11), 4)
+
+	if stats, ok := pc.ClientStateCacheStats(); !ok || stats.Hits != 3 || stats.Misses != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// the cached state expires after the max age
+	pc.EnableClientStateCache(time.Millisecond)
+	query(at(11), 5)
+	time.Sleep(5 * time.Millisecond)
+	query(at(11), 6)
+
+	pc.EnableClientStateCache(0)
+	query(at(11), 7)
+	query(at(11), 8)
+}
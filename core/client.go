@@ -1,22 +1,20 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	"github.com/cosmos/ibc-go/v7/modules/core/exported"
 	"github.com/hyperledger-labs/yui-relayer/log"
 )
 
-func CreateClients(pathName string, src, dst *ProvableChain, srcHeight, dstHeight exported.Height) error {
+// CreateClients creates the light clients on both chains.
+// It aborts without submitting the msgs if `ctx` is done (e.g. the deadline is exceeded) before they are ready.
+func CreateClients(ctx context.Context, pathName string, src, dst *ProvableChain, srcHeight, dstHeight exported.Height) error {
 	logger := GetChainPairLogger(src, dst)
 	defer logger.TimeTrack(time.Now(), "CreateClients")
-	var (
-		clients = &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
-	)
-
 	srcAddr, err := src.GetAddress()
 	if err != nil {
 		logger.Error(
@@ -34,44 +32,42 @@ func CreateClients(pathName string, src, dst *ProvableChain, srcHeight, dstHeigh
 		return err
 	}
 
-	{
-		cs, cons, err := dst.CreateInitialLightClientState(dstHeight)
-		if err != nil {
-			logger.Error("failed to create initial light client state", err)
-			return err
-		}
-		msg, err := clienttypes.NewMsgCreateClient(cs, cons, srcAddr.String())
-		if err != nil {
-			return fmt.Errorf("failed to create MsgCreateClient: %v", err)
-		}
-		clients.Src = append(clients.Src, msg)
+	srcCS, srcCons, err := src.CreateInitialLightClientState(srcHeight)
+	if err != nil {
+		logger.Error("failed to create initial light client state", err)
+		return err
+	}
+	dstCS, dstCons, err := dst.CreateInitialLightClientState(dstHeight)
+	if err != nil {
+		logger.Error("failed to create initial light client state", err)
+		return err
+	}
+	clients, err := ClientCreationStep(
+		&ClientCreationEnd{ChainID: src.ChainID(), Signer: srcAddr, ClientState: srcCS, ConsensusState: srcCons},
+		&ClientCreationEnd{ChainID: dst.ChainID(), Signer: dstAddr, ClientState: dstCS, ConsensusState: dstCons},
+	)
+	if err != nil {
+		logger.Error("failed to create MsgCreateClient", err)
+		return err
 	}
 
-	{
-		cs, cons, err := src.CreateInitialLightClientState(srcHeight)
-		if err != nil {
-			logger.Error("failed to create initial light client state", err)
-			return err
-		}
-		msg, err := clienttypes.NewMsgCreateClient(cs, cons, dstAddr.String())
-		if err != nil {
-			logger.Error("failed to create MsgCreateClient: %v", err)
-			return err
-		}
-		clients.Dst = append(clients.Dst, msg)
+	if err := ctx.Err(); err != nil {
+		logger.Error("client creation aborted", err)
+		return fmt.Errorf("client creation aborted: %w: no clients created on %s and %s", err, src.ChainID(), dst.ChainID())
 	}
 
 	// Send msgs to both chains
 	if clients.Ready() {
 		// TODO: Add retry here for out of gas or other errors
 		clients.Send(src, dst)
-		if clients.Success() {
-			logger.Info(
-				"★ Clients created",
-			)
-			if err := SyncChainConfigsFromEvents(pathName, clients.SrcMsgIDs, clients.DstMsgIDs, src, dst); err != nil {
-				return err
-			}
+		if !clients.Success() {
+			return withSendErrors(fmt.Errorf("failed to create the clients on %s and %s", src.ChainID(), dst.ChainID()), clients)
+		}
+		logger.Info(
+			"★ Clients created",
+		)
+		if err := SyncChainConfigsFromEvents(pathName, clients.SrcMsgResults, clients.DstMsgResults, src, dst); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -108,11 +104,12 @@ func UpdateClients(src, dst *ProvableChain) error {
 	}
 	// Send msgs to both chains
 	if clients.Ready() {
-		if clients.Send(src, dst); clients.Success() {
-			logger.Info(
-				"★ Clients updated",
-			)
+		if clients.Send(src, dst); !clients.Success() {
+			return withSendErrors(fmt.Errorf("failed to update the clients on %s and %s", src.ChainID(), dst.ChainID()), clients)
 		}
+		logger.Info(
+			"★ Clients updated",
+		)
 	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/auth/vesting"
+	authzmodule "github.com/cosmos/cosmos-sdk/x/authz/module"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/capability"
 	"github.com/cosmos/cosmos-sdk/x/crisis"
@@ -22,6 +23,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/upgrade"
 	upgradeclient "github.com/cosmos/cosmos-sdk/x/upgrade/client"
+	ibcfee "github.com/cosmos/ibc-go/v7/modules/apps/29-fee"
 	transfer "github.com/cosmos/ibc-go/v7/modules/apps/transfer"
 	ibc "github.com/cosmos/ibc-go/v7/modules/core"
 	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
@@ -29,6 +31,7 @@ import (
 
 var moduleBasics = module.NewBasicManager(
 	auth.AppModuleBasic{},
+	authzmodule.AppModuleBasic{},
 	genutil.AppModuleBasic{},
 	bank.AppModuleBasic{},
 	capability.AppModuleBasic{},
@@ -50,13 +53,56 @@ var moduleBasics = module.NewBasicManager(
 	upgrade.AppModuleBasic{},
 	evidence.AppModuleBasic{},
 	transfer.AppModuleBasic{},
+	ibcfee.AppModuleBasic{},
 	vesting.AppModuleBasic{},
 )
 
-func MakeCodec() codec.ProtoCodecMarshaler {
+// InterfaceRegistrar registers additional types (e.g. the msgs of an app or the states of a custom light client) into the interface registry
+type InterfaceRegistrar func(registry types.InterfaceRegistry)
+
+// InterfaceRegisterer is an optional interface of Chain and Prover to register the types specific to the chain,
+// which are registered when the chain is loaded from the config
+type InterfaceRegisterer interface {
+	RegisterInterfaces(registry types.InterfaceRegistry)
+}
+
+// the names of the interfaces of the chain and prover configs, which list the implementations registered by the modules (e.g. for the config schema)
+const (
+	ChainConfigInterfaceName  = "relayer.core.ChainConfig"
+	ProverConfigInterfaceName = "relayer.core.ProverConfig"
+)
+
+var interfaceRegistrars []InterfaceRegistrar
+
+// RegisterInterfaceRegistrar adds a registrar applied to every codec made by MakeCodec.
+// It is intended to be called by downstream users in init functions before the codec is made.
+func RegisterInterfaceRegistrar(registrar InterfaceRegistrar) {
+	interfaceRegistrars = append(interfaceRegistrars, registrar)
+}
+
+// MakeCodec returns a codec with the types of the SDK and IBC modules, the types added by RegisterInterfaceRegistrar and the types of `registrars`
+func MakeCodec(registrars ...InterfaceRegistrar) codec.ProtoCodecMarshaler {
 	interfaceRegistry := types.NewInterfaceRegistry()
 	marshaler := codec.NewProtoCodec(interfaceRegistry)
 	std.RegisterInterfaces(interfaceRegistry)
 	moduleBasics.RegisterInterfaces(interfaceRegistry)
+	interfaceRegistry.RegisterInterface(ChainConfigInterfaceName, (*ChainConfig)(nil))
+	interfaceRegistry.RegisterInterface(ProverConfigInterfaceName, (*ProverConfig)(nil))
+	for _, r := range interfaceRegistrars {
+		r(interfaceRegistry)
+	}
+	for _, r := range registrars {
+		r(interfaceRegistry)
+	}
 	return marshaler
 }
+
+// RegisterChainInterfaces registers the types specific to the chain if its Chain or Prover implements InterfaceRegisterer
+func RegisterChainInterfaces(registry types.InterfaceRegistry, chain *ProvableChain) {
+	if r, ok := chain.Chain.(InterfaceRegisterer); ok {
+		r.RegisterInterfaces(registry)
+	}
+	if r, ok := chain.CurrentProver().(InterfaceRegisterer); ok {
+		r.RegisterInterfaces(registry)
+	}
+}
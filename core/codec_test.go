@@ -0,0 +1,35 @@
+package core_test
+
+import (
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	mocktypes "github.com/datachainlab/ibc-mock-client/modules/light-clients/xx-mock/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestMakeCodecWithRegistrars(t *testing.T) {
+	cached, err := codectypes.NewAnyWithValue(&mocktypes.ClientState{LatestHeight: clienttypes.NewHeight(0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an Any without the cached value to resolve the type with the registry
+	any := &codectypes.Any{TypeUrl: cached.TypeUrl, Value: cached.Value}
+
+	var cs exported.ClientState
+	if err := core.MakeCodec().UnpackAny(any, &cs); err == nil {
+		t.Fatalf("%s must not be registered by default", any.TypeUrl)
+	}
+
+	m := core.MakeCodec(func(registry codectypes.InterfaceRegistry) {
+		registry.RegisterImplementations((*exported.ClientState)(nil), &mocktypes.ClientState{})
+	})
+	if err := m.UnpackAny(any, &cs); err != nil {
+		t.Fatalf("failed to unpack the registered type: %v", err)
+	}
+	if cs.GetLatestHeight().GetRevisionHeight() != 1 {
+		t.Errorf("unexpected client state: %v", cs)
+	}
+}
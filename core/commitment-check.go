@@ -0,0 +1,302 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	ics23 "github.com/cosmos/ics23/go"
+)
+
+// ErrCommitmentUnverifiable is returned by VerifyCommitmentProof if the proof can't be verified locally,
+// e.g. the light client doesn't verify ICS-23 proofs or the consensus state at the proof height is unknown
+var ErrCommitmentUnverifiable = errors.New("the commitment proof can't be verified locally")
+
+// CommitmentCheckCfg makes the relay service verify the proofs of the packet commitments and the acknowledgements locally
+// against the consensus state roots of the counterparty clients (by ICS-23) before submitting them.
+// The msgs whose proofs don't verify are not submitted and an alert is raised, which catches a misconfiguration
+// of the prover or the proof specs of the client before wasting gas on the txs failing on chain.
+type CommitmentCheckCfg struct {
+	// Interval is the minimum interval between the relay cycles verifying the proofs (e.g. "10m"). The proofs are verified in every relay cycle if empty.
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// WebhookCfg is the webhook receiving the CommitmentMismatchAlert when a proof doesn't verify
+	WebhookCfg `yaml:",inline"`
+}
+
+// Validate validates the config
+func (cfg *CommitmentCheckCfg) Validate() error {
+	if err := cfg.WebhookCfg.Validate(); err != nil {
+		return fmt.Errorf("commitment-check: %w", err)
+	}
+	if cfg.Interval != "" {
+		if d, err := time.ParseDuration(cfg.Interval); err != nil {
+			return fmt.Errorf("commitment-check: invalid interval: %w", err)
+		} else if d <= 0 {
+			return fmt.Errorf("commitment-check: interval must be positive: %v", d)
+		}
+	}
+	return nil
+}
+
+// CommitmentMismatchAlert is the payload of the webhook alert
+type CommitmentMismatchAlert struct {
+	Path string `json:"path"`
+	// ChainID is the chain to which the msg was about to be submitted
+	ChainID       string `json:"chain_id"`
+	MsgType       string `json:"msg_type"`
+	SourcePort    string `json:"source_port"`
+	SourceChannel string `json:"source_channel"`
+	Sequence      uint64 `json:"sequence"`
+	// ProofHeight is the height of the counterparty chain at which the proof was made
+	ProofHeight string    `json:"proof_height"`
+	Error       string    `json:"error"`
+	Time        time.Time `json:"time"`
+}
+
+// VerifyCommitmentProof verifies `proof` of the membership of `value` at `path` under `prefix` against the root of `consensusState`
+// with the proof specs of `clientState`. The proof is a MerkleProof encoded by `cdc` as the ICS-23 based provers give.
+// It returns ErrCommitmentUnverifiable if the client doesn't verify ICS-23 proofs against a commitment root.
+func VerifyCommitmentProof(cdc codec.BinaryCodec, clientState exported.ClientState, consensusState exported.ConsensusState, prefix exported.Prefix, path string, value, proof []byte) error {
+	specs, ok := ClientProofSpecs(clientState)
+	if !ok {
+		return fmt.Errorf("%w: the client %s doesn't use the ICS-23 proof specs", ErrCommitmentUnverifiable, clientState.ClientType())
+	}
+	root, err := commitmentRoot(consensusState)
+	if err != nil {
+		return err
+	}
+	return VerifyMembership(cdc, specs, root, prefix, path, value, proof)
+}
+
+// commitmentRoot returns the commitment root of the consensus state, or ErrCommitmentUnverifiable if it doesn't have one
+func commitmentRoot(consensusState exported.ConsensusState) (exported.Root, error) {
+	cr, ok := consensusState.(interface{ GetRoot() exported.Root })
+	if !ok || cr.GetRoot() == nil {
+		return nil, fmt.Errorf("%w: the consensus state of the client %s doesn't have the commitment root", ErrCommitmentUnverifiable, consensusState.ClientType())
+	}
+	return cr.GetRoot(), nil
+}
+
+// commitmentCheck verifies the proofs of the msgs before they are submitted
+type commitmentCheck struct {
+	path   string
+	cfg    *CommitmentCheckCfg
+	notify func(ctx context.Context, alert *CommitmentMismatchAlert) error
+
+	mu        sync.Mutex
+	lastCheck time.Time
+}
+
+func newCommitmentCheck(pathName string, cfg *CommitmentCheckCfg) *commitmentCheck {
+	send := cfg.WebhookCfg.sender()
+	return &commitmentCheck{
+		path: pathName,
+		cfg:  cfg,
+		notify: func(ctx context.Context, alert *CommitmentMismatchAlert) error {
+			return send(ctx, alert)
+		},
+	}
+}
+
+// due returns true if the proofs are verified in the relay cycle at `now`
+func (c *commitmentCheck) due(now time.Time) bool {
+	var interval time.Duration
+	if c.cfg.Interval != "" {
+		interval, _ = time.ParseDuration(c.cfg.Interval)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.lastCheck.IsZero() && now.Sub(c.lastCheck) < interval {
+		return false
+	}
+	c.lastCheck = now
+	return true
+}
+
+// SetCommitmentCheck enables the local verification of the proofs of the packet commitments and the acknowledgements before they are submitted
+func (srv *RelayService) SetCommitmentCheck(pathName string, cfg *CommitmentCheckCfg) {
+	srv.commitmentCheck = newCommitmentCheck(pathName, cfg)
+}
+
+// checkCommitments verifies the proofs of MsgRecvPacket and MsgAcknowledgement in `msgs` if the check is due,
+// and removes the msgs whose proofs don't verify
+func (srv *RelayService) checkCommitments(msgs *RelayMsgs) {
+	if srv.commitmentCheck == nil || !srv.commitmentCheck.due(time.Now()) {
+		return
+	}
+	msgs.Src = srv.checkChainCommitments(srv.src, srv.dst, msgs.Src)
+	msgs.Dst = srv.checkChainCommitments(srv.dst, srv.src, msgs.Dst)
+}
+
+// checkChainCommitments verifies the proofs of `counterparty` in the msgs submitted to `chain` and returns the msgs to be submitted
+func (srv *RelayService) checkChainCommitments(chain, counterparty *ProvableChain, msgs []sdk.Msg) []sdk.Msg {
+	logger := GetChannelLogger(chain)
+	if !hasCommitmentProofs(msgs) {
+		return msgs
+	}
+	verifier, err := srv.newCommitmentVerifier(chain, counterparty, msgs)
+	if err != nil {
+		logger.Error("failed to prepare the verification of the commitment proofs", err)
+		return msgs
+	}
+
+	ret := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		var (
+			packet       chantypes.Packet
+			path         string
+			value, proof []byte
+			proofHeight  clienttypes.Height
+		)
+		switch msg := msg.(type) {
+		case *chantypes.MsgRecvPacket:
+			packet, proof, proofHeight = msg.Packet, msg.ProofCommitment, msg.ProofHeight
+			path = host.PacketCommitmentPath(packet.SourcePort, packet.SourceChannel, packet.Sequence)
+			value = chantypes.CommitPacket(counterparty.Codec(), &packet)
+		case *chantypes.MsgAcknowledgement:
+			packet, proof, proofHeight = msg.Packet, msg.ProofAcked, msg.ProofHeight
+			path = host.PacketAcknowledgementPath(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+			value = chantypes.CommitAcknowledgement(msg.Acknowledgement)
+		default:
+			ret = append(ret, msg)
+			continue
+		}
+
+		err := verifier.verify(proofHeight, path, value, proof)
+		if err == nil || errors.Is(err, ErrCommitmentUnverifiable) {
+			if err != nil {
+				logger.Debug("skipped the verification of the commitment proof", "sequence", packet.Sequence, "reason", err.Error())
+			}
+			ret = append(ret, msg)
+			continue
+		}
+		alert := &CommitmentMismatchAlert{
+			Path:          srv.commitmentCheck.path,
+			ChainID:       chain.ChainID(),
+			MsgType:       sdk.MsgTypeURL(msg),
+			SourcePort:    packet.SourcePort,
+			SourceChannel: packet.SourceChannel,
+			Sequence:      packet.Sequence,
+			ProofHeight:   proofHeight.String(),
+			Error:         err.Error(),
+			Time:          time.Now(),
+		}
+		logger.Error("the commitment proof doesn't verify against the consensus state of the client, so the msg is not submitted", err,
+			"msg_type", alert.MsgType, "source_port", alert.SourcePort, "source_channel", alert.SourceChannel, "sequence", alert.Sequence, "proof_height", alert.ProofHeight)
+		// the alert is sent in the background not to delay the relay
+		go func() {
+			if err := srv.commitmentCheck.notify(context.TODO(), alert); err != nil {
+				logger.Error("failed to send the commitment mismatch alert", err)
+			}
+		}()
+	}
+	return ret
+}
+
+// hasCommitmentProofs returns true if `msgs` include the msgs with the commitment proofs verified by the commitment check
+func hasCommitmentProofs(msgs []sdk.Msg) bool {
+	for _, msg := range msgs {
+		switch msg.(type) {
+		case *chantypes.MsgRecvPacket, *chantypes.MsgAcknowledgement:
+			return true
+		}
+	}
+	return false
+}
+
+// commitmentVerifier verifies the proofs submitted to a chain against the client of the counterparty on the chain
+type commitmentVerifier struct {
+	chain       *ProvableChain
+	ctx         QueryContext
+	clientState exported.ClientState
+	prefix      exported.Prefix
+	// specs are the proof specs of the counterparty, which are nil if unknown
+	specs []*ics23.ProofSpec
+	// specsErr is the mismatch between the proof specs of the client and the ones configured for the counterparty
+	specsErr error
+	// consensus states by height given by the MsgUpdateClient submitted together, which are not stored on the chain yet
+	updates map[clienttypes.Height]exported.ConsensusState
+}
+
+func (srv *RelayService) newCommitmentVerifier(chain, counterparty *ProvableChain, msgs []sdk.Msg) (*commitmentVerifier, error) {
+	ctx := srv.sh.GetQueryContext(chain.ChainID())
+	csRes, err := chain.QueryClientState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the client state: %w", err)
+	}
+	var clientState exported.ClientState
+	if err := chain.Codec().UnpackAny(csRes.ClientState, &clientState); err != nil {
+		return nil, err
+	}
+	prefix := exported.Prefix(&DefaultChainPrefix)
+	if chain.Path().ConnectionID != "" {
+		connRes, err := chain.QueryConnection(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the connection: %w", err)
+		}
+		if connRes.Connection != nil {
+			p := connRes.Connection.Counterparty.Prefix
+			prefix = &p
+		}
+	}
+	v := &commitmentVerifier{chain: chain, ctx: ctx, clientState: clientState, prefix: prefix, updates: make(map[clienttypes.Height]exported.ConsensusState)}
+	// the client verifies the proofs with its own specs on chain, so the configured specs only fill in the ones unknown to the relayer
+	configured := srv.proofSpecs[counterparty.ChainID()]
+	if specs, ok := ClientProofSpecs(clientState); ok {
+		v.specs = specs
+		if configured != nil && !ProofSpecsEqual(specs, configured) {
+			v.specsErr = fmt.Errorf("the proof specs of the client %s differ from the ones configured for the chain %s", chain.Path().ClientID, counterparty.ChainID())
+		}
+	} else {
+		v.specs = configured
+	}
+	for _, msg := range msgs {
+		update, ok := msg.(*clienttypes.MsgUpdateClient)
+		if !ok || update.ClientId != chain.Path().ClientID {
+			continue
+		}
+		var clientMsg exported.ClientMessage
+		if err := chain.Codec().UnpackAny(update.ClientMessage, &clientMsg); err != nil {
+			continue
+		}
+		if header, ok := clientMsg.(*tmclient.Header); ok {
+			v.updates[header.GetHeight().(clienttypes.Height)] = header.ConsensusState()
+		}
+	}
+	return v, nil
+}
+
+// verify verifies the proof against the consensus state at the proof height, which is given by a MsgUpdateClient
+// submitted together or stored in the client on the chain
+func (v *commitmentVerifier) verify(proofHeight clienttypes.Height, path string, value, proof []byte) error {
+	consensusState, ok := v.updates[proofHeight]
+	if !ok {
+		res, err := v.chain.QueryClientConsensusState(v.ctx, proofHeight)
+		if err != nil {
+			return fmt.Errorf("%w: the consensus state at %v is not found: %v", ErrCommitmentUnverifiable, proofHeight, err)
+		}
+		if err := v.chain.Codec().UnpackAny(res.ConsensusState, &consensusState); err != nil {
+			return fmt.Errorf("%w: %v", ErrCommitmentUnverifiable, err)
+		}
+	}
+	if v.specsErr != nil {
+		return v.specsErr
+	} else if v.specs == nil {
+		return fmt.Errorf("%w: the client %s doesn't use the ICS-23 proof specs and no proof specs are configured", ErrCommitmentUnverifiable, v.clientState.ClientType())
+	}
+	root, err := commitmentRoot(consensusState)
+	if err != nil {
+		return err
+	}
+	return VerifyMembership(v.chain.Codec(), v.specs, root, v.prefix, path, value, proof)
+}
@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	ics23 "github.com/cosmos/ics23/go"
+	mocktypes "github.com/datachainlab/ibc-mock-client/modules/light-clients/xx-mock/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// proveIBCStore stores `kvs` in the IBC store of a multistore and returns the proof of `path` and the app hash
+func proveIBCStore(t *testing.T, kvs map[string][]byte, path string) ([]byte, []byte) {
+	store := rootmulti.NewStore(dbm.NewMemDB(), cmtlog.NewNopLogger())
+	key := storetypes.NewKVStoreKey(exported.StoreKey)
+	store.MountStoreWithDB(key, storetypes.StoreTypeIAVL, nil)
+	if err := store.LoadVersion(0); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range kvs {
+		store.GetKVStore(key).Set([]byte(k), v)
+	}
+	cid := store.Commit()
+
+	res := store.Query(abci.RequestQuery{Path: "/" + exported.StoreKey + "/key", Data: []byte(path), Height: cid.Version, Prove: true})
+	if res.Code != 0 {
+		t.Fatalf("query failed: %s", res.Log)
+	}
+	proof, err := commitmenttypes.ConvertProofs(res.ProofOps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bz, err := core.MakeCodec().Marshal(&proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bz, cid.Hash
+}
+
+func TestVerifyCommitmentProof(t *testing.T) {
+	cdc := core.MakeCodec()
+	path := host.PacketCommitmentPath("transfer", "channel-0", 1)
+	value := []byte("commitment")
+	proof, root := proveIBCStore(t, map[string][]byte{path: value}, path)
+
+	clientState := &tmclient.ClientState{ProofSpecs: commitmenttypes.GetSDKSpecs()}
+	consensusState := &tmclient.ConsensusState{Root: commitmenttypes.NewMerkleRoot(root)}
+	if err := core.VerifyCommitmentProof(cdc, clientState, consensusState, &core.DefaultChainPrefix, path, value, proof); err != nil {
+		t.Fatal(err)
+	}
+
+	// a wrong commitment, root or order of the proof specs is a mismatch
+	if err := core.VerifyCommitmentProof(cdc, clientState, consensusState, &core.DefaultChainPrefix, path, []byte("other"), proof); err == nil || errors.Is(err, core.ErrCommitmentUnverifiable) {
+		t.Errorf("unexpected result of the wrong commitment: %v", err)
+	}
+	wrongRoot := &tmclient.ConsensusState{Root: commitmenttypes.NewMerkleRoot([]byte("root"))}
+	if err := core.VerifyCommitmentProof(cdc, clientState, wrongRoot, &core.DefaultChainPrefix, path, value, proof); err == nil || errors.Is(err, core.ErrCommitmentUnverifiable) {
+		t.Errorf("unexpected result of the wrong root: %v", err)
+	}
+	wrongSpecs := &tmclient.ClientState{ProofSpecs: []*ics23.ProofSpec{ics23.TendermintSpec, ics23.IavlSpec}}
+	if err := core.VerifyCommitmentProof(cdc, wrongSpecs, consensusState, &core.DefaultChainPrefix, path, value, proof); err == nil || errors.Is(err, core.ErrCommitmentUnverifiable) {
+		t.Errorf("unexpected result of the wrong proof specs: %v", err)
+	}
+
+	// the mock client doesn't verify ICS-23 proofs
+	if err := core.VerifyCommitmentProof(cdc, &mocktypes.ClientState{}, &mocktypes.ConsensusState{}, &core.DefaultChainPrefix, path, value, proof); !errors.Is(err, core.ErrCommitmentUnverifiable) {
+		t.Errorf("unexpected result of the mock client: %v", err)
+	}
+}
+
+func TestCommitmentCheckCfgValidate(t *testing.T) {
+	cases := map[string]struct {
+		cfg   core.CommitmentCheckCfg
+		valid bool
+	}{
+		"empty":            {core.CommitmentCheckCfg{}, true},
+		"full":             {core.CommitmentCheckCfg{Interval: "10m", WebhookCfg: core.WebhookCfg{WebhookURL: "https://hooks.example.com", Timeout: "5s"}}, true},
+		"invalid url":      {core.CommitmentCheckCfg{WebhookCfg: core.WebhookCfg{WebhookURL: "hooks.example.com"}}, false},
+		"invalid interval": {core.CommitmentCheckCfg{Interval: "10"}, false},
+		"negative timeout": {core.CommitmentCheckCfg{WebhookCfg: core.WebhookCfg{Timeout: "-1s"}}, false},
+	}
+	for name, c := range cases {
+		if err := c.cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected result: %v", name, err)
+		}
+	}
+}
@@ -19,6 +19,8 @@ const (
 	ConfigIDClient     ConfigIDType = "client"
 	ConfigIDConnection ConfigIDType = "connection"
 	ConfigIDChannel    ConfigIDType = "channel"
+	// ConfigIDChannelVersion is the channel version negotiated in the handshake
+	ConfigIDChannelVersion ConfigIDType = "channel-version"
 )
 
 type ConfigI interface {
@@ -56,6 +58,13 @@ type ProverConfig interface {
 	Validate() error
 }
 
+// ConfigFieldDescriber is an optional interface of ChainConfig and ProverConfig that describes the fields of the config.
+// The descriptions are keyed by the field names in the config file, with the fields of nested messages joined by dots
+// (e.g. "consumer.provider_chain_id"), and are shown in the JSON schema of the config file.
+type ConfigFieldDescriber interface {
+	DescribeConfigFields() map[string]string
+}
+
 // NewChainProverConfig returns a new config instance
 func NewChainProverConfig(m codec.JSONCodec, chain ChainConfig, client ProverConfig) (*ChainProverConfig, error) {
 	logger := log.GetLogger().WithModule("core.config")
@@ -104,6 +113,20 @@ func (cc ChainProverConfig) GetChainConfig() (ChainConfig, error) {
 	return cc.chain, nil
 }
 
+// SetChainConfig replaces the chain config with `chain`
+func (cc *ChainProverConfig) SetChainConfig(m codec.JSONCodec, chain ChainConfig) error {
+	if err := chain.Validate(); err != nil {
+		return fmt.Errorf("invalid chain config: %v", err)
+	}
+	bz, err := utils.MarshalJSONAny(m, chain)
+	if err != nil {
+		return err
+	}
+	cc.Chain = bz
+	cc.chain = chain
+	return nil
+}
+
 // GetProverConfig returns the cached ChainProverConfig instance
 func (cc ChainProverConfig) GetProverConfig() (ProverConfig, error) {
 	if cc.prover == nil {
@@ -112,6 +135,20 @@ func (cc ChainProverConfig) GetProverConfig() (ProverConfig, error) {
 	return cc.prover, nil
 }
 
+// SetProverConfig replaces the prover config with `prover`
+func (cc *ChainProverConfig) SetProverConfig(m codec.JSONCodec, prover ProverConfig) error {
+	if err := prover.Validate(); err != nil {
+		return fmt.Errorf("invalid prover config: %v", err)
+	}
+	bz, err := utils.MarshalJSONAny(m, prover)
+	if err != nil {
+		return err
+	}
+	cc.Prover = bz
+	cc.prover = prover
+	return nil
+}
+
 // Build returns a new ProvableChain instance
 func (cc ChainProverConfig) Build() (*ProvableChain, error) {
 	chainConfig, err := cc.GetChainConfig()
@@ -133,26 +170,29 @@ func (cc ChainProverConfig) Build() (*ProvableChain, error) {
 	return NewProvableChain(chain, prover), nil
 }
 
-func SyncChainConfigsFromEvents(pathName string, msgIDsSrc, msgIDsDst []MsgID, src, dst *ProvableChain) error {
-	if err := SyncChainConfigFromEvents(pathName, msgIDsSrc, src); err != nil {
+// SyncChainConfigsFromEvents updates the identifiers of the path with the ones generated by the msgs of `srcResults` and `dstResults`
+func SyncChainConfigsFromEvents(pathName string, srcResults, dstResults []MsgResult, src, dst *ProvableChain) error {
+	if err := SyncChainConfigFromEvents(pathName, srcResults, src); err != nil {
 		return err
 	}
-	if err := SyncChainConfigFromEvents(pathName, msgIDsDst, dst); err != nil {
+	if err := SyncChainConfigFromEvents(pathName, dstResults, dst); err != nil {
 		return err
 	}
 	return nil
 }
 
-func SyncChainConfigFromEvents(pathName string, msgIDs []MsgID, chain *ProvableChain) error {
-	for _, msgID := range msgIDs {
-		if msgID == nil {
+// SyncChainConfigFromEvents updates the identifiers of the path on `chain` with the ones in the events of the msgs of `results`.
+// The results of the msgs not included yet are queried by GetMsgResult.
+func SyncChainConfigFromEvents(pathName string, results []MsgResult, chain *ProvableChain) error {
+	for _, res := range results {
+		if res == nil {
 			continue
 		}
-		msgRes, err := chain.Chain.GetMsgResult(msgID)
+		msgRes, err := ResolveMsgResult(chain.Chain, res)
 		if err != nil {
 			return fmt.Errorf("failed to get message result: %v", err)
 		} else if ok, failureReason := msgRes.Status(); !ok {
-			return fmt.Errorf("msg(id=%v) execution failed: %v", msgID, failureReason)
+			return fmt.Errorf("msg(id=%v) execution failed: %v", res.MsgID(), failureReason)
 		}
 
 		for _, event := range msgRes.Events() {
@@ -170,6 +210,7 @@ func SyncChainConfigFromEvents(pathName string, msgIDs []MsgID, chain *ProvableC
 				id = event.ID
 			}
 			if id != "" {
+				chain.Path().SetConfigID(configID, id)
 				if err := config.UpdateConfigID(pathName, chain.ChainID(), configID, id); err != nil {
 					return err
 				}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// txConfirmationBlocks is the number of average block times to wait for a tx to be included
+const txConfirmationBlocks = 30
+
+// InclusionWaiter is an optional interface of Chain that tells whether SendMsgs waits for the msgs to be included in blocks.
+// A chain that doesn't implement it is regarded as waiting for the inclusion.
+type InclusionWaiter interface {
+	// WaitsForInclusion returns false if SendMsgs returns just after broadcasting the msgs
+	WaitsForInclusion() bool
+}
+
+func waitsForInclusion(chain Chain) bool {
+	if w, ok := unwrapChain(chain).(InclusionWaiter); ok {
+		return w.WaitsForInclusion()
+	}
+	return true
+}
+
+// MsgConfirmation is the inclusion result of a msg sent by SendMsgs
+type MsgConfirmation struct {
+	MsgID  MsgID
+	Result MsgResult
+	Err    error
+}
+
+// ConfirmMsgs waits for the msgs sent by SendMsgs to be included in blocks and returns their results.
+// The inclusion is confirmed by GetMsgResult, so the results are obtained in the same way
+// regardless of whether SendMsgs of the chain waits for the inclusion or not. Nil msg IDs are skipped.
+func ConfirmMsgs(chain Chain, msgIDs []MsgID) []MsgConfirmation {
+	var confirmations []MsgConfirmation
+	for _, msgID := range msgIDs {
+		if msgID == nil {
+			continue
+		}
+		c := MsgConfirmation{MsgID: msgID}
+		c.Result, c.Err = chain.GetMsgResult(msgID)
+		if c.Err == nil {
+			if ok, reason := c.Result.Status(); !ok {
+				c.Err = fmt.Errorf("msg(id=%v) execution failed: %v", msgID, reason)
+			}
+		}
+		confirmations = append(confirmations, c)
+	}
+	return confirmations
+}
+
+// confirmSentMsgs confirms the inclusion of the msgs sent to the chains whose SendMsgs doesn't wait for it.
+// The chains are confirmed concurrently after all the batches are broadcasted.
+func (r *RelayMsgs) confirmSentMsgs(src, dst Chain) {
+	logger := GetChannelPairLogger(src, dst)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, sent := range []struct {
+		chain  Chain
+		msgIDs []MsgID
+	}{{src, r.SrcMsgIDs}, {dst, r.DstMsgIDs}} {
+		if waitsForInclusion(sent.chain) || len(sent.msgIDs) == 0 {
+			continue
+		}
+		sent := sent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := confirmInclusion(sent.chain, sent.msgIDs); err != nil {
+				logger.Error("failed to confirm the inclusion of msgs", err, "chain_id", sent.chain.ChainID())
+				mu.Lock()
+				r.Succeeded = false
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// confirmInclusion waits for the msgs to be included in blocks and returns an error if any of them failed.
+// The msgs whose tx IDs are known are confirmed by polling GetTxResult once per tx, and the others by GetMsgResult.
+func confirmInclusion(chain Chain, msgIDs []MsgID) error {
+	var (
+		txIDs  []string
+		others []MsgID
+		seen   = make(map[string]bool)
+	)
+	for _, msgID := range msgIDs {
+		if msgID == nil {
+			continue
+		}
+		txID := txIDOf(msgID)
+		if txID == "" {
+			others = append(others, msgID)
+		} else if !seen[txID] {
+			seen[txID] = true
+			txIDs = append(txIDs, txID)
+		}
+	}
+
+	var errs []error
+	for _, txID := range txIDs {
+		ctx, cancel := context.WithTimeout(context.Background(), chain.AverageBlockTime()*txConfirmationBlocks)
+		res, err := WaitTxResult(ctx, chain, txID)
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+		} else if !res.Succeeded() {
+			errs = append(errs, fmt.Errorf("tx %s execution failed: %s", txID, res.FailureReason))
+		}
+	}
+	for _, c := range ConfirmMsgs(chain, others) {
+		if c.Err != nil {
+			errs = append(errs, c.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
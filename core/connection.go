@@ -22,14 +22,34 @@ var (
 	rtyErr    = retry.LastErrorOnly(true)
 )
 
-func CreateConnection(pathName string, src, dst *ProvableChain, to time.Duration) error {
+// CreateConnection runs the connection handshake until it completes, waiting between the steps according to the backoff policy.
+// It aborts with the last observed states of the connection ends when `ctx` is done (e.g. the deadline is exceeded).
+func CreateConnection(ctx context.Context, pathName string, src, dst *ProvableChain, policy BackoffPolicy) error {
+	if src.Path().Protocol() == ProtocolV2 {
+		return fmt.Errorf("the path %s is a %s path, which has no connection handshake: register the counterparties instead", pathName, ProtocolV2)
+	}
+	sh, err := NewSyncHeaders(src, dst)
+	if err != nil {
+		return err
+	}
+	return createConnection(ctx, pathName, src, dst, sh, policy)
+}
+
+// createConnection is CreateConnection with SyncHeaders shared with the other stages of a handshake
+func createConnection(ctx context.Context, pathName string, src, dst *ProvableChain, sh SyncHeaders, policy BackoffPolicy) error {
 	logger := GetConnectionPairLogger(src, dst)
 	defer logger.TimeTrack(time.Now(), "CreateConnection")
-	ticker := time.NewTicker(to)
+	backoff := policy.NewBackoff()
 
 	failed := 0
-	for ; true; <-ticker.C {
-		connSteps, err := createConnectionStep(src, dst)
+	for ; true; sleepContext(ctx, backoff.Next()) {
+		if err := ctx.Err(); err != nil {
+			states := connectionStates(src, dst)
+			logger.Error("connection handshake aborted", err, "states", states)
+			return fmt.Errorf("connection handshake aborted: %w: %s", err, states)
+		}
+
+		connSteps, err := createConnectionStep(src, dst, sh)
 		if err != nil {
 			logger.Error(
 				"failed to create connection step",
@@ -45,7 +65,7 @@ func CreateConnection(pathName string, src, dst *ProvableChain, to time.Duration
 
 		connSteps.Send(src, dst)
 		if connSteps.Success() {
-			if err := SyncChainConfigsFromEvents(pathName, connSteps.SrcMsgIDs, connSteps.DstMsgIDs, src, dst); err != nil {
+			if err := SyncChainConfigsFromEvents(pathName, connSteps.SrcMsgResults, connSteps.DstMsgResults, src, dst); err != nil {
 				return err
 			}
 		}
@@ -61,21 +81,21 @@ func CreateConnection(pathName string, src, dst *ProvableChain, to time.Duration
 		// In the case of success, reset the failures counter
 		case connSteps.Success():
 			failed = 0
+			backoff.Reset()
 			continue
 		// In the case of failure, increment the failures counter and exit if this is the 3rd failure
 		case !connSteps.Success():
 			failed++
 			logger.Info("retrying transaction...")
-			time.Sleep(5 * time.Second)
 			if failed > 2 {
 				logger.Error(
 					"! Connection failed",
 					errors.New("failed 3 times"),
 				)
-				return fmt.Errorf("! Connection failed: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
+				return withSendErrors(fmt.Errorf("! Connection failed: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
 					src.ChainID(), src.Path().ClientID, src.Path().ConnectionID,
 					dst.ChainID(), dst.Path().ClientID, dst.Path().ConnectionID,
-				)
+				), connSteps)
 			}
 		}
 
@@ -84,13 +104,13 @@ func CreateConnection(pathName string, src, dst *ProvableChain, to time.Duration
 	return nil
 }
 
-func createConnectionStep(src, dst *ProvableChain) (*RelayMsgs, error) {
+func createConnectionStep(src, dst *ProvableChain, sh SyncHeaders) (*RelayMsgs, error) {
 	out := NewRelayMsgs()
 	if err := validatePaths(src, dst); err != nil {
 		return nil, err
 	}
 	// First, update the light clients to the latest header and return the header
-	sh, err := NewSyncHeaders(src, dst)
+	err := sh.Updates(src, dst)
 	if err != nil {
 		return nil, err
 	}
@@ -165,75 +185,35 @@ func createConnectionStep(src, dst *ProvableChain) (*RelayMsgs, error) {
 		}
 	}
 
-	switch {
-	// Handshake hasn't been started on src or dst, relay `connOpenInit` to src
-	case srcConn.Connection.State == conntypes.UNINITIALIZED && dstConn.Connection.State == conntypes.UNINITIALIZED:
-		logConnectionStates(src, dst, srcConn, dstConn)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ConnInit(dst.Path(), addr))
-		// Handshake has started on dst (1 stepdone), relay `connOpenTry` and `updateClient` on src
-	case srcConn.Connection.State == conntypes.UNINITIALIZED && dstConn.Connection.State == conntypes.INIT:
-		logConnectionStates(src, dst, srcConn, dstConn)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ConnTry(dst.Path(), dstCsRes, dstConn, dstConsRes, srcHostConsProof, addr))
-	// Handshake has started on src (1 step done), relay `connOpenTry` and `updateClient` on dst
-	case srcConn.Connection.State == conntypes.INIT && dstConn.Connection.State == conntypes.UNINITIALIZED:
-		logConnectionStates(dst, src, dstConn, srcConn)
-		addr := mustGetAddress(dst)
-		if len(srcUpdateHeaders) > 0 {
-			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
-		}
-		out.Dst = append(out.Dst, dst.Path().ConnTry(src.Path(), srcCsRes, srcConn, srcConsRes, dstHostConsProof, addr))
-
-	// Handshake has started on src end (2 steps done), relay `connOpenAck` and `updateClient` to dst end
-	case srcConn.Connection.State == conntypes.TRYOPEN && dstConn.Connection.State == conntypes.INIT:
-		logConnectionStates(dst, src, dstConn, srcConn)
-		addr := mustGetAddress(dst)
-		if len(srcUpdateHeaders) > 0 {
-			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
-		}
-		out.Dst = append(out.Dst, dst.Path().ConnAck(src.Path(), srcCsRes, srcConn, srcConsRes, dstHostConsProof, addr))
-
-	// Handshake has started on dst end (2 steps done), relay `connOpenAck` and `updateClient` to src end
-	case srcConn.Connection.State == conntypes.INIT && dstConn.Connection.State == conntypes.TRYOPEN:
-		logConnectionStates(src, dst, srcConn, dstConn)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ConnAck(dst.Path(), dstCsRes, dstConn, dstConsRes, srcHostConsProof, addr))
-
-	// Handshake has confirmed on dst (3 steps done), relay `connOpenConfirm` and `updateClient` to src end
-	case srcConn.Connection.State == conntypes.TRYOPEN && dstConn.Connection.State == conntypes.OPEN:
-		logConnectionStates(src, dst, srcConn, dstConn)
-		addr := mustGetAddress(src)
-		if len(dstUpdateHeaders) > 0 {
-			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
-		}
-		out.Src = append(out.Src, src.Path().ConnConfirm(dstConn, addr))
-		out.Last = true
-
-	// Handshake has confirmed on src (3 steps done), relay `connOpenConfirm` and `updateClient` to dst end
-	case srcConn.Connection.State == conntypes.OPEN && dstConn.Connection.State == conntypes.TRYOPEN:
-		logConnectionStates(dst, src, dstConn, srcConn)
-		addr := mustGetAddress(dst)
-		if len(srcUpdateHeaders) > 0 {
-			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
-		}
-		out.Dst = append(out.Dst, dst.Path().ConnConfirm(srcConn, addr))
-		out.Last = true
-
-	default:
-		panic(fmt.Sprintf("not implemented error: %v %v", srcConn.Connection.State, dstConn.Connection.State))
+	logConnectionStates(src, dst, srcConn, dstConn)
+	srcAddr, err := src.GetAddress()
+	if err != nil {
+		return nil, err
 	}
-
-	return out, nil
+	dstAddr, err := dst.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	return ConnectionHandshakeStep(
+		&ConnectionHandshakeEnd{
+			Path:                    src.Path(),
+			Signer:                  srcAddr,
+			Connection:              srcConn,
+			ClientState:             srcCsRes,
+			ConsensusState:          srcConsRes,
+			HostConsensusStateProof: srcHostConsProof,
+			UpdateHeaders:           srcUpdateHeaders,
+		},
+		&ConnectionHandshakeEnd{
+			Path:                    dst.Path(),
+			Signer:                  dstAddr,
+			Connection:              dstConn,
+			ClientState:             dstCsRes,
+			ConsensusState:          dstConsRes,
+			HostConsensusStateProof: dstHostConsProof,
+			UpdateHeaders:           dstUpdateHeaders,
+		},
+	)
 }
 
 // validatePaths takes two chains and validates their paths
@@ -317,3 +297,19 @@ func GetConnectionPairLogger(src, dst Chain) *log.RelayLogger {
 		).
 		WithModule("core.connection")
 }
+
+// connectionStates returns the latest states of the connection ends for diagnostics
+func connectionStates(src, dst *ProvableChain) string {
+	stateOf := func(chain *ProvableChain) string {
+		height, err := chain.LatestHeight()
+		if err != nil {
+			return fmt.Sprintf("[%s]conn{%s}: failed to get the latest height: %v", chain.ChainID(), chain.Path().ConnectionID, err)
+		}
+		res, err := chain.QueryConnection(NewQueryContext(context.TODO(), height))
+		if err != nil {
+			return fmt.Sprintf("[%s]conn{%s}: failed to query the connection: %v", chain.ChainID(), chain.Path().ConnectionID, err)
+		}
+		return fmt.Sprintf("[%s]conn{%s}: %s", chain.ChainID(), chain.Path().ConnectionID, res.Connection.State)
+	}
+	return fmt.Sprintf("%s, %s", stateOf(src), stateOf(dst))
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// CounterSnapshotFile returns the path of the file to persist the metrics counters of the relay service for the path
+func CounterSnapshotFile(homePath, pathName string) string {
+	return filepath.Join(homePath, "metrics", pathName+".json")
+}
+
+// LoadCounterSnapshot reads the counter snapshot file. It returns nil if the file doesn't exist.
+func LoadCounterSnapshot(file string) (metrics.CounterSnapshot, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var snapshot metrics.CounterSnapshot
+	if err := json.Unmarshal(bz, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the counter snapshot file %s: %w", file, err)
+	}
+	return snapshot, nil
+}
+
+// SaveCounterSnapshot writes the counter snapshot file atomically
+func SaveCounterSnapshot(file string, snapshot metrics.CounterSnapshot) error {
+	bz, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return saveCounterSnapshotBytes(file, bz)
+}
+
+func saveCounterSnapshotBytes(file string, bz []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(file, bz, 0600)
+}
+
+// SetCounterSnapshotFile restores the metrics counters from the snapshot in `file`, and makes the service save the snapshot
+// to the file after every relay cycle, so that the counters (e.g. the packets relayed and the fees paid) don't reset to zero on restarts.
+// The metrics must be initialized before it is called.
+func (srv *RelayService) SetCounterSnapshotFile(file string) error {
+	snapshot, err := LoadCounterSnapshot(file)
+	if err != nil {
+		return err
+	}
+	if err := metrics.RestoreCounters(snapshot); err != nil {
+		return fmt.Errorf("failed to restore the counters from %s: %w", file, err)
+	}
+	srv.counterFile = file
+	return nil
+}
+
+// saveCounterSnapshot saves the snapshot of the metrics counters if they have changed since the last save
+func (srv *RelayService) saveCounterSnapshot() {
+	if srv.counterFile == "" {
+		return
+	}
+	snapshot := metrics.SnapshotCounters()
+	if snapshot == nil {
+		return
+	}
+	bz, err := json.Marshal(snapshot)
+	if err != nil {
+		GetChannelPairLogger(srv.src, srv.dst).Error("failed to marshal the counter snapshot", err)
+		return
+	}
+	if bytes.Equal(bz, srv.lastCounterSnapshot) {
+		return
+	}
+	if err := saveCounterSnapshotBytes(srv.counterFile, bz); err != nil {
+		GetChannelPairLogger(srv.src, srv.dst).Error("failed to save the counter snapshot", err, "file", srv.counterFile)
+		return
+	}
+	srv.lastCounterSnapshot = bz
+}
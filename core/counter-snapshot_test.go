@@ -0,0 +1,51 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+func TestCounterSnapshot(t *testing.T) {
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	attrs := api.WithAttributes(attribute.Key("chain_id").String("ibc0"), attribute.Key("success").Bool(true))
+	metrics.TxResultsCounter.Add(context.TODO(), 3, attrs)
+	metrics.GasUsedCounter.Add(context.TODO(), 100000, api.WithAttributes(attribute.Key("chain_id").String("ibc0")))
+
+	file := core.CounterSnapshotFile(t.TempDir(), "path")
+	if err := core.SaveCounterSnapshot(file, metrics.SnapshotCounters()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the counters are restored after a restart and keep counting from the snapshot
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := core.LoadCounterSnapshot(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.RestoreCounters(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	metrics.TxResultsCounter.Add(context.TODO(), 2, attrs)
+
+	restored := metrics.SnapshotCounters()
+	txResults := restored["relayer.tx_results"]
+	if len(txResults) != 1 || txResults[0].Value != 5 || len(txResults[0].Attributes) != 2 {
+		t.Errorf("unexpected tx results: %+v", txResults)
+	}
+	if gasUsed := restored["relayer.gas_used"]; len(gasUsed) != 1 || gasUsed[0].Value != 100000 {
+		t.Errorf("unexpected gas used: %+v", gasUsed)
+	}
+
+	if snapshot, err := core.LoadCounterSnapshot(core.CounterSnapshotFile(t.TempDir(), "path")); err != nil || snapshot != nil {
+		t.Errorf("a missing snapshot must be nil: %v, %v", snapshot, err)
+	}
+}
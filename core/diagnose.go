@@ -0,0 +1,228 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DiagnosisSeverity is the severity of a finding of the diagnosis
+type DiagnosisSeverity string
+
+const (
+	DiagnosisOK      DiagnosisSeverity = "ok"
+	DiagnosisWarning DiagnosisSeverity = "warning"
+	DiagnosisError   DiagnosisSeverity = "error"
+)
+
+// DiagnosisFinding is the result of a check of the diagnosis with the likely causes and the suggested commands to fix it
+type DiagnosisFinding struct {
+	// ChainID is empty for the checks of the whole path
+	ChainID     string            `json:"chain_id,omitempty"`
+	Check       string            `json:"check"`
+	Severity    DiagnosisSeverity `json:"severity"`
+	Detail      string            `json:"detail"`
+	Causes      []string          `json:"causes,omitempty"`
+	Suggestions []string          `json:"suggestions,omitempty"`
+}
+
+// DiagnoseOptions are the thresholds of the diagnosis
+type DiagnoseOptions struct {
+	// MaxRPCLag is the maximum age of the latest block before the RPC node is regarded as lagging or the chain as halted
+	MaxRPCLag time.Duration
+	// ClientExpiryMargin is the time before the expiry of a client from which the client is regarded as expiring soon
+	ClientExpiryMargin time.Duration
+	// StaleRelay is the time since the last relay after which pending packets are regarded as stuck
+	StaleRelay time.Duration
+}
+
+// DefaultDiagnoseOptions returns the default thresholds of the diagnosis
+func DefaultDiagnoseOptions() DiagnoseOptions {
+	return DiagnoseOptions{
+		MaxRPCLag:          time.Minute,
+		ClientExpiryMargin: 24 * time.Hour,
+		StaleRelay:         10 * time.Minute,
+	}
+}
+
+// Diagnose interprets the results of the pre-flight check and the status snapshot of a path, and returns the findings with
+// the likely causes of the problems and the commands suggested to fix them. It encodes the common support knowledge,
+// so the findings are heuristics rather than proofs.
+func Diagnose(pathName string, checks []PathCheckResult, snapshot *PathSnapshot, pause *PauseState, opts DiagnoseOptions, now time.Time) []DiagnosisFinding {
+	var findings []DiagnosisFinding
+
+	if pause != nil && pause.Paused {
+		f := DiagnosisFinding{
+			Check:       "pause",
+			Severity:    DiagnosisWarning,
+			Detail:      fmt.Sprintf("the path is paused since %s (reason: %q)", pause.Since.Format(time.RFC3339), pause.Reason),
+			Causes:      []string{"relaying was paused by an operator or the admin API, so the relay service skips the path"},
+			Suggestions: []string{fmt.Sprintf("yrly paths resume %s", pathName)},
+		}
+		if len(pause.Recovery) > 0 {
+			f.Severity = DiagnosisError
+			f.Causes = nil
+			f.Suggestions = nil
+			for _, r := range pause.Recovery {
+				f.Causes = append(f.Causes, fmt.Sprintf("the client %s on %s is %s, so the relay service paused the path", r.ClientID, r.ChainID, r.Status))
+				for _, step := range r.Steps {
+					// the steps of both clients end with resuming the path
+					if !slices.Contains(f.Suggestions, step) {
+						f.Suggestions = append(f.Suggestions, step)
+					}
+				}
+			}
+		}
+		findings = append(findings, f)
+	}
+
+	for _, r := range checks {
+		findings = append(findings, diagnoseCheck(pathName, r))
+	}
+
+	if snapshot != nil {
+		for _, cs := range []ChainSnapshot{snapshot.Src, snapshot.Dst} {
+			findings = append(findings, diagnoseChainSnapshot(pathName, cs, opts, now)...)
+		}
+		findings = append(findings, diagnosePendingPackets(pathName, snapshot, pause, opts, now))
+	}
+	return findings
+}
+
+// diagnoseCheck maps a result of CheckPath to the likely causes and the fixes
+func diagnoseCheck(pathName string, r PathCheckResult) DiagnosisFinding {
+	f := DiagnosisFinding{ChainID: r.ChainID, Check: r.Item, Severity: DiagnosisOK, Detail: r.Detail}
+	if r.OK {
+		return f
+	}
+	f.Severity = DiagnosisError
+	switch r.Item {
+	case "rpc":
+		f.Causes = []string{
+			"the RPC node of the chain is down or unreachable from the relayer",
+			"the rpc-addr of the chain in the config is wrong",
+		}
+		f.Suggestions = []string{"yrly config show"}
+	case "key":
+		f.Causes = []string{"the key of the relayer account is not in the keyring of the relayer"}
+		f.Suggestions = []string{fmt.Sprintf("yrly tendermint keys restore %s [name] [mnemonic]", r.ChainID)}
+	case "balance":
+		f.Causes = []string{"the relayer account is out of funds to pay the tx fees"}
+		f.Suggestions = []string{fmt.Sprintf("yrly query balance %s", r.ChainID)}
+	case "client":
+		switch {
+		case strings.Contains(r.Detail, "not set"):
+			f.Causes = []string{"the clients of the path are not created yet"}
+			f.Suggestions = []string{fmt.Sprintf("yrly tx clients %s", pathName)}
+		case strings.Contains(r.Detail, "expired"):
+			f.Causes = []string{"the client was not updated within its trusting period (e.g. the relayer was stopped for too long)"}
+			f.Suggestions = []string{"an expired client can only be recovered by a governance proposal substituting it with an active client"}
+		default:
+			f.Causes = []string{
+				"the client-id in the path config doesn't exist on the chain",
+				"the path config points to a client of another chain",
+			}
+			f.Suggestions = []string{fmt.Sprintf("yrly query client %s %s", pathName, r.ChainID)}
+		}
+	case "connection":
+		if strings.Contains(r.Detail, "not open") {
+			f.Causes = []string{"the connection handshake is not completed"}
+			f.Suggestions = []string{fmt.Sprintf("yrly tx connection %s", pathName)}
+		} else {
+			f.Causes = []string{"the identifiers in the path config don't match the connection on the chain"}
+			f.Suggestions = []string{fmt.Sprintf("yrly query connection %s %s", pathName, r.ChainID)}
+		}
+	case "channel":
+		switch {
+		case strings.Contains(r.Detail, "CLOSED"):
+			f.Causes = []string{"the channel is closed (e.g. a packet timed out on an ordered channel) and can't be reopened"}
+			f.Suggestions = []string{"create a new channel and update the channel-id of the path config with `yrly paths edit`"}
+		case strings.Contains(r.Detail, "not open"):
+			f.Causes = []string{"the channel handshake is not completed"}
+			f.Suggestions = []string{fmt.Sprintf("yrly tx channel %s", pathName)}
+		default:
+			f.Causes = []string{"the identifiers, order or version in the path config don't match the channel on the chain"}
+			f.Suggestions = []string{fmt.Sprintf("yrly query channel %s %s", pathName, r.ChainID)}
+		}
+	}
+	return f
+}
+
+// diagnoseChainSnapshot checks the freshness of the RPC node and the expiry of the client of a chain
+func diagnoseChainSnapshot(pathName string, cs ChainSnapshot, opts DiagnoseOptions, now time.Time) []DiagnosisFinding {
+	var findings []DiagnosisFinding
+	if !cs.LatestTimestamp.IsZero() {
+		f := DiagnosisFinding{ChainID: cs.ChainID, Check: "rpc-lag", Severity: DiagnosisOK}
+		lag := now.Sub(cs.LatestTimestamp).Truncate(time.Second)
+		f.Detail = fmt.Sprintf("the latest block %s is %v old", cs.LatestHeight, lag)
+		if lag > opts.MaxRPCLag {
+			f.Severity = DiagnosisWarning
+			f.Causes = []string{
+				"the RPC node is syncing or lagging behind the network",
+				"the chain is halted",
+			}
+			f.Suggestions = []string{"switch the rpc-addr of the chain to a synced node and restart the relay service"}
+		}
+		findings = append(findings, f)
+	}
+	if cs.Client.ExpiresAt != nil && cs.Client.ExpiresAt.After(now) {
+		f := DiagnosisFinding{
+			ChainID:  cs.ChainID,
+			Check:    "client-expiry",
+			Severity: DiagnosisOK,
+			Detail:   fmt.Sprintf("the client %s expires at %s", cs.Client.ClientID, cs.Client.ExpiresAt.Format(time.RFC3339)),
+		}
+		if cs.Client.ExpiresAt.Sub(now) < opts.ClientExpiryMargin {
+			f.Severity = DiagnosisWarning
+			f.Causes = []string{"the client has not been updated recently because there were no packets to relay or the relayer was stopped"}
+			f.Suggestions = []string{fmt.Sprintf("yrly tx update-clients %s", pathName)}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// diagnosePendingPackets checks whether the pending packets and acknowledgements are being relayed
+func diagnosePendingPackets(pathName string, snapshot *PathSnapshot, pause *PauseState, opts DiagnoseOptions, now time.Time) DiagnosisFinding {
+	pending := 0
+	for _, cs := range []ChainSnapshot{snapshot.Src, snapshot.Dst} {
+		pending += cs.UnrelayedPackets + cs.UnrelayedAcknowledgements
+	}
+	f := DiagnosisFinding{
+		Check:    "pending-packets",
+		Severity: DiagnosisOK,
+		Detail: fmt.Sprintf("packets: %d (src) / %d (dst), acknowledgements: %d (src) / %d (dst)",
+			snapshot.Src.UnrelayedPackets, snapshot.Dst.UnrelayedPackets,
+			snapshot.Src.UnrelayedAcknowledgements, snapshot.Dst.UnrelayedAcknowledgements),
+	}
+	if pending == 0 {
+		return f
+	}
+	if snapshot.LastRelayTime != nil && now.Sub(*snapshot.LastRelayTime) <= opts.StaleRelay {
+		f.Detail += fmt.Sprintf("; last relayed at %s", snapshot.LastRelayTime.Format(time.RFC3339))
+		return f
+	}
+	f.Severity = DiagnosisWarning
+	if snapshot.LastRelayTime == nil {
+		f.Detail += "; never relayed by the relay service"
+	} else {
+		f.Detail += fmt.Sprintf("; last relayed at %s", snapshot.LastRelayTime.Format(time.RFC3339))
+	}
+	if pause != nil && pause.Paused {
+		f.Causes = []string{"the path is paused"}
+		f.Suggestions = []string{fmt.Sprintf("yrly paths resume %s", pathName)}
+		return f
+	}
+	f.Causes = []string{
+		"the relay service is not running for the path",
+		"the relay txs are failing (see the errors in the logs of the relay service)",
+		"the packets are held by the value limits or the address screening",
+	}
+	f.Suggestions = []string{
+		fmt.Sprintf("yrly service start %s", pathName),
+		fmt.Sprintf("yrly tx relay %s", pathName),
+		fmt.Sprintf("yrly query unrelayed-packets %s", pathName),
+	}
+	return f
+}
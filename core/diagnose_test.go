@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestDiagnose(t *testing.T) {
+	now := time.Now()
+	expiresSoon := now.Add(time.Hour)
+	lastRelay := now.Add(-time.Hour)
+	checks := []core.PathCheckResult{
+		{ChainID: "ibc0", Item: "rpc", OK: true, Detail: "latest height: 0-100"},
+		{ChainID: "ibc0", Item: "client", OK: false, Detail: "the client has expired at 2024-01-01"},
+		{ChainID: "ibc1", Item: "channel", OK: false, Detail: "the channel is not open: TRYOPEN"},
+	}
+	snapshot := &core.PathSnapshot{
+		Path: "ibc01",
+		Src: core.ChainSnapshot{
+			ChainID:          "ibc0",
+			LatestTimestamp:  now.Add(-10 * time.Minute),
+			UnrelayedPackets: 3,
+		},
+		Dst: core.ChainSnapshot{
+			ChainID:         "ibc1",
+			LatestTimestamp: now.Add(-time.Second),
+			Client:          core.ClientSnapshot{ClientID: "07-tendermint-0", ExpiresAt: &expiresSoon},
+		},
+		LastRelayTime: &lastRelay,
+	}
+	findings := core.Diagnose("ibc01", checks, snapshot, &core.PauseState{}, core.DefaultDiagnoseOptions(), now)
+
+	find := func(chainID, check string) core.DiagnosisFinding {
+		for _, f := range findings {
+			if f.ChainID == chainID && f.Check == check {
+				return f
+			}
+		}
+		t.Fatalf("no finding of %s on %q", check, chainID)
+		return core.DiagnosisFinding{}
+	}
+	expected := []struct {
+		chainID, check string
+		severity       core.DiagnosisSeverity
+		suggestion     string
+	}{
+		{"ibc0", "rpc", core.DiagnosisOK, ""},
+		{"ibc0", "client", core.DiagnosisError, "governance"},
+		{"ibc1", "channel", core.DiagnosisError, "yrly tx channel ibc01"},
+		{"ibc0", "rpc-lag", core.DiagnosisWarning, "rpc-addr"},
+		{"ibc1", "rpc-lag", core.DiagnosisOK, ""},
+		{"ibc1", "client-expiry", core.DiagnosisWarning, "yrly tx update-clients ibc01"},
+		{"", "pending-packets", core.DiagnosisWarning, "yrly service start ibc01"},
+	}
+	for _, e := range expected {
+		f := find(e.chainID, e.check)
+		if f.Severity != e.severity {
+			t.Errorf("%s on %q: unexpected severity: %s", e.check, e.chainID, f.Severity)
+		}
+		if e.suggestion != "" && !strings.Contains(strings.Join(f.Suggestions, "\n"), e.suggestion) {
+			t.Errorf("%s on %q: %q is not suggested: %v", e.check, e.chainID, e.suggestion, f.Suggestions)
+		}
+	}
+
+	// the pending packets are being relayed
+	snapshot.LastRelayTime = &now
+	findings = core.Diagnose("ibc01", nil, snapshot, &core.PauseState{Paused: true, Since: now}, core.DefaultDiagnoseOptions(), now)
+	if f := find("", "pending-packets"); f.Severity != core.DiagnosisOK {
+		t.Errorf("recently relayed packets are regarded as stuck: %+v", f)
+	}
+	if f := find("", "pause"); f.Severity != core.DiagnosisWarning || f.Suggestions[0] != "yrly paths resume ibc01" {
+		t.Errorf("unexpected finding of the pause state: %+v", f)
+	}
+}
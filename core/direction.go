@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// RelayDirection is the direction in which the states are relayed on a path:
+// from the chain on which a packet or an acknowledgement is committed to the chain to which the msg relaying it is submitted
+type RelayDirection string
+
+const (
+	// SrcToDst is the direction of the msgs submitted to the dst chain
+	SrcToDst RelayDirection = "src-to-dst"
+	// DstToSrc is the direction of the msgs submitted to the src chain
+	DstToSrc RelayDirection = "dst-to-src"
+)
+
+// relayDirectionAttr returns the metrics attribute of the direction
+func relayDirectionAttr(d RelayDirection) attribute.KeyValue {
+	return attribute.Key("relay_direction").String(string(d))
+}
+
+// DirectionCounters counts the packet msgs submitted by the relay service in a direction of a path
+type DirectionCounters struct {
+	RelayedPackets          uint64 `json:"relayed_packets"`
+	RelayedAcknowledgements uint64 `json:"relayed_acknowledgements"`
+	RelayedTimeouts         uint64 `json:"relayed_timeouts"`
+	// Failed is the number of the packet msgs of any type failed to be submitted
+	Failed uint64 `json:"failed"`
+}
+
+// DirectionStats is the statistics of a direction of a path
+type DirectionStats struct {
+	// PendingPackets is the number of the packets not received on the destination chain yet
+	PendingPackets int `json:"pending_packets"`
+	// PendingAcknowledgements is the number of the acknowledgements not relayed to the destination chain yet
+	PendingAcknowledgements int `json:"pending_acknowledgements"`
+	DirectionCounters
+}
+
+// add counts the packet msgs of the journal entries
+func (c *DirectionCounters) add(entries []*JournalEntry) {
+	for _, e := range entries {
+		if !e.Success {
+			c.Failed++
+			continue
+		}
+		switch e.MsgType {
+		case sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}):
+			c.RelayedPackets++
+		case sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}):
+			c.RelayedAcknowledgements++
+		case sdk.MsgTypeURL(&chantypes.MsgTimeout{}), sdk.MsgTypeURL(&chantypes.MsgTimeoutOnClose{}):
+			c.RelayedTimeouts++
+		}
+	}
+}
+
+// recordDirection sets the direction `d` to the journal entries of the msgs relayed in the direction and updates its counters and metrics
+func (srv *RelayService) recordDirection(d RelayDirection, entries []*JournalEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	srv.relayStatusMu.Lock()
+	counters := &srv.relayStatus.SrcToDst
+	if d == DstToSrc {
+		counters = &srv.relayStatus.DstToSrc
+	}
+	counters.add(entries)
+	srv.relayStatusChanged = true
+	srv.relayStatusMu.Unlock()
+	for _, e := range entries {
+		e.Direction = d
+		attrs := append([]attribute.KeyValue{
+			attribute.Key("chain_id").String(e.ChainID),
+			attribute.Key("msg_type").String(e.MsgType),
+			attribute.Key("success").Bool(e.Success),
+			relayDirectionAttr(d),
+		}, groupAttrs(srv.group)...)
+		metrics.RelayedMsgsCounter.Add(context.TODO(), 1, api.WithAttributes(attrs...))
+	}
+}
@@ -0,0 +1,65 @@
+package core_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+func TestRelayStatusByDirection(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	chains := newMockChains(t, [2]*core.PathEnd{
+		{ChainID: "ibc0", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-1", Order: "unordered", Version: "ics20-1"},
+	}, nil)
+
+	file := filepath.Join(t.TempDir(), "path.json")
+	saved := &core.RelayStatus{
+		LastRelayTime: time.Unix(100, 0).UTC(),
+		SrcToDst:      core.DirectionCounters{RelayedPackets: 3, RelayedAcknowledgements: 1},
+		DstToSrc:      core.DirectionCounters{RelayedTimeouts: 2, Failed: 5},
+	}
+	if err := core.SaveRelayStatus(file, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// the counters are restored from the status file
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], nil, 0, 0, 0, 0, 0)
+	srv.SetStatusFile(file)
+	rec := httptest.NewRecorder()
+	core.NewAdminServer(srv, core.AdminServerOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", rec.Code)
+	}
+	var status core.RelayStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.SrcToDst != saved.SrcToDst || status.DstToSrc != saved.DstToSrc || !status.LastRelayTime.Equal(saved.LastRelayTime) {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	snapshot := &core.PathSnapshot{SrcToDst: core.DirectionStats{PendingPackets: 4}}
+	snapshot.SetRelayStatus(&status)
+	if snapshot.SrcToDst.PendingPackets != 4 || snapshot.SrcToDst.RelayedPackets != 3 || snapshot.DstToSrc.Failed != 5 {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	if snapshot.LastRelayTime == nil || !snapshot.LastRelayTime.Equal(saved.LastRelayTime) {
+		t.Errorf("unexpected last relay time: %v", snapshot.LastRelayTime)
+	}
+
+	// the last relay time is not set before the first relay
+	snapshot = &core.PathSnapshot{}
+	snapshot.SetRelayStatus(&core.RelayStatus{})
+	if snapshot.LastRelayTime != nil {
+		t.Errorf("unexpected last relay time: %v", snapshot.LastRelayTime)
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// RelayEventType is the type of a relay event streamed to the subscribers of the event feed
+type RelayEventType string
+
+const (
+	RelayEventPacketRelayed  RelayEventType = "packet_relayed"
+	RelayEventAckRelayed     RelayEventType = "ack_relayed"
+	RelayEventTimeoutRelayed RelayEventType = "timeout_relayed"
+	RelayEventClientUpdated  RelayEventType = "client_updated"
+	RelayEventError          RelayEventType = "error"
+	// RelayEventClientRecoveryRequired is emitted when the path is paused because a client is expired or frozen
+	RelayEventClientRecoveryRequired RelayEventType = "client_recovery_required"
+)
+
+// ParseRelayEventTypes parses a comma-separated list of relay event types
+func ParseRelayEventTypes(s string) ([]RelayEventType, error) {
+	var types []RelayEventType
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		switch typ := RelayEventType(t); typ {
+		case RelayEventPacketRelayed, RelayEventAckRelayed, RelayEventTimeoutRelayed, RelayEventClientUpdated, RelayEventError, RelayEventClientRecoveryRequired:
+			types = append(types, typ)
+		default:
+			return nil, fmt.Errorf("unknown relay event type: %s", t)
+		}
+	}
+	return types, nil
+}
+
+// RelayEvent is a structured event of the relay service streamed in real time to external consumers
+type RelayEvent struct {
+	Type    RelayEventType `json:"type"`
+	Time    time.Time      `json:"time"`
+	ChainID string         `json:"chain_id,omitempty"` // chain to which the msg was submitted
+	MsgType string         `json:"msg_type,omitempty"`
+	TxID    string         `json:"tx_id,omitempty"`
+
+	// packet fields, which are set for the packet, ack and timeout events
+	SourcePort         string `json:"source_port,omitempty"`
+	SourceChannel      string `json:"source_channel,omitempty"`
+	DestinationPort    string `json:"destination_port,omitempty"`
+	DestinationChannel string `json:"destination_channel,omitempty"`
+	Sequence           uint64 `json:"sequence,omitempty"`
+
+	Transfer  *TransferInfo `json:"transfer,omitempty"`
+	AckResult AckResult     `json:"ack_result,omitempty"`
+
+	// ClientID is set for the client update and recovery events
+	ClientID string `json:"client_id,omitempty"`
+	// Recovery is set for the client recovery events
+	Recovery *ClientRecovery `json:"recovery,omitempty"`
+
+	// Error is set for the error events
+	Error string `json:"error,omitempty"`
+}
+
+// EventFeed broadcasts the relay events to the subscribers.
+// A subscriber that doesn't keep up with the events misses the events overflowing its buffer,
+// so that a slow consumer never blocks relaying.
+type EventFeed struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*eventSubscription
+}
+
+type eventSubscription struct {
+	ch    chan RelayEvent
+	types map[RelayEventType]bool // all the types are delivered if empty
+}
+
+// NewEventFeed returns an event feed without subscribers
+func NewEventFeed() *EventFeed {
+	return &EventFeed{subs: make(map[int]*eventSubscription)}
+}
+
+// Subscribe returns a channel receiving the events of `types` (all the types if empty) and a function to cancel the subscription.
+// The channel is closed when the subscription is canceled.
+func (f *EventFeed) Subscribe(buffer int, types ...RelayEventType) (<-chan RelayEvent, func()) {
+	sub := &eventSubscription{ch: make(chan RelayEvent, buffer), types: make(map[RelayEventType]bool)}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	f.mu.Lock()
+	id := f.nextID
+	f.nextID++
+	f.subs[id] = sub
+	f.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.subs, id)
+			f.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+// Publish delivers the event to the subscribers without blocking
+func (f *EventFeed) Publish(ev RelayEvent) {
+	if f == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, sub := range f.subs {
+		if len(sub.types) > 0 && !sub.types[ev.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			metrics.DroppedRelayEventsCounter.Add(context.TODO(), 1, api.WithAttributes(
+				attribute.Key("type").String(string(ev.Type)),
+			))
+		}
+	}
+}
+
+// Events returns the feed of the relay events of the service
+func (srv *RelayService) Events() *EventFeed {
+	return srv.events
+}
+
+// publishRelayEvents publishes the events of the msgs submitted to the chains, which are given as the journal entries of the packet msgs
+func (srv *RelayService) publishRelayEvents(msgs *RelayMsgs, entries []*JournalEntry) {
+	for _, e := range entries {
+		ev := RelayEvent{
+			Time:               e.Time,
+			ChainID:            e.ChainID,
+			MsgType:            e.MsgType,
+			TxID:               e.TxID,
+			SourcePort:         e.SourcePort,
+			SourceChannel:      e.SourceChannel,
+			DestinationPort:    e.DestinationPort,
+			DestinationChannel: e.DestinationChannel,
+			Sequence:           e.Sequence,
+			Transfer:           e.Transfer,
+			AckResult:          e.AckResult,
+		}
+		switch {
+		case !e.Success:
+			ev.Type = RelayEventError
+			ev.Error = "the msg was not included in a successful transaction"
+		case e.MsgType == sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}):
+			ev.Type = RelayEventPacketRelayed
+		case e.MsgType == sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}):
+			ev.Type = RelayEventAckRelayed
+		default:
+			ev.Type = RelayEventTimeoutRelayed
+		}
+		srv.events.Publish(ev)
+	}
+	for _, side := range []struct {
+		chain  ChainInfo
+		msgs   []sdk.Msg
+		msgIDs []MsgID
+	}{
+		{srv.src, msgs.Src, msgs.SrcMsgIDs},
+		{srv.dst, msgs.Dst, msgs.DstMsgIDs},
+	} {
+		for i, msg := range side.msgs {
+			msg, ok := msg.(*clienttypes.MsgUpdateClient)
+			if !ok || i >= len(side.msgIDs) || side.msgIDs[i] == nil {
+				continue
+			}
+			srv.events.Publish(RelayEvent{
+				Type:     RelayEventClientUpdated,
+				ChainID:  side.chain.ChainID(),
+				MsgType:  sdk.MsgTypeURL(msg),
+				TxID:     txIDOf(side.msgIDs[i]),
+				ClientID: msg.ClientId,
+			})
+		}
+	}
+}
+
+// publishError publishes an error of a relay cycle
+func (srv *RelayService) publishError(err error) {
+	srv.events.Publish(RelayEvent{Type: RelayEventError, Error: err.Error()})
+}
@@ -0,0 +1,59 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestEventFeed(t *testing.T) {
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	feed := core.NewEventFeed()
+	all, cancelAll := feed.Subscribe(2)
+	errs, cancelErrs := feed.Subscribe(2, core.RelayEventError)
+	defer cancelErrs()
+
+	feed.Publish(core.RelayEvent{Type: core.RelayEventPacketRelayed, Sequence: 1})
+	feed.Publish(core.RelayEvent{Type: core.RelayEventError, Error: "failed"})
+	// the buffer of `all` is full, so the event is dropped for it
+	feed.Publish(core.RelayEvent{Type: core.RelayEventAckRelayed, Sequence: 1})
+
+	if ev := <-all; ev.Type != core.RelayEventPacketRelayed || ev.Time.IsZero() {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev := <-all; ev.Type != core.RelayEventError {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev := <-errs; ev.Type != core.RelayEventError || ev.Error != "failed" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	select {
+	case ev := <-errs:
+		t.Errorf("the event of an unsubscribed type is delivered: %+v", ev)
+	default:
+	}
+
+	cancelAll()
+	cancelAll()
+	// the channel is closed without the dropped event
+	if ev, ok := <-all; ok {
+		t.Errorf("the dropped event is delivered: %+v", ev)
+	}
+	feed.Publish(core.RelayEvent{Type: core.RelayEventError})
+}
+
+func TestParseRelayEventTypes(t *testing.T) {
+	types, err := core.ParseRelayEventTypes("packet_relayed, error")
+	if err != nil || len(types) != 2 || types[0] != core.RelayEventPacketRelayed || types[1] != core.RelayEventError {
+		t.Errorf("unexpected result: %v, %v", types, err)
+	}
+	if types, err := core.ParseRelayEventTypes(""); err != nil || len(types) != 0 {
+		t.Errorf("unexpected result: %v, %v", types, err)
+	}
+	if _, err := core.ParseRelayEventTypes("packet_relayed,unknown"); err == nil {
+		t.Error("an unknown type is accepted")
+	}
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Chain modules translate their native events into the MsgEventLog types of this package,
+// so that strategies, filters and notifications (e.g. webhook payloads) don't depend on a chain module.
+// The names below identify the event types in such chain-agnostic representations.
+const (
+	EventNameClientCreated       = "client_created"
+	EventNameConnectionCreated   = "connection_created"
+	EventNameChannelCreated      = "channel_created"
+	EventNamePacketSent          = "packet_sent"
+	EventNamePacketReceived      = "packet_received"
+	EventNameAckWritten          = "ack_written"
+	EventNameAckReceived         = "ack_received"
+	EventNameClientUpdated       = "client_updated"
+	EventNameChannelStateChanged = "channel_state_changed"
+	EventNameUnknown             = "unknown"
+)
+
+// MsgEventName returns the chain-agnostic name of the event type
+func MsgEventName(ev MsgEventLog) string {
+	switch ev.(type) {
+	case *EventGenerateClientIdentifier:
+		return EventNameClientCreated
+	case *EventGenerateConnectionIdentifier:
+		return EventNameConnectionCreated
+	case *EventGenerateChannelIdentifier:
+		return EventNameChannelCreated
+	case *EventSendPacket:
+		return EventNamePacketSent
+	case *EventRecvPacket:
+		return EventNamePacketReceived
+	case *EventWriteAcknowledgement:
+		return EventNameAckWritten
+	case *EventAcknowledgePacket:
+		return EventNameAckReceived
+	case *EventUpdateClient:
+		return EventNameClientUpdated
+	case *EventChannelStateChange:
+		return EventNameChannelStateChanged
+	default:
+		return EventNameUnknown
+	}
+}
+
+// MsgEventPayload returns a JSON-friendly representation of the event, which has the event name as "type".
+// Bytes are hex-encoded, and heights and timestamps are formatted as strings.
+func MsgEventPayload(ev MsgEventLog) map[string]interface{} {
+	p := map[string]interface{}{"type": MsgEventName(ev)}
+	switch ev := ev.(type) {
+	case *EventGenerateClientIdentifier:
+		p["client_id"] = ev.ID
+	case *EventGenerateConnectionIdentifier:
+		p["connection_id"] = ev.ID
+	case *EventGenerateChannelIdentifier:
+		p["channel_id"] = ev.ID
+	case *EventSendPacket:
+		p["sequence"] = ev.Sequence
+		p["src_port"] = ev.SrcPort
+		p["src_channel"] = ev.SrcChannel
+		p["timeout_height"] = ev.TimeoutHeight.String()
+		p["timeout_timestamp"] = ev.TimeoutTimestamp.UTC().String()
+		p["data"] = hex.EncodeToString(ev.Data)
+	case *EventRecvPacket:
+		p["sequence"] = ev.Sequence
+		p["dst_port"] = ev.DstPort
+		p["dst_channel"] = ev.DstChannel
+		p["timeout_height"] = ev.TimeoutHeight.String()
+		p["timeout_timestamp"] = ev.TimeoutTimestamp.UTC().String()
+		p["data"] = hex.EncodeToString(ev.Data)
+	case *EventWriteAcknowledgement:
+		p["sequence"] = ev.Sequence
+		p["dst_port"] = ev.DstPort
+		p["dst_channel"] = ev.DstChannel
+		p["acknowledgement"] = hex.EncodeToString(ev.Acknowledgement)
+	case *EventAcknowledgePacket:
+		p["sequence"] = ev.Sequence
+		p["src_port"] = ev.SrcPort
+		p["src_channel"] = ev.SrcChannel
+		p["timeout_height"] = ev.TimeoutHeight.String()
+		p["timeout_timestamp"] = ev.TimeoutTimestamp.UTC().String()
+	case *EventUpdateClient:
+		heights := make([]string, len(ev.ConsensusHeights))
+		for i, h := range ev.ConsensusHeights {
+			heights[i] = h.String()
+		}
+		p["client_id"] = ev.ClientID
+		p["client_type"] = ev.ClientType
+		p["consensus_heights"] = heights
+	case *EventChannelStateChange:
+		p["port_id"] = ev.PortID
+		p["channel_id"] = ev.ChannelID
+		p["counterparty_port_id"] = ev.CounterpartyPortID
+		p["counterparty_channel_id"] = ev.CounterpartyChannelID
+		p["connection_id"] = ev.ConnectionID
+		p["state"] = ev.State.String()
+	case *EventUnknown:
+		p["value"] = fmt.Sprint(ev.Value)
+	}
+	return p
+}
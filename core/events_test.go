@@ -0,0 +1,40 @@
+package core_test
+
+import (
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestMsgEventPayload(t *testing.T) {
+	cases := []struct {
+		event    core.MsgEventLog
+		name     string
+		key      string
+		expected interface{}
+	}{
+		{&core.EventSendPacket{Sequence: 1, SrcPort: "transfer", SrcChannel: "channel-0", Data: []byte{0xab}}, core.EventNamePacketSent, "data", "ab"},
+		{&core.EventWriteAcknowledgement{Sequence: 1, DstPort: "transfer", DstChannel: "channel-1"}, core.EventNameAckWritten, "dst_channel", "channel-1"},
+		{&core.EventChannelStateChange{PortID: "transfer", ChannelID: "channel-0", State: chantypes.OPEN}, core.EventNameChannelStateChanged, "state", "STATE_OPEN"},
+		{&core.EventUnknown{Value: "foo"}, core.EventNameUnknown, "value", "foo"},
+	}
+	for i, c := range cases {
+		p := core.MsgEventPayload(c.event)
+		if p["type"] != c.name {
+			t.Errorf("case %d: unexpected type: actual=%v, expected=%v", i, p["type"], c.name)
+		}
+		if p[c.key] != c.expected {
+			t.Errorf("case %d: unexpected %s: actual=%v, expected=%v", i, c.key, p[c.key], c.expected)
+		}
+	}
+
+	p := core.MsgEventPayload(&core.EventUpdateClient{
+		ClientID:         "07-tendermint-0",
+		ConsensusHeights: []clienttypes.Height{clienttypes.NewHeight(1, 10), clienttypes.NewHeight(1, 11)},
+	})
+	if heights := p["consensus_heights"].([]string); len(heights) != 2 || heights[1] != "1-11" {
+		t.Errorf("unexpected consensus heights: %v", heights)
+	}
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// ErrTxRejected is wrapped by the errors of SendMsgs if the chain rejected the transaction (e.g. CheckTx or DeliverTx failed),
+// as opposed to the transaction not reaching the chain
+var ErrTxRejected = errors.New("tx rejected")
+
+// FailureKind classifies the failure of a command so that the scripts running the relayer can branch on it
+type FailureKind string
+
+const (
+	// FailureConfig is a failure caused by the config, the flags or the arguments
+	FailureConfig FailureKind = "config"
+	// FailureChainUnreachable is a failure to connect to a chain node
+	FailureChainUnreachable FailureKind = "chain_unreachable"
+	// FailureHandshakeTimeout is a handshake or a relay not completed within the deadline
+	FailureHandshakeTimeout FailureKind = "handshake_timeout"
+	// FailureTxRejected is a transaction rejected by a chain
+	FailureTxRejected FailureKind = "tx_rejected"
+	// FailureUnknown is any other failure
+	FailureUnknown FailureKind = "unknown"
+)
+
+// ExitCode returns the exit code of the process failed by the kind of failure
+func (k FailureKind) ExitCode() int {
+	switch k {
+	case FailureConfig:
+		return 2
+	case FailureChainUnreachable:
+		return 3
+	case FailureHandshakeTimeout:
+		return 4
+	case FailureTxRejected:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// Failure is an error whose kind is known where it occurs, e.g. an invalid config
+type Failure struct {
+	Kind FailureKind
+	Err  error
+}
+
+// NewFailure returns an error of `err` classified as `kind`
+func NewFailure(kind FailureKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Failure{Kind: kind, Err: err}
+}
+
+func (f *Failure) Error() string {
+	return f.Err.Error()
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// FailureKindOf classifies the error of a command. An explicit Failure in the chain of the error takes precedence,
+// and otherwise the kind is inferred from the well-known errors wrapped by it.
+func FailureKindOf(err error) FailureKind {
+	if err == nil {
+		return ""
+	}
+	var f *Failure
+	if errors.As(err, &f) {
+		return f.Kind
+	}
+	// context.DeadlineExceeded also implements net.Error, so the deadline is checked first
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailureHandshakeTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return FailureChainUnreachable
+	}
+	var msgErr *MsgFailureError
+	if errors.Is(err, ErrTxRejected) || errors.As(err, &msgErr) {
+		return FailureTxRejected
+	}
+	return FailureUnknown
+}
+
+// FailureSummary is the machine-readable summary of a failed command
+type FailureSummary struct {
+	Kind     FailureKind `json:"kind"`
+	ExitCode int         `json:"exit_code"`
+	Error    string      `json:"error"`
+	// Command is the full command path (e.g. "yrly tx link")
+	Command string    `json:"command,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// NewFailureSummary returns the summary of the command failed with `err`
+func NewFailureSummary(command string, err error) *FailureSummary {
+	kind := FailureKindOf(err)
+	return &FailureSummary{
+		Kind:     kind,
+		ExitCode: kind.ExitCode(),
+		Error:    err.Error(),
+		Command:  command,
+		Time:     time.Now().UTC(),
+	}
+}
+
+// WriteFile writes the summary to `path` in JSON
+func (s *FailureSummary) WriteFile(path string) error {
+	bz, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path, append(bz, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write the failure summary: %w", err)
+	}
+	return nil
+}
+
+// withSendErrors wraps the errors of the transactions failed in the last Send of `msgs` into `err`,
+// so that the failure of a handshake can be classified by the errors of the chains
+func withSendErrors(err error, msgs *RelayMsgs) error {
+	if sendErr := msgs.Err(); sendErr != nil {
+		return fmt.Errorf("%w: %w", err, sendErr)
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestFailureKindOf(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	cases := map[string]struct {
+		err  error
+		kind core.FailureKind
+	}{
+		"config":            {fmt.Errorf("cmd: %w", core.NewFailure(core.FailureConfig, errors.New("path not found"))), core.FailureConfig},
+		"deadline":          {fmt.Errorf("connection handshake aborted: %w: INIT <=> UNINITIALIZED", context.DeadlineExceeded), core.FailureHandshakeTimeout},
+		"connection":        {fmt.Errorf("post failed: %w", refused), core.FailureChainUnreachable},
+		"check tx":          {fmt.Errorf("%w: CheckTx failed: out of gas", core.ErrTxRejected), core.FailureTxRejected},
+		"msg failure":       {&core.MsgFailureError{MsgIndex: 1, Reason: "packet already received"}, core.FailureTxRejected},
+		"joined send error": {fmt.Errorf("! Channel failed: %w", errors.Join(errors.New("account sequence mismatch"), core.ErrTxRejected)), core.FailureTxRejected},
+		"other":             {errors.New("something went wrong"), core.FailureUnknown},
+	}
+	codes := make(map[int]core.FailureKind)
+	for name, c := range cases {
+		kind := core.FailureKindOf(c.err)
+		if kind != c.kind {
+			t.Errorf("%s: unexpected kind: expected=%s actual=%s", name, c.kind, kind)
+		}
+		if other, ok := codes[kind.ExitCode()]; ok && other != kind {
+			t.Errorf("%s: the exit code %d is shared by %s and %s", name, kind.ExitCode(), kind, other)
+		}
+		codes[kind.ExitCode()] = kind
+	}
+	if core.FailureKindOf(nil) != "" {
+		t.Error("nil is classified as a failure")
+	}
+}
+
+func TestFailureSummary(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "failure.json")
+	err := fmt.Errorf("failed to relay the packets: %w", core.ErrTxRejected)
+	if err := core.NewFailureSummary("yrly tx relay", err).WriteFile(file); err != nil {
+		t.Fatal(err)
+	}
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary core.FailureSummary
+	if err := json.Unmarshal(bz, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Kind != core.FailureTxRejected || summary.ExitCode != 5 || summary.Command != "yrly tx relay" || summary.Error == "" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
@@ -0,0 +1,300 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// types of the faucet backends
+const (
+	// FaucetHTTP requests the coins from an HTTP faucet (e.g. the faucet service of a testnet)
+	FaucetHTTP = "http"
+	// FaucetKey sends the coins from a funded key in the keyring of the chain
+	FaucetKey = "key"
+)
+
+// FaucetCfg configures the faucet topping up the relayer account on a testnet.
+// The relay service requests the faucet when the balance of the relayer account falls below MinBalance,
+// and `dev faucet-request` requests it on demand.
+type FaucetCfg struct {
+	// Type is the faucet backend: "http" or "key"
+	Type string `json:"type" yaml:"type"`
+
+	// URL is the endpoint of the HTTP faucet receiving a POST request with the FaucetRequest in JSON (type "http")
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Key is the name of the funded key in the keyring of the chain sending the coins (type "key").
+	// The chain must implement FundsSender.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// Amount is the coins requested at a time (e.g. "10000000stake")
+	Amount string `json:"amount" yaml:"amount"`
+
+	// MinBalance is the balance below which the relay service requests the faucet automatically.
+	// The faucet is requested if the balance of any denom is below the amount of the denom. The faucet is only requested on demand if empty.
+	MinBalance string `json:"min-balance,omitempty" yaml:"min-balance,omitempty"`
+
+	// CheckInterval is the interval of the balance checks by the relay service (default: "1m")
+	CheckInterval string `json:"check-interval,omitempty" yaml:"check-interval,omitempty"`
+
+	// Cooldown is the minimum interval between the automatic requests, which gives the faucet the time to deliver the coins (default: "10m")
+	Cooldown string `json:"cooldown,omitempty" yaml:"cooldown,omitempty"`
+
+	// Timeout is the timeout of a request to the HTTP faucet (default: "30s")
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *FaucetCfg) Validate() error {
+	switch cfg.Type {
+	case FaucetHTTP:
+		if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+			return fmt.Errorf("faucet: invalid url: %q", cfg.URL)
+		}
+	case FaucetKey:
+		if cfg.Key == "" {
+			return fmt.Errorf("faucet: key must be specified")
+		}
+	default:
+		return fmt.Errorf("faucet: unknown type: %q", cfg.Type)
+	}
+	if coins, err := sdk.ParseCoinsNormalized(cfg.Amount); err != nil {
+		return fmt.Errorf("faucet: invalid amount: %w", err)
+	} else if coins.IsZero() {
+		return fmt.Errorf("faucet: amount must be positive")
+	}
+	if _, err := sdk.ParseCoinsNormalized(cfg.MinBalance); err != nil {
+		return fmt.Errorf("faucet: invalid min-balance: %w", err)
+	}
+	for name, d := range map[string]string{"check-interval": cfg.CheckInterval, "cooldown": cfg.Cooldown, "timeout": cfg.Timeout} {
+		if d == "" {
+			continue
+		}
+		if v, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("faucet: invalid %s: %w", name, err)
+		} else if v <= 0 {
+			return fmt.Errorf("faucet: %s must be positive: %v", name, v)
+		}
+	}
+	return nil
+}
+
+func (cfg *FaucetCfg) duration(d string, defaultValue time.Duration) time.Duration {
+	if d == "" {
+		return defaultValue
+	}
+	v, _ := time.ParseDuration(d)
+	return v
+}
+
+// Build returns the faucet backend of the config
+func (cfg *FaucetCfg) Build() (Faucet, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Type {
+	case FaucetHTTP:
+		return &httpFaucet{url: cfg.URL, client: &http.Client{Timeout: cfg.duration(cfg.Timeout, 30*time.Second)}}, nil
+	default:
+		return &keyFaucet{key: cfg.Key}, nil
+	}
+}
+
+// IsLow returns true if `balance` is below the min balance of the config
+func (cfg *FaucetCfg) IsLow(balance sdk.Coins) bool {
+	minBalance, _ := sdk.ParseCoinsNormalized(cfg.MinBalance)
+	for _, coin := range minBalance {
+		if balance.AmountOf(coin.Denom).LT(coin.Amount) {
+			return true
+		}
+	}
+	return false
+}
+
+// Faucet is a backend sending coins to an account
+type Faucet interface {
+	// Request requests `amount` to be sent to `address` on the chain and returns the ID of the tx sending them if known
+	Request(ctx context.Context, chain *ProvableChain, address sdk.AccAddress, amount sdk.Coins) (string, error)
+}
+
+// FundsSender is an optional interface of Chain to send coins from a key other than the relayer key in the keyring of the chain
+type FundsSender interface {
+	// SendFunds sends `amount` from the key `fromKey` to `to` and returns the ID of the tx
+	SendFunds(ctx context.Context, fromKey string, to sdk.AccAddress, amount sdk.Coins) (string, error)
+}
+
+// FaucetRequest is the body of the request to the HTTP faucet
+type FaucetRequest struct {
+	ChainID string `json:"chain_id"`
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+type httpFaucet struct {
+	url    string
+	client *http.Client
+}
+
+// Request implements Faucet. The tx sent by the HTTP faucet is unknown to the relayer.
+func (f *httpFaucet) Request(ctx context.Context, chain *ProvableChain, address sdk.AccAddress, amount sdk.Coins) (string, error) {
+	req := &FaucetRequest{ChainID: chain.ChainID(), Address: address.String(), Amount: amount.String()}
+	if err := postWebhook(ctx, f.client, f.url, req); err != nil {
+		return "", fmt.Errorf("faucet request failed: %w", err)
+	}
+	return "", nil
+}
+
+type keyFaucet struct {
+	key string
+}
+
+// Request implements Faucet
+func (f *keyFaucet) Request(ctx context.Context, chain *ProvableChain, address sdk.AccAddress, amount sdk.Coins) (string, error) {
+	sender, ok := chain.Chain.(FundsSender)
+	if !ok {
+		return "", fmt.Errorf("chain %s doesn't support sending the funds from a key: %T", chain.ChainID(), chain.Chain)
+	}
+	return sender.SendFunds(ctx, f.key, address, amount)
+}
+
+// FaucetResult is the result of a faucet request
+type FaucetResult struct {
+	ChainID string `json:"chain_id"`
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	// TxID is the ID of the tx sending the coins, which is empty if unknown (e.g. sent by an HTTP faucet)
+	TxID string `json:"tx_id,omitempty"`
+}
+
+// RequestFaucet requests the faucet of the config to top up the relayer account of the chain
+func RequestFaucet(ctx context.Context, chain *ProvableChain, cfg *FaucetCfg) (*FaucetResult, error) {
+	faucet, err := cfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	amount, err := sdk.ParseCoinsNormalized(cfg.Amount)
+	if err != nil {
+		return nil, err
+	}
+	address, err := chain.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	txID, err := faucet.Request(ctx, chain, address, amount)
+	metrics.FaucetRequestsCounter.Add(ctx, 1, api.WithAttributes(
+		attribute.Key("chain_id").String(chain.ChainID()),
+		attribute.Key("success").Bool(err == nil),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetResult{ChainID: chain.ChainID(), Address: address.String(), Amount: amount.String(), TxID: txID}, nil
+}
+
+// balanceMonitor checks the balances of the relayer accounts periodically and requests the faucets for the low balances
+type balanceMonitor struct {
+	// faucet configs by chain ID
+	cfgs map[string]*FaucetCfg
+
+	mu sync.Mutex
+	// times of the last balance checks and the last faucet requests by chain ID
+	lastChecks   map[string]time.Time
+	lastRequests map[string]time.Time
+}
+
+// SetFaucets sets the faucet configs by chain ID to top up the relayer accounts whose balances fall below the min balances.
+// The configs without the min balance are ignored.
+func (srv *RelayService) SetFaucets(cfgs map[string]*FaucetCfg) {
+	m := &balanceMonitor{cfgs: make(map[string]*FaucetCfg), lastChecks: make(map[string]time.Time), lastRequests: make(map[string]time.Time)}
+	for chainID, cfg := range cfgs {
+		if cfg.MinBalance != "" {
+			m.cfgs[chainID] = cfg
+		}
+	}
+	if len(m.cfgs) == 0 {
+		m = nil
+	}
+	srv.balances = m
+}
+
+// checkBalances checks the balances of the relayer accounts of the chains and requests the faucets for the low balances.
+// The faucets are requested in the background not to delay the relay.
+func (srv *RelayService) checkBalances(ctx context.Context) {
+	if srv.balances == nil {
+		return
+	}
+	for _, chain := range []*ProvableChain{srv.src, srv.dst} {
+		if cfg, ok := srv.balances.due(chain.ChainID(), time.Now()); ok {
+			srv.checkBalance(ctx, chain, cfg)
+		}
+	}
+}
+
+// due returns the faucet config of the chain if its balance check is due
+func (m *balanceMonitor) due(chainID string, now time.Time) (*FaucetCfg, bool) {
+	cfg, ok := m.cfgs[chainID]
+	if !ok {
+		return nil, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if last, ok := m.lastChecks[chainID]; ok && now.Sub(last) < cfg.duration(cfg.CheckInterval, time.Minute) {
+		return nil, false
+	}
+	m.lastChecks[chainID] = now
+	return cfg, true
+}
+
+// startRequest returns true if the faucet of the chain can be requested, i.e. the cooldown has elapsed since the last request
+func (m *balanceMonitor) startRequest(chainID string, cfg *FaucetCfg, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if last, ok := m.lastRequests[chainID]; ok && now.Sub(last) < cfg.duration(cfg.Cooldown, 10*time.Minute) {
+		return false
+	}
+	m.lastRequests[chainID] = now
+	return true
+}
+
+func (srv *RelayService) checkBalance(ctx context.Context, chain *ProvableChain, cfg *FaucetCfg) {
+	logger := GetChainLogger(chain)
+	addr, err := chain.GetAddress()
+	if err != nil {
+		logger.Error("failed to get the relayer address to check the balance", err)
+		return
+	}
+	height, err := chain.LatestHeight()
+	if err != nil {
+		logger.Error("failed to get the latest height to check the balance", err)
+		return
+	}
+	balance, err := chain.QueryBalance(NewQueryContext(ctx, height), addr)
+	if err != nil {
+		logger.Error("failed to query the balance of the relayer account", err)
+		return
+	}
+	if !cfg.IsLow(balance) {
+		return
+	}
+	logger.Warn("the balance of the relayer account is low", "address", addr.String(), "balance", balance.String(), "min_balance", cfg.MinBalance)
+	if !srv.balances.startRequest(chain.ChainID(), cfg, time.Now()) {
+		return
+	}
+	go func() {
+		res, err := RequestFaucet(context.TODO(), chain, cfg)
+		if err != nil {
+			logger.Error("failed to request the faucet", err)
+			return
+		}
+		logger.Info("requested the faucet to top up the relayer account", "address", res.Address, "amount", res.Amount, "tx_id", res.TxID)
+	}()
+}
@@ -0,0 +1,139 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// fundedKeyChain is a mock chain recording the funds sent from the keys
+type fundedKeyChain struct {
+	*mock.Chain
+	sent []string
+}
+
+func (c *fundedKeyChain) SendFunds(ctx context.Context, fromKey string, to sdk.AccAddress, amount sdk.Coins) (string, error) {
+	c.sent = append(c.sent, fromKey+":"+amount.String())
+	return "tx-hash", nil
+}
+
+func TestFaucetCfgValidate(t *testing.T) {
+	cases := map[string]struct {
+		cfg   core.FaucetCfg
+		valid bool
+	}{
+		"http":             {core.FaucetCfg{Type: core.FaucetHTTP, URL: "https://faucet.example.com", Amount: "100stake", MinBalance: "10stake", Cooldown: "1h"}, true},
+		"key":              {core.FaucetCfg{Type: core.FaucetKey, Key: "faucet", Amount: "100stake"}, true},
+		"unknown type":     {core.FaucetCfg{Type: "grpc", Amount: "100stake"}, false},
+		"invalid url":      {core.FaucetCfg{Type: core.FaucetHTTP, URL: "faucet.example.com", Amount: "100stake"}, false},
+		"no key":           {core.FaucetCfg{Type: core.FaucetKey, Amount: "100stake"}, false},
+		"no amount":        {core.FaucetCfg{Type: core.FaucetKey, Key: "faucet"}, false},
+		"invalid balance":  {core.FaucetCfg{Type: core.FaucetKey, Key: "faucet", Amount: "100stake", MinBalance: "stake"}, false},
+		"invalid interval": {core.FaucetCfg{Type: core.FaucetKey, Key: "faucet", Amount: "100stake", CheckInterval: "0s"}, false},
+	}
+	for name, c := range cases {
+		if err := c.cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected result: %v", name, err)
+		}
+	}
+
+	cfg := &core.FaucetCfg{MinBalance: "10stake,5uatom"}
+	if cfg.IsLow(sdk.NewCoins(sdk.NewInt64Coin("stake", 10), sdk.NewInt64Coin("uatom", 5))) {
+		t.Error("the balance reaching the min balance is low")
+	}
+	if !cfg.IsLow(sdk.NewCoins(sdk.NewInt64Coin("stake", 100))) {
+		t.Error("the balance missing a denom is not low")
+	}
+}
+
+func TestRequestFaucet(t *testing.T) {
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	var funded *fundedKeyChain
+	_, pc := newMockChain(t, "ibc0", func(chain *mock.Chain, prover *mockprover.Prover) (core.Chain, core.Prover) {
+		funded = &fundedKeyChain{Chain: chain}
+		return funded, prover
+	})
+
+	res, err := core.RequestFaucet(context.TODO(), pc, &core.FaucetCfg{Type: core.FaucetKey, Key: "faucet", Amount: "100stake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TxID != "tx-hash" || len(funded.sent) != 1 || funded.sent[0] != "faucet:100stake" {
+		t.Errorf("unexpected result: %+v, %v", res, funded.sent)
+	}
+
+	// the key faucet requires the chain to send the funds
+	_, plain := newMockChain(t, "ibc0", nil)
+	if _, err := core.RequestFaucet(context.TODO(), plain, &core.FaucetCfg{Type: core.FaucetKey, Key: "faucet", Amount: "100stake"}); err == nil {
+		t.Error("the funds are sent from a chain without the keys")
+	}
+
+	faucet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer faucet.Close()
+	if _, err := core.RequestFaucet(context.TODO(), pc, &core.FaucetCfg{Type: core.FaucetHTTP, URL: faucet.URL, Amount: "100stake"}); err == nil {
+		t.Error("the rejected faucet request succeeds")
+	}
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	requests := make(chan core.FaucetRequest, 4)
+	faucet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req core.FaucetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		requests <- req
+	}))
+	defer faucet.Close()
+
+	chains, sh := newScriptedChains(t)
+	srv := core.NewRelayService(&scriptedStrategy{}, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	srv.SetFaucets(map[string]*core.FaucetCfg{
+		"ibc0": {Type: core.FaucetHTTP, URL: faucet.URL, Amount: "100stake", MinBalance: "10stake"},
+		// not requested automatically without the min balance
+		"ibc1": {Type: core.FaucetHTTP, URL: faucet.URL, Amount: "100stake"},
+	})
+
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case req := <-requests:
+		addr, _ := chains[0].GetAddress()
+		if req.ChainID != "ibc0" || req.Address != addr.String() || req.Amount != "100stake" {
+			t.Errorf("unexpected faucet request: %+v", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the faucet is not requested")
+	}
+
+	// the balance is not checked again until the check interval elapses
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case req := <-requests:
+		t.Errorf("unexpected faucet request: %+v", req)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// ErrInjectedFault is returned by the operations failed by the fault injection
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultInjectionCfg injects artificial failures into the chains, with which the e2e tests verify that the retries,
+// the backoffs and the recoveries of the relayer behave as designed. It must never be set in production.
+type FaultInjectionCfg struct {
+	// Chains are the IDs of the chains into which the failures are injected (all the chains if empty)
+	Chains []string `json:"chains,omitempty" yaml:"chains,omitempty"`
+	// BroadcastDropRate is the probability (from 0 to 1) that the msgs are dropped without being broadcasted and SendMsgs fails
+	BroadcastDropRate float64 `json:"broadcast-drop-rate,omitempty" yaml:"broadcast-drop-rate,omitempty"`
+	// QueryDelay is the delay added to every query of the IBC states (e.g. "500ms")
+	QueryDelay string `json:"query-delay,omitempty" yaml:"query-delay,omitempty"`
+	// StaleHeightRate is the probability (from 0 to 1) that LatestHeight returns a height behind the latest one by StaleHeightLag
+	StaleHeightRate float64 `json:"stale-height-rate,omitempty" yaml:"stale-height-rate,omitempty"`
+	// StaleHeightLag is the number of blocks by which a stale height is behind the latest one (1 if not specified)
+	StaleHeightLag uint64 `json:"stale-height-lag,omitempty" yaml:"stale-height-lag,omitempty"`
+	// Seed is the seed of the random failures, which makes them reproducible (a random seed is used if it is 0)
+	Seed int64 `json:"seed,omitempty" yaml:"seed,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *FaultInjectionCfg) Validate() error {
+	if cfg.BroadcastDropRate < 0 || cfg.BroadcastDropRate > 1 {
+		return fmt.Errorf("fault-injection: broadcast-drop-rate must be between 0 and 1: %v", cfg.BroadcastDropRate)
+	}
+	if cfg.StaleHeightRate < 0 || cfg.StaleHeightRate > 1 {
+		return fmt.Errorf("fault-injection: stale-height-rate must be between 0 and 1: %v", cfg.StaleHeightRate)
+	}
+	if cfg.QueryDelay != "" {
+		if d, err := time.ParseDuration(cfg.QueryDelay); err != nil || d < 0 {
+			return fmt.Errorf("fault-injection: invalid query-delay: %s", cfg.QueryDelay)
+		}
+	}
+	return nil
+}
+
+// Targets returns whether the failures are injected into the chain `chainID`
+func (cfg *FaultInjectionCfg) Targets(chainID string) bool {
+	if len(cfg.Chains) == 0 {
+		return true
+	}
+	for _, id := range cfg.Chains {
+		if id == chainID {
+			return true
+		}
+	}
+	return false
+}
+
+type faultInjector struct {
+	cfg        FaultInjectionCfg
+	queryDelay time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// hit returns true with the probability `rate`
+func (f *faultInjector) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rand.Float64() < rate
+}
+
+// InjectFaults injects the failures of `cfg` into the operations of the chain called through the ProvableChain:
+// SendMsgs, LatestHeight and the queries of the IBC states. The calls through the Chain of the ProvableChain are not affected.
+func (pc *ProvableChain) InjectFaults(cfg *FaultInjectionCfg) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	f := &faultInjector{cfg: *cfg}
+	if cfg.QueryDelay != "" {
+		f.queryDelay, _ = time.ParseDuration(cfg.QueryDelay)
+	}
+	if f.cfg.StaleHeightLag == 0 {
+		f.cfg.StaleHeightLag = 1
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	f.rand = rand.New(rand.NewSource(seed))
+	pc.faults = f
+	GetChainLogger(pc.Chain).Warn("fault injection is enabled: never use it in production",
+		"broadcast_drop_rate", f.cfg.BroadcastDropRate,
+		"query_delay", f.queryDelay,
+		"stale_height_rate", f.cfg.StaleHeightRate,
+		"stale_height_lag", f.cfg.StaleHeightLag,
+		"seed", seed,
+	)
+	return nil
+}
+
+// LatestHeight returns the latest height of the chain, or a stale height if it is injected
+func (pc *ProvableChain) LatestHeight() (ibcexported.Height, error) {
+	height, err := pc.Chain.LatestHeight()
+	if err != nil || pc.faults == nil || !pc.faults.hit(pc.faults.cfg.StaleHeightRate) {
+		return height, err
+	}
+	lag := pc.faults.cfg.StaleHeightLag
+	if height.GetRevisionHeight() <= lag {
+		return height, nil
+	}
+	stale := clienttypes.NewHeight(height.GetRevisionNumber(), height.GetRevisionHeight()-lag)
+	GetChainLogger(pc.Chain).Warn("injected a stale height", "latest_height", height, "stale_height", stale)
+	return stale, nil
+}
+
+// dropBroadcast returns an error if a broadcast drop is injected
+func (pc *ProvableChain) dropBroadcast(msgs []sdk.Msg) error {
+	if pc.faults != nil && pc.faults.hit(pc.faults.cfg.BroadcastDropRate) {
+		GetChainLogger(pc.Chain).Warn("injected a broadcast drop", "msg_count", len(msgs))
+		return fmt.Errorf("%w: the msgs were dropped without being broadcasted", ErrInjectedFault)
+	}
+	return nil
+}
+
+// delayQuery waits for the query delay injected into the chain
+func (pc *ProvableChain) delayQuery(ctx QueryContext) error {
+	if pc.faults == nil || pc.faults.queryDelay == 0 {
+		return nil
+	}
+	select {
+	case <-time.After(pc.faults.queryDelay):
+		return nil
+	case <-ctx.Context().Done():
+		return ctx.Context().Err()
+	}
+}
+
+// QueryClientConsensusState calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryClientConsensusState(ctx QueryContext, dstClientConsHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryClientConsensusState(ctx, dstClientConsHeight)
+}
+
+// QueryConnection calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryConnection(ctx QueryContext) (*conntypes.QueryConnectionResponse, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryConnection(ctx)
+}
+
+// QueryChannel calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryChannel(ctx QueryContext) (*chantypes.QueryChannelResponse, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryChannel(ctx)
+}
+
+// QueryUnreceivedPackets calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryUnreceivedPackets(ctx QueryContext, seqs []uint64) ([]uint64, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryUnreceivedPackets(ctx, seqs)
+}
+
+// QueryUnfinalizedRelayPackets calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryUnfinalizedRelayPackets(ctx QueryContext, counterparty LightClientICS04Querier) (PacketInfoList, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryUnfinalizedRelayPackets(ctx, counterparty)
+}
+
+// QueryUnreceivedAcknowledgements calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryUnreceivedAcknowledgements(ctx QueryContext, seqs []uint64) ([]uint64, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryUnreceivedAcknowledgements(ctx, seqs)
+}
+
+// QueryUnfinalizedRelayAcknowledgements calls the query of the chain after the injected delay
+func (pc *ProvableChain) QueryUnfinalizedRelayAcknowledgements(ctx QueryContext, counterparty LightClientICS04Querier) (PacketInfoList, error) {
+	if err := pc.delayQuery(ctx); err != nil {
+		return nil, err
+	}
+	return pc.Chain.QueryUnfinalizedRelayAcknowledgements(ctx, counterparty)
+}
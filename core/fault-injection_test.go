@@ -0,0 +1,63 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+func TestFaultInjection(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	chain, pc := newMockChain(t, "ibc0", nil)
+	for i := 0; i < 3; i++ {
+		if _, err := pc.SendMsgs(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	latest, err := chain.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pc.InjectFaults(&core.FaultInjectionCfg{BroadcastDropRate: 1.5}); err == nil {
+		t.Error("invalid broadcast-drop-rate is accepted")
+	}
+	if err := pc.InjectFaults(&core.FaultInjectionCfg{QueryDelay: "soon"}); err == nil {
+		t.Error("invalid query-delay is accepted")
+	}
+
+	cfg := &core.FaultInjectionCfg{BroadcastDropRate: 1, StaleHeightRate: 1, StaleHeightLag: 2, QueryDelay: "1h", Seed: 1}
+	if err := pc.InjectFaults(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pc.SendMsgs(nil); !errors.Is(err, core.ErrInjectedFault) {
+		t.Errorf("the broadcast is not dropped: %v", err)
+	}
+	if h, err := chain.LatestHeight(); err != nil {
+		t.Fatal(err)
+	} else if !h.EQ(latest) {
+		t.Errorf("the dropped msgs are broadcasted: height=%v", h)
+	}
+	stale, err := pc.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale.GetRevisionHeight() != latest.GetRevisionHeight()-2 {
+		t.Errorf("unexpected stale height: actual=%v, latest=%v", stale, latest)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pc.QueryChannel(core.NewQueryContext(ctx, latest)); !errors.Is(err, context.Canceled) {
+		t.Errorf("the query is not delayed: %v", err)
+	}
+
+	if !cfg.Targets("ibc0") || (&core.FaultInjectionCfg{Chains: []string{"ibc1"}}).Targets("ibc0") {
+		t.Error("unexpected target chains")
+	}
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	feetypes "github.com/cosmos/ibc-go/v7/modules/apps/29-fee/types"
+)
+
+// FeePayeeCfg configures the payout addresses registered to the ICS-29 fee middleware on the fee-enabled channels of a path.
+// The payees are registered automatically when the relay service detects a fee-enabled channel.
+type FeePayeeCfg struct {
+	// SrcPayee is the address on the src chain receiving the fees paid on the src chain (i.e. the ack and timeout fees
+	// of the packets sent from the src chain and the recv fees of the packets relayed to the dst chain).
+	// The relayer address on the src chain is used if empty.
+	SrcPayee string `yaml:"src-payee,omitempty" json:"src-payee,omitempty"`
+
+	// DstPayee is the address on the dst chain receiving the fees paid on the dst chain.
+	// The relayer address on the dst chain is used if empty.
+	DstPayee string `yaml:"dst-payee,omitempty" json:"dst-payee,omitempty"`
+
+	// Disabled disables the automatic registration of the payees
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// FeePayeeQuerier is an optional interface of Chain supporting the ICS-29 fee middleware,
+// which tells the payees registered for a relayer on the channel currently set to the chain.
+// The payees can only be registered automatically on chains implementing it.
+type FeePayeeQuerier interface {
+	// QueryPayee returns the payee of the ack and timeout fees registered for the relayer, or an empty string if not registered
+	QueryPayee(ctx QueryContext, relayer string) (string, error)
+
+	// QueryCounterpartyPayee returns the payee on the counterparty chain of the recv fees registered for the relayer,
+	// or an empty string if not registered
+	QueryCounterpartyPayee(ctx QueryContext, relayer string) (string, error)
+}
+
+// RegisterFeePayees registers the payees of the relayers to the fee middleware on both ends of the channel set to the chains
+// if the channel is fee-enabled. The msgs are submitted only for the payees that differ from the registered ones,
+// so calling it repeatedly is idempotent. It returns false if the channel is not fee-enabled.
+func RegisterFeePayees(src, dst *ProvableChain, cfg *FeePayeeCfg) (bool, error) {
+	h, err := src.LatestHeight()
+	if err != nil {
+		return false, err
+	}
+	ch, err := src.QueryChannel(NewQueryContext(context.TODO(), h))
+	if err != nil {
+		return false, err
+	}
+	version, err := ParseChannelVersion(ch.Channel.Version)
+	if err != nil {
+		return false, err
+	}
+	if !version.FeeEnabled() {
+		return false, nil
+	}
+
+	srcAddr, err := src.GetAddress()
+	if err != nil {
+		return true, err
+	}
+	dstAddr, err := dst.GetAddress()
+	if err != nil {
+		return true, err
+	}
+	srcPayee, dstPayee := srcAddr.String(), dstAddr.String()
+	if cfg.SrcPayee != "" {
+		srcPayee = cfg.SrcPayee
+	}
+	if cfg.DstPayee != "" {
+		dstPayee = cfg.DstPayee
+	}
+	if err := registerFeePayees(src, srcAddr, srcPayee, dstPayee); err != nil {
+		return true, fmt.Errorf("failed to register the fee payees on %s: %w", src.ChainID(), err)
+	}
+	if err := registerFeePayees(dst, dstAddr, dstPayee, srcPayee); err != nil {
+		return true, fmt.Errorf("failed to register the fee payees on %s: %w", dst.ChainID(), err)
+	}
+	return true, nil
+}
+
+// registerFeePayees submits MsgRegisterPayee and MsgRegisterCounterpartyPayee to `chain` if they differ from the registered ones
+func registerFeePayees(chain *ProvableChain, relayer sdk.AccAddress, payee, counterpartyPayee string) error {
+	logger := GetChannelLogger(chain)
+	querier, ok := chain.Chain.(FeePayeeQuerier)
+	if !ok {
+		logger.Warn("the fee payees are not registered because the chain doesn't support querying them")
+		return nil
+	}
+	h, err := chain.LatestHeight()
+	if err != nil {
+		return err
+	}
+	ctx := NewQueryContext(context.TODO(), h)
+	pe := chain.Path()
+
+	var msgs []sdk.Msg
+	registered, err := querier.QueryPayee(ctx, relayer.String())
+	if err != nil {
+		return err
+	}
+	// the fees are paid to the relayer itself if no payee is registered
+	if registered != payee && !(registered == "" && payee == relayer.String()) {
+		msgs = append(msgs, feetypes.NewMsgRegisterPayee(pe.PortID, pe.ChannelID, relayer.String(), payee))
+	}
+	registered, err = querier.QueryCounterpartyPayee(ctx, relayer.String())
+	if err != nil {
+		return err
+	}
+	if registered != counterpartyPayee {
+		msgs = append(msgs, feetypes.NewMsgRegisterCounterpartyPayee(pe.PortID, pe.ChannelID, relayer.String(), counterpartyPayee))
+	}
+	if len(msgs) == 0 {
+		logger.Debug("the fee payees are already registered", "payee", payee, "counterparty_payee", counterpartyPayee)
+		return nil
+	}
+	if _, err := chain.SendMsgs(msgs); err != nil {
+		return err
+	}
+	logger.Info("registered the fee payees", "payee", payee, "counterparty_payee", counterpartyPayee)
+	return nil
+}
+
+// SetFeePayees sets the payout addresses registered on the fee-enabled channels relayed by the service
+func (srv *RelayService) SetFeePayees(cfg *FeePayeeCfg) {
+	srv.feePayees = cfg
+}
+
+// checkFeePayees registers the fee payees once for the channel currently set to the chains if it is fee-enabled.
+// A failure is logged and retried in the next relay cycle without interrupting the relay.
+func (srv *RelayService) checkFeePayees(ch *relayChannel) {
+	if ch.feePayeesChecked || srv.observing() {
+		return
+	}
+	cfg := srv.feePayees
+	if cfg == nil {
+		cfg = &FeePayeeCfg{}
+	}
+	if cfg.Disabled {
+		ch.feePayeesChecked = true
+		return
+	}
+	if _, err := RegisterFeePayees(srv.src, srv.dst, cfg); err != nil {
+		GetChannelPairLogger(srv.src, srv.dst).Error("failed to register the fee payees", err)
+		return
+	}
+	ch.feePayeesChecked = true
+}
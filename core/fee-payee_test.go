@@ -0,0 +1,102 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	feetypes "github.com/cosmos/ibc-go/v7/modules/apps/29-fee/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// feeChain is a chain with the fee middleware which records the payees registered by the submitted msgs
+type feeChain struct {
+	core.Chain
+	chainID            string
+	addr               sdk.AccAddress
+	version            string
+	payees             map[string]string
+	counterpartyPayees map[string]string
+	txs                [][]sdk.Msg
+}
+
+func newFeeChain(chainID string, addr byte, version string) *feeChain {
+	return &feeChain{
+		chainID:            chainID,
+		addr:               sdk.AccAddress([]byte{addr}),
+		version:            version,
+		payees:             map[string]string{},
+		counterpartyPayees: map[string]string{},
+	}
+}
+
+func (c *feeChain) ChainID() string { return c.chainID }
+func (c *feeChain) Path() *core.PathEnd {
+	return &core.PathEnd{ChainID: c.chainID, PortID: "transfer", ChannelID: "channel-0"}
+}
+func (c *feeChain) GetAddress() (sdk.AccAddress, error) { return c.addr, nil }
+func (c *feeChain) LatestHeight() (ibcexported.Height, error) {
+	return clienttypes.NewHeight(0, 1), nil
+}
+func (c *feeChain) QueryChannel(core.QueryContext) (*chantypes.QueryChannelResponse, error) {
+	return &chantypes.QueryChannelResponse{Channel: &chantypes.Channel{State: chantypes.OPEN, Version: c.version}}, nil
+}
+func (c *feeChain) QueryPayee(_ core.QueryContext, relayer string) (string, error) {
+	return c.payees[relayer], nil
+}
+func (c *feeChain) QueryCounterpartyPayee(_ core.QueryContext, relayer string) (string, error) {
+	return c.counterpartyPayees[relayer], nil
+}
+
+func (c *feeChain) SendMsgs(msgs []sdk.Msg) ([]core.MsgResult, error) {
+	c.txs = append(c.txs, msgs)
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case *feetypes.MsgRegisterPayee:
+			c.payees[msg.Relayer] = msg.Payee
+		case *feetypes.MsgRegisterCounterpartyPayee:
+			c.counterpartyPayees[msg.Relayer] = msg.CounterpartyPayee
+		}
+	}
+	return make([]core.MsgResult, len(msgs)), nil
+}
+
+func TestRegisterFeePayees(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	const feeVersion = `{"fee_version":"ics29-1","app_version":"ics20-1"}`
+
+	// no msgs are submitted on a channel without the fee middleware
+	src, dst := newFeeChain("src", 1, "ics20-1"), newFeeChain("dst", 2, "ics20-1")
+	if enabled, err := core.RegisterFeePayees(core.NewProvableChain(src, nil), core.NewProvableChain(dst, nil), &core.FeePayeeCfg{}); err != nil || enabled {
+		t.Fatalf("unexpected result on a channel without fees: enabled=%v err=%v", enabled, err)
+	}
+	if len(src.txs)+len(dst.txs) != 0 {
+		t.Fatal("msgs are submitted on a channel without fees")
+	}
+
+	src, dst = newFeeChain("src", 1, feeVersion), newFeeChain("dst", 2, feeVersion)
+	cfg := &core.FeePayeeCfg{SrcPayee: sdk.AccAddress([]byte{3}).String()}
+	for i := 0; i < 2; i++ {
+		if enabled, err := core.RegisterFeePayees(core.NewProvableChain(src, nil), core.NewProvableChain(dst, nil), cfg); err != nil || !enabled {
+			t.Fatalf("unexpected result: enabled=%v err=%v", enabled, err)
+		}
+	}
+	// the payee of the dst relayer is the relayer itself, so only the counterparty payee is registered on dst
+	if len(src.txs) != 1 || len(src.txs[0]) != 2 || len(dst.txs) != 1 || len(dst.txs[0]) != 1 {
+		t.Fatalf("the registration is not idempotent: src=%v dst=%v", src.txs, dst.txs)
+	}
+	if payee := src.payees[src.addr.String()]; payee != cfg.SrcPayee {
+		t.Errorf("unexpected payee on src: %s", payee)
+	}
+	if payee := src.counterpartyPayees[src.addr.String()]; payee != dst.addr.String() {
+		t.Errorf("unexpected counterparty payee on src: %s", payee)
+	}
+	if payee := dst.counterpartyPayees[dst.addr.String()]; payee != cfg.SrcPayee {
+		t.Errorf("unexpected counterparty payee on dst: %s", payee)
+	}
+}
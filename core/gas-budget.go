@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+)
+
+// GasEstimator is an optional interface of Chain that estimates the gas of msgs by simulating them (e.g. with the Simulate query of Cosmos SDK).
+// The gas budget of a relay cycle is applied only to the chains implementing it.
+type GasEstimator interface {
+	// EstimateGas returns the gas to be used by a transaction containing `msgs`, including the gas adjustment of the chain
+	EstimateGas(msgs []sdk.Msg) (uint64, error)
+}
+
+// GasBudgetCfg limits the gas of the msgs submitted to each chain in a relay cycle.
+// The msgs exceeding the budget are left to the next relay cycle, which smooths the gas spend on large backlogs
+// and keeps the transactions under the block gas limits of the chains.
+type GasBudgetCfg struct {
+	// Src and Dst are the gas budgets of the msgs submitted to the src and dst chains in a relay cycle (0 means unlimited)
+	Src uint64 `json:"src,omitempty" yaml:"src,omitempty"`
+	Dst uint64 `json:"dst,omitempty" yaml:"dst,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *GasBudgetCfg) Validate() error {
+	if cfg.Src == 0 && cfg.Dst == 0 {
+		return fmt.Errorf("gas-budget: src or dst must be specified")
+	}
+	return nil
+}
+
+// FitGasBudget returns the longest prefix of `msgs` whose estimated gas is within `budget`, and the estimated gas of the prefix.
+// The order of the msgs is preserved, so it is safe for ordered channels.
+// The leading MsgUpdateClient msgs and the following msg are always kept so that a relay cycle makes progress even if a single packet exceeds the budget.
+func FitGasBudget(estimator GasEstimator, msgs []sdk.Msg, budget uint64) ([]sdk.Msg, uint64, error) {
+	if budget == 0 || len(msgs) == 0 {
+		return msgs, 0, nil
+	}
+	gas, err := estimator.EstimateGas(msgs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if gas <= budget {
+		return msgs, gas, nil
+	}
+
+	min := 1
+	for min < len(msgs) {
+		if _, ok := msgs[min-1].(*clienttypes.MsgUpdateClient); !ok {
+			break
+		}
+		min++
+	}
+	// binary search of the longest prefix within the budget, which needs O(log n) simulations
+	lo, hi := min, len(msgs)-1
+	fit, fitGas := min, uint64(0)
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		gas, err := estimator.EstimateGas(msgs[:mid])
+		if err != nil {
+			return nil, 0, err
+		}
+		if gas <= budget || mid == min {
+			fit, fitGas = mid, gas
+		}
+		if gas <= budget {
+			lo = mid + 1
+		} else {
+			hi = mid - 1
+		}
+	}
+	if fitGas == 0 {
+		if fitGas, err = estimator.EstimateGas(msgs[:fit]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return msgs[:fit], fitGas, nil
+}
+
+// applyGasBudget removes the msgs exceeding the gas budget of `chain` from `msgs`.
+// All the msgs are kept if the chain can't estimate gas or the estimation fails, in which case the failure is left to the submission.
+func applyGasBudget(chain Chain, msgs []sdk.Msg, budget uint64) []sdk.Msg {
+	if budget == 0 || len(msgs) == 0 {
+		return msgs
+	}
+	logger := GetChainLogger(chain)
+	estimator, ok := unwrapChain(chain).(GasEstimator)
+	if !ok {
+		logger.Debug("the chain doesn't support gas estimation: the gas budget is not applied")
+		return msgs
+	}
+	fit, gas, err := FitGasBudget(estimator, msgs, budget)
+	if err != nil {
+		logger.Error("failed to estimate the gas of the msgs: the gas budget is not applied", err, "msg_count", len(msgs))
+		return msgs
+	}
+	if len(fit) < len(msgs) {
+		logger.Info("the gas budget of the relay cycle is reached: the rest of the msgs are left to the next relay cycle",
+			"budget", budget,
+			"estimated_gas", gas,
+			"msg_count", len(fit),
+			"carried_msg_count", len(msgs)-len(fit),
+		)
+	}
+	return fit
+}
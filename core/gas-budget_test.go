@@ -0,0 +1,86 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// linearGasEstimator estimates 50000 gas per tx and 100000 gas per msg
+type linearGasEstimator struct {
+	calls int
+}
+
+func (e *linearGasEstimator) EstimateGas(msgs []sdk.Msg) (uint64, error) {
+	e.calls++
+	return 50000 + 100000*uint64(len(msgs)), nil
+}
+
+func TestFitGasBudget(t *testing.T) {
+	msgs := []sdk.Msg{&clienttypes.MsgUpdateClient{}}
+	for i := 0; i < 100; i++ {
+		msgs = append(msgs, &chantypes.MsgRecvPacket{})
+	}
+
+	cases := []struct {
+		budget   uint64
+		expected int
+	}{
+		{0, 101},
+		{20_000_000, 101},
+		{1_050_000, 10},
+		{1_000_000, 9},
+		// the client update and a packet are kept even if they exceed the budget
+		{100_000, 2},
+	}
+	for _, c := range cases {
+		e := &linearGasEstimator{}
+		fit, gas, err := core.FitGasBudget(e, msgs, c.budget)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fit) != c.expected {
+			t.Errorf("budget %d: unexpected msg count: actual=%d, expected=%d", c.budget, len(fit), c.expected)
+		}
+		if c.budget > 0 && gas != 50000+100000*uint64(len(fit)) {
+			t.Errorf("budget %d: unexpected gas: %d", c.budget, gas)
+		}
+		if e.calls > 10 {
+			t.Errorf("budget %d: too many estimations: %d", c.budget, e.calls)
+		}
+	}
+}
+
+// estimatingChain is a batchChain estimating gas with linearGasEstimator
+type estimatingChain struct {
+	batchChain
+	linearGasEstimator
+}
+
+func TestSendAppliesGasBudget(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	var msgs []sdk.Msg
+	for seq := uint64(1); seq <= 20; seq++ {
+		msgs = append(msgs, recvMsg("channel-0", seq))
+	}
+
+	// the budget is applied to the chain wrapped in a ProvableChain as the relay service sends the msgs
+	src, dst := &batchChain{}, &estimatingChain{}
+	st := core.NewNaiveStrategy(false, false)
+	st.GasBudget = &core.GasBudgetCfg{Dst: 1_050_000}
+	rm := core.NewRelayMsgs()
+	rm.Dst = msgs
+	st.Send(core.NewProvableChain(src, nil), core.NewProvableChain(dst, nil), rm)
+	if !rm.Success() {
+		t.Fatal("Send failed")
+	}
+	if len(dst.txs) != 1 || len(dst.txs[0]) != 10 {
+		t.Errorf("unexpected txs within the budget: %v", dst.txs)
+	}
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// groupNamePattern restricts the group names to the characters safe for file names and metric labels
+var groupNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidateGroupName validates the name of a path group
+func ValidateGroupName(name string) error {
+	if !groupNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid group name: %q", name)
+	}
+	return nil
+}
+
+// GroupCfg configures the controls shared by the paths of a group, e.g. the paths relayed for a tenant of a relayer-as-a-service operator.
+// A path joins a group by its `group` field.
+type GroupCfg struct {
+	// FeeBudget is the daily fee budget of the group (in UTC) by chain ID (e.g. {"ibc0": "1000000stake"}),
+	// which limits the fees paid for all the paths of the group together. The relay services of the paths pause relaying
+	// until the next day once any denom of the fees paid on a chain reaches the budget of the chain.
+	FeeBudget map[string]string `json:"fee-budget,omitempty" yaml:"fee-budget,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *GroupCfg) Validate() error {
+	for chainID, budget := range cfg.FeeBudget {
+		if _, err := sdk.ParseCoinsNormalized(budget); err != nil {
+			return fmt.Errorf("fee-budget: invalid budget of %s: %w", chainID, err)
+		}
+	}
+	return nil
+}
+
+// FeeBudgetCoins returns the daily fee budgets of the group parsed by chain ID
+func (cfg *GroupCfg) FeeBudgetCoins() (map[string]sdk.Coins, error) {
+	budgets := make(map[string]sdk.Coins)
+	for chainID, budget := range cfg.FeeBudget {
+		coins, err := sdk.ParseCoinsNormalized(budget)
+		if err != nil {
+			return nil, err
+		}
+		budgets[chainID] = coins
+	}
+	return budgets, nil
+}
+
+// GroupPauseStateFile returns the path of the file to persist the pause state of the group, which pauses all the paths of the group
+func GroupPauseStateFile(homePath, group string) string {
+	return filepath.Join(homePath, "pause", "groups", group+".json")
+}
+
+// PathsInGroup returns the names of the paths of the group in the sorted order
+func (ph Paths) PathsInGroup(group string) []string {
+	var names []string
+	for name, p := range ph {
+		if p.Group == group {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// groupAttrs returns the metric attribute of the group, which is omitted for the paths without a group
+func groupAttrs(group string) []attribute.KeyValue {
+	if group == "" {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.Key("group").String(group)}
+}
+
+// groupBudget is the group of a path with the daily fee budget shared by the paths of the group.
+// The fees paid for the other paths are read from their spend files, which are written only by their own relay services,
+// so the relay services of the paths in different processes share the budget without coordination.
+type groupBudget struct {
+	group   string
+	budgets map[string]sdk.Coins // chain ID => daily fee budget
+	// spend files of the other paths of the group by path name
+	files map[string]string
+}
+
+// SetGroup sets the group of the path, which labels the spend metrics. If `cfg` has a fee budget, the tracker checks
+// the daily fee budget of the group shared with the other paths of the group, whose spend files are given by `files` keyed by the path names.
+func (t *SpendTracker) SetGroup(group string, cfg *GroupCfg, files map[string]string) error {
+	g := &groupBudget{group: group, files: files}
+	if cfg != nil {
+		budgets, err := cfg.FeeBudgetCoins()
+		if err != nil {
+			return err
+		}
+		g.budgets = budgets
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.group = g
+	return nil
+}
+
+// GroupBudgetExceeded returns the ID of a chain on which today's fees paid for all the paths of the group have reached
+// the daily budget of the group, or empty if none or the group budget is not set
+func (t *SpendTracker) GroupBudgetExceeded() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	g := t.group
+	today := time.Now().UTC().Format(time.DateOnly)
+	fees := make(map[string]sdk.Coins)
+	if g != nil && len(g.budgets) > 0 {
+		for chainID, cs := range t.spend.Chains {
+			if cs.Day == today {
+				fees[chainID] = fees[chainID].Add(cs.DayFees...)
+			}
+		}
+	}
+	t.mu.Unlock()
+	if g == nil || len(g.budgets) == 0 {
+		return ""
+	}
+
+	for name, file := range g.files {
+		spend, err := LoadPathSpend(file, name)
+		if err != nil {
+			logger := log.GetLogger().WithModule("core.spend")
+			logger.Error("failed to read the spend of a path in the group", err, "group", g.group, "path", name)
+			continue
+		}
+		for chainID, cs := range spend.Chains {
+			if cs.Day == today {
+				fees[chainID] = fees[chainID].Add(cs.DayFees...)
+			}
+		}
+	}
+	chainIDs := make([]string, 0, len(g.budgets))
+	for chainID := range g.budgets {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	for _, chainID := range chainIDs {
+		for _, coin := range g.budgets[chainID] {
+			if fees[chainID].AmountOf(coin.Denom).GTE(coin.Amount) {
+				return chainID
+			}
+		}
+	}
+	return ""
+}
+
+// SetGroup sets the group of the path, which labels the relay metrics, and the file persisting the pause state of the group.
+// The pause state of the group is checked in every relay cycle in addition to the one of the path.
+func (srv *RelayService) SetGroup(group, pauseFile string) {
+	srv.group = group
+	srv.groupPauseFile = pauseFile
+}
+
+// Group returns the group of the path, or empty if the path doesn't belong to a group
+func (srv *RelayService) Group() string {
+	return srv.group
+}
+
+// GroupPauseState returns the pause state of the group of the path
+func (srv *RelayService) GroupPauseState() (*PauseState, error) {
+	if srv.groupPauseFile == "" {
+		return &PauseState{}, nil
+	}
+	return LoadPauseState(srv.groupPauseFile)
+}
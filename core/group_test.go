@@ -0,0 +1,111 @@
+package core_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestGroupCfg(t *testing.T) {
+	for name, valid := range map[string]bool{"tenant-a": true, "tenant_1.prod": true, "": false, "-a": false, "a/b": false} {
+		if err := core.ValidateGroupName(name); (err == nil) != valid {
+			t.Errorf("%q: unexpected result: %v", name, err)
+		}
+	}
+	if err := (&core.GroupCfg{FeeBudget: map[string]string{"ibc0": "100stake"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (&core.GroupCfg{FeeBudget: map[string]string{"ibc0": "stake"}}).Validate(); err == nil {
+		t.Error("an invalid fee budget is accepted")
+	}
+
+	paths := core.Paths{
+		"c": {Group: "tenant-a"},
+		"a": {Group: "tenant-a"},
+		"b": {Group: "tenant-b"},
+		"d": {},
+	}
+	if names := paths.PathsInGroup("tenant-a"); len(names) != 2 || names[0] != "a" || names[1] != "c" {
+		t.Errorf("unexpected paths: %v", names)
+	}
+}
+
+func TestGroupBudget(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	home := t.TempDir()
+	path := &core.Path{Src: &core.PathEnd{ChainID: "ibc0"}, Dst: &core.PathEnd{ChainID: "ibc1"}, Group: "tenant-a"}
+	newTracker := func(name, other string) *core.SpendTracker {
+		tracker, err := core.NewSpendTracker(core.SpendFile(home, name), name, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := &core.GroupCfg{FeeBudget: map[string]string{"ibc0": "100stake"}}
+		if err := tracker.SetGroup("tenant-a", cfg, map[string]string{other: core.SpendFile(home, other)}); err != nil {
+			t.Fatal(err)
+		}
+		return tracker
+	}
+	a, b := newTracker("a", "b"), newTracker("b", "a")
+
+	a.OnTxSpend(core.TxSpend{ChainID: "ibc0", GasUsed: 1, Fee: sdk.NewCoins(sdk.NewInt64Coin("stake", 60))})
+	if chainID := b.GroupBudgetExceeded(); chainID != "" {
+		t.Errorf("the group budget is exceeded too early: %s", chainID)
+	}
+	// the fees paid for both paths reach the budget of the group while neither does alone
+	b.OnTxSpend(core.TxSpend{ChainID: "ibc0", GasUsed: 1, Fee: sdk.NewCoins(sdk.NewInt64Coin("stake", 40))})
+	for name, tracker := range map[string]*core.SpendTracker{"a": a, "b": b} {
+		if chainID := tracker.GroupBudgetExceeded(); chainID != "ibc0" {
+			t.Errorf("%s: the group budget is not exceeded: %q", name, chainID)
+		}
+		if chainID := tracker.BudgetExceeded(); chainID != "" {
+			t.Errorf("%s: the path budget is exceeded: %s", name, chainID)
+		}
+	}
+}
+
+func TestGroupPause(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, sh := newScriptedChains(t)
+	st := &scriptedStrategy{pending: []uint64{1}, own: map[uint64]bool{1: true}}
+	srv := core.NewRelayService(st, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	file := core.GroupPauseStateFile(t.TempDir(), "tenant-a")
+	if filepath.Base(file) != "tenant-a.json" {
+		t.Errorf("unexpected pause state file: %s", file)
+	}
+	srv.SetGroup("tenant-a", file)
+
+	if err := core.PausePath(file, "invoice overdue"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(st.relayed) != 0 {
+		t.Errorf("packets are relayed while the group is paused: %v", st.relayed)
+	}
+
+	if err := core.ResumePath(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(st.relayed) != 1 {
+		t.Errorf("packets are not relayed after the group is resumed: %v", st.relayed)
+	}
+}
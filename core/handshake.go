@@ -0,0 +1,179 @@
+package core
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// The handshake steps below are pure functions computing the msgs of the next step from the states of both ends.
+// They neither query the chains nor submit txs, so that other programs (e.g. custom orchestrators and tests) can embed
+// the handshake logic with their own way to collect the states and to retry. CreateClients, CreateConnection and
+// CreateChannel are the loops of the CLI built on them.
+
+// ClientCreationEnd is the input of ClientCreationStep for a chain
+type ClientCreationEnd struct {
+	ChainID string
+	// Signer is the relayer address on the chain, which submits MsgCreateClient of the counterparty client
+	Signer sdk.AccAddress
+	// ClientState and ConsensusState are the initial light client state of the chain, which is created on the counterparty chain
+	ClientState    exported.ClientState
+	ConsensusState exported.ConsensusState
+}
+
+// ClientCreationStep returns the msgs creating the light client of each chain on the counterparty chain
+func ClientCreationStep(src, dst *ClientCreationEnd) (*RelayMsgs, error) {
+	out := NewRelayMsgs()
+	for _, c := range []struct {
+		self, counterparty *ClientCreationEnd
+		msgs               *[]sdk.Msg
+	}{
+		{src, dst, &out.Src},
+		{dst, src, &out.Dst},
+	} {
+		cp := c.counterparty
+		if err := cp.ClientState.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid client state of %s: %w", cp.ChainID, err)
+		}
+		if err := cp.ConsensusState.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("invalid consensus state of %s: %w", cp.ChainID, err)
+		}
+		msg, err := clienttypes.NewMsgCreateClient(cp.ClientState, cp.ConsensusState, c.self.Signer.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MsgCreateClient: %w", err)
+		}
+		*c.msgs = append(*c.msgs, msg)
+	}
+	return out, nil
+}
+
+// ConnectionHandshakeEnd is the input of ConnectionHandshakeStep for a chain
+type ConnectionHandshakeEnd struct {
+	Path *PathEnd
+	// Signer is the relayer address on the chain
+	Signer sdk.AccAddress
+	// Connection is the connection end on the chain with its proof
+	Connection *conntypes.QueryConnectionResponse
+	// ClientState and ConsensusState are the client of the counterparty chain on the chain with their proofs,
+	// which are required unless the handshake is not started on both ends
+	ClientState    *clienttypes.QueryClientStateResponse
+	ConsensusState *clienttypes.QueryConsensusStateResponse
+	// HostConsensusStateProof is the proof of the consensus state of the chain stored in the counterparty client
+	// (only required by chains that can't introspect their own consensus states)
+	HostConsensusStateProof []byte
+	// UpdateHeaders are the headers of the chain to update its client on the counterparty chain before the proofs are verified
+	UpdateHeaders []Header
+}
+
+// ConnectionHandshakeStep returns the msgs of the next step of the connection handshake.
+// Last of the returned msgs is true if the step completes the handshake.
+func ConnectionHandshakeStep(src, dst *ConnectionHandshakeEnd) (*RelayMsgs, error) {
+	out := NewRelayMsgs()
+	srcState, dstState := src.Connection.Connection.State, dst.Connection.Connection.State
+	// updateClient prepends the msgs updating the client of the counterparty on `self`
+	updateClient := func(self, counterparty *ConnectionHandshakeEnd) []sdk.Msg {
+		if len(counterparty.UpdateHeaders) == 0 {
+			return nil
+		}
+		return self.Path.UpdateClients(counterparty.UpdateHeaders, self.Signer)
+	}
+
+	switch {
+	// Handshake hasn't been started on src or dst, relay `connOpenInit` to src
+	case srcState == conntypes.UNINITIALIZED && dstState == conntypes.UNINITIALIZED:
+		out.Src = append(updateClient(src, dst), src.Path.ConnInit(dst.Path, src.Signer))
+	// Handshake has started on dst (1 step done), relay `connOpenTry` and `updateClient` on src
+	case srcState == conntypes.UNINITIALIZED && dstState == conntypes.INIT:
+		out.Src = append(updateClient(src, dst), src.Path.ConnTry(dst.Path, dst.ClientState, dst.Connection, dst.ConsensusState, src.HostConsensusStateProof, src.Signer))
+	// Handshake has started on src (1 step done), relay `connOpenTry` and `updateClient` on dst
+	case srcState == conntypes.INIT && dstState == conntypes.UNINITIALIZED:
+		out.Dst = append(updateClient(dst, src), dst.Path.ConnTry(src.Path, src.ClientState, src.Connection, src.ConsensusState, dst.HostConsensusStateProof, dst.Signer))
+	// Handshake has started on src end (2 steps done), relay `connOpenAck` and `updateClient` to dst end
+	case srcState == conntypes.TRYOPEN && dstState == conntypes.INIT:
+		out.Dst = append(updateClient(dst, src), dst.Path.ConnAck(src.Path, src.ClientState, src.Connection, src.ConsensusState, dst.HostConsensusStateProof, dst.Signer))
+	// Handshake has started on dst end (2 steps done), relay `connOpenAck` and `updateClient` to src end
+	case srcState == conntypes.INIT && dstState == conntypes.TRYOPEN:
+		out.Src = append(updateClient(src, dst), src.Path.ConnAck(dst.Path, dst.ClientState, dst.Connection, dst.ConsensusState, src.HostConsensusStateProof, src.Signer))
+	// Handshake has confirmed on dst (3 steps done), relay `connOpenConfirm` and `updateClient` to src end
+	case srcState == conntypes.TRYOPEN && dstState == conntypes.OPEN:
+		out.Src = append(updateClient(src, dst), src.Path.ConnConfirm(dst.Connection, src.Signer))
+		out.Last = true
+	// Handshake has confirmed on src (3 steps done), relay `connOpenConfirm` and `updateClient` to dst end
+	case srcState == conntypes.OPEN && dstState == conntypes.TRYOPEN:
+		out.Dst = append(updateClient(dst, src), dst.Path.ConnConfirm(src.Connection, dst.Signer))
+		out.Last = true
+	default:
+		return nil, fmt.Errorf("unexpected connection states: %v <=> %v", srcState, dstState)
+	}
+	return out, nil
+}
+
+// ChannelHandshakeEnd is the input of ChannelHandshakeStep for a chain
+type ChannelHandshakeEnd struct {
+	Path *PathEnd
+	// Signer is the relayer address on the chain
+	Signer sdk.AccAddress
+	// Channel is the channel end on the chain with its proof
+	Channel *chantypes.QueryChannelResponse
+	// UpdateHeaders are the headers of the chain to update its client on the counterparty chain before the proofs are verified
+	UpdateHeaders []Header
+}
+
+// ChannelHandshakeStep returns the msgs of the next step of the channel handshake.
+// Last of the returned msgs is true if the step completes the handshake.
+// It fails if the version proposed by the counterparty is malformed or incompatible with the path config.
+func ChannelHandshakeStep(src, dst *ChannelHandshakeEnd) (*RelayMsgs, error) {
+	out := NewRelayMsgs()
+	srcState, dstState := src.Channel.Channel.State, dst.Channel.Channel.State
+	updateClient := func(self, counterparty *ChannelHandshakeEnd) []sdk.Msg {
+		if len(counterparty.UpdateHeaders) == 0 {
+			return nil
+		}
+		return self.Path.UpdateClients(counterparty.UpdateHeaders, self.Signer)
+	}
+
+	switch {
+	// Handshake hasn't been started on src or dst, relay `chanOpenInit` to src
+	case srcState == chantypes.UNINITIALIZED && dstState == chantypes.UNINITIALIZED:
+		out.Src = append(out.Src, src.Path.ChanInit(dst.Path, src.Signer))
+	// Handshake has started on dst (1 step done), relay `chanOpenTry` and `updateClient` to src
+	case srcState == chantypes.UNINITIALIZED && dstState == chantypes.INIT:
+		if err := validateCounterpartyVersion(src.Path, dst.Channel.Channel); err != nil {
+			return nil, err
+		}
+		out.Src = append(updateClient(src, dst), src.Path.ChanTry(dst.Path, dst.Channel, src.Signer))
+	// Handshake has started on src (1 step done), relay `chanOpenTry` and `updateClient` to dst
+	case srcState == chantypes.INIT && dstState == chantypes.UNINITIALIZED:
+		if err := validateCounterpartyVersion(dst.Path, src.Channel.Channel); err != nil {
+			return nil, err
+		}
+		out.Dst = append(updateClient(dst, src), dst.Path.ChanTry(src.Path, src.Channel, dst.Signer))
+	// Handshake has started on src (2 steps done), relay `chanOpenAck` and `updateClient` to dst
+	case srcState == chantypes.TRYOPEN && dstState == chantypes.INIT:
+		if err := validateCounterpartyVersion(dst.Path, src.Channel.Channel); err != nil {
+			return nil, err
+		}
+		out.Dst = append(updateClient(dst, src), dst.Path.ChanAck(src.Path, src.Channel, dst.Signer))
+	// Handshake has started on dst (2 steps done), relay `chanOpenAck` and `updateClient` to src
+	case srcState == chantypes.INIT && dstState == chantypes.TRYOPEN:
+		if err := validateCounterpartyVersion(src.Path, dst.Channel.Channel); err != nil {
+			return nil, err
+		}
+		out.Src = append(updateClient(src, dst), src.Path.ChanAck(dst.Path, dst.Channel, src.Signer))
+	// Handshake has confirmed on dst (3 steps done), relay `chanOpenConfirm` and `updateClient` to src
+	case srcState == chantypes.TRYOPEN && dstState == chantypes.OPEN:
+		out.Src = append(updateClient(src, dst), src.Path.ChanConfirm(dst.Channel, src.Signer))
+		out.Last = true
+	// Handshake has confirmed on src (3 steps done), relay `chanOpenConfirm` and `updateClient` to dst
+	case srcState == chantypes.OPEN && dstState == chantypes.TRYOPEN:
+		out.Dst = append(updateClient(dst, src), dst.Path.ChanConfirm(src.Channel, dst.Signer))
+		out.Last = true
+	default:
+		return nil, fmt.Errorf("unexpected channel states: %v <=> %v", srcState, dstState)
+	}
+	return out, nil
+}
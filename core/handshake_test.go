@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	mocktypes "github.com/datachainlab/ibc-mock-client/modules/light-clients/xx-mock/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func channelEnd(chainID, channelID string, state chantypes.State, version string) *core.ChannelHandshakeEnd {
+	return &core.ChannelHandshakeEnd{
+		Path: &core.PathEnd{
+			ChainID:      chainID,
+			ClientID:     "mock-client-0",
+			ConnectionID: "connection-0",
+			ChannelID:    channelID,
+			PortID:       "transfer",
+			Order:        "unordered",
+			Version:      "ics20-1",
+		},
+		Signer: sdk.AccAddress(chainID),
+		Channel: &chantypes.QueryChannelResponse{
+			Channel:     &chantypes.Channel{State: state, Version: version},
+			Proof:       []byte("proof"),
+			ProofHeight: clienttypes.NewHeight(0, 1),
+		},
+	}
+}
+
+func TestChannelHandshakeStep(t *testing.T) {
+	const feeVersion = `{"fee_version":"ics29-1","app_version":"ics20-1"}`
+	cases := map[string]struct {
+		src, dst      *core.ChannelHandshakeEnd
+		srcMsg        sdk.Msg
+		dstMsg        sdk.Msg
+		last, invalid bool
+	}{
+		"init": {
+			src:    channelEnd("ibc0", "", chantypes.UNINITIALIZED, ""),
+			dst:    channelEnd("ibc1", "", chantypes.UNINITIALIZED, ""),
+			srcMsg: &chantypes.MsgChannelOpenInit{},
+		},
+		"try on dst": {
+			src:    channelEnd("ibc0", "channel-0", chantypes.INIT, "ics20-1"),
+			dst:    channelEnd("ibc1", "", chantypes.UNINITIALIZED, ""),
+			dstMsg: &chantypes.MsgChannelOpenTry{},
+		},
+		"ack on src": {
+			src:    channelEnd("ibc0", "channel-0", chantypes.INIT, "ics20-1"),
+			dst:    channelEnd("ibc1", "channel-1", chantypes.TRYOPEN, "ics20-1"),
+			srcMsg: &chantypes.MsgChannelOpenAck{},
+		},
+		"confirm on dst": {
+			src:    channelEnd("ibc0", "channel-0", chantypes.OPEN, "ics20-1"),
+			dst:    channelEnd("ibc1", "channel-1", chantypes.TRYOPEN, "ics20-1"),
+			dstMsg: &chantypes.MsgChannelOpenConfirm{},
+			last:   true,
+		},
+		"fee mismatch": {
+			src:     channelEnd("ibc0", "channel-0", chantypes.INIT, feeVersion),
+			dst:     channelEnd("ibc1", "", chantypes.UNINITIALIZED, ""),
+			invalid: true,
+		},
+		"already open": {
+			src:     channelEnd("ibc0", "channel-0", chantypes.OPEN, "ics20-1"),
+			dst:     channelEnd("ibc1", "channel-1", chantypes.OPEN, "ics20-1"),
+			invalid: true,
+		},
+	}
+	for name, c := range cases {
+		msgs, err := core.ChannelHandshakeStep(c.src, c.dst)
+		if c.invalid {
+			if err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		for _, side := range []struct {
+			msgs     []sdk.Msg
+			expected sdk.Msg
+		}{{msgs.Src, c.srcMsg}, {msgs.Dst, c.dstMsg}} {
+			if side.expected == nil {
+				if len(side.msgs) != 0 {
+					t.Errorf("%s: unexpected msgs: %v", name, side.msgs)
+				}
+			} else if len(side.msgs) != 1 || sdk.MsgTypeURL(side.msgs[0]) != sdk.MsgTypeURL(side.expected) {
+				t.Errorf("%s: unexpected msgs: %v", name, side.msgs)
+			}
+		}
+		if msgs.Last != c.last {
+			t.Errorf("%s: unexpected Last: %v", name, msgs.Last)
+		}
+	}
+}
+
+func TestConnectionHandshakeStepUpdatesClient(t *testing.T) {
+	end := func(chainID string, state conntypes.State) *core.ConnectionHandshakeEnd {
+		return &core.ConnectionHandshakeEnd{
+			Path:   &core.PathEnd{ChainID: chainID, ClientID: "mock-client-0", ConnectionID: "connection-0"},
+			Signer: sdk.AccAddress(chainID),
+			Connection: &conntypes.QueryConnectionResponse{
+				Connection:  &conntypes.ConnectionEnd{State: state},
+				Proof:       []byte("proof"),
+				ProofHeight: clienttypes.NewHeight(0, 1),
+			},
+		}
+	}
+	src, dst := end("ibc0", conntypes.TRYOPEN), end("ibc1", conntypes.OPEN)
+	dst.UpdateHeaders = []core.Header{
+		&mocktypes.Header{Height: clienttypes.NewHeight(0, 1)},
+		&mocktypes.Header{Height: clienttypes.NewHeight(0, 2)},
+	}
+	msgs, err := core.ConnectionHandshakeStep(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the client of dst on src is updated before the proof of dst is verified
+	if len(msgs.Src) != 3 || len(msgs.Dst) != 0 || !msgs.Last {
+		t.Fatalf("unexpected msgs: %v", msgs)
+	}
+	if _, ok := msgs.Src[2].(*conntypes.MsgConnectionOpenConfirm); !ok {
+		t.Errorf("the last msg is not MsgConnectionOpenConfirm: %T", msgs.Src[2])
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+)
+
+// maxUpdateClientMsgsPerTx is the maximum number of MsgUpdateClient bundled in a transaction,
+// which splits a long sequence of headers to update a client far behind across multiple transactions
+const maxUpdateClientMsgsPerTx = 10
+
+// HeaderSkipper is an optional interface of Prover for light clients supporting skipping verification.
+// If the prover implements it, the headers returned by SetupHeadersForUpdate are reduced to the minimal set
+// needed to reach the target header within the trust constraints of the light client.
+type HeaderSkipper interface {
+	// CanSkip returns true if the light client on `counterparty` trusting `trusted` can verify `target` directly
+	// within its trust constraints (e.g. the trusting period and the trust level against a validator set change).
+	// `trusted` is nil for the latest state of the client on `counterparty`.
+	CanSkip(counterparty FinalityAwareChain, trusted, target Header) (bool, error)
+
+	// RebaseHeader returns `target` modified to be verified on top of `trusted` instead of the header preceding it.
+	// `trusted` is nil for the latest state of the client on `counterparty`.
+	RebaseHeader(counterparty FinalityAwareChain, trusted, target Header) (Header, error)
+}
+
+// MinimalHeadersForUpdate reduces `headers` in the order returned by SetupHeadersForUpdate, where each header is verified
+// on top of the preceding one, to the minimal subsequence ending with the target header (i.e. the last one).
+// From the trusted state, the farthest header that the light client can verify directly is selected repeatedly.
+func MinimalHeadersForUpdate(skipper HeaderSkipper, counterparty FinalityAwareChain, headers []Header) ([]Header, error) {
+	if len(headers) <= 1 {
+		return headers, nil
+	}
+	var (
+		ret     []Header
+		trusted Header
+	)
+	for i := 0; i < len(headers); {
+		next := i
+		for j := len(headers) - 1; j > i; j-- {
+			ok, err := skipper.CanSkip(counterparty, trusted, headers[j])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				next = j
+				break
+			}
+		}
+		h := headers[next]
+		if next != i {
+			var err error
+			if h, err = skipper.RebaseHeader(counterparty, trusted, h); err != nil {
+				return nil, fmt.Errorf("failed to rebase the header at %v: %w", headers[next].GetHeight(), err)
+			}
+		}
+		ret = append(ret, h)
+		trusted = h
+		i = next + 1
+	}
+	return ret, nil
+}
+
+// minimalHeadersForUpdate applies MinimalHeadersForUpdate if the prover of `chain` implements HeaderSkipper
+func minimalHeadersForUpdate(chain, counterparty ChainLightClient, headers []Header) ([]Header, error) {
+	var lc interface{} = chain
+	if pc, ok := chain.(*ProvableChain); ok {
+		lc = pc.CurrentProver()
+	}
+	skipper, ok := lc.(HeaderSkipper)
+	if !ok || len(headers) <= 1 {
+		return headers, nil
+	}
+	reduced, err := MinimalHeadersForUpdate(skipper, counterparty, headers)
+	if err != nil {
+		return nil, err
+	}
+	if len(reduced) < len(headers) {
+		GetChainPairLogger(chain, counterparty).Info("skipped intermediate headers to update the client",
+			"num_headers", len(headers),
+			"num_reduced_headers", len(reduced),
+		)
+	}
+	return reduced, nil
+}
+
+func isUpdateClientMsg(msg sdk.Msg) bool {
+	_, ok := msg.(*clienttypes.MsgUpdateClient)
+	return ok
+}
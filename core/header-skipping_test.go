@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	mocktypes "github.com/datachainlab/ibc-mock-client/modules/light-clients/xx-mock/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// rangeSkipper allows skipping verification up to `maxSkip` blocks from the trusted height
+type rangeSkipper struct {
+	trustedHeight uint64
+	maxSkip       uint64
+	rebased       int
+}
+
+func (s *rangeSkipper) CanSkip(counterparty core.FinalityAwareChain, trusted, target core.Header) (bool, error) {
+	from := s.trustedHeight
+	if trusted != nil {
+		from = trusted.GetHeight().GetRevisionHeight()
+	}
+	return target.GetHeight().GetRevisionHeight()-from <= s.maxSkip, nil
+}
+
+func (s *rangeSkipper) RebaseHeader(counterparty core.FinalityAwareChain, trusted, target core.Header) (core.Header, error) {
+	s.rebased++
+	return target, nil
+}
+
+func TestMinimalHeadersForUpdate(t *testing.T) {
+	var headers []core.Header
+	for h := uint64(101); h <= 110; h++ {
+		headers = append(headers, &mocktypes.Header{Height: clienttypes.NewHeight(0, h)})
+	}
+
+	skipper := &rangeSkipper{trustedHeight: 100, maxSkip: 4}
+	reduced, err := core.MinimalHeadersForUpdate(skipper, nil, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var heights []uint64
+	for _, h := range reduced {
+		heights = append(heights, h.GetHeight().GetRevisionHeight())
+	}
+	expected := []uint64{104, 108, 110}
+	if len(heights) != len(expected) {
+		t.Fatalf("unexpected headers: actual=%v, expected=%v", heights, expected)
+	}
+	for i := range expected {
+		if heights[i] != expected[i] {
+			t.Fatalf("unexpected headers: actual=%v, expected=%v", heights, expected)
+		}
+	}
+	if skipper.rebased != 3 {
+		t.Errorf("unexpected number of rebased headers: %d", skipper.rebased)
+	}
+
+	// no header can be skipped
+	skipper = &rangeSkipper{trustedHeight: 100, maxSkip: 1}
+	if reduced, err = core.MinimalHeadersForUpdate(skipper, nil, headers); err != nil {
+		t.Fatal(err)
+	} else if len(reduced) != len(headers) || skipper.rebased != 0 {
+		t.Errorf("unexpected reduction: %d headers, %d rebased", len(reduced), skipper.rebased)
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cosmos/ibc-go/v7/modules/core/exported"
 	"github.com/hyperledger-labs/yui-relayer/metrics"
@@ -16,16 +17,29 @@ type Header interface {
 
 // SyncHeaders manages the latest finalized headers on both `src` and `dst` chains
 // It also provides the helper functions to update the clients on the chains
+//
+// The headers are tracked per chain with two heights: the finalized height, whose state is safe to prove
+// to the counterparty, and the latest height, which may be ahead of it on chains without instant finality
+// (e.g. the safe or latest block of Ethereum, or the unsafe head of a rollup).
 type SyncHeaders interface {
 	// Updates updates the headers on both chains
 	Updates(src, dst ChainInfoLightClient) error
 
+	// UpdateChain updates the headers of the chain independently of its counterparty
+	UpdateChain(chain ChainInfoLightClient) error
+
 	// GetLatestFinalizedHeader returns the latest finalized header of the chain
 	GetLatestFinalizedHeader(chainID string) Header
 
+	// GetChainHeights returns the heights of the chain observed at the last update
+	GetChainHeights(chainID string) ChainHeights
+
 	// GetQueryContext builds a query context based on the latest finalized header
 	GetQueryContext(chainID string) QueryContext
 
+	// GetLatestQueryContext builds a query context based on the latest (possibly unfinalized) height
+	GetLatestQueryContext(chainID string) QueryContext
+
 	// SetupHeadersForUpdate returns `src` chain's headers needed to update the client on `dst` chain
 	SetupHeadersForUpdate(src, dst ChainLightClient) ([]Header, error)
 
@@ -33,6 +47,45 @@ type SyncHeaders interface {
 	SetupBothHeadersForUpdate(src, dst ChainLightClient) (srcHeaders []Header, dstHeaders []Header, err error)
 }
 
+// LatestHeightProvider is an optional interface of LightClient for chains whose latest height usable for
+// unfinalized queries differs from ChainInfo.LatestHeight, e.g. a prover of Ethereum that regards the safe block as the latest.
+type LatestHeightProvider interface {
+	// GetLatestHeight returns the latest height of the chain under the finality semantics of the light client
+	GetLatestHeight() (exported.Height, error)
+}
+
+// ChainHeights is the heights of a chain tracked by SyncHeaders
+type ChainHeights struct {
+	// Finalized is the height of the latest finalized header
+	Finalized exported.Height
+	// Latest is the latest height, which is equal to or higher than Finalized
+	Latest exported.Height
+	// UpdatedAt is the time when the heights are updated
+	UpdatedAt time.Time
+}
+
+// FinalityLag returns the number of blocks from the latest finalized height to the latest height.
+// It returns 0 if the heights are in different revisions.
+func (h ChainHeights) FinalityLag() uint64 {
+	if h.Finalized == nil || h.Latest == nil || h.Latest.LTE(h.Finalized) ||
+		h.Latest.GetRevisionNumber() != h.Finalized.GetRevisionNumber() {
+		return 0
+	}
+	return h.Latest.GetRevisionHeight() - h.Finalized.GetRevisionHeight()
+}
+
+// latestHeight returns the latest height of the chain, preferring the one given by the light client
+func latestHeight(chain ChainInfoLightClient) (exported.Height, error) {
+	var lc interface{} = chain
+	if pc, ok := chain.(*ProvableChain); ok {
+		lc = pc.CurrentProver()
+	}
+	if p, ok := lc.(LatestHeightProvider); ok {
+		return p.GetLatestHeight()
+	}
+	return chain.LatestHeight()
+}
+
 // ChainInfoLightClient = ChainInfo + LightClient
 type ChainInfoLightClient interface {
 	ChainInfo
@@ -45,8 +98,11 @@ type ChainLightClient interface {
 	LightClient
 }
 
+// syncHeaders retains only the latest finalized header and heights of each of the two chains, which are replaced
+// on every update, so its memory usage is bounded regardless of the uptime and no history needs to be pruned.
 type syncHeaders struct {
-	latestFinalizedHeaders map[string]Header // chainID => Header
+	latestFinalizedHeaders map[string]Header       // chainID => Header
+	chainHeights           map[string]ChainHeights // chainID => ChainHeights
 }
 
 var _ SyncHeaders = (*syncHeaders)(nil)
@@ -61,6 +117,7 @@ func NewSyncHeaders(src, dst ChainInfoLightClient) (SyncHeaders, error) {
 	}
 	sh := &syncHeaders{
 		latestFinalizedHeaders: map[string]Header{src.ChainID(): nil, dst.ChainID(): nil},
+		chainHeights:           map[string]ChainHeights{},
 	}
 	if err := sh.Updates(src, dst); err != nil {
 		logger.Error("error updating headers", err)
@@ -77,12 +134,12 @@ func (sh *syncHeaders) Updates(src, dst ChainInfoLightClient) error {
 		return err
 	}
 
-	srcHeader, err := src.GetLatestFinalizedHeader()
+	srcHeader, srcHeights, err := queryChainHeaders(src)
 	if err != nil {
 		logger.Error("error getting latest finalized header of src", err)
 		return err
 	}
-	dstHeader, err := dst.GetLatestFinalizedHeader()
+	dstHeader, dstHeights, err := queryChainHeaders(dst)
 	if err != nil {
 		logger.Error("error getting latest finalized header of dst", err)
 		return err
@@ -94,9 +151,44 @@ func (sh *syncHeaders) Updates(src, dst ChainInfoLightClient) error {
 
 	sh.latestFinalizedHeaders[src.ChainID()] = srcHeader
 	sh.latestFinalizedHeaders[dst.ChainID()] = dstHeader
+	sh.chainHeights[src.ChainID()] = srcHeights
+	sh.chainHeights[dst.ChainID()] = dstHeights
 	return nil
 }
 
+// UpdateChain updates the headers of the chain independently of its counterparty
+func (sh *syncHeaders) UpdateChain(chain ChainInfoLightClient) error {
+	if _, ok := sh.latestFinalizedHeaders[chain.ChainID()]; !ok {
+		return fmt.Errorf("the chain is not managed by the sync headers: %v", chain.ChainID())
+	}
+	header, heights, err := queryChainHeaders(chain)
+	if err != nil {
+		GetChainLogger(chain).Error("error getting latest finalized header", err)
+		return err
+	}
+	sh.latestFinalizedHeaders[chain.ChainID()] = header
+	sh.chainHeights[chain.ChainID()] = heights
+	return nil
+}
+
+// queryChainHeaders queries the latest finalized header and the latest height of the chain.
+// The latest height is clamped to the finalized height in case the latest height is queried from a lagging node.
+func queryChainHeaders(chain ChainInfoLightClient) (Header, ChainHeights, error) {
+	header, err := chain.GetLatestFinalizedHeader()
+	if err != nil {
+		return nil, ChainHeights{}, err
+	}
+	latest, err := latestHeight(chain)
+	if err != nil {
+		return nil, ChainHeights{}, err
+	}
+	finalized := header.GetHeight()
+	if latest.LT(finalized) {
+		latest = finalized
+	}
+	return header, ChainHeights{Finalized: finalized, Latest: latest, UpdatedAt: time.Now()}, nil
+}
+
 func (sh syncHeaders) updateBlockMetrics(ctx context.Context, src, dst ChainInfo, srcHeader, dstHeader Header) error {
 	metrics.ProcessedBlockHeightGauge.Set(
 		int64(srcHeader.GetHeight().GetRevisionHeight()),
@@ -121,6 +213,16 @@ func (sh syncHeaders) GetQueryContext(chainID string) QueryContext {
 	return NewQueryContext(context.TODO(), sh.GetLatestFinalizedHeader(chainID).GetHeight())
 }
 
+// GetChainHeights returns the heights of the chain observed at the last update
+func (sh syncHeaders) GetChainHeights(chainID string) ChainHeights {
+	return sh.chainHeights[chainID]
+}
+
+// GetLatestQueryContext builds a query context based on the latest (possibly unfinalized) height
+func (sh syncHeaders) GetLatestQueryContext(chainID string) QueryContext {
+	return NewQueryContext(context.TODO(), sh.GetChainHeights(chainID).Latest)
+}
+
 // SetupHeadersForUpdate returns `src` chain's headers to update the client on `dst` chain
 func (sh syncHeaders) SetupHeadersForUpdate(src, dst ChainLightClient) ([]Header, error) {
 	logger := GetChainPairLogger(src, dst)
@@ -128,7 +230,11 @@ func (sh syncHeaders) SetupHeadersForUpdate(src, dst ChainLightClient) ([]Header
 		logger.Error("error ensuring different chains", err)
 		return nil, err
 	}
-	return src.SetupHeadersForUpdate(dst, sh.GetLatestFinalizedHeader(src.ChainID()))
+	headers, err := src.SetupHeadersForUpdate(dst, sh.GetLatestFinalizedHeader(src.ChainID()))
+	if err != nil {
+		return nil, err
+	}
+	return minimalHeadersForUpdate(src, dst, headers)
 }
 
 // SetupBothHeadersForUpdate returns both `src` and `dst` chain's headers to update the clients on each chain
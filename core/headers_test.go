@@ -0,0 +1,24 @@
+package core_test
+
+import (
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestChainHeightsFinalityLag(t *testing.T) {
+	cases := []struct {
+		heights  core.ChainHeights
+		expected uint64
+	}{
+		{core.ChainHeights{Finalized: clienttypes.NewHeight(1, 100), Latest: clienttypes.NewHeight(1, 164)}, 64},
+		{core.ChainHeights{Finalized: clienttypes.NewHeight(1, 100), Latest: clienttypes.NewHeight(1, 100)}, 0},
+		{core.ChainHeights{Finalized: clienttypes.NewHeight(1, 100)}, 0},
+	}
+	for i, c := range cases {
+		if lag := c.heights.FinalityLag(); lag != c.expected {
+			t.Errorf("case %d: unexpected lag: actual=%d, expected=%d", i, lag, c.expected)
+		}
+	}
+}
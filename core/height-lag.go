@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// kinds of the heights compared by the height lag alarm
+const (
+	HeightLagLatest    = "latest"
+	HeightLagFinalized = "finalized"
+)
+
+// HeightLagAlarmCfg raises an alert when the heights known to the relayer (the latest and the finalized height given by the prover
+// and tracked by SyncHeaders) lag behind the latest height reported by the chain module, which catches a misbehaving prover
+// or a stuck header sync before the relay stalls.
+type HeightLagAlarmCfg struct {
+	// LatestThreshold is the number of blocks by which the latest height known to the relayer may differ from
+	// the latest height reported by the chain (not checked if 0)
+	LatestThreshold uint64 `json:"latest-threshold,omitempty" yaml:"latest-threshold,omitempty"`
+
+	// FinalizedThreshold is the number of blocks by which the finalized height known to the relayer may differ from
+	// the latest height reported by the chain, which should include the finality delay of the chain (not checked if 0)
+	FinalizedThreshold uint64 `json:"finalized-threshold,omitempty" yaml:"finalized-threshold,omitempty"`
+
+	// WebhookCfg is the webhook receiving the HeightLagAlert when a height enters or leaves the alarm
+	WebhookCfg `yaml:",inline"`
+}
+
+// Validate validates the config
+func (cfg *HeightLagAlarmCfg) Validate() error {
+	if cfg.LatestThreshold == 0 && cfg.FinalizedThreshold == 0 {
+		return fmt.Errorf("height-lag-alarm: either latest-threshold or finalized-threshold must be positive")
+	}
+	if err := cfg.WebhookCfg.Validate(); err != nil {
+		return fmt.Errorf("height-lag-alarm: %w", err)
+	}
+	return nil
+}
+
+// HeightLag returns the number of blocks by which `height` known to the relayer lags behind `chainLatest` reported by the chain.
+// It is negative if `height` is ahead of the chain, e.g. a prover reporting a height that the chain has not produced.
+// It returns 0 if the heights are in different revisions.
+func HeightLag(height, chainLatest exported.Height) int64 {
+	if height == nil || chainLatest == nil || height.GetRevisionNumber() != chainLatest.GetRevisionNumber() {
+		return 0
+	}
+	return int64(chainLatest.GetRevisionHeight()) - int64(height.GetRevisionHeight())
+}
+
+// HeightLagAlert is the payload of the webhook alert
+type HeightLagAlert struct {
+	Path    string `json:"path"`
+	ChainID string `json:"chain_id"`
+	// Kind is the height known to the relayer compared with the latest height of the chain: "latest" or "finalized"
+	Kind string `json:"kind"`
+	// Alarm is true when the height enters the alarm, and false when it leaves
+	Alarm bool `json:"alarm"`
+	// Lag is the number of blocks by which the height lags behind the latest height of the chain, which is negative if it is ahead
+	Lag int64 `json:"lag"`
+	// RelayerHeight and ChainHeight are the revision heights compared
+	RelayerHeight uint64    `json:"relayer_height"`
+	ChainHeight   uint64    `json:"chain_height"`
+	Threshold     uint64    `json:"threshold"`
+	Time          time.Time `json:"time"`
+}
+
+// heightLagAlarm keeps track of the heights in alarm
+type heightLagAlarm struct {
+	path   string
+	cfg    *HeightLagAlarmCfg
+	notify func(ctx context.Context, alert *HeightLagAlert) error
+
+	mu      sync.Mutex
+	alarmed map[string]bool
+}
+
+func newHeightLagAlarm(pathName string, cfg *HeightLagAlarmCfg) *heightLagAlarm {
+	send := cfg.WebhookCfg.sender()
+	return &heightLagAlarm{
+		path: pathName,
+		cfg:  cfg,
+		notify: func(ctx context.Context, alert *HeightLagAlert) error {
+			return send(ctx, alert)
+		},
+		alarmed: make(map[string]bool),
+	}
+}
+
+// update updates the alarm state of the height of the kind with its lag,
+// and returns an alert if the height enters or leaves the alarm
+func (a *heightLagAlarm) update(chainID, kind string, height, chainLatest exported.Height, now time.Time) *HeightLagAlert {
+	threshold := a.cfg.LatestThreshold
+	if kind == HeightLagFinalized {
+		threshold = a.cfg.FinalizedThreshold
+	}
+	if threshold == 0 {
+		return nil
+	}
+	lag := HeightLag(height, chainLatest)
+	alarm := lag > int64(threshold) || -lag > int64(threshold)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := chainID + "/" + kind
+	if alarm == a.alarmed[key] {
+		return nil
+	}
+	a.alarmed[key] = alarm
+	return &HeightLagAlert{
+		Path:          a.path,
+		ChainID:       chainID,
+		Kind:          kind,
+		Alarm:         alarm,
+		Lag:           lag,
+		RelayerHeight: height.GetRevisionHeight(),
+		ChainHeight:   chainLatest.GetRevisionHeight(),
+		Threshold:     threshold,
+		Time:          now,
+	}
+}
+
+// SetHeightLagAlarm enables the alarm raised when the heights known to the relayer lag behind the latest heights of the chains
+func (srv *RelayService) SetHeightLagAlarm(pathName string, cfg *HeightLagAlarmCfg) {
+	srv.heightLagAlarm = newHeightLagAlarm(pathName, cfg)
+}
+
+// checkHeightLags compares the heights of the chains updated in SyncHeaders with the latest heights reported by the chains
+func (srv *RelayService) checkHeightLags() {
+	if srv.heightLagAlarm == nil {
+		return
+	}
+	for _, chain := range []*ProvableChain{srv.src, srv.dst} {
+		srv.checkHeightLag(chain)
+	}
+}
+
+func (srv *RelayService) checkHeightLag(chain *ProvableChain) {
+	logger := GetChainLogger(chain)
+	chainLatest, err := chain.LatestHeight()
+	if err != nil {
+		logger.Error("failed to get the latest height to check the height lag", err)
+		return
+	}
+	heights := srv.sh.GetChainHeights(chain.ChainID())
+	for _, h := range []struct {
+		kind   string
+		height exported.Height
+	}{{HeightLagLatest, heights.Latest}, {HeightLagFinalized, heights.Finalized}} {
+		if h.height == nil {
+			continue
+		}
+		metrics.HeightLagGauge.Set(HeightLag(h.height, chainLatest),
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("kind").String(h.kind),
+		)
+		alert := srv.heightLagAlarm.update(chain.ChainID(), h.kind, h.height, chainLatest, time.Now())
+		if alert == nil {
+			continue
+		}
+		if alert.Alarm {
+			logger.Warn("the height known to the relayer differs from the latest height of the chain beyond the threshold",
+				"kind", alert.Kind, "relayer_height", alert.RelayerHeight, "chain_height", alert.ChainHeight, "lag", alert.Lag, "threshold", alert.Threshold)
+		} else {
+			logger.Info("the height known to the relayer is back within the threshold",
+				"kind", alert.Kind, "relayer_height", alert.RelayerHeight, "chain_height", alert.ChainHeight, "lag", alert.Lag, "threshold", alert.Threshold)
+		}
+		// the alert is sent in the background not to delay the relay
+		go func() {
+			if err := srv.heightLagAlarm.notify(context.TODO(), alert); err != nil {
+				logger.Error("failed to send the height lag alert", err)
+			}
+		}()
+	}
+}
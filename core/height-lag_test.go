@@ -0,0 +1,123 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// aheadProver is a misbehaving mock prover reporting the latest height `ahead` blocks higher than the chain
+type aheadProver struct {
+	*mockprover.Prover
+	chain interface {
+		LatestHeight() (exported.Height, error)
+	}
+	ahead uint64
+}
+
+func (pr *aheadProver) GetLatestHeight() (exported.Height, error) {
+	h, err := pr.chain.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	return clienttypes.NewHeight(h.GetRevisionNumber(), h.GetRevisionHeight()+pr.ahead), nil
+}
+
+func TestHeightLagAlarmCfgValidate(t *testing.T) {
+	cases := map[string]struct {
+		cfg   core.HeightLagAlarmCfg
+		valid bool
+	}{
+		"latest":          {core.HeightLagAlarmCfg{LatestThreshold: 5}, true},
+		"finalized":       {core.HeightLagAlarmCfg{FinalizedThreshold: 100, WebhookCfg: core.WebhookCfg{WebhookURL: "https://example.com/hook", Timeout: "5s"}}, true},
+		"no threshold":    {core.HeightLagAlarmCfg{}, false},
+		"invalid webhook": {core.HeightLagAlarmCfg{LatestThreshold: 5, WebhookCfg: core.WebhookCfg{WebhookURL: "example.com"}}, false},
+		"invalid timeout": {core.HeightLagAlarmCfg{LatestThreshold: 5, WebhookCfg: core.WebhookCfg{Timeout: "-1s"}}, false},
+	}
+	for name, c := range cases {
+		if err := c.cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected result: %v", name, err)
+		}
+	}
+
+	if lag := core.HeightLag(clienttypes.NewHeight(1, 90), clienttypes.NewHeight(1, 100)); lag != 10 {
+		t.Errorf("unexpected lag: %d", lag)
+	}
+	if lag := core.HeightLag(clienttypes.NewHeight(1, 105), clienttypes.NewHeight(1, 100)); lag != -5 {
+		t.Errorf("unexpected lag: %d", lag)
+	}
+	if lag := core.HeightLag(clienttypes.NewHeight(1, 90), clienttypes.NewHeight(2, 1)); lag != 0 {
+		t.Errorf("unexpected lag across revisions: %d", lag)
+	}
+}
+
+func TestHeightLagAlarm(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	alerts := make(chan core.HeightLagAlert, 4)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert core.HeightLagAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Error(err)
+		}
+		alerts <- alert
+	}))
+	defer webhook.Close()
+	receive := func() core.HeightLagAlert {
+		t.Helper()
+		select {
+		case alert := <-alerts:
+			return alert
+		case <-time.After(5 * time.Second):
+			t.Fatal("no alert is sent")
+			return core.HeightLagAlert{}
+		}
+	}
+
+	chains, sh := newScriptedChains(t)
+	prover := &aheadProver{Prover: mockprover.NewProver(chains[0].Chain, mockprover.ProverConfig{}), chain: chains[0].Chain, ahead: 5}
+	if err := chains[0].EnableProverSwap(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chains[0].SwapProver(context.TODO(), prover); err != nil {
+		t.Fatal(err)
+	}
+	srv := core.NewRelayService(&scriptedStrategy{}, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	srv.SetHeightLagAlarm("test-path", &core.HeightLagAlarmCfg{LatestThreshold: 2, FinalizedThreshold: 100, WebhookCfg: core.WebhookCfg{WebhookURL: webhook.URL}})
+
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	alert := receive()
+	if !alert.Alarm || alert.ChainID != "ibc0" || alert.Kind != core.HeightLagLatest || alert.Lag != -5 || alert.Path != "test-path" {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+
+	// the alarm is cleared when the prover gets back in sync with the chain
+	prover.ahead = 0
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if alert := receive(); alert.Alarm || alert.ChainID != "ibc0" || alert.Lag != 0 {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+	select {
+	case alert := <-alerts:
+		t.Errorf("unexpected alert: %+v", alert)
+	default:
+	}
+}
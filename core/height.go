@@ -0,0 +1,41 @@
+package core
+
+import (
+	"fmt"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// Heights consist of a revision number and a revision height, and the revision height may be reset when a chain
+// performs a coordinated upgrade bumping the revision number (e.g. "chain-1" to "chain-2").
+// The helpers below never mix revision heights of different revisions.
+
+// HeightAfter returns the height `delta` blocks after `h` in the same revision
+func HeightAfter(h ibcexported.Height, delta uint64) clienttypes.Height {
+	return clienttypes.NewHeight(h.GetRevisionNumber(), h.GetRevisionHeight()+delta)
+}
+
+// BlocksBetween returns the number of blocks from `from` to `to`.
+// It returns false if they are in different revisions or `to` is lower than `from`, where the number of blocks is unknown.
+func BlocksBetween(from, to ibcexported.Height) (uint64, bool) {
+	if from.GetRevisionNumber() != to.GetRevisionNumber() || to.GetRevisionHeight() < from.GetRevisionHeight() {
+		return 0, false
+	}
+	return to.GetRevisionHeight() - from.GetRevisionHeight(), true
+}
+
+// CheckRevision returns an error if the revision number of `h` is not the current revision of the chain `chainID`.
+// A revision height of a previous revision doesn't identify a block of the current revision of the chain.
+func CheckRevision(chainID string, h ibcexported.Height) error {
+	if revision := clienttypes.ParseChainID(chainID); h.GetRevisionNumber() != revision {
+		return fmt.Errorf("height %v is not in the current revision %d of %s", h, revision, chainID)
+	}
+	return nil
+}
+
+// inPreviousRevision returns true if `h` is a height of a revision before the current revision of the chain `chainID`.
+// The blocks of such a height can't be queried on the chain anymore, while the event at the height is older than the upgrade.
+func inPreviousRevision(chainID string, h ibcexported.Height) bool {
+	return h.GetRevisionNumber() < clienttypes.ParseChainID(chainID)
+}
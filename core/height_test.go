@@ -0,0 +1,65 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestHeightAfter(t *testing.T) {
+	h := core.HeightAfter(clienttypes.NewHeight(2, 10), 1000)
+	if expected := clienttypes.NewHeight(2, 1010); !h.EQ(expected) {
+		t.Errorf("HeightAfter returns an unexpected result: actual=%v, expected=%v", h, expected)
+	}
+}
+
+func TestBlocksBetween(t *testing.T) {
+	cases := []struct {
+		from, to clienttypes.Height
+		blocks   uint64
+		ok       bool
+	}{
+		{clienttypes.NewHeight(1, 100), clienttypes.NewHeight(1, 105), 5, true},
+		{clienttypes.NewHeight(1, 100), clienttypes.NewHeight(1, 100), 0, true},
+		{clienttypes.NewHeight(1, 105), clienttypes.NewHeight(1, 100), 0, false},
+		// the revision height is reset by the upgrade, so the heights are not comparable by their revision heights
+		{clienttypes.NewHeight(1, 100), clienttypes.NewHeight(2, 3), 0, false},
+		{clienttypes.NewHeight(2, 3), clienttypes.NewHeight(1, 100), 0, false},
+	}
+	for i, c := range cases {
+		blocks, ok := core.BlocksBetween(c.from, c.to)
+		if blocks != c.blocks || ok != c.ok {
+			t.Errorf("case %d: BlocksBetween returns an unexpected result: actual=(%d, %v), expected=(%d, %v)", i, blocks, ok, c.blocks, c.ok)
+		}
+	}
+}
+
+func TestCheckRevision(t *testing.T) {
+	// a height observed before the counterparty is upgraded from "chain-1" to "chain-2"
+	h := clienttypes.NewHeight(1, 100)
+	if err := core.CheckRevision("chain-1", h); err != nil {
+		t.Errorf("CheckRevision returns an unexpected error: %v", err)
+	}
+	if err := core.CheckRevision("chain-2", h); err == nil {
+		t.Errorf("CheckRevision must fail for a height of the previous revision")
+	}
+	if err := core.CheckRevision("chain", clienttypes.NewHeight(0, 100)); err != nil {
+		t.Errorf("CheckRevision returns an unexpected error: %v", err)
+	}
+}
+
+func TestMsgTransferTimeoutHeightAfterUpgrade(t *testing.T) {
+	src := &core.PathEnd{ChainID: "src-1", PortID: "transfer", ChannelID: "channel-0"}
+	// the path config still has the chain ID before the counterparty is upgraded from "dst-1" to "dst-2"
+	dst := &core.PathEnd{ChainID: "dst-1", PortID: "transfer", ChannelID: "channel-0"}
+	latest := clienttypes.NewHeight(2, 3)
+
+	msg := src.MsgTransfer(dst, sdk.NewInt64Coin("stake", 1), "receiver", sdk.AccAddress("signer"), core.HeightAfter(latest, 1000), 0, "")
+	expected := clienttypes.NewHeight(2, 1003)
+	if actual := msg.(*transfertypes.MsgTransfer).TimeoutHeight; !actual.EQ(expected) {
+		t.Errorf("MsgTransfer has an unexpected timeout height: actual=%v, expected=%v", actual, expected)
+	}
+}
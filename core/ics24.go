@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
 	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
 )
 
@@ -12,7 +15,14 @@ func (pe *PathEnd) Vclient() error {
 	if pe.ClientID == "" {
 		return nil
 	}
-	return host.ClientIdentifierValidator(pe.ClientID)
+	if err := host.ClientIdentifierValidator(pe.ClientID); err != nil {
+		return pe.invalidIdentifierError("client-id", pe.ClientID, err)
+	}
+	clientType, seq, err := clienttypes.ParseClientIdentifier(pe.ClientID)
+	if err != nil {
+		return pe.invalidIdentifierError("client-id", pe.ClientID, err)
+	}
+	return pe.checkCanonicalIdentifier("client-id", pe.ClientID, clienttypes.FormatClientIdentifier(clientType, seq))
 }
 
 // Vconn validates the connection identifier in the path
@@ -20,25 +30,78 @@ func (pe *PathEnd) Vconn() error {
 	if pe.ConnectionID == "" {
 		return nil
 	}
-	return host.ConnectionIdentifierValidator(pe.ConnectionID)
+	if err := host.ConnectionIdentifierValidator(pe.ConnectionID); err != nil {
+		return pe.invalidIdentifierError("connection-id", pe.ConnectionID, err)
+	}
+	seq, err := conntypes.ParseConnectionSequence(pe.ConnectionID)
+	if err != nil {
+		return pe.invalidIdentifierError("connection-id", pe.ConnectionID, err)
+	}
+	return pe.checkCanonicalIdentifier("connection-id", pe.ConnectionID, conntypes.FormatConnectionIdentifier(seq))
 }
 
-// Vchan validates the channel identifier in the path
+// Vchan validates the channel identifier in the path.
+// The channel ID of a v2 path is the client ID, which is validated by Path.Validate.
 func (pe *PathEnd) Vchan() error {
-	if pe.ChannelID == "" {
+	if pe.ChannelID == "" || pe.Protocol() == ProtocolV2 {
 		return nil
 	}
-	return host.ChannelIdentifierValidator(pe.ChannelID)
+	if err := host.ChannelIdentifierValidator(pe.ChannelID); err != nil {
+		return pe.invalidIdentifierError("channel-id", pe.ChannelID, err)
+	}
+	seq, err := chantypes.ParseChannelSequence(pe.ChannelID)
+	if err != nil {
+		return pe.invalidIdentifierError("channel-id", pe.ChannelID, err)
+	}
+	return pe.checkCanonicalIdentifier("channel-id", pe.ChannelID, chantypes.FormatChannelIdentifier(seq))
 }
 
 // Vport validates the port identifier in the path
 func (pe *PathEnd) Vport() error {
-	return host.PortIdentifierValidator(pe.PortID)
+	if err := host.PortIdentifierValidator(pe.PortID); err != nil {
+		return pe.invalidIdentifierError("port-id", pe.PortID, err)
+	}
+	return nil
+}
+
+// Normalize trims the whitespaces around the identifiers and upper-cases the channel order,
+// which fixes the typos that pass unnoticed in the config file but make the queries fail
+func (pe *PathEnd) Normalize() {
+	pe.ChainID = strings.TrimSpace(pe.ChainID)
+	pe.ClientID = strings.TrimSpace(pe.ClientID)
+	pe.ConnectionID = strings.TrimSpace(pe.ConnectionID)
+	pe.ChannelID = strings.TrimSpace(pe.ChannelID)
+	pe.PortID = strings.TrimSpace(pe.PortID)
+	pe.Order = strings.ToUpper(strings.TrimSpace(pe.Order))
 }
 
-// Vversion validates the version identifier in the path
+// ValidateIdentifiers validates the identifiers in the path, which are allowed to be empty except the port ID
+func (pe *PathEnd) ValidateIdentifiers() error {
+	for _, v := range []func() error{pe.Vclient, pe.Vconn, pe.Vchan, pe.Vport} {
+		if err := v(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pe *PathEnd) invalidIdentifierError(field, id string, err error) error {
+	return fmt.Errorf("invalid %s on %s: %q: %w", field, pe.ChainID, id, err)
+}
+
+// checkCanonicalIdentifier returns an error if the identifier is not in the form generated by IBC (e.g. "channel-01" for "channel-1")
+func (pe *PathEnd) checkCanonicalIdentifier(field, id, canonical string) error {
+	if id != canonical {
+		return fmt.Errorf("invalid %s on %s: %q is not a canonical identifier (did you mean %q?)", field, pe.ChainID, id, canonical)
+	}
+	return nil
+}
+
+// Vversion validates the channel version in the path, which may be JSON metadata of a middleware stack
 func (pe *PathEnd) Vversion() error {
-	// TODO: version validation
+	if _, err := ParseChannelVersion(pe.Version); err != nil {
+		return fmt.Errorf("invalid version on %s: %q: %w", pe.ChainID, pe.Version, err)
+	}
 	return nil
 }
 
@@ -61,6 +124,9 @@ func (pe *PathEnd) Validate() error {
 	if err := pe.Vport(); err != nil {
 		return err
 	}
+	if err := pe.Vversion(); err != nil {
+		return err
+	}
 	if !(strings.ToUpper(pe.Order) == "ORDERED" || strings.ToUpper(pe.Order) == "UNORDERED") {
 		return fmt.Errorf("channel must be either 'ORDERED' or 'UNORDERED' is '%s'", pe.Order)
 	}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/cosmos/gogoproto/proto"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+)
+
+const (
+	ibcGoModule     = "github.com/cosmos/ibc-go/v7"
+	cosmosSDKModule = "github.com/cosmos/cosmos-sdk"
+	cometBFTModule  = "github.com/cometbft/cometbft"
+)
+
+// RelayerIdentity identifies exactly what is deployed, which is logged on startup and served by the admin API
+// so that bug reports and fleet management can tell the build, the modules and the config of a relayer
+type RelayerIdentity struct {
+	// Version is the version of the main module of the relayer binary (a pseudo-version or "(devel)" if built from a working tree)
+	Version string `json:"version"`
+	// Revision is the VCS revision the binary is built from, and Modified is true if the working tree had local changes
+	Revision  string `json:"revision,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+
+	IBCGoVersion     string `json:"ibc_go_version"`
+	CosmosSDKVersion string `json:"cosmos_sdk_version"`
+	CometBFTVersion  string `json:"cometbft_version"`
+	// ConnectionVersions are the IBC connection versions with the features proposed in connection handshakes
+	ConnectionVersions []string `json:"connection_versions"`
+
+	Modules []ModuleIdentity `json:"modules"`
+	Chains  []ChainIdentity  `json:"chains"`
+
+	// ConfigHash is the SHA-256 hash of the effective config (after the overrides are applied)
+	ConfigHash string `json:"config_hash"`
+	// Path is the path served by the relay service, if any
+	Path string `json:"path,omitempty"`
+}
+
+// ModuleIdentity identifies a module of the relayer
+type ModuleIdentity struct {
+	Name string `json:"name"`
+	// GoModule and Version are the Go module implementing the module and its version
+	GoModule string `json:"go_module"`
+	Version  string `json:"version"`
+}
+
+// ChainIdentity identifies the chain and prover types of a configured chain
+type ChainIdentity struct {
+	ChainID    string `json:"chain_id"`
+	ChainType  string `json:"chain_type"`
+	ProverType string `json:"prover_type"`
+}
+
+// NewRelayerIdentity returns the identity of the build of the running binary. The modules, the chains and the config are added by the caller.
+func NewRelayerIdentity() *RelayerIdentity {
+	id := &RelayerIdentity{
+		GoVersion: runtime.Version(),
+		Modules:   []ModuleIdentity{},
+		Chains:    []ChainIdentity{},
+	}
+	for _, v := range conntypes.GetCompatibleVersions() {
+		id.ConnectionVersions = append(id.ConnectionVersions, v.GetIdentifier()+":"+strings.Join(v.GetFeatures(), ","))
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return id
+	}
+	id.Version = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			id.Revision = s.Value
+		case "vcs.modified":
+			id.Modified = s.Value == "true"
+		}
+	}
+	id.IBCGoVersion = moduleVersion(info, ibcGoModule)
+	id.CosmosSDKVersion = moduleVersion(info, cosmosSDKModule)
+	id.CometBFTVersion = moduleVersion(info, cometBFTModule)
+	return id
+}
+
+// AddModule adds the module `name` implemented by `impl`, whose Go module is identified by the package of its type
+func (id *RelayerIdentity) AddModule(name string, impl interface{}) {
+	m := ModuleIdentity{Name: name}
+	t := reflect.TypeOf(impl)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		m.GoModule, m.Version = packageModule(info, t.PkgPath())
+	}
+	id.Modules = append(id.Modules, m)
+}
+
+// AddChain adds the chain configured with the chain and prover configs
+func (id *RelayerIdentity) AddChain(chainID string, chain ChainConfig, prover ProverConfig) {
+	id.Chains = append(id.Chains, ChainIdentity{
+		ChainID:    chainID,
+		ChainType:  "/" + proto.MessageName(chain),
+		ProverType: "/" + proto.MessageName(prover),
+	})
+}
+
+// LogAttrs returns the attributes of the identity for logging
+func (id *RelayerIdentity) LogAttrs() []interface{} {
+	attrs := []interface{}{
+		"version", id.Version,
+		"revision", id.Revision,
+		"modified", id.Modified,
+		"go_version", id.GoVersion,
+		"ibc_go_version", id.IBCGoVersion,
+		"cosmos_sdk_version", id.CosmosSDKVersion,
+		"cometbft_version", id.CometBFTVersion,
+		"connection_versions", id.ConnectionVersions,
+		"config_hash", id.ConfigHash,
+	}
+	for _, m := range id.Modules {
+		attrs = append(attrs, "module."+m.Name, m.GoModule+"@"+m.Version)
+	}
+	for _, c := range id.Chains {
+		attrs = append(attrs, "chain."+c.ChainID, c.ChainType+" "+c.ProverType)
+	}
+	if id.Path != "" {
+		attrs = append(attrs, "path", id.Path)
+	}
+	return attrs
+}
+
+// moduleVersion returns the version of the dependency `path`, taking the replacement into account
+func moduleVersion(info *debug.BuildInfo, path string) string {
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// packageModule returns the Go module and its version containing the package `pkgPath`
+func packageModule(info *debug.BuildInfo, pkgPath string) (string, string) {
+	if pkgPath == info.Main.Path || strings.HasPrefix(pkgPath, info.Main.Path+"/") {
+		return info.Main.Path, info.Main.Version
+	}
+	var module, version string
+	for _, dep := range info.Deps {
+		// the longest module path wins for nested modules
+		if (pkgPath == dep.Path || strings.HasPrefix(pkgPath, dep.Path+"/")) && len(dep.Path) > len(module) {
+			module = dep.Path
+			version = moduleVersion(info, dep.Path)
+		}
+	}
+	return module, version
+}
@@ -0,0 +1,34 @@
+package core_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/chains/tendermint"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+func TestRelayerIdentity(t *testing.T) {
+	id := core.NewRelayerIdentity()
+	if id.GoVersion != runtime.Version() || len(id.ConnectionVersions) == 0 {
+		t.Errorf("unexpected build identity: %+v", id)
+	}
+
+	id.AddChain("ibc0", &tendermint.ChainConfig{ChainId: "ibc0"}, &mockprover.ProverConfig{})
+	if c := id.Chains[0]; c.ChainID != "ibc0" ||
+		c.ChainType != "/relayer.chains.tendermint.config.ChainConfig" ||
+		c.ProverType != "/relayer.provers.mock.config.ProverConfig" {
+		t.Errorf("unexpected chain identity: %+v", c)
+	}
+
+	id.ConfigHash = "hash"
+	attrs := id.LogAttrs()
+	found := map[string]interface{}{}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		found[attrs[i].(string)] = attrs[i+1]
+	}
+	if found["config_hash"] != "hash" || found["chain.ibc0"] == nil {
+		t.Errorf("unexpected log attributes: %v", attrs)
+	}
+}
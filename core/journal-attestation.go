@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// AttestationSigner is an optional interface of Chain that signs the journal attestations with the relayer key.
+// The entries of the msgs submitted to a chain are attested only if the chain implements it.
+type AttestationSigner interface {
+	// SignAttestation signs `msg` with the relayer key and returns the signature and the public key of the key
+	SignAttestation(msg []byte) ([]byte, cryptotypes.PubKey, error)
+}
+
+// JournalAttestation is the signature of the relayer over the attestation statement of a journal entry,
+// which proves that the relayer submitted the msg of the entry
+type JournalAttestation struct {
+	KeyType   string `json:"key_type"`
+	PubKey    []byte `json:"pub_key"`
+	Signature []byte `json:"signature"`
+}
+
+// AttestationStatement returns the bytes signed by the attestation of the entry.
+// It consists of the fields identifying the relayed msg in a fixed text format, so that the signature
+// doesn't depend on how the entry is encoded in the journal.
+func (e *JournalEntry) AttestationStatement() []byte {
+	return []byte(fmt.Sprintf("yui-relayer journal attestation\npath=%s\nchain_id=%s\nmsg_type=%s\nsuccess=%t\ntx_id=%s\nsource=%s/%s\ndestination=%s/%s\nsequence=%d\ntime=%s\n",
+		e.Path,
+		e.ChainID,
+		e.MsgType,
+		e.Success,
+		e.TxID,
+		e.SourcePort, e.SourceChannel,
+		e.DestinationPort, e.DestinationChannel,
+		e.Sequence,
+		e.Time.UTC().Format(time.RFC3339Nano),
+	))
+}
+
+// VerifyAttestation verifies the attestation of the entry. It fails if the entry is not attested.
+func (e *JournalEntry) VerifyAttestation() error {
+	if e.Attestation == nil {
+		return errors.New("the entry is not attested")
+	}
+	var pubKey cryptotypes.PubKey
+	switch e.Attestation.KeyType {
+	case (&secp256k1.PubKey{}).Type():
+		pubKey = &secp256k1.PubKey{Key: e.Attestation.PubKey}
+	case (&ed25519.PubKey{}).Type():
+		pubKey = &ed25519.PubKey{Key: e.Attestation.PubKey}
+	default:
+		return fmt.Errorf("unsupported key type: %s", e.Attestation.KeyType)
+	}
+	if !pubKey.VerifySignature(e.AttestationStatement(), e.Attestation.Signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// attest signs the entry with the signer of the chain to which the msg was submitted
+func (e *JournalEntry) attest(signer AttestationSigner) error {
+	sig, pubKey, err := signer.SignAttestation(e.AttestationStatement())
+	if err != nil {
+		return err
+	}
+	e.Attestation = &JournalAttestation{
+		KeyType:   pubKey.Type(),
+		PubKey:    pubKey.Bytes(),
+		Signature: sig,
+	}
+	return nil
+}
+
+// EnableAttestations makes the journal sign the entries of the msgs submitted to the chains with their relayer keys.
+// It fails if a chain doesn't support signing the attestations.
+func (j *Journal) EnableAttestations(chains ...Chain) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.signers == nil {
+		j.signers = make(map[string]AttestationSigner)
+	}
+	for _, chain := range chains {
+		signer, ok := chain.(AttestationSigner)
+		if !ok {
+			return fmt.Errorf("chain %s doesn't support signing the journal attestations", chain.ChainID())
+		}
+		j.signers[chain.ChainID()] = signer
+	}
+	return nil
+}
+
+// ReadJournal reads the entries of the journal. It returns no entries if the journal doesn't exist.
+func ReadJournal(file string) ([]*JournalEntry, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entries []*JournalEntry
+	scanner := bufio.NewScanner(bytes.NewReader(bz))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the entry at line %d of the journal %s: %w", line, file, err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the journal %s: %w", file, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+type attestingChain struct {
+	core.Chain
+	chainID string
+	key     *secp256k1.PrivKey
+}
+
+func (c *attestingChain) ChainID() string {
+	return c.chainID
+}
+
+func (c *attestingChain) SignAttestation(msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	sig, err := c.key.Sign(msg)
+	return sig, c.key.PubKey(), err
+}
+
+func TestJournalAttestation(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := core.NewJournal(file, "ibc01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.EnableAttestations(&attestingChain{chainID: "ibc1", key: secp256k1.GenPrivKey()}); err != nil {
+		t.Fatal(err)
+	}
+	// the time is not in UTC to check that the statement doesn't depend on the time zone
+	now := time.Now().In(time.FixedZone("JST", 9*60*60))
+	if err := journal.Append([]*core.JournalEntry{
+		{Time: now, ChainID: "ibc1", MsgType: "/ibc.core.channel.v1.MsgRecvPacket", Success: true, TxID: "tx", SourcePort: "transfer", SourceChannel: "channel-0", Sequence: 1},
+		{Time: now, ChainID: "ibc0", MsgType: "/ibc.core.channel.v1.MsgAcknowledgement", Success: true, Sequence: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := core.ReadJournal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	if err := entries[0].VerifyAttestation(); err != nil {
+		t.Errorf("failed to verify the attestation: %v", err)
+	}
+	// the chain without the signer is not attested
+	if entries[1].Attestation != nil {
+		t.Errorf("unexpected attestation: %+v", entries[1].Attestation)
+	}
+
+	tampered := *entries[0]
+	tampered.Sequence = 2
+	if err := tampered.VerifyAttestation(); err == nil {
+		t.Error("the attestation of a tampered entry is verified")
+	}
+
+	type plainChain struct{ core.Chain }
+	if err := journal.EnableAttestations(plainChain{Chain: &attestingChain{chainID: "ibc2"}}); err == nil {
+		t.Error("a chain without the signer is accepted")
+	}
+}
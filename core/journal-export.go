@@ -0,0 +1,145 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// JournalExportFormat is the file format of the exported journal
+type JournalExportFormat string
+
+const (
+	JournalExportCSV JournalExportFormat = "csv"
+)
+
+// journalExportColumns are the columns of the exported journal.
+// The spend columns are of the whole tx including the msg, so they must be aggregated per tx_id.
+var journalExportColumns = []string{
+	"time",
+	"path",
+	"chain_id",
+	"counterparty_chain_id",
+	"msg_type",
+	"success",
+	"tx_id",
+	"source_port",
+	"source_channel",
+	"destination_port",
+	"destination_channel",
+	"sequence",
+	"ack_result",
+	"denom",
+	"amount",
+	"gas_used",
+	"fee",
+	"fees_earned",
+	"event_time",
+	"latency_ms",
+}
+
+// ExportJournal writes the entries of the journal of `path` to `w` in the format for accounting and analytics pipelines.
+// `path` is used to fill the counterparty chain of the entries and may be nil.
+func ExportJournal(w io.Writer, format JournalExportFormat, path *Path, entries []*JournalEntry) error {
+	rows := make([][]interface{}, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, journalExportRow(path, e))
+	}
+	switch format {
+	case JournalExportCSV:
+		return writeJournalCSV(w, rows)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// FilterJournalSince returns the entries recorded at or after `since`
+func FilterJournalSince(entries []*JournalEntry, since time.Time) []*JournalEntry {
+	var filtered []*JournalEntry
+	for _, e := range entries {
+		if !e.Time.Before(since) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func journalExportRow(path *Path, e *JournalEntry) []interface{} {
+	var counterparty string
+	if path != nil {
+		switch e.ChainID {
+		case path.Src.ChainID:
+			counterparty = path.Dst.ChainID
+		case path.Dst.ChainID:
+			counterparty = path.Src.ChainID
+		}
+	}
+	var denom, amount string
+	if e.Transfer != nil {
+		denom, amount = e.Transfer.BaseDenom, e.Transfer.Amount
+	}
+	// the spend is unknown if the tx failed or the chain doesn't report it
+	var gasUsed interface{}
+	if e.GasUsed > 0 {
+		gasUsed = int64(e.GasUsed)
+	}
+	var eventTime, latency interface{}
+	if l, ok := e.Latency(); ok {
+		eventTime, latency = *e.EventTime, l.Milliseconds()
+	}
+	return []interface{}{
+		e.Time,
+		e.Path,
+		e.ChainID,
+		counterparty,
+		e.MsgType,
+		e.Success,
+		e.TxID,
+		e.SourcePort,
+		e.SourceChannel,
+		e.DestinationPort,
+		e.DestinationChannel,
+		int64(e.Sequence),
+		string(e.AckResult),
+		denom,
+		amount,
+		gasUsed,
+		e.Fee.String(),
+		e.FeesEarned.String(),
+		eventTime,
+		latency,
+	}
+}
+
+func writeJournalCSV(w io.Writer, rows [][]interface{}) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(journalExportColumns); err != nil {
+		return err
+	}
+	record := make([]string, len(journalExportColumns))
+	for _, row := range rows {
+		for i, v := range row {
+			switch v := v.(type) {
+			case nil:
+				record[i] = ""
+			case string:
+				record[i] = v
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case bool:
+				record[i] = strconv.FormatBool(v)
+			case time.Time:
+				record[i] = v.UTC().Format(time.RFC3339Nano)
+			default:
+				return fmt.Errorf("unexpected value %v of the column %s", v, journalExportColumns[i])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
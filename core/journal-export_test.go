@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func testJournalEntries() []*core.JournalEntry {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	sent := now.Add(-1500 * time.Millisecond)
+	return []*core.JournalEntry{
+		{
+			Time: now, Path: "ibc01", ChainID: "ibc1", MsgType: "/ibc.core.channel.v1.MsgRecvPacket", Success: true, TxID: "AB",
+			SourcePort: "transfer", SourceChannel: "channel-0", DestinationPort: "transfer", DestinationChannel: "channel-1", Sequence: 3,
+			Transfer:  &core.TransferInfo{BaseDenom: "samoleans", Amount: "100"},
+			EventTime: &sent,
+			GasUsed:   80000, Fee: sdk.NewCoins(sdk.NewInt64Coin("stake", 2000)), FeesEarned: sdk.NewCoins(sdk.NewInt64Coin("stake", 500)),
+		},
+		{
+			Time: now.Add(-time.Hour), Path: "ibc01", ChainID: "ibc0", MsgType: "/ibc.core.channel.v1.MsgAcknowledgement", Success: false,
+			SourcePort: "transfer", SourceChannel: "channel-0", DestinationPort: "transfer", DestinationChannel: "channel-1", Sequence: 2,
+		},
+	}
+}
+
+func TestExportJournalCSV(t *testing.T) {
+	path := &core.Path{Src: &core.PathEnd{ChainID: "ibc0"}, Dst: &core.PathEnd{ChainID: "ibc1"}}
+	var buf bytes.Buffer
+	if err := core.ExportJournal(&buf, core.JournalExportCSV, path, testJournalEntries()); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+	expected := map[string]string{
+		"time":                  "2023-10-01T12:00:00Z",
+		"counterparty_chain_id": "ibc0",
+		"success":               "true",
+		"sequence":              "3",
+		"denom":                 "samoleans",
+		"amount":                "100",
+		"gas_used":              "80000",
+		"fee":                   "2000stake",
+		"fees_earned":           "500stake",
+		"latency_ms":            "1500",
+	}
+	for name, v := range expected {
+		if row[name] != v {
+			t.Errorf("unexpected %s: %q != %q", name, row[name], v)
+		}
+	}
+
+	row = make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[2][i]
+	}
+	// the spend and the latency are unknown
+	for _, name := range []string{"gas_used", "fee", "event_time", "latency_ms"} {
+		if row[name] != "" {
+			t.Errorf("unexpected %s: %q", name, row[name])
+		}
+	}
+
+	filtered := core.FilterJournalSince(testJournalEntries(), time.Date(2023, 10, 1, 11, 30, 0, 0, time.UTC))
+	if len(filtered) != 1 || filtered[0].Sequence != 3 {
+		t.Errorf("unexpected filtered entries: %v", filtered)
+	}
+
+	if err := core.ExportJournal(&buf, "parquet", nil, nil); err == nil {
+		t.Error("an unsupported format is accepted")
+	}
+}
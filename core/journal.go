@@ -0,0 +1,170 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// JournalFile returns the path of the relay journal of the path
+func JournalFile(homePath, pathName string) string {
+	return filepath.Join(homePath, "journal", pathName+".jsonl")
+}
+
+// JournalEntry is a record of a packet msg submitted by the relay service
+type JournalEntry struct {
+	Time    time.Time `json:"time"`
+	Path    string    `json:"path"`
+	Group   string    `json:"group,omitempty"` // group of the path, which is empty if the path doesn't belong to a group
+	ChainID string    `json:"chain_id"`        // chain to which the msg was submitted
+	MsgType string    `json:"msg_type"`
+	// Direction is the direction of the path in which the msg relays the packet or the acknowledgement
+	Direction RelayDirection `json:"direction,omitempty"`
+	Success   bool           `json:"success"`
+	TxID      string         `json:"tx_id,omitempty"`
+
+	SourcePort         string `json:"source_port"`
+	SourceChannel      string `json:"source_channel"`
+	DestinationPort    string `json:"destination_port"`
+	DestinationChannel string `json:"destination_channel"`
+	Sequence           uint64 `json:"sequence"`
+
+	Data     *PacketData   `json:"data,omitempty"`
+	Transfer *TransferInfo `json:"transfer,omitempty"`
+
+	// AckResult and AckError are the result decoded from the acknowledgement of a MsgAcknowledgement
+	AckResult AckResult `json:"ack_result,omitempty"`
+	AckError  string    `json:"ack_error,omitempty"`
+
+	// EventTime is the time of the block including the event relayed by the msg, i.e. the send_packet event for
+	// MsgRecvPacket and MsgTimeout and the write_acknowledgement event for MsgAcknowledgement
+	EventTime *time.Time `json:"event_time,omitempty"`
+
+	// GasUsed, Fee and FeesEarned are the spend of the whole tx including the msg, which may include other msgs.
+	// FeesEarned is the ICS-29 relayer fees paid to the relayer by the tx.
+	GasUsed    uint64    `json:"gas_used,omitempty"`
+	Fee        sdk.Coins `json:"fee,omitempty"`
+	FeesEarned sdk.Coins `json:"fees_earned,omitempty"`
+
+	// Attestation is the signature of the relayer over the entry, which is set if the attestations are enabled
+	Attestation *JournalAttestation `json:"attestation,omitempty"`
+}
+
+// Journal is an append-only JSON-lines file recording the packet msgs submitted by the relay service
+type Journal struct {
+	mu    sync.Mutex
+	file  string
+	path  string
+	group string
+
+	// signers of the attestations by chain ID; the entries are not attested if empty
+	signers map[string]AttestationSigner
+}
+
+// NewJournal returns a journal that appends entries of the path to `file`
+func NewJournal(file, pathName string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, err
+	}
+	return &Journal{file: file, path: pathName}, nil
+}
+
+// SetGroup sets the group of the path recorded in the entries
+func (j *Journal) SetGroup(group string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.group = group
+}
+
+// Append appends the entries to the journal
+func (j *Journal) Append(entries []*JournalEntry) error {
+	if j == nil || len(entries) == 0 {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		e.Path = j.path
+		e.Group = j.group
+		if signer, ok := j.signers[e.ChainID]; ok {
+			// the entry is recorded without the attestation rather than lost
+			if err := e.attest(signer); err != nil {
+				logger := log.GetLogger().WithModule("core.journal")
+				logger.Error("failed to sign the journal attestation", err, "chain_id", e.ChainID, "sequence", e.Sequence)
+			}
+		}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
+
+// Latency returns the time from the event relayed by the msg to the submission of the msg, or false if unknown
+func (e *JournalEntry) Latency() (time.Duration, bool) {
+	if e.EventTime == nil {
+		return 0, false
+	}
+	return e.Time.Sub(*e.EventTime), true
+}
+
+// journalEntries builds the journal entries of the packet msgs in `msgs` submitted to `chain`.
+// A msg is regarded as successful if its msg ID is set.
+// The packet data is decoded by the registered decoders with the version of the channel returned by `channelVersion`.
+func journalEntries(chain ChainInfo, msgs []sdk.Msg, msgIDs []MsgID, channelVersion func(packet chantypes.Packet) string) []*JournalEntry {
+	now := time.Now()
+	var entries []*JournalEntry
+	for i, msg := range msgs {
+		var packet chantypes.Packet
+		switch msg := msg.(type) {
+		case *chantypes.MsgRecvPacket:
+			packet = msg.Packet
+		case *chantypes.MsgAcknowledgement:
+			packet = msg.Packet
+		case *chantypes.MsgTimeout:
+			packet = msg.Packet
+		case *chantypes.MsgTimeoutOnClose:
+			packet = msg.Packet
+		default:
+			continue
+		}
+		e := &JournalEntry{
+			Time:               now,
+			ChainID:            chain.ChainID(),
+			MsgType:            sdk.MsgTypeURL(msg),
+			Success:            i < len(msgIDs) && msgIDs[i] != nil,
+			SourcePort:         packet.SourcePort,
+			SourceChannel:      packet.SourceChannel,
+			DestinationPort:    packet.DestinationPort,
+			DestinationChannel: packet.DestinationChannel,
+			Sequence:           packet.Sequence,
+		}
+		if e.Success {
+			e.TxID = txIDOf(msgIDs[i])
+		}
+		if msg, ok := msg.(*chantypes.MsgAcknowledgement); ok {
+			e.AckResult, e.AckError = DecodeAcknowledgement(msg.Acknowledgement)
+		}
+		if data, ok := DecodePacketData(packet, channelVersion(packet)); ok {
+			e.Data = data
+			if info, ok := data.Value.(*TransferInfo); ok {
+				e.Transfer = info
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
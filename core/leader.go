@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// LeaderElector elects the leader among the relayer instances serving the same path.
+// Only the leader relays packets, and the other instances stay in the observe mode until they take over the leadership,
+// which prevents both the double spend of gas and gaps in the coverage when the leader fails.
+// Implementations may use a lock on a shared file system or an external lock service (e.g. etcd, Consul).
+type LeaderElector interface {
+	// TryAcquire tries to become or stay the leader and returns true if this instance is the leader.
+	// The leadership lasts for the TTL of the elector unless it is renewed by calling TryAcquire again.
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Release gives up the leadership so that a standby instance can take over immediately
+	Release(ctx context.Context) error
+
+	// TTL returns the time for which the leadership lasts without renewal
+	TTL() time.Duration
+}
+
+// retries to take the lock file of FileLeaderElector held by another instance
+const (
+	lockRetries    = 10
+	lockRetryDelay = 50 * time.Millisecond
+)
+
+// errFileLocked is returned by tryLockFile if the file is locked by another holder
+var errFileLocked = errors.New("the file is locked by another holder")
+
+// LeaderLease is the content of the lease file of FileLeaderElector
+type LeaderLease struct {
+	Holder    string    `json:"holder"`
+	RenewedAt time.Time `json:"renewed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileLeaderElector is a LeaderElector based on a lease file on a file system shared by the instances.
+// The leader renews the expiration of the lease, and another instance acquires the lease once it expires.
+//
+// The elector relies on the following assumptions:
+//   - The file system supports flock(2) across the instances (e.g. a local file system, or NFSv4 with locking enabled),
+//     which serializes the updates of the lease. The elector is not available on platforms without flock(2).
+//   - The clocks of the instances are synchronized well within the TTL, since the expiration written by an instance is
+//     compared with the clock of another instance.
+//   - The lease carries no fencing token checked by the chains. A leader stalled for longer than the TTL (e.g. by a VM pause)
+//     may submit msgs after another instance took over. The relay service renews the lease just before submitting msgs,
+//     which narrows the window, and the msgs submitted twice are rejected by the chains at the cost of the gas.
+type FileLeaderElector struct {
+	file   string
+	holder string
+	ttl    time.Duration
+}
+
+var _ LeaderElector = (*FileLeaderElector)(nil)
+
+// NewFileLeaderElector returns an elector using the lease file `file`.
+// `holder` identifies this instance and must be unique among the instances.
+func NewFileLeaderElector(file, holder string, ttl time.Duration) (*FileLeaderElector, error) {
+	if holder == "" {
+		return nil, fmt.Errorf("holder must not be empty")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("TTL must be positive: %v", ttl)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, err
+	}
+	return &FileLeaderElector{file: file, holder: holder, ttl: ttl}, nil
+}
+
+// TTL implements LeaderElector
+func (e *FileLeaderElector) TTL() time.Duration {
+	return e.ttl
+}
+
+// TryAcquire implements LeaderElector
+func (e *FileLeaderElector) TryAcquire(ctx context.Context) (bool, error) {
+	var acquired bool
+	err := e.withLock(func() error {
+		lease, err := e.load()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if lease != nil && lease.Holder != e.holder && now.Before(lease.ExpiresAt) {
+			return nil
+		}
+		acquired = true
+		return e.save(&LeaderLease{Holder: e.holder, RenewedAt: now, ExpiresAt: now.Add(e.ttl)})
+	})
+	return acquired && err == nil, err
+}
+
+// Release implements LeaderElector
+func (e *FileLeaderElector) Release(ctx context.Context) error {
+	return e.withLock(func() error {
+		lease, err := e.load()
+		if err != nil || lease == nil || lease.Holder != e.holder {
+			return err
+		}
+		return os.Remove(e.file)
+	})
+}
+
+// withLock runs `f` holding an exclusive flock(2) on the lock file, which serializes the read-modify-write of the lease among the instances.
+// The lock is released by the kernel if the holder crashes, so no stale lock is left. The lock file is never removed,
+// since another instance could otherwise lock a new file while the removed one is still locked.
+func (e *FileLeaderElector) withLock(f func() error) error {
+	lockFile := e.file + ".lock"
+	lock, err := os.OpenFile(lockFile, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	for i := 0; ; i++ {
+		err := tryLockFile(lock)
+		if err == nil {
+			break
+		} else if !errors.Is(err, errFileLocked) {
+			return fmt.Errorf("failed to lock %s: %w", lockFile, err)
+		} else if i >= lockRetries {
+			return fmt.Errorf("the lease file is locked by another instance: %s", lockFile)
+		}
+		time.Sleep(lockRetryDelay)
+	}
+	defer unlockFile(lock)
+	return f()
+}
+
+func (e *FileLeaderElector) load() (*LeaderLease, error) {
+	bz, err := os.ReadFile(e.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var lease LeaderLease
+	if err := json.Unmarshal(bz, &lease); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the lease file %s: %w", e.file, err)
+	}
+	return &lease, nil
+}
+
+func (e *FileLeaderElector) save(lease *LeaderLease) error {
+	bz, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(e.file, bz, 0600)
+}
+
+// leadership tracks whether the relay service is the leader
+type leadership struct {
+	elector LeaderElector
+	leader  atomic.Bool
+}
+
+// SetLeaderElector sets the elector of the leader among the instances serving the path.
+// The service relays packets only while it is the leader, and otherwise stays in the observe mode.
+func (srv *RelayService) SetLeaderElector(elector LeaderElector) {
+	srv.leadership = &leadership{elector: elector}
+}
+
+// isStandby returns true if a leader elector is set and the service is not the leader
+func (srv *RelayService) isStandby() bool {
+	return srv.leadership != nil && !srv.leadership.leader.Load()
+}
+
+// confirmLeadership renews the leadership just before submitting msgs, and returns false if the service is no longer the leader.
+// It always returns true if no leader elector is set.
+func (srv *RelayService) confirmLeadership(ctx context.Context) bool {
+	l := srv.leadership
+	if l == nil {
+		return true
+	}
+	leader, err := l.elector.TryAcquire(ctx)
+	if err != nil {
+		GetChannelPairLogger(srv.src, srv.dst).Warn("failed to renew the leadership", "error", err)
+	}
+	if was := l.leader.Swap(leader); was && !leader {
+		GetChannelPairLogger(srv.src, srv.dst).Info("lost the leadership; switched to the observe mode")
+	}
+	return leader
+}
+
+// runLeaderElection renews or tries to acquire the leadership at a third of the TTL until `ctx` is done,
+// and then releases the leadership. A failure to reach the elector is regarded as the loss of the leadership.
+func (srv *RelayService) runLeaderElection(ctx context.Context) {
+	l := srv.leadership
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	ticker := time.NewTicker(l.elector.TTL() / 3)
+	defer ticker.Stop()
+	for {
+		leader, err := l.elector.TryAcquire(ctx)
+		if err != nil {
+			logger.Warn("failed to acquire the leadership", "error", err)
+		}
+		if was := l.leader.Swap(leader); was != leader {
+			if leader {
+				logger.Info("became the leader; relaying packets")
+			} else {
+				logger.Info("lost the leadership; switched to the observe mode")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			l.leader.Store(false)
+			if err := l.elector.Release(context.Background()); err != nil {
+				logger.Error("failed to release the leadership", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// tryLockFile returns an error since flock(2) is not available on the platform
+func tryLockFile(f *os.File) error {
+	return fmt.Errorf("file locks are not supported on %s", runtime.GOOS)
+}
+
+// unlockFile does nothing since no file is locked on the platform
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestFileLeaderElector(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "leader.json")
+	ctx := context.TODO()
+	a, err := core.NewFileLeaderElector(file, "a", 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := core.NewFileLeaderElector(file, "b", 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := a.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("a must acquire the leadership: %v, %v", ok, err)
+	}
+	if ok, err := b.TryAcquire(ctx); err != nil || ok {
+		t.Fatalf("b must not acquire the leadership held by a: %v, %v", ok, err)
+	}
+	if ok, err := a.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("a must renew the leadership: %v, %v", ok, err)
+	}
+
+	// b takes over once the lease of a expires
+	time.Sleep(300 * time.Millisecond)
+	if ok, err := b.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("b must take over the expired leadership: %v, %v", ok, err)
+	}
+	if ok, err := a.TryAcquire(ctx); err != nil || ok {
+		t.Fatalf("a must not acquire the leadership held by b: %v, %v", ok, err)
+	}
+
+	// a takes over immediately when b releases the leadership
+	if err := b.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := a.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("a must acquire the released leadership: %v, %v", ok, err)
+	}
+}
+
+func TestFileLeaderElectorContention(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "leader.json")
+	// a lock file left by a crashed instance doesn't block the others
+	if err := os.WriteFile(file+".lock", nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	const instances = 8
+	var (
+		wg       sync.WaitGroup
+		acquired atomic.Int32
+	)
+	for i := 0; i < instances; i++ {
+		e, err := core.NewFileLeaderElector(file, fmt.Sprintf("instance-%d", i), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := e.TryAcquire(context.TODO())
+			if err != nil {
+				t.Error(err)
+			} else if ok {
+				acquired.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := acquired.Load(); n != 1 {
+		t.Fatalf("unexpected number of leaders: %d", n)
+	}
+}
+
+// scriptedElector returns the scripted results of TryAcquire in order, and false after they run out
+type scriptedElector struct {
+	results []bool
+}
+
+func (e *scriptedElector) TryAcquire(ctx context.Context) (bool, error) {
+	if len(e.results) == 0 {
+		return false, nil
+	}
+	ok := e.results[0]
+	e.results = e.results[1:]
+	return ok, nil
+}
+
+func (e *scriptedElector) Release(ctx context.Context) error {
+	return nil
+}
+
+func (e *scriptedElector) TTL() time.Duration {
+	return time.Minute
+}
+
+// sendCountingStrategy counts the submissions of the msgs
+type sendCountingStrategy struct {
+	*scriptedStrategy
+	sends int
+}
+
+func (st *sendCountingStrategy) Send(src, dst core.Chain, msgs *core.RelayMsgs) {
+	st.sends++
+	st.scriptedStrategy.Send(src, dst, msgs)
+}
+
+func TestLeadershipConfirmedBeforeSend(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, sh := newScriptedChains(t)
+	st := &sendCountingStrategy{scriptedStrategy: &scriptedStrategy{pending: []uint64{1}, own: map[uint64]bool{1: true}}}
+	srv := core.NewRelayService(st, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+
+	// the leadership is renewed just before the submission
+	srv.SetLeaderElector(&scriptedElector{results: []bool{true, true}})
+	if err := srv.RunOnce(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if st.sends != 1 {
+		t.Fatalf("the msgs are not submitted by the leader: %d", st.sends)
+	}
+
+	// the msgs are not submitted if the leadership is lost while building them
+	srv.SetLeaderElector(&scriptedElector{results: []bool{true, false}})
+	if err := srv.RunOnce(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if st.sends != 1 {
+		t.Errorf("the msgs are submitted after losing the leadership: %d", st.sends)
+	}
+}
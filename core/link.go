@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// LinkStage is a stage of Link
+type LinkStage string
+
+const (
+	LinkStageClients    LinkStage = "clients"
+	LinkStageConnection LinkStage = "connection"
+	LinkStageChannel    LinkStage = "channel"
+	// LinkStageCounterparty registers the clients as the counterparties of each other on a v2 path
+	LinkStageCounterparty LinkStage = "counterparty"
+)
+
+// LinkStageStatus is the result of a stage of Link
+type LinkStageStatus string
+
+const (
+	LinkStageCreated  LinkStageStatus = "created"
+	LinkStageExisting LinkStageStatus = "already exists"
+	LinkStageFailed   LinkStageStatus = "failed"
+	LinkStageNotRun   LinkStageStatus = "not run"
+	// LinkStageNotApplicable is the status of the connection and channel stages of a v2 path
+	LinkStageNotApplicable LinkStageStatus = "n/a"
+)
+
+// LinkStageResult is the summary of a stage of Link
+type LinkStageResult struct {
+	Stage   LinkStage       `json:"stage"`
+	Status  LinkStageStatus `json:"status"`
+	Elapsed time.Duration   `json:"elapsed"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Link creates the clients, the connection and the channel of the path in sequence.
+// On a v2 path, the clients are registered as the counterparties of each other instead of the connection and channel handshakes.
+// The stages already completed are skipped, and SyncHeaders is shared among the stages to avoid redundant header queries.
+// It returns the summary of all the stages even if a stage failed.
+func Link(ctx context.Context, pathName string, src, dst *ProvableChain, srcHeight, dstHeight exported.Height, policy BackoffPolicy) ([]LinkStageResult, error) {
+	if src.Path().Protocol() == ProtocolV2 {
+		return linkV2(ctx, pathName, src, dst, srcHeight, dstHeight)
+	}
+	results := []LinkStageResult{
+		{Stage: LinkStageClients, Status: LinkStageNotRun},
+		{Stage: LinkStageConnection, Status: LinkStageNotRun},
+		{Stage: LinkStageChannel, Status: LinkStageNotRun},
+	}
+
+	// the headers are shared by the connection and channel stages
+	var sh SyncHeaders
+	updateHeaders := func() error {
+		if sh != nil {
+			return sh.Updates(src, dst)
+		}
+		var err error
+		sh, err = NewSyncHeaders(src, dst)
+		return err
+	}
+
+	stages := []func() (bool, error){
+		func() (bool, error) {
+			return linkClients(ctx, pathName, src, dst, srcHeight, dstHeight)
+		},
+		// connection
+		func() (bool, error) {
+			if err := updateHeaders(); err != nil {
+				return false, err
+			}
+			srcConn, dstConn, err := QueryConnectionPair(sh.GetQueryContext(src.ChainID()), sh.GetQueryContext(dst.ChainID()), src, dst, false)
+			if err != nil {
+				return false, err
+			}
+			if srcConn.Connection.State == conntypes.OPEN && dstConn.Connection.State == conntypes.OPEN {
+				return false, nil
+			}
+			return true, createConnection(ctx, pathName, src, dst, sh, policy)
+		},
+		// channel
+		func() (bool, error) {
+			if err := updateHeaders(); err != nil {
+				return false, err
+			}
+			srcChan, dstChan, err := QueryChannelPair(sh.GetQueryContext(src.ChainID()), sh.GetQueryContext(dst.ChainID()), src, dst, false)
+			if err != nil {
+				return false, err
+			}
+			if srcChan.Channel.State == chantypes.OPEN && dstChan.Channel.State == chantypes.OPEN {
+				return false, nil
+			}
+			return true, createChannel(ctx, pathName, src, dst, sh, policy)
+		},
+	}
+
+	return runLinkStages(results, stages)
+}
+
+// linkV2 links a v2 path by creating the clients and registering them as the counterparties of each other
+func linkV2(ctx context.Context, pathName string, src, dst *ProvableChain, srcHeight, dstHeight exported.Height) ([]LinkStageResult, error) {
+	results := []LinkStageResult{
+		{Stage: LinkStageClients, Status: LinkStageNotRun},
+		{Stage: LinkStageCounterparty, Status: LinkStageNotRun},
+		{Stage: LinkStageConnection, Status: LinkStageNotApplicable},
+		{Stage: LinkStageChannel, Status: LinkStageNotApplicable},
+	}
+	if err := CheckProtocolSupport(src, dst); err != nil {
+		results[0].Status = LinkStageFailed
+		results[0].Error = err.Error()
+		return results, err
+	}
+	return runLinkStages(results, []func() (bool, error){
+		func() (bool, error) {
+			return linkClients(ctx, pathName, src, dst, srcHeight, dstHeight)
+		},
+		func() (bool, error) {
+			return RegisterCounterparties(ctx, pathName, src, dst)
+		},
+	})
+}
+
+// linkClients creates the clients of the path unless both of them exist
+func linkClients(ctx context.Context, pathName string, src, dst *ProvableChain, srcHeight, dstHeight exported.Height) (bool, error) {
+	if src.Path().ClientID != "" && dst.Path().ClientID != "" {
+		return false, nil
+	}
+	if err := CreateClients(ctx, pathName, src, dst, srcHeight, dstHeight); err != nil {
+		return false, err
+	}
+	if src.Path().ClientID == "" || dst.Path().ClientID == "" {
+		return false, fmt.Errorf("failed to create clients")
+	}
+	return true, nil
+}
+
+// runLinkStages runs the stages in sequence and records their results, stopping at the first failure
+func runLinkStages(results []LinkStageResult, stages []func() (bool, error)) ([]LinkStageResult, error) {
+	for i, stage := range stages {
+		start := time.Now()
+		created, err := stage()
+		results[i].Elapsed = time.Since(start)
+		switch {
+		case err != nil:
+			results[i].Status = LinkStageFailed
+			results[i].Error = err.Error()
+			return results, fmt.Errorf("%s stage failed: %w", results[i].Stage, err)
+		case created:
+			results[i].Status = LinkStageCreated
+		default:
+			results[i].Status = LinkStageExisting
+		}
+	}
+	return results, nil
+}
+
+// LinkTask is a path to link by LinkAll
+type LinkTask struct {
+	PathName string
+	// ChainIDs are the chains of the path, which are not shared with the other paths linked at the same time
+	ChainIDs [2]string
+	// Link links the path (e.g. by Link) and returns the summary of the stages
+	Link func(ctx context.Context) ([]LinkStageResult, error)
+}
+
+// PathLinkResult is the summary of a path linked by LinkAll
+type PathLinkResult struct {
+	PathName string            `json:"path_name"`
+	Stages   []LinkStageResult `json:"stages"`
+	Elapsed  time.Duration     `json:"elapsed"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// LinkAll links the paths concurrently with at most `parallelism` paths at a time, and returns the summaries in the order of `tasks`.
+// Paths sharing a chain are linked one after another, because a chain holds the path end of a single path
+// and the txs to the chain are signed with the account sequence of a single key.
+// The paths not started yet when `ctx` is done are reported as failed.
+func LinkAll(ctx context.Context, tasks []LinkTask, parallelism int) []PathLinkResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	results := make([]PathLinkResult, len(tasks))
+	pending := make([]int, len(tasks))
+	for i := range tasks {
+		pending[i] = i
+		results[i].PathName = tasks[i].PathName
+	}
+	busy := make(map[string]bool)
+	done := make(chan int)
+	running := 0
+	for len(pending) > 0 || running > 0 {
+		if err := ctx.Err(); err != nil {
+			for _, i := range pending {
+				results[i].Error = fmt.Sprintf("not started: %v", err)
+			}
+			pending = nil
+		}
+		for j := 0; j < len(pending) && running < parallelism; {
+			i := pending[j]
+			task := tasks[i]
+			if busy[task.ChainIDs[0]] || busy[task.ChainIDs[1]] {
+				j++
+				continue
+			}
+			pending = append(pending[:j], pending[j+1:]...)
+			busy[task.ChainIDs[0]], busy[task.ChainIDs[1]] = true, true
+			running++
+			go func() {
+				start := time.Now()
+				stages, err := task.Link(ctx)
+				results[i].Stages = stages
+				results[i].Elapsed = time.Since(start)
+				if err != nil {
+					results[i].Error = err.Error()
+				}
+				done <- i
+			}()
+		}
+		if running == 0 {
+			continue
+		}
+		i := <-done
+		running--
+		delete(busy, tasks[i].ChainIDs[0])
+		delete(busy, tasks[i].ChainIDs[1])
+	}
+	return results
+}
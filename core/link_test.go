@@ -0,0 +1,81 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestLinkAll(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		busy       = make(map[string]string)
+		running    int
+		maxRunning int
+	)
+	task := func(name, src, dst string, fail bool) core.LinkTask {
+		return core.LinkTask{
+			PathName: name,
+			ChainIDs: [2]string{src, dst},
+			Link: func(ctx context.Context) ([]core.LinkStageResult, error) {
+				mu.Lock()
+				for _, chainID := range []string{src, dst} {
+					if other, ok := busy[chainID]; ok {
+						t.Errorf("%s and %s are linked at the same time on %s", name, other, chainID)
+					}
+					busy[chainID] = name
+				}
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mu.Lock()
+				delete(busy, src)
+				delete(busy, dst)
+				running--
+				mu.Unlock()
+				if fail {
+					return nil, errors.New("handshake failed")
+				}
+				return []core.LinkStageResult{{Stage: core.LinkStageClients, Status: core.LinkStageCreated}}, nil
+			},
+		}
+	}
+	tasks := []core.LinkTask{
+		task("ab", "a", "b", false),
+		task("bc", "b", "c", false),
+		task("cd", "c", "d", true),
+		task("de", "d", "e", false),
+		task("fg", "f", "g", false),
+		task("hi", "h", "i", false),
+	}
+
+	results := core.LinkAll(context.TODO(), tasks, 2)
+	if maxRunning != 2 {
+		t.Errorf("unexpected max parallelism: %d", maxRunning)
+	}
+	for i, r := range results {
+		if r.PathName != tasks[i].PathName {
+			t.Errorf("unexpected order of the results: %d: %s", i, r.PathName)
+		}
+		if failed := r.Error != ""; failed != (r.PathName == "cd") {
+			t.Errorf("unexpected result of %s: %q", r.PathName, r.Error)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	for _, r := range core.LinkAll(ctx, tasks, 2) {
+		if r.Error == "" || r.Stages != nil {
+			t.Errorf("%s must not be started after the context is done", r.PathName)
+		}
+	}
+}
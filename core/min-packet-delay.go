@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// MinPacketDelayCfg is the minimum age of a packet before it is relayed, which is useful for the chains with shallow reorgs
+// or to yield the priority to a primary relayer. A packet is relayed after both the blocks and the duration elapse.
+type MinPacketDelayCfg struct {
+	// Blocks is the number of blocks produced on the sending chain after the block containing the packet
+	Blocks uint64 `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+
+	// Duration is the time elapsed since the block containing the packet (e.g. "30s")
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *MinPacketDelayCfg) Validate() error {
+	if cfg.Duration != "" {
+		if d, err := time.ParseDuration(cfg.Duration); err != nil {
+			return fmt.Errorf("min-packet-delay: invalid duration: %w", err)
+		} else if d < 0 {
+			return fmt.Errorf("min-packet-delay: negative duration: %s", cfg.Duration)
+		}
+	}
+	return nil
+}
+
+func (cfg *MinPacketDelayCfg) duration() time.Duration {
+	d, _ := time.ParseDuration(cfg.Duration)
+	return d
+}
+
+// Elapsed returns true if the delay has elapsed for a packet sent at `eventHeight` and `eventTime`,
+// given the latest height of the sending chain and the current time
+func (cfg *MinPacketDelayCfg) Elapsed(eventHeight, latestHeight ibcexported.Height, eventTime, now time.Time) bool {
+	if cfg == nil {
+		return true
+	}
+	if eventHeight.GetRevisionNumber() == latestHeight.GetRevisionNumber() &&
+		eventHeight.GetRevisionHeight()+cfg.Blocks > latestHeight.GetRevisionHeight() {
+		return false
+	}
+	return !now.Before(eventTime.Add(cfg.duration()))
+}
+
+// filterMinPacketDelay returns the packets sent on `chain` for which the minimum delay has elapsed.
+// The packets are sorted by their event heights in the order of the sequences, so the packets after a delayed one are also delayed.
+func filterMinPacketDelay(chain *ProvableChain, packets PacketInfoList, cfg *MinPacketDelayCfg) (PacketInfoList, error) {
+	if cfg == nil || len(packets) == 0 || (cfg.Blocks == 0 && cfg.duration() == 0) {
+		return packets, nil
+	}
+	latest, err := latestHeight(chain)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	timestamps := make(map[uint64]time.Time)
+	var ret PacketInfoList
+	for _, p := range packets {
+		// the packets sent before the upgrade of the chain have been kept through the upgrade
+		if inPreviousRevision(chain.ChainID(), p.EventHeight) {
+			ret = append(ret, p)
+			continue
+		}
+		var eventTime time.Time
+		if cfg.duration() > 0 {
+			h := p.EventHeight.GetRevisionHeight()
+			ts, ok := timestamps[h]
+			if !ok {
+				if ts, err = chain.Timestamp(p.EventHeight); err != nil {
+					return nil, err
+				}
+				timestamps[h] = ts
+			}
+			eventTime = ts
+		}
+		if cfg.Elapsed(p.EventHeight, latest, eventTime, now) {
+			ret = append(ret, p)
+		}
+	}
+	if delayed := len(packets) - len(ret); delayed > 0 {
+		GetChainLogger(chain).Debug("packets are delayed by min-packet-delay", "num_delayed", delayed)
+	}
+	return ret, nil
+}
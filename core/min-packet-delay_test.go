@@ -0,0 +1,46 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestMinPacketDelayElapsed(t *testing.T) {
+	now := time.Now()
+	cfg := &core.MinPacketDelayCfg{Blocks: 3, Duration: "30s"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]struct {
+		eventHeight, latestHeight uint64
+		age                       time.Duration
+		elapsed                   bool
+	}{
+		"elapsed":            {10, 13, time.Minute, true},
+		"not enough blocks":  {10, 12, time.Minute, false},
+		"not enough time":    {10, 20, 10 * time.Second, false},
+		"exactly at the age": {10, 13, 30 * time.Second, true},
+	}
+	for name, c := range cases {
+		elapsed := cfg.Elapsed(clienttypes.NewHeight(0, c.eventHeight), clienttypes.NewHeight(0, c.latestHeight), now.Add(-c.age), now)
+		if elapsed != c.elapsed {
+			t.Errorf("%s: unexpected result: %v", name, elapsed)
+		}
+	}
+
+	// the blocks are not counted across an upgrade of the revision
+	if !cfg.Elapsed(clienttypes.NewHeight(0, 10), clienttypes.NewHeight(1, 1), now.Add(-time.Minute), now) {
+		t.Error("the packet sent before the revision upgrade is delayed")
+	}
+	var disabled *core.MinPacketDelayCfg
+	if !disabled.Elapsed(clienttypes.NewHeight(0, 10), clienttypes.NewHeight(0, 10), now, now) {
+		t.Error("the packet is delayed without the config")
+	}
+
+	if err := (&core.MinPacketDelayCfg{Duration: "soon"}).Validate(); err == nil {
+		t.Error("an invalid duration is accepted")
+	}
+}
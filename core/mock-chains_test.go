@@ -0,0 +1,49 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// wrapMockChain wraps a mock chain and its prover, e.g. to implement optional interfaces or record the calls
+type wrapMockChain func(chain *mock.Chain, prover *mockprover.Prover) (core.Chain, core.Prover)
+
+// newMockChain returns a mock chain and a ProvableChain of it with a mock prover, which are wrapped by `wrap` if not nil
+func newMockChain(t *testing.T, chainID string, wrap wrapMockChain) (*mock.Chain, *core.ProvableChain) {
+	t.Helper()
+	chain, err := mock.NewChain(chainID, sdk.NewCoins())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prover := mockprover.NewProver(chain, mockprover.ProverConfig{})
+	var pc *core.ProvableChain
+	if wrap != nil {
+		pc = core.NewProvableChain(wrap(chain, prover))
+	} else {
+		pc = core.NewProvableChain(chain, prover)
+	}
+	if err := pc.Init("", 10*time.Second, core.MakeCodec(mock.RegisterInterfaces, mockprover.RegisterInterfaces), false); err != nil {
+		t.Fatal(err)
+	}
+	return chain, pc
+}
+
+// newMockChains returns a pair of mock chains with mock provers, which are wrapped by `wrap` if not nil, with `ends` set
+func newMockChains(t *testing.T, ends [2]*core.PathEnd, wrap wrapMockChain) [2]*core.ProvableChain {
+	t.Helper()
+	var chains [2]*core.ProvableChain
+	for i, end := range ends {
+		_, chains[i] = newMockChain(t, end.ChainID, wrap)
+	}
+	for i, end := range ends {
+		if err := chains[i].SetRelayInfo(end, chains[1-i], ends[1-i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chains
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// CheckMsgOrder checks the invariants of the order of the msgs submitted to a chain in a relay round.
+// RelayMsgs.Send splits the msgs into txs without reordering them, so the invariants hold within every tx:
+//
+//  1. every MsgUpdateClient precedes all the msgs carrying proofs (handshakes, packets, acknowledgements and timeouts),
+//     so that the consensus states the proofs are verified against are stored before the proofs are verified
+//  2. the packet msgs of the same kind on the same channel are in ascending order of sequence,
+//     which is required for ordered channels and avoids wasting gas on unordered channels
+//
+// RelayMsgs.Send refuses to send the msgs to a chain if they violate the invariants.
+func CheckMsgOrder(msgs []sdk.Msg) error {
+	var (
+		firstProof = -1
+		lastSeqs   = make(map[string]uint64)
+	)
+	for i, msg := range msgs {
+		if isUpdateClientMsg(msg) {
+			if firstProof >= 0 {
+				return fmt.Errorf("MsgUpdateClient at index %d follows %T at index %d carrying a proof", i, msgs[firstProof], firstProof)
+			}
+			continue
+		}
+		if firstProof < 0 && hasProof(msg) {
+			firstProof = i
+		}
+		key, seq, ok := packetMsgSequence(msg)
+		if !ok {
+			continue
+		}
+		if last, found := lastSeqs[key]; found && seq <= last {
+			return fmt.Errorf("%T at index %d has sequence %d not greater than the preceding sequence %d", msg, i, seq, last)
+		}
+		lastSeqs[key] = seq
+	}
+	return nil
+}
+
+// hasProof returns true if the msg carries a proof verified by a light client
+func hasProof(msg sdk.Msg) bool {
+	switch msg.(type) {
+	case *conntypes.MsgConnectionOpenTry, *conntypes.MsgConnectionOpenAck, *conntypes.MsgConnectionOpenConfirm,
+		*chantypes.MsgChannelOpenTry, *chantypes.MsgChannelOpenAck, *chantypes.MsgChannelOpenConfirm, *chantypes.MsgChannelCloseConfirm,
+		*chantypes.MsgRecvPacket, *chantypes.MsgAcknowledgement, *chantypes.MsgTimeout, *chantypes.MsgTimeoutOnClose,
+		*clienttypes.MsgUpgradeClient:
+		return true
+	default:
+		return false
+	}
+}
+
+// packetMsgSequence returns the key of the kind and the channel of a packet msg and its sequence
+func packetMsgSequence(msg sdk.Msg) (string, uint64, bool) {
+	switch msg := msg.(type) {
+	case *chantypes.MsgRecvPacket:
+		p := msg.Packet
+		return "recv/" + p.DestinationPort + "/" + p.DestinationChannel, p.Sequence, true
+	case *chantypes.MsgAcknowledgement:
+		p := msg.Packet
+		return "ack/" + p.SourcePort + "/" + p.SourceChannel, p.Sequence, true
+	case *chantypes.MsgTimeout:
+		p := msg.Packet
+		return "timeout/" + p.SourcePort + "/" + p.SourceChannel, p.Sequence, true
+	case *chantypes.MsgTimeoutOnClose:
+		p := msg.Packet
+		return "timeout/" + p.SourcePort + "/" + p.SourceChannel, p.Sequence, true
+	default:
+		return "", 0, false
+	}
+}
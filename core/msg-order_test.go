@@ -0,0 +1,71 @@
+package core_test
+
+import (
+	"slices"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func recvMsg(channelID string, seq uint64) sdk.Msg {
+	return &chantypes.MsgRecvPacket{Packet: chantypes.Packet{Sequence: seq, DestinationPort: "transfer", DestinationChannel: channelID}}
+}
+
+func ackMsg(channelID string, seq uint64) sdk.Msg {
+	return &chantypes.MsgAcknowledgement{Packet: chantypes.Packet{Sequence: seq, SourcePort: "transfer", SourceChannel: channelID}}
+}
+
+func TestCheckMsgOrder(t *testing.T) {
+	update := &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0"}
+	cases := map[string]struct {
+		msgs  []sdk.Msg
+		valid bool
+	}{
+		"empty":                       {nil, true},
+		"updates precede proofs":      {[]sdk.Msg{update, update, recvMsg("channel-0", 1), ackMsg("channel-0", 1)}, true},
+		"update follows a packet":     {[]sdk.Msg{recvMsg("channel-0", 1), update}, false},
+		"update follows a handshake":  {[]sdk.Msg{&chantypes.MsgChannelOpenTry{}, update}, false},
+		"update follows a non-proof":  {[]sdk.Msg{&chantypes.MsgChannelOpenInit{}, update, recvMsg("channel-0", 1)}, true},
+		"ascending sequences":         {[]sdk.Msg{recvMsg("channel-0", 1), recvMsg("channel-0", 2), recvMsg("channel-0", 10)}, true},
+		"descending sequences":        {[]sdk.Msg{recvMsg("channel-0", 10), recvMsg("channel-0", 2)}, false},
+		"duplicate sequences":         {[]sdk.Msg{ackMsg("channel-0", 3), ackMsg("channel-0", 3)}, false},
+		"sequences on other channels": {[]sdk.Msg{recvMsg("channel-0", 10), recvMsg("channel-1", 2), recvMsg("channel-0", 11)}, true},
+		"sequences of other kinds":    {[]sdk.Msg{ackMsg("channel-0", 10), recvMsg("channel-0", 2), ackMsg("channel-0", 11)}, true},
+	}
+	for name, c := range cases {
+		err := core.CheckMsgOrder(c.msgs)
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		} else if !c.valid && err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestOrderMsgsKeepsMsgOrder(t *testing.T) {
+	packetMsgs, ackMsgs := core.NewRelayMsgs(), core.NewRelayMsgs()
+	for seq := uint64(1); seq <= 5; seq++ {
+		packetMsgs.Dst = append(packetMsgs.Dst, recvMsg("channel-0", seq))
+		ackMsgs.Dst = append(ackMsgs.Dst, ackMsg("channel-0", seq))
+	}
+	for _, priority := range []string{core.AckPriorityFirst, core.AckPriorityInterleave, ""} {
+		st := core.NewNaiveStrategy(false, false)
+		st.AckPriority = priority
+		st.AckRatio = 2
+		msgs := st.OrderMsgs(packetMsgs, ackMsgs)
+		if err := core.CheckMsgOrder(msgs.Dst); err != nil {
+			t.Errorf("OrderMsgs with ack priority %q violates the msg order: %v", priority, err)
+		}
+	}
+}
+
+func TestPacketInfoListSortBySequence(t *testing.T) {
+	packets := makePacketInfoList(10, 2, 1, 11)
+	packets.SortBySequence()
+	if actual, expected := packets.ExtractSequenceList(), []uint64{1, 2, 10, 11}; !slices.Equal(actual, expected) {
+		t.Errorf("SortBySequence returns an unexpected result: actual=%v, expected=%v", actual, expected)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/cosmos/gogoproto/proto"
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
 )
 
 // MsgID represents an identifier of `sdk.Msg` that has been sent to a chain by `Chain::SendMsgs`.
@@ -15,6 +16,9 @@ type MsgID interface {
 
 // MsgResult represents a execution result of `sdk.Msg` that has been sent to a chain by `Chain::SendMsgs`.
 type MsgResult interface {
+	// MsgID returns the ID of the message, with which the result can be queried again by `Chain::GetMsgResult` (e.g. after a reorg).
+	MsgID() MsgID
+
 	// BlockHeight returns the height that the message is included.
 	BlockHeight() clienttypes.Height
 
@@ -26,6 +30,62 @@ type MsgResult interface {
 	Events() []MsgEventLog
 }
 
+// PendingMsgResult is the MsgResult of a message broadcasted but not included in a block yet, which is returned by `Chain::SendMsgs`
+// of a chain not waiting for the inclusion (see InclusionWaiter). The result after the inclusion is obtained by `Chain::GetMsgResult`.
+type PendingMsgResult struct {
+	ID MsgID
+}
+
+var _ MsgResult = (*PendingMsgResult)(nil)
+
+// NewPendingMsgResult returns the MsgResult of the message not included in a block yet
+func NewPendingMsgResult(id MsgID) *PendingMsgResult {
+	return &PendingMsgResult{ID: id}
+}
+
+// MsgID returns the ID of the message
+func (r *PendingMsgResult) MsgID() MsgID {
+	return r.ID
+}
+
+// BlockHeight returns the zero height since the message is not included yet
+func (r *PendingMsgResult) BlockHeight() clienttypes.Height {
+	return clienttypes.ZeroHeight()
+}
+
+// Status returns false since the message is not executed yet
+func (r *PendingMsgResult) Status() (bool, string) {
+	return false, "the message is not included in a block yet"
+}
+
+// Events returns nil since the message is not executed yet
+func (r *PendingMsgResult) Events() []MsgEventLog {
+	return nil
+}
+
+// ResolveMsgResult returns `res` if it is the result of an included message, or queries the result by `Chain::GetMsgResult`
+// if it is a PendingMsgResult, which waits for the inclusion
+func ResolveMsgResult(chain Chain, res MsgResult) (MsgResult, error) {
+	if _, ok := res.(*PendingMsgResult); !ok {
+		return res, nil
+	}
+	return chain.GetMsgResult(res.MsgID())
+}
+
+// MsgIDsOf returns the IDs of the messages of `results`, where the IDs of nil results are nil
+func MsgIDsOf(results []MsgResult) []MsgID {
+	if results == nil {
+		return nil
+	}
+	ids := make([]MsgID, len(results))
+	for i, res := range results {
+		if res != nil {
+			ids[i] = res.MsgID()
+		}
+	}
+	return ids
+}
+
 // MsgEventLog represents an event emitted by `sdk.Msg` that has been sent to a chain by `Chain::SendMsgs`.
 type MsgEventLog interface {
 	is_MsgEventLog()
@@ -46,6 +106,8 @@ var (
 	_ MsgEventLog = (*EventRecvPacket)(nil)
 	_ MsgEventLog = (*EventWriteAcknowledgement)(nil)
 	_ MsgEventLog = (*EventAcknowledgePacket)(nil)
+	_ MsgEventLog = (*EventUpdateClient)(nil)
+	_ MsgEventLog = (*EventChannelStateChange)(nil)
 	_ MsgEventLog = (*EventUnknown)(nil)
 )
 
@@ -115,6 +177,28 @@ type EventAcknowledgePacket struct {
 	TimeoutTimestamp time.Time
 }
 
+// EventUpdateClient is an implementation of `MsgEventLog` that represents the information of an `updateClient` operation.
+type EventUpdateClient struct {
+	isMsgEventLog
+
+	ClientID         string
+	ClientType       string
+	ConsensusHeights []clienttypes.Height
+}
+
+// EventChannelStateChange is an implementation of `MsgEventLog` that represents a state transition of a channel
+// by a channel handshake or closing operation. `State` is the state of the channel after the operation.
+type EventChannelStateChange struct {
+	isMsgEventLog
+
+	PortID                string
+	ChannelID             string
+	CounterpartyPortID    string
+	CounterpartyChannelID string
+	ConnectionID          string
+	State                 chantypes.State
+}
+
 // EventUnknown is an implementation of `MsgEventLog` that represents another event.
 type EventUnknown struct {
 	isMsgEventLog
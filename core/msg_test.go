@@ -0,0 +1,49 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestResolveMsgResult(t *testing.T) {
+	chain, pc := newMockChain(t, "ibc0", nil)
+	addr, _ := chain.GetAddress()
+	clientState, consensusState, err := pc.CreateInitialLightClientState(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := clienttypes.NewMsgCreateClient(clientState, consensusState, addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := chain.SendMsgs([]sdk.Msg{msg})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the result of an included msg is returned as it is
+	if res, err := core.ResolveMsgResult(chain, results[0]); err != nil || res != results[0] {
+		t.Errorf("unexpected result: %v, %v", res, err)
+	}
+
+	// the result of a pending msg is queried
+	pending := core.NewPendingMsgResult(results[0].MsgID())
+	if ok, _ := pending.Status(); ok || !pending.BlockHeight().IsZero() {
+		t.Error("the pending msg is executed")
+	}
+	res, err := core.ResolveMsgResult(chain, pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, reason := res.Status(); !ok || len(res.Events()) != 1 {
+		t.Errorf("unexpected result: %v %s", res.Events(), reason)
+	}
+
+	ids := core.MsgIDsOf([]core.MsgResult{nil, pending})
+	if len(ids) != 2 || ids[0] != nil || ids[1] != results[0].MsgID() {
+		t.Errorf("unexpected msg IDs: %v", ids)
+	}
+}
@@ -21,10 +21,20 @@ type NaiveStrategy struct {
 	Ordered      bool
 	MaxTxSize    uint64 // maximum permitted size of the msgs in a bundled relay transaction
 	MaxMsgLength uint64 // maximum amount of messages in a bundled relay transaction
-	srcNoAck     bool
-	dstNoAck     bool
+	AckPriority  string // order of the acknowledgement msgs relative to the packet msgs (see StrategyCfg.AckPriority)
+	AckRatio     uint64 // number of acknowledgements relayed per packet if AckPriority is "interleave"
+	// minimum age of the packets before they are relayed; the packets are relayed as soon as they are found if nil
+	MinPacketDelay *MinPacketDelayCfg
+	// gas budgets of the msgs submitted to the chains in a relay cycle; the gas is not limited if nil
+	GasBudget *GasBudgetCfg
+	srcNoAck  bool
+	dstNoAck  bool
 
 	metrics naiveStrategyMetrics
+
+	packetProofs *asyncProofs
+	ackProofs    *asyncProofs
+	pendingAcks  *pendingAcks
 }
 
 type naiveStrategyMetrics struct {
@@ -32,12 +42,18 @@ type naiveStrategyMetrics struct {
 	dstBacklog PacketInfoList
 }
 
-var _ StrategyI = (*NaiveStrategy)(nil)
+var (
+	_ StrategyI  = (*NaiveStrategy)(nil)
+	_ MsgOrderer = (*NaiveStrategy)(nil)
+)
 
 func NewNaiveStrategy(srcNoAck, dstNoAck bool) *NaiveStrategy {
 	return &NaiveStrategy{
-		srcNoAck: srcNoAck,
-		dstNoAck: dstNoAck,
+		srcNoAck:     srcNoAck,
+		dstNoAck:     dstNoAck,
+		packetProofs: newAsyncProofs(),
+		ackProofs:    newAsyncProofs(),
+		pendingAcks:  newPendingAcks(),
 	}
 }
 
@@ -69,7 +85,7 @@ func getQueryContext(chain *ProvableChain, sh SyncHeaders, useFinalizedHeader bo
 	if useFinalizedHeader {
 		return sh.GetQueryContext(chain.ChainID()), nil
 	} else {
-		height, err := chain.LatestHeight()
+		height, err := latestHeight(chain)
 		if err != nil {
 			return nil, err
 		}
@@ -147,6 +163,16 @@ func (st *NaiveStrategy) UnrelayedPackets(src, dst *ProvableChain, sh SyncHeader
 		return nil, err
 	}
 
+	// packets that have disappeared from the backlogs are received on the counterparty chains
+	var srcReceived, dstReceived PacketInfoList
+	if !st.srcNoAck {
+		srcReceived = st.metrics.srcBacklog.Subtract(srcPackets.ExtractSequenceList())
+	}
+	if !st.dstNoAck {
+		dstReceived = st.metrics.dstBacklog.Subtract(dstPackets.ExtractSequenceList())
+	}
+	st.pendingAcks.addReceived(srcReceived, dstReceived)
+
 	if err := st.metrics.updateBacklogMetrics(context.TODO(), src, dst, srcPackets, dstPackets); err != nil {
 		return nil, err
 	}
@@ -189,10 +215,20 @@ func (st *NaiveStrategy) UnrelayedPackets(src, dst *ProvableChain, sh SyncHeader
 		if err := eg.Wait(); err != nil {
 			return nil, err
 		}
+
+		if srcPackets, err = filterMinPacketDelay(src, srcPackets, st.MinPacketDelay); err != nil {
+			return nil, err
+		}
+		if dstPackets, err = filterMinPacketDelay(dst, dstPackets, st.MinPacketDelay); err != nil {
+			return nil, err
+		}
 	}
 
 	defer logger.TimeTrack(now, "UnrelayedPackets", "num_src", len(srcPackets), "num_dst", len(dstPackets))
 
+	srcPackets.SortBySequence()
+	dstPackets.SortBySequence()
+
 	return &RelayPackets{
 		Src: srcPackets,
 		Dst: dstPackets,
@@ -226,7 +262,7 @@ func (st *NaiveStrategy) RelayPackets(src, dst *ProvableChain, rp *RelayPackets,
 	}
 
 	if doExecuteRelayDst {
-		msgs.Dst, err = collectPackets(srcCtx, src, rp.Src, dstAddress)
+		msgs.Dst, err = collectRecvPackets(st.packetProofs, srcCtx, src, dst, rp.Src, dstAddress)
 		if err != nil {
 			logger.Error(
 				"error collecting packets",
@@ -237,7 +273,7 @@ func (st *NaiveStrategy) RelayPackets(src, dst *ProvableChain, rp *RelayPackets,
 	}
 
 	if doExecuteRelaySrc {
-		msgs.Src, err = collectPackets(dstCtx, dst, rp.Dst, srcAddress)
+		msgs.Src, err = collectRecvPackets(st.packetProofs, dstCtx, dst, src, rp.Dst, srcAddress)
 		if err != nil {
 			logger.Error(
 				"error collecting packets",
@@ -337,6 +373,11 @@ func (st *NaiveStrategy) UnrelayedAcknowledgements(src, dst *ProvableChain, sh S
 		return nil, err
 	}
 
+	if err := st.pendingAcks.update(context.TODO(), src, dst, srcAcks, dstAcks); err != nil {
+		logger.Error("failed to update pending acknowledgements", err)
+		return nil, err
+	}
+
 	// If includeRelayedButUnfinalized is true, this function should return packets of which AcknowledgePacket is not finalized yet.
 	// In this case, filtering packets by QueryUnreceivedAcknowledgements is not needed because QueryUnfinalizedRelayAcknowledgements
 	// has already returned packets that completely match this condition.
@@ -383,6 +424,9 @@ func (st *NaiveStrategy) UnrelayedAcknowledgements(src, dst *ProvableChain, sh S
 
 	defer logger.TimeTrack(now, "UnrelayedAcknowledgements", "num_src", len(srcAcks), "num_dst", len(dstAcks))
 
+	srcAcks.SortBySequence()
+	dstAcks.SortBySequence()
+
 	return &RelayPackets{
 		Src: srcAcks,
 		Dst: dstAcks,
@@ -390,13 +434,20 @@ func (st *NaiveStrategy) UnrelayedAcknowledgements(src, dst *ProvableChain, sh S
 }
 
 // TODO add packet-timeout support
-func collectPackets(ctx QueryContext, chain *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
+// If the prover of `chain` is an AsyncStateProver, packets whose proofs are not ready yet are skipped and collected in a later call.
+// On an ordered channel, the packets after such a packet are also skipped (but their proofs are requested) to keep the order of sequences.
+func collectPackets(ap *asyncProofs, ctx QueryContext, chain *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
 	logger := GetChannelLogger(chain)
-	var msgs []sdk.Msg
+	var (
+		msgs     []sdk.Msg
+		paths    = make(map[string]struct{})
+		skipRest bool
+	)
 	for _, p := range packets {
 		commitment := chantypes.CommitPacket(chain.Codec(), &p.Packet)
 		path := host.PacketCommitmentPath(p.SourcePort, p.SourceChannel, p.Sequence)
-		proof, proofHeight, err := chain.ProveState(ctx, path, commitment)
+		paths[path] = struct{}{}
+		proof, proofHeight, ready, err := ap.prove(ctx, chain, path, commitment)
 		if err != nil {
 			logger.Error("failed to ProveState", err,
 				"height", ctx.Height(),
@@ -404,10 +455,16 @@ func collectPackets(ctx QueryContext, chain *ProvableChain, packets PacketInfoLi
 				"commitment", commitment,
 			)
 			return nil, err
+		} else if !ready {
+			skipRest = chain.Path().GetOrder() == chantypes.ORDERED
+			continue
+		} else if skipRest {
+			continue
 		}
 		msg := chantypes.NewMsgRecvPacket(p.Packet, proof, proofHeight, signer.String())
 		msgs = append(msgs, msg)
 	}
+	ap.retain(chain.ChainID(), paths)
 	return msgs, nil
 }
 
@@ -446,13 +503,13 @@ func (st *NaiveStrategy) RelayAcknowledgements(src, dst *ProvableChain, rp *Rela
 	}
 
 	if !st.dstNoAck && doExecuteAckDst {
-		msgs.Dst, err = collectAcks(srcCtx, src, rp.Src, dstAddress)
+		msgs.Dst, err = collectAcks(st.ackProofs, srcCtx, src, rp.Src, dstAddress)
 		if err != nil {
 			return nil, err
 		}
 	}
 	if !st.srcNoAck && doExecuteAckSrc {
-		msgs.Src, err = collectAcks(dstCtx, dst, rp.Dst, srcAddress)
+		msgs.Src, err = collectAcks(st.ackProofs, dstCtx, dst, rp.Dst, srcAddress)
 		if err != nil {
 			return nil, err
 		}
@@ -472,14 +529,20 @@ func (st *NaiveStrategy) RelayAcknowledgements(src, dst *ProvableChain, rp *Rela
 	return msgs, nil
 }
 
-func collectAcks(ctx QueryContext, chain *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
+// Acknowledgements whose proofs are not ready yet are handled in the same way as collectPackets.
+func collectAcks(ap *asyncProofs, ctx QueryContext, chain *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
 	logger := GetChannelLogger(chain)
-	var msgs []sdk.Msg
+	var (
+		msgs     []sdk.Msg
+		paths    = make(map[string]struct{})
+		skipRest bool
+	)
 
 	for _, p := range packets {
 		commitment := chantypes.CommitAcknowledgement(p.Acknowledgement)
 		path := host.PacketAcknowledgementPath(p.DestinationPort, p.DestinationChannel, p.Sequence)
-		proof, proofHeight, err := chain.ProveState(ctx, path, commitment)
+		paths[path] = struct{}{}
+		proof, proofHeight, ready, err := ap.prove(ctx, chain, path, commitment)
 		if err != nil {
 			logger.Error("failed to ProveState", err,
 				"height", ctx.Height(),
@@ -487,10 +550,16 @@ func collectAcks(ctx QueryContext, chain *ProvableChain, packets PacketInfoList,
 				"commitment", commitment,
 			)
 			return nil, err
+		} else if !ready {
+			skipRest = chain.Path().GetOrder() == chantypes.ORDERED
+			continue
+		} else if skipRest {
+			continue
 		}
 		msg := chantypes.NewMsgAcknowledgement(p.Packet, p.Acknowledgement, proof, proofHeight, signer.String())
 		msgs = append(msgs, msg)
 	}
+	ap.retain(chain.ChainID(), paths)
 
 	return msgs, nil
 }
@@ -562,6 +631,11 @@ func (st *NaiveStrategy) Send(src, dst Chain, msgs *RelayMsgs) {
 
 	msgs.MaxTxSize = st.MaxTxSize
 	msgs.MaxMsgLength = st.MaxMsgLength
+	msgs.MaxUpdateClientMsgs = maxUpdateClientMsgsPerTx
+	if st.GasBudget != nil {
+		msgs.Src = applyGasBudget(src, msgs.Src, st.GasBudget.Src)
+		msgs.Dst = applyGasBudget(dst, msgs.Dst, st.GasBudget.Dst)
+	}
 	msgs.Send(src, dst)
 
 	logger.Info("msgs relayed",
@@ -570,38 +644,65 @@ func (st *NaiveStrategy) Send(src, dst Chain, msgs *RelayMsgs) {
 	)
 }
 
+// OrderMsgs implements MsgOrderer. The order of the packet msgs and the order of the acknowledgement msgs are preserved respectively,
+// so it is safe for ordered channels.
+func (st *NaiveStrategy) OrderMsgs(packetMsgs, ackMsgs *RelayMsgs) *RelayMsgs {
+	msgs := NewRelayMsgs()
+	switch st.AckPriority {
+	case AckPriorityFirst:
+		msgs.Merge(ackMsgs)
+		msgs.Merge(packetMsgs)
+	case AckPriorityInterleave:
+		msgs.Src = interleaveMsgs(ackMsgs.Src, packetMsgs.Src, int(st.AckRatio))
+		msgs.Dst = interleaveMsgs(ackMsgs.Dst, packetMsgs.Dst, int(st.AckRatio))
+	default:
+		msgs.Merge(packetMsgs)
+		msgs.Merge(ackMsgs)
+	}
+	return msgs
+}
+
+// interleaveMsgs returns the msgs of `acks` and `packets` interleaved so that `ratio` acks are followed by a packet
+func interleaveMsgs(acks, packets []sdk.Msg, ratio int) []sdk.Msg {
+	if ratio <= 0 {
+		ratio = 1
+	}
+	ret := make([]sdk.Msg, 0, len(acks)+len(packets))
+	for len(acks) > 0 || len(packets) > 0 {
+		n := ratio
+		if n > len(acks) {
+			n = len(acks)
+		}
+		ret = append(ret, acks[:n]...)
+		acks = acks[n:]
+		if len(packets) > 0 {
+			ret = append(ret, packets[0])
+			packets = packets[1:]
+		}
+	}
+	return ret
+}
+
 func (st *naiveStrategyMetrics) updateBacklogMetrics(ctx context.Context, src, dst ChainInfo, newSrcBacklog, newDstBacklog PacketInfoList) error {
 	srcAttrs := []attribute.KeyValue{
 		attribute.Key("chain_id").String(src.ChainID()),
 		attribute.Key("direction").String("src"),
+		relayDirectionAttr(SrcToDst),
 	}
 	dstAttrs := []attribute.KeyValue{
 		attribute.Key("chain_id").String(dst.ChainID()),
 		attribute.Key("direction").String("dst"),
+		relayDirectionAttr(DstToSrc),
 	}
 
 	metrics.BacklogSizeGauge.Set(int64(len(newSrcBacklog)), srcAttrs...)
 	metrics.BacklogSizeGauge.Set(int64(len(newDstBacklog)), dstAttrs...)
 
-	if len(newSrcBacklog) > 0 {
-		oldestHeight := newSrcBacklog[0].EventHeight
-		oldestTimestamp, err := src.Timestamp(oldestHeight)
-		if err != nil {
-			return fmt.Errorf("failed to get the timestamp of block[%d] on the src chain: %v", oldestHeight, err)
-		}
-		metrics.BacklogOldestTimestampGauge.Set(oldestTimestamp.UnixNano(), srcAttrs...)
-	} else {
-		metrics.BacklogOldestTimestampGauge.Set(0, srcAttrs...)
+	if err := setBacklogOldestTimestamp(src, "src", newSrcBacklog, srcAttrs); err != nil {
+		return err
 	}
-	if len(newDstBacklog) > 0 {
-		oldestHeight := newDstBacklog[0].EventHeight
-		oldestTimestamp, err := dst.Timestamp(oldestHeight)
-		if err != nil {
-			return fmt.Errorf("failed to get the timestamp of block[%d] on the dst chain: %v", oldestHeight, err)
-		}
-		metrics.BacklogOldestTimestampGauge.Set(oldestTimestamp.UnixNano(), dstAttrs...)
-	} else {
-		metrics.BacklogOldestTimestampGauge.Set(0, dstAttrs...)
+	if err := setBacklogOldestTimestamp(dst, "dst", newDstBacklog, dstAttrs); err != nil {
+		return err
 	}
 
 	srcReceivedPackets := st.srcBacklog.Subtract(newSrcBacklog.ExtractSequenceList())
@@ -614,3 +715,22 @@ func (st *naiveStrategyMetrics) updateBacklogMetrics(ctx context.Context, src, d
 
 	return nil
 }
+
+// setBacklogOldestTimestamp sets the timestamp of the oldest packet in the backlog of `chain`, or zero if the backlog is empty.
+// The gauge is left unchanged if the packet was sent in a previous revision of the chain, whose blocks can't be queried anymore.
+func setBacklogOldestTimestamp(chain ChainInfo, side string, backlog PacketInfoList, attrs []attribute.KeyValue) error {
+	if len(backlog) == 0 {
+		metrics.BacklogOldestTimestampGauge.Set(0, attrs...)
+		return nil
+	}
+	oldestHeight := backlog[0].EventHeight
+	if inPreviousRevision(chain.ChainID(), oldestHeight) {
+		return nil
+	}
+	oldestTimestamp, err := chain.Timestamp(oldestHeight)
+	if err != nil {
+		return fmt.Errorf("failed to get the timestamp of block[%d] on the %s chain: %v", oldestHeight, side, err)
+	}
+	metrics.BacklogOldestTimestampGauge.Set(oldestTimestamp.UnixNano(), attrs...)
+	return nil
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// ICS-721 port ID and app version used by the NFT transfer modules
+const (
+	NFTTransferPortID  = "nft-transfer"
+	NFTTransferVersion = "ics721-1"
+)
+
+// NonFungibleTokenPacketData is the packet data of ICS-721 (interchain NFT transfer).
+// ibc-go doesn't have the types of ICS-721, so the JSON encoding of the spec is decoded here.
+type NonFungibleTokenPacketData struct {
+	ClassID   string   `json:"classId"`
+	ClassURI  string   `json:"classUri,omitempty"`
+	ClassData string   `json:"classData,omitempty"`
+	TokenIDs  []string `json:"tokenIds"`
+	TokenURIs []string `json:"tokenUris,omitempty"`
+	TokenData []string `json:"tokenData,omitempty"`
+	Sender    string   `json:"sender"`
+	Receiver  string   `json:"receiver"`
+	Memo      string   `json:"memo,omitempty"`
+}
+
+// NFTTransferInfo is the human-readable summary of an ICS-721 non-fungible token transfer packet
+type NFTTransferInfo struct {
+	// ClassTrace is the full class path on the sending chain (e.g. "nft-transfer/channel-0/kitty")
+	ClassTrace  string `json:"class_trace"`
+	BaseClassID string `json:"base_class_id"`
+	// ReceiverClassTrace is the full class path on the receiving chain
+	ReceiverClassTrace string   `json:"receiver_class_trace"`
+	ClassURI           string   `json:"class_uri,omitempty"`
+	TokenIDs           []string `json:"token_ids"`
+	Sender             string   `json:"sender"`
+	Receiver           string   `json:"receiver"`
+	Memo               string   `json:"memo,omitempty"`
+}
+
+// DecodeNFTTransferPacket decodes the packet as an ICS-721 packet and resolves its class traces.
+// It returns false if the packet data is not NonFungibleTokenPacketData.
+func DecodeNFTTransferPacket(packet chantypes.Packet) (*NFTTransferInfo, bool) {
+	var data NonFungibleTokenPacketData
+	if err := json.Unmarshal(packet.GetData(), &data); err != nil {
+		return nil, false
+	}
+	if strings.TrimSpace(data.ClassID) == "" || len(data.TokenIDs) == 0 || data.Sender == "" || data.Receiver == "" {
+		return nil, false
+	}
+	if len(data.TokenURIs) > len(data.TokenIDs) || len(data.TokenData) > len(data.TokenIDs) {
+		return nil, false
+	}
+
+	// class traces are prefixed with the port and channel IDs in the same way as ICS-20 denom traces
+	trace := transfertypes.ParseDenomTrace(data.ClassID)
+	var receiverTrace string
+	if transfertypes.ReceiverChainIsSource(packet.SourcePort, packet.SourceChannel, data.ClassID) {
+		// the class returns to the chain it came from, so the prefix is removed
+		receiverTrace = strings.TrimPrefix(data.ClassID, transfertypes.GetDenomPrefix(packet.SourcePort, packet.SourceChannel))
+	} else {
+		receiverTrace = transfertypes.GetPrefixedDenom(packet.DestinationPort, packet.DestinationChannel, data.ClassID)
+	}
+
+	return &NFTTransferInfo{
+		ClassTrace:         data.ClassID,
+		BaseClassID:        trace.BaseDenom,
+		ReceiverClassTrace: receiverTrace,
+		ClassURI:           data.ClassURI,
+		TokenIDs:           data.TokenIDs,
+		Sender:             data.Sender,
+		Receiver:           data.Receiver,
+		Memo:               data.Memo,
+	}, true
+}
+
+// attributes returns the attributes of the packet data.
+// "amount" is the number of tokens, so value limits apply to NFT transfers by their class IDs.
+func (info *NFTTransferInfo) attributes() map[string]string {
+	attrs := map[string]string{
+		"class_id":             info.ClassTrace,
+		"base_class_id":        info.BaseClassID,
+		"receiver_class_trace": info.ReceiverClassTrace,
+		"token_ids":            strings.Join(info.TokenIDs, ","),
+		"token_count":          strconv.Itoa(len(info.TokenIDs)),
+		"amount":               strconv.Itoa(len(info.TokenIDs)),
+		"sender":               info.Sender,
+		"receiver":             info.Receiver,
+	}
+	if info.ClassURI != "" {
+		attrs["class_uri"] = info.ClassURI
+	}
+	if info.Memo != "" {
+		attrs["memo"] = info.Memo
+	}
+	return attrs
+}
@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestDecodeNFTTransferPacket(t *testing.T) {
+	packet := chantypes.Packet{
+		SourcePort:         "nft-transfer",
+		SourceChannel:      "channel-0",
+		DestinationPort:    "nft-transfer",
+		DestinationChannel: "channel-1",
+		Data:               []byte(`{"classId":"kitty","classUri":"https://example.com/kitty","tokenIds":["1","2"],"tokenUris":["",""],"sender":"cosmos1sender","receiver":"cosmos1receiver"}`),
+	}
+	data, ok := core.DecodePacketData(packet, `{"fee_version":"ics29-1","app_version":"ics721-1"}`)
+	if !ok {
+		t.Fatal("failed to decode the ICS-721 packet")
+	}
+	if data.App != "ics721" ||
+		data.Attribute("class_id") != "kitty" ||
+		data.Attribute("receiver_class_trace") != "nft-transfer/channel-1/kitty" ||
+		data.Attribute("token_ids") != "1,2" ||
+		data.Attribute("token_count") != "2" ||
+		data.Attribute("sender") != "cosmos1sender" {
+		t.Errorf("unexpected packet data: %+v", data)
+	}
+
+	// the class returns to the chain it came from
+	packet.Data = []byte(`{"classId":"nft-transfer/channel-0/kitty","tokenIds":["1"],"sender":"cosmos1sender","receiver":"cosmos1receiver"}`)
+	info, ok := core.DecodeNFTTransferPacket(packet)
+	if !ok || info.BaseClassID != "kitty" || info.ReceiverClassTrace != "kitty" {
+		t.Errorf("unexpected transfer info: %+v", info)
+	}
+
+	for name, bz := range map[string]string{
+		"no tokens":  `{"classId":"kitty","tokenIds":[],"sender":"cosmos1sender","receiver":"cosmos1receiver"}`,
+		"no class":   `{"tokenIds":["1"],"sender":"cosmos1sender","receiver":"cosmos1receiver"}`,
+		"ics20 data": `{"denom":"uatom","amount":"1","sender":"cosmos1sender","receiver":"cosmos1receiver"}`,
+	} {
+		packet.Data = []byte(bz)
+		if _, ok := core.DecodeNFTTransferPacket(packet); ok {
+			t.Errorf("%s: must not be decoded", name)
+		}
+	}
+}
+
+func TestNFTTransferFilterAttributes(t *testing.T) {
+	// value limits match the base class ID and count the tokens as the amount
+	packet := chantypes.Packet{
+		SourcePort:         "nft-transfer",
+		SourceChannel:      "channel-0",
+		DestinationPort:    "nft-transfer",
+		DestinationChannel: "channel-1",
+		Data:               []byte(`{"classId":"nft-transfer/channel-9/kitty","tokenIds":["1","2"],"sender":"cosmos1sender","receiver":"cosmos1receiver"}`),
+	}
+	data, ok := core.DecodePacketData(packet, "ics721-1")
+	if !ok {
+		t.Fatal("failed to decode the ICS-721 packet")
+	}
+	if data.Attribute("base_class_id") != "kitty" || data.Attribute("amount") != "2" {
+		t.Errorf("unexpected packet data: %+v", data)
+	}
+}
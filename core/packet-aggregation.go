@@ -0,0 +1,84 @@
+package core
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// MultiPacketProver is an optional interface of Prover that proves the commitments of multiple packets with an aggregated proof
+type MultiPacketProver interface {
+	// ProvePacketCommitments returns an aggregated proof of the commitments of `packets` at the height of `ctx`
+	ProvePacketCommitments(ctx QueryContext, packets []chantypes.Packet) (proof []byte, proofHeight clienttypes.Height, err error)
+}
+
+// RecvPacketAggregator is an optional interface of Chain whose IBC handler verifies multiple packets with an aggregated proof in a single call
+// (e.g. some EVM implementations), which costs much less gas than verifying a proof per packet.
+// If the prover of the counterparty chain implements MultiPacketProver, the MsgRecvPacket msgs submitted to the chain share an aggregated proof
+// and its height, and the chain submits the consecutive msgs with the same proof in one batched call in SendMsgs.
+// The msg IDs are still returned per msg, so the results of the packets are tracked individually.
+type RecvPacketAggregator interface {
+	// MaxAggregatedPackets returns the maximum number of packets verified with an aggregated proof in a call (0 means unlimited)
+	MaxAggregatedPackets() int
+}
+
+// aggregatesRecvPackets returns the prover of `chain` and the maximum number of packets per aggregated proof for the packets
+// sent from `chain` to `counterparty`, and false if the aggregation is not supported by either of them.
+// The prover is fetched once here and used for all the proofs, since it may be swapped at runtime (see SwapProver).
+func aggregatesRecvPackets(chain, counterparty *ProvableChain) (MultiPacketProver, int, bool) {
+	prover, ok := chain.CurrentProver().(MultiPacketProver)
+	if !ok {
+		return nil, 0, false
+	}
+	aggregator, ok := counterparty.Chain.(RecvPacketAggregator)
+	if !ok {
+		return nil, 0, false
+	}
+	return prover, aggregator.MaxAggregatedPackets(), true
+}
+
+// collectRecvPackets returns the MsgRecvPacket msgs of the packets sent from `chain` to `counterparty`.
+// The packets share aggregated proofs if both chains support the aggregation, and they are proven individually otherwise.
+func collectRecvPackets(ap *asyncProofs, ctx QueryContext, chain, counterparty *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
+	prover, max, ok := aggregatesRecvPackets(chain, counterparty)
+	if !ok {
+		return collectPackets(ap, ctx, chain, packets, signer)
+	}
+	return collectAggregatedPackets(ctx, chain, prover, packets, signer, max)
+}
+
+// collectAggregatedPackets returns the MsgRecvPacket msgs of the packets, each batch of `max` packets sharing an aggregated proof by `prover`.
+// The order of the packets is preserved, so it is safe for ordered channels.
+func collectAggregatedPackets(ctx QueryContext, chain *ProvableChain, prover MultiPacketProver, packets PacketInfoList, signer sdk.AccAddress, max int) ([]sdk.Msg, error) {
+	logger := GetChannelLogger(chain)
+	if max <= 0 {
+		max = len(packets)
+	}
+	var msgs []sdk.Msg
+	for start := 0; start < len(packets); start += max {
+		end := start + max
+		if end > len(packets) {
+			end = len(packets)
+		}
+		batch := make([]chantypes.Packet, 0, end-start)
+		for _, p := range packets[start:end] {
+			batch = append(batch, p.Packet)
+		}
+		proof, proofHeight, err := prover.ProvePacketCommitments(ctx, batch)
+		if err != nil {
+			logger.Error("failed to prove the packet commitments", err,
+				"height", ctx.Height(),
+				"first_sequence", batch[0].Sequence,
+				"last_sequence", batch[len(batch)-1].Sequence,
+			)
+			return nil, err
+		}
+		for _, p := range batch {
+			msgs = append(msgs, chantypes.NewMsgRecvPacket(p, proof, proofHeight, signer.String()))
+		}
+	}
+	if len(packets) > 0 {
+		logger.Debug("aggregated the proofs of the packets", "packets", len(packets), "max_aggregated_packets", max)
+	}
+	return msgs, nil
+}
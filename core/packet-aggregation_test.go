@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// aggregatingProver proves the commitments of packets with a proof listing their sequences
+type aggregatingProver struct {
+	*mockprover.Prover
+}
+
+func (pr *aggregatingProver) ProvePacketCommitments(ctx core.QueryContext, packets []chantypes.Packet) ([]byte, clienttypes.Height, error) {
+	var seqs []string
+	for _, p := range packets {
+		seqs = append(seqs, fmt.Sprint(p.Sequence))
+	}
+	return []byte(strings.Join(seqs, ",")), ctx.Height().(clienttypes.Height), nil
+}
+
+// aggregatingChain verifies up to 2 packets with an aggregated proof
+type aggregatingChain struct {
+	*mock.Chain
+}
+
+func (c *aggregatingChain) MaxAggregatedPackets() int {
+	return 2
+}
+
+func TestAggregatedRecvPackets(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	ends := [2]*core.PathEnd{
+		{ChainID: "ibc0", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-1", Order: "unordered", Version: "ics20-1"},
+	}
+	chains := newMockChains(t, ends, func(chain *mock.Chain, prover *mockprover.Prover) (core.Chain, core.Prover) {
+		return &aggregatingChain{chain}, &aggregatingProver{prover}
+	})
+	sh, err := core.NewSyncHeaders(chains[0], chains[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp := &core.RelayPackets{}
+	for seq := uint64(1); seq <= 5; seq++ {
+		rp.Src = append(rp.Src, &core.PacketInfo{Packet: chantypes.Packet{
+			Sequence:           seq,
+			SourcePort:         "transfer",
+			SourceChannel:      "channel-0",
+			DestinationPort:    "transfer",
+			DestinationChannel: "channel-1",
+			Data:               []byte("data"),
+			TimeoutHeight:      clienttypes.NewHeight(0, 1000),
+		}})
+	}
+	msgs, err := core.NewNaiveStrategy(false, false).RelayPackets(chains[0], chains[1], rp, sh, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1,2", "1,2", "3,4", "3,4", "5"}
+	if len(msgs.Dst) != len(expected) {
+		t.Fatalf("unexpected msgs: %v", msgs.Dst)
+	}
+	for i, msg := range msgs.Dst {
+		recv := msg.(*chantypes.MsgRecvPacket)
+		if recv.Packet.Sequence != uint64(i+1) || string(recv.ProofCommitment) != expected[i] {
+			t.Errorf("unexpected msg %d: sequence=%d, proof=%s", i, recv.Packet.Sequence, recv.ProofCommitment)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	icatypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// PacketData is the application data of a packet decoded by a PacketDecoder
+type PacketData struct {
+	// App is the name of the application protocol (e.g. "ics20")
+	App string `json:"app"`
+
+	// Attributes is a flat summary of the data used for logging, filtering and webhook payloads
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Value is the decoded data (e.g. *TransferInfo for ICS-20 packets)
+	Value interface{} `json:"-"`
+}
+
+// Attribute returns the attribute of the key, or an empty string if it doesn't exist
+func (pd *PacketData) Attribute(key string) string {
+	if pd == nil {
+		return ""
+	}
+	return pd.Attributes[key]
+}
+
+// LogAttrs returns the attributes as key-value pairs sorted by key for structured logging
+func (pd *PacketData) LogAttrs() []interface{} {
+	keys := make([]string, 0, len(pd.Attributes))
+	for k := range pd.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := []interface{}{"app", pd.App}
+	for _, k := range keys {
+		attrs = append(attrs, k, pd.Attributes[k])
+	}
+	return attrs
+}
+
+// PacketDecoder decodes the application data of a packet.
+// It returns an error if the data is not in the format of the app.
+type PacketDecoder func(packet chantypes.Packet) (*PacketData, error)
+
+type packetDecoderKey struct {
+	portID  string
+	version string
+}
+
+// PacketDecoderRegistry maps port IDs and app versions of channels to the decoders of their packet data
+type PacketDecoderRegistry struct {
+	mu       sync.RWMutex
+	decoders map[packetDecoderKey]PacketDecoder
+}
+
+// NewPacketDecoderRegistry returns an empty registry
+func NewPacketDecoderRegistry() *PacketDecoderRegistry {
+	return &PacketDecoderRegistry{decoders: make(map[packetDecoderKey]PacketDecoder)}
+}
+
+// Register registers the decoder for the packets of the channels with the port ID and the app version.
+// An empty `portID` or `version` matches any port ID or version. A decoder registered later overrides the previous one.
+func (r *PacketDecoderRegistry) Register(portID, version string, decoder PacketDecoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[packetDecoderKey{portID: portID, version: version}] = decoder
+}
+
+// Decode decodes the data of the packet sent on a channel with `version`.
+// The decoders are looked up in the order of (port ID, version), (port ID, any) and (any, version),
+// and the result of the first one that succeeds is returned. It returns false if no decoder can decode the packet.
+func (r *PacketDecoderRegistry) Decode(packet chantypes.Packet, version string) (*PacketData, bool) {
+	version = AppVersion(version)
+	keys := []packetDecoderKey{
+		{portID: packet.SourcePort, version: version},
+		{portID: packet.SourcePort},
+		{version: version},
+	}
+	if packet.DestinationPort != packet.SourcePort {
+		keys = append(keys, packetDecoderKey{portID: packet.DestinationPort, version: version}, packetDecoderKey{portID: packet.DestinationPort})
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, key := range keys {
+		if key.version == "" && key.portID == "" {
+			continue
+		}
+		decoder, ok := r.decoders[key]
+		if !ok {
+			continue
+		}
+		if data, err := decoder(packet); err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+var packetDecoders = NewPacketDecoderRegistry()
+
+func init() {
+	packetDecoders.Register(transfertypes.PortID, "", decodeTransferPacketData)
+	packetDecoders.Register("", transfertypes.Version, decodeTransferPacketData)
+	packetDecoders.Register(icatypes.HostPortID, "", decodeInterchainAccountPacketData)
+	packetDecoders.Register("", icatypes.Version, decodeInterchainAccountPacketData)
+	packetDecoders.Register(NFTTransferPortID, "", decodeNFTTransferPacketData)
+	packetDecoders.Register("", NFTTransferVersion, decodeNFTTransferPacketData)
+}
+
+// GetPacketDecoderRegistry returns the registry used by the relay service.
+// It has the decoders of ICS-20, ICS-27 and ICS-721 by default, and modules can register the decoders of their apps.
+func GetPacketDecoderRegistry() *PacketDecoderRegistry {
+	return packetDecoders
+}
+
+// DecodePacketData decodes the data of the packet sent on a channel with `version` by the registered decoders
+func DecodePacketData(packet chantypes.Packet, version string) (*PacketData, bool) {
+	return packetDecoders.Decode(packet, version)
+}
+
+// AppVersion returns the version of the app on which `version` is based.
+// It unwraps the version of ICS-29 fee middleware and extracts the version from ICS-27 metadata.
+func AppVersion(version string) string {
+	if !strings.HasPrefix(version, "{") {
+		return version
+	}
+	var v struct {
+		AppVersion string `json:"app_version"`
+		Version    string `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(version), &v); err != nil {
+		return version
+	}
+	switch {
+	case v.AppVersion != "":
+		return AppVersion(v.AppVersion)
+	case v.Version != "":
+		return v.Version
+	default:
+		return version
+	}
+}
+
+func decodeTransferPacketData(packet chantypes.Packet) (*PacketData, error) {
+	info, ok := DecodeTransferPacket(packet)
+	if !ok {
+		return nil, fmt.Errorf("not an ICS-20 packet")
+	}
+	return &PacketData{
+		App: "ics20",
+		Attributes: map[string]string{
+			"denom_trace":    info.DenomTrace,
+			"base_denom":     info.BaseDenom,
+			"receiver_denom": info.ReceiverDenom,
+			"amount":         info.Amount,
+			"sender":         info.Sender,
+			"receiver":       info.Receiver,
+		},
+		Value: info,
+	}, nil
+}
+
+func decodeNFTTransferPacketData(packet chantypes.Packet) (*PacketData, error) {
+	info, ok := DecodeNFTTransferPacket(packet)
+	if !ok {
+		return nil, fmt.Errorf("not an ICS-721 packet")
+	}
+	return &PacketData{
+		App:        "ics721",
+		Attributes: info.attributes(),
+		Value:      info,
+	}, nil
+}
+
+func decodeInterchainAccountPacketData(packet chantypes.Packet) (*PacketData, error) {
+	var data icatypes.InterchainAccountPacketData
+	if err := icatypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &data); err != nil {
+		return nil, err
+	}
+	if err := data.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	attrs := map[string]string{
+		"type": data.Type.String(),
+	}
+	if data.Memo != "" {
+		attrs["memo"] = data.Memo
+	}
+	// the msgs are listed by their type URLs, so they don't have to be registered to the codec
+	var tx icatypes.CosmosTx
+	if err := tx.Unmarshal(data.Data); err == nil {
+		var msgTypes []string
+		for _, msg := range tx.Messages {
+			msgTypes = append(msgTypes, msg.TypeUrl)
+		}
+		attrs["msg_types"] = strings.Join(msgTypes, ",")
+	}
+	return &PacketData{
+		App:        "ics27",
+		Attributes: attrs,
+		Value:      &data,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// packetEventKey identifies a packet event relayed in a relay cycle
+type packetEventKey struct {
+	sourcePort    string
+	sourceChannel string
+	sequence      uint64
+	// true for the write_acknowledgement event, false for the send_packet event
+	ack bool
+}
+
+// packetEvent is the chain and the height at which a packet event was emitted
+type packetEvent struct {
+	chain  *ProvableChain
+	height clienttypes.Height
+}
+
+// rememberPacketEvents records the events of the packets and the acknowledgements to be relayed in the current relay cycle,
+// which are looked up to record the event times in the journal
+func (srv *RelayService) rememberPacketEvents(pseqs, aseqs *RelayPackets) {
+	if srv.packetEvents == nil {
+		srv.packetEvents = make(map[packetEventKey]packetEvent)
+	}
+	for _, r := range []struct {
+		chain   *ProvableChain
+		packets PacketInfoList
+		ack     bool
+	}{
+		{srv.src, pseqs.Src, false},
+		{srv.dst, pseqs.Dst, false},
+		{srv.src, aseqs.Src, true},
+		{srv.dst, aseqs.Dst, true},
+	} {
+		for _, p := range r.packets {
+			key := packetEventKey{p.SourcePort, p.SourceChannel, p.Sequence, r.ack}
+			srv.packetEvents[key] = packetEvent{chain: r.chain, height: p.EventHeight}
+		}
+	}
+}
+
+// blockKey identifies a block of a chain
+type blockKey struct {
+	chainID string
+	height  clienttypes.Height
+}
+
+// blockTimes caches the timestamps of the blocks queried in a relay cycle
+type blockTimes map[blockKey]time.Time
+
+// get returns the timestamp of the block at `height` of `chain`
+func (t blockTimes) get(chain *ProvableChain, height clienttypes.Height) (time.Time, error) {
+	key := blockKey{chain.ChainID(), height}
+	if ts, ok := t[key]; ok {
+		return ts, nil
+	}
+	ts, err := chain.Timestamp(height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t[key] = ts
+	return ts, nil
+}
+
+// setEventTimes sets the times of the packet events relayed by the entries.
+// The event time is left unset if the event wasn't remembered or the timestamp of the block is not available,
+// e.g. the block is of a previous revision of the chain.
+func (srv *RelayService) setEventTimes(entries []*JournalEntry) {
+	timestamps := make(blockTimes)
+	ackType := sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{})
+	for _, e := range entries {
+		ev, ok := srv.packetEvents[packetEventKey{e.SourcePort, e.SourceChannel, e.Sequence, e.MsgType == ackType}]
+		if !ok {
+			continue
+		}
+		// the block of a previous revision can't be queried after the upgrade of the chain
+		if inPreviousRevision(ev.chain.ChainID(), ev.height) {
+			continue
+		}
+		ts, err := timestamps.get(ev.chain, ev.height)
+		if err != nil {
+			GetChainLogger(ev.chain).Error("failed to get the timestamp of the block of a packet event", err, "height", ev.height)
+			continue
+		}
+		e.EventTime = &ts
+	}
+}
+
+// recordDeliveryLatencies records the latencies of the packets and the acknowledgements relayed by the msgs submitted by the service,
+// i.e. the time from the block of the send_packet or write_acknowledgement event to the block including the MsgRecvPacket or MsgAcknowledgement.
+// The msgs that failed or whose events weren't remembered are skipped, and so are the msgs relayed by other relayers.
+func (srv *RelayService) recordDeliveryLatencies(msgs *RelayMsgs) {
+	timestamps := make(blockTimes)
+	for _, sent := range []struct {
+		chain   *ProvableChain
+		msgs    []sdk.Msg
+		results []MsgResult
+	}{
+		{srv.src, msgs.Src, msgs.SrcMsgResults},
+		{srv.dst, msgs.Dst, msgs.DstMsgResults},
+	} {
+		logger := GetChainLogger(sent.chain)
+		// the inclusion heights of the txs broadcast without waiting for the inclusion by tx ID
+		inclusions := make(map[string]clienttypes.Height)
+		for i, msg := range sent.msgs {
+			var (
+				histogram api.Float64Histogram
+				packet    chantypes.Packet
+				ack       bool
+			)
+			switch msg := msg.(type) {
+			case *chantypes.MsgRecvPacket:
+				histogram, packet = metrics.PacketDeliveryLatencyHistogram, msg.Packet
+			case *chantypes.MsgAcknowledgement:
+				histogram, packet, ack = metrics.AcknowledgementRelayLatencyHistogram, msg.Packet, true
+			default:
+				continue
+			}
+			if i >= len(sent.results) || sent.results[i] == nil {
+				continue
+			}
+			ev, ok := srv.packetEvents[packetEventKey{packet.SourcePort, packet.SourceChannel, packet.Sequence, ack}]
+			if !ok || inPreviousRevision(ev.chain.ChainID(), ev.height) {
+				continue
+			}
+
+			msgID := sent.results[i].MsgID()
+			txID := txIDOf(msgID)
+			height, ok := inclusions[txID]
+			if !ok || txID == "" {
+				res, err := ResolveMsgResult(sent.chain, sent.results[i])
+				if err != nil {
+					logger.Error("failed to get the result of a relayed msg", err, "msg_id", msgID)
+					continue
+				}
+				if ok, _ := res.Status(); !ok {
+					continue
+				}
+				height = res.BlockHeight()
+				if txID != "" {
+					inclusions[txID] = height
+				}
+			}
+			included, err := timestamps.get(sent.chain, height)
+			if err != nil {
+				logger.Error("failed to get the timestamp of the block including a relayed msg", err, "height", height)
+				continue
+			}
+			emitted, err := timestamps.get(ev.chain, ev.height)
+			if err != nil {
+				GetChainLogger(ev.chain).Error("failed to get the timestamp of the block of a packet event", err, "height", ev.height)
+				continue
+			}
+
+			// the chain on which the event was emitted and the channel on it
+			direction, relayDirection := "src", SrcToDst
+			if ev.chain == srv.dst {
+				direction, relayDirection = "dst", DstToSrc
+			}
+			portID, channelID := packet.SourcePort, packet.SourceChannel
+			if ack {
+				portID, channelID = packet.DestinationPort, packet.DestinationChannel
+			}
+			histogram.Record(context.TODO(), included.Sub(emitted).Seconds(), api.WithAttributes(
+				attribute.Key("path").String(srv.pathName),
+				attribute.Key("chain_id").String(ev.chain.ChainID()),
+				attribute.Key("direction").String(direction),
+				attribute.Key("port_id").String(portID),
+				attribute.Key("channel_id").String(channelID),
+				relayDirectionAttr(relayDirection),
+			))
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// reasons why a packet is held
+const (
+	HoldReasonValueLimit = "value-limit"
+	HoldReasonScreening  = "screening"
+)
+
+// HeldPacket is a packet held by a value limit or the address screening until it is released
+type HeldPacket struct {
+	// ChainID is the ID of the chain from which the packet is sent
+	ChainID   string    `json:"chain_id"`
+	PortID    string    `json:"port_id"`
+	ChannelID string    `json:"channel_id"`
+	Sequence  uint64    `json:"sequence"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail,omitempty"`
+	Denom     string    `json:"denom,omitempty"`
+	Amount    string    `json:"amount,omitempty"`
+	HeldAt    time.Time `json:"held_at"`
+	Released  bool      `json:"released"`
+	// Denied is true if the address screening denied the packet, which is never relayed and can't be released
+	Denied bool `json:"denied,omitempty"`
+	// ScreeningFailed is true if the packet is held because the screening service failed.
+	// Such a packet is screened again in the following relay cycles until the screening succeeds or it is released.
+	ScreeningFailed bool `json:"screening_failed,omitempty"`
+}
+
+type heldPacketKey struct {
+	chainID   string
+	portID    string
+	channelID string
+	sequence  uint64
+}
+
+// packetHolds keeps track of the packets held by the value limits and the address screening of a path.
+// The state is kept in memory, so the packets are held again after a restart until they are released.
+type packetHolds struct {
+	mu       sync.Mutex
+	limits   []*ValueLimitCfg
+	screener AddressScreener
+	// outcome of the screening for the packets whose data can't be decoded
+	onUndecodable ScreeningOutcome
+	held          map[heldPacketKey]*HeldPacket
+}
+
+func newPacketHolds() *packetHolds {
+	return &packetHolds{
+		held: make(map[heldPacketKey]*HeldPacket),
+	}
+}
+
+func (h *packetHolds) setLimits(limits []*ValueLimitCfg) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.limits = limits
+}
+
+func (h *packetHolds) setScreener(screener AddressScreener, onUndecodable ScreeningOutcome) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.screener = screener
+	h.onUndecodable = onUndecodable
+}
+
+// filter returns the packets sent from `chain` to `counterparty` on the channel of `end` except the ones held by the value limits or the address screening.
+// Held packets that are no longer unrelayed (e.g. relayed by another relayer) are forgotten.
+// On ordered channels, the packets following a held packet are also left unrelayed because they can't be received before it.
+// The screening service is consulted without holding the lock so that the admin API isn't blocked by it.
+func (h *packetHolds) filter(ctx context.Context, chain, counterparty ChainInfo, end *PathEnd, packets PacketInfoList) PacketInfoList {
+	h.mu.Lock()
+	if len(h.limits) == 0 && h.screener == nil {
+		h.mu.Unlock()
+		return packets
+	}
+	chainID := chain.ChainID()
+	unrelayed := make(map[uint64]struct{}, len(packets))
+	for _, p := range packets {
+		unrelayed[p.Sequence] = struct{}{}
+	}
+	for key := range h.held {
+		if key.chainID != chainID || key.portID != end.PortID || key.channelID != end.ChannelID {
+			continue
+		}
+		if _, ok := unrelayed[key.sequence]; !ok {
+			delete(h.held, key)
+		}
+	}
+	// the packets held already are not checked again unless their screening failed
+	known := make(map[uint64]bool, len(packets))
+	for _, p := range packets {
+		if hp, ok := h.held[heldPacketKey{chainID: chainID, portID: p.SourcePort, channelID: p.SourceChannel, sequence: p.Sequence}]; ok && (hp.Released || !hp.ScreeningFailed) {
+			known[p.Sequence] = !hp.Released
+		}
+	}
+	limits, screener, onUndecodable := h.limits, h.screener, h.onUndecodable
+	h.mu.Unlock()
+
+	ordered := end.GetOrder() == chantypes.ORDERED
+	checked := make(map[uint64]*HeldPacket)
+	for _, p := range packets {
+		if holding, ok := known[p.Sequence]; ok {
+			if holding && ordered {
+				break
+			}
+			continue
+		}
+		hp := checkPacket(ctx, limits, screener, onUndecodable, chainID, counterparty.ChainID(), end, p)
+		checked[p.Sequence] = hp
+		if hp != nil && ordered {
+			break
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	logger := GetChainLogger(chain)
+	var ret PacketInfoList
+	for _, p := range packets {
+		key := heldPacketKey{chainID: chainID, portID: p.SourcePort, channelID: p.SourceChannel, sequence: p.Sequence}
+		hp, isChecked := checked[p.Sequence]
+		if isChecked {
+			h.register(logger, key, hp)
+		} else if _, ok := known[p.Sequence]; !ok {
+			// the packets following a held packet on an ordered channel are not checked yet
+			return ret
+		}
+		if hp, ok := h.held[key]; ok && !hp.Released {
+			if ordered {
+				return ret
+			}
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// register records the result of checking the packet, where `hp` is nil if the packet is not held.
+// A packet released or held by another check while it was checked is left as it is.
+func (h *packetHolds) register(logger *log.RelayLogger, key heldPacketKey, hp *HeldPacket) {
+	prev, ok := h.held[key]
+	if ok && (prev.Released || !prev.ScreeningFailed) {
+		return
+	}
+	if hp == nil {
+		delete(h.held, key)
+		return
+	}
+	if ok {
+		hp.HeldAt = prev.HeldAt
+	} else {
+		logger.Warn("held a packet until it is released",
+			"port_id", key.portID,
+			"channel_id", key.channelID,
+			"sequence", key.sequence,
+			"reason", hp.Reason,
+			"detail", hp.Detail,
+			"denied", hp.Denied,
+		)
+	}
+	h.held[key] = hp
+}
+
+// checkPacket returns the hold of the packet if it exceeds the value limits or the address screening doesn't allow it, or nil otherwise.
+// If the screening is enabled, the packets whose data can't be decoded get `onUndecodable`.
+func checkPacket(ctx context.Context, limits []*ValueLimitCfg, screener AddressScreener, onUndecodable ScreeningOutcome, chainID, counterpartyChainID string, end *PathEnd, p *PacketInfo) *HeldPacket {
+	held := &HeldPacket{
+		ChainID:   chainID,
+		PortID:    p.SourcePort,
+		ChannelID: p.SourceChannel,
+		Sequence:  p.Sequence,
+		HeldAt:    time.Now(),
+	}
+	data, ok := DecodePacketData(p.Packet, end.Version)
+	if !ok {
+		// the addresses of the packet are unknown, so the screening can't allow it
+		if screener == nil || onUndecodable == ScreeningAllow {
+			return nil
+		}
+		held.Reason = HoldReasonScreening
+		held.Detail = "the packet data can't be decoded: " + string(onUndecodable)
+		held.Denied = onUndecodable == ScreeningDeny
+		return held
+	}
+	for _, limit := range limits {
+		if !limit.exceeds(data) {
+			continue
+		}
+		held.Reason = HoldReasonValueLimit
+		held.Detail = fmt.Sprintf("max amount is %s", limit.MaxAmount)
+		held.Denom = limit.Denom
+		held.Amount = data.Attribute("amount")
+		return held
+	}
+	if screener == nil {
+		return nil
+	}
+	outcome, detail, failed := screenPacket(ctx, screener, chainID, counterpartyChainID, data)
+	if outcome == ScreeningAllow {
+		return nil
+	}
+	held.Reason = HoldReasonScreening
+	held.Detail = detail
+	held.Denied = outcome == ScreeningDeny
+	held.ScreeningFailed = failed
+	return held
+}
+
+// list returns the held packets sorted by the time they were held
+func (h *packetHolds) list() []*HeldPacket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ret := make([]*HeldPacket, 0, len(h.held))
+	for _, p := range h.held {
+		cp := *p
+		ret = append(ret, &cp)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].HeldAt.Before(ret[j].HeldAt) })
+	return ret
+}
+
+// release approves the held packet so that it is relayed in the next relay cycle
+func (h *packetHolds) release(chainID, portID, channelID string, sequence uint64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	held, ok := h.held[heldPacketKey{chainID: chainID, portID: portID, channelID: channelID, sequence: sequence}]
+	if !ok {
+		return fmt.Errorf("packet is not held: chain_id=%s, port_id=%s, channel_id=%s, sequence=%d", chainID, portID, channelID, sequence)
+	}
+	if held.Denied {
+		return fmt.Errorf("packet is denied by the address screening: chain_id=%s, port_id=%s, channel_id=%s, sequence=%d", chainID, portID, channelID, sequence)
+	}
+	held.Released = true
+	return nil
+}
@@ -5,33 +5,97 @@ import (
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
 )
 
-func SendTransferMsg(src, dst *ProvableChain, amount sdk.Coin, dstAddr string, toHeightOffset uint64, toTimeOffset time.Duration) error {
-	logger := GetChannelPairLogger(src, dst)
-	defer logger.TimeTrack(time.Now(), "SendTransferMsg")
-	var (
-		timeoutHeight    uint64
-		timeoutTimestamp uint64
-	)
+const (
+	// DefaultTransferTimeoutHeightOffset and DefaultTransferTimeoutTimeOffset are the timeout of a transfer
+	// relative to the latest block of the dst chain if no timeout is specified
+	DefaultTransferTimeoutHeightOffset = 1000
+	DefaultTransferTimeoutTimeOffset   = 10 * time.Minute
+)
 
-	h, err := dst.LatestHeight()
+// TransferTimeoutOptions specifies the timeout of a transfer packet. The offsets are relative to the latest height
+// and the latest block time of the dst chain, not to the local clock, in the same way as the app frontends compute them.
+// If nothing is specified, the default offsets are used for both the height and the timestamp.
+type TransferTimeoutOptions struct {
+	// HeightOffset is the number of the blocks after the latest height of the dst chain at which the packet times out
+	HeightOffset uint64
+	// TimeOffset is the duration after the latest block time of the dst chain at which the packet times out
+	TimeOffset time.Duration
+	// Height is the absolute timeout height, which overrides HeightOffset
+	Height clienttypes.Height
+	// Timestamp is the absolute timeout timestamp, which overrides TimeOffset
+	Timestamp time.Time
+}
+
+// ComputeTransferTimeout returns the timeout height and timestamp of a transfer packet received on `dst`.
+// It fails if the timeout is already in the past on `dst` or if the packet never times out.
+func ComputeTransferTimeout(dst ChainInfo, opts TransferTimeoutOptions) (clienttypes.Height, uint64, error) {
+	latestHeight, err := dst.LatestHeight()
 	if err != nil {
-		return err
+		return clienttypes.ZeroHeight(), 0, fmt.Errorf("failed to get the latest height of the dst chain: %w", err)
+	}
+	latestTime, err := dst.Timestamp(latestHeight)
+	if err != nil {
+		return clienttypes.ZeroHeight(), 0, fmt.Errorf("failed to get the latest block time of the dst chain: %w", err)
+	}
+
+	if opts.HeightOffset == 0 && opts.TimeOffset == 0 && opts.Height.IsZero() && opts.Timestamp.IsZero() {
+		opts.HeightOffset = DefaultTransferTimeoutHeightOffset
+		opts.TimeOffset = DefaultTransferTimeoutTimeOffset
+	}
+	timeoutHeight := opts.Height
+	if timeoutHeight.IsZero() && opts.HeightOffset > 0 {
+		timeoutHeight = HeightAfter(latestHeight, opts.HeightOffset)
+	}
+	var timeoutTimestamp uint64
+	if !opts.Timestamp.IsZero() {
+		timeoutTimestamp = uint64(opts.Timestamp.UnixNano())
+	} else if opts.TimeOffset > 0 {
+		timeoutTimestamp = uint64(latestTime.Add(opts.TimeOffset).UnixNano())
+	}
+
+	if err := ValidateTransferTimeout(timeoutHeight, timeoutTimestamp, latestHeight, latestTime); err != nil {
+		return clienttypes.ZeroHeight(), 0, NewFailure(FailureConfig, err)
 	}
+	return timeoutHeight, timeoutTimestamp, nil
+}
+
+// ValidateTransferTimeout checks that a packet with the timeout height and timestamp can be received on the dst chain
+// whose latest block is at `latestHeight` and `latestTime`. A zero height or timestamp disables the timeout by it.
+func ValidateTransferTimeout(timeoutHeight clienttypes.Height, timeoutTimestamp uint64, latestHeight ibcexported.Height, latestTime time.Time) error {
+	if timeoutHeight.IsZero() && timeoutTimestamp == 0 {
+		return fmt.Errorf("either the timeout height or the timeout timestamp must be set")
+	}
+	if !timeoutHeight.IsZero() && timeoutHeight.LTE(latestHeight) {
+		return fmt.Errorf("the timeout height %v is not after the latest height %v of the dst chain", timeoutHeight, latestHeight)
+	}
+	if timeoutTimestamp != 0 && timeoutTimestamp <= uint64(latestTime.UnixNano()) {
+		return fmt.Errorf("the timeout timestamp %s is not after the latest block time %s of the dst chain",
+			time.Unix(0, int64(timeoutTimestamp)).UTC().Format(time.RFC3339), latestTime.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
 
-	switch {
-	case toHeightOffset > 0 && toTimeOffset > 0:
+// SendTransferMsg sends a MsgTransfer to `src` with the timeout offsets relative to the latest block of `dst`.
+// At most one of the offsets can be set, and the default timeout is used if neither is set.
+func SendTransferMsg(src, dst *ProvableChain, amount sdk.Coin, dstAddr string, toHeightOffset uint64, toTimeOffset time.Duration) error {
+	if toHeightOffset > 0 && toTimeOffset > 0 {
 		return fmt.Errorf("cant set both timeout height and time offset")
-	case toHeightOffset > 0:
-		timeoutHeight = uint64(h.GetRevisionHeight()) + toHeightOffset
-		timeoutTimestamp = 0
-	case toTimeOffset > 0:
-		timeoutHeight = 0
-		timeoutTimestamp = uint64(time.Now().Add(toTimeOffset).UnixNano())
-	case toHeightOffset == 0 && toTimeOffset == 0:
-		timeoutHeight = uint64(h.GetRevisionHeight() + 1000)
-		timeoutTimestamp = 0
+	}
+	return SendTransferMsgWithTimeout(src, dst, amount, dstAddr, TransferTimeoutOptions{HeightOffset: toHeightOffset, TimeOffset: toTimeOffset})
+}
+
+// SendTransferMsgWithTimeout sends a MsgTransfer to `src` with the timeout computed by ComputeTransferTimeout
+func SendTransferMsgWithTimeout(src, dst *ProvableChain, amount sdk.Coin, dstAddr string, timeout TransferTimeoutOptions) error {
+	logger := GetChannelPairLogger(src, dst)
+	defer logger.TimeTrack(time.Now(), "SendTransferMsg")
+
+	timeoutHeight, timeoutTimestamp, err := ComputeTransferTimeout(dst, timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
 	}
 
 	srcAddr, err := src.GetAddress()
@@ -52,7 +116,7 @@ func SendTransferMsg(src, dst *ProvableChain, amount sdk.Coin, dstAddr string, t
 	}
 
 	if txs.Send(src, dst); !txs.Success() {
-		err := fmt.Errorf("failed to send transfer message")
+		err := withSendErrors(fmt.Errorf("failed to send transfer message"), &txs)
 		logger.Error(err.Error(), err)
 		return err
 	}
@@ -0,0 +1,62 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestComputeTransferTimeout(t *testing.T) {
+	chain, err := mock.NewChain("ibc1", sdk.NewCoins())
+	if err != nil {
+		t.Fatal(err)
+	}
+	latestHeight, err := chain.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latestTime, err := chain.Timestamp(latestHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(d time.Duration) uint64 {
+		return uint64(latestTime.Add(d).UnixNano())
+	}
+	after := func(n uint64) clienttypes.Height {
+		return core.HeightAfter(latestHeight, n)
+	}
+
+	cases := map[string]struct {
+		opts      core.TransferTimeoutOptions
+		height    clienttypes.Height
+		timestamp uint64
+		ok        bool
+	}{
+		"default":            {core.TransferTimeoutOptions{}, after(core.DefaultTransferTimeoutHeightOffset), at(core.DefaultTransferTimeoutTimeOffset), true},
+		"height offset":      {core.TransferTimeoutOptions{HeightOffset: 10}, after(10), 0, true},
+		"time offset":        {core.TransferTimeoutOptions{TimeOffset: time.Hour}, clienttypes.ZeroHeight(), at(time.Hour), true},
+		"both offsets":       {core.TransferTimeoutOptions{HeightOffset: 10, TimeOffset: time.Hour}, after(10), at(time.Hour), true},
+		"absolute height":    {core.TransferTimeoutOptions{Height: after(5), HeightOffset: 10}, after(5), 0, true},
+		"absolute timestamp": {core.TransferTimeoutOptions{Timestamp: latestTime.Add(time.Minute)}, clienttypes.ZeroHeight(), at(time.Minute), true},
+		"past height":        {core.TransferTimeoutOptions{Height: clienttypes.NewHeight(latestHeight.GetRevisionNumber(), latestHeight.GetRevisionHeight())}, clienttypes.ZeroHeight(), 0, false},
+		"past timestamp":     {core.TransferTimeoutOptions{Timestamp: latestTime.Add(-time.Second)}, clienttypes.ZeroHeight(), 0, false},
+	}
+	for name, c := range cases {
+		height, timestamp, err := core.ComputeTransferTimeout(chain, c.opts)
+		if ok := err == nil; ok != c.ok {
+			t.Errorf("%s: unexpected result: err=%v", name, err)
+			continue
+		}
+		if c.ok && (!height.EQ(c.height) || timestamp != c.timestamp) {
+			t.Errorf("%s: unexpected timeout: height=%v, timestamp=%d", name, height, timestamp)
+		}
+	}
+
+	if err := core.ValidateTransferTimeout(clienttypes.ZeroHeight(), 0, latestHeight, latestTime); err == nil {
+		t.Error("a packet never timing out is accepted")
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MsgFailureError is an error of SendMsgs that tells which msg of the tx caused the failure.
+// Chain modules should return (or wrap) it if they know the failed msg, otherwise the index is
+// parsed from the error message in the format of the cosmos-sdk (see FailedMsgIndex).
+type MsgFailureError struct {
+	// MsgIndex is the index of the failed msg in the msgs passed to SendMsgs
+	MsgIndex int
+	// Reason describes the failure
+	Reason string
+}
+
+func (e *MsgFailureError) Error() string {
+	return fmt.Sprintf("failed to execute message; message index: %d: %s", e.MsgIndex, e.Reason)
+}
+
+// sdkMsgIndexPattern matches the error message of the cosmos-sdk baseapp, which is included in
+// the error of the simulation, CheckTx and the log of DeliverTx
+var sdkMsgIndexPattern = regexp.MustCompile(`message index: (\d+)`)
+
+// FailedMsgIndex returns the index of the msg that caused the failure of a tx
+func FailedMsgIndex(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+	var mfe *MsgFailureError
+	if errors.As(err, &mfe) {
+		return mfe.MsgIndex, true
+	}
+	m := sdkMsgIndexPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	index, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// sendMsgsRecovering sends the msgs in a tx. If the tx fails because of one of the msgs, the msg is dropped and
+// the remainder is resent instead of failing the whole batch, so that a single bad msg (e.g. a packet already relayed
+// by another relayer) doesn't block the others. The dropped msgs are picked again in later relay cycles.
+// MsgUpdateClient is never dropped because the proofs in the remainder would depend on it.
+// The returned msg results are aligned with `msgs`, where the results of the dropped msgs are nil.
+// A non-nil error is returned if any msg is not sent.
+func sendMsgsRecovering(chain Chain, msgs []sdk.Msg) ([]MsgResult, error) {
+	logger := GetChannelLogger(chain)
+	// indices of the remaining msgs in `msgs`
+	remaining := make([]int, len(msgs))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	var dropErr error
+	for len(remaining) > 0 {
+		batch := make([]sdk.Msg, len(remaining))
+		for i, index := range remaining {
+			batch[i] = msgs[index]
+		}
+		results, err := chain.SendMsgs(batch)
+		if err == nil {
+			msgResults := make([]MsgResult, len(msgs))
+			for i, index := range remaining {
+				msgResults[index] = results[i]
+			}
+			return msgResults, dropErr
+		}
+		failed, ok := FailedMsgIndex(err)
+		if !ok || failed < 0 || failed >= len(batch) || len(batch) == 1 || isUpdateClientMsg(batch[failed]) {
+			return nil, err
+		}
+		logger.Warn("dropped the failed msg from the tx and resending the remainder", "msg_index", remaining[failed], "msg_type", sdk.MsgTypeURL(batch[failed]), "error", err.Error())
+		dropErr = errors.Join(dropErr, fmt.Errorf("msg %d dropped: %w", remaining[failed], err))
+		remaining = append(remaining[:failed], remaining[failed+1:]...)
+	}
+	return nil, dropErr
+}
@@ -0,0 +1,82 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// batchChain is a chain on which a tx fails if it includes any msg in `bad`
+type batchChain struct {
+	core.Chain
+	bad map[sdk.Msg]bool
+	txs [][]sdk.Msg
+}
+
+func (c *batchChain) ChainID() string     { return "batch" }
+func (c *batchChain) Path() *core.PathEnd { return &core.PathEnd{ChainID: "batch"} }
+
+func (c *batchChain) SendMsgs(msgs []sdk.Msg) ([]core.MsgResult, error) {
+	c.txs = append(c.txs, msgs)
+	var results []core.MsgResult
+	for i, msg := range msgs {
+		if c.bad[msg] {
+			// the error message of the cosmos-sdk
+			return nil, fmt.Errorf("DeliverTx failed: failed to execute message; message index: %d: packet already received", i)
+		}
+		results = append(results, core.NewPendingMsgResult(&mock.MsgID{TxHash: fmt.Sprint(len(c.txs)), MsgIndex: uint32(i)}))
+	}
+	return results, nil
+}
+
+func TestFailedMsgIndex(t *testing.T) {
+	if index, ok := core.FailedMsgIndex(fmt.Errorf("tx failed: %w", &core.MsgFailureError{MsgIndex: 3, Reason: "bad"})); !ok || index != 3 {
+		t.Errorf("unexpected index of MsgFailureError: %d %v", index, ok)
+	}
+	if index, ok := core.FailedMsgIndex(errors.New("DeliverTx failed: failed to execute message; message index: 12: out of gas")); !ok || index != 12 {
+		t.Errorf("unexpected index of an sdk error: %d %v", index, ok)
+	}
+	if _, ok := core.FailedMsgIndex(errors.New("connection refused")); ok {
+		t.Error("an index is found in an unrelated error")
+	}
+}
+
+func TestSendDropsFailedMsgs(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	update := &clienttypes.MsgUpdateClient{ClientId: "mock-client-0"}
+	good1, bad, good2 := recvMsg("channel-0", 1), recvMsg("channel-0", 2), recvMsg("channel-0", 3)
+
+	src, dst := &batchChain{}, &batchChain{bad: map[sdk.Msg]bool{bad: true}}
+	msgs := core.NewRelayMsgs()
+	msgs.Dst = []sdk.Msg{update, good1, bad, good2}
+	msgs.Send(src, dst)
+
+	if msgs.Success() {
+		t.Error("Send succeeded although a msg is dropped")
+	}
+	if len(dst.txs) != 2 || len(dst.txs[1]) != 3 || dst.txs[1][2] != good2 {
+		t.Fatalf("the remainder is not resent: %v", dst.txs)
+	}
+	for i, id := range msgs.DstMsgIDs {
+		if (id == nil) != (i == 2) {
+			t.Errorf("unexpected msg id at %d: %v", i, id)
+		}
+	}
+
+	// a failed MsgUpdateClient fails the whole tx
+	dst = &batchChain{bad: map[sdk.Msg]bool{update: true}}
+	msgs = core.NewRelayMsgs()
+	msgs.Dst = []sdk.Msg{update, good1}
+	msgs.Send(src, dst)
+	if msgs.Success() || len(dst.txs) != 1 {
+		t.Errorf("the tx with a failed MsgUpdateClient is resent: %v", dst.txs)
+	}
+}
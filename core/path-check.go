@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	conntypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+)
+
+// PathCheckResult is the result of an item of the pre-flight check of a path
+type PathCheckResult struct {
+	ChainID string `json:"chain_id"`
+	Item    string `json:"item"`
+	OK      bool   `json:"ok"`
+	Detail  string `json:"detail"`
+}
+
+// CheckPath verifies that the path whose ends are set to `src` and `dst` is ready to relay:
+// RPC reachability, key existence and balance, client validity, and the states and compatibility of the connection and the channel.
+func CheckPath(src, dst *ProvableChain) []PathCheckResult {
+	return append(checkPathEnd(src, dst), checkPathEnd(dst, src)...)
+}
+
+func checkPathEnd(chain, counterparty *ProvableChain) []PathCheckResult {
+	var results []PathCheckResult
+	check := func(item string, detail string, err error) bool {
+		r := PathCheckResult{ChainID: chain.ChainID(), Item: item, OK: err == nil, Detail: detail}
+		if err != nil {
+			r.Detail = err.Error()
+		}
+		results = append(results, r)
+		return r.OK
+	}
+	pe, cpe := chain.Path(), counterparty.Path()
+
+	height, err := chain.LatestHeight()
+	if !check("rpc", fmt.Sprintf("latest height: %v", height), err) {
+		// the other items can't be checked without the RPC
+		return results
+	}
+	ctx := NewQueryContext(context.TODO(), height)
+
+	if addr, err := chain.GetAddress(); check("key", fmt.Sprintf("address: %v", addr), err) {
+		coins, err := chain.QueryBalance(ctx, addr)
+		if err == nil {
+			err = checkFeeBalance(chain, addr.String(), coins)
+		}
+		check("balance", coins.String(), err)
+	}
+
+	check("client", fmt.Sprintf("client_id: %s", pe.ClientID), checkClient(ctx, chain, counterparty))
+
+	connRes, err := chain.QueryConnection(ctx)
+	if err == nil {
+		err = checkConnection(connRes.Connection, pe, cpe)
+	}
+	check("connection", fmt.Sprintf("connection_id: %s, state: OPEN", pe.ConnectionID), err)
+
+	chanRes, err := chain.QueryChannel(ctx)
+	if err == nil {
+		err = checkChannel(chanRes.Channel, pe, cpe)
+	}
+	check("channel", fmt.Sprintf("port_id: %s, channel_id: %s, state: OPEN, version: %s", pe.PortID, pe.ChannelID, pe.Version), err)
+
+	return results
+}
+
+// checkFeeBalance checks that the relayer account holds coins to pay the tx fees
+func checkFeeBalance(chain *ProvableChain, addr string, coins sdk.Coins) error {
+	var denoms []string
+	if fp, ok := chain.Chain.(FeeDenomsProvider); ok {
+		denoms = fp.FeeDenoms()
+	}
+	if len(denoms) == 0 {
+		if coins.IsZero() {
+			return fmt.Errorf("the relayer account %s has no balance", addr)
+		}
+		return nil
+	}
+	for _, denom := range denoms {
+		if coins.AmountOf(denom).IsPositive() {
+			return nil
+		}
+	}
+	return fmt.Errorf("the relayer account %s has no balance in the fee denoms %v", addr, denoms)
+}
+
+func checkClient(ctx QueryContext, chain, counterparty *ProvableChain) error {
+	if chain.Path().ClientID == "" {
+		return fmt.Errorf("client_id is not set")
+	}
+	var cs ClientSnapshot
+	if err := cs.query(ctx, chain); err != nil {
+		return err
+	}
+	if cs.ExpiresAt != nil && cs.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("the client has expired at %v", cs.ExpiresAt)
+	}
+
+	res, err := chain.QueryClientState(ctx)
+	if err != nil {
+		return err
+	}
+	var clientState ibcexported.ClientState
+	if err := chain.Codec().UnpackAny(res.ClientState, &clientState); err != nil {
+		return err
+	}
+	if tmcs, ok := clientState.(*tmclient.ClientState); ok && tmcs.ChainId != counterparty.ChainID() {
+		return fmt.Errorf("the client tracks chain %s instead of %s", tmcs.ChainId, counterparty.ChainID())
+	}
+	return nil
+}
+
+func checkConnection(conn *conntypes.ConnectionEnd, pe, cpe *PathEnd) error {
+	switch {
+	case conn.State != conntypes.OPEN:
+		return fmt.Errorf("the connection is not open: %s", conn.State)
+	case conn.ClientId != pe.ClientID:
+		return fmt.Errorf("the connection is on client %s instead of %s", conn.ClientId, pe.ClientID)
+	case conn.Counterparty.ClientId != cpe.ClientID:
+		return fmt.Errorf("the counterparty client is %s instead of %s", conn.Counterparty.ClientId, cpe.ClientID)
+	case conn.Counterparty.ConnectionId != cpe.ConnectionID:
+		return fmt.Errorf("the counterparty connection is %s instead of %s", conn.Counterparty.ConnectionId, cpe.ConnectionID)
+	default:
+		return nil
+	}
+}
+
+func checkChannel(ch *chantypes.Channel, pe, cpe *PathEnd) error {
+	switch {
+	case ch.State != chantypes.OPEN:
+		return fmt.Errorf("the channel is not open: %s", ch.State)
+	case len(ch.ConnectionHops) == 0 || ch.ConnectionHops[0] != pe.ConnectionID:
+		return fmt.Errorf("the channel is on connection %v instead of %s", ch.ConnectionHops, pe.ConnectionID)
+	case ch.Ordering != pe.GetOrder():
+		return fmt.Errorf("the channel order is %s instead of %s", ch.Ordering, pe.Order)
+	case pe.Version != "" && ch.Version != pe.Version:
+		return fmt.Errorf("the channel version is %s instead of %s", ch.Version, pe.Version)
+	case ch.Counterparty.PortId != cpe.PortID:
+		return fmt.Errorf("the counterparty port is %s instead of %s", ch.Counterparty.PortId, cpe.PortID)
+	case ch.Counterparty.ChannelId != cpe.ChannelID:
+		return fmt.Errorf("the counterparty channel is %s instead of %s", ch.Counterparty.ChannelId, cpe.ChannelID)
+	default:
+		return nil
+	}
+}
@@ -54,6 +54,7 @@ func (p Paths) MustGet(name string) *Path {
 
 // Add adds a path by its name
 func (p Paths) Add(name string, path *Path) error {
+	path.Normalize()
 	if err := path.Validate(); err != nil {
 		return err
 	}
@@ -66,6 +67,7 @@ func (p Paths) Add(name string, path *Path) error {
 
 // AddForce ignores existing paths and overwrites an existing path with that name
 func (p Paths) AddForce(name string, path *Path) error {
+	path.Normalize()
 	if err := path.Validate(); err != nil {
 		return err
 	}
@@ -105,6 +107,159 @@ type Path struct {
 	Src      *PathEnd     `yaml:"src" json:"src"`
 	Dst      *PathEnd     `yaml:"dst" json:"dst"`
 	Strategy *StrategyCfg `yaml:"strategy" json:"strategy"`
+
+	// Group is the group of the path (e.g. the tenant for which the path is relayed), which shares the pause state
+	// and the fee budget with the other paths of the group and labels the metrics and the journal entries of the path
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+
+	// Protocol is the IBC protocol version of the path: "v1" (default) or "v2".
+	// A v2 path has only the clients registered as the counterparties of each other, without any connection or channel.
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+
+	// Channels are additional channels relayed over the same clients and connection as Src and Dst
+	Channels []*ChannelPair `yaml:"channels,omitempty" json:"channels,omitempty"`
+
+	// ChannelDiscovery enables the relay service to discover channels on the connection and relay them automatically
+	ChannelDiscovery *ChannelDiscoveryCfg `yaml:"channel-discovery,omitempty" json:"channel-discovery,omitempty"`
+
+	// FeeBudget pauses the relay service for the rest of the day when the fees paid for the path exceed the daily budget
+	FeeBudget *FeeBudgetCfg `yaml:"fee-budget,omitempty" json:"fee-budget,omitempty"`
+
+	// ValueLimits holds the packets transferring large amounts until they are released via the admin API
+	ValueLimits []*ValueLimitCfg `yaml:"value-limits,omitempty" json:"value-limits,omitempty"`
+
+	// Screening consults an external screening service with the sender and receiver addresses of packets before relaying them
+	Screening *ScreeningCfg `yaml:"screening,omitempty" json:"screening,omitempty"`
+
+	// Sharding splits the channels among multiple relay service instances started with different shard indices
+	Sharding *ShardingCfg `yaml:"sharding,omitempty" json:"sharding,omitempty"`
+
+	// FeePayee configures the payout addresses registered on the fee-enabled channels of the path
+	FeePayee *FeePayeeCfg `yaml:"fee-payee,omitempty" json:"fee-payee,omitempty"`
+
+	// ErrorAckAlert sends a webhook alert when a channel of the path starts producing error acknowledgements at a high rate
+	ErrorAckAlert *ErrorAckAlertCfg `yaml:"error-ack-alert,omitempty" json:"error-ack-alert,omitempty"`
+
+	// BacklogAlarm raises an alert when the pending packets on a channel of the path exceed a threshold and optionally sheds them
+	BacklogAlarm *BacklogAlarmCfg `yaml:"backlog-alarm,omitempty" json:"backlog-alarm,omitempty"`
+
+	// Yield makes the relay service yield the channels of the path to another relayer actively serving them
+	Yield *YieldCfg `yaml:"yield,omitempty" json:"yield,omitempty"`
+
+	// HeightLagAlarm raises an alert when the heights known to the relayer lag behind the latest heights reported by the chains
+	HeightLagAlarm *HeightLagAlarmCfg `yaml:"height-lag-alarm,omitempty" json:"height-lag-alarm,omitempty"`
+
+	// CommitmentCheck verifies the proofs of the packet commitments and the acknowledgements locally against the consensus states
+	// of the clients before submitting them, and raises an alert instead of submitting the msgs whose proofs don't verify
+	CommitmentCheck *CommitmentCheckCfg `yaml:"commitment-check,omitempty" json:"commitment-check,omitempty"`
+
+	// JournalAttestations makes the relay service sign each journal entry with the relayer key of the chain to which the msg is submitted,
+	// so that the operator can prove the relays (see `journal verify`)
+	JournalAttestations bool `yaml:"journal-attestations,omitempty" json:"journal-attestations,omitempty"`
+}
+
+// ChannelEnd represents the identifiers of a channel end relayed over the connection of a path
+type ChannelEnd struct {
+	ChannelID string `yaml:"channel-id,omitempty" json:"channel-id,omitempty"`
+	PortID    string `yaml:"port-id,omitempty" json:"port-id,omitempty"`
+	Order     string `yaml:"order,omitempty" json:"order,omitempty"`
+	Version   string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+// Normalize trims the whitespaces around the identifiers and upper-cases the channel order
+func (ch *ChannelEnd) Normalize() {
+	ch.ChannelID = strings.TrimSpace(ch.ChannelID)
+	ch.PortID = strings.TrimSpace(ch.PortID)
+	ch.Order = strings.ToUpper(strings.TrimSpace(ch.Order))
+}
+
+// ChannelPair represents a pair of channel ends on the src and dst chains of a path
+type ChannelPair struct {
+	Src *ChannelEnd `yaml:"src" json:"src"`
+	Dst *ChannelEnd `yaml:"dst" json:"dst"`
+}
+
+// PathEndPair is a pair of path ends on the src and dst chains
+type PathEndPair struct {
+	Src *PathEnd
+	Dst *PathEnd
+}
+
+// ChannelPathEnds returns the pairs of path ends for all channels relayed over the path.
+// The first element is always the pair of Src and Dst of the path, followed by the ones for Channels.
+func (p *Path) ChannelPathEnds() []PathEndPair {
+	pairs := []PathEndPair{{Src: p.Src, Dst: p.Dst}}
+	for _, ch := range p.Channels {
+		pairs = append(pairs, PathEndPair{
+			Src: p.Src.withChannel(ch.Src),
+			Dst: p.Dst.withChannel(ch.Dst),
+		})
+	}
+	return pairs
+}
+
+// ChannelParams are the parameters of the channel to create over a path, overriding the ones in the path config (e.g. by the flags of `tx channel`).
+// Empty fields are not overridden.
+type ChannelParams struct {
+	SrcPortID string
+	DstPortID string
+	Order     string
+	// SrcVersion and DstVersion are the versions proposed by the ends, which may be JSON metadata of a middleware stack
+	SrcVersion string
+	DstVersion string
+}
+
+// SetChannelParams overrides the parameters of the channel of the path and returns true if any of them is changed.
+// The parameters of a channel end whose channel ID is already set can't be changed.
+func (p *Path) SetChannelParams(params ChannelParams) (bool, error) {
+	var err error
+	if params.Order != "" {
+		params.Order = strings.ToUpper(strings.TrimSpace(params.Order))
+		if OrderFromString(params.Order) == chantypes.NONE {
+			return false, fmt.Errorf("channel order must be either 'ORDERED' or 'UNORDERED', got '%s'", params.Order)
+		}
+	}
+	for _, v := range []*string{&params.SrcVersion, &params.DstVersion} {
+		if *v == "" {
+			continue
+		}
+		if *v, err = NormalizeChannelVersion(*v); err != nil {
+			return false, err
+		}
+	}
+
+	changed := false
+	for _, end := range []struct {
+		pe      *PathEnd
+		portID  string
+		version string
+	}{
+		{p.Src, params.SrcPortID, params.SrcVersion},
+		{p.Dst, params.DstPortID, params.DstVersion},
+	} {
+		next := *end.pe
+		if end.portID != "" {
+			next.PortID = strings.TrimSpace(end.portID)
+		}
+		if params.Order != "" {
+			next.Order = params.Order
+		}
+		if end.version != "" {
+			next.Version = end.version
+		}
+		if next == *end.pe {
+			continue
+		}
+		if end.pe.ChannelID != "" {
+			return false, fmt.Errorf("the channel %s on %s is already set in the path; remove it from the path config to create a new channel", end.pe.ChannelID, end.pe.ChainID)
+		}
+		if err = next.Vport(); err != nil {
+			return false, err
+		}
+		*end.pe = next
+		changed = true
+	}
+	return changed, nil
 }
 
 // GenSrcClientID generates the specififed identifier
@@ -131,7 +286,65 @@ func (p *Path) Ordered() bool {
 }
 
 // Validate checks that a path is valid
+// Normalize normalizes the identifiers of the path ends (see PathEnd.Normalize)
+func (p *Path) Normalize() {
+	p.Protocol = strings.ToLower(strings.TrimSpace(p.Protocol))
+	if p.Src != nil {
+		p.Src.Normalize()
+		p.Src.protocol = p.Protocol
+	}
+	if p.Dst != nil {
+		p.Dst.Normalize()
+		p.Dst.protocol = p.Protocol
+	}
+	for _, ch := range p.Channels {
+		if ch.Src != nil {
+			ch.Src.Normalize()
+		}
+		if ch.Dst != nil {
+			ch.Dst.Normalize()
+		}
+	}
+}
+
+// ValidateIdentifiers validates the identifiers of the path ends, which are allowed to be empty before the handshakes
+func (p *Path) ValidateIdentifiers() error {
+	if p.Src == nil || p.Dst == nil {
+		return fmt.Errorf("both src and dst must be specified")
+	}
+	if err := p.validateProtocol(); err != nil {
+		return err
+	}
+	if err := p.Src.ValidateIdentifiers(); err != nil {
+		return err
+	}
+	if err := p.Dst.ValidateIdentifiers(); err != nil {
+		return err
+	}
+	for i, ch := range p.Channels {
+		if ch.Src == nil || ch.Dst == nil {
+			return fmt.Errorf("channels[%d]: both src and dst must be specified", i)
+		}
+		pair := p.ChannelPathEnds()[i+1]
+		if err := pair.Src.ValidateIdentifiers(); err != nil {
+			return fmt.Errorf("channels[%d]: %w", i, err)
+		}
+		if err := pair.Dst.ValidateIdentifiers(); err != nil {
+			return fmt.Errorf("channels[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
 func (p *Path) Validate() (err error) {
+	if err = p.validateProtocol(); err != nil {
+		return err
+	}
+	if p.Group != "" {
+		if err = ValidateGroupName(p.Group); err != nil {
+			return err
+		}
+	}
 	if err = p.Src.Validate(); err != nil {
 		return err
 	}
@@ -148,6 +361,72 @@ func (p *Path) Validate() (err error) {
 		return fmt.Errorf("both sides must have same order ('ORDERED' or 'UNORDERED'), got src(%s) and dst(%s)",
 			p.Src.Order, p.Dst.Order)
 	}
+	for i, ch := range p.Channels {
+		if ch.Src == nil || ch.Dst == nil {
+			return fmt.Errorf("channels[%d]: both src and dst must be specified", i)
+		}
+		pair := p.ChannelPathEnds()[i+1]
+		if err = pair.Src.Validate(); err != nil {
+			return fmt.Errorf("channels[%d]: %w", i, err)
+		}
+		if err = pair.Dst.Validate(); err != nil {
+			return fmt.Errorf("channels[%d]: %w", i, err)
+		}
+		if pair.Src.Order != pair.Dst.Order {
+			return fmt.Errorf("channels[%d]: both sides must have same order ('ORDERED' or 'UNORDERED'), got src(%s) and dst(%s)",
+				i, pair.Src.Order, pair.Dst.Order)
+		}
+	}
+	if p.ChannelDiscovery != nil {
+		if err = p.ChannelDiscovery.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.FeeBudget != nil {
+		if err = p.FeeBudget.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, limit := range p.ValueLimits {
+		if err = limit.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Screening != nil {
+		if err = p.Screening.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Sharding != nil {
+		if err = p.Sharding.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.ErrorAckAlert != nil {
+		if err = p.ErrorAckAlert.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.BacklogAlarm != nil {
+		if err = p.BacklogAlarm.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Yield != nil {
+		if err = p.Yield.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.HeightLagAlarm != nil {
+		if err = p.HeightLagAlarm.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.CommitmentCheck != nil {
+		if err = p.CommitmentCheck.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
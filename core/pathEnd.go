@@ -30,6 +30,36 @@ type PathEnd struct {
 	PortID       string `yaml:"port-id,omitempty" json:"port-id,omitempty"`
 	Order        string `yaml:"order,omitempty" json:"order,omitempty"`
 	Version      string `yaml:"version,omitempty" json:"version,omitempty"`
+
+	// protocol is the IBC protocol version of the path, which is set by Path.Normalize
+	protocol string
+}
+
+// withChannel returns a copy of the path end whose channel is replaced with `ch`
+func (pe *PathEnd) withChannel(ch *ChannelEnd) *PathEnd {
+	return &PathEnd{
+		ChainID:      pe.ChainID,
+		ClientID:     pe.ClientID,
+		ConnectionID: pe.ConnectionID,
+		ChannelID:    ch.ChannelID,
+		PortID:       ch.PortID,
+		Order:        ch.Order,
+		Version:      ch.Version,
+	}
+}
+
+// SetConfigID sets the identifier of `configID` to the path end
+func (pe *PathEnd) SetConfigID(configID ConfigIDType, id string) {
+	switch configID {
+	case ConfigIDClient:
+		pe.ClientID = id
+	case ConfigIDConnection:
+		pe.ConnectionID = id
+	case ConfigIDChannel:
+		pe.ChannelID = id
+	case ConfigIDChannelVersion:
+		pe.Version = id
+	}
 }
 
 // OrderFromString parses a string into a channel order byte
@@ -237,27 +267,27 @@ func (pe *PathEnd) ChanCloseConfirm(dstChanState *chantypes.QueryChannelResponse
 	)
 }
 
-// MsgTransfer creates a new transfer message
+// MsgTransfer creates a new transfer message.
+// `timeoutHeight` must be a height of the dst chain including its revision number.
 func (pe *PathEnd) MsgTransfer(dst *PathEnd, amount sdk.Coin, dstAddr string,
-	signer sdk.AccAddress, timeoutHeight, timeoutTimestamp uint64, memo string) sdk.Msg {
+	signer sdk.AccAddress, timeoutHeight clienttypes.Height, timeoutTimestamp uint64, memo string) sdk.Msg {
 
-	version := clienttypes.ParseChainID(dst.ChainID)
 	return transfertypes.NewMsgTransfer(
 		pe.PortID,
 		pe.ChannelID,
 		amount,
 		signer.String(),
 		dstAddr,
-		clienttypes.NewHeight(version, timeoutHeight),
+		timeoutHeight,
 		timeoutTimestamp,
 		memo,
 	)
 }
 
 // NewPacket returns a new packet from src to dist w
+// `timeoutHeight` must be a height of the dst chain including its revision number.
 func (pe *PathEnd) NewPacket(dst *PathEnd, sequence uint64, packetData []byte,
-	timeoutHeight, timeoutStamp uint64) chantypes.Packet {
-	version := clienttypes.ParseChainID(dst.ChainID)
+	timeoutHeight clienttypes.Height, timeoutStamp uint64) chantypes.Packet {
 	return chantypes.NewPacket(
 		packetData,
 		sequence,
@@ -265,7 +295,7 @@ func (pe *PathEnd) NewPacket(dst *PathEnd, sequence uint64, packetData []byte,
 		pe.ChannelID,
 		dst.PortID,
 		dst.ChannelID,
-		clienttypes.NewHeight(version, timeoutHeight),
+		timeoutHeight,
 		timeoutStamp,
 	)
 }
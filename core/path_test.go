@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestPathEndIdentifiers(t *testing.T) {
+	pe := &core.PathEnd{
+		ChainID:      "ibc0 ",
+		ClientID:     " 07-tendermint-0",
+		ConnectionID: "connection-0\t",
+		ChannelID:    "channel-1 ",
+		PortID:       "transfer",
+		Order:        "unordered ",
+	}
+	pe.Normalize()
+	if pe.ChainID != "ibc0" || pe.ClientID != "07-tendermint-0" || pe.ConnectionID != "connection-0" || pe.ChannelID != "channel-1" || pe.Order != "UNORDERED" {
+		t.Fatalf("unexpected normalized path end: %+v", pe)
+	}
+	if err := pe.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		pe    core.PathEnd
+		field string
+	}{
+		{core.PathEnd{ChannelID: "channel-01", PortID: "transfer"}, "channel-id"},
+		{core.PathEnd{ChannelID: "chan-1", PortID: "transfer"}, "channel-id"},
+		{core.PathEnd{ConnectionID: "connection-x", PortID: "transfer"}, "connection-id"},
+		{core.PathEnd{ClientID: "07-tendermint-007", PortID: "transfer"}, "client-id"},
+		{core.PathEnd{PortID: "transfer port"}, "port-id"},
+	}
+	for i, c := range cases {
+		err := c.pe.ValidateIdentifiers()
+		if err == nil {
+			t.Errorf("case %d: ValidateIdentifiers must fail", i)
+		} else if !strings.Contains(err.Error(), c.field) {
+			t.Errorf("case %d: the error must mention %s: %v", i, c.field, err)
+		}
+	}
+}
+
+func TestSetChannelParams(t *testing.T) {
+	path := core.GenPath("ibc0", "ibc1", "transfer", "transfer", "UNORDERED", "ics20-1")
+	path.Src.ChannelID, path.Dst.ChannelID = "", ""
+
+	changed, err := path.SetChannelParams(core.ChannelParams{
+		DstPortID:  "transfer2",
+		Order:      "unordered",
+		SrcVersion: "{\n  \"fee_version\": \"ics29-1\",\n  \"app_version\": \"ics20-1\"\n}",
+		DstVersion: `{"fee_version":"ics29-1","app_version":"ics20-1"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("the params must be changed")
+	}
+	if path.Src.PortID != "transfer" || path.Dst.PortID != "transfer2" || path.Src.Order != "UNORDERED" {
+		t.Errorf("unexpected path ends: %+v %+v", path.Src, path.Dst)
+	}
+	if v := `{"fee_version":"ics29-1","app_version":"ics20-1"}`; path.Src.Version != v || path.Dst.Version != v {
+		t.Errorf("the versions must be compacted: %s %s", path.Src.Version, path.Dst.Version)
+	}
+
+	if changed, err := path.SetChannelParams(core.ChannelParams{DstPortID: "transfer2"}); err != nil || changed {
+		t.Errorf("the same params must not change the path: %v %v", changed, err)
+	}
+	for i, params := range []core.ChannelParams{
+		{Order: "none"},
+		{SrcVersion: `{"fee_version":"ics29-1"`},
+		{DstPortID: "transfer port"},
+	} {
+		if _, err := path.SetChannelParams(params); err == nil {
+			t.Errorf("case %d: invalid params must be rejected", i)
+		}
+	}
+
+	path.Src.ChannelID = "channel-0"
+	if _, err := path.SetChannelParams(core.ChannelParams{SrcVersion: "ics20-1"}); err == nil {
+		t.Error("the params of an existing channel must not be changed")
+	}
+}
+
+func TestProtocolV2Path(t *testing.T) {
+	newPath := func() *core.Path {
+		path := core.GenPath("ibc0", "ibc1", "transfer", "transfer", "UNORDERED", "ics20-1")
+		path.Protocol = "V2 "
+		path.Src.ClientID, path.Dst.ClientID = "07-tendermint-0", "07-tendermint-1"
+		path.Src.ConnectionID, path.Dst.ConnectionID = "", ""
+		path.Src.ChannelID, path.Dst.ChannelID = "", ""
+		return path
+	}
+
+	path := newPath()
+	path.Normalize()
+	if err := path.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if path.ProtocolVersion() != core.ProtocolV2 || path.Src.Protocol() != core.ProtocolV2 {
+		t.Fatalf("unexpected protocol: %s", path.Protocol)
+	}
+	// the channel ID of a v2 path is the client ID after the counterparty is registered
+	path.Src.ChannelID = path.Src.ClientID
+	if err := path.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]func(p *core.Path){
+		"connection": func(p *core.Path) { p.Src.ConnectionID = "connection-0" },
+		"channel":    func(p *core.Path) { p.Dst.ChannelID = "channel-0" },
+		"ordered":    func(p *core.Path) { p.Src.Order, p.Dst.Order = "ORDERED", "ORDERED" },
+		"channels":   func(p *core.Path) { p.Channels = []*core.ChannelPair{{}} },
+		"unknown":    func(p *core.Path) { p.Protocol = "v3" },
+	}
+	for name, modify := range cases {
+		path := newPath()
+		modify(path)
+		path.Normalize()
+		if err := path.Validate(); err == nil {
+			t.Errorf("%s: Validate must fail", name)
+		}
+	}
+
+	if v1 := core.GenPath("ibc0", "ibc1", "transfer", "transfer", "UNORDERED", "ics20-1"); v1.ProtocolVersion() != core.ProtocolV1 {
+		t.Errorf("the default protocol must be %s", core.ProtocolV1)
+	}
+}
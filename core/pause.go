@@ -0,0 +1,64 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// PauseStateFile returns the path of the file to persist the pause state of the path
+func PauseStateFile(homePath, pathName string) string {
+	return filepath.Join(homePath, "pause", pathName+".json")
+}
+
+// PauseState is the pause state of a path persisted across restarts of the relay service
+type PauseState struct {
+	Paused bool      `json:"paused"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+
+	// Recovery is set if the path is paused because a client can't be updated any more
+	Recovery []*ClientRecovery `json:"recovery,omitempty"`
+}
+
+// LoadPauseState reads the pause state file. It returns a state that is not paused if the file doesn't exist.
+func LoadPauseState(file string) (*PauseState, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return &PauseState{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var state PauseState
+	if err := json.Unmarshal(bz, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the pause state file %s: %w", file, err)
+	}
+	return &state, nil
+}
+
+// SavePauseState writes the pause state file atomically
+func SavePauseState(file string, state *PauseState) error {
+	bz, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(file, bz, 0600)
+}
+
+// PausePath persists that the path is paused. The relay service of the path stops relaying in the next relay cycle.
+func PausePath(file, reason string) error {
+	return SavePauseState(file, &PauseState{Paused: true, Reason: reason, Since: time.Now()})
+}
+
+// ResumePath persists that the path is resumed
+func ResumePath(file string) error {
+	return SavePauseState(file, &PauseState{Paused: false, Since: time.Now()})
+}
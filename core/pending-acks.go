@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// pendingAcks keeps track of packets that have been received on the counterparty chain but whose acknowledgements have not been written yet.
+// Apps may write acknowledgements asynchronously (i.e. many blocks after RecvPacket), so such packets are polled
+// in every relay cycle until their acknowledgements appear or the packets are acknowledged by another relayer.
+type pendingAcks struct {
+	mu  sync.Mutex
+	src map[uint64]time.Time // packets sent from src and received on dst => time when the receipt was observed
+	dst map[uint64]time.Time // packets sent from dst and received on src => time when the receipt was observed
+}
+
+func newPendingAcks() *pendingAcks {
+	return &pendingAcks{
+		src: make(map[uint64]time.Time),
+		dst: make(map[uint64]time.Time),
+	}
+}
+
+// addReceived registers packets that are received on the counterparty chains
+func (pa *pendingAcks) addReceived(srcReceived, dstReceived PacketInfoList) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	now := time.Now()
+	for _, p := range srcReceived {
+		pa.src[p.Sequence] = now
+	}
+	for _, p := range dstReceived {
+		pa.dst[p.Sequence] = now
+	}
+}
+
+// update removes the packets whose acknowledgements have been written (i.e. contained in `srcAcks` or `dstAcks`)
+// and the packets already acknowledged on the sending chains, and then updates the metrics.
+// `srcAcks` are acknowledgements written on src, so they resolve the packets sent from dst, and vice versa.
+func (pa *pendingAcks) update(ctx context.Context, src, dst *ProvableChain, srcAcks, dstAcks PacketInfoList) error {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	for _, seq := range srcAcks.ExtractSequenceList() {
+		delete(pa.dst, seq)
+	}
+	for _, seq := range dstAcks.ExtractSequenceList() {
+		delete(pa.src, seq)
+	}
+
+	if err := pa.removeAcknowledged(src, pa.src); err != nil {
+		return fmt.Errorf("failed to query unreceived acknowledgements on src chain: %w", err)
+	}
+	if err := pa.removeAcknowledged(dst, pa.dst); err != nil {
+		return fmt.Errorf("failed to query unreceived acknowledgements on dst chain: %w", err)
+	}
+
+	logger := GetChannelPairLogger(src, dst)
+	if len(pa.src) > 0 || len(pa.dst) > 0 {
+		logger.Info("waiting for acknowledgements to be written",
+			"num_src", len(pa.src),
+			"num_dst", len(pa.dst),
+			"oldest_src_received_at", oldestTime(pa.src),
+			"oldest_dst_received_at", oldestTime(pa.dst),
+		)
+	}
+
+	metrics.PendingAcknowledgementsGauge.Set(
+		int64(len(pa.src)),
+		attribute.Key("chain_id").String(src.ChainID()),
+		attribute.Key("direction").String("src"),
+		relayDirectionAttr(SrcToDst),
+	)
+	metrics.PendingAcknowledgementsGauge.Set(
+		int64(len(pa.dst)),
+		attribute.Key("chain_id").String(dst.ChainID()),
+		attribute.Key("direction").String("dst"),
+		relayDirectionAttr(DstToSrc),
+	)
+	return nil
+}
+
+// removeAcknowledged removes the packets of which commitments no longer exist on the sending chain `chain`
+func (pa *pendingAcks) removeAcknowledged(chain *ProvableChain, pending map[uint64]time.Time) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	seqs := make([]uint64, 0, len(pending))
+	for seq := range pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	height, err := chain.LatestHeight()
+	if err != nil {
+		return err
+	}
+	unacked, err := chain.QueryUnreceivedAcknowledgements(NewQueryContext(context.TODO(), height), seqs)
+	if err != nil {
+		return err
+	}
+
+	remaining := make(map[uint64]struct{}, len(unacked))
+	for _, seq := range unacked {
+		remaining[seq] = struct{}{}
+	}
+	for _, seq := range seqs {
+		if _, ok := remaining[seq]; !ok {
+			delete(pending, seq)
+		}
+	}
+	return nil
+}
+
+func oldestTime(pending map[uint64]time.Time) time.Time {
+	var oldest time.Time
+	for _, t := range pending {
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return oldest
+}
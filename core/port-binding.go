@@ -0,0 +1,43 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPortNotBound is returned when no IBC app is bound to the port of a channel end
+var ErrPortNotBound = errors.New("port not bound")
+
+// PortBindingChecker is an optional interface of Chain that checks whether an IBC app is bound to a port
+// (e.g. the transfer module exists on the chain, or a contract is bound to the port on an EVM chain).
+type PortBindingChecker interface {
+	// CheckPortBinding returns an error wrapping ErrPortNotBound if no app is bound to `portID`.
+	// It returns nil if the binding can't be determined, in which case the handshake reports the error on-chain.
+	CheckPortBinding(ctx context.Context, portID string) error
+}
+
+// checkPortBindings fails fast before a channel handshake if no app is bound to the port of a channel end to be created,
+// instead of letting ChanOpenInit or ChanOpenTry fail opaquely on-chain
+func checkPortBindings(ctx context.Context, src, dst *ProvableChain) error {
+	logger := GetChannelPairLogger(src, dst)
+	for _, chain := range []*ProvableChain{src, dst} {
+		// the port of an existing channel end is bound
+		if chain.Path().ChannelID != "" {
+			continue
+		}
+		checker, ok := chain.Chain.(PortBindingChecker)
+		if !ok {
+			continue
+		}
+		portID := chain.Path().PortID
+		if err := checker.CheckPortBinding(ctx, portID); err != nil {
+			if errors.Is(err, ErrPortNotBound) {
+				return fmt.Errorf("no app is bound to the port %s on %s; check that the app (e.g. the transfer module or the contract) is enabled for the port: %w", portID, chain.ChainID(), err)
+			}
+			return fmt.Errorf("failed to check the port %s on %s: %w", portID, chain.ChainID(), err)
+		}
+		logger.Debug("checked the port binding", "chain_id", chain.ChainID(), "port_id", portID)
+	}
+	return nil
+}
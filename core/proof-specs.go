@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/gogoproto/jsonpb"
+	"github.com/cosmos/gogoproto/proto"
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	ics23 "github.com/cosmos/ics23/go"
+)
+
+// ProofSpecPresets are the well-known ICS-23 proof specs available by name in ProofSpecsCfg
+var ProofSpecPresets = map[string]*ics23.ProofSpec{
+	// the IAVL trees of the stores of the Cosmos SDK
+	"iavl": ics23.IavlSpec,
+	// the simple merkle tree of the store hashes of the Cosmos SDK, i.e. the app hash
+	"tendermint": ics23.TendermintSpec,
+	// the sparse merkle trees (e.g. the SMT stores of the Cosmos SDK)
+	"smt": ics23.SmtSpec,
+}
+
+// ProofSpecsCfg configures the ICS-23 proof specs of the proofs given by a chain, from the innermost store to the root
+// (e.g. ["iavl", "tendermint"] for a Cosmos SDK chain). The chains whose proofs are not proven by the presets,
+// like the EVM storage proofs of a chain module outside Cosmos, give the specs of their own.
+type ProofSpecsCfg struct {
+	// Specs are the proof specs: the name of a preset in ProofSpecPresets, or a custom spec in the JSON encoding of ics23.ProofSpec
+	Specs []json.RawMessage `json:"specs" yaml:"specs"`
+}
+
+// Validate validates the config
+func (cfg *ProofSpecsCfg) Validate() error {
+	_, err := cfg.ProofSpecs()
+	return err
+}
+
+// ProofSpecs returns the proof specs of the config
+func (cfg *ProofSpecsCfg) ProofSpecs() ([]*ics23.ProofSpec, error) {
+	if len(cfg.Specs) == 0 {
+		return nil, fmt.Errorf("proof-specs: specs must be specified")
+	}
+	specs := make([]*ics23.ProofSpec, len(cfg.Specs))
+	for i, raw := range cfg.Specs {
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			spec, ok := ProofSpecPresets[name]
+			if !ok {
+				return nil, fmt.Errorf("proof-specs: unknown preset: %q (available: %v)", name, proofSpecPresetNames())
+			}
+			specs[i] = spec
+			continue
+		}
+		var spec ics23.ProofSpec
+		if err := jsonpb.Unmarshal(bytes.NewReader(raw), &spec); err != nil {
+			return nil, fmt.Errorf("proof-specs: invalid spec %d: %w", i, err)
+		}
+		if spec.LeafSpec == nil || spec.InnerSpec == nil {
+			return nil, fmt.Errorf("proof-specs: spec %d must have the leaf_spec and the inner_spec", i)
+		}
+		specs[i] = &spec
+	}
+	return specs, nil
+}
+
+func proofSpecPresetNames() []string {
+	var names []string
+	for name := range ProofSpecPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProofSpecsEqual returns true if the proof specs are the same
+func ProofSpecsEqual(a, b []*ics23.ProofSpec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientProofSpecs returns the proof specs with which the client verifies the proofs, if the client verifies ICS-23 proofs
+func ClientProofSpecs(clientState exported.ClientState) ([]*ics23.ProofSpec, bool) {
+	ps, ok := clientState.(interface{ GetProofSpecs() []*ics23.ProofSpec })
+	if !ok {
+		return nil, false
+	}
+	return ps.GetProofSpecs(), true
+}
+
+// VerifyMembership verifies `proof` of the membership of `value` at `path` under `prefix` against `root` with `specs`.
+// The proof is a MerkleProof encoded by `cdc` as the ICS-23 based provers give.
+func VerifyMembership(cdc codec.BinaryCodec, specs []*ics23.ProofSpec, root exported.Root, prefix exported.Prefix, path string, value, proof []byte) error {
+	merkleProof, merklePath, err := decodeMerkleProof(cdc, prefix, path, proof)
+	if err != nil {
+		return err
+	}
+	return merkleProof.VerifyMembership(specs, root, merklePath, value)
+}
+
+// VerifyNonMembership verifies `proof` of the absence of a value at `path` under `prefix` against `root` with `specs`.
+// The proof is a MerkleProof encoded by `cdc` as the ICS-23 based provers give.
+func VerifyNonMembership(cdc codec.BinaryCodec, specs []*ics23.ProofSpec, root exported.Root, prefix exported.Prefix, path string, proof []byte) error {
+	merkleProof, merklePath, err := decodeMerkleProof(cdc, prefix, path, proof)
+	if err != nil {
+		return err
+	}
+	return merkleProof.VerifyNonMembership(specs, root, merklePath)
+}
+
+func decodeMerkleProof(cdc codec.BinaryCodec, prefix exported.Prefix, path string, proof []byte) (*commitmenttypes.MerkleProof, commitmenttypes.MerklePath, error) {
+	var merkleProof commitmenttypes.MerkleProof
+	if err := cdc.Unmarshal(proof, &merkleProof); err != nil {
+		return nil, commitmenttypes.MerklePath{}, fmt.Errorf("failed to decode the merkle proof: %w", err)
+	}
+	merklePath, err := commitmenttypes.ApplyPrefix(prefix, commitmenttypes.NewMerklePath(path))
+	if err != nil {
+		return nil, commitmenttypes.MerklePath{}, err
+	}
+	return &merkleProof, merklePath, nil
+}
+
+// SetProofSpecs sets the proof specs configured by chain ID. The commitment check verifies the proofs given by a chain with its proof specs
+// if the client doesn't tell them, and reports a mismatch if the client verifies the proofs with different specs.
+func (srv *RelayService) SetProofSpecs(cfgs map[string]*ProofSpecsCfg) error {
+	specs := make(map[string][]*ics23.ProofSpec)
+	for chainID, cfg := range cfgs {
+		s, err := cfg.ProofSpecs()
+		if err != nil {
+			return fmt.Errorf("chain %s: %w", chainID, err)
+		}
+		specs[chainID] = s
+	}
+	srv.proofSpecs = specs
+	return nil
+}
@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+	ics23 "github.com/cosmos/ics23/go"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestProofSpecsCfg(t *testing.T) {
+	var cfg core.ProofSpecsCfg
+	if err := json.Unmarshal([]byte(`{"specs": ["iavl", "tendermint"]}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := cfg.ProofSpecs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !core.ProofSpecsEqual(specs, commitmenttypes.GetSDKSpecs()) {
+		t.Errorf("unexpected specs: %v", specs)
+	}
+
+	// a custom spec in the JSON encoding of ics23.ProofSpec
+	custom := `{"specs": [{
+		"leaf_spec": {"hash": "KECCAK", "prehash_key": "KECCAK", "prehash_value": "NO_HASH", "length": "NO_PREFIX", "prefix": "AA=="},
+		"inner_spec": {"child_order": [0, 1], "child_size": 32, "min_prefix_length": 1, "max_prefix_length": 1, "hash": "KECCAK"},
+		"max_depth": 64
+	}, "tendermint"]}`
+	if err := json.Unmarshal([]byte(custom), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	specs, err = cfg.ProofSpecs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || specs[0].LeafSpec.Hash != ics23.HashOp_KECCAK || specs[0].MaxDepth != 64 || specs[1] != ics23.TendermintSpec {
+		t.Errorf("unexpected specs: %v", specs)
+	}
+	if core.ProofSpecsEqual(specs, commitmenttypes.GetSDKSpecs()) {
+		t.Error("the different specs are equal")
+	}
+
+	for _, invalid := range []string{
+		`{"specs": []}`,
+		`{"specs": ["patricia"]}`,
+		`{"specs": [{"max_depth": 64}]}`,
+		`{"specs": [{"leaf_spec": {"hash": "UNKNOWN"}}]}`,
+	} {
+		var cfg core.ProofSpecsCfg
+		if err := json.Unmarshal([]byte(invalid), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("the invalid specs are valid: %s", invalid)
+		}
+	}
+}
+
+func TestVerifyMembership(t *testing.T) {
+	cdc := core.MakeCodec()
+	specs := commitmenttypes.GetSDKSpecs()
+	path := host.PacketCommitmentPath("transfer", "channel-0", 1)
+	absent := host.PacketCommitmentPath("transfer", "channel-0", 2)
+	kvs := map[string][]byte{
+		path: []byte("commitment"),
+		host.PacketCommitmentPath("transfer", "channel-0", 3): []byte("commitment"),
+	}
+
+	proof, root := proveIBCStore(t, kvs, path)
+	if err := core.VerifyMembership(cdc, specs, commitmenttypes.NewMerkleRoot(root), &core.DefaultChainPrefix, path, kvs[path], proof); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.VerifyNonMembership(cdc, specs, commitmenttypes.NewMerkleRoot(root), &core.DefaultChainPrefix, path, proof); err == nil {
+		t.Error("the absence of the existing value is verified")
+	}
+
+	proof, root = proveIBCStore(t, kvs, absent)
+	if err := core.VerifyNonMembership(cdc, specs, commitmenttypes.NewMerkleRoot(root), &core.DefaultChainPrefix, absent, proof); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.VerifyMembership(cdc, specs, commitmenttypes.NewMerkleRoot(root), &core.DefaultChainPrefix, absent, kvs[path], proof); err == nil {
+		t.Error("the membership of the absent value is verified")
+	}
+	if err := core.VerifyNonMembership(cdc, []*ics23.ProofSpec{ics23.SmtSpec, ics23.TendermintSpec}, commitmenttypes.NewMerkleRoot(root), &core.DefaultChainPrefix, absent, proof); err == nil {
+		t.Error("the proof is verified with the wrong specs")
+	}
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// IBC protocol versions of a path
+const (
+	// ProtocolV1 is the classic IBC, where packets are sent on channels established by the connection and channel handshakes
+	ProtocolV1 = "v1"
+	// ProtocolV2 is IBC v2 ("Eureka"), where packets are sent between a pair of clients registered as the counterparties of each other
+	// without any connection or channel
+	ProtocolV2 = "v2"
+)
+
+// ErrIBCv2NotSupported is returned when a chain of a v2 path doesn't support IBC v2
+var ErrIBCv2NotSupported = errors.New("IBC v2 not supported")
+
+// IBCv2Chain is an optional interface of Chain for the chains whose IBC handler supports IBC v2.
+// The relayer handles the packets of a v2 path in the same way as a channel whose channel ID is the client ID of each end,
+// so the chain module reports v2 packets with the client IDs in place of the channel IDs (and the port IDs of the payload),
+// and it converts MsgRecvPacket, MsgAcknowledgement and MsgTimeout to the msgs of IBC v2 when submitting them.
+type IBCv2Chain interface {
+	// MerklePathPrefix returns the prefix of the merkle paths under which the chain commits the packets of IBC v2
+	MerklePathPrefix() [][]byte
+
+	// QueryCounterpartyClient returns the client ID registered as the counterparty of the client of the path end, or "" if none is registered
+	QueryCounterpartyClient(ctx QueryContext) (string, error)
+
+	// RegisterCounterparty registers the client of the counterparty chain and its merkle path prefix as the counterparty of the client of the path end
+	RegisterCounterparty(ctx context.Context, counterpartyClientID string, counterpartyMerklePathPrefix [][]byte) error
+}
+
+// ProtocolVersion returns the IBC protocol version of the path, which is ProtocolV1 if not specified
+func (p *Path) ProtocolVersion() string {
+	if p.Protocol == "" {
+		return ProtocolV1
+	}
+	return p.Protocol
+}
+
+// Protocol returns the IBC protocol version of the path to which the path end belongs
+func (pe *PathEnd) Protocol() string {
+	if pe.protocol == "" {
+		return ProtocolV1
+	}
+	return pe.protocol
+}
+
+// validateProtocol validates the protocol version of the path and the identifiers required by it
+func (p *Path) validateProtocol() error {
+	switch p.ProtocolVersion() {
+	case ProtocolV1:
+		return nil
+	case ProtocolV2:
+	default:
+		return fmt.Errorf("invalid protocol: %s", p.Protocol)
+	}
+	if len(p.Channels) > 0 || p.ChannelDiscovery != nil {
+		return fmt.Errorf("protocol %s: channels and channel-discovery are not available because the path has no connection", ProtocolV2)
+	}
+	for _, pe := range []*PathEnd{p.Src, p.Dst} {
+		if pe.ConnectionID != "" {
+			return fmt.Errorf("protocol %s: connection-id must be empty on %s", ProtocolV2, pe.ChainID)
+		}
+		if pe.ChannelID != "" && pe.ChannelID != pe.ClientID {
+			return fmt.Errorf("protocol %s: channel-id must be empty or the client-id on %s: %s", ProtocolV2, pe.ChainID, pe.ChannelID)
+		}
+		if pe.Order != "" && pe.GetOrder() != chantypes.UNORDERED {
+			return fmt.Errorf("protocol %s: packets are always unordered, but the order on %s is %s", ProtocolV2, pe.ChainID, pe.Order)
+		}
+	}
+	return nil
+}
+
+// CheckProtocolSupport returns ErrIBCv2NotSupported if the path of the chains is a v2 path and either of the chains doesn't support IBC v2
+func CheckProtocolSupport(src, dst *ProvableChain) error {
+	if src.Path().Protocol() != ProtocolV2 {
+		return nil
+	}
+	for _, chain := range []*ProvableChain{src, dst} {
+		if _, ok := chain.Chain.(IBCv2Chain); !ok {
+			return fmt.Errorf("%w: chain %s", ErrIBCv2NotSupported, chain.ChainID())
+		}
+	}
+	return nil
+}
+
+// RegisterCounterparties registers the clients of a v2 path as the counterparties of each other, which replaces the connection and channel handshakes.
+// The client ID of each end is set to the channel ID of the path end when its counterparty is registered.
+// It returns false if both counterparties have already been registered.
+func RegisterCounterparties(ctx context.Context, pathName string, src, dst *ProvableChain) (bool, error) {
+	logger := GetChainPairLogger(src, dst)
+	defer logger.TimeTrack(time.Now(), "RegisterCounterparties")
+	if err := CheckProtocolSupport(src, dst); err != nil {
+		return false, err
+	}
+	if src.Path().Protocol() != ProtocolV2 {
+		return false, fmt.Errorf("the path %s is not a %s path", pathName, ProtocolV2)
+	}
+	registered := false
+	for _, c := range []struct{ self, counterparty *ProvableChain }{{src, dst}, {dst, src}} {
+		self, counterparty := c.self, c.counterparty
+		clientID, counterpartyClientID := self.Path().ClientID, counterparty.Path().ClientID
+		if clientID == "" || counterpartyClientID == "" {
+			return false, fmt.Errorf("the clients must be created before registering the counterparties")
+		}
+		height, err := self.LatestHeight()
+		if err != nil {
+			return false, err
+		}
+		v2 := self.Chain.(IBCv2Chain)
+		current, err := v2.QueryCounterpartyClient(NewQueryContext(ctx, height))
+		if err != nil {
+			return false, err
+		}
+		switch current {
+		case counterpartyClientID:
+		case "":
+			prefix := counterparty.Chain.(IBCv2Chain).MerklePathPrefix()
+			if err := v2.RegisterCounterparty(ctx, counterpartyClientID, prefix); err != nil {
+				return false, fmt.Errorf("failed to register the counterparty on %s: %w", self.ChainID(), err)
+			}
+			logger.Info("registered the counterparty",
+				"chain_id", self.ChainID(),
+				"client_id", clientID,
+				"counterparty_client_id", counterpartyClientID,
+				"counterparty_merkle_path_prefix", formatMerklePathPrefix(prefix),
+			)
+			registered = true
+		default:
+			return false, fmt.Errorf("the client %s on %s already has another counterparty: %s", clientID, self.ChainID(), current)
+		}
+		if self.Path().ChannelID != clientID {
+			self.Path().ChannelID = clientID
+			if err := config.UpdateConfigID(pathName, self.ChainID(), ConfigIDChannel, clientID); err != nil {
+				return false, err
+			}
+		}
+	}
+	return registered, nil
+}
+
+func formatMerklePathPrefix(prefix [][]byte) string {
+	keys := make([]string, 0, len(prefix))
+	for _, key := range prefix {
+		keys = append(keys, string(key))
+	}
+	return strings.Join(keys, "/")
+}
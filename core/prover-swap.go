@@ -0,0 +1,307 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"github.com/cosmos/ibc-go/v7/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// ProverConfigUpdater is an optional interface of ConfigI that saves the prover config of a chain swapped at runtime,
+// so that the new prover is also used after a restart
+type ProverConfigUpdater interface {
+	UpdateProverConfig(m codec.JSONCodec, chainID string, prover ProverConfig) error
+}
+
+// proverSetup records the setup of the prover of a ProvableChain (Init, SetRelayInfo and SetupForRelay),
+// so that a new prover is set up in the same way before it is swapped in by SwapProver
+type proverSetup struct {
+	// mu serializes the swaps and the setup of the prover
+	mu sync.Mutex
+
+	// the arguments of Init, or nil if the prover is not initialized yet
+	init *proverInitArgs
+	// the arguments of SetRelayInfo, or nil if the relay info is not set yet
+	relay *proverRelayInfo
+	// setupForRelay is true if SetupForRelay has been called
+	setupForRelay bool
+}
+
+type proverInitArgs struct {
+	homePath string
+	timeout  time.Duration
+	codec    codec.ProtoCodecMarshaler
+	debug    bool
+}
+
+type proverRelayInfo struct {
+	path             *PathEnd
+	counterparty     *ProvableChain
+	counterpartyPath *PathEnd
+}
+
+// initProver initializes the prover and records the arguments. Nothing is recorded if `s` is nil.
+func (s *proverSetup) initProver(prover Prover, homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	if s == nil {
+		return prover.Init(homePath, timeout, codec, debug)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := prover.Init(homePath, timeout, codec, debug); err != nil {
+		return err
+	}
+	s.init = &proverInitArgs{homePath, timeout, codec, debug}
+	return nil
+}
+
+// setRelayInfo sets the relay info to the prover and records it. Nothing is recorded if `s` is nil.
+func (s *proverSetup) setRelayInfo(prover Prover, path *PathEnd, counterparty *ProvableChain, counterpartyPath *PathEnd) error {
+	if s == nil {
+		return prover.SetRelayInfo(path, counterparty, counterpartyPath)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := prover.SetRelayInfo(path, counterparty, counterpartyPath); err != nil {
+		return err
+	}
+	s.relay = &proverRelayInfo{path, counterparty, counterpartyPath}
+	return nil
+}
+
+// setupProverForRelay sets up the prover for relay and records it. Nothing is recorded if `s` is nil.
+func (s *proverSetup) setupProverForRelay(ctx context.Context, prover Prover) error {
+	if s == nil {
+		return prover.SetupForRelay(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := prover.SetupForRelay(ctx); err != nil {
+		return err
+	}
+	s.setupForRelay = true
+	return nil
+}
+
+// codec returns the codec given to Init, or nil if the prover is not initialized yet
+func (s *proverSetup) codec() codec.ProtoCodecMarshaler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.init == nil {
+		return nil
+	}
+	return s.init.codec
+}
+
+// swap sets up `prover` in the same way as the current prover of `h` and replaces the current prover with it
+func (s *proverSetup) swap(ctx context.Context, h *proverHolder, prover Prover) (Prover, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a := s.init; a != nil {
+		if err := prover.Init(a.homePath, a.timeout, a.codec, a.debug); err != nil {
+			return nil, fmt.Errorf("failed to initialize the new prover: %w", err)
+		}
+	}
+	if ri := s.relay; ri != nil {
+		if err := prover.SetRelayInfo(ri.path, ri.counterparty, ri.counterpartyPath); err != nil {
+			return nil, fmt.Errorf("failed to set the relay info to the new prover: %w", err)
+		}
+	}
+	if s.setupForRelay {
+		if err := prover.SetupForRelay(ctx); err != nil {
+			return nil, fmt.Errorf("failed to set up the new prover for relay: %w", err)
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	prev := h.prover
+	h.prover = prover
+	return prev, nil
+}
+
+// proverHolder holds the prover of a ProvableChain whose prover is swappable (see EnableProverSwap).
+// It implements Prover by delegating to the current prover.
+type proverHolder struct {
+	mu     sync.RWMutex
+	prover Prover
+}
+
+var _ Prover = (*proverHolder)(nil)
+
+// current returns the current prover
+func (h *proverHolder) current() Prover {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.prover
+}
+
+// Init implements Prover
+func (h *proverHolder) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	return h.current().Init(homePath, timeout, codec, debug)
+}
+
+// SetRelayInfo implements Prover
+func (h *proverHolder) SetRelayInfo(path *PathEnd, counterparty *ProvableChain, counterpartyPath *PathEnd) error {
+	return h.current().SetRelayInfo(path, counterparty, counterpartyPath)
+}
+
+// SetupForRelay implements Prover
+func (h *proverHolder) SetupForRelay(ctx context.Context) error {
+	return h.current().SetupForRelay(ctx)
+}
+
+// GetLatestFinalizedHeader implements Prover
+func (h *proverHolder) GetLatestFinalizedHeader() (Header, error) {
+	return h.current().GetLatestFinalizedHeader()
+}
+
+// CreateInitialLightClientState implements Prover
+func (h *proverHolder) CreateInitialLightClientState(height exported.Height) (exported.ClientState, exported.ConsensusState, error) {
+	return h.current().CreateInitialLightClientState(height)
+}
+
+// SetupHeadersForUpdate implements Prover
+func (h *proverHolder) SetupHeadersForUpdate(counterparty FinalityAwareChain, latestFinalizedHeader Header) ([]Header, error) {
+	return h.current().SetupHeadersForUpdate(counterparty, latestFinalizedHeader)
+}
+
+// CheckRefreshRequired implements Prover
+func (h *proverHolder) CheckRefreshRequired(counterparty ChainInfoICS02Querier) (bool, error) {
+	return h.current().CheckRefreshRequired(counterparty)
+}
+
+// ProveState implements Prover
+func (h *proverHolder) ProveState(ctx QueryContext, path string, value []byte) ([]byte, clienttypes.Height, error) {
+	return h.current().ProveState(ctx, path, value)
+}
+
+// ProveHostConsensusState implements Prover
+func (h *proverHolder) ProveHostConsensusState(ctx QueryContext, height exported.Height, consensusState exported.ConsensusState) ([]byte, error) {
+	return h.current().ProveHostConsensusState(ctx, height, consensusState)
+}
+
+// EnableProverSwap makes the prover of the chain swappable by SwapProver. The prover is held behind a holder in the Prover field
+// afterwards, so the concrete type and the optional interfaces of the prover must be checked on CurrentProver.
+// It must be called before the chain is used concurrently (e.g. before the relay service starts).
+func (pc *ProvableChain) EnableProverSwap() error {
+	if pc.setup == nil {
+		return fmt.Errorf("the prover of chain %s is not swappable since the chain is not built by NewProvableChain", pc.ChainID())
+	}
+	if _, ok := pc.Prover.(*proverHolder); !ok {
+		pc.Prover = &proverHolder{prover: pc.Prover}
+	}
+	return nil
+}
+
+// CurrentProver returns the current prover of the chain.
+// The concrete type and the optional interfaces of the prover should be checked on it, since the prover may be swapped at runtime by SwapProver.
+func (pc *ProvableChain) CurrentProver() Prover {
+	if h, ok := pc.Prover.(*proverHolder); ok {
+		return h.current()
+	}
+	return pc.Prover
+}
+
+// proverHolder returns the holder of the prover, which is available only if the prover swap is enabled
+func (pc *ProvableChain) proverHolder() (*proverHolder, error) {
+	h, ok := pc.Prover.(*proverHolder)
+	if !ok {
+		return nil, fmt.Errorf("swapping the prover of chain %s is not enabled", pc.ChainID())
+	}
+	return h, nil
+}
+
+// SwapProver replaces the prover of the chain with `prover` and returns the previous one. The swap must be enabled by EnableProverSwap.
+// The new prover is initialized, given the relay info and set up for relay in the same way as the previous one before the swap,
+// and the previous one is kept if any of them fails. The chain and the path state (e.g. the clients, the connection and the channel)
+// are kept as they are, so the new prover must produce the headers and the proofs verifiable by the existing light client on the counterparty.
+func (pc *ProvableChain) SwapProver(ctx context.Context, prover Prover) (Prover, error) {
+	h, err := pc.proverHolder()
+	if err != nil {
+		return nil, err
+	}
+	prev, err := pc.setup.swap(ctx, h, prover)
+	if err != nil {
+		return nil, err
+	}
+	GetChainLogger(pc).Info("swapped the prover", "previous_prover", fmt.Sprintf("%T", prev), "prover", fmt.Sprintf("%T", prover))
+	return prev, nil
+}
+
+// ProverInfo describes the prover of a chain
+type ProverInfo struct {
+	ChainID string `json:"chain_id"`
+	// Type is the Go type of the prover
+	Type string `json:"type"`
+	// PreviousType is the Go type of the prover replaced by a swap
+	PreviousType string `json:"previous_type,omitempty"`
+}
+
+// EnableProverSwap makes the provers of the chains swappable by SwapProver (see ProvableChain.EnableProverSwap).
+// It must be called before the service starts.
+func (srv *RelayService) EnableProverSwap() error {
+	for _, chain := range []*ProvableChain{srv.src, srv.dst} {
+		if err := chain.EnableProverSwap(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Provers returns the current provers of the chains of the path
+func (srv *RelayService) Provers() []ProverInfo {
+	var ret []ProverInfo
+	for _, chain := range []*ProvableChain{srv.src, srv.dst} {
+		ret = append(ret, ProverInfo{ChainID: chain.ChainID(), Type: fmt.Sprintf("%T", chain.CurrentProver())})
+	}
+	return ret
+}
+
+// SwapProver builds a prover from the JSON of the prover config (in the same format as the config file) and swaps the prover
+// of the chain `chainID` with it, without stopping the service. The next relay cycle uses the new prover.
+// The new prover config is saved to the config file if the core config supports it (see ProverConfigUpdater).
+func (srv *RelayService) SwapProver(ctx context.Context, chainID string, proverConfig json.RawMessage) (*ProverInfo, error) {
+	var chain *ProvableChain
+	for _, c := range []*ProvableChain{srv.src, srv.dst} {
+		if c.ChainID() == chainID {
+			chain = c
+		}
+	}
+	if chain == nil {
+		return nil, fmt.Errorf("chain %s is not served by the service", chainID)
+	}
+	if _, err := chain.proverHolder(); err != nil {
+		return nil, err
+	}
+	m := chain.setup.codec()
+	if m == nil {
+		return nil, fmt.Errorf("the prover of chain %s is not initialized", chainID)
+	}
+	var cfg ProverConfig
+	if err := utils.UnmarshalJSONAny(m, &cfg, proverConfig); err != nil {
+		return nil, fmt.Errorf("invalid prover config: %w", err)
+	} else if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid prover config: %w", err)
+	}
+	prover, err := cfg.Build(chain.Chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the prover: %w", err)
+	}
+	prev, err := chain.SwapProver(ctx, prover)
+	if err != nil {
+		return nil, err
+	}
+	if u, ok := config.(ProverConfigUpdater); ok {
+		if err := u.UpdateProverConfig(m, chainID, cfg); err != nil {
+			logger := log.GetLogger().WithModule("core.admin")
+			logger.Error("failed to save the prover config", err, "chain_id", chainID)
+		}
+	}
+	return &ProverInfo{ChainID: chainID, Type: fmt.Sprintf("%T", prover), PreviousType: fmt.Sprintf("%T", prev)}, nil
+}
@@ -0,0 +1,149 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	mockprover "github.com/hyperledger-labs/yui-relayer/provers/mock"
+)
+
+// setupRecordingProver is a mock prover recording the setup of the prover by the ProvableChain
+type setupRecordingProver struct {
+	*mockprover.Prover
+	initErr              error
+	inits, relayInfoSets int
+}
+
+func (pr *setupRecordingProver) Init(homePath string, timeout time.Duration, codec codec.ProtoCodecMarshaler, debug bool) error {
+	pr.inits++
+	return pr.initErr
+}
+
+func (pr *setupRecordingProver) SetRelayInfo(path *core.PathEnd, counterparty *core.ProvableChain, counterpartyPath *core.PathEnd) error {
+	pr.relayInfoSets++
+	return nil
+}
+
+func TestSwapProver(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	chains, _ := newScriptedChains(t)
+
+	// the concrete prover is kept in the field until the swap is enabled
+	initial, ok := chains[0].Prover.(*mockprover.Prover)
+	if !ok {
+		t.Fatalf("unexpected prover in the field: %T", chains[0].Prover)
+	}
+	if _, err := chains[0].SwapProver(context.TODO(), mockprover.NewProver(chains[0].Chain, mockprover.ProverConfig{})); err == nil {
+		t.Fatal("the prover is swapped without EnableProverSwap")
+	}
+	if err := chains[0].EnableProverSwap(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the new prover is set up in the same way as the previous one, including the setup before the swap is enabled
+	if chains[0].CurrentProver() != core.Prover(initial) {
+		t.Fatalf("unexpected current prover: %T", chains[0].CurrentProver())
+	}
+	next := &setupRecordingProver{Prover: mockprover.NewProver(chains[0].Chain, mockprover.ProverConfig{})}
+	prev, err := chains[0].SwapProver(context.TODO(), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev != core.Prover(initial) || chains[0].CurrentProver() != core.Prover(next) {
+		t.Fatalf("the prover is not swapped: %T", chains[0].CurrentProver())
+	}
+	if next.inits != 1 || next.relayInfoSets != 1 {
+		t.Errorf("the new prover is not set up: inits=%d, relayInfoSets=%d", next.inits, next.relayInfoSets)
+	}
+	if _, err := chains[0].GetLatestFinalizedHeader(); err != nil {
+		t.Errorf("the new prover doesn't work: %v", err)
+	}
+
+	// the previous prover is kept if the setup of the new one fails
+	broken := &setupRecordingProver{Prover: mockprover.NewProver(chains[0].Chain, mockprover.ProverConfig{}), initErr: errors.New("broken")}
+	if _, err := chains[0].SwapProver(context.TODO(), broken); err == nil {
+		t.Fatal("the broken prover is swapped in")
+	}
+	if chains[0].CurrentProver() != core.Prover(next) {
+		t.Errorf("the prover is changed by the failed swap: %T", chains[0].CurrentProver())
+	}
+
+	// a chain built without NewProvableChain keeps working with the prover in the field, which is not swappable
+	literal := &core.ProvableChain{Chain: chains[0].Chain, Prover: initial}
+	if literal.CurrentProver() != core.Prover(initial) {
+		t.Errorf("unexpected prover: %T", literal.CurrentProver())
+	}
+	if err := literal.EnableProverSwap(); err == nil {
+		t.Error("the prover swap is enabled on a chain built without NewProvableChain")
+	}
+	if _, err := literal.SwapProver(context.TODO(), next); err == nil {
+		t.Error("the prover of a chain built without NewProvableChain is swapped")
+	}
+
+	// swap through the admin API
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], nil, 0, 0, 0, 0, 0)
+	cfg := json.RawMessage(`{"@type": "/relayer.provers.mock.config.ProverConfig", "finality_delay": 3}`)
+	disabled := core.NewAdminServer(srv, core.AdminServerOptions{AuthToken: "token"})
+	bz, err := json.Marshal(core.SwapProverRequest{ChainID: "ibc0", Prover: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/prover", bytes.NewReader(bz))
+	r.Header.Set("Authorization", "Bearer token")
+	if disabled.ServeHTTP(rec, r); rec.Code != http.StatusForbidden {
+		t.Errorf("the prover is swapped without EnableProverSwap: %d", rec.Code)
+	}
+	if err := srv.EnableProverSwap(); err != nil {
+		t.Fatal(err)
+	}
+	admin := core.NewAdminServer(srv, core.AdminServerOptions{AuthToken: "token", EnableProverSwap: true})
+	post := func(req core.SwapProverRequest) *httptest.ResponseRecorder {
+		bz, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/prover", bytes.NewReader(bz))
+		r.Header.Set("Authorization", "Bearer token")
+		admin.ServeHTTP(rec, r)
+		return rec
+	}
+	if rec := post(core.SwapProverRequest{ChainID: "ibc0", Prover: cfg}); rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := chains[0].CurrentProver().(*mockprover.Prover); !ok {
+		t.Errorf("the prover is not swapped by the admin API: %T", chains[0].CurrentProver())
+	}
+	if rec := post(core.SwapProverRequest{ChainID: "ibc2", Prover: cfg}); rec.Code != http.StatusBadRequest {
+		t.Errorf("a prover of an unknown chain is swapped: %d", rec.Code)
+	}
+	if rec := post(core.SwapProverRequest{ChainID: "ibc1", Prover: json.RawMessage(`{"@type": "/unknown.ProverConfig"}`)}); rec.Code != http.StatusBadRequest {
+		t.Errorf("an unknown prover config is accepted: %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/prover", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	admin.ServeHTTP(rec, req)
+	var res struct {
+		Provers []core.ProverInfo `json:"provers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Provers) != 2 || res.Provers[0].ChainID != "ibc0" || res.Provers[0].Type != "*mock.Prover" {
+		t.Errorf("unexpected provers: %+v", res.Provers)
+	}
+}
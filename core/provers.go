@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -71,3 +72,49 @@ type ChainInfoICS02Querier interface {
 	ChainInfo
 	ICS02Querier
 }
+
+// ProofRequestID is an identifier of a proof generation request issued to AsyncStateProver
+type ProofRequestID string
+
+// ProofStatus represents the status of a proof generation request
+type ProofStatus int
+
+const (
+	// ProofStatusPending means that the proof is still being generated
+	ProofStatusPending ProofStatus = iota
+	// ProofStatusReady means that the proof has been generated and can be retrieved
+	ProofStatusReady
+	// ProofStatusFailed means that the proof generation has failed and the request should be issued again
+	ProofStatusFailed
+)
+
+func (s ProofStatus) String() string {
+	switch s {
+	case ProofStatusPending:
+		return "pending"
+	case ProofStatusReady:
+		return "ready"
+	case ProofStatusFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// AsyncStateProver is an optional interface of Prover for provers that take a long time to generate a proof (e.g. zk provers).
+// If a Prover implements it, the strategy requests proofs of packet and acknowledgement commitments instead of calling `ProveState`,
+// and relays the corresponding messages in a later relay cycle once the proofs are ready, while other packets are relayed in the meantime.
+// A proof is requested at the height of the query context, and the header at that height is submitted to the counterparty chain
+// by UpdateClient in the same relay cycle, so the retrieved proof must be verifiable at that height.
+type AsyncStateProver interface {
+	// RequestStateProof requests the generation of a proof of an IBC state specified by `path` and `value` and returns the ID of the request.
+	// It must not block until the proof is generated.
+	RequestStateProof(ctx QueryContext, path string, value []byte) (ProofRequestID, error)
+
+	// GetStateProofStatus returns the status of the request specified by `id`
+	GetStateProofStatus(id ProofRequestID) (ProofStatus, error)
+
+	// GetStateProof returns the proof generated for the request specified by `id`.
+	// It is called only after the status of the request becomes ProofStatusReady.
+	GetStateProof(id ProofRequestID) (proof []byte, proofHeight clienttypes.Height, err error)
+}
@@ -21,6 +21,9 @@ func QueryClientStatePair(
 	var eg = new(errgroup.Group)
 	eg.Go(func() error {
 		var err error
+		if prove {
+			srcCtx = WithoutClientStateCache(srcCtx)
+		}
 		srcCsRes, err = src.QueryClientState(srcCtx)
 		if err != nil {
 			return err
@@ -38,6 +41,9 @@ func QueryClientStatePair(
 	})
 	eg.Go(func() error {
 		var err error
+		if prove {
+			dstCtx = WithoutClientStateCache(dstCtx)
+		}
 		dstCsRes, err = dst.QueryClientState(dstCtx)
 		if err != nil {
 			return err
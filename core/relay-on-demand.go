@@ -0,0 +1,175 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// priorityPacketTTL is the time a relay-on-demand request is kept until its packet is found unrelayed.
+	// Requests for packets already relayed (e.g. by another relayer) are dropped after the TTL.
+	priorityPacketTTL = 10 * time.Minute
+	// maxPriorityPackets bounds the number of pending relay-on-demand requests of a path
+	maxPriorityPackets = 1000
+)
+
+var (
+	// ErrRelayRequestNotFound is returned when a relay-on-demand request is for a path or a channel not relayed by the service
+	ErrRelayRequestNotFound = errors.New("relay request not found")
+	// ErrRelayRequestRejected is returned when the service can't accept a relay-on-demand request in its current state
+	ErrRelayRequestRejected = errors.New("relay request rejected")
+)
+
+// PriorityPacket is a packet requested to be relayed on demand (e.g. by an application backend right after its user's transaction)
+type PriorityPacket struct {
+	// ChainID is the ID of the chain from which the packet is sent
+	ChainID     string    `json:"chain_id"`
+	PortID      string    `json:"port_id"`
+	ChannelID   string    `json:"channel_id"`
+	Sequence    uint64    `json:"sequence"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// priorityPackets keeps track of the relay-on-demand requests of a path.
+// A request is removed when its packet is relayed in a relay cycle or when the TTL elapses.
+type priorityPackets struct {
+	mu       sync.Mutex
+	requests map[heldPacketKey]*PriorityPacket
+}
+
+func newPriorityPackets() *priorityPackets {
+	return &priorityPackets{
+		requests: make(map[heldPacketKey]*PriorityPacket),
+	}
+}
+
+func (q *priorityPackets) add(p *PriorityPacket) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(p.RequestedAt)
+	key := heldPacketKey{chainID: p.ChainID, portID: p.PortID, channelID: p.ChannelID, sequence: p.Sequence}
+	if _, ok := q.requests[key]; !ok && len(q.requests) >= maxPriorityPackets {
+		return fmt.Errorf("too many pending relay requests: %d", len(q.requests))
+	}
+	q.requests[key] = p
+	return nil
+}
+
+func (q *priorityPackets) list() []*PriorityPacket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(time.Now())
+	ret := make([]*PriorityPacket, 0, len(q.requests))
+	for _, p := range q.requests {
+		ret = append(ret, p)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].RequestedAt.Before(ret[j].RequestedAt) })
+	return ret
+}
+
+// take removes the requests for the packets sent from `chainID` and returns true if any of them is found
+func (q *priorityPackets) take(chainID string, packets PacketInfoList) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	found := false
+	for _, p := range packets {
+		key := heldPacketKey{chainID: chainID, portID: p.SourcePort, channelID: p.SourceChannel, sequence: p.Sequence}
+		if _, ok := q.requests[key]; ok {
+			delete(q.requests, key)
+			found = true
+		}
+	}
+	return found
+}
+
+// requested returns true if the packet sent from `chainID` is requested to be relayed
+func (q *priorityPackets) requested(chainID string, p *PacketInfo) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.requests[heldPacketKey{chainID: chainID, portID: p.SourcePort, channelID: p.SourceChannel, sequence: p.Sequence}]
+	return ok
+}
+
+func (q *priorityPackets) expire(now time.Time) {
+	for key, p := range q.requests {
+		if now.Sub(p.RequestedAt) > priorityPacketTTL {
+			delete(q.requests, key)
+		}
+	}
+}
+
+// RelayPacket requests the packet `sequence` sent on `channelID` to be relayed as soon as possible.
+// The service starts the next relay cycle immediately and relays the packet without waiting for the relay optimization
+// (the interval and the count of packets to batch), while the value limits, the address screening and the challenge windows still apply.
+// `pathName` must be the path served by the service, and `chainID` may be empty unless both ends of a relayed channel have `channelID`.
+func (srv *RelayService) RelayPacket(pathName, chainID, channelID string, sequence uint64) (*PriorityPacket, error) {
+	if pathName != "" && srv.pathName != "" && pathName != srv.pathName {
+		return nil, fmt.Errorf("%w: the service relays the path %s, not %s", ErrRelayRequestNotFound, srv.pathName, pathName)
+	}
+	if sequence == 0 {
+		return nil, fmt.Errorf("sequence must be positive")
+	}
+	if srv.observing() {
+		return nil, fmt.Errorf("%w: the service doesn't relay packets in the observe mode or as a standby instance", ErrRelayRequestRejected)
+	}
+	if state, err := srv.PauseState(); err != nil {
+		return nil, err
+	} else if state.Paused {
+		return nil, fmt.Errorf("%w: relaying is paused: %s", ErrRelayRequestRejected, state.Reason)
+	}
+
+	var found []*PriorityPacket
+	srv.channelsMu.RLock()
+	for _, ch := range srv.channels {
+		for _, end := range []*PathEnd{ch.srcEnd, ch.dstEnd} {
+			if end.ChannelID == channelID && (chainID == "" || end.ChainID == chainID) {
+				found = append(found, &PriorityPacket{ChainID: end.ChainID, PortID: end.PortID, ChannelID: end.ChannelID, Sequence: sequence})
+			}
+		}
+	}
+	srv.channelsMu.RUnlock()
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: channel %s is not relayed by the service", ErrRelayRequestNotFound, channelID)
+	case 1:
+	default:
+		return nil, fmt.Errorf("channel %s exists on both chains: chain_id must be specified", channelID)
+	}
+
+	p := found[0]
+	p.RequestedAt = time.Now()
+	if err := srv.priority.add(p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRelayRequestRejected, err)
+	}
+	select {
+	case srv.wake <- struct{}{}:
+	default:
+	}
+	GetChannelPairLogger(srv.src, srv.dst).Info("requested to relay a packet on demand",
+		"chain_id", p.ChainID,
+		"port_id", p.PortID,
+		"channel_id", p.ChannelID,
+		"sequence", p.Sequence,
+	)
+	return p, nil
+}
+
+// PriorityPackets returns the pending relay-on-demand requests
+func (srv *RelayService) PriorityPackets() []*PriorityPacket {
+	return srv.priority.list()
+}
+
+// prioritizePackets returns whether the relay to src and to dst must be executed in the relay cycle
+// because the unrelayed packets contain the packets requested by RelayPacket.
+func (srv *RelayService) prioritizePackets(pseqs *RelayPackets) (relaySrc, relayDst bool) {
+	// the packets sent from src are received on dst and vice versa
+	relayDst = srv.priority.take(srv.src.ChainID(), pseqs.Src)
+	relaySrc = srv.priority.take(srv.dst.ChainID(), pseqs.Dst)
+	if relaySrc || relayDst {
+		GetChannelPairLogger(srv.src, srv.dst).Info("relaying the packets requested on demand", "relay_src", relaySrc, "relay_dst", relayDst)
+	}
+	return relaySrc, relayDst
+}
@@ -0,0 +1,71 @@
+package core_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+func TestRelayPacketAPI(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	chains := newMockChains(t, [2]*core.PathEnd{
+		{ChainID: "ibc0", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-1", Order: "unordered", Version: "ics20-1"},
+	}, nil)
+	srv := core.NewRelayService(core.NewNaiveStrategy(false, false), chains[0], chains[1], nil, 0, 0, 0, 0, 0)
+	srv.SetPathName("path")
+	admin := core.NewAdminServer(srv, core.AdminServerOptions{AuthToken: "token"})
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/relay-packet", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		admin.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	cases := map[string]struct {
+		body   string
+		status int
+	}{
+		"queued":          {`{"path":"path","channel_id":"channel-1","sequence":3}`, http.StatusAccepted},
+		"wrong path":      {`{"path":"other","channel_id":"channel-1","sequence":3}`, http.StatusNotFound},
+		"unknown channel": {`{"path":"path","channel_id":"channel-9","sequence":3}`, http.StatusNotFound},
+		"wrong chain":     {`{"path":"path","chain_id":"ibc0","channel_id":"channel-1","sequence":3}`, http.StatusNotFound},
+		"no sequence":     {`{"path":"path","channel_id":"channel-1"}`, http.StatusBadRequest},
+	}
+	for name, c := range cases {
+		if status := post(c.body); status != c.status {
+			t.Errorf("%s: unexpected status: actual=%d, expected=%d", name, status, c.status)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/priority-packets", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	admin.ServeHTTP(rec, req)
+	var res struct {
+		PriorityPackets []*core.PriorityPacket `json:"priority_packets"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.PriorityPackets) != 1 {
+		t.Fatalf("unexpected priority packets: %s", rec.Body.String())
+	}
+	if p := res.PriorityPackets[0]; p.ChainID != "ibc1" || p.PortID != "transfer" || p.Sequence != 3 {
+		t.Errorf("unexpected priority packet: %+v", p)
+	}
+
+	// requests are rejected while the service doesn't relay packets
+	srv.SetObserveMode(true)
+	if status := post(`{"path":"path","channel_id":"channel-0","sequence":1}`); status != http.StatusConflict {
+		t.Errorf("unexpected status in the observe mode: %d", status)
+	}
+}
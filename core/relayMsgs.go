@@ -1,24 +1,34 @@
 package core
 
 import (
+	"errors"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/gogoproto/proto"
 )
 
 // RelayMsgs contains the msgs that need to be sent to both a src and dst chain
-// after a given relay round. MaxTxSize and MaxMsgLength are ignored if they are
+// after a given relay round. MaxTxSize, MaxMsgLength and MaxUpdateClientMsgs are ignored if they are
 // set to zero.
 type RelayMsgs struct {
-	Src          []sdk.Msg `json:"src"`
-	Dst          []sdk.Msg `json:"dst"`
-	MaxTxSize    uint64    `json:"max_tx_size"`    // maximum permitted size of the msgs in a bundled relay transaction
-	MaxMsgLength uint64    `json:"max_msg_length"` // maximum amount of messages in a bundled relay transaction
+	Src                 []sdk.Msg `json:"src"`
+	Dst                 []sdk.Msg `json:"dst"`
+	MaxTxSize           uint64    `json:"max_tx_size"`            // maximum permitted size of the msgs in a bundled relay transaction
+	MaxMsgLength        uint64    `json:"max_msg_length"`         // maximum amount of messages in a bundled relay transaction
+	MaxUpdateClientMsgs uint64    `json:"max_update_client_msgs"` // maximum amount of MsgUpdateClient in a bundled relay transaction
 
 	Last      bool `json:"last"`
 	Succeeded bool `json:"success"`
 
 	SrcMsgIDs []MsgID `json:"src_msg_ids"`
 	DstMsgIDs []MsgID `json:"dst_msg_ids"`
+
+	// SrcMsgResults and DstMsgResults are the results of the msgs returned by SendMsgs, aligned with Src and Dst.
+	// The results of the msgs not sent are nil.
+	SrcMsgResults []MsgResult `json:"-"`
+	DstMsgResults []MsgResult `json:"-"`
+
+	// Errors are the errors of the transactions failed in Send
+	Errors []error `json:"-"`
 }
 
 // NewRelayMsgs returns an initialized version of relay messages
@@ -43,9 +53,51 @@ func (r *RelayMsgs) Success() bool {
 	return r.Succeeded
 }
 
+// Err returns the errors of the transactions failed in Send joined into one, or nil if none failed
+func (r *RelayMsgs) Err() error {
+	return errors.Join(r.Errors...)
+}
+
 func (r *RelayMsgs) IsMaxTx(msgLen, txSize uint64) bool {
-	return (r.MaxMsgLength != 0 && msgLen > r.MaxMsgLength) ||
-		(r.MaxTxSize != 0 && txSize > r.MaxTxSize)
+	return TxLimits{MaxTxBytes: r.MaxTxSize, MaxMsgs: r.MaxMsgLength}.exceeded(msgLen, txSize)
+}
+
+// txLimits returns the limits of a tx sent to the chain, which are the stricter of the limits of the msgs and the chain
+func (r *RelayMsgs) txLimits(chain Chain) TxLimits {
+	limits := TxLimits{MaxTxBytes: r.MaxTxSize, MaxMsgs: r.MaxMsgLength}
+	if p, ok := unwrapChain(chain).(TxLimitsProvider); ok {
+		cl := p.TxLimits()
+		limits.MaxTxBytes = minLimit(limits.MaxTxBytes, cl.MaxTxBytes)
+		limits.MaxMsgs = minLimit(limits.MaxMsgs, cl.MaxMsgs)
+	}
+	return limits
+}
+
+// minLimit returns the smaller of the limits, where zero means no limit
+func minLimit(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// exceeded returns true if a tx of `msgLen` msgs whose total size is `txSize` exceeds the limits
+func (l TxLimits) exceeded(msgLen, txSize uint64) bool {
+	return (l.MaxMsgs != 0 && msgLen > l.MaxMsgs) ||
+		(l.MaxTxBytes != 0 && txSize > l.MaxTxBytes)
+}
+
+// isMaxUpdateClients returns true if the number of MsgUpdateClient exceeds the limit
+func (r *RelayMsgs) isMaxUpdateClients(updateLen uint64) bool {
+	return r.MaxUpdateClientMsgs != 0 && updateLen > r.MaxUpdateClientMsgs
+}
+
+// countUpdateClient returns 1 if the msg is MsgUpdateClient and 0 otherwise
+func countUpdateClient(msg sdk.Msg) uint64 {
+	if isUpdateClientMsg(msg) {
+		return 1
+	}
+	return 0
 }
 
 // Send sends the messages with appropriate output
@@ -54,18 +106,32 @@ func (r *RelayMsgs) Send(src, dst Chain) {
 	logger := GetChannelPairLogger(src, dst)
 	//nolint:prealloc // can not be pre allocated
 	var (
-		msgLen, txSize uint64
-		msgs           []sdk.Msg
+		msgLen, txSize, updateLen uint64
+		msgs                      []sdk.Msg
 	)
 
 	r.Succeeded = true
 
-	srcMsgIDs := make([]MsgID, len(r.Src))
-	dstMsgIDs := make([]MsgID, len(r.Dst))
+	// msgs violating the ordering invariants are never sent
+	srcMsgs, dstMsgs := r.Src, r.Dst
+	if err := CheckMsgOrder(srcMsgs); err != nil {
+		logger.Error("refused to send msgs violating the msg order", err, "chain_id", src.ChainID())
+		r.Succeeded = false
+		srcMsgs = nil
+	}
+	if err := CheckMsgOrder(dstMsgs); err != nil {
+		logger.Error("refused to send msgs violating the msg order", err, "chain_id", dst.ChainID())
+		r.Succeeded = false
+		dstMsgs = nil
+	}
+
+	srcLimits, dstLimits := r.txLimits(src), r.txLimits(dst)
+	srcResults := make([]MsgResult, len(r.Src))
+	dstResults := make([]MsgResult, len(r.Dst))
 	// submit batches of relay transactions
 	maxTxCount := 0
 
-	for _, msg := range r.Src {
+	for _, msg := range srcMsgs {
 		bz, err := proto.Marshal(msg)
 		if err != nil {
 			logger.Error("failed to marshal msg", err)
@@ -74,22 +140,22 @@ func (r *RelayMsgs) Send(src, dst Chain) {
 
 		msgLen++
 		txSize += uint64(len(bz))
+		updateLen += countUpdateClient(msg)
 
-		if r.IsMaxTx(msgLen, txSize) {
+		if len(msgs) > 0 && (srcLimits.exceeded(msgLen, txSize) || r.isMaxUpdateClients(updateLen)) {
 			// Submit the transactions to src chain and update its status
-			msgIDs, err := src.SendMsgs(msgs)
+			results, err := sendMsgsRecovering(src, msgs)
 			if err != nil {
 				logger.Error("failed to send msgs", err, "msgs", msgs)
+				r.Errors = append(r.Errors, err)
 			}
 			r.Succeeded = r.Succeeded && (err == nil)
-			if err == nil {
-				for i := range msgs {
-					srcMsgIDs[i+maxTxCount] = msgIDs[i]
-				}
+			for i := range results {
+				srcResults[i+maxTxCount] = results[i]
 			}
 			// clear the current batch and reset variables
 			maxTxCount += len(msgs)
-			msgLen, txSize = 1, uint64(len(bz))
+			msgLen, txSize, updateLen = 1, uint64(len(bz)), countUpdateClient(msg)
 			msgs = []sdk.Msg{}
 		}
 		msgs = append(msgs, msg)
@@ -97,24 +163,23 @@ func (r *RelayMsgs) Send(src, dst Chain) {
 
 	// submit leftover msgs
 	if len(msgs) > 0 {
-		msgIDs, err := src.SendMsgs(msgs)
+		results, err := sendMsgsRecovering(src, msgs)
 		if err != nil {
 			logger.Error("failed to send msgs", err, "msgs", msgs)
+			r.Errors = append(r.Errors, err)
 		}
 		r.Succeeded = r.Succeeded && (err == nil)
-		if err == nil {
-			for i := range msgs {
-				srcMsgIDs[i+maxTxCount] = msgIDs[i]
-			}
+		for i := range results {
+			srcResults[i+maxTxCount] = results[i]
 		}
 	}
 
 	// reset variables
-	msgLen, txSize = 0, 0
+	msgLen, txSize, updateLen = 0, 0, 0
 	msgs = []sdk.Msg{}
 	maxTxCount = 0
 
-	for _, msg := range r.Dst {
+	for _, msg := range dstMsgs {
 		bz, err := proto.Marshal(msg)
 		if err != nil {
 			logger.Error("failed to marshal msg", err)
@@ -123,22 +188,22 @@ func (r *RelayMsgs) Send(src, dst Chain) {
 
 		msgLen++
 		txSize += uint64(len(bz))
+		updateLen += countUpdateClient(msg)
 
-		if r.IsMaxTx(msgLen, txSize) {
+		if len(msgs) > 0 && (dstLimits.exceeded(msgLen, txSize) || r.isMaxUpdateClients(updateLen)) {
 			// Submit the transaction to dst chain and update its status
-			msgIDs, err := dst.SendMsgs(msgs)
+			results, err := sendMsgsRecovering(dst, msgs)
 			if err != nil {
 				logger.Error("failed to send msgs", err, "msgs", msgs)
+				r.Errors = append(r.Errors, err)
 			}
 			r.Succeeded = r.Succeeded && (err == nil)
-			if err == nil {
-				for i := range msgs {
-					dstMsgIDs[i+maxTxCount] = msgIDs[i]
-				}
+			for i := range results {
+				dstResults[i+maxTxCount] = results[i]
 			}
 			// clear the current batch and reset variables
 			maxTxCount += len(msgs)
-			msgLen, txSize = 1, uint64(len(bz))
+			msgLen, txSize, updateLen = 1, uint64(len(bz)), countUpdateClient(msg)
 			msgs = []sdk.Msg{}
 		}
 		msgs = append(msgs, msg)
@@ -146,19 +211,20 @@ func (r *RelayMsgs) Send(src, dst Chain) {
 
 	// submit leftover msgs
 	if len(msgs) > 0 {
-		msgIDs, err := dst.SendMsgs(msgs)
+		results, err := sendMsgsRecovering(dst, msgs)
 		if err != nil {
 			logger.Error("failed to send msgs", err, "msgs", msgs)
+			r.Errors = append(r.Errors, err)
 		}
 		r.Succeeded = r.Succeeded && (err == nil)
-		if err == nil {
-			for i := range msgs {
-				dstMsgIDs[i+maxTxCount] = msgIDs[i]
-			}
+		for i := range results {
+			dstResults[i+maxTxCount] = results[i]
 		}
 	}
-	r.SrcMsgIDs = srcMsgIDs
-	r.DstMsgIDs = dstMsgIDs
+	r.SrcMsgResults, r.DstMsgResults = srcResults, dstResults
+	r.SrcMsgIDs, r.DstMsgIDs = MsgIDsOf(srcResults), MsgIDsOf(dstResults)
+
+	r.confirmSentMsgs(src, dst)
 }
 
 // Merge merges the argument into the receiver
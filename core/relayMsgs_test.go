@@ -0,0 +1,65 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// limitedChain is a batchChain with tx limits
+type limitedChain struct {
+	batchChain
+	limits core.TxLimits
+}
+
+func (c *limitedChain) TxLimits() core.TxLimits { return c.limits }
+
+func TestSendHonorsChainTxLimits(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	var msgs []sdk.Msg
+	for seq := uint64(1); seq <= 5; seq++ {
+		msgs = append(msgs, recvMsg("channel-0", seq))
+	}
+
+	cases := map[string]struct {
+		strategyMaxMsgs uint64
+		chainLimits     core.TxLimits
+		expectedTxs     int
+	}{
+		"no limits":               {0, core.TxLimits{}, 1},
+		"chain max msgs":          {0, core.TxLimits{MaxMsgs: 2}, 3},
+		"stricter strategy limit": {1, core.TxLimits{MaxMsgs: 2}, 5},
+		"stricter chain limit":    {4, core.TxLimits{MaxMsgs: 2}, 3},
+		// every msg is larger than the limit, so each msg is sent alone
+		"chain max tx bytes": {0, core.TxLimits{MaxTxBytes: 1}, 5},
+	}
+	for name, c := range cases {
+		// the limits are honored also for the chain wrapped in a ProvableChain as the relay service sends the msgs
+		for _, wrapped := range []bool{false, true} {
+			src, dst := &batchChain{}, &limitedChain{limits: c.chainLimits}
+			var srcChain, dstChain core.Chain = src, dst
+			if wrapped {
+				srcChain, dstChain = core.NewProvableChain(src, nil), core.NewProvableChain(dst, nil)
+			}
+			rm := core.NewRelayMsgs()
+			rm.Dst = msgs
+			rm.MaxMsgLength = c.strategyMaxMsgs
+			rm.Send(srcChain, dstChain)
+			if !rm.Success() {
+				t.Errorf("%s (wrapped=%v): Send failed", name, wrapped)
+			}
+			if len(dst.txs) != c.expectedTxs {
+				t.Errorf("%s (wrapped=%v): unexpected number of txs: %d != %d", name, wrapped, len(dst.txs), c.expectedTxs)
+			}
+			for _, tx := range dst.txs {
+				if len(tx) == 0 {
+					t.Errorf("%s (wrapped=%v): an empty tx is sent", name, wrapped)
+				}
+			}
+		}
+	}
+}
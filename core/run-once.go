@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RunOnce performs a single relay cycle of the service and returns, for the deployments where the relayer is run
+// periodically by a scheduler (e.g. cron or serverless functions) instead of as a daemon.
+// Unlike `tx relay`, the cycle is the same as a cycle of Start: it updates the clients close to expiry, relays the packets
+// and the acknowledgements on all the channels in both directions, and records the status, the journal and the metrics counters.
+// The relay optimization is bypassed so that all the pending packets are relayed.
+// It returns an error if the cycle fails even after the retries or any transaction of the cycle fails.
+func (srv *RelayService) RunOnce(ctx context.Context) error {
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	if srv.identity != nil {
+		logger.Info("relayer identity", srv.identity.LogAttrs()...)
+	}
+	if l := srv.leadership; l != nil {
+		leader, err := l.elector.TryAcquire(ctx)
+		if err != nil {
+			logger.Warn("failed to acquire the leadership", "error", err)
+		}
+		l.leader.Store(leader)
+		if !leader {
+			logger.Info("another instance is the leader; the relay cycle runs in the observe mode")
+		}
+		defer func() {
+			if !leader {
+				return
+			}
+			l.leader.Store(false)
+			if err := l.elector.Release(context.Background()); err != nil {
+				logger.Error("failed to release the leadership", err)
+			}
+		}()
+	}
+
+	srv.relayAll = true
+	defer func() { srv.relayAll = false }()
+	err := srv.serveWithRetry(ctx)
+	srv.saveCounterSnapshot()
+	if err != nil {
+		return fmt.Errorf("the relay cycle failed: %w", err)
+	}
+	if len(srv.sendErrors) > 0 {
+		return fmt.Errorf("%d transaction(s) failed in the relay cycle: %w", len(srv.sendErrors), errors.Join(srv.sendErrors...))
+	}
+	logger.Info("completed the relay cycle")
+	return nil
+}
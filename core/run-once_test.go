@@ -0,0 +1,43 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestRunOnce(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, sh := newScriptedChains(t)
+	st := &scriptedStrategy{pending: []uint64{1}, own: map[uint64]bool{1: true}}
+	// the relay optimization never relays a single fresh packet
+	srv := core.NewRelayService(st, chains[0], chains[1], sh, 0, time.Hour, 100, time.Hour, 100)
+
+	if err := srv.Serve(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if st.executeDst {
+		t.Fatal("the relay optimization is bypassed in a normal relay cycle")
+	}
+	if err := srv.RunOnce(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if !st.executeDst {
+		t.Error("the pending packet is not relayed by RunOnce")
+	}
+
+	st.sendErr = errors.New("out of gas")
+	if err := srv.RunOnce(context.TODO()); !errors.Is(err, st.sendErr) {
+		t.Errorf("the failed transaction is not reported: %v", err)
+	}
+}
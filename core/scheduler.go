@@ -0,0 +1,148 @@
+package core
+
+import (
+	"sort"
+	"sync"
+)
+
+// RelayScheduler selects the packets to be relayed in a relay cycle.
+// It picks packets from the channels in a round-robin manner so that a channel with a huge backlog doesn't starve the other channels,
+// and limits the number of packets relayed per channel in a relay cycle.
+// A RelayService owns one scheduler shared by all the channels it relays, and calls NextCycle once per relay cycle.
+type RelayScheduler struct {
+	// maximum number of packets relayed per channel in a relay cycle (0 means unlimited)
+	MaxPacketsPerChannel uint64
+	// maximum number of packets relayed in a relay cycle across all the channels (0 means unlimited).
+	// The limit applies to each direction of the packets and of the acknowledgements separately.
+	MaxPacketsPerCycle uint64
+
+	mu     sync.Mutex
+	offset int // rotates the channel to start picking from across relay cycles
+}
+
+// NewRelayScheduler returns a new instance of RelayScheduler
+func NewRelayScheduler(maxPacketsPerChannel, maxPacketsPerCycle uint64) *RelayScheduler {
+	return &RelayScheduler{
+		MaxPacketsPerChannel: maxPacketsPerChannel,
+		MaxPacketsPerCycle:   maxPacketsPerCycle,
+	}
+}
+
+// Enabled returns true if the scheduler limits the packets relayed in a relay cycle
+func (s *RelayScheduler) Enabled() bool {
+	return s != nil && (s.MaxPacketsPerChannel > 0 || s.MaxPacketsPerCycle > 0)
+}
+
+// NextCycle rotates the channel to start picking from in the next relay cycle
+func (s *RelayScheduler) NextCycle() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset++
+}
+
+// Schedule returns the packets to be relayed in this relay cycle.
+// The order of packets within each channel is preserved, so it is safe for ordered channels.
+func (s *RelayScheduler) Schedule(packets PacketInfoList) PacketInfoList {
+	if !s.Enabled() {
+		return packets
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// group packets by the channel they are sent from
+	queues := make(map[string]PacketInfoList)
+	for _, p := range packets {
+		key := p.SourcePort + "/" + p.SourceChannel
+		queues[key] = append(queues[key], p)
+	}
+	if len(queues) == 0 {
+		return packets
+	}
+	keys := make([]string, 0, len(queues))
+	for key := range queues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// the channel to start from is rotated across relay cycles
+	start := s.offset % len(keys)
+
+	var (
+		scheduled PacketInfoList
+		picked    = make(map[string]uint64)
+	)
+	for {
+		progressed := false
+		for i := range keys {
+			key := keys[(start+i)%len(keys)]
+			if len(queues[key]) == 0 {
+				continue
+			}
+			if s.MaxPacketsPerChannel > 0 && picked[key] >= s.MaxPacketsPerChannel {
+				continue
+			}
+			if s.MaxPacketsPerCycle > 0 && uint64(len(scheduled)) >= s.MaxPacketsPerCycle {
+				return scheduled
+			}
+			scheduled = append(scheduled, queues[key][0])
+			queues[key] = queues[key][1:]
+			picked[key]++
+			progressed = true
+		}
+		if !progressed {
+			return scheduled
+		}
+	}
+}
+
+// SetScheduler sets the scheduler shared by all the channels relayed by the service
+func (srv *RelayService) SetScheduler(scheduler *RelayScheduler) {
+	srv.scheduler = scheduler
+}
+
+// scheduleChannels selects the packets and acknowledgements relayed in this relay cycle across all the channels,
+// and removes the others from the scans so that they are left to the next relay cycles
+func (srv *RelayService) scheduleChannels(scans []*channelScan) {
+	if !srv.scheduler.Enabled() {
+		return
+	}
+	lists := []struct {
+		seqs    func(*channelScan) *PacketInfoList
+		execute func(*channelScan) bool
+	}{
+		{func(s *channelScan) *PacketInfoList { return &s.pseqs.Src }, func(s *channelScan) bool { return s.relayDst }},
+		{func(s *channelScan) *PacketInfoList { return &s.pseqs.Dst }, func(s *channelScan) bool { return s.relaySrc }},
+		{func(s *channelScan) *PacketInfoList { return &s.aseqs.Src }, func(s *channelScan) bool { return s.ackDst }},
+		{func(s *channelScan) *PacketInfoList { return &s.aseqs.Dst }, func(s *channelScan) bool { return s.ackSrc }},
+	}
+	for _, l := range lists {
+		// the packets of the channels not relayed in this relay cycle don't consume the limits
+		var all PacketInfoList
+		for _, scan := range scans {
+			if l.execute(scan) {
+				all = append(all, *l.seqs(scan)...)
+			}
+		}
+		scheduled := make(map[*PacketInfo]bool)
+		for _, p := range srv.scheduler.Schedule(all) {
+			scheduled[p] = true
+		}
+		for _, scan := range scans {
+			if !l.execute(scan) {
+				continue
+			}
+			var kept PacketInfoList
+			for _, p := range *l.seqs(scan) {
+				if scheduled[p] {
+					kept = append(kept, p)
+				}
+			}
+			*l.seqs(scan) = kept
+		}
+	}
+	srv.scheduler.NextCycle()
+}
@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func makeChannelPacketInfoList(channelID string, seqs ...uint64) core.PacketInfoList {
+	var packets core.PacketInfoList
+	for _, seq := range seqs {
+		packets = append(packets, &core.PacketInfo{Packet: chantypes.Packet{Sequence: seq, SourcePort: "transfer", SourceChannel: channelID}})
+	}
+	return packets
+}
+
+func TestRelaySchedulerUnlimited(t *testing.T) {
+	packets := makePacketInfoList(1, 2, 3)
+	var s *core.RelayScheduler
+	if actual := s.Schedule(packets); !slices.Equal(actual.ExtractSequenceList(), packets.ExtractSequenceList()) {
+		t.Errorf("Schedule returns an unexpected result: actual=%v, expected=%v", actual, packets)
+	}
+	s = core.NewRelayScheduler(0, 0)
+	if actual := s.Schedule(packets); !slices.Equal(actual.ExtractSequenceList(), packets.ExtractSequenceList()) {
+		t.Errorf("Schedule returns an unexpected result: actual=%v, expected=%v", actual, packets)
+	}
+}
+
+func TestRelaySchedulerFairness(t *testing.T) {
+	var packets core.PacketInfoList
+	packets = append(packets, makeChannelPacketInfoList("channel-0", 1, 2, 3, 4, 5, 6)...)
+	packets = append(packets, makeChannelPacketInfoList("channel-1", 11, 12)...)
+
+	s := core.NewRelayScheduler(3, 4)
+
+	// the first cycle starts from channel-0
+	actual := s.Schedule(packets).ExtractSequenceList()
+	if expected := []uint64{1, 11, 2, 12}; !slices.Equal(actual, expected) {
+		t.Errorf("Schedule returns an unexpected result: actual=%v, expected=%v", actual, expected)
+	}
+
+	// the packets scheduled in the same cycle start from the same channel
+	actual = s.Schedule(packets).ExtractSequenceList()
+	if expected := []uint64{1, 11, 2, 12}; !slices.Equal(actual, expected) {
+		t.Errorf("Schedule returns an unexpected result: actual=%v, expected=%v", actual, expected)
+	}
+
+	// the second cycle starts from channel-1
+	s.NextCycle()
+	actual = s.Schedule(packets).ExtractSequenceList()
+	if expected := []uint64{11, 1, 12, 2}; !slices.Equal(actual, expected) {
+		t.Errorf("Schedule returns an unexpected result: actual=%v, expected=%v", actual, expected)
+	}
+
+	// the per-channel limit is applied
+	s = core.NewRelayScheduler(3, 0)
+	actual = s.Schedule(packets).ExtractSequenceList()
+	if expected := []uint64{1, 11, 2, 12, 3}; !slices.Equal(actual, expected) {
+		t.Errorf("Schedule returns an unexpected result: actual=%v, expected=%v", actual, expected)
+	}
+}
+
+func TestRelayServiceScheduler(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, sh := newScriptedChains(t)
+	st0 := &scriptedStrategy{pending: []uint64{1, 2, 3}}
+	st1 := &scriptedStrategy{pending: []uint64{11, 12}}
+	srv := core.NewRelayService(st0, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	srv.AddChannel(
+		&core.PathEnd{ChainID: "ibc0", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-1", Order: "unordered", Version: "ics20-1"},
+		&core.PathEnd{ChainID: "ibc1", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-1", Order: "unordered", Version: "ics20-1"},
+		st1,
+	)
+	// the limit per cycle is shared by the channels
+	srv.SetScheduler(core.NewRelayScheduler(0, 1))
+
+	// the channel to start from rotates once per relay cycle
+	for i, expected := range [][2][]uint64{
+		{{1}, nil},
+		{nil, {11}},
+		{{1}, nil},
+	} {
+		if err := srv.RunOnce(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(st0.relayed, expected[0]) || !slices.Equal(st1.relayed, expected[1]) {
+			t.Errorf("cycle %d: unexpected packets relayed: channel-0=%v, channel-1=%v, expected=%v", i, st0.relayed, st1.relayed, expected)
+		}
+	}
+
+	// the per-channel limit applies to each channel
+	srv.SetScheduler(core.NewRelayScheduler(2, 0))
+	if err := srv.RunOnce(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(st0.relayed, []uint64{1, 2}) || !slices.Equal(st1.relayed, []uint64{11, 12}) {
+		t.Errorf("unexpected packets relayed: channel-0=%v, channel-1=%v", st0.relayed, st1.relayed)
+	}
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScreeningOutcome is the result of screening an address
+type ScreeningOutcome string
+
+const (
+	// ScreeningAllow relays the packet
+	ScreeningAllow ScreeningOutcome = "allow"
+	// ScreeningDeny never relays the packet
+	ScreeningDeny ScreeningOutcome = "deny"
+	// ScreeningQueue holds the packet until it is released via the admin API
+	ScreeningQueue ScreeningOutcome = "queue"
+)
+
+func (o ScreeningOutcome) validate() error {
+	switch o {
+	case ScreeningAllow, ScreeningDeny, ScreeningQueue:
+		return nil
+	default:
+		return fmt.Errorf("invalid screening outcome: %s", o)
+	}
+}
+
+// AddressScreener is an external screening service consulted with the sender and receiver addresses of packets before relaying them.
+// The addresses are taken from the "sender" and "receiver" attributes of the packet data decoded by the packet decoder registry.
+// An implementation with other protocols (e.g. gRPC) can be set to RelayService by SetAddressScreener.
+type AddressScreener interface {
+	// Screen returns the outcome for the address on the chain
+	Screen(ctx context.Context, chainID, address string) (ScreeningOutcome, error)
+}
+
+// ScreeningCfg configures the address screening of a path with an HTTP screening service
+type ScreeningCfg struct {
+	// URL is the endpoint of the screening service.
+	// It receives a POST request with {"chain_id": "...", "address": "..."} and responds {"outcome": "allow" | "deny" | "queue"}.
+	URL string `json:"url" yaml:"url"`
+
+	// Timeout is the timeout of a request to the screening service (default: "10s")
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// CacheTTL is the time to cache the outcome for an address (default: "10m")
+	CacheTTL string `json:"cache-ttl,omitempty" yaml:"cache-ttl,omitempty"`
+
+	// OnError is the outcome applied when the screening service fails (default: "queue").
+	// The packets held because of a failure are screened again in the following relay cycles.
+	OnError ScreeningOutcome `json:"on-error,omitempty" yaml:"on-error,omitempty"`
+
+	// OnUndecodable is the outcome applied to the packets whose data can't be decoded to find the addresses (default: "queue")
+	OnUndecodable ScreeningOutcome `json:"on-undecodable,omitempty" yaml:"on-undecodable,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *ScreeningCfg) Validate() error {
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return fmt.Errorf("screening: invalid url: %s", cfg.URL)
+	}
+	for name, v := range map[string]string{"timeout": cfg.Timeout, "cache-ttl": cfg.CacheTTL} {
+		if v == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("screening: invalid %s: %w", name, err)
+		} else if d < 0 {
+			return fmt.Errorf("screening: %s must not be negative: %v", name, d)
+		}
+	}
+	if cfg.OnError != "" {
+		if err := cfg.OnError.validate(); err != nil {
+			return fmt.Errorf("screening: on-error: %w", err)
+		}
+	}
+	if cfg.OnUndecodable != "" {
+		if err := cfg.OnUndecodable.validate(); err != nil {
+			return fmt.Errorf("screening: on-undecodable: %w", err)
+		}
+	}
+	return nil
+}
+
+// UndecodableOutcome returns the outcome applied to the packets whose data can't be decoded
+func (cfg *ScreeningCfg) UndecodableOutcome() ScreeningOutcome {
+	if cfg.OnUndecodable == "" {
+		return ScreeningQueue
+	}
+	return cfg.OnUndecodable
+}
+
+// NewScreener returns the cached HTTP screener configured by `cfg`
+func (cfg *ScreeningCfg) NewScreener() AddressScreener {
+	timeout, ttl := 10*time.Second, 10*time.Minute
+	if cfg.Timeout != "" {
+		timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+	if cfg.CacheTTL != "" {
+		ttl, _ = time.ParseDuration(cfg.CacheTTL)
+	}
+	onError := cfg.OnError
+	if onError == "" {
+		onError = ScreeningQueue
+	}
+	return NewCachedScreener(&HTTPScreener{
+		URL:     cfg.URL,
+		Client:  &http.Client{Timeout: timeout},
+		OnError: onError,
+	}, ttl)
+}
+
+// HTTPScreener is an AddressScreener which consults an HTTP screening service
+type HTTPScreener struct {
+	URL    string
+	Client *http.Client
+
+	// OnError is the outcome returned with the error when the screening service fails
+	OnError ScreeningOutcome
+}
+
+var _ AddressScreener = (*HTTPScreener)(nil)
+
+// Screen implements AddressScreener.Screen
+func (s *HTTPScreener) Screen(ctx context.Context, chainID, address string) (ScreeningOutcome, error) {
+	body, err := json.Marshal(map[string]string{"chain_id": chainID, "address": address})
+	if err != nil {
+		return s.OnError, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return s.OnError, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return s.OnError, fmt.Errorf("failed to request the screening service: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s.OnError, fmt.Errorf("screening service responded with status %d", res.StatusCode)
+	}
+	var v struct {
+		Outcome ScreeningOutcome `json:"outcome"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+		return s.OnError, fmt.Errorf("failed to decode the response of the screening service: %w", err)
+	}
+	if err := v.Outcome.validate(); err != nil {
+		return s.OnError, err
+	}
+	return v.Outcome, nil
+}
+
+// CachedScreener caches the outcomes of an AddressScreener for a while. Failed screenings are not cached.
+type CachedScreener struct {
+	screener AddressScreener
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedOutcome
+}
+
+type cachedOutcome struct {
+	outcome   ScreeningOutcome
+	expiresAt time.Time
+}
+
+var _ AddressScreener = (*CachedScreener)(nil)
+
+// NewCachedScreener returns a screener which caches the outcomes of `screener` for `ttl`
+func NewCachedScreener(screener AddressScreener, ttl time.Duration) *CachedScreener {
+	return &CachedScreener{
+		screener: screener,
+		ttl:      ttl,
+		cache:    make(map[string]cachedOutcome),
+	}
+}
+
+// Screen implements AddressScreener.Screen
+func (s *CachedScreener) Screen(ctx context.Context, chainID, address string) (ScreeningOutcome, error) {
+	key := chainID + "/" + address
+	now := time.Now()
+
+	s.mu.Lock()
+	if c, ok := s.cache[key]; ok && now.Before(c.expiresAt) {
+		s.mu.Unlock()
+		return c.outcome, nil
+	}
+	s.mu.Unlock()
+
+	outcome, err := s.screener.Screen(ctx, chainID, address)
+	if err != nil {
+		return outcome, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, c := range s.cache {
+		if !now.Before(c.expiresAt) {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = cachedOutcome{outcome: outcome, expiresAt: now.Add(s.ttl)}
+	return outcome, nil
+}
+
+// screenPacket screens the sender on the sending chain and the receiver on the receiving chain of the packet data.
+// The most restrictive outcome is returned with the description of the reason,
+// and whether the outcome is the one applied because the screening service failed.
+func screenPacket(ctx context.Context, screener AddressScreener, chainID, counterpartyChainID string, data *PacketData) (ScreeningOutcome, string, bool) {
+	result, detail, failed := ScreeningAllow, "", false
+	for _, addr := range []struct{ role, chainID, address string }{
+		{"sender", chainID, data.Attribute("sender")},
+		{"receiver", counterpartyChainID, data.Attribute("receiver")},
+	} {
+		if addr.address == "" {
+			continue
+		}
+		outcome, err := screener.Screen(ctx, addr.chainID, addr.address)
+		reason := fmt.Sprintf("%s %s: %s", addr.role, addr.address, outcome)
+		if err != nil {
+			reason = fmt.Sprintf("%s %s: %s (screening failed: %v)", addr.role, addr.address, outcome, err)
+		}
+		switch {
+		case outcome == ScreeningDeny:
+			return ScreeningDeny, reason, err != nil
+		case outcome == ScreeningQueue && result == ScreeningAllow:
+			result, detail, failed = ScreeningQueue, reason, err != nil
+		}
+	}
+	return result, detail, failed
+}
@@ -0,0 +1,124 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// transferStrategy is scriptedStrategy whose packets carry the ICS-20 packet data except the ones in `undecodable`
+type transferStrategy struct {
+	*scriptedStrategy
+	undecodable map[uint64]bool
+}
+
+func (st *transferStrategy) UnrelayedPackets(src, dst *core.ProvableChain, sh core.SyncHeaders, includeRelayedButUnfinalized bool) (*core.RelayPackets, error) {
+	rp, err := st.scriptedStrategy.UnrelayedPackets(src, dst, sh, includeRelayedButUnfinalized)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range rp.Src {
+		if !st.undecodable[p.Sequence] {
+			p.Data = transfertypes.NewFungibleTokenPacketData("stake", "100", "cosmos1sender", "cosmos1receiver", "").GetBytes()
+		}
+	}
+	return rp, nil
+}
+
+type cycleKey struct{}
+
+// failingScreener fails while `fail` is set, and checks that it is consulted in the relay cycle without blocking the admin API
+type failingScreener struct {
+	srv *core.RelayService
+
+	mu    sync.Mutex
+	fail  bool
+	calls int
+	errs  []error
+}
+
+func (s *failingScreener) Screen(ctx context.Context, chainID, address string) (core.ScreeningOutcome, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if ctx.Value(cycleKey{}) == nil {
+		s.errs = append(s.errs, errors.New("the screening is not given the context of the relay cycle"))
+	}
+	done := make(chan struct{})
+	go func() {
+		s.srv.HeldPackets()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.errs = append(s.errs, errors.New("the held packets are locked while screening"))
+	}
+	if s.fail {
+		return core.ScreeningQueue, errors.New("service unavailable")
+	}
+	return core.ScreeningAllow, nil
+}
+
+func TestAddressScreeningHolds(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, sh := newScriptedChains(t)
+	st := &transferStrategy{scriptedStrategy: &scriptedStrategy{pending: []uint64{1, 2}}, undecodable: map[uint64]bool{2: true}}
+	srv := core.NewRelayService(st, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	screener := &failingScreener{srv: srv, fail: true}
+	srv.SetAddressScreener(screener, core.ScreeningQueue)
+	ctx := context.WithValue(context.TODO(), cycleKey{}, true)
+
+	// the packet is queued while the screening service fails, and the packet without the addresses is queued
+	if err := srv.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(st.relayed) != 0 {
+		t.Fatalf("the packets are relayed: %v", st.relayed)
+	}
+	failed := make(map[uint64]bool)
+	for _, p := range srv.HeldPackets() {
+		failed[p.Sequence] = p.ScreeningFailed
+	}
+	if len(failed) != 2 || !failed[1] || failed[2] {
+		t.Fatalf("unexpected held packets: %v", failed)
+	}
+
+	// the packet queued by the failure is screened again
+	screener.fail = false
+	if err := srv.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(st.relayed, []uint64{1}) {
+		t.Errorf("unexpected packets relayed: %v", st.relayed)
+	}
+	if held := srv.HeldPackets(); len(held) != 1 || held[0].Sequence != 2 {
+		t.Errorf("unexpected held packets: %+v", held)
+	}
+
+	// the packet held by the outcome of the screening is not screened again
+	calls := screener.calls
+	st.pending = []uint64{2}
+	if err := srv.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if screener.calls != calls || len(st.relayed) != 0 {
+		t.Errorf("the held packet is screened again: calls=%d, relayed=%v", screener.calls-calls, st.relayed)
+	}
+	for _, err := range screener.errs {
+		t.Error(err)
+	}
+}
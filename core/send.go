@@ -31,11 +31,9 @@ func GetFinalizedMsgResult(chain ProvableChain, msgID MsgID) (MsgResult, error)
 		// check whether the block that includes the message has been finalized, or not
 		if msgHeight, lfHeight := msgRes.BlockHeight(), lfHeader.GetHeight(); msgHeight.GT(lfHeight) {
 			// wait for the block including the msg to be finalized
-			var waitTime time.Duration
-			if msgHeight.GetRevisionNumber() != lfHeight.GetRevisionNumber() {
-				waitTime = avgBlockTime //TODO: is there better default value?
-			} else {
-				waitTime = avgBlockTime * time.Duration(msgHeight.GetRevisionHeight()-lfHeight.GetRevisionHeight())
+			waitTime := avgBlockTime //TODO: is there better default value for different revisions?
+			if blocks, ok := BlocksBetween(lfHeight, msgHeight); ok {
+				waitTime = avgBlockTime * time.Duration(blocks)
 			}
 			time.Sleep(waitTime)
 			return fmt.Errorf("msg(id=%v) not finalied: msg.height(%v) > lfh.height(%v)", msgID, msgHeight, lfHeight)
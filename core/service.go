@@ -2,9 +2,17 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	retry "github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	ics23 "github.com/cosmos/ics23/go"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
 )
 
 // StartService starts a relay service
@@ -39,10 +47,120 @@ func StartService(
 type RelayService struct {
 	src           *ProvableChain
 	dst           *ProvableChain
-	st            StrategyI
 	sh            SyncHeaders
 	interval      time.Duration
 	optimizeRelay OptimizeRelay
+
+	// channels relayed by the service; the first one is the channel set to `src` and `dst` at construction.
+	// channelsMu guards the additions of channels against the readers outside the relay cycle (e.g. the admin API).
+	channels   []*relayChannel
+	channelsMu sync.RWMutex
+
+	discovery *channelDiscovery
+
+	// if true, the service only scans unrelayed packets and acknowledgements and never broadcasts transactions
+	observe bool
+
+	// if true, the relay optimization is bypassed and all the pending packets are relayed in every relay cycle
+	relayAll bool
+
+	// errors of the transactions failed in the last relay cycle
+	sendErrors []error
+
+	// file to record the status of the service (e.g. the last relay time); the status is not recorded if empty
+	statusFile string
+	// status recorded to the status file, whose counters are restored from the file on startup.
+	// relayStatusMu guards it against the readers outside the relay cycle (e.g. the admin API).
+	relayStatus        RelayStatus
+	relayStatusChanged bool
+	relayStatusMu      sync.Mutex
+
+	// identifies the deployed relayer, which is logged on startup; nothing is logged if nil
+	identity *RelayerIdentity
+
+	// file to persist the metrics counters across restarts; the counters are not persisted if empty
+	counterFile         string
+	lastCounterSnapshot []byte
+
+	// accumulates the spend of the transactions and pauses relaying if the daily fee budget is exceeded
+	spendTracker *SpendTracker
+
+	// records the packet msgs submitted by the service; nothing is recorded if nil
+	journal *Journal
+
+	// file persisting the pause state of the path, which is checked in every relay cycle; the service is never paused if empty
+	pauseFile string
+
+	// group of the path and the file persisting the pause state of the group; the path doesn't belong to a group if empty
+	group          string
+	groupPauseFile string
+
+	// wakes up the service waiting for the next relay cycle when new packet events are detected
+	wake chan struct{}
+
+	// elects the leader among the instances serving the path; the service always relays if nil
+	leadership *leadership
+
+	// restricts the relayed channels to the ones assigned to the shard; all the channels are relayed if nil
+	shard *shard
+
+	// holds the packets exceeding the value limits or queued by the address screening until they are released
+	holds *packetHolds
+
+	// queues the packets and acknowledgements until the challenge windows of the chains elapse
+	delayed *challengeWindowQueue
+
+	// detects the relays by other relayers and yields the packets to them if enabled
+	counterpartyRelays *counterpartyRelays
+
+	// selects the packets relayed in a relay cycle across the channels; all the packets are relayed if nil
+	scheduler *RelayScheduler
+
+	// packets requested to be relayed on demand, which are relayed without waiting for the relay optimization
+	priority *priorityPackets
+
+	// raises alerts for the channels producing error acknowledgements at a high rate; no alert is raised if nil
+	errorAcks *errorAckMonitor
+
+	// payout addresses registered on the fee-enabled channels; the relayer addresses are registered if nil
+	feePayees *FeePayeeCfg
+
+	// raises alerts for the channels with too many pending packets and sheds their packets; nothing is checked if nil
+	backlogAlarm *backlogAlarm
+	// raises alerts for the heights known to the relayer lagging behind the chains; nothing is checked if nil
+	heightLagAlarm *heightLagAlarm
+	// verifies the commitment proofs locally before submitting them; nothing is verified if nil
+	commitmentCheck *commitmentCheck
+	// proof specs configured by chain ID, which are used by the commitment check
+	proofSpecs map[string][]*ics23.ProofSpec
+	// tops up the relayer accounts with low balances from the faucets; nothing is checked if nil
+	balances *balanceMonitor
+
+	// streams the relay events to the subscribers (e.g. the clients of the admin API)
+	events *EventFeed
+
+	// events of the packets and acknowledgements to be relayed in the current relay cycle
+	packetEvents map[packetEventKey]packetEvent
+
+	// name of the path served by the service, which is used in the guidance for the operator
+	pathName string
+}
+
+// channelDiscovery holds the state of the periodic channel discovery
+type channelDiscovery struct {
+	cfg         *ChannelDiscoveryCfg
+	newStrategy func() (StrategyI, error)
+	lastRun     time.Time
+}
+
+// relayChannel is a channel relayed by RelayService with its own strategy instance
+type relayChannel struct {
+	srcEnd *PathEnd
+	dstEnd *PathEnd
+	st     StrategyI
+
+	// true if the fee payees are registered or the channel turned out not to be fee-enabled
+	feePayeesChecked bool
 }
 
 type OptimizeRelay struct {
@@ -66,7 +184,6 @@ func NewRelayService(
 	return &RelayService{
 		src:      src,
 		dst:      dst,
-		st:       st,
 		sh:       sh,
 		interval: interval,
 		optimizeRelay: OptimizeRelay{
@@ -75,116 +192,593 @@ func NewRelayService(
 			dstOptimizeInterval: dstOptimizeInterval,
 			dstOptimizeCount:    dstOptimizeCount,
 		},
+		channels: []*relayChannel{{srcEnd: src.Path(), dstEnd: dst.Path(), st: st}},
+		holds:    newPacketHolds(),
+		delayed:  newChallengeWindowQueue(),
+		priority: newPriorityPackets(),
+		wake:     make(chan struct{}, 1),
+		events:   NewEventFeed(),
+
+		counterpartyRelays: newCounterpartyRelays(nil),
 	}
 }
 
+// AddChannel adds a channel relayed over the same clients and connection as the channel set to `src` and `dst`.
+// `st` must be a strategy instance dedicated to the channel because a strategy may keep per-channel state.
+func (srv *RelayService) AddChannel(srcEnd, dstEnd *PathEnd, st StrategyI) {
+	srv.channelsMu.Lock()
+	defer srv.channelsMu.Unlock()
+	srv.channels = append(srv.channels, &relayChannel{srcEnd: srcEnd, dstEnd: dstEnd, st: st})
+}
+
 // Start starts a relay service
 func (srv *RelayService) Start(ctx context.Context) error {
 	logger := GetChannelPairLogger(srv.src, srv.dst)
+	if srv.identity != nil {
+		logger.Info("relayer identity", srv.identity.LogAttrs()...)
+	}
+	srv.startEventSubscriptions(ctx)
+	if srv.leadership != nil {
+		go srv.runLeaderElection(ctx)
+	}
 	for {
-		if err := retry.Do(func() error {
-			select {
-			case <-ctx.Done():
-				return retry.Unrecoverable(ctx.Err())
-			default:
-				return srv.Serve(ctx)
-			}
-		}, rtyAtt, rtyDel, rtyErr, retry.OnRetry(func(n uint, err error) {
-			logger.Info(
-				"retrying to serve relays",
-				"src", srv.src.ChainID(),
-				"dst", srv.dst.ChainID(),
-				"try", n+1,
-				"try_limit", rtyAttNum,
-				"error", err.Error(),
-			)
-		})); err != nil {
+		if err := srv.serveWithRetry(ctx); err != nil {
+			srv.saveCounterSnapshot()
 			return err
 		}
-		time.Sleep(srv.interval)
+		srv.saveCounterSnapshot()
+		srv.waitNextCycle(ctx)
+	}
+}
+
+// serveWithRetry performs a relay cycle, retrying it on failures until the retries are exhausted or `ctx` is done
+func (srv *RelayService) serveWithRetry(ctx context.Context) error {
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	return retry.Do(func() error {
+		select {
+		case <-ctx.Done():
+			return retry.Unrecoverable(ctx.Err())
+		default:
+			return srv.Serve(ctx)
+		}
+	}, rtyAtt, rtyDel, rtyErr, retry.OnRetry(func(n uint, err error) {
+		srv.publishError(err)
+		logger.Info(
+			"retrying to serve relays",
+			"src", srv.src.ChainID(),
+			"dst", srv.dst.ChainID(),
+			"try", n+1,
+			"try_limit", rtyAttNum,
+			"error", err.Error(),
+		)
+	}))
+}
+
+// waitNextCycle waits for the relay interval, or until new packet events are detected by the event subscriptions
+func (srv *RelayService) waitNextCycle(ctx context.Context) {
+	t := time.NewTimer(srv.interval)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	case <-srv.wake:
+	}
+}
+
+// SetObserveMode enables or disables the observe mode.
+// In the observe mode, the service performs all the scans and emits metrics and logs as usual, but never submits any msgs to the chains.
+// It is useful to validate a config or to monitor a path served by other relayers.
+func (srv *RelayService) SetObserveMode(observe bool) {
+	srv.observe = observe
+}
+
+// observing returns true if the service doesn't submit msgs in the relay cycle
+// because the observe mode is enabled or it is a standby instance
+func (srv *RelayService) observing() bool {
+	return srv.observe || srv.isStandby()
+}
+
+// SetPathName sets the name of the path served by the service
+func (srv *RelayService) SetPathName(name string) {
+	srv.pathName = name
+}
+
+// SetIdentity sets the identity of the relayer, which is logged when the service starts and served by the admin API
+func (srv *RelayService) SetIdentity(id *RelayerIdentity) {
+	srv.identity = id
+}
+
+// Identity returns the identity of the relayer, or nil if it is not set
+func (srv *RelayService) Identity() *RelayerIdentity {
+	return srv.identity
+}
+
+// SetStatusFile sets the file to record the status of the service, which is read by `query status`
+// The direction counters of the status are restored from the file if it exists.
+func (srv *RelayService) SetStatusFile(file string) {
+	srv.statusFile = file
+	status, err := LoadRelayStatus(file)
+	if err != nil {
+		GetChannelPairLogger(srv.src, srv.dst).Error("failed to load the relay status", err, "file", file)
+	} else if status != nil {
+		srv.relayStatusMu.Lock()
+		srv.relayStatus = *status
+		srv.relayStatusMu.Unlock()
 	}
 }
 
+// RelayStatus returns the status of the service: the last relay time and the counters of the packet msgs submitted in each direction
+func (srv *RelayService) RelayStatus() RelayStatus {
+	srv.relayStatusMu.Lock()
+	defer srv.relayStatusMu.Unlock()
+	return srv.relayStatus
+}
+
+// SetSpendTracker sets the tracker of the spend of the path and registers it to the chains
+func (srv *RelayService) SetSpendTracker(tracker *SpendTracker) {
+	srv.spendTracker = tracker
+	tracker.Watch(srv.src.Chain)
+	tracker.Watch(srv.dst.Chain)
+}
+
+// SetJournal sets the journal to record the packet msgs submitted by the service
+func (srv *RelayService) SetJournal(journal *Journal) {
+	srv.journal = journal
+}
+
+// SetPauseFile sets the file persisting the pause state of the path.
+// The state can be changed by the admin API or `paths pause` and `paths resume` while the service is running.
+func (srv *RelayService) SetPauseFile(file string) {
+	srv.pauseFile = file
+}
+
+// PauseState returns the pause state of the path
+func (srv *RelayService) PauseState() (*PauseState, error) {
+	if srv.pauseFile == "" {
+		return &PauseState{}, nil
+	}
+	return LoadPauseState(srv.pauseFile)
+}
+
+// Pause pauses relaying on the path until Resume is called, even across restarts
+func (srv *RelayService) Pause(reason string) error {
+	if srv.pauseFile == "" {
+		return fmt.Errorf("the pause state file is not set")
+	}
+	return PausePath(srv.pauseFile, reason)
+}
+
+// Resume resumes relaying on the path
+func (srv *RelayService) Resume() error {
+	if srv.pauseFile == "" {
+		return fmt.Errorf("the pause state file is not set")
+	}
+	return ResumePath(srv.pauseFile)
+}
+
+// SetValueLimits sets the value limits to hold the packets transferring large amounts until they are released
+func (srv *RelayService) SetValueLimits(limits []*ValueLimitCfg) {
+	srv.holds.setLimits(limits)
+}
+
+// SetAddressScreener sets the screener consulted with the sender and receiver addresses of packets before relaying them.
+// The packets whose data can't be decoded to find the addresses get `onUndecodable`.
+func (srv *RelayService) SetAddressScreener(screener AddressScreener, onUndecodable ScreeningOutcome) {
+	srv.holds.setScreener(screener, onUndecodable)
+}
+
+// HeldPackets returns the packets held by the value limits or the address screening
+func (srv *RelayService) HeldPackets() []*HeldPacket {
+	return srv.holds.list()
+}
+
+// ReleasePacket releases the packet held by the value limits or queued by the address screening so that it is relayed in the next relay cycle.
+// `chainID`, `portID` and `channelID` identify the end of the channel from which the packet is sent.
+func (srv *RelayService) ReleasePacket(chainID, portID, channelID string, sequence uint64) error {
+	if err := srv.holds.release(chainID, portID, channelID, sequence); err != nil {
+		return err
+	}
+	GetChannelPairLogger(srv.src, srv.dst).Info("released a held packet",
+		"chain_id", chainID,
+		"port_id", portID,
+		"channel_id", channelID,
+		"sequence", sequence,
+	)
+	return nil
+}
+
+// EnableChannelDiscovery enables the periodic discovery of channels on the connection.
+// A strategy instance for each discovered channel is created by `newStrategy`.
+func (srv *RelayService) EnableChannelDiscovery(cfg *ChannelDiscoveryCfg, newStrategy func() (StrategyI, error)) {
+	srv.discovery = &channelDiscovery{cfg: cfg, newStrategy: newStrategy}
+}
+
+// discoverChannels adds the newly discovered channels to the service if the discovery interval has elapsed
+func (srv *RelayService) discoverChannels() error {
+	d := srv.discovery
+	if d == nil || time.Since(d.lastRun) < d.cfg.GetInterval() {
+		return nil
+	}
+	logger := GetConnectionPairLogger(srv.src, srv.dst)
+
+	if err := srv.setChannel(srv.channels[0]); err != nil {
+		return err
+	}
+	pairs, err := DiscoverChannels(srv.src, srv.dst, d.cfg)
+	if err != nil {
+		logger.Error("failed to discover channels", err)
+		return err
+	}
+	d.lastRun = time.Now()
+
+	for _, pair := range pairs {
+		if srv.hasChannel(pair.Src) {
+			continue
+		}
+		st, err := d.newStrategy()
+		if err != nil {
+			return err
+		}
+		srv.AddChannel(pair.Src, pair.Dst, st)
+		logger.Info("discovered a new channel to relay",
+			"src_port_id", pair.Src.PortID,
+			"src_channel_id", pair.Src.ChannelID,
+			"dst_port_id", pair.Dst.PortID,
+			"dst_channel_id", pair.Dst.ChannelID,
+		)
+	}
+	return nil
+}
+
+// hasChannel returns true if the channel of `srcEnd` is already relayed by the service
+func (srv *RelayService) hasChannel(srcEnd *PathEnd) bool {
+	for _, ch := range srv.channels {
+		if ch.srcEnd.PortID == srcEnd.PortID && ch.srcEnd.ChannelID == srcEnd.ChannelID {
+			return true
+		}
+	}
+	return false
+}
+
 // Serve performs packet-relay
 func (srv *RelayService) Serve(ctx context.Context) error {
 	logger := GetChannelPairLogger(srv.src, srv.dst)
+	srv.sendErrors = nil
+
+	if state, err := srv.PauseState(); err != nil {
+		logger.Error("failed to load the pause state", err, "file", srv.pauseFile)
+		return err
+	} else if state.Paused {
+		logger.Info("relaying is paused", "reason", state.Reason, "since", state.Since)
+		for _, r := range state.Recovery {
+			logger.Warn("the client requires recovery", "chain_id", r.ChainID, "client_id", r.ClientID, "status", r.Status, "steps", r.Steps)
+		}
+		return nil
+	}
+	if state, err := srv.GroupPauseState(); err != nil {
+		logger.Error("failed to load the pause state of the group", err, "file", srv.groupPauseFile)
+		return err
+	} else if state.Paused {
+		logger.Info("relaying is paused for the group", "group", srv.group, "reason", state.Reason, "since", state.Since)
+		return nil
+	}
 
 	// First, update the latest headers for src and dst
 	if err := srv.sh.Updates(srv.src, srv.dst); err != nil {
 		logger.Error("failed to update headers", err)
 		return err
 	}
+	srv.checkHeightLags()
+	srv.checkBalances(ctx)
 
-	// get unrelayed packets
-	pseqs, err := srv.st.UnrelayedPackets(srv.src, srv.dst, srv.sh, false)
-	if err != nil {
-		logger.Error("failed to get unrelayed packets", err)
+	if err := srv.discoverChannels(); err != nil {
 		return err
 	}
 
-	// get unrelayed acks
-	aseqs, err := srv.st.UnrelayedAcknowledgements(srv.src, srv.dst, srv.sh, false)
-	if err != nil {
-		logger.Error("failed to get unrelayed acknowledgements", err)
+	srv.packetEvents = nil
+	var scans []*channelScan
+	for _, ch := range srv.channels {
+		if !srv.ownsChannel(ch) {
+			continue
+		}
+		if err := srv.setChannel(ch); err != nil {
+			return err
+		}
+		srv.checkFeePayees(ch)
+		scan, err := srv.scanChannel(ctx, ch)
+		if err != nil {
+			return err
+		}
+		scans = append(scans, scan)
+	}
+
+	if srv.observing() {
+		return nil
+	}
+
+	// the packets relayed in this cycle are selected across all the channels
+	srv.scheduleChannels(scans)
+
+	var (
+		packetMsgs, ackMsgs                                                    = NewRelayMsgs(), NewRelayMsgs()
+		doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst bool
+	)
+	for _, scan := range scans {
+		if err := srv.setChannel(scan.ch); err != nil {
+			return err
+		}
+		pm, am, err := srv.buildChannelMsgs(scan)
+		if err != nil {
+			return err
+		}
+		packetMsgs.Merge(pm)
+		ackMsgs.Merge(am)
+		doExecuteRelaySrc = doExecuteRelaySrc || scan.relaySrc
+		doExecuteRelayDst = doExecuteRelayDst || scan.relayDst
+		doExecuteAckSrc = doExecuteAckSrc || scan.ackSrc
+		doExecuteAckDst = doExecuteAckDst || scan.ackDst
+	}
+
+	if chainID := srv.spendTracker.BudgetExceeded(); chainID != "" {
+		logger.Warn("relaying is paused until the next day (UTC) because the daily fee budget is exceeded", "budget_chain_id", chainID)
+		return nil
+	}
+	if chainID := srv.spendTracker.GroupBudgetExceeded(); chainID != "" {
+		logger.Warn("relaying is paused until the next day (UTC) because the daily fee budget of the group is exceeded", "group", srv.group, "budget_chain_id", chainID)
+		return nil
+	}
+
+	// the clients are shared by all the channels, so they are updated only once
+	primary := srv.channels[0]
+	if err := srv.setChannel(primary); err != nil {
 		return err
 	}
 
 	msgs := NewRelayMsgs()
 
-	doExecuteRelaySrc, doExecuteRelayDst := srv.shouldExecuteRelay(pseqs)
-	doExecuteAckSrc, doExecuteAckDst := srv.shouldExecuteRelay(aseqs)
 	// update clients
-	if m, err := srv.st.UpdateClients(srv.src, srv.dst, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst, srv.sh, true); err != nil {
+	if m, err := primary.st.UpdateClients(srv.src, srv.dst, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst, srv.sh, true); err != nil {
 		logger.Error("failed to update clients", err)
+		if srv.recoverInactiveClients(err) {
+			return nil
+		}
 		return err
 	} else {
 		msgs.Merge(m)
 	}
 
-	// relay packets if unrelayed seqs exist
-	if m, err := srv.st.RelayPackets(srv.src, srv.dst, pseqs, srv.sh, doExecuteRelaySrc, doExecuteRelayDst); err != nil {
-		logger.Error("failed to relay packets", err)
-		return err
+	if orderer, ok := primary.st.(MsgOrderer); ok {
+		msgs.Merge(orderer.OrderMsgs(packetMsgs, ackMsgs))
 	} else {
-		msgs.Merge(m)
+		msgs.Merge(packetMsgs)
+		msgs.Merge(ackMsgs)
 	}
+	srv.checkCommitments(msgs)
 
-	// relay acks if unrelayed seqs exist
-	if m, err := srv.st.RelayAcknowledgements(srv.src, srv.dst, aseqs, srv.sh, doExecuteAckSrc, doExecuteAckDst); err != nil {
-		logger.Error("failed to relay acknowledgements", err)
-		return err
-	} else {
-		msgs.Merge(m)
+	// the leadership may have been lost while the msgs were built
+	if !srv.confirmLeadership(ctx) {
+		logger.Info("skipped submitting the msgs since the service is no longer the leader")
+		return nil
 	}
 
 	// send all msgs to src/dst chains
-	srv.st.Send(srv.src, srv.dst, msgs)
+	primary.st.Send(srv.src, srv.dst, msgs)
+	srv.recordRelayedMsgs(msgs)
+	srv.sendErrors = msgs.Errors
+	for _, err := range msgs.Errors {
+		if srv.recoverInactiveClients(err) {
+			break
+		}
+	}
+
+	if msgs.Ready() && msgs.Success() {
+		srv.relayStatusMu.Lock()
+		srv.relayStatus.LastRelayTime = time.Now()
+		srv.relayStatusChanged = true
+		srv.relayStatusMu.Unlock()
+	}
+	srv.saveRelayStatus()
 
 	return nil
 }
 
+// saveRelayStatus saves the status of the service to the status file if it has changed since the last save
+func (srv *RelayService) saveRelayStatus() {
+	if srv.statusFile == "" {
+		return
+	}
+	srv.relayStatusMu.Lock()
+	defer srv.relayStatusMu.Unlock()
+	if !srv.relayStatusChanged {
+		return
+	}
+	if err := SaveRelayStatus(srv.statusFile, &srv.relayStatus); err != nil {
+		GetChannelPairLogger(srv.src, srv.dst).Error("failed to save the relay status", err, "file", srv.statusFile)
+		return
+	}
+	srv.relayStatusChanged = false
+}
+
+// recordRelayedMsgs logs the packet msgs submitted to the chains with the decoded packet data, updates the metrics and appends them to the journal
+func (srv *RelayService) recordRelayedMsgs(msgs *RelayMsgs) {
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	srcEntries := journalEntries(srv.src, msgs.Src, msgs.SrcMsgIDs, srv.channelVersion)
+	dstEntries := journalEntries(srv.dst, msgs.Dst, msgs.DstMsgIDs, srv.channelVersion)
+	srv.recordDirection(DstToSrc, srcEntries)
+	srv.recordDirection(SrcToDst, dstEntries)
+	entries := append(srcEntries, dstEntries...)
+	srv.recordOwnRelays(entries)
+	srv.setEventTimes(entries)
+	srv.recordDeliveryLatencies(msgs)
+	for _, e := range entries {
+		if spend, ok := srv.spendTracker.recentTxSpend(e.ChainID, e.TxID); ok {
+			e.GasUsed, e.Fee, e.FeesEarned = spend.GasUsed, spend.Fee, spend.FeesEarned
+		}
+	}
+	for _, e := range entries {
+		if e.Data == nil || !e.Success {
+			continue
+		}
+		logger.Info("relayed a packet", append([]interface{}{
+			"chain_id", e.ChainID,
+			"msg_type", e.MsgType,
+			"source_channel", e.SourceChannel,
+			"sequence", e.Sequence,
+		}, e.Data.LogAttrs()...)...)
+		if e.Transfer != nil && e.MsgType == sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}) {
+			metrics.TransferPacketsRelayedCounter.Add(context.TODO(), 1, api.WithAttributes(
+				attribute.Key("chain_id").String(e.ChainID),
+				attribute.Key("base_denom").String(denomLabel(e.Transfer.BaseDenom)),
+				relayDirectionAttr(e.Direction),
+			))
+		}
+	}
+	srv.inspectAcknowledgements(entries)
+	srv.publishRelayEvents(msgs, entries)
+	if err := srv.journal.Append(entries); err != nil {
+		logger.Error("failed to append to the journal", err)
+	}
+}
+
+// channelVersion returns the version of the relayed channel on which the packet is sent
+func (srv *RelayService) channelVersion(packet chantypes.Packet) string {
+	for _, ch := range srv.channels {
+		for _, end := range []*PathEnd{ch.srcEnd, ch.dstEnd} {
+			if end.PortID == packet.SourcePort && end.ChannelID == packet.SourceChannel {
+				return end.Version
+			}
+		}
+	}
+	return ""
+}
+
+// setChannel sets the path ends of `ch` to the chains if the service relays multiple channels
+func (srv *RelayService) setChannel(ch *relayChannel) error {
+	if len(srv.channels) == 1 {
+		return nil
+	}
+	if err := srv.src.SetRelayInfo(ch.srcEnd, srv.dst, ch.dstEnd); err != nil {
+		return err
+	}
+	if err := srv.dst.SetRelayInfo(ch.dstEnd, srv.src, ch.srcEnd); err != nil {
+		return err
+	}
+	return nil
+}
+
+// channelScan is the unrelayed packets and acknowledgements found on a channel in a relay cycle,
+// and whether they are relayed to each chain in the cycle
+type channelScan struct {
+	ch    *relayChannel
+	pseqs *RelayPackets
+	aseqs *RelayPackets
+
+	relaySrc, relayDst, ackSrc, ackDst bool
+}
+
+// scanChannel finds the unrelayed packets and acknowledgements on the channel currently set to the chains,
+// and decides whether they are relayed in this relay cycle
+func (srv *RelayService) scanChannel(ctx context.Context, ch *relayChannel) (*channelScan, error) {
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+
+	// get unrelayed packets
+	pseqs, err := ch.st.UnrelayedPackets(srv.src, srv.dst, srv.sh, false)
+	if err != nil {
+		logger.Error("failed to get unrelayed packets", err)
+		return nil, err
+	}
+
+	// get unrelayed acks
+	aseqs, err := ch.st.UnrelayedAcknowledgements(srv.src, srv.dst, srv.sh, false)
+	if err != nil {
+		logger.Error("failed to get unrelayed acknowledgements", err)
+		return nil, err
+	}
+
+	srv.detectCounterpartyRelays(ch, pseqs, aseqs)
+
+	if srv.observing() {
+		logger.Info("observe mode: skipped relaying",
+			"standby", srv.isStandby(),
+			"unrelayed_src_packets", len(pseqs.Src),
+			"unrelayed_dst_packets", len(pseqs.Dst),
+			"unrelayed_src_acks", len(aseqs.Src),
+			"unrelayed_dst_acks", len(aseqs.Dst),
+		)
+		return &channelScan{ch: ch, pseqs: &RelayPackets{}, aseqs: &RelayPackets{}}, nil
+	}
+
+	pseqs.Src = srv.holds.filter(ctx, srv.src, srv.dst, ch.srcEnd, pseqs.Src)
+	pseqs.Dst = srv.holds.filter(ctx, srv.dst, srv.src, ch.dstEnd, pseqs.Dst)
+	srv.checkBacklogs(ch, pseqs)
+	pseqs.Src = srv.yieldPackets(srv.src.ChainID(), ch.srcEnd, pseqs.Src)
+	pseqs.Dst = srv.yieldPackets(srv.dst.ChainID(), ch.dstEnd, pseqs.Dst)
+
+	if err := srv.filterChallengeWindows(ch, pseqs, aseqs); err != nil {
+		logger.Error("failed to check the challenge windows", err)
+		return nil, err
+	}
+
+	srv.rememberPacketEvents(pseqs, aseqs)
+
+	scan := &channelScan{ch: ch, pseqs: pseqs, aseqs: aseqs}
+	scan.relaySrc, scan.relayDst = srv.shouldExecuteRelay(pseqs)
+	prioritySrc, priorityDst := srv.prioritizePackets(pseqs)
+	scan.relaySrc, scan.relayDst = scan.relaySrc || prioritySrc, scan.relayDst || priorityDst
+	scan.ackSrc, scan.ackDst = srv.shouldExecuteRelay(aseqs)
+	return scan, nil
+}
+
+// buildChannelMsgs builds the msgs to relay the packets and the msgs to relay the acknowledgements of `scan`
+// on the channel currently set to the chains
+func (srv *RelayService) buildChannelMsgs(scan *channelScan) (packetMsgs, ackMsgs *RelayMsgs, err error) {
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+
+	// relay packets if unrelayed seqs exist
+	packetMsgs, err = scan.ch.st.RelayPackets(srv.src, srv.dst, scan.pseqs, srv.sh, scan.relaySrc, scan.relayDst)
+	if err != nil {
+		logger.Error("failed to relay packets", err)
+		return nil, nil, err
+	}
+
+	// relay acks if unrelayed seqs exist
+	ackMsgs, err = scan.ch.st.RelayAcknowledgements(srv.src, srv.dst, scan.aseqs, srv.sh, scan.ackSrc, scan.ackDst)
+	if err != nil {
+		logger.Error("failed to relay acknowledgements", err)
+		return nil, nil, err
+	}
+
+	return packetMsgs, ackMsgs, nil
+}
+
 func (srv *RelayService) shouldExecuteRelay(seqs *RelayPackets) (bool, bool) {
 	logger := GetChannelPairLogger(srv.src, srv.dst)
 
+	// the packets sent from src are relayed to dst and vice versa
+	if srv.relayAll {
+		return len(seqs.Dst) > 0, len(seqs.Src) > 0
+	}
+
 	srcRelay := false
 	dstRelay := false
 
+	// the packets sent before the upgrade of the chain are old enough to be relayed
 	if len(seqs.Src) > 0 {
-		tsDst, err := srv.src.Timestamp(seqs.Src[0].EventHeight)
-		if err != nil {
+		if inPreviousRevision(srv.src.ChainID(), seqs.Src[0].EventHeight) {
+			dstRelay = true
+		} else if tsDst, err := srv.src.Timestamp(seqs.Src[0].EventHeight); err != nil {
 			return false, false
-		}
-		if time.Since(tsDst) >= srv.optimizeRelay.dstOptimizeInterval {
+		} else if time.Since(tsDst) >= srv.optimizeRelay.dstOptimizeInterval {
 			dstRelay = true
 		}
 	}
 
 	if len(seqs.Dst) > 0 {
-		tsSrc, err := srv.dst.Timestamp(seqs.Dst[0].EventHeight)
-		if err != nil {
+		if inPreviousRevision(srv.dst.ChainID(), seqs.Dst[0].EventHeight) {
+			srcRelay = true
+		} else if tsSrc, err := srv.dst.Timestamp(seqs.Dst[0].EventHeight); err != nil {
 			return false, false
-		}
-		if time.Since(tsSrc) >= srv.optimizeRelay.srcOptimizeInterval {
+		} else if time.Since(tsSrc) >= srv.optimizeRelay.srcOptimizeInterval {
 			srcRelay = true
 		}
 	}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// ShardingCfg splits the channels of a path among multiple relay service instances,
+// each of which is started with its own shard index and relays only the channels assigned to the shard.
+// Channels are identified by their src channel IDs. A channel without an explicit assignment is assigned
+// to the shard of the FNV-1a hash of the channel ID modulo the number of shards.
+type ShardingCfg struct {
+	// Shards is the number of shards (i.e. relay service instances)
+	Shards uint32 `json:"shards" yaml:"shards"`
+
+	// Assignments assigns channels to shards explicitly (shard index => src channel IDs)
+	Assignments map[uint32][]string `json:"assignments,omitempty" yaml:"assignments,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *ShardingCfg) Validate() error {
+	if cfg.Shards == 0 {
+		return fmt.Errorf("sharding: shards must be positive")
+	}
+	for shard := range cfg.Assignments {
+		if shard >= cfg.Shards {
+			return fmt.Errorf("sharding: shard index %d of the assignments must be less than the number of shards %d", shard, cfg.Shards)
+		}
+	}
+	return nil
+}
+
+// ShardsOf returns the shards relaying the channel. More than one shard is returned if the channel
+// is explicitly assigned to multiple shards, in which case the instances relay the same packets.
+func (cfg *ShardingCfg) ShardsOf(channelID string) []uint32 {
+	var shards []uint32
+	for shard, channelIDs := range cfg.Assignments {
+		for _, id := range channelIDs {
+			if id == channelID {
+				shards = append(shards, shard)
+				break
+			}
+		}
+	}
+	if len(shards) > 0 {
+		sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+		return shards
+	}
+	h := fnv.New32a()
+	h.Write([]byte(channelID))
+	return []uint32{h.Sum32() % cfg.Shards}
+}
+
+// Owns returns true if the shard relays the channel
+func (cfg *ShardingCfg) Owns(shard uint32, channelID string) bool {
+	for _, s := range cfg.ShardsOf(channelID) {
+		if s == shard {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps returns the channels explicitly assigned to multiple shards with the shards
+func (cfg *ShardingCfg) Overlaps() map[string][]uint32 {
+	overlaps := make(map[string][]uint32)
+	for _, channelIDs := range cfg.Assignments {
+		for _, id := range channelIDs {
+			if _, ok := overlaps[id]; ok {
+				continue
+			}
+			if shards := cfg.ShardsOf(id); len(shards) > 1 {
+				overlaps[id] = shards
+			}
+		}
+	}
+	return overlaps
+}
+
+// shard is the shard relayed by the relay service
+type shard struct {
+	cfg   *ShardingCfg
+	index uint32
+}
+
+// SetShard restricts the channels relayed by the service to the ones assigned to the shard `index`.
+// The clients are still updated for the relayed channels even if the channel set to `src` and `dst` belongs to another shard.
+func (srv *RelayService) SetShard(cfg *ShardingCfg, index uint32) error {
+	if index >= cfg.Shards {
+		return fmt.Errorf("shard index %d must be less than the number of shards %d", index, cfg.Shards)
+	}
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	for channelID, shards := range cfg.Overlaps() {
+		logger.Warn("a channel is assigned to multiple shards, so the instances relay the same packets",
+			"channel_id", channelID,
+			"shards", shards,
+		)
+	}
+	srv.shard = &shard{cfg: cfg, index: index}
+	return nil
+}
+
+// ownsChannel returns true if the channel is assigned to the shard of the service
+func (srv *RelayService) ownsChannel(ch *relayChannel) bool {
+	return srv.shard == nil || srv.shard.cfg.Owns(srv.shard.index, ch.srcEnd.ChannelID)
+}
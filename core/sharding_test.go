@@ -0,0 +1,47 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestShardingCfg(t *testing.T) {
+	cfg := &core.ShardingCfg{
+		Shards: 3,
+		Assignments: map[uint32][]string{
+			0: {"channel-0", "channel-5"},
+			2: {"channel-5"},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Owns(0, "channel-0") || cfg.Owns(1, "channel-0") || cfg.Owns(2, "channel-0") {
+		t.Errorf("channel-0 must be relayed only by the shard 0")
+	}
+
+	// each unassigned channel is relayed by exactly one shard
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("channel-%d", 100+i)
+		owners := 0
+		for shard := uint32(0); shard < cfg.Shards; shard++ {
+			if cfg.Owns(shard, id) {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Errorf("%s is relayed by %d shards", id, owners)
+		}
+	}
+
+	overlaps := cfg.Overlaps()
+	if len(overlaps) != 1 || len(overlaps["channel-5"]) != 2 {
+		t.Errorf("unexpected overlaps: %v", overlaps)
+	}
+
+	if err := (&core.ShardingCfg{Shards: 2, Assignments: map[uint32][]string{2: {"channel-0"}}}).Validate(); err == nil {
+		t.Errorf("Validate must fail with an assignment to a shard out of range")
+	}
+}
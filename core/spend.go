@@ -0,0 +1,243 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/utils"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// TxSpend is the gas used and the fee paid by a transaction
+type TxSpend struct {
+	ChainID string
+	// TxID is the ID of the transaction in the same format as TxMsgID.TxID; it may be empty
+	TxID    string
+	GasUsed uint64
+	Fee     sdk.Coins
+	// FeesEarned is the ICS-29 relayer fees paid to the relayer by the transaction
+	FeesEarned sdk.Coins
+}
+
+// TxSpendListener is a listener that is notified of the spend of every transaction sent to a chain
+type TxSpendListener interface {
+	OnTxSpend(spend TxSpend)
+}
+
+// TxSpendReporter is an optional interface of Chain to report the gas used and the fees paid by the transactions it sends.
+// The spend must be reported even if the execution of the transaction failed because the fee is charged anyway.
+type TxSpendReporter interface {
+	RegisterTxSpendListener(TxSpendListener)
+}
+
+// FeeBudgetCfg limits the fees paid by the relayer for a path per day (in UTC).
+// The budgets are coins (e.g. "1000000stake"), and relaying is paused until the next day once any denom of the fees paid
+// on a chain reaches the budget of the chain. Empty means unlimited.
+type FeeBudgetCfg struct {
+	SrcDaily string `json:"src-daily,omitempty" yaml:"src-daily,omitempty"`
+	DstDaily string `json:"dst-daily,omitempty" yaml:"dst-daily,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *FeeBudgetCfg) Validate() error {
+	if _, err := sdk.ParseCoinsNormalized(cfg.SrcDaily); err != nil {
+		return fmt.Errorf("fee-budget: invalid src-daily: %w", err)
+	}
+	if _, err := sdk.ParseCoinsNormalized(cfg.DstDaily); err != nil {
+		return fmt.Errorf("fee-budget: invalid dst-daily: %w", err)
+	}
+	return nil
+}
+
+// SpendFile returns the path of the file to record the spend of the path
+func SpendFile(homePath, pathName string) string {
+	return filepath.Join(homePath, "spend", pathName+".json")
+}
+
+// PathSpend is the cumulative spend of a path persisted to the spend file
+type PathSpend struct {
+	Path   string                 `json:"path"`
+	Chains map[string]*ChainSpend `json:"chains"`
+}
+
+// ChainSpend is the cumulative spend of a path on a chain
+type ChainSpend struct {
+	GasUsed uint64    `json:"gas_used"`
+	Fees    sdk.Coins `json:"fees"`
+
+	// fees paid on the day (in UTC, formatted as "2006-01-02")
+	Day     string    `json:"day"`
+	DayFees sdk.Coins `json:"day_fees"`
+}
+
+// LoadPathSpend reads the spend file. It returns an empty spend if the file doesn't exist.
+func LoadPathSpend(file, pathName string) (*PathSpend, error) {
+	spend := &PathSpend{Path: pathName, Chains: make(map[string]*ChainSpend)}
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return spend, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bz, spend); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the spend file %s: %w", file, err)
+	}
+	if spend.Chains == nil {
+		spend.Chains = make(map[string]*ChainSpend)
+	}
+	return spend, nil
+}
+
+func (s *PathSpend) save(file string) error {
+	bz, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(file, bz, 0600)
+}
+
+// SpendTracker accumulates the spend of the transactions sent for a path, persists it and checks the daily fee budgets
+type SpendTracker struct {
+	file    string
+	budgets map[string]sdk.Coins // chain ID => daily fee budget
+
+	mu    sync.Mutex
+	spend *PathSpend
+
+	// spends of the recent transactions by chain ID and tx ID, looked up to record the spend in the journal
+	recent map[txSpendKey]TxSpend
+
+	// group of the path, which is nil if the path doesn't belong to a group (see SetGroup)
+	group *groupBudget
+}
+
+type txSpendKey struct {
+	chainID string
+	txID    string
+}
+
+// maxRecentTxSpends bounds the number of the spends remembered by SpendTracker
+const maxRecentTxSpends = 1000
+
+var _ TxSpendListener = (*SpendTracker)(nil)
+
+// NewSpendTracker returns a new SpendTracker that records the spend of the path to `file`.
+// `budget` may be nil.
+func NewSpendTracker(file, pathName string, path *Path, budget *FeeBudgetCfg) (*SpendTracker, error) {
+	spend, err := LoadPathSpend(file, pathName)
+	if err != nil {
+		return nil, err
+	}
+	budgets := make(map[string]sdk.Coins)
+	if budget != nil {
+		if budgets[path.Src.ChainID], err = sdk.ParseCoinsNormalized(budget.SrcDaily); err != nil {
+			return nil, err
+		}
+		if budgets[path.Dst.ChainID], err = sdk.ParseCoinsNormalized(budget.DstDaily); err != nil {
+			return nil, err
+		}
+	}
+	return &SpendTracker{file: file, budgets: budgets, spend: spend}, nil
+}
+
+// Watch registers the tracker to the chain if the chain reports the spend of transactions
+func (t *SpendTracker) Watch(chain Chain) {
+	if r, ok := chain.(TxSpendReporter); ok {
+		r.RegisterTxSpendListener(t)
+	} else {
+		GetChainLogger(chain).Warn("the chain doesn't report the spend of transactions")
+	}
+}
+
+// OnTxSpend implements TxSpendListener
+func (t *SpendTracker) OnTxSpend(spend TxSpend) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cs, ok := t.spend.Chains[spend.ChainID]
+	if !ok {
+		cs = &ChainSpend{}
+		t.spend.Chains[spend.ChainID] = cs
+	}
+	today := time.Now().UTC().Format(time.DateOnly)
+	if cs.Day != today {
+		cs.Day = today
+		cs.DayFees = nil
+	}
+	if spend.TxID != "" {
+		if t.recent == nil || len(t.recent) >= maxRecentTxSpends {
+			t.recent = make(map[txSpendKey]TxSpend)
+		}
+		t.recent[txSpendKey{spend.ChainID, strings.ToUpper(spend.TxID)}] = spend
+	}
+
+	cs.GasUsed += spend.GasUsed
+	cs.Fees = cs.Fees.Add(spend.Fee...)
+	cs.DayFees = cs.DayFees.Add(spend.Fee...)
+
+	attrs := []attribute.KeyValue{
+		attribute.Key("chain_id").String(spend.ChainID),
+		attribute.Key("path").String(t.spend.Path),
+	}
+	if t.group != nil {
+		attrs = append(attrs, groupAttrs(t.group.group)...)
+	}
+	metrics.GasUsedCounter.Add(context.TODO(), int64(spend.GasUsed), api.WithAttributes(attrs...))
+	for _, coin := range spend.Fee {
+		if coin.Amount.IsInt64() {
+			metrics.FeesPaidCounter.Add(context.TODO(), coin.Amount.Int64(), api.WithAttributes(append(attrs, attribute.Key("denom").String(coin.Denom))...))
+		}
+	}
+
+	if err := t.spend.save(t.file); err != nil {
+		log.GetLogger().WithModule("core.spend").Error("failed to save the spend", err, "file", t.file)
+	}
+}
+
+// recentTxSpend returns the spend of the recent transaction reported to the tracker
+func (t *SpendTracker) recentTxSpend(chainID, txID string) (TxSpend, bool) {
+	if t == nil || txID == "" {
+		return TxSpend{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	spend, ok := t.recent[txSpendKey{chainID, strings.ToUpper(txID)}]
+	return spend, ok
+}
+
+// BudgetExceeded returns the ID of a chain on which today's fees have reached the daily budget, or empty if none
+func (t *SpendTracker) BudgetExceeded() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	for chainID, budget := range t.budgets {
+		cs, ok := t.spend.Chains[chainID]
+		if !ok || cs.Day != today || budget.IsZero() {
+			continue
+		}
+		for _, coin := range budget {
+			if cs.DayFees.AmountOf(coin.Denom).GTE(coin.Amount) {
+				return chainID
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// StateExportVersion is the version of the format of StateExport
+const StateExportVersion = 1
+
+// StateExport is a dump of the durable state of the relayer stored under the home directory,
+// which is imported on another machine to migrate a relayer deployment without losing its progress.
+// The packets to relay are not included because they are always scanned from the chains.
+type StateExport struct {
+	Version    int                   `json:"version"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Paths      map[string]*PathState `json:"paths"`
+}
+
+// PathState is the durable state of a path
+type PathState struct {
+	Status  *RelayStatus  `json:"status,omitempty"`
+	Spend   *PathSpend    `json:"spend,omitempty"`
+	Pause   *PauseState   `json:"pause,omitempty"`
+	Journal *JournalState `json:"journal,omitempty"`
+
+	Counters metrics.CounterSnapshot `json:"counters,omitempty"`
+}
+
+// JournalState is the position of the relay journal of a path, with its content if exported with it
+type JournalState struct {
+	Size     int64      `json:"size"`
+	Entries  int        `json:"entries"`
+	LastTime *time.Time `json:"last_time,omitempty"`
+	// Content is the whole journal file, which is set only if the journal is exported with the state
+	Content []byte `json:"content,omitempty"`
+}
+
+// ExportState dumps the durable state of the paths stored under `homePath`
+func ExportState(homePath string, pathNames []string, withJournal bool) (*StateExport, error) {
+	export := &StateExport{
+		Version:    StateExportVersion,
+		ExportedAt: time.Now(),
+		Paths:      make(map[string]*PathState),
+	}
+	for _, name := range pathNames {
+		var (
+			state PathState
+			err   error
+		)
+		if state.Status, err = LoadRelayStatus(RelayStatusFile(homePath, name)); err != nil {
+			return nil, err
+		}
+		if exists(SpendFile(homePath, name)) {
+			if state.Spend, err = LoadPathSpend(SpendFile(homePath, name), name); err != nil {
+				return nil, err
+			}
+		}
+		if exists(PauseStateFile(homePath, name)) {
+			if state.Pause, err = LoadPauseState(PauseStateFile(homePath, name)); err != nil {
+				return nil, err
+			}
+		}
+		if state.Journal, err = loadJournalState(JournalFile(homePath, name), withJournal); err != nil {
+			return nil, err
+		}
+		if state.Counters, err = LoadCounterSnapshot(CounterSnapshotFile(homePath, name)); err != nil {
+			return nil, err
+		}
+		export.Paths[name] = &state
+	}
+	return export, nil
+}
+
+// ImportState restores the durable state of the paths under `homePath`.
+// It fails without writing anything if the state of a path already exists, unless `overwrite` is true.
+func ImportState(homePath string, export *StateExport, overwrite bool) error {
+	if export.Version != StateExportVersion {
+		return fmt.Errorf("unsupported state export version: %d", export.Version)
+	}
+	if !overwrite {
+		for name, state := range export.Paths {
+			for _, file := range stateFiles(homePath, name, state) {
+				if exists(file) {
+					return fmt.Errorf("the state of the path %s already exists: %s", name, file)
+				}
+			}
+		}
+	}
+	for name, state := range export.Paths {
+		if state.Status != nil {
+			if err := SaveRelayStatus(RelayStatusFile(homePath, name), state.Status); err != nil {
+				return err
+			}
+		}
+		if state.Spend != nil {
+			state.Spend.Path = name
+			if err := state.Spend.save(SpendFile(homePath, name)); err != nil {
+				return err
+			}
+		}
+		if state.Pause != nil {
+			if err := SavePauseState(PauseStateFile(homePath, name), state.Pause); err != nil {
+				return err
+			}
+		}
+		if state.Counters != nil {
+			if err := SaveCounterSnapshot(CounterSnapshotFile(homePath, name), state.Counters); err != nil {
+				return err
+			}
+		}
+		if state.Journal != nil && state.Journal.Content != nil {
+			file := JournalFile(homePath, name)
+			if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+				return err
+			}
+			if err := utils.WriteFileAtomic(file, state.Journal.Content, 0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stateFiles returns the files written by ImportState for the path
+func stateFiles(homePath, name string, state *PathState) []string {
+	var files []string
+	if state.Status != nil {
+		files = append(files, RelayStatusFile(homePath, name))
+	}
+	if state.Spend != nil {
+		files = append(files, SpendFile(homePath, name))
+	}
+	if state.Pause != nil {
+		files = append(files, PauseStateFile(homePath, name))
+	}
+	if state.Counters != nil {
+		files = append(files, CounterSnapshotFile(homePath, name))
+	}
+	if state.Journal != nil && state.Journal.Content != nil {
+		files = append(files, JournalFile(homePath, name))
+	}
+	return files
+}
+
+// loadJournalState returns the position of the journal. It returns nil if the journal doesn't exist.
+func loadJournalState(file string, withContent bool) (*JournalState, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	state := &JournalState{Size: int64(len(bz))}
+	scanner := bufio.NewScanner(bytes.NewReader(bz))
+	scanner.Buffer(nil, 1<<20)
+	var last []byte
+	for scanner.Scan() {
+		state.Entries++
+		last = scanner.Bytes()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the journal %s: %w", file, err)
+	}
+	if last != nil {
+		var e JournalEntry
+		if err := json.Unmarshal(last, &e); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the last entry of the journal %s: %w", file, err)
+		}
+		state.LastTime = &e.Time
+	}
+	if withContent {
+		state.Content = bz
+	}
+	return state, nil
+}
+
+func exists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
@@ -0,0 +1,59 @@
+package core_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestExportImportState(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	if err := core.SaveRelayStatus(core.RelayStatusFile(src, "path"), &core.RelayStatus{LastRelayTime: time.Unix(100, 0).UTC()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.PausePath(core.PauseStateFile(src, "path"), "maintenance"); err != nil {
+		t.Fatal(err)
+	}
+	journal, err := core.NewJournal(core.JournalFile(src, "path"), "path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Append([]*core.JournalEntry{{Time: time.Unix(200, 0).UTC(), Sequence: 1}, {Time: time.Unix(300, 0).UTC(), Sequence: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := core.ExportState(src, []string{"path"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := export.Paths["path"]
+	if state.Journal == nil || state.Journal.Entries != 2 || !state.Journal.LastTime.Equal(time.Unix(300, 0)) {
+		t.Fatalf("unexpected journal state: %+v", state.Journal)
+	}
+	if state.Spend != nil {
+		t.Errorf("spend must not be exported if it doesn't exist")
+	}
+
+	if err := core.ImportState(dst, export, false); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := core.LoadRelayStatus(core.RelayStatusFile(dst, "path")); err != nil || !status.LastRelayTime.Equal(time.Unix(100, 0)) {
+		t.Errorf("unexpected imported status: %v, %v", status, err)
+	}
+	if pause, err := core.LoadPauseState(core.PauseStateFile(dst, "path")); err != nil || !pause.Paused || pause.Reason != "maintenance" {
+		t.Errorf("unexpected imported pause state: %v, %v", pause, err)
+	}
+	if bz, err := os.ReadFile(core.JournalFile(dst, "path")); err != nil || int64(len(bz)) != state.Journal.Size {
+		t.Errorf("unexpected imported journal: size=%d, err=%v", len(bz), err)
+	}
+
+	// the existing state is not overwritten by default
+	if err := core.ImportState(dst, export, false); err == nil {
+		t.Errorf("ImportState must fail if the state already exists")
+	}
+	if err := core.ImportState(dst, export, true); err != nil {
+		t.Errorf("ImportState returns an unexpected error with overwrite: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// PathSnapshot is a snapshot of the status of a path
+type PathSnapshot struct {
+	Path          string        `json:"path"`
+	Src           ChainSnapshot `json:"src"`
+	Dst           ChainSnapshot `json:"dst"`
+	LastRelayTime *time.Time    `json:"last_relay_time,omitempty"`
+
+	// statistics by direction, whose counters are set from the status recorded by the relay service
+	SrcToDst DirectionStats `json:"src_to_dst"`
+	DstToSrc DirectionStats `json:"dst_to_src"`
+}
+
+// ChainSnapshot is a snapshot of the status of one end of a path
+type ChainSnapshot struct {
+	ChainID      string `json:"chain_id"`
+	LatestHeight string `json:"latest_height"`
+	// latest finalized height, which lags behind the latest height on chains without instant finality
+	FinalizedHeight string    `json:"finalized_height,omitempty"`
+	LatestTimestamp time.Time `json:"latest_timestamp"`
+
+	Client ClientSnapshot `json:"client"`
+
+	// channel end of the path; nil if the channel is not created yet
+	Channel *ChannelSnapshot `json:"channel,omitempty"`
+
+	// number of packets sent from the chain and not received on the counterparty chain yet
+	UnrelayedPackets int `json:"unrelayed_packets"`
+	// number of acknowledgements written on the chain and not relayed to the counterparty chain yet
+	UnrelayedAcknowledgements int `json:"unrelayed_acknowledgements"`
+
+	Address string `json:"address,omitempty"`
+	Balance string `json:"balance,omitempty"`
+
+	// errors occurred while collecting the status; the status is partial if not empty
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ClientSnapshot is a snapshot of the light client of the counterparty chain on a chain
+type ClientSnapshot struct {
+	ClientID           string     `json:"client_id"`
+	LatestHeight       string     `json:"latest_height,omitempty"`
+	ConsensusTimestamp *time.Time `json:"consensus_timestamp,omitempty"`
+	// time when the client expires if it's not updated (only available for clients with a trusting period)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ChannelSnapshot is a snapshot of the channel end of a path on a chain
+type ChannelSnapshot struct {
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+	State     string `json:"state"`
+	// version negotiated in the handshake with its parsed metadata
+	Version *ChannelVersion `json:"version,omitempty"`
+}
+
+// QueryPathSnapshot collects the status of `path` whose ends are set to `src` and `dst`.
+// The unrelayed packets and acknowledgements are summed over all the channels relayed over the path, excluding the ones already relayed but not finalized yet.
+// Failures to query each item are recorded in the status instead of aborting the whole query.
+func QueryPathSnapshot(pathName string, path *Path, src, dst *ProvableChain, sh SyncHeaders) *PathSnapshot {
+	status := &PathSnapshot{
+		Path: pathName,
+		Src:  queryChainSnapshot(src),
+		Dst:  queryChainSnapshot(dst),
+	}
+	if h := sh.GetChainHeights(src.ChainID()).Finalized; h != nil {
+		status.Src.FinalizedHeight = h.String()
+	}
+	if h := sh.GetChainHeights(dst.ChainID()).Finalized; h != nil {
+		status.Dst.FinalizedHeight = h.String()
+	}
+
+	// the path ends are switched to each channel and restored after the query
+	srcEnd, dstEnd := src.Path(), dst.Path()
+	defer func() {
+		if err := src.SetRelayInfo(srcEnd, dst, dstEnd); err != nil {
+			status.Src.addError("path end", err)
+		}
+		if err := dst.SetRelayInfo(dstEnd, src, srcEnd); err != nil {
+			status.Dst.addError("path end", err)
+		}
+	}()
+	for _, pair := range path.ChannelPathEnds() {
+		// no packet is sent on the channel not created yet
+		if pair.Src.ChannelID == "" || pair.Dst.ChannelID == "" {
+			continue
+		}
+		if err := status.queryChannelBacklog(pair, path.Strategy, src, dst, sh); err != nil {
+			status.Src.addError(fmt.Sprintf("channel %s/%s", pair.Src.PortID, pair.Src.ChannelID), err)
+			status.Dst.addError(fmt.Sprintf("channel %s/%s", pair.Dst.PortID, pair.Dst.ChannelID), err)
+		}
+	}
+	return status
+}
+
+// queryChannelBacklog adds the numbers of the unrelayed packets and acknowledgements on the channel of `pair` to the status
+func (status *PathSnapshot) queryChannelBacklog(pair PathEndPair, strategy *StrategyCfg, src, dst *ProvableChain, sh SyncHeaders) error {
+	if err := src.SetRelayInfo(pair.Src, dst, pair.Dst); err != nil {
+		return err
+	}
+	if err := dst.SetRelayInfo(pair.Dst, src, pair.Src); err != nil {
+		return err
+	}
+	// the strategy keeps the state of the channel
+	st, err := GetStrategy(*strategy)
+	if err != nil {
+		return err
+	}
+
+	if sp, err := st.UnrelayedPackets(src, dst, sh, false); err != nil {
+		status.Src.addError("unrelayed packets", err)
+		status.Dst.addError("unrelayed packets", err)
+	} else {
+		status.Src.UnrelayedPackets += len(sp.Src)
+		status.Dst.UnrelayedPackets += len(sp.Dst)
+		status.SrcToDst.PendingPackets += len(sp.Src)
+		status.DstToSrc.PendingPackets += len(sp.Dst)
+	}
+	if sa, err := st.UnrelayedAcknowledgements(src, dst, sh, false); err != nil {
+		status.Src.addError("unrelayed acknowledgements", err)
+		status.Dst.addError("unrelayed acknowledgements", err)
+	} else {
+		status.Src.UnrelayedAcknowledgements += len(sa.Src)
+		status.Dst.UnrelayedAcknowledgements += len(sa.Dst)
+		status.SrcToDst.PendingAcknowledgements += len(sa.Src)
+		status.DstToSrc.PendingAcknowledgements += len(sa.Dst)
+	}
+	return nil
+}
+
+func queryChainSnapshot(chain *ProvableChain) ChainSnapshot {
+	status := ChainSnapshot{
+		ChainID: chain.ChainID(),
+		Client:  ClientSnapshot{ClientID: chain.Path().ClientID},
+	}
+
+	height, err := chain.LatestHeight()
+	if err != nil {
+		status.addError("latest height", err)
+		return status
+	}
+	status.LatestHeight = height.String()
+	if ts, err := chain.Timestamp(height); err != nil {
+		status.addError("latest timestamp", err)
+	} else {
+		status.LatestTimestamp = ts
+	}
+
+	queryCtx := NewQueryContext(context.TODO(), height)
+	if err := status.Client.query(queryCtx, chain); err != nil {
+		status.addError("client", err)
+	}
+
+	if chain.Path().ChannelID != "" {
+		if res, err := chain.QueryChannel(queryCtx); err != nil {
+			status.addError("channel", err)
+		} else {
+			status.Channel = &ChannelSnapshot{
+				PortID:    chain.Path().PortID,
+				ChannelID: chain.Path().ChannelID,
+				State:     res.Channel.State.String(),
+			}
+			if version, err := ParseChannelVersion(res.Channel.Version); err != nil {
+				status.addError("channel version", err)
+			} else {
+				status.Channel.Version = version
+			}
+		}
+	}
+
+	if addr, err := chain.GetAddress(); err != nil {
+		status.addError("address", err)
+	} else {
+		status.Address = addr.String()
+		if coins, err := chain.QueryBalance(queryCtx, addr); err != nil {
+			status.addError("balance", err)
+		} else {
+			status.Balance = coins.String()
+		}
+	}
+	return status
+}
+
+func (cs *ClientSnapshot) query(ctx QueryContext, chain *ProvableChain) error {
+	csRes, err := chain.QueryClientState(ctx)
+	if err != nil {
+		return err
+	}
+	var clientState ibcexported.ClientState
+	if err := chain.Codec().UnpackAny(csRes.ClientState, &clientState); err != nil {
+		return err
+	}
+	cs.LatestHeight = clientState.GetLatestHeight().String()
+
+	consRes, err := chain.QueryClientConsensusState(ctx, clientState.GetLatestHeight())
+	if err != nil {
+		return err
+	}
+	var consState ibcexported.ConsensusState
+	if err := chain.Codec().UnpackAny(consRes.ConsensusState, &consState); err != nil {
+		return err
+	}
+	ts := time.Unix(0, int64(consState.GetTimestamp()))
+	cs.ConsensusTimestamp = &ts
+
+	if tmcs, ok := clientState.(*tmclient.ClientState); ok {
+		expiresAt := ts.Add(tmcs.TrustingPeriod)
+		cs.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+func (cs *ChainSnapshot) addError(item string, err error) {
+	cs.Errors = append(cs.Errors, fmt.Sprintf("%s: %v", item, err))
+}
+
+// RelayStatusFile returns the path of the file to record the status of the relay service for the path
+func RelayStatusFile(homePath, pathName string) string {
+	return filepath.Join(homePath, "status", pathName+".json")
+}
+
+// RelayStatus is the status of the relay service persisted to the status file
+type RelayStatus struct {
+	// LastRelayTime is zero if no relay cycle has succeeded yet
+	LastRelayTime time.Time `json:"last_relay_time"`
+
+	// cumulative counters of the packet msgs submitted in each direction
+	SrcToDst DirectionCounters `json:"src_to_dst"`
+	DstToSrc DirectionCounters `json:"dst_to_src"`
+}
+
+// SetRelayStatus sets the last relay time and the direction counters recorded by the relay service to the snapshot
+func (ps *PathSnapshot) SetRelayStatus(status *RelayStatus) {
+	if !status.LastRelayTime.IsZero() {
+		t := status.LastRelayTime
+		ps.LastRelayTime = &t
+	}
+	ps.SrcToDst.DirectionCounters = status.SrcToDst
+	ps.DstToSrc.DirectionCounters = status.DstToSrc
+}
+
+// LoadRelayStatus reads the status file. It returns nil if the file doesn't exist.
+func LoadRelayStatus(file string) (*RelayStatus, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var status RelayStatus
+	if err := json.Unmarshal(bz, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the relay status file %s: %w", file, err)
+	}
+	return &status, nil
+}
+
+// SaveRelayStatus writes the status file atomically
+func SaveRelayStatus(file string, status *RelayStatus) error {
+	bz, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(file, bz, 0600)
+}
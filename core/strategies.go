@@ -45,12 +45,61 @@ type StrategyCfg struct {
 	// If set, executions of acknowledgePacket are always skipped on the dst chain
 	// Also `UnrelayedAcknowledgements` returns zero packets for the dst chain.
 	DstNoack bool `json:"dst-noack" yaml:"dst-noack"`
+
+	// Maximum number of packets (or acknowledgements) relayed per channel in a relay cycle.
+	// Zero means unlimited.
+	MaxPacketsPerChannel uint64 `json:"max-packets-per-channel,omitempty" yaml:"max-packets-per-channel,omitempty"`
+
+	// Maximum number of packets (or acknowledgements) relayed in each direction in a relay cycle across all the channels of the path.
+	// Packets are picked from the channels in a round-robin manner, starting from a different channel in each relay cycle. Zero means unlimited.
+	MaxPacketsPerCycle uint64 `json:"max-packets-per-cycle,omitempty" yaml:"max-packets-per-cycle,omitempty"`
+
+	// AckPriority decides the order of the acknowledgement msgs relative to the packet msgs submitted in a relay cycle.
+	// "" relays packets first, "first" relays acknowledgements first and "interleave" relays AckRatio acknowledgements per packet.
+	AckPriority string `json:"ack-priority,omitempty" yaml:"ack-priority,omitempty"`
+
+	// AckRatio is the number of acknowledgements relayed per packet if AckPriority is "interleave"
+	AckRatio uint64 `json:"ack-ratio,omitempty" yaml:"ack-ratio,omitempty"`
+
+	// MinPacketDelay is the minimum age of the packets before they are relayed.
+	// It applies to the packets only, and the acknowledgements are relayed as soon as they are found.
+	MinPacketDelay *MinPacketDelayCfg `json:"min-packet-delay,omitempty" yaml:"min-packet-delay,omitempty"`
+
+	// GasBudget limits the gas of the msgs submitted to each chain in a relay cycle.
+	// The gas is estimated by simulation, and the msgs exceeding the budget are left to the next relay cycle.
+	GasBudget *GasBudgetCfg `json:"gas-budget,omitempty" yaml:"gas-budget,omitempty"`
+}
+
+// priorities of acknowledgements
+const (
+	AckPriorityNone       = ""
+	AckPriorityFirst      = "first"
+	AckPriorityInterleave = "interleave"
+)
+
+// MsgOrderer is an optional interface of StrategyI to decide the order of the msgs for packets and acknowledgements submitted in a relay cycle.
+// If a strategy doesn't implement it, the packet msgs are followed by the acknowledgement msgs.
+type MsgOrderer interface {
+	OrderMsgs(packetMsgs, ackMsgs *RelayMsgs) *RelayMsgs
+}
+
+// NewScheduler returns the scheduler limiting the packets relayed in a relay cycle, or nil if no limit is configured
+func (cfg StrategyCfg) NewScheduler() *RelayScheduler {
+	if cfg.MaxPacketsPerChannel == 0 && cfg.MaxPacketsPerCycle == 0 {
+		return nil
+	}
+	return NewRelayScheduler(cfg.MaxPacketsPerChannel, cfg.MaxPacketsPerCycle)
 }
 
 func GetStrategy(cfg StrategyCfg) (StrategyI, error) {
 	switch cfg.Type {
 	case "naive":
-		return NewNaiveStrategy(cfg.SrcNoack, cfg.DstNoack), nil
+		st := NewNaiveStrategy(cfg.SrcNoack, cfg.DstNoack)
+		st.AckPriority = cfg.AckPriority
+		st.AckRatio = cfg.AckRatio
+		st.MinPacketDelay = cfg.MinPacketDelay
+		st.GasBudget = cfg.GasBudget
+		return st, nil
 	default:
 		return nil, fmt.Errorf("unknown strategy type '%v'", cfg.Type)
 	}
@@ -60,8 +109,28 @@ func GetStrategy(cfg StrategyCfg) (StrategyI, error) {
 func (p *Path) ValidateStrategy() error {
 	switch p.Strategy.Type {
 	case (&NaiveStrategy{}).GetType():
-		return nil
 	default:
 		return fmt.Errorf("invalid strategy: %s", p.Strategy.Type)
 	}
+	if p.Strategy.MinPacketDelay != nil {
+		if err := p.Strategy.MinPacketDelay.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Strategy.GasBudget != nil {
+		if err := p.Strategy.GasBudget.Validate(); err != nil {
+			return err
+		}
+	}
+	switch p.Strategy.AckPriority {
+	case AckPriorityNone, AckPriorityFirst:
+		return nil
+	case AckPriorityInterleave:
+		if p.Strategy.AckRatio == 0 {
+			return fmt.Errorf("ack-ratio must be positive if ack-priority is %s", AckPriorityInterleave)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid ack-priority: %s", p.Strategy.AckPriority)
+	}
 }
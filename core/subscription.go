@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// PacketEventSubscriber is an optional interface of Chain to detect new packet events by a push-based subscription (e.g. WebSocket).
+// The relay service starts the next relay cycle as soon as it is notified instead of waiting for the relay interval,
+// while the packets to relay are still determined by the strategy scanning the chains.
+type PacketEventSubscriber interface {
+	// SubscribePacketEvents calls `notify` with the height of each block containing packets sent or acknowledgements written
+	// on the connection of the path end set to the chain, and blocks until `ctx` is done.
+	// Implementations must reconnect when the subscription drops and notify the events in the blocks missed during the outage.
+	// It returns nil immediately if the subscription is not enabled for the chain.
+	SubscribePacketEvents(ctx context.Context, notify func(height ibcexported.Height)) error
+}
+
+// startEventSubscriptions starts the packet event subscriptions of the chains that support them.
+// A notification wakes up the relay service waiting for the next relay cycle.
+func (srv *RelayService) startEventSubscriptions(ctx context.Context) {
+	for _, chain := range []*ProvableChain{srv.src, srv.dst} {
+		sub, ok := chain.Chain.(PacketEventSubscriber)
+		if !ok {
+			continue
+		}
+		chain := chain
+		go func() {
+			logger := GetChainLogger(chain)
+			if err := sub.SubscribePacketEvents(ctx, func(height ibcexported.Height) {
+				logger.Debug("packet events detected", "height", height)
+				select {
+				case srv.wake <- struct{}{}:
+				default:
+				}
+			}); err != nil {
+				logger.Error("packet event subscription stopped", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"strings"
+	"sync"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// TransferInfo is the human-readable summary of an ICS-20 fungible token transfer packet
+type TransferInfo struct {
+	// DenomTrace is the full denom path on the sending chain (e.g. "transfer/channel-0/uatom")
+	DenomTrace string `json:"denom_trace"`
+	BaseDenom  string `json:"base_denom"`
+	// SenderDenom and ReceiverDenom are the denoms of the token on the sending and receiving chains (e.g. "ibc/27394FB0...")
+	SenderDenom   string `json:"sender_denom"`
+	ReceiverDenom string `json:"receiver_denom"`
+	Amount        string `json:"amount"`
+	Sender        string `json:"sender"`
+	Receiver      string `json:"receiver"`
+}
+
+// DecodeTransferPacket decodes the packet as an ICS-20 packet and resolves its denoms.
+// It returns false if the packet data is not FungibleTokenPacketData.
+func DecodeTransferPacket(packet chantypes.Packet) (*TransferInfo, bool) {
+	var data transfertypes.FungibleTokenPacketData
+	if err := transfertypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &data); err != nil {
+		return nil, false
+	}
+	if data.Denom == "" || data.Amount == "" {
+		return nil, false
+	}
+
+	trace := transfertypes.ParseDenomTrace(data.Denom)
+	var receiverTrace transfertypes.DenomTrace
+	if transfertypes.ReceiverChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		// the token returns to the chain it came from, so the prefix is removed
+		prefix := transfertypes.GetDenomPrefix(packet.SourcePort, packet.SourceChannel)
+		receiverTrace = transfertypes.ParseDenomTrace(strings.TrimPrefix(data.Denom, prefix))
+	} else {
+		receiverTrace = transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(packet.DestinationPort, packet.DestinationChannel, data.Denom))
+	}
+
+	return &TransferInfo{
+		DenomTrace:    data.Denom,
+		BaseDenom:     trace.BaseDenom,
+		SenderDenom:   trace.IBCDenom(),
+		ReceiverDenom: receiverTrace.IBCDenom(),
+		Amount:        data.Amount,
+		Sender:        data.Sender,
+		Receiver:      data.Receiver,
+	}, true
+}
+
+// maxDenomLabels bounds the number of distinct denoms used as metric labels to keep the cardinality of the metrics bounded
+const maxDenomLabels = 64
+
+var denomLabels = struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}{seen: make(map[string]struct{})}
+
+// denomLabel returns `denom` as is if it is one of the first maxDenomLabels denoms, otherwise "other"
+func denomLabel(denom string) string {
+	denomLabels.mu.Lock()
+	defer denomLabels.mu.Unlock()
+	if _, ok := denomLabels.seen[denom]; ok {
+		return denom
+	}
+	if len(denomLabels.seen) >= maxDenomLabels {
+		return "other"
+	}
+	denomLabels.seen[denom] = struct{}{}
+	return denom
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// ErrTxNotFound is returned by GetTxResult if the tx is not included in any block yet
+var ErrTxNotFound = errors.New("tx not found")
+
+// TxResult is the execution result of a transaction, which is common to the chain modules
+type TxResult struct {
+	TxID   string             `json:"tx_id"`
+	Height clienttypes.Height `json:"height"`
+	// Code is the result code of the execution, where zero means success
+	Code uint32 `json:"code"`
+	// FailureReason describes the failure if Code is not zero
+	FailureReason string `json:"failure_reason,omitempty"`
+	GasWanted     uint64 `json:"gas_wanted"`
+	GasUsed       uint64 `json:"gas_used"`
+	// Events are the events emitted by the msgs in the transaction
+	Events []MsgEventLog `json:"-"`
+}
+
+// Succeeded returns true if the execution of the transaction succeeded
+func (r *TxResult) Succeeded() bool {
+	return r.Code == 0
+}
+
+// TxMsgID is an optional interface of MsgID that tells the ID of the transaction including the msg
+type TxMsgID interface {
+	// TxID returns the ID of the transaction that can be passed to GetTxResult
+	TxID() string
+}
+
+// txIDOf returns the ID of the transaction including the msg, or an empty string if unknown
+func txIDOf(msgID MsgID) string {
+	if id, ok := msgID.(TxMsgID); ok {
+		return id.TxID()
+	}
+	return ""
+}
+
+// WaitTxResult polls GetTxResult of the chain at intervals of the average block time until the transaction is included in a block.
+// It returns an error when `ctx` is done before the inclusion.
+func WaitTxResult(ctx context.Context, chain Chain, txID string) (*TxResult, error) {
+	interval := chain.AverageBlockTime()
+	for {
+		res, err := chain.GetTxResult(ctx, txID)
+		if err == nil {
+			recordTxResult(chain.ChainID(), res)
+			return res, nil
+		} else if !errors.Is(err, ErrTxNotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("tx %s is not included: %w", txID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func recordTxResult(chainID string, res *TxResult) {
+	metrics.TxResultsCounter.Add(context.TODO(), 1, api.WithAttributes(
+		attribute.Key("chain_id").String(chainID),
+		attribute.Key("success").Bool(res.Succeeded()),
+	))
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"sort"
+
 	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
 )
@@ -15,6 +17,14 @@ type PacketInfo struct {
 	EventHeight     clienttypes.Height `json:"event_height"`
 }
 
+// PacketEvents represents the packet events found in a range of blocks.
+// `SentPackets` have nil `Acknowledgement`, and `EventHeight` of each entry is the height in which the event occurs.
+// Both lists are sorted in the order in which the events occur.
+type PacketEvents struct {
+	SentPackets             PacketInfoList `json:"sent_packets"`
+	WrittenAcknowledgements PacketInfoList `json:"written_acknowledgements"`
+}
+
 // PacketInfoList represents a list of PacketInfo that is sorted in the order in which
 // underlying events (SendPacket and RecvPacket) occur.
 type PacketInfoList []*PacketInfo
@@ -27,6 +37,13 @@ func (ps PacketInfoList) ExtractSequenceList() []uint64 {
 	return seqs
 }
 
+// SortBySequence sorts the packets in ascending order of sequence.
+// Chains may return packets in the order of their keys in the store (e.g. "10" before "2"),
+// while the msgs of the packets must be submitted in order of sequence (see CheckMsgOrder).
+func (ps PacketInfoList) SortBySequence() {
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].Sequence < ps[j].Sequence })
+}
+
 func (ps PacketInfoList) Subtract(seqs []uint64) PacketInfoList {
 	var ret PacketInfoList
 out:
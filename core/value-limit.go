@@ -0,0 +1,46 @@
+package core
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ValueLimitCfg holds the packets transferring more than MaxAmount of Denom until they are released via the admin API.
+// The packet data is decoded by the packet decoder registry, so the limit applies to any app whose decoder
+// sets the "amount" attribute and the "base_denom" or "denom_trace" attribute.
+// ICS-721 packets are limited by the number of tokens with the class ID as the denom.
+type ValueLimitCfg struct {
+	// Denom is matched against the base denom (e.g. "uatom") or the full denom trace (e.g. "transfer/channel-0/uatom"),
+	// or the base class ID (e.g. "kitty") or the full class trace (e.g. "nft-transfer/channel-0/kitty") of NFTs
+	Denom string `json:"denom" yaml:"denom"`
+
+	// MaxAmount is the maximum amount of a single packet relayed without approval
+	MaxAmount string `json:"max-amount" yaml:"max-amount"`
+}
+
+// Validate validates the config
+func (cfg *ValueLimitCfg) Validate() error {
+	if cfg.Denom == "" {
+		return fmt.Errorf("value-limits: denom must be specified")
+	}
+	if amount, ok := sdk.NewIntFromString(cfg.MaxAmount); !ok || amount.IsNegative() {
+		return fmt.Errorf("value-limits: invalid max-amount for %s: %s", cfg.Denom, cfg.MaxAmount)
+	}
+	return nil
+}
+
+// exceeds returns true if the packet data transfers more than the limit
+func (cfg *ValueLimitCfg) exceeds(data *PacketData) bool {
+	switch cfg.Denom {
+	case data.Attribute("base_denom"), data.Attribute("denom_trace"), data.Attribute("base_class_id"), data.Attribute("class_id"):
+	default:
+		return false
+	}
+	amount, ok := sdk.NewIntFromString(data.Attribute("amount"))
+	if !ok {
+		return false
+	}
+	max, _ := sdk.NewIntFromString(cfg.MaxAmount)
+	return amount.GT(max)
+}
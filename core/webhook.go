@@ -0,0 +1,78 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultWebhookTimeout is the timeout of a request to a webhook if not configured
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookCfg configures the webhook receiving the alerts of a feature, which is embedded in the config of the feature
+type WebhookCfg struct {
+	// WebhookURL is the endpoint receiving a POST request with the alert in JSON.
+	// The alert is only logged if empty, unless the feature requires the webhook.
+	WebhookURL string `json:"webhook-url,omitempty" yaml:"webhook-url,omitempty"`
+
+	// Timeout is the timeout of a request to the webhook (default: "10s")
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Validate validates the config, where the webhook URL may be empty
+func (cfg *WebhookCfg) Validate() error {
+	if cfg.WebhookURL != "" && !strings.HasPrefix(cfg.WebhookURL, "http://") && !strings.HasPrefix(cfg.WebhookURL, "https://") {
+		return fmt.Errorf("invalid webhook-url: %s", cfg.WebhookURL)
+	}
+	if cfg.Timeout != "" {
+		if d, err := time.ParseDuration(cfg.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		} else if d <= 0 {
+			return fmt.Errorf("timeout must be positive: %v", d)
+		}
+	}
+	return nil
+}
+
+// sender returns the function posting a payload to the webhook, which does nothing if the webhook URL is empty.
+// The config must have been validated.
+func (cfg *WebhookCfg) sender() func(ctx context.Context, payload interface{}) error {
+	timeout := defaultWebhookTimeout
+	if cfg.Timeout != "" {
+		timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+	client := &http.Client{Timeout: timeout}
+	url := cfg.WebhookURL
+	return func(ctx context.Context, payload interface{}) error {
+		if url == "" {
+			return nil
+		}
+		return postWebhook(ctx, client, url, payload)
+	}
+}
+
+// postWebhook posts the payload in JSON to the webhook
+func postWebhook(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request the webhook: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestWebhookCfgFields(t *testing.T) {
+	// the fields of the webhook are at the same level as the other fields of the configs embedding it
+	expected := core.WebhookCfg{WebhookURL: "https://example.com/hook", Timeout: "5s"}
+	var fromJSON core.BacklogAlarmCfg
+	if err := json.Unmarshal([]byte(`{"threshold":10,"webhook-url":"https://example.com/hook","timeout":"5s"}`), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if fromJSON.Threshold != 10 || fromJSON.WebhookCfg != expected {
+		t.Errorf("unexpected config from JSON: %+v", fromJSON)
+	}
+	var fromYAML core.HeightLagAlarmCfg
+	if err := yaml.Unmarshal([]byte("latest-threshold: 5\nwebhook-url: https://example.com/hook\ntimeout: 5s\n"), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if fromYAML.LatestThreshold != 5 || fromYAML.WebhookCfg != expected {
+		t.Errorf("unexpected config from YAML: %+v", fromYAML)
+	}
+	bz, err := json.Marshal(&core.CommitmentCheckCfg{Interval: "10m", WebhookCfg: expected})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := string(bz); actual != `{"interval":"10m","webhook-url":"https://example.com/hook","timeout":"5s"}` {
+		t.Errorf("unexpected JSON: %s", actual)
+	}
+
+	// the URL is optional unless the feature requires it
+	if err := (&core.WebhookCfg{Timeout: "5s"}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (&core.ErrorAckAlertCfg{WebhookCfg: core.WebhookCfg{Timeout: "5s"}}).Validate(); err == nil {
+		t.Error("the error-ack alert is accepted without the webhook")
+	}
+}
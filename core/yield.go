@@ -0,0 +1,252 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+const (
+	defaultYieldWindow = 10 * time.Minute
+	defaultYieldDelay  = 5 * time.Minute
+	// ownRelayTTL is how long a packet relayed by the service is remembered until it is found relayed on the chain,
+	// which covers the finalization of the chains
+	ownRelayTTL = time.Hour
+)
+
+// YieldCfg makes the relay service yield the channels served by another relayer, which is detected by the packets and
+// the acknowledgements relayed without the service's submissions. While yielding, the service leaves the packets to
+// the other relayer for Delay before relaying them, and keeps relaying the acknowledgements, so that it saves the gas
+// spent on the redundant relays and takes over the packets left unrelayed if the other relayer stops.
+type YieldCfg struct {
+	// Threshold is the number of the relays by other relayers within Window at which the service starts yielding (default: 1)
+	Threshold uint64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	// Window is the period in which the relays by other relayers are counted (default: "10m").
+	// The service stops yielding when the relays by other relayers fall below the threshold in the window.
+	Window string `json:"window,omitempty" yaml:"window,omitempty"`
+	// Delay is how long a pending packet is left to other relayers while yielding (default: "5m")
+	Delay string `json:"delay,omitempty" yaml:"delay,omitempty"`
+}
+
+// Validate validates the config
+func (cfg *YieldCfg) Validate() error {
+	for name, s := range map[string]string{"window": cfg.Window, "delay": cfg.Delay} {
+		if s == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(s); err != nil {
+			return fmt.Errorf("yield: invalid %s: %w", name, err)
+		} else if d <= 0 {
+			return fmt.Errorf("yield: %s must be positive: %v", name, d)
+		}
+	}
+	return nil
+}
+
+// counterpartyRelayKey identifies a packet or an acknowledgement pending on a chain
+type counterpartyRelayKey struct {
+	// chainID, portID and channelID are the channel end of the chain on which the packet or the acknowledgement is written
+	chainID   string
+	portID    string
+	channelID string
+	sequence  uint64
+	ack       bool
+}
+
+// counterpartyRelays detects the relays by other relayers on the channels of a path and decides whether the service yields
+type counterpartyRelays struct {
+	// yielding is never enabled if cfg is nil
+	cfg       *YieldCfg
+	threshold uint64
+	window    time.Duration
+	delay     time.Duration
+
+	mu sync.Mutex
+	// packets and acknowledgements found pending with the times they were first found
+	pending map[counterpartyRelayKey]time.Time
+	// packets and acknowledgements relayed by the service with the times they were relayed
+	own map[counterpartyRelayKey]time.Time
+	// times at which the relays by other relayers were detected within the window
+	detected []time.Time
+	yielding bool
+}
+
+func newCounterpartyRelays(cfg *YieldCfg) *counterpartyRelays {
+	r := &counterpartyRelays{
+		cfg:       cfg,
+		threshold: 1,
+		window:    defaultYieldWindow,
+		delay:     defaultYieldDelay,
+		pending:   make(map[counterpartyRelayKey]time.Time),
+		own:       make(map[counterpartyRelayKey]time.Time),
+	}
+	if cfg != nil {
+		if cfg.Threshold > 0 {
+			r.threshold = cfg.Threshold
+		}
+		if cfg.Window != "" {
+			r.window, _ = time.ParseDuration(cfg.Window)
+		}
+		if cfg.Delay != "" {
+			r.delay, _ = time.ParseDuration(cfg.Delay)
+		}
+	}
+	return r
+}
+
+// SetYield makes the service yield the channels served by another relayer.
+// The relays by other relayers are detected and logged even if it is not called.
+func (srv *RelayService) SetYield(cfg *YieldCfg) {
+	srv.counterpartyRelays = newCounterpartyRelays(cfg)
+}
+
+// Yielding returns true if the service is yielding the channels to another relayer
+func (srv *RelayService) Yielding() bool {
+	r := srv.counterpartyRelays
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.yielding
+}
+
+// observe updates the pending packets or acknowledgements written on the channel end `end` of `chainID`,
+// and returns the number of the ones relayed since the last observation without the service's submissions
+func (r *counterpartyRelays) observe(chainID string, end *PathEnd, packets PacketInfoList, ack bool, now time.Time) int {
+	pending := make(map[counterpartyRelayKey]struct{}, len(packets))
+	for _, p := range packets {
+		key := counterpartyRelayKey{chainID, end.PortID, end.ChannelID, p.Sequence, ack}
+		pending[key] = struct{}{}
+		if _, ok := r.pending[key]; !ok {
+			r.pending[key] = now
+		}
+	}
+	relayed := 0
+	for key := range r.pending {
+		if key.chainID != chainID || key.portID != end.PortID || key.channelID != end.ChannelID || key.ack != ack {
+			continue
+		}
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		delete(r.pending, key)
+		if _, ok := r.own[key]; ok {
+			delete(r.own, key)
+			continue
+		}
+		relayed++
+		r.detected = append(r.detected, now)
+	}
+	return relayed
+}
+
+// update forgets the detections out of the window and the own relays out of the TTL,
+// and returns whether the yielding has started (1), stopped (-1) or not changed (0)
+func (r *counterpartyRelays) update(now time.Time) int {
+	for len(r.detected) > 0 && now.Sub(r.detected[0]) > r.window {
+		r.detected = r.detected[1:]
+	}
+	for key, t := range r.own {
+		if now.Sub(t) > ownRelayTTL {
+			delete(r.own, key)
+		}
+	}
+	yielding := r.cfg != nil && uint64(len(r.detected)) >= r.threshold
+	changed := 0
+	if yielding && !r.yielding {
+		changed = 1
+	} else if !yielding && r.yielding {
+		changed = -1
+	}
+	r.yielding = yielding
+	return changed
+}
+
+// detectCounterpartyRelays detects the packets and the acknowledgements on the channel relayed by other relayers,
+// and updates whether the service yields
+func (srv *RelayService) detectCounterpartyRelays(ch *relayChannel, pseqs, aseqs *RelayPackets) {
+	r := srv.counterpartyRelays
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	srcID, dstID := srv.src.ChainID(), srv.dst.ChainID()
+	packets := r.observe(srcID, ch.srcEnd, pseqs.Src, false, now) + r.observe(dstID, ch.dstEnd, pseqs.Dst, false, now)
+	acks := r.observe(srcID, ch.srcEnd, aseqs.Src, true, now) + r.observe(dstID, ch.dstEnd, aseqs.Dst, true, now)
+
+	logger := GetChannelPairLogger(srv.src, srv.dst)
+	if packets > 0 || acks > 0 {
+		logger.Info("detected the relays by another relayer",
+			"src_channel_id", ch.srcEnd.ChannelID,
+			"dst_channel_id", ch.dstEnd.ChannelID,
+			"num_packets", packets,
+			"num_acks", acks,
+		)
+	}
+	switch r.update(now) {
+	case 1:
+		logger.Warn("another relayer is serving the path; yielding the packets to it", "relays_in_window", len(r.detected), "window", r.window, "delay", r.delay)
+	case -1:
+		logger.Warn("another relayer stopped serving the path; taking over the packets", "window", r.window)
+	}
+}
+
+// yieldPackets returns the packets written on the channel end `end` of `chainID` that the service relays:
+// all of them unless the service is yielding, and otherwise the ones pending for the delay or requested on demand.
+// On ordered channels the packets following a yielded packet are also yielded.
+func (srv *RelayService) yieldPackets(chainID string, end *PathEnd, packets PacketInfoList) PacketInfoList {
+	r := srv.counterpartyRelays
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.yielding {
+		return packets
+	}
+	now := time.Now()
+	var ret PacketInfoList
+	for _, p := range packets {
+		since, ok := r.pending[counterpartyRelayKey{chainID, end.PortID, end.ChannelID, p.Sequence, false}]
+		if ok && now.Sub(since) < r.delay && !srv.priority.requested(chainID, p) {
+			if end.GetOrder() == chantypes.ORDERED {
+				break
+			}
+			continue
+		}
+		ret = append(ret, p)
+	}
+	if yielded := len(packets) - len(ret); yielded > 0 {
+		GetChannelPairLogger(srv.src, srv.dst).Info("yielded the packets to another relayer", "chain_id", chainID, "channel_id", end.ChannelID, "num_yielded", yielded)
+	}
+	return ret
+}
+
+// recordOwnRelays remembers the packets and the acknowledgements relayed by the service,
+// so that they are not detected as the relays by other relayers
+func (srv *RelayService) recordOwnRelays(entries []*JournalEntry) {
+	r := srv.counterpartyRelays
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, e := range entries {
+		if !e.Success {
+			continue
+		}
+		// the chain from which the msg relays the state
+		from, to := srv.src.ChainID(), srv.dst.ChainID()
+		if e.Direction == DstToSrc {
+			from, to = to, from
+		}
+		var key counterpartyRelayKey
+		switch e.MsgType {
+		case sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}):
+			key = counterpartyRelayKey{from, e.SourcePort, e.SourceChannel, e.Sequence, false}
+		case sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{}):
+			key = counterpartyRelayKey{from, e.DestinationPort, e.DestinationChannel, e.Sequence, true}
+		case sdk.MsgTypeURL(&chantypes.MsgTimeout{}), sdk.MsgTypeURL(&chantypes.MsgTimeoutOnClose{}):
+			// the timed-out packet is pending on the chain to which the msg is submitted
+			key = counterpartyRelayKey{to, e.SourcePort, e.SourceChannel, e.Sequence, false}
+		default:
+			continue
+		}
+		r.own[key] = now
+	}
+}
@@ -0,0 +1,127 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/chains/mock"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// scriptedStrategy returns the scripted unrelayed packets on src and submits MsgRecvPacket only for the packets in `own`.
+// The submissions fail with `sendErr` if it is set.
+type scriptedStrategy struct {
+	core.StrategyI
+	pending []uint64
+	own     map[uint64]bool
+	relayed []uint64
+	sendErr error
+	// doExecuteRelayDst of the last RelayPackets
+	executeDst bool
+}
+
+func (st *scriptedStrategy) UnrelayedPackets(src, dst *core.ProvableChain, sh core.SyncHeaders, includeRelayedButUnfinalized bool) (*core.RelayPackets, error) {
+	rp := &core.RelayPackets{}
+	for _, seq := range st.pending {
+		rp.Src = append(rp.Src, &core.PacketInfo{Packet: chantypes.Packet{
+			Sequence:           seq,
+			SourcePort:         src.Path().PortID,
+			SourceChannel:      src.Path().ChannelID,
+			DestinationPort:    dst.Path().PortID,
+			DestinationChannel: dst.Path().ChannelID,
+		}})
+	}
+	return rp, nil
+}
+
+func (st *scriptedStrategy) UnrelayedAcknowledgements(src, dst *core.ProvableChain, sh core.SyncHeaders, includeRelayedButUnfinalized bool) (*core.RelayPackets, error) {
+	return &core.RelayPackets{}, nil
+}
+
+func (st *scriptedStrategy) RelayPackets(src, dst *core.ProvableChain, rp *core.RelayPackets, sh core.SyncHeaders, doExecuteRelaySrc, doExecuteRelayDst bool) (*core.RelayMsgs, error) {
+	msgs := core.NewRelayMsgs()
+	st.relayed = nil
+	st.executeDst = doExecuteRelayDst
+	for _, p := range rp.Src {
+		st.relayed = append(st.relayed, p.Sequence)
+		if st.own[p.Sequence] {
+			msgs.Dst = append(msgs.Dst, &chantypes.MsgRecvPacket{Packet: p.Packet})
+		}
+	}
+	return msgs, nil
+}
+
+func (st *scriptedStrategy) RelayAcknowledgements(src, dst *core.ProvableChain, rp *core.RelayPackets, sh core.SyncHeaders, doExecuteAckSrc, doExecuteAckDst bool) (*core.RelayMsgs, error) {
+	return core.NewRelayMsgs(), nil
+}
+
+func (st *scriptedStrategy) UpdateClients(src, dst *core.ProvableChain, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst bool, sh core.SyncHeaders, doRefresh bool) (*core.RelayMsgs, error) {
+	return core.NewRelayMsgs(), nil
+}
+
+func (st *scriptedStrategy) Send(src, dst core.Chain, msgs *core.RelayMsgs) {
+	if st.sendErr != nil {
+		msgs.Errors = append(msgs.Errors, st.sendErr)
+		return
+	}
+	for range msgs.Dst {
+		msgs.DstMsgIDs = append(msgs.DstMsgIDs, &mock.MsgID{})
+	}
+	msgs.Succeeded = true
+}
+
+// newScriptedChains returns a pair of mock chains with a channel end set, on which scriptedStrategy relays
+func newScriptedChains(t *testing.T) ([2]*core.ProvableChain, core.SyncHeaders) {
+	t.Helper()
+	chains := newMockChains(t, [2]*core.PathEnd{
+		{ChainID: "ibc0", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+		{ChainID: "ibc1", ClientID: "mock-client-0", ConnectionID: "connection-0", PortID: "transfer", ChannelID: "channel-0", Order: "unordered", Version: "ics20-1"},
+	}, nil)
+	sh, err := core.NewSyncHeaders(chains[0], chains[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chains, sh
+}
+
+func TestYieldToCounterpartyRelayer(t *testing.T) {
+	if err := log.InitLogger("ERROR", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	chains, sh := newScriptedChains(t)
+	st := &scriptedStrategy{own: map[uint64]bool{2: true}}
+	srv := core.NewRelayService(st, chains[0], chains[1], sh, 0, 0, 0, 0, 0)
+	srv.SetYield(&core.YieldCfg{Threshold: 2, Delay: "1h"})
+
+	serve := func(pending ...uint64) {
+		t.Helper()
+		st.pending = pending
+		if err := srv.Serve(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serve(1, 2)
+	if len(st.relayed) != 2 || srv.Yielding() {
+		t.Fatalf("unexpected relay: relayed=%v, yielding=%t", st.relayed, srv.Yielding())
+	}
+	// the packet 1 is relayed by another relayer, and the packet 2 by the service
+	serve(3)
+	if len(st.relayed) != 1 || srv.Yielding() {
+		t.Fatalf("the service yields below the threshold: relayed=%v", st.relayed)
+	}
+	// the packet 3 is relayed by another relayer
+	serve(4)
+	if !srv.Yielding() {
+		t.Fatal("the service doesn't yield")
+	}
+	if len(st.relayed) != 0 {
+		t.Errorf("the packets are relayed while yielding: %v", st.relayed)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	tendermint "github.com/hyperledger-labs/yui-relayer/chains/tendermint/module"
 	"github.com/hyperledger-labs/yui-relayer/cmd"
@@ -13,6 +14,7 @@ func main() {
 		tendermint.Module{},
 		mock.Module{},
 	); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	api "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
 )
 
 const (
@@ -24,9 +25,34 @@ var (
 	ProcessedBlockHeightGauge      *Int64SyncGauge
 	BacklogSizeGauge               *Int64SyncGauge
 	BacklogOldestTimestampGauge    *Int64SyncGauge
-	ReceivePacketsFinalizedCounter api.Int64Counter
+	ReceivePacketsFinalizedCounter *Int64PersistentCounter
+	PendingAcknowledgementsGauge   *Int64SyncGauge
+	HeightLagGauge                 *Int64SyncGauge
+
+	PacketDeliveryLatencyHistogram       api.Float64Histogram
+	AcknowledgementRelayLatencyHistogram api.Float64Histogram
+
+	GasUsedCounter  *Int64PersistentCounter
+	FeesPaidCounter *Int64PersistentCounter
+
+	TransferPacketsRelayedCounter *Int64PersistentCounter
+
+	TxResultsCounter *Int64PersistentCounter
+
+	AcknowledgementsRelayedCounter *Int64PersistentCounter
+
+	DroppedRelayEventsCounter *Int64PersistentCounter
+
+	ShedPacketsCounter *Int64PersistentCounter
+
+	RelayedMsgsCounter *Int64PersistentCounter
+
+	FaucetRequestsCounter *Int64PersistentCounter
 )
 
+// latencyBuckets are the histogram bucket boundaries (in seconds) of the latency instruments
+var latencyBuckets = []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600, 1800, 3600}
+
 type ExporterConfig interface {
 	exporterType() string
 }
@@ -49,20 +75,27 @@ func (e ExporterProm) exporterType() string { return "prometheus" }
 func InitializeMetrics(exporterConf ExporterConfig) error {
 	var err error
 
+	// the default buckets of histograms are too fine-grained for latencies of cross-chain relays
+	latencyView := metric.NewView(
+		metric.Instrument{Name: fmt.Sprintf("%s.*_latency", namespaceRoot)},
+		metric.Stream{Aggregation: aggregation.ExplicitBucketHistogram{Boundaries: latencyBuckets}},
+	)
+
 	switch exporterConf := exporterConf.(type) {
 	case ExporterNull:
-		meterProvider = metric.NewMeterProvider()
+		meterProvider = metric.NewMeterProvider(metric.WithView(latencyView))
 	case ExporterProm:
 		if exporter, err := NewPrometheusExporter(exporterConf.Addr); err != nil {
 			return err
 		} else {
-			meterProvider = metric.NewMeterProvider(metric.WithReader(exporter))
+			meterProvider = metric.NewMeterProvider(metric.WithReader(exporter), metric.WithView(latencyView))
 		}
 	default:
 		panic("unexpected exporter type")
 	}
 
 	meter = meterProvider.Meter(meterName)
+	resetPersistentCounters()
 
 	// create the instrument "relayer.processed_block_height"
 	name := fmt.Sprintf("%s.processed_block_height", namespaceRoot)
@@ -99,7 +132,8 @@ func InitializeMetrics(exporterConf ExporterConfig) error {
 
 	// create the instrument "relayer.receive_packets_finalized"
 	name = fmt.Sprintf("%s.receive_packets_finalized", namespaceRoot)
-	if ReceivePacketsFinalizedCounter, err = meter.Int64Counter(
+	if ReceivePacketsFinalizedCounter, err = NewInt64PersistentCounter(
+		meter,
 		name,
 		api.WithUnit("1"),
 		api.WithDescription("number of packets that are received and finalized"),
@@ -107,6 +141,147 @@ func InitializeMetrics(exporterConf ExporterConfig) error {
 		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
 	}
 
+	// create the instrument "relayer.pending_acknowledgements"
+	name = fmt.Sprintf("%s.pending_acknowledgements", namespaceRoot)
+	if PendingAcknowledgementsGauge, err = NewInt64SyncGauge(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of packets that are received but whose acknowledgements are not written yet"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.height_lag"
+	name = fmt.Sprintf("%s.height_lag", namespaceRoot)
+	if HeightLagGauge, err = NewInt64SyncGauge(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of blocks by which the latest or finalized height known to the relayer lags behind the latest height reported by the chain"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.packet_delivery_latency"
+	name = fmt.Sprintf("%s.packet_delivery_latency", namespaceRoot)
+	if PacketDeliveryLatencyHistogram, err = meter.Float64Histogram(
+		name,
+		api.WithUnit("s"),
+		api.WithDescription("time from the block including a packet send to the block including the MsgRecvPacket submitted by the relayer"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.acknowledgement_relay_latency"
+	name = fmt.Sprintf("%s.acknowledgement_relay_latency", namespaceRoot)
+	if AcknowledgementRelayLatencyHistogram, err = meter.Float64Histogram(
+		name,
+		api.WithUnit("s"),
+		api.WithDescription("time from the block including an acknowledgement write to the block including the MsgAcknowledgement submitted by the relayer"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.gas_used"
+	name = fmt.Sprintf("%s.gas_used", namespaceRoot)
+	if GasUsedCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("amount of gas used by the transactions sent by the relayer"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.fees_paid"
+	name = fmt.Sprintf("%s.fees_paid", namespaceRoot)
+	if FeesPaidCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("amount of fees paid by the relayer for the transactions"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.transfer_packets_relayed"
+	name = fmt.Sprintf("%s.transfer_packets_relayed", namespaceRoot)
+	if TransferPacketsRelayedCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of ICS-20 transfer packets relayed by the relayer"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.tx_results"
+	name = fmt.Sprintf("%s.tx_results", namespaceRoot)
+	if TxResultsCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of the transactions sent by the relayer whose inclusion is confirmed"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.acknowledgements_relayed"
+	name = fmt.Sprintf("%s.acknowledgements_relayed", namespaceRoot)
+	if AcknowledgementsRelayedCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of the acknowledgements relayed by the relayer, labeled with the result (success, error or unknown) decoded from the acknowledgement"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.dropped_relay_events"
+	name = fmt.Sprintf("%s.dropped_relay_events", namespaceRoot)
+	if DroppedRelayEventsCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of the relay events dropped because the subscribers of the event feed don't keep up with them"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.shed_packets"
+	name = fmt.Sprintf("%s.shed_packets", namespaceRoot)
+	if ShedPacketsCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of the pending packets left unrelayed in a relay cycle by the shed mode of the backlog alarm"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.relayed_msgs"
+	name = fmt.Sprintf("%s.relayed_msgs", namespaceRoot)
+	if RelayedMsgsCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of the packet msgs submitted by the relayer, labeled with the msg type, the result and the relay direction (src-to-dst or dst-to-src)"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.faucet_requests"
+	name = fmt.Sprintf("%s.faucet_requests", namespaceRoot)
+	if FaucetRequestsCounter, err = NewInt64PersistentCounter(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of the faucet requests to top up the relayer accounts with low balances, labeled with the result"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
 	return nil
 }
 
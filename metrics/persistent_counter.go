@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// Int64PersistentCounter is a counter whose totals can be snapshotted and restored,
+// so that the counters don't reset to zero on every restart of the relayer.
+// It is exported as an observable counter reporting the totals.
+type Int64PersistentCounter struct {
+	counter       api.Int64ObservableCounter
+	mutex         *sync.RWMutex
+	attrsValueMap map[string]*Int64WithAttributes
+}
+
+// persistentCounters are the counters created by InitializeMetrics by name
+var (
+	persistentCountersMutex sync.Mutex
+	persistentCounters      = make(map[string]*Int64PersistentCounter)
+)
+
+// resetPersistentCounters forgets the counters created by the previous InitializeMetrics
+func resetPersistentCounters() {
+	persistentCountersMutex.Lock()
+	defer persistentCountersMutex.Unlock()
+	persistentCounters = make(map[string]*Int64PersistentCounter)
+}
+
+// NewInt64PersistentCounter creates the counter and registers it to the snapshots
+func NewInt64PersistentCounter(meter api.Meter, name string, options ...api.Int64ObservableCounterOption) (*Int64PersistentCounter, error) {
+	mutex := &sync.RWMutex{}
+	attrsValueMap := make(map[string]*Int64WithAttributes)
+	callback := func(ctx context.Context, observer api.Int64Observer) error {
+		mutex.RLock()
+		defer mutex.RUnlock()
+		for _, entry := range attrsValueMap {
+			observer.Observe(entry.value, api.WithAttributeSet(entry.attrs))
+		}
+		return nil
+	}
+	options = append(options, api.WithInt64Callback(callback))
+	counter, err := meter.Int64ObservableCounter(name, options...)
+	if err != nil {
+		return nil, err
+	}
+	c := &Int64PersistentCounter{counter, mutex, attrsValueMap}
+	persistentCountersMutex.Lock()
+	defer persistentCountersMutex.Unlock()
+	persistentCounters[name] = c
+	return c, nil
+}
+
+// Add adds `incr` to the total of the attributes given by `options` in the same way as api.Int64Counter
+func (c *Int64PersistentCounter) Add(_ context.Context, incr int64, options ...api.AddOption) {
+	c.add(incr, api.NewAddConfig(options).Attributes())
+}
+
+func (c *Int64PersistentCounter) add(incr int64, attrs attribute.Set) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	encodedAttrs := attrs.Encoded(attribute.DefaultEncoder())
+	if entry, ok := c.attrsValueMap[encodedAttrs]; ok {
+		entry.value += incr
+	} else {
+		c.attrsValueMap[encodedAttrs] = &Int64WithAttributes{incr, attrs}
+	}
+}
+
+// CounterSnapshot is the totals of the persistent counters by the name of the counter
+type CounterSnapshot map[string][]CounterValue
+
+// CounterValue is the total of a counter for a set of attributes
+type CounterValue struct {
+	Attributes []CounterAttribute `json:"attributes,omitempty"`
+	Value      int64              `json:"value"`
+}
+
+// CounterAttribute is an attribute of a counter in a snapshot
+type CounterAttribute struct {
+	Key string `json:"key"`
+	// Type is the type of the value (e.g. "STRING" or "BOOL")
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// SnapshotCounters returns the totals of all the persistent counters. It returns nil if the metrics are not initialized.
+func SnapshotCounters() CounterSnapshot {
+	persistentCountersMutex.Lock()
+	defer persistentCountersMutex.Unlock()
+	if len(persistentCounters) == 0 {
+		return nil
+	}
+	snapshot := make(CounterSnapshot)
+	for name, c := range persistentCounters {
+		c.mutex.RLock()
+		values := make([]CounterValue, 0, len(c.attrsValueMap))
+		keys := make([]string, 0, len(c.attrsValueMap))
+		for key := range c.attrsValueMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			entry := c.attrsValueMap[key]
+			v := CounterValue{Value: entry.value}
+			for _, kv := range entry.attrs.ToSlice() {
+				v.Attributes = append(v.Attributes, CounterAttribute{Key: string(kv.Key), Type: kv.Value.Type().String(), Value: kv.Value.AsInterface()})
+			}
+			values = append(values, v)
+		}
+		c.mutex.RUnlock()
+		snapshot[name] = values
+	}
+	return snapshot
+}
+
+// RestoreCounters adds the totals in the snapshot to the persistent counters.
+// It must be called once after InitializeMetrics and before the counters are updated.
+func RestoreCounters(snapshot CounterSnapshot) error {
+	persistentCountersMutex.Lock()
+	defer persistentCountersMutex.Unlock()
+	for name, values := range snapshot {
+		c, ok := persistentCounters[name]
+		if !ok {
+			// the counter was removed
+			continue
+		}
+		for _, v := range values {
+			kvs := make([]attribute.KeyValue, 0, len(v.Attributes))
+			for _, a := range v.Attributes {
+				kv, err := a.keyValue()
+				if err != nil {
+					return fmt.Errorf("invalid attribute of the counter %s: %w", name, err)
+				}
+				kvs = append(kvs, kv)
+			}
+			c.add(v.Value, attribute.NewSet(kvs...))
+		}
+	}
+	return nil
+}
+
+// keyValue restores the attribute, whose value was decoded from JSON
+func (a CounterAttribute) keyValue() (attribute.KeyValue, error) {
+	key := attribute.Key(a.Key)
+	switch a.Type {
+	case attribute.STRING.String():
+		if s, ok := a.Value.(string); ok {
+			return key.String(s), nil
+		}
+	case attribute.BOOL.String():
+		if b, ok := a.Value.(bool); ok {
+			return key.Bool(b), nil
+		}
+	case attribute.INT64.String():
+		if f, ok := a.Value.(float64); ok {
+			return key.Int64(int64(f)), nil
+		}
+	case attribute.FLOAT64.String():
+		if f, ok := a.Value.(float64); ok {
+			return key.Float64(f), nil
+		}
+	default:
+		return key.String(fmt.Sprint(a.Value)), nil
+	}
+	return attribute.KeyValue{}, fmt.Errorf("%s: unexpected value of type %s: %v", a.Key, a.Type, a.Value)
+}
@@ -13,3 +13,12 @@ func (c ProverConfig) Build(chain core.Chain) (core.Prover, error) {
 func (c ProverConfig) Validate() error {
 	return nil
 }
+
+var _ core.ConfigFieldDescriber = (*ProverConfig)(nil)
+
+// DescribeConfigFields describes the fields of the prover config in the config schema
+func (c ProverConfig) DescribeConfigFields() map[string]string {
+	return map[string]string{
+		"finality_delay": "number of blocks after which a header of the mock chain is regarded as finalized",
+	}
+}
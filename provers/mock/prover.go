@@ -69,8 +69,28 @@ func (pr *Prover) CreateInitialLightClientState(height exported.Height) (exporte
 }
 
 // SetupHeadersForUpdate returns the finalized header and any intermediate headers needed to apply it to the client on the counterpaty chain
-func (pr *Prover) SetupHeadersForUpdate(_ core.FinalityAwareChain, latestFinalizedHeader core.Header) ([]core.Header, error) {
-	return []core.Header{latestFinalizedHeader.(*mocktypes.Header)}, nil
+func (pr *Prover) SetupHeadersForUpdate(counterparty core.FinalityAwareChain, latestFinalizedHeader core.Header) ([]core.Header, error) {
+	h := latestFinalizedHeader.(*mocktypes.Header)
+
+	cph, err := counterparty.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	counterpartyClientRes, err := counterparty.QueryClientState(core.NewQueryContext(context.TODO(), cph))
+	if err != nil {
+		return nil, err
+	}
+	var cs exported.ClientState
+	if err := pr.chain.Codec().UnpackAny(counterpartyClientRes.ClientState, &cs); err != nil {
+		return nil, err
+	}
+
+	// the mock client rejects a header of a revision other than the one of its latest height, so the client must be upgraded first
+	if revision := h.GetHeight().GetRevisionNumber(); cs.GetLatestHeight().GetRevisionNumber() != revision {
+		return nil, fmt.Errorf("the client on %s is at revision %d but %s is at revision %d: the client must be upgraded to the new revision",
+			counterparty.ChainID(), cs.GetLatestHeight().GetRevisionNumber(), pr.chain.ChainID(), revision)
+	}
+	return []core.Header{h}, nil
 }
 
 func (pr *Prover) createMockHeader(height exported.Height) (core.Header, error) {
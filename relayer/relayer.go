@@ -0,0 +1,127 @@
+// Package relayer provides the Go API to embed the relay service into other programs (e.g. a node process of a chain
+// or a control plane of an operator) instead of running it via the CLI.
+//
+//	srv, err := relayer.New(relayer.Config{
+//		HomePath: "/var/lib/relayer",
+//		Modules:  []config.ModuleI{tendermint.Module{}, mock.Module{}},
+//		Path:     "ibc01",
+//		Service:  relayer.DefaultServiceOptions(),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	return srv.Start(ctx)
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// DefaultConfigPath is the path of the config file relative to the home directory used by the CLI
+const DefaultConfigPath = "config/config.json"
+
+// Config configures an embedded relayer
+type Config struct {
+	// HomePath is the home directory of the relayer, which holds the keys and the state files of the paths
+	HomePath string
+
+	// ConfigPath is the path of the config file, relative to HomePath unless it is absolute (default: DefaultConfigPath)
+	ConfigPath string
+
+	// ConfigOverrides override the values of the config file in the form of key=value (see the --set flag of the CLI)
+	ConfigOverrides []string
+
+	// Modules are the chain and prover modules used by the chains in the config
+	Modules []config.ModuleI
+
+	// Path is the name of the path relayed by the service
+	Path string
+
+	// Service is the options of the relay service
+	Service ServiceOptions
+
+	// Metrics is the exporter of the metrics (default: metrics.ExporterNull)
+	Metrics metrics.ExporterConfig
+
+	// SkipLoggerInit leaves the global logger as it is instead of initializing it with the logger config of the config file,
+	// which is useful if the host program has already initialized the logger with log.InitLogger
+	SkipLoggerInit bool
+}
+
+// Service is an embedded relay service of a path
+type Service struct {
+	ctx  *config.Context
+	srv  *core.RelayService
+	opts ServiceOptions
+}
+
+// New loads the config and builds the relay service of the path.
+// Note that the config can be loaded only once per process because the core config is global (see core.SetCoreConfig).
+func New(cfg Config) (*Service, error) {
+	if cfg.HomePath == "" {
+		return nil, errors.New("home path is not set")
+	}
+	if cfg.Path == "" {
+		return nil, errors.New("path is not set")
+	}
+	configPath := cfg.ConfigPath
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+	ctx := config.NewContext(cfg.Modules...)
+	if err := ctx.Config.InitConfig(ctx, cfg.HomePath, configPath, false, cfg.ConfigOverrides...); err != nil {
+		return nil, fmt.Errorf("failed to initialize the configuration: %w", err)
+	}
+	if !cfg.SkipLoggerInit {
+		lc := ctx.Config.Global.LoggerConfig
+		if err := log.InitLogger(lc.Level, lc.Format, lc.Output); err != nil {
+			return nil, err
+		}
+	}
+	exporter := cfg.Metrics
+	if exporter == nil {
+		exporter = metrics.ExporterNull{}
+	}
+	if err := metrics.InitializeMetrics(exporter); err != nil {
+		return nil, fmt.Errorf("failed to initialize the metrics: %w", err)
+	}
+	srv, err := NewRelayService(ctx, cfg.HomePath, cfg.Path, cfg.Service)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{ctx: ctx, srv: srv, opts: cfg.Service}, nil
+}
+
+// Start runs the relay service until `ctx` is done. The admin API server is started as well if AdminAddr is set,
+// and an error is returned if it fails to start.
+// It returns nil when `ctx` is canceled.
+func (s *Service) Start(ctx context.Context) error {
+	if s.opts.AdminAddr != "" {
+		admin := core.NewAdminServer(s.srv, s.opts.Admin)
+		if err := admin.Start(s.opts.AdminAddr); err != nil {
+			return err
+		}
+		defer admin.Close()
+	}
+	if err := s.srv.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// RelayService returns the underlying relay service, e.g. to pause the path or to release the held packets
+func (s *Service) RelayService() *core.RelayService {
+	return s.srv
+}
+
+// Context returns the config context loaded by New, which gives access to the chains and the paths in the config
+func (s *Service) Context() *config.Context {
+	return s.ctx
+}
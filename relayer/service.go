@@ -0,0 +1,199 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/config"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+// ServiceOptions are the options of the relay service, which correspond to the flags of `service start`
+type ServiceOptions struct {
+	// RelayInterval is the time interval to perform relays
+	RelayInterval time.Duration
+
+	// SrcRelayOptimizeInterval and SrcRelayOptimizeCount are the maximum time interval and number of packets to delay relays from the src chain for optimization
+	SrcRelayOptimizeInterval time.Duration
+	SrcRelayOptimizeCount    uint64
+
+	// DstRelayOptimizeInterval and DstRelayOptimizeCount are the maximum time interval and number of packets to delay relays from the dst chain for optimization
+	DstRelayOptimizeInterval time.Duration
+	DstRelayOptimizeCount    uint64
+
+	// Observe makes the service scan packets and emit metrics without submitting any transactions
+	Observe bool
+
+	// ShardIndex is the index of the shard relayed by the service if the path has the sharding config
+	ShardIndex uint32
+
+	// LeaderLeaseFile is the lease file on a shared file system to elect the leader among the instances serving the path (disabled if empty)
+	LeaderLeaseFile string
+	// LeaderLeaseTTL is the time for which the leadership lasts without renewal
+	LeaderLeaseTTL time.Duration
+
+	// AdminAddr is the host address to which the admin API server listens (disabled if empty)
+	AdminAddr string
+	// Admin is the options of the admin API server, which configure its authentication and TLS
+	Admin core.AdminServerOptions
+
+	// ClientStateCacheMaxAge is the maximum age of the client states cached in the relay cycles (disabled if 0)
+	ClientStateCacheMaxAge time.Duration
+}
+
+// DefaultServiceOptions returns the options with the default values of the flags of `service start`
+func DefaultServiceOptions() ServiceOptions {
+	return ServiceOptions{
+		RelayInterval:            3 * time.Second,
+		SrcRelayOptimizeInterval: 10 * time.Second,
+		SrcRelayOptimizeCount:    5,
+		DstRelayOptimizeInterval: 10 * time.Second,
+		DstRelayOptimizeCount:    5,
+		LeaderLeaseTTL:           30 * time.Second,
+		ClientStateCacheMaxAge:   30 * time.Second,
+	}
+}
+
+// NewRelayService builds the relay service of the path in the config of `ctx` with all the features configured for the path
+// (e.g. the additional channels, the fee budget, the journal and the value limits). The state files are placed under `homePath`.
+// The admin API server is not started even if AdminAddr is set.
+func NewRelayService(ctx *config.Context, homePath, pathName string, opts ServiceOptions) (*core.RelayService, error) {
+	c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
+	if err != nil {
+		return nil, err
+	}
+	path, err := ctx.Config.Paths.Get(pathName)
+	if err != nil {
+		return nil, err
+	}
+	st, err := core.GetStrategy(*path.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.SetupRelay(context.TODO(), c[src], c[dst]); err != nil {
+		return nil, err
+	}
+	c[src].EnableClientStateCache(opts.ClientStateCacheMaxAge)
+	c[dst].EnableClientStateCache(opts.ClientStateCacheMaxAge)
+	sh, err := core.NewSyncHeaders(c[src], c[dst])
+	if err != nil {
+		return nil, err
+	}
+	srv := core.NewRelayService(
+		st,
+		c[src],
+		c[dst],
+		sh,
+		opts.RelayInterval,
+		opts.SrcRelayOptimizeInterval,
+		opts.SrcRelayOptimizeCount,
+		opts.DstRelayOptimizeInterval,
+		opts.DstRelayOptimizeCount,
+	)
+	// relay the additional channels over the same connection
+	for _, pair := range path.ChannelPathEnds()[1:] {
+		st, err := core.GetStrategy(*path.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		srv.AddChannel(pair.Src, pair.Dst, st)
+	}
+	if path.ChannelDiscovery != nil {
+		srv.EnableChannelDiscovery(path.ChannelDiscovery, func() (core.StrategyI, error) {
+			return core.GetStrategy(*path.Strategy)
+		})
+	}
+	srv.SetScheduler(path.Strategy.NewScheduler())
+	srv.SetObserveMode(opts.Observe)
+	srv.SetPathName(pathName)
+	identity, err := ctx.Identity()
+	if err != nil {
+		return nil, err
+	}
+	identity.Path = pathName
+	srv.SetIdentity(identity)
+	srv.SetStatusFile(core.RelayStatusFile(homePath, pathName))
+	if err := srv.SetCounterSnapshotFile(core.CounterSnapshotFile(homePath, pathName)); err != nil {
+		return nil, err
+	}
+	tracker, err := core.NewSpendTracker(core.SpendFile(homePath, pathName), pathName, path, path.FeeBudget)
+	if err != nil {
+		return nil, err
+	}
+	srv.SetSpendTracker(tracker)
+	journal, err := core.NewJournal(core.JournalFile(homePath, pathName), pathName)
+	if err != nil {
+		return nil, err
+	}
+	if path.JournalAttestations {
+		if err := journal.EnableAttestations(c[src].Chain, c[dst].Chain); err != nil {
+			return nil, err
+		}
+	}
+	if path.Group != "" {
+		// the other paths of the group share the fee budget of the group through their spend files
+		files := make(map[string]string)
+		for _, name := range ctx.Config.Paths.PathsInGroup(path.Group) {
+			if name != pathName {
+				files[name] = core.SpendFile(homePath, name)
+			}
+		}
+		if err := tracker.SetGroup(path.Group, ctx.Config.Groups[path.Group], files); err != nil {
+			return nil, err
+		}
+		journal.SetGroup(path.Group)
+		srv.SetGroup(path.Group, core.GroupPauseStateFile(homePath, path.Group))
+	}
+	srv.SetJournal(journal)
+	srv.SetPauseFile(core.PauseStateFile(homePath, pathName))
+	srv.SetFaucets(ctx.Config.Faucets)
+	srv.SetValueLimits(path.ValueLimits)
+	if path.Screening != nil {
+		srv.SetAddressScreener(path.Screening.NewScreener(), path.Screening.UndecodableOutcome())
+	}
+	if path.FeePayee != nil {
+		srv.SetFeePayees(path.FeePayee)
+	}
+	if path.ErrorAckAlert != nil {
+		srv.SetErrorAckAlert(pathName, path.ErrorAckAlert)
+	}
+	if path.BacklogAlarm != nil {
+		srv.SetBacklogAlarm(pathName, path.BacklogAlarm)
+	}
+	if path.Yield != nil {
+		srv.SetYield(path.Yield)
+	}
+	if path.HeightLagAlarm != nil {
+		srv.SetHeightLagAlarm(pathName, path.HeightLagAlarm)
+	}
+	if path.CommitmentCheck != nil {
+		srv.SetCommitmentCheck(pathName, path.CommitmentCheck)
+		if err := srv.SetProofSpecs(ctx.Config.ProofSpecs); err != nil {
+			return nil, err
+		}
+	}
+	if path.Sharding != nil {
+		if err := srv.SetShard(path.Sharding, opts.ShardIndex); err != nil {
+			return nil, err
+		}
+	}
+	if opts.LeaderLeaseFile != "" {
+		elector, err := core.NewFileLeaderElector(opts.LeaderLeaseFile, leaderHolderID(), opts.LeaderLeaseTTL)
+		if err != nil {
+			return nil, err
+		}
+		srv.SetLeaderElector(elector)
+	}
+	return srv, nil
+}
+
+// leaderHolderID returns the identifier of this instance in the leader election
+func leaderHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,150 @@
+package signer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// KeyTypeSecp256k1 is the key type of secp256k1 keys returned by PubKey
+const KeyTypeSecp256k1 = "secp256k1"
+
+// unixScheme is the prefix of the address of a signer listening on a unix domain socket
+const unixScheme = "unix://"
+
+// TLSConfig is the TLS config to connect to a remote signer over TCP
+type TLSConfig struct {
+	// CAFile is the CA certificate to verify the signer
+	CAFile string
+	// CertFile and KeyFile are the client certificate and key presented to the signer (mutual TLS)
+	CertFile string
+	KeyFile  string
+}
+
+// Dial connects to the remote signer at `addr`, which is either `unix:///path/to/socket` or `host:port`.
+// A unix domain socket is used without TLS, and a TCP connection always requires TLS.
+func Dial(addr string, tlsCfg *TLSConfig) (*grpc.ClientConn, error) {
+	if strings.HasPrefix(addr, unixScheme) {
+		return grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if tlsCfg == nil || tlsCfg.CAFile == "" {
+		return nil, fmt.Errorf("a CA file is required to connect to the remote signer over TCP: %s", addr)
+	}
+	ca, err := os.ReadFile(tlsCfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate found in the CA file: %s", tlsCfg.CAFile)
+	}
+	cfg := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.Dial(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+}
+
+// Keyring is a keyring.Keyring whose keys are held by a remote signer.
+// Key lookups and signing are served by the signer, and the other operations are delegated to the local keyring.
+type Keyring struct {
+	keyring.Keyring
+	client  RemoteSignerClient
+	chainID string
+	timeout time.Duration
+
+	// public keys are cached because they never change for a key name
+	mu      sync.Mutex
+	pubKeys map[string]cryptotypes.PubKey
+}
+
+var _ keyring.Keyring = (*Keyring)(nil)
+
+// NewKeyring returns a keyring signing with `client` for the chain `chainID`.
+// `local` serves the operations other than key lookups and signing, e.g. listing keys.
+func NewKeyring(local keyring.Keyring, client RemoteSignerClient, chainID string, timeout time.Duration) *Keyring {
+	return &Keyring{Keyring: local, client: client, chainID: chainID, timeout: timeout, pubKeys: make(map[string]cryptotypes.PubKey)}
+}
+
+// Key returns an offline record with the public key held by the signer
+func (k *Keyring) Key(uid string) (*keyring.Record, error) {
+	pk, err := k.pubKey(uid)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.NewOfflineRecord(uid, pk)
+}
+
+// KeyByAddress isn't supported because the signer identifies keys by their names
+func (k *Keyring) KeyByAddress(address sdk.Address) (*keyring.Record, error) {
+	return nil, fmt.Errorf("looking up a key by address is not supported by the remote signer: %s", address)
+}
+
+// Sign asks the signer to sign `msg` with the key `uid`
+func (k *Keyring) Sign(uid string, msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	pk, err := k.pubKey(uid)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := k.context()
+	defer cancel()
+	res, err := k.client.Sign(ctx, &SignRequest{ChainId: k.chainID, KeyName: uid, SignBytes: msg})
+	if err != nil {
+		return nil, nil, fmt.Errorf("remote signer failed to sign with the key %s: %w", uid, err)
+	}
+	if !pk.VerifySignature(msg, res.Signature) {
+		return nil, nil, fmt.Errorf("remote signer returned an invalid signature for the key %s", uid)
+	}
+	return res.Signature, pk, nil
+}
+
+// SignByAddress isn't supported because the signer identifies keys by their names
+func (k *Keyring) SignByAddress(address sdk.Address, msg []byte) ([]byte, cryptotypes.PubKey, error) {
+	return nil, nil, fmt.Errorf("signing by address is not supported by the remote signer: %s", address)
+}
+
+func (k *Keyring) pubKey(uid string) (cryptotypes.PubKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if pk, ok := k.pubKeys[uid]; ok {
+		return pk, nil
+	}
+	ctx, cancel := k.context()
+	defer cancel()
+	res, err := k.client.PubKey(ctx, &PubKeyRequest{ChainId: k.chainID, KeyName: uid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the public key of %s from the remote signer: %w", uid, err)
+	}
+	switch res.KeyType {
+	case KeyTypeSecp256k1:
+		if len(res.PubKey) != secp256k1.PubKeySize {
+			return nil, fmt.Errorf("invalid secp256k1 public key size: %d", len(res.PubKey))
+		}
+		pk := &secp256k1.PubKey{Key: res.PubKey}
+		k.pubKeys[uid] = pk
+		return pk, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", res.KeyType)
+	}
+}
+
+func (k *Keyring) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), k.timeout)
+}
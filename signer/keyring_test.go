@@ -0,0 +1,75 @@
+package signer_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/hyperledger-labs/yui-relayer/signer"
+	"google.golang.org/grpc"
+)
+
+type testSigner struct {
+	signer.UnimplementedRemoteSignerServer
+	key *secp256k1.PrivKey
+}
+
+func (s *testSigner) PubKey(ctx context.Context, req *signer.PubKeyRequest) (*signer.PubKeyResponse, error) {
+	return &signer.PubKeyResponse{KeyType: signer.KeyTypeSecp256k1, PubKey: s.key.PubKey().Bytes()}, nil
+}
+
+func (s *testSigner) Sign(ctx context.Context, req *signer.SignRequest) (*signer.SignResponse, error) {
+	sig, err := s.key.Sign(req.SignBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &signer.SignResponse{Signature: sig}, nil
+}
+
+func TestRemoteSignerKeyring(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := secp256k1.GenPrivKey()
+	srv := grpc.NewServer()
+	signer.RegisterRemoteSignerServer(srv, &testSigner{key: key})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := signer.Dial("unix://"+socket, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	kr := signer.NewKeyring(nil, signer.NewRemoteSignerClient(conn), "ibc0", 5*time.Second)
+
+	record, err := kr.Key("relayer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := record.GetPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Equals(key.PubKey()) {
+		t.Errorf("unexpected public key: %v", pk)
+	}
+
+	msg := []byte("sign bytes")
+	sig, _, err := kr.Sign("relayer", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.PubKey().VerifySignature(msg, sig) {
+		t.Errorf("invalid signature")
+	}
+
+	if _, err := signer.Dial("localhost:1234", nil); err == nil {
+		t.Errorf("Dial must fail without TLS over TCP")
+	}
+}
@@ -0,0 +1,193 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hyperledger-labs/yui-relayer/utils"
+)
+
+// ErrDoubleSign is returned when signing would produce a signature conflicting with one produced before
+var ErrDoubleSign = errors.New("refused to sign: conflicting with a previous signature")
+
+// maxSignStateRecords is the number of the records in the WAL beyond which it is compacted to the last record
+const maxSignStateRecords = 1000
+
+// SignRecord is a record of the WAL of SignStateStore
+type SignRecord struct {
+	// Sequence is the sequence or height over which the signature is produced (e.g. the sequence of a solo machine client)
+	Sequence uint64 `json:"sequence"`
+	// SignBytesHash is the SHA-256 hash of the signed bytes
+	SignBytesHash []byte `json:"sign_bytes_hash"`
+}
+
+// SignStateStore protects a key from double signing for provers in which the relayer itself signs the states of a chain
+// at monotonically increasing sequences (e.g. solo machine or enclave-based provers).
+// Before a signature is produced, its sequence and the hash of the sign bytes are appended to a write-ahead log and synced,
+// so that even after a crash or a restart the key never signs different bytes at the same sequence or a lower sequence.
+// A store must be used by a single process for a key.
+type SignStateStore struct {
+	mu      sync.Mutex
+	file    string
+	f       *os.File
+	last    *SignRecord
+	records int
+}
+
+// SignStateFile returns the path of the WAL of the sign state of a key, e.g. named after the chain ID and the client ID it signs for
+func SignStateFile(homePath, name string) string {
+	return filepath.Join(homePath, "sign-state", name+".wal")
+}
+
+// OpenSignStateStore opens the store persisted in the WAL `file`, which is created if it doesn't exist
+func OpenSignStateStore(file string) (*SignStateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, err
+	}
+	s := &SignStateStore{file: file}
+	if err := s.recover(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s.f = f
+	return s, nil
+}
+
+// recover reads the last record of the WAL.
+// A torn record at the end is truncated because the signature of a record is produced only after the record is synced.
+func (s *SignStateStore) recover() error {
+	bz, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if end := bytes.LastIndexByte(bz, '\n') + 1; end < len(bz) {
+		if err := os.Truncate(s.file, int64(end)); err != nil {
+			return fmt.Errorf("failed to truncate the torn record of the sign state %s: %w", s.file, err)
+		}
+		bz = bz[:end]
+	}
+	for _, line := range bytes.Split(bytes.TrimSuffix(bz, []byte("\n")), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var r SignRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			return fmt.Errorf("corrupted sign state %s: %w", s.file, err)
+		}
+		if s.last != nil && r.Sequence < s.last.Sequence {
+			return fmt.Errorf("corrupted sign state %s: the sequence decreased from %d to %d", s.file, s.last.Sequence, r.Sequence)
+		}
+		s.last = &r
+		s.records++
+	}
+	return nil
+}
+
+// Last returns the last record, or false if nothing has been signed
+func (s *SignStateStore) Last() (SignRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		return SignRecord{}, false
+	}
+	return *s.last, true
+}
+
+// Sign calls `sign` to sign `signBytes` at `sequence` only if it doesn't conflict with the previous signatures.
+// Signing at a lower sequence than the last one, or signing different bytes at the same sequence, fails with ErrDoubleSign.
+// Signing the same bytes at the last sequence again is allowed, e.g. to retry a failed transaction.
+func (s *SignStateStore) Sign(sequence uint64, signBytes []byte, sign func([]byte) ([]byte, error)) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := sha256.Sum256(signBytes)
+	if s.last != nil {
+		switch {
+		case sequence < s.last.Sequence:
+			return nil, fmt.Errorf("%w: sequence %d is lower than the last signed sequence %d", ErrDoubleSign, sequence, s.last.Sequence)
+		case sequence == s.last.Sequence && !bytes.Equal(hash[:], s.last.SignBytesHash):
+			return nil, fmt.Errorf("%w: different bytes at the signed sequence %d", ErrDoubleSign, sequence)
+		case sequence == s.last.Sequence:
+			return sign(signBytes)
+		}
+	}
+	if err := s.append(SignRecord{Sequence: sequence, SignBytesHash: hash[:]}); err != nil {
+		return nil, fmt.Errorf("failed to record the sign state: %w", err)
+	}
+	return sign(signBytes)
+}
+
+// append appends the record to the WAL and syncs it
+func (s *SignStateStore) append(r SignRecord) error {
+	if s.f == nil {
+		return errors.New("the sign state store is closed")
+	}
+	if s.records >= maxSignStateRecords {
+		if err := s.compact(r); err != nil {
+			return err
+		}
+		s.last = &r
+		return nil
+	}
+	bz, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err = s.f.Write(append(bz, '\n')); err == nil {
+		err = s.f.Sync()
+	}
+	if err != nil {
+		// the WAL may end with a torn record, which is truncated when the store is opened again
+		s.f.Close()
+		s.f = nil
+		return err
+	}
+	s.last = &r
+	s.records++
+	return nil
+}
+
+// compact replaces the WAL atomically with the single record `r`
+func (s *SignStateStore) compact(r SignRecord) error {
+	bz, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(s.file, append(bz, '\n'), 0600); err != nil {
+		return err
+	}
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.file, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		s.f = nil
+		return err
+	}
+	s.f = f
+	s.records = 1
+	return nil
+}
+
+// Close closes the WAL
+func (s *SignStateStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
@@ -0,0 +1,96 @@
+package signer_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/hyperledger-labs/yui-relayer/signer"
+)
+
+func TestSignStateStore(t *testing.T) {
+	file := signer.SignStateFile(t.TempDir(), "ibc0-06-solomachine-0")
+	key := secp256k1.GenPrivKey()
+	store, err := signer.OpenSignStateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name      string
+		sequence  uint64
+		signBytes string
+		ok        bool
+	}{
+		{"first", 1, "a", true},
+		{"next", 2, "b", true},
+		{"same bytes again", 2, "b", true},
+		{"conflicting bytes", 2, "c", false},
+		{"lower sequence", 1, "a", false},
+		{"skipped sequence", 5, "d", true},
+	} {
+		sig, err := store.Sign(c.sequence, []byte(c.signBytes), key.Sign)
+		if c.ok && (err != nil || len(sig) == 0) {
+			t.Errorf("%s: failed to sign: %v", c.name, err)
+		} else if !c.ok && !errors.Is(err, signer.ErrDoubleSign) {
+			t.Errorf("%s: unexpected result: %v", c.name, err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a torn record left by a crash is discarded on restart, and the state before it is kept
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"sequence":6,"sign_`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	store, err = signer.OpenSignStateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if last, ok := store.Last(); !ok || last.Sequence != 5 {
+		t.Errorf("unexpected last record: %v", last)
+	}
+	if _, err := store.Sign(5, []byte("e"), key.Sign); !errors.Is(err, signer.ErrDoubleSign) {
+		t.Errorf("signed conflicting bytes after the restart: %v", err)
+	}
+	if _, err := store.Sign(6, []byte("f"), key.Sign); err != nil {
+		t.Errorf("failed to sign after the restart: %v", err)
+	}
+}
+
+func TestSignStateStoreCompaction(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.wal")
+	store, err := signer.OpenSignStateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(bz []byte) ([]byte, error) { return bz, nil }
+	for seq := uint64(1); seq <= 2500; seq++ {
+		if _, err := store.Sign(seq, []byte{byte(seq)}, noop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	store, err = signer.OpenSignStateStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if last, ok := store.Last(); !ok || last.Sequence != 2500 {
+		t.Errorf("unexpected last record: %v", last)
+	}
+	if _, err := store.Sign(2499, []byte{1}, noop); !errors.Is(err, signer.ErrDoubleSign) {
+		t.Errorf("signed at a lower sequence after the compaction: %v", err)
+	}
+}